@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+
 	"github.com/labstack/echo/v4"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/config"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/database"
@@ -18,6 +20,12 @@ func main() {
 		panic(err)
 	}
 	e := echo.New()
-	route.InitGlobalRoute(e, db)
+	workers := route.InitGlobalRoute(e, db)
+
+	// expiry, stock batch and refund worker run in background
+	for _, worker := range workers {
+		go worker.Start(context.Background())
+	}
+
 	e.Logger.Fatal(e.Start(":" + config.Cfg.API_PORT))
 }
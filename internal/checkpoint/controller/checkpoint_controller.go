@@ -9,6 +9,8 @@ import (
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/checkpoint/service"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	_middleware "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/middleware"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
 type JWTService interface {
@@ -28,19 +30,14 @@ func NewCheckpointController(service service.CheckpointService, jwt JWTService)
 
 func (u *checkpointController) InitRoute(auth *echo.Group) {
 	checkpoints := auth.Group("/checkpoints")
-	checkpoints.POST("", u.CreateCheckpoint)
-	checkpoints.GET("", u.GetCheckpoints)
-	checkpoints.GET("/profile", u.GetCheckpointByUser)
+	checkpoints.POST("", u.CreateCheckpoint, _middleware.RequirePermission(constants.Permission_checkpoint_manage))
+	checkpoints.GET("", u.GetCheckpoints, _middleware.RequirePermission(constants.Permission_checkpoint_read))
+	checkpoints.GET("/profile", u.GetCheckpointByUser, _middleware.RequirePermission(constants.Permission_checkpoint_read))
+	checkpoints.POST("/:id/operators", u.AssignOperator, _middleware.RequirePermission(constants.Permission_checkpoint_manage))
+	checkpoints.DELETE("/:id/operators/:user_id", u.RemoveOperator, _middleware.RequirePermission(constants.Permission_checkpoint_manage))
 }
 
 func (u *checkpointController) CreateCheckpoint(c echo.Context) error {
-	claims := u.jwtService.GetClaims(&c)
-	role := claims["role_id"].(float64)
-	if role != constants.Role_admin {
-		return c.JSON(http.StatusForbidden, echo.Map{
-			"message": customerrors.ErrPermission.Error(),
-		})
-	}
 	var checkpointBody dto.CheckpointRequest
 	if err := c.Bind(&checkpointBody); err != nil {
 		return c.JSON(http.StatusBadRequest, echo.Map{
@@ -67,15 +64,22 @@ func (u *checkpointController) CreateCheckpoint(c echo.Context) error {
 }
 
 func (u *checkpointController) GetCheckpoints(c echo.Context) error {
-	checkpoints, err := u.service.FindCheckpoints(c.Request().Context())
+	opts, err := query.Parse(c.QueryParams(), dto.CheckpointQuery)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	checkpoints, page, err := u.service.FindCheckpoints(opts, c.Request().Context())
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{
 			"message": err.Error(),
 		})
 	}
 	return c.JSON(http.StatusOK, echo.Map{
-		"message": "get checkpoint success",
-		"data":    checkpoints,
+		"message":    "get checkpoint success",
+		"data":       checkpoints,
+		"pagination": page,
 	})
 }
 
@@ -84,7 +88,7 @@ func (u *checkpointController) GetCheckpointByUser(c echo.Context) error {
 	userId := claims["user_id"].(string)
 	checkpoints, err := u.service.FindCheckpointsByUser(userId, c.Request().Context())
 	if err != nil {
-		if err == customerrors.ErrCheckpointNotCovered {
+		if err == customerrors.ErrCheckpointNotCovered || err == customerrors.ErrNotFound {
 			return c.JSON(http.StatusNotFound, echo.Map{
 				"message": err.Error(),
 			})
@@ -98,3 +102,56 @@ func (u *checkpointController) GetCheckpointByUser(c echo.Context) error {
 		"data":    checkpoints,
 	})
 }
+
+func (u *checkpointController) AssignOperator(c echo.Context) error {
+	var operatorBody dto.OperatorRequest
+	if err := c.Bind(&operatorBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrBadRequestBody.Error()})
+	}
+	if err := c.Validate(operatorBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	}
+	err := u.service.AssignOperator(c.Param("id"), operatorBody, c.Request().Context())
+	if err != nil {
+		if err == customerrors.ErrInvalidId || err == customerrors.ErrInvalidOperator {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		if err == customerrors.ErrNotFound {
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "operator assigned to checkpoint",
+	})
+}
+
+func (u *checkpointController) RemoveOperator(c echo.Context) error {
+	err := u.service.RemoveOperator(c.Param("id"), c.Param("user_id"), c.Request().Context())
+	if err != nil {
+		if err == customerrors.ErrInvalidId || err == customerrors.ErrInvalidOperator {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		if err == customerrors.ErrNotFound {
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "operator removed from checkpoint",
+	})
+}
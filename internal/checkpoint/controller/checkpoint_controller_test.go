@@ -17,6 +17,7 @@ import (
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
 	mm "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/middleware/mock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	vm "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/validator/mock"
 	"github.com/stretchr/testify/suite"
 )
@@ -83,28 +84,6 @@ func (s *suiteCheckpointController) TestCreateCheckpoint() {
 			CreateCheckpointErr: nil,
 			CreateCheckpointRes: checkpontId,
 		},
-		{
-			Name:           "forbidden",
-			ExpectedStatus: 403,
-			ExpectedResult: map[string]interface{}{
-				"message": customerrors.ErrPermission.Error(),
-			},
-			Body: map[string]interface{}{
-				"name":        "checkpoint",
-				"description": "test",
-				"province_id": 1,
-				"regency_id":  1,
-				"district_id": 1,
-				"village_id":  1,
-				"lat_long":    "-12, 12",
-			},
-			JWTReturn: jwt.MapClaims{
-				"role_id": float64(100),
-			},
-			ValidatorErr:        nil,
-			CreateCheckpointErr: nil,
-			CreateCheckpointRes: checkpontId,
-		},
 		{
 			Name:           "invalid body type",
 			ExpectedStatus: 400,
@@ -229,6 +208,15 @@ func (s *suiteCheckpointController) TestCreateCheckpoint() {
 	}
 }
 
+var listPage = &query.Page{Page: 1, Limit: 20, Total: 1, TotalPages: 1}
+
+var listPageResult = map[string]interface{}{
+	"page":        float64(1),
+	"limit":       float64(20),
+	"total":       float64(1),
+	"total_pages": float64(1),
+}
+
 func (s *suiteCheckpointController) TestGetCheckpoint() {
 	checkpointId := uuid.New()
 
@@ -255,7 +243,8 @@ func (s *suiteCheckpointController) TestGetCheckpoint() {
 						"village_name":  "village",
 					},
 				},
-				"message": "get checkpoint success",
+				"message":    "get checkpoint success",
+				"pagination": listPageResult,
 			},
 			FindCheckpointsErr: nil,
 			FindCheckpointsRes: dto.CheckpointsResponse{
@@ -282,8 +271,9 @@ func (s *suiteCheckpointController) TestGetCheckpoint() {
 			Name:           "nil checkpoint",
 			ExpectedStatus: 200,
 			ExpectedResult: map[string]interface{}{
-				"data":    []interface{}{},
-				"message": "get checkpoint success",
+				"data":       []interface{}{},
+				"message":    "get checkpoint success",
+				"pagination": listPageResult,
 			},
 			FindCheckpointsErr: nil,
 			FindCheckpointsRes: dto.CheckpointsResponse{},
@@ -303,7 +293,7 @@ func (s *suiteCheckpointController) TestGetCheckpoint() {
 			ctx.SetPath("/checkpoints")
 
 			// define mock
-			s.checkpointServiceMock.On("FindCheckpoints").Return(v.FindCheckpointsRes, v.FindCheckpointsErr)
+			s.checkpointServiceMock.On("FindCheckpoints").Return(v.FindCheckpointsRes, listPage, v.FindCheckpointsErr)
 
 			err := s.checkpointController.GetCheckpoints(ctx)
 			s.NoError(err)
@@ -421,6 +411,162 @@ func (s *suiteCheckpointController) TestGetCheckpointByUser() {
 	}
 }
 
+func (s *suiteCheckpointController) TestAssignOperator() {
+	checkpointId := uuid.New()
+	userId := uuid.New()
+
+	testCase := []struct {
+		Name              string
+		ExpectedStatus    int
+		ExpectedResult    map[string]interface{}
+		ValidatorErr      error
+		AssignOperatorErr error
+	}{
+		{
+			Name:           "success assign operator",
+			ExpectedStatus: 200,
+			ExpectedResult: map[string]interface{}{
+				"message": "operator assigned to checkpoint",
+			},
+		},
+		{
+			Name:           "validator error",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": errors.New("validator error").Error(),
+			},
+			ValidatorErr: errors.New("validator error"),
+		},
+		{
+			Name:           "user cant be operator",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrInvalidOperator.Error(),
+			},
+			AssignOperatorErr: customerrors.ErrInvalidOperator,
+		},
+		{
+			Name:           "checkpoint not found",
+			ExpectedStatus: 404,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrNotFound.Error(),
+			},
+			AssignOperatorErr: customerrors.ErrNotFound,
+		},
+		{
+			Name:           "internal server error",
+			ExpectedStatus: 500,
+			ExpectedResult: map[string]interface{}{
+				"message": errors.New("internal error").Error(),
+			},
+			AssignOperatorErr: errors.New("internal error"),
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			body, err := json.Marshal(map[string]interface{}{
+				"user_id": userId.String(),
+			})
+			s.NoError(err)
+
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(body))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			c := s.echoNew
+			ctx := c.NewContext(r, w)
+			ctx.SetPath("/checkpoints/:id/operators")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(checkpointId.String())
+
+			// define mock
+			s.checkpointServiceMock.On("AssignOperator").Return(v.AssignOperatorErr)
+			s.validatorMock.On("Validate").Return(v.ValidatorErr)
+
+			err = s.checkpointController.AssignOperator(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteCheckpointController) TestRemoveOperator() {
+	checkpointId := uuid.New()
+	userId := uuid.New()
+
+	testCase := []struct {
+		Name              string
+		ExpectedStatus    int
+		ExpectedResult    map[string]interface{}
+		RemoveOperatorErr error
+	}{
+		{
+			Name:           "success remove operator",
+			ExpectedStatus: 200,
+			ExpectedResult: map[string]interface{}{
+				"message": "operator removed from checkpoint",
+			},
+		},
+		{
+			Name:           "invalid id",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrInvalidId.Error(),
+			},
+			RemoveOperatorErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:           "operator not assigned",
+			ExpectedStatus: 404,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrNotFound.Error(),
+			},
+			RemoveOperatorErr: customerrors.ErrNotFound,
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			r := httptest.NewRequest(http.MethodDelete, "/", nil)
+			w := httptest.NewRecorder()
+
+			c := s.echoNew
+			ctx := c.NewContext(r, w)
+			ctx.SetPath("/checkpoints/:id/operators/:user_id")
+			ctx.SetParamNames("id", "user_id")
+			ctx.SetParamValues(checkpointId.String(), userId.String())
+
+			// define mock
+			s.checkpointServiceMock.On("RemoveOperator").Return(v.RemoveOperatorErr)
+
+			err := s.checkpointController.RemoveOperator(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			s.TearDown()
+		})
+	}
+}
+
 func TestCheckpointController(t *testing.T) {
 	suite.Run(t, new(suiteCheckpointController))
 }
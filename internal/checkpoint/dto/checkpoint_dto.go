@@ -3,8 +3,26 @@ package dto
 import (
 	"github.com/google/uuid"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
+// CheckpointQuery is sort and filter allowed on checkpoint list
+var CheckpointQuery = query.Schema{
+	Sort: map[string]string{
+		"name":       "name",
+		"created_at": "created_at",
+	},
+	DefaultSort: "name",
+	Key:         "id",
+	Filter: map[string]query.Filter{
+		"name":        {Column: "name", Type: query.String, Operator: query.Contain},
+		"province_id": {Column: "province_id", Type: query.Uint, Operator: query.Equal},
+		"regency_id":  {Column: "regency_id", Type: query.Uint, Operator: query.Equal},
+		"district_id": {Column: "district_id", Type: query.Uint, Operator: query.Equal},
+		"village_id":  {Column: "village_id", Type: query.Uint, Operator: query.Equal},
+	},
+}
+
 type CheckpointRequest struct {
 	Name        string `json:"name" validate:"required"`
 	Description string `json:"description"`
@@ -27,6 +45,10 @@ func (u *CheckpointRequest) ToModel() *model.Checkpoint {
 	}
 }
 
+type OperatorRequest struct {
+	UserID string `json:"user_id" validate:"required"`
+}
+
 type CheckpointResponse struct {
 	ID           uuid.UUID `json:"id"`
 	Name         string    `json:"name"`
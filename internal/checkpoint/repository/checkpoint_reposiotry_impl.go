@@ -3,8 +3,13 @@ package repository
 import (
 	"context"
 
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type checkpointRepositoryImpl struct {
@@ -70,13 +75,59 @@ func (r *checkpointRepositoryImpl) CreateCheckpoint(checkpoint *model.Checkpoint
 }
 
 // FindCheckpoints implements CheckpointRepository
-func (r *checkpointRepositoryImpl) FindCheckpoints(ctx context.Context) ([]model.Checkpoint, error) {
+func (r *checkpointRepositoryImpl) FindCheckpoints(opts query.Options, ctx context.Context) ([]model.Checkpoint, int64, error) {
 	var checkpoints []model.Checkpoint
-	err := r.db.WithContext(ctx).Preload("Province").Preload("Regency").Preload("District").Preload("Village").Find(&checkpoints).Error
+	total, err := query.Find(r.db.WithContext(ctx).Model(&model.Checkpoint{}).Preload("Province").Preload("Regency").Preload("District").Preload("Village"), opts, &checkpoints)
+	if err != nil {
+		return nil, 0, err
+	}
+	return checkpoints, total, nil
+}
+
+// AssignOperator implements CheckpointRepository
+func (r *checkpointRepositoryImpl) AssignOperator(operator *model.CheckpointOperator, ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Select("id").First(&model.Checkpoint{}, "id = ?", operator.CheckpointID).Error
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return customerrors.ErrNotFound
+			}
+			return err
+		}
+		// only common user or existing operator can be assigned, admin already manage every checkpoint
+		res := tx.Model(&model.User{}).Where("id = ? AND role_id IN ?", operator.UserID, []uint{constants.Role_user, constants.Role_checkpoint_operator}).
+			Update("role_id", constants.Role_checkpoint_operator)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return customerrors.ErrInvalidOperator
+		}
+		// assign same operator twice is no-op
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(operator).Error
+	})
+}
+
+// RemoveOperator implements CheckpointRepository
+func (r *checkpointRepositoryImpl) RemoveOperator(operator *model.CheckpointOperator, ctx context.Context) error {
+	res := r.db.WithContext(ctx).Where("user_id = ? AND checkpoint_id = ?", operator.UserID, operator.CheckpointID).Delete(&model.CheckpointOperator{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return customerrors.ErrNotFound
+	}
+	return nil
+}
+
+// FindOperatorCheckpointIds implements CheckpointRepository
+func (r *checkpointRepositoryImpl) FindOperatorCheckpointIds(userId uuid.UUID, ctx context.Context) ([]uuid.UUID, error) {
+	var checkpointIds []uuid.UUID
+	err := r.db.WithContext(ctx).Model(&model.CheckpointOperator{}).Where("user_id = ?", userId).Pluck("checkpoint_id", &checkpointIds).Error
 	if err != nil {
 		return nil, err
 	}
-	return checkpoints, nil
+	return checkpointIds, nil
 }
 
 func NewCheckpointRepository(db *gorm.DB) CheckpointRepository {
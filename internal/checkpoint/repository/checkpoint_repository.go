@@ -3,14 +3,19 @@ package repository
 import (
 	"context"
 
+	"github.com/google/uuid"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
 type CheckpointRepository interface {
 	CreateCheckpoint(checkpoint *model.Checkpoint, ctx context.Context) error
-	FindCheckpoints(ctx context.Context) ([]model.Checkpoint, error)
+	FindCheckpoints(opts query.Options, ctx context.Context) ([]model.Checkpoint, int64, error)
 	FindCheckpointByProvince(id model.User, ctx context.Context) ([]model.Checkpoint, error)
 	FindCheckpointByRegency(user model.User, ctx context.Context) ([]model.Checkpoint, error)
 	FindCheckpointByDistrict(user model.User, ctx context.Context) ([]model.Checkpoint, error)
 	FindCheckpointByVilage(user model.User, ctx context.Context) ([]model.Checkpoint, error)
+	AssignOperator(operator *model.CheckpointOperator, ctx context.Context) error
+	RemoveOperator(operator *model.CheckpointOperator, ctx context.Context) error
+	FindOperatorCheckpointIds(userId uuid.UUID, ctx context.Context) ([]uuid.UUID, error)
 }
@@ -8,11 +8,15 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/checkpoint/dto"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"github.com/stretchr/testify/suite"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"net/url"
 )
 
 type suiteCheckpointRepository struct {
@@ -160,7 +164,9 @@ func (s *suiteCheckpointRepository) TestFindCheckpoints() {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.SetupSuite()
 
-			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `checkpoints` WHERE `checkpoints`.`deleted_at` IS NULL")).WillReturnError(v.ExpectedErr).WillReturnRows(v.FindCheckpointRes)
+			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `checkpoints` WHERE `checkpoints`.`deleted_at` IS NULL")).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(len(v.ExpectedRes)))
+
+			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `checkpoints` WHERE `checkpoints`.`deleted_at` IS NULL ORDER BY `name`,`id` LIMIT 20")).WillReturnError(v.ExpectedErr).WillReturnRows(v.FindCheckpointRes)
 
 			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `districts` WHERE `districts`.`id` = ?")).WillReturnRows(v.PreloadDistrictRes)
 
@@ -170,10 +176,13 @@ func (s *suiteCheckpointRepository) TestFindCheckpoints() {
 
 			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `villages` WHERE `villages`.`id` = ?")).WillReturnRows(v.PreloadVillageRes)
 
-			res, err := s.repository.FindCheckpoints(context.Background())
+			opts, err := query.Parse(url.Values{}, dto.CheckpointQuery)
+			s.NoError(err)
+			res, total, err := s.repository.FindCheckpoints(opts, context.Background())
 
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(v.ExpectedRes, res)
+			s.Equal(int64(len(v.ExpectedRes)), total)
 
 			s.TearDown()
 		})
@@ -616,6 +625,155 @@ func (s *suiteCheckpointRepository) TestFindCheckpointByProvince() {
 	}
 }
 
+func (s *suiteCheckpointRepository) TestAssignOperator() {
+	operator := model.CheckpointOperator{
+		UserID:       uuid.New(),
+		CheckpointID: uuid.New(),
+	}
+
+	testCase := []struct {
+		Name              string
+		ExpectedErr       error
+		FindCheckpointErr error
+		UpdateRoleRows    int64
+	}{
+		{
+			Name:           "success assign operator",
+			ExpectedErr:    nil,
+			UpdateRoleRows: 1,
+		},
+		{
+			Name:              "checkpoint not found",
+			ExpectedErr:       customerrors.ErrNotFound,
+			FindCheckpointErr: gorm.ErrRecordNotFound,
+		},
+		{
+			Name:           "user is admin or not exist",
+			ExpectedErr:    customerrors.ErrInvalidOperator,
+			UpdateRoleRows: 0,
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuite()
+
+			s.mock.ExpectBegin()
+			findCheckpointMock := s.mock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `checkpoints` WHERE id = ?"))
+			if v.FindCheckpointErr != nil {
+				findCheckpointMock.WillReturnError(v.FindCheckpointErr)
+				s.mock.ExpectRollback()
+			} else {
+				findCheckpointMock.WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(operator.CheckpointID))
+				s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `users` SET `role_id`=?,`updated_at`=? WHERE (id = ? AND role_id IN (?,?))")).
+					WillReturnResult(sqlmock.NewResult(0, v.UpdateRoleRows))
+				if v.UpdateRoleRows == 0 {
+					s.mock.ExpectRollback()
+				} else {
+					s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `checkpoint_operators`")).WillReturnResult(sqlmock.NewResult(1, 1))
+					s.mock.ExpectCommit()
+				}
+			}
+
+			body := operator
+			err := s.repository.AssignOperator(&body, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			s.NoError(s.mock.ExpectationsWereMet())
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteCheckpointRepository) TestRemoveOperator() {
+	operator := model.CheckpointOperator{
+		UserID:       uuid.New(),
+		CheckpointID: uuid.New(),
+	}
+
+	testCase := []struct {
+		Name         string
+		ExpectedErr  error
+		RowsAffected int64
+	}{
+		{
+			Name:         "success remove operator",
+			ExpectedErr:  nil,
+			RowsAffected: 1,
+		},
+		{
+			Name:         "operator not assigned",
+			ExpectedErr:  customerrors.ErrNotFound,
+			RowsAffected: 0,
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuite()
+
+			s.mock.ExpectBegin()
+			s.mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `checkpoint_operators` WHERE user_id = ? AND checkpoint_id = ?")).
+				WithArgs(operator.UserID, operator.CheckpointID).
+				WillReturnResult(sqlmock.NewResult(0, v.RowsAffected))
+			s.mock.ExpectCommit()
+
+			err := s.repository.RemoveOperator(&operator, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteCheckpointRepository) TestFindOperatorCheckpointIds() {
+	userId := uuid.New()
+	checkpointId := uuid.New()
+
+	testCase := []struct {
+		Name        string
+		ExpectedErr error
+		ExpectedRes []uuid.UUID
+		FindErr     error
+		FindRes     *sqlmock.Rows
+	}{
+		{
+			Name:        "success",
+			ExpectedErr: nil,
+			ExpectedRes: []uuid.UUID{checkpointId},
+			FindRes:     sqlmock.NewRows([]string{"checkpoint_id"}).AddRow(checkpointId),
+		},
+		{
+			Name:        "error",
+			ExpectedErr: errors.New("error"),
+			ExpectedRes: nil,
+			FindErr:     errors.New("error"),
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuite()
+
+			findMock := s.mock.ExpectQuery(regexp.QuoteMeta("SELECT `checkpoint_id` FROM `checkpoint_operators` WHERE user_id = ?")).WithArgs(userId)
+			if v.FindErr != nil {
+				findMock.WillReturnError(v.FindErr)
+			} else {
+				findMock.WillReturnRows(v.FindRes)
+			}
+
+			res, err := s.repository.FindOperatorCheckpointIds(userId, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			s.Equal(v.ExpectedRes, res)
+
+			s.TearDown()
+		})
+	}
+}
+
 func TestSuiteCheckpointRepository(t *testing.T) {
 	suite.Run(t, new(suiteCheckpointRepository))
 }
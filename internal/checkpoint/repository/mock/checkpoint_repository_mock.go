@@ -3,7 +3,9 @@ package mock
 import (
 	"context"
 
+	"github.com/google/uuid"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -16,9 +18,9 @@ func (b *CheckpointRepositoryMock) CreateCheckpoint(checkpoint *model.Checkpoint
 	return args.Error(0)
 }
 
-func (b *CheckpointRepositoryMock) FindCheckpoints(ctx context.Context) ([]model.Checkpoint, error) {
+func (b *CheckpointRepositoryMock) FindCheckpoints(opts query.Options, ctx context.Context) ([]model.Checkpoint, int64, error) {
 	args := b.Called()
-	return args.Get(0).([]model.Checkpoint), args.Error(1)
+	return args.Get(0).([]model.Checkpoint), args.Get(1).(int64), args.Error(2)
 }
 func (b *CheckpointRepositoryMock) FindCheckpointByProvince(id model.User, ctx context.Context) ([]model.Checkpoint, error) {
 	args := b.Called()
@@ -39,3 +41,18 @@ func (b *CheckpointRepositoryMock) FindCheckpointByVilage(user model.User, ctx c
 	args := b.Called()
 	return args.Get(0).([]model.Checkpoint), args.Error(1)
 }
+
+func (b *CheckpointRepositoryMock) AssignOperator(operator *model.CheckpointOperator, ctx context.Context) error {
+	args := b.Called(operator)
+	return args.Error(0)
+}
+
+func (b *CheckpointRepositoryMock) RemoveOperator(operator *model.CheckpointOperator, ctx context.Context) error {
+	args := b.Called(operator)
+	return args.Error(0)
+}
+
+func (b *CheckpointRepositoryMock) FindOperatorCheckpointIds(userId uuid.UUID, ctx context.Context) ([]uuid.UUID, error) {
+	args := b.Called(userId)
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
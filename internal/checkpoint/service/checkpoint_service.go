@@ -5,10 +5,13 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/checkpoint/dto"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
 type CheckpointService interface {
 	CreateCheckpoint(body dto.CheckpointRequest, ctx context.Context) (uuid.UUID, error)
-	FindCheckpoints(ctx context.Context) (dto.CheckpointsResponse, error)
+	FindCheckpoints(opts query.Options, ctx context.Context) (dto.CheckpointsResponse, *query.Page, error)
 	FindCheckpointsByUser(id string, ctx context.Context) (dto.CheckpointsResponse, error)
+	AssignOperator(checkpointId string, body dto.OperatorRequest, ctx context.Context) error
+	RemoveOperator(checkpointId string, userId string, ctx context.Context) error
 }
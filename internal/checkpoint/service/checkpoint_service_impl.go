@@ -8,7 +8,9 @@ import (
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/checkpoint/dto"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/checkpoint/repository"
 	urp "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/user/repository"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
 type checkpointServiceImpl struct {
@@ -22,7 +24,10 @@ func (s *checkpointServiceImpl) FindCheckpointsByUser(id string, ctx context.Con
 	if err != nil {
 		return nil, customerrors.ErrInvalidId
 	}
-	user, _ := s.userRepo.FindUserByID(id, ctx)
+	user, err := s.userRepo.FindUserByID(id, ctx)
+	if err != nil {
+		return nil, err
+	}
 	var checkpointsResponse dto.CheckpointsResponse
 
 	checkpoints1, err := s.repo.FindCheckpointByVilage(*user, ctx)
@@ -81,14 +86,47 @@ func (s *checkpointServiceImpl) CreateCheckpoint(body dto.CheckpointRequest, ctx
 }
 
 // FindCheckpoints implements CheckpointService
-func (s *checkpointServiceImpl) FindCheckpoints(ctx context.Context) (dto.CheckpointsResponse, error) {
-	checkpoints, err := s.repo.FindCheckpoints(ctx)
+func (s *checkpointServiceImpl) FindCheckpoints(opts query.Options, ctx context.Context) (dto.CheckpointsResponse, *query.Page, error) {
+	checkpoints, total, err := s.repo.FindCheckpoints(opts, ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	var checkpointsResponse dto.CheckpointsResponse
 	checkpointsResponse.FromModel(checkpoints)
-	return checkpointsResponse, nil
+	return checkpointsResponse, query.NewPage(opts, total), nil
+}
+
+// AssignOperator implements CheckpointService
+func (s *checkpointServiceImpl) AssignOperator(checkpointId string, body dto.OperatorRequest, ctx context.Context) error {
+	operator, err := newCheckpointOperator(checkpointId, body.UserID)
+	if err != nil {
+		return err
+	}
+	return s.repo.AssignOperator(operator, ctx)
+}
+
+// RemoveOperator implements CheckpointService
+func (s *checkpointServiceImpl) RemoveOperator(checkpointId string, userId string, ctx context.Context) error {
+	operator, err := newCheckpointOperator(checkpointId, userId)
+	if err != nil {
+		return err
+	}
+	return s.repo.RemoveOperator(operator, ctx)
+}
+
+func newCheckpointOperator(checkpointId string, userId string) (*model.CheckpointOperator, error) {
+	checkpointUUID, err := uuid.Parse(checkpointId)
+	if err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	return &model.CheckpointOperator{
+		UserID:       userUUID,
+		CheckpointID: checkpointUUID,
+	}, nil
 }
 
 func NewCheckpointService(repository repository.CheckpointRepository, userRepo urp.UserRepository) CheckpointService {
@@ -11,6 +11,7 @@ import (
 	userRepositoryMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/user/repository/mock"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -135,12 +136,16 @@ func (s *suiteCheckpointService) TestFindCheckpoints() {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.SetupSuit()
 
-			s.checkpointRepositoryMock.On("FindCheckpoints").Return(v.FindCheckpointsRes, v.FindCheckpointsErr)
+			s.checkpointRepositoryMock.On("FindCheckpoints").Return(v.FindCheckpointsRes, int64(len(v.FindCheckpointsRes)), v.FindCheckpointsErr)
 
-			res, err := s.checkpointService.FindCheckpoints(context.Background())
+			opts := query.Options{Page: 1, Limit: 20}
+			res, page, err := s.checkpointService.FindCheckpoints(opts, context.Background())
 
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(v.ExpectedRes, res)
+			if v.ExpectedErr == nil {
+				s.Equal(&query.Page{Page: 1, Limit: 20, Total: 1, TotalPages: 1}, page)
+			}
 
 			s.TearDown()
 		})
@@ -293,6 +298,22 @@ func (s *suiteCheckpointService) TestFindCheckpointsByUser() {
 			FindCheckpointsByProvinceErr: nil,
 			FindCheckpointsByProvinceRes: []model.Checkpoint{},
 		},
+		{
+			Name:                         "user not found",
+			ExpectedRes:                  dto.CheckpointsResponse(nil),
+			ExpectedErr:                  customerrors.ErrNotFound,
+			UserId:                       userId.String(),
+			FindUserByIdErr:              customerrors.ErrNotFound,
+			FindUserByIdRes:              (*model.User)(nil),
+			FindCheckpointsByVillageErr:  nil,
+			FindCheckpointsByVillageRes:  []model.Checkpoint{},
+			FindCheckpointsByDistrictErr: nil,
+			FindCheckpointsByDistrictRes: []model.Checkpoint{},
+			FindCheckpointsByRegencyErr:  nil,
+			FindCheckpointsByRegencyRes:  []model.Checkpoint{},
+			FindCheckpointsByProvinceErr: nil,
+			FindCheckpointsByProvinceRes: []model.Checkpoint{},
+		},
 		{
 			Name:            "error find by village",
 			ExpectedRes:     dto.CheckpointsResponse(nil),
@@ -390,6 +411,113 @@ func (s *suiteCheckpointService) TestFindCheckpointsByUser() {
 	}
 }
 
+func (s *suiteCheckpointService) TestAssignOperator() {
+	checkpointId := uuid.New()
+	userId := uuid.New()
+
+	testCase := []struct {
+		Name              string
+		CheckpointId      string
+		Body              dto.OperatorRequest
+		ExpectedErr       error
+		ExpectRepoCalled  bool
+		AssignOperatorErr error
+	}{
+		{
+			Name:             "success",
+			CheckpointId:     checkpointId.String(),
+			Body:             dto.OperatorRequest{UserID: userId.String()},
+			ExpectedErr:      nil,
+			ExpectRepoCalled: true,
+		},
+		{
+			Name:         "invalid checkpoint id",
+			CheckpointId: "invalid",
+			Body:         dto.OperatorRequest{UserID: userId.String()},
+			ExpectedErr:  customerrors.ErrInvalidId,
+		},
+		{
+			Name:         "invalid user id",
+			CheckpointId: checkpointId.String(),
+			Body:         dto.OperatorRequest{UserID: "invalid"},
+			ExpectedErr:  customerrors.ErrInvalidId,
+		},
+		{
+			Name:              "user cant be operator",
+			CheckpointId:      checkpointId.String(),
+			Body:              dto.OperatorRequest{UserID: userId.String()},
+			ExpectedErr:       customerrors.ErrInvalidOperator,
+			ExpectRepoCalled:  true,
+			AssignOperatorErr: customerrors.ErrInvalidOperator,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			operator := &model.CheckpointOperator{UserID: userId, CheckpointID: checkpointId}
+			s.checkpointRepositoryMock.On("AssignOperator", operator).Return(v.AssignOperatorErr)
+
+			err := s.checkpointService.AssignOperator(v.CheckpointId, v.Body, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			if v.ExpectRepoCalled {
+				s.checkpointRepositoryMock.AssertCalled(t, "AssignOperator", operator)
+			} else {
+				s.checkpointRepositoryMock.AssertNotCalled(t, "AssignOperator", operator)
+			}
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteCheckpointService) TestRemoveOperator() {
+	checkpointId := uuid.New()
+	userId := uuid.New()
+
+	testCase := []struct {
+		Name              string
+		CheckpointId      string
+		UserId            string
+		ExpectedErr       error
+		RemoveOperatorErr error
+	}{
+		{
+			Name:         "success",
+			CheckpointId: checkpointId.String(),
+			UserId:       userId.String(),
+			ExpectedErr:  nil,
+		},
+		{
+			Name:         "invalid user id",
+			CheckpointId: checkpointId.String(),
+			UserId:       "invalid",
+			ExpectedErr:  customerrors.ErrInvalidId,
+		},
+		{
+			Name:              "operator not assigned",
+			CheckpointId:      checkpointId.String(),
+			UserId:            userId.String(),
+			ExpectedErr:       customerrors.ErrNotFound,
+			RemoveOperatorErr: customerrors.ErrNotFound,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			s.checkpointRepositoryMock.On("RemoveOperator", &model.CheckpointOperator{UserID: userId, CheckpointID: checkpointId}).Return(v.RemoveOperatorErr)
+
+			err := s.checkpointService.RemoveOperator(v.CheckpointId, v.UserId, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+
+			s.TearDown()
+		})
+	}
+}
+
 func TestSuiteCheckpointService(t *testing.T) {
 	suite.Run(t, new(suiteCheckpointService))
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/checkpoint/dto"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -17,9 +18,9 @@ func (b *CheckpointServiceMock) CreateCheckpoint(body dto.CheckpointRequest, ctx
 	return args.Get(0).(uuid.UUID), args.Error(1)
 }
 
-func (b *CheckpointServiceMock) FindCheckpoints(ctx context.Context) (dto.CheckpointsResponse, error) {
+func (b *CheckpointServiceMock) FindCheckpoints(opts query.Options, ctx context.Context) (dto.CheckpointsResponse, *query.Page, error) {
 	args := b.Called()
-	return args.Get(0).(dto.CheckpointsResponse), args.Error(1)
+	return args.Get(0).(dto.CheckpointsResponse), args.Get(1).(*query.Page), args.Error(2)
 
 }
 
@@ -27,3 +28,13 @@ func (b *CheckpointServiceMock) FindCheckpointsByUser(id string, ctx context.Con
 	args := b.Called()
 	return args.Get(0).(dto.CheckpointsResponse), args.Error(1)
 }
+
+func (b *CheckpointServiceMock) AssignOperator(checkpointId string, body dto.OperatorRequest, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *CheckpointServiceMock) RemoveOperator(checkpointId string, userId string, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
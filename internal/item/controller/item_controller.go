@@ -1,14 +1,21 @@
 package controller
 
 import (
+	"fmt"
+	"io"
 	"net/http"
+	"path/filepath"
+	"strings"
 
 	"github.com/golang-jwt/jwt"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/item/dto"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/item/service"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	_middleware "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/middleware"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
 type JWTService interface {
@@ -28,24 +35,40 @@ func NewItemController(service service.ItemService, jwt JWTService) *itemControl
 
 func (u *itemController) InitRoute(auth *echo.Group) {
 	items := auth.Group("/items")
-	items.POST("", u.CreateItem)
-	items.GET("", u.GetItems)
-	items.PUT("/:id", u.UpdateItem)
+	items.POST("", u.CreateItem, _middleware.RequirePermission(constants.Permission_item_manage))
+	items.GET("", u.GetItems, _middleware.RequirePermission(constants.Permission_item_read))
+	items.GET("/search", u.SearchItems, _middleware.RequirePermission(constants.Permission_item_read))
+	items.GET("/archived", u.GetArchivedItems, _middleware.RequirePermission(constants.Permission_item_manage))
+	items.POST("/import", u.ImportItems, _middleware.RequirePermission(constants.Permission_item_manage), middleware.BodyLimit(constants.Import_body_limit))
+	items.GET("/export", u.ExportItems, _middleware.RequirePermission(constants.Permission_item_manage))
+	items.PUT("/:id", u.UpdateItem, _middleware.RequirePermission(constants.Permission_item_manage))
+	items.DELETE("/:id", u.DeleteItem, _middleware.RequirePermission(constants.Permission_item_manage))
+	items.PUT("/:id/restore", u.RestoreItem, _middleware.RequirePermission(constants.Permission_item_manage))
+	items.PUT("/:id/image", u.UploadItemImage, _middleware.RequirePermission(constants.Permission_item_manage), middleware.BodyLimit(constants.Image_body_limit))
+	items.POST("/:id/variants", u.CreateVariant, _middleware.RequirePermission(constants.Permission_item_manage))
+	items.PUT("/:id/variants/:variant_id", u.UpdateVariant, _middleware.RequirePermission(constants.Permission_item_manage))
+	items.DELETE("/:id/variants/:variant_id", u.DeleteVariant, _middleware.RequirePermission(constants.Permission_item_manage))
+	items.GET("/units", u.GetUnits, _middleware.RequirePermission(constants.Permission_item_read))
+	items.GET("/stock/report", u.GetStockReport, _middleware.RequirePermission(constants.Permission_item_manage))
+	items.GET("/stock/expiring", u.GetExpiringBatches, _middleware.RequirePermission(constants.Permission_item_manage))
+	items.POST("/:id/stock", u.CreateStockMovement, _middleware.RequirePermission(constants.Permission_item_manage))
+	items.GET("/:id/stock", u.GetStockMovements, _middleware.RequirePermission(constants.Permission_item_manage))
+	items.POST("/:id/stock/transfer", u.TransferStock, _middleware.RequirePermission(constants.Permission_item_manage))
+	items.GET("/:id/stock/checkpoints", u.GetItemStocks, _middleware.RequirePermission(constants.Permission_item_manage))
 
 	categories := items.Group("/categories")
-	categories.POST("", u.CreateCategory)
-	categories.GET("", u.GetCategories)
-	categories.GET("/:id", u.GetItemsByCategory)
+	categories.POST("", u.CreateCategory, _middleware.RequirePermission(constants.Permission_item_manage))
+	categories.GET("", u.GetCategories, _middleware.RequirePermission(constants.Permission_item_read))
+	categories.GET("/archived", u.GetArchivedCategories, _middleware.RequirePermission(constants.Permission_item_manage))
+	categories.POST("/import", u.ImportCategories, _middleware.RequirePermission(constants.Permission_item_manage), middleware.BodyLimit(constants.Import_body_limit))
+	categories.GET("/export", u.ExportCategories, _middleware.RequirePermission(constants.Permission_item_manage))
+	categories.GET("/:id", u.GetItemsByCategory, _middleware.RequirePermission(constants.Permission_item_read))
+	categories.DELETE("/:id", u.DeleteCategory, _middleware.RequirePermission(constants.Permission_item_manage))
+	categories.PUT("/:id/restore", u.RestoreCategory, _middleware.RequirePermission(constants.Permission_item_manage))
+	categories.PUT("/:id/image", u.UploadCategoryImage, _middleware.RequirePermission(constants.Permission_item_manage), middleware.BodyLimit(constants.Image_body_limit))
 }
 
 func (u *itemController) CreateItem(c echo.Context) error {
-	claims := u.jwtService.GetClaims(&c)
-	role := claims["role_id"].(float64)
-	if role != constants.Role_admin {
-		return c.JSON(http.StatusForbidden, echo.Map{
-			"message": customerrors.ErrPermission.Error(),
-		})
-	}
 	var itemBody dto.ItemRequest
 	if err := c.Bind(&itemBody); err != nil {
 		return c.JSON(http.StatusBadRequest, echo.Map{
@@ -55,7 +78,9 @@ func (u *itemController) CreateItem(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, echo.Map{
 			"message": err.Error()})
 	}
-	id, err := u.service.CreateItem(itemBody, c.Request().Context())
+	claims := u.jwtService.GetClaims(&c)
+	userId := claims["user_id"].(string)
+	id, err := u.service.CreateItem(itemBody, userId, c.Request().Context())
 	if err != nil {
 		if err == customerrors.ErrBadRequestBody || err == customerrors.ErrDuplicateData {
 			return c.JSON(http.StatusBadRequest, echo.Map{
@@ -72,13 +97,6 @@ func (u *itemController) CreateItem(c echo.Context) error {
 }
 
 func (u *itemController) CreateCategory(c echo.Context) error {
-	claims := u.jwtService.GetClaims(&c)
-	role := claims["role_id"].(float64)
-	if role != constants.Role_admin {
-		return c.JSON(http.StatusForbidden, echo.Map{
-			"message": customerrors.ErrPermission.Error(),
-		})
-	}
 	var categoryBody dto.CategoryRequest
 	if err := c.Bind(&categoryBody); err != nil {
 		return c.JSON(http.StatusBadRequest, echo.Map{
@@ -105,13 +123,6 @@ func (u *itemController) CreateCategory(c echo.Context) error {
 }
 
 func (u *itemController) UpdateItem(c echo.Context) error {
-	claims := u.jwtService.GetClaims(&c)
-	role := claims["role_id"].(float64)
-	if role != constants.Role_admin {
-		return c.JSON(http.StatusForbidden, echo.Map{
-			"message": customerrors.ErrPermission.Error(),
-		})
-	}
 	id := c.Param("id")
 	var itemBody dto.ItemRequest
 	if err := c.Bind(&itemBody); err != nil {
@@ -121,7 +132,9 @@ func (u *itemController) UpdateItem(c echo.Context) error {
 	if err := c.Validate(itemBody); err != nil {
 		return err
 	}
-	err := u.service.UpdateItem(id, itemBody, c.Request().Context())
+	claims := u.jwtService.GetClaims(&c)
+	userId := claims["user_id"].(string)
+	err := u.service.UpdateItem(id, itemBody, userId, c.Request().Context())
 	if err != nil {
 		if err == customerrors.ErrBadRequestBody || err == customerrors.ErrDuplicateData || err == customerrors.ErrInvalidId {
 			return c.JSON(http.StatusBadRequest, echo.Map{
@@ -136,37 +149,624 @@ func (u *itemController) UpdateItem(c echo.Context) error {
 	})
 }
 
+func (u *itemController) DeleteItem(c echo.Context) error {
+	err := u.service.DeleteItem(c.Param("id"), c.Request().Context())
+	if err != nil {
+		return archiveError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "success archive item",
+	})
+}
+
+func (u *itemController) RestoreItem(c echo.Context) error {
+	err := u.service.RestoreItem(c.Param("id"), c.Request().Context())
+	if err != nil {
+		return archiveError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "success restore item",
+	})
+}
+
+func (u *itemController) GetArchivedItems(c echo.Context) error {
+	opts, err := query.Parse(c.QueryParams(), dto.ArchivedItemQuery)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	items, page, err := u.service.FindArchivedItems(opts, c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message":    "get archived items success",
+		"data":       items,
+		"pagination": page,
+	})
+}
+
+func (u *itemController) DeleteCategory(c echo.Context) error {
+	err := u.service.DeleteCategory(c.Param("id"), c.Request().Context())
+	if err != nil {
+		return archiveError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "success archive category",
+	})
+}
+
+func (u *itemController) RestoreCategory(c echo.Context) error {
+	err := u.service.RestoreCategory(c.Param("id"), c.Request().Context())
+	if err != nil {
+		return archiveError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "success restore category",
+	})
+}
+
+func (u *itemController) GetArchivedCategories(c echo.Context) error {
+	opts, err := query.Parse(c.QueryParams(), dto.ArchivedCategoryQuery)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	categories, page, err := u.service.FindArchivedCategories(opts, c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message":    "get archived categories success",
+		"data":       categories,
+		"pagination": page,
+	})
+}
+
+func (u *itemController) ImportItems(c echo.Context) error {
+	data, body, err := readImport(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	}
+	claims := u.jwtService.GetClaims(&c)
+	userId := claims["user_id"].(string)
+	res, err := u.service.ImportItems(data, body, userId, c.Request().Context())
+	return importResult(c, "items", res, err)
+}
+
+func (u *itemController) ImportCategories(c echo.Context) error {
+	data, body, err := readImport(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	}
+	res, err := u.service.ImportCategories(data, body, c.Request().Context())
+	return importResult(c, "categories", res, err)
+}
+
+func (u *itemController) ExportItems(c echo.Context) error {
+	var body dto.ExportRequest
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &body); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrFileFormat.Error()})
+	}
+	if body.Format == "" {
+		body.Format = constants.File_format_csv
+	}
+	data, err := u.service.ExportItems(body, c.Request().Context())
+	if err != nil {
+		return exportError(c, err)
+	}
+	return exportFile(c, "items", body.Format, data)
+}
+
+func (u *itemController) ExportCategories(c echo.Context) error {
+	var body dto.ExportRequest
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &body); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrFileFormat.Error()})
+	}
+	if body.Format == "" {
+		body.Format = constants.File_format_csv
+	}
+	data, err := u.service.ExportCategories(body, c.Request().Context())
+	if err != nil {
+		return exportError(c, err)
+	}
+	return exportFile(c, "categories", body.Format, data)
+}
+
+// readImport read uploaded file, format is taken from file extension when not in query
+func readImport(c echo.Context) ([]byte, dto.ImportRequest, error) {
+	var body dto.ImportRequest
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &body); err != nil {
+		return nil, body, customerrors.ErrBadRequestBody
+	}
+	file, err := c.FormFile("file")
+	if err != nil || file.Size > constants.Import_max_size {
+		return nil, body, customerrors.ErrImportFile
+	}
+	if body.Format == "" {
+		body.Format = strings.ToLower(strings.TrimPrefix(filepath.Ext(file.Filename), "."))
+	}
+	src, err := file.Open()
+	if err != nil {
+		return nil, body, customerrors.ErrImportFile
+	}
+	defer src.Close()
+	data, err := io.ReadAll(io.LimitReader(src, constants.Import_max_size+1))
+	if err != nil || len(data) > constants.Import_max_size {
+		return nil, body, customerrors.ErrImportFile
+	}
+	return data, body, nil
+}
+
+func importResult(c echo.Context, name string, res *dto.ImportResponse, err error) error {
+	switch err {
+	case nil:
+		message := "success import " + name
+		if res.DryRun {
+			message = "success check import " + name + ", nothing is saved"
+		}
+		return c.JSON(http.StatusOK, echo.Map{
+			"message": message,
+			"data":    res,
+		})
+	case customerrors.ErrImportInvalid:
+		return c.JSON(http.StatusUnprocessableEntity, echo.Map{
+			"message": err.Error(),
+			"data":    res,
+		})
+	case customerrors.ErrFileFormat, customerrors.ErrImportHeader, customerrors.ErrImportRows, customerrors.ErrDuplicateData:
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"message": err.Error(),
+	})
+}
+
+func exportError(c echo.Context, err error) error {
+	if err == customerrors.ErrFileFormat {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"message": err.Error(),
+	})
+}
+
+func exportFile(c echo.Context, name string, format string, data []byte) error {
+	contentType := constants.Content_type_csv
+	if format == constants.File_format_xlsx {
+		contentType = constants.Content_type_xlsx
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+	return c.Blob(http.StatusOK, contentType, data)
+}
+
+func archiveError(c echo.Context, err error) error {
+	switch err {
+	case customerrors.ErrInvalidId:
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	case customerrors.ErrNotFound:
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"message": err.Error()})
+	case customerrors.ErrCategoryNotEmpty, customerrors.ErrCategoryArchived:
+		return c.JSON(http.StatusConflict, echo.Map{
+			"message": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"message": err.Error(),
+	})
+}
+
+func (u *itemController) CreateVariant(c echo.Context) error {
+	var variantBody dto.ItemVariantRequest
+	if err := c.Bind(&variantBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrBadRequestBody.Error()})
+	}
+	if err := c.Validate(variantBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	}
+	claims := u.jwtService.GetClaims(&c)
+	userId := claims["user_id"].(string)
+	id, err := u.service.CreateVariant(c.Param("id"), variantBody, userId, c.Request().Context())
+	if err != nil {
+		return variantError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "new variant success created",
+		"id":      id,
+	})
+}
+
+func (u *itemController) UpdateVariant(c echo.Context) error {
+	var variantBody dto.ItemVariantRequest
+	if err := c.Bind(&variantBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrBadRequestBody.Error()})
+	}
+	if err := c.Validate(variantBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	}
+	claims := u.jwtService.GetClaims(&c)
+	userId := claims["user_id"].(string)
+	err := u.service.UpdateVariant(c.Param("id"), c.Param("variant_id"), variantBody, userId, c.Request().Context())
+	if err != nil {
+		return variantError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "success update variant",
+	})
+}
+
+func (u *itemController) DeleteVariant(c echo.Context) error {
+	err := u.service.DeleteVariant(c.Param("id"), c.Param("variant_id"), c.Request().Context())
+	if err != nil {
+		return variantError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "success delete variant",
+	})
+}
+
+func (u *itemController) CreateStockMovement(c echo.Context) error {
+	var moveBody dto.StockMovementRequest
+	if err := c.Bind(&moveBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrBadRequestBody.Error()})
+	}
+	if err := c.Validate(moveBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	}
+	claims := u.jwtService.GetClaims(&c)
+	userId := claims["user_id"].(string)
+	move, err := u.service.CreateStockMovement(c.Param("id"), moveBody, userId, c.Request().Context())
+	if err != nil {
+		if err == customerrors.ErrInvalidId || err == customerrors.ErrStockQty || err == customerrors.ErrStockNotEnough ||
+			err == customerrors.ErrInvalidVariant || err == customerrors.ErrStockBatch {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"message": err.Error()})
+		}
+		if err == customerrors.ErrNotFound || err == customerrors.ErrCheckpointNotFound {
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"message": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "success create stock movement",
+		"data":    move,
+	})
+}
+
+func (u *itemController) TransferStock(c echo.Context) error {
+	var transferBody dto.StockTransferRequest
+	if err := c.Bind(&transferBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrBadRequestBody.Error()})
+	}
+	if err := c.Validate(transferBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	}
+	claims := u.jwtService.GetClaims(&c)
+	userId := claims["user_id"].(string)
+	moves, err := u.service.TransferStock(c.Param("id"), transferBody, userId, c.Request().Context())
+	if err != nil {
+		if err == customerrors.ErrInvalidId || err == customerrors.ErrStockQty || err == customerrors.ErrStockNotEnough ||
+			err == customerrors.ErrInvalidVariant || err == customerrors.ErrStockTransfer {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"message": err.Error()})
+		}
+		if err == customerrors.ErrNotFound || err == customerrors.ErrCheckpointNotFound {
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"message": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "success transfer stock",
+		"data":    moves,
+	})
+}
+
+func (u *itemController) GetItemStocks(c echo.Context) error {
+	stocks, err := u.service.FindItemStocks(c.Param("id"), c.Request().Context())
+	if err != nil {
+		if err == customerrors.ErrInvalidId {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"message": err.Error()})
+		}
+		if err == customerrors.ErrNotFound {
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"message": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "success get item stocks",
+		"data":    stocks,
+	})
+}
+
+func (u *itemController) GetStockMovements(c echo.Context) error {
+	opts, err := query.Parse(c.QueryParams(), dto.StockMovementQuery)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	moves, page, err := u.service.FindStockMovements(c.Param("id"), opts, c.Request().Context())
+	if err != nil {
+		if err == customerrors.ErrInvalidId {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"message": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message":    "success get stock movements",
+		"data":       moves,
+		"pagination": page,
+	})
+}
+
+func (u *itemController) GetExpiringBatches(c echo.Context) error {
+	body := dto.ExpiringBatchRequest{Days: constants.Batch_expiring_days}
+	if err := c.Bind(&body); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrInvalidFilter.Error()})
+	}
+	batches, err := u.service.FindExpiringBatches(body, c.Request().Context())
+	if err != nil {
+		if err == customerrors.ErrInvalidFilter {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"message": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "success get expiring batches",
+		"data":    batches,
+	})
+}
+
+func (u *itemController) GetStockReport(c echo.Context) error {
+	var body dto.StockReportRequest
+	if err := c.Bind(&body); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrBadRequestBody.Error()})
+	}
+	report, err := u.service.StockReport(body, c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "success get stock report",
+		"data":    report,
+	})
+}
+
+func (u *itemController) GetUnits(c echo.Context) error {
+	units, err := u.service.FindUnits(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "success get units",
+		"data":    units,
+	})
+}
+
+func variantError(c echo.Context, err error) error {
+	if err == customerrors.ErrInvalidId {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	}
+	if err == customerrors.ErrNotFound {
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"message": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"message": err.Error(),
+	})
+}
+
+func (u *itemController) UploadItemImage(c echo.Context) error {
+	data, err := readImage(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	}
+	image, err := u.service.UploadItemImage(c.Param("id"), data, c.Request().Context())
+	if err != nil {
+		return imageError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "success upload item image",
+		"data":    image,
+	})
+}
+
+func (u *itemController) UploadCategoryImage(c echo.Context) error {
+	data, err := readImage(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	}
+	image, err := u.service.UploadCategoryImage(c.Param("id"), data, c.Request().Context())
+	if err != nil {
+		return imageError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "success upload category image",
+		"data":    image,
+	})
+}
+
+// readImage read image field of multipart form, size is checked before whole file is read
+func readImage(c echo.Context) ([]byte, error) {
+	file, err := c.FormFile("image")
+	if err != nil {
+		return nil, customerrors.ErrImageRequired
+	}
+	if file.Size > constants.Image_max_size {
+		return nil, customerrors.ErrImageTooLarge
+	}
+	src, err := file.Open()
+	if err != nil {
+		return nil, customerrors.ErrImageRequired
+	}
+	defer src.Close()
+	data, err := io.ReadAll(io.LimitReader(src, constants.Image_max_size+1))
+	if err != nil {
+		return nil, customerrors.ErrImageRequired
+	}
+	return data, nil
+}
+
+func imageError(c echo.Context, err error) error {
+	if err == customerrors.ErrInvalidId || err == customerrors.ErrImageType || err == customerrors.ErrImageTooLarge {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	}
+	if err == customerrors.ErrNotFound {
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"message": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"message": err.Error(),
+	})
+}
+
 func (u *itemController) GetItems(c echo.Context) error {
-	items, err := u.service.FindItems(c.Request().Context())
+	opts, err := query.Parse(c.QueryParams(), dto.ItemQuery)
 	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	var availabilityBody dto.ItemAvailabilityRequest
+	if err := c.Bind(&availabilityBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrBadRequestBody.Error()})
+	}
+	claims := u.jwtService.GetClaims(&c)
+	userId := claims["user_id"].(string)
+	items, page, err := u.service.FindItems(opts, availabilityBody, userId, c.Request().Context())
+	if err != nil {
+		if err == customerrors.ErrInvalidId || err == customerrors.ErrCheckpointNotCovered {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"message": err.Error()})
+		}
+		if err == customerrors.ErrNotFound {
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"message": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message":    "get items success",
+		"data":       items,
+		"pagination": page,
+	})
+}
+
+func (u *itemController) SearchItems(c echo.Context) error {
+	var searchBody dto.ItemSearchRequest
+	if err := c.Bind(&searchBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrInvalidFilter.Error()})
+	}
+	if err := c.Validate(searchBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	}
+	opts, err := query.Parse(c.QueryParams(), dto.ItemSearchQuery)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	res, page, err := u.service.SearchItems(searchBody, opts, c.Request().Context())
+	if err != nil {
+		if err == customerrors.ErrInvalidSearch || err == customerrors.ErrInvalidFilter {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"message": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, echo.Map{
 			"message": err.Error(),
 		})
 	}
 	return c.JSON(http.StatusOK, echo.Map{
-		"message": "get items success",
-		"data":    items,
+		"message":    "search items success",
+		"data":       res.Items,
+		"facets":     res.Facets,
+		"pagination": page,
 	})
 }
 
 func (u *itemController) GetCategories(c echo.Context) error {
-	categories, err := u.service.FindCategories(c.Request().Context())
+	opts, err := query.Parse(c.QueryParams(), dto.CategoryQuery)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	categories, page, err := u.service.FindCategories(opts, c.Request().Context())
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{
 			"message": err.Error(),
 		})
 	}
 	return c.JSON(http.StatusOK, echo.Map{
-		"message": "get categories success",
-		"data":    categories,
+		"message":    "get categories success",
+		"data":       categories,
+		"pagination": page,
 	})
 }
 
 func (u *itemController) GetItemsByCategory(c echo.Context) error {
 	paramId := c.Param("id")
-	items, err := u.service.FindItemsByCategory(paramId, c.Request().Context())
+	opts, err := query.Parse(c.QueryParams(), dto.ItemQuery)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	items, page, err := u.service.FindItemsByCategory(paramId, opts, c.Request().Context())
 	if err != nil {
-		if err == customerrors.ErrBadRequestBody {
+		if err == customerrors.ErrInvalidId {
 			return c.JSON(http.StatusBadRequest, echo.Map{
 				"message": err.Error()})
 		}
@@ -175,7 +775,8 @@ func (u *itemController) GetItemsByCategory(c echo.Context) error {
 		})
 	}
 	return c.JSON(http.StatusOK, echo.Map{
-		"message": "get items success",
-		"data":    items,
+		"message":    "get items success",
+		"data":       items,
+		"pagination": page,
 	})
 }
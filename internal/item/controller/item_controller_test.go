@@ -4,17 +4,21 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/item/dto"
 	ism "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/item/service/mock"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
 	mm "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/middleware/mock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	vm "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/validator/mock"
 	"github.com/stretchr/testify/suite"
 )
@@ -35,6 +39,9 @@ func (s *suiteItemController) SetupSuit() {
 	s.itemController = NewItemController(s.itemServiceMock, s.JWTServiceMock)
 	s.echoNew = echo.New()
 	s.echoNew.Validator = s.validatorMock
+	s.JWTServiceMock.On("GetClaims").Return(jwt.MapClaims{
+		"user_id": "a4ac7f1e-4a1a-4f6c-9d2a-1f0f5d1b2c3d",
+	})
 }
 
 func (s *suiteItemController) TearDown() {
@@ -75,25 +82,6 @@ func (s *suiteItemController) TestCreateItem() {
 			CreateItemErr: nil,
 			CreateItemRes: 1,
 		},
-		{
-			Name:           "forbidden",
-			ExpectedStatus: 403,
-			ExpectedResult: map[string]interface{}{
-				"message": customerrors.ErrPermission.Error(),
-			},
-			Body: map[string]interface{}{
-				"name":        "item",
-				"description": "test",
-				"category_id": 1,
-				"qty":         1,
-			},
-			JWTReturn: jwt.MapClaims{
-				"role_id": float64(100),
-			},
-			ValidatorErr:  nil,
-			CreateItemErr: nil,
-			CreateItemRes: 0,
-		},
 		{
 			Name:           "invalid body type",
 			ExpectedStatus: 400,
@@ -255,23 +243,6 @@ func (s *suiteItemController) TestCreateCategory() {
 			CreateCategoryErr: nil,
 			CreateCategoryRes: 1,
 		},
-		{
-			Name:           "forbidden",
-			ExpectedStatus: 403,
-			ExpectedResult: map[string]interface{}{
-				"message": customerrors.ErrPermission.Error(),
-			},
-			Body: map[string]interface{}{
-				"name":        "item",
-				"description": "test",
-			},
-			JWTReturn: jwt.MapClaims{
-				"role_id": float64(100),
-			},
-			ValidatorErr:      nil,
-			CreateCategoryErr: nil,
-			CreateCategoryRes: 0,
-		},
 		{
 			Name:           "bad body request",
 			ExpectedStatus: 400,
@@ -376,6 +347,71 @@ func (s *suiteItemController) TestCreateCategory() {
 		})
 	}
 }
+
+var listPage = &query.Page{Page: 1, Limit: 20, Total: 1, TotalPages: 1}
+
+var listPageResult = map[string]interface{}{
+	"page":        float64(1),
+	"limit":       float64(20),
+	"total":       float64(1),
+	"total_pages": float64(1),
+}
+
+func (s *suiteItemController) TestGetItemsInvalidQuery() {
+	s.SetupSuit()
+	defer s.TearDown()
+
+	testCase := []struct {
+		Name    string
+		Query   string
+		Handler func(c echo.Context) error
+		Message string
+	}{
+		{
+			Name:    "sort not allowed",
+			Query:   "sort=description",
+			Handler: s.itemController.GetItems,
+			Message: customerrors.ErrInvalidSort.Error(),
+		},
+		{
+			Name:    "invalid page",
+			Query:   "page=0",
+			Handler: s.itemController.GetItemsByCategory,
+			Message: customerrors.ErrInvalidPage.Error(),
+		},
+		{
+			Name:    "invalid filter",
+			Query:   "min_price=cheap",
+			Handler: s.itemController.GetItems,
+			Message: customerrors.ErrInvalidFilter.Error(),
+		},
+		{
+			Name:    "invalid category sort",
+			Query:   "sort=-price",
+			Handler: s.itemController.GetCategories,
+			Message: customerrors.ErrInvalidSort.Error(),
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/?"+v.Query, nil)
+			w := httptest.NewRecorder()
+			ctx := s.echoNew.NewContext(r, w)
+
+			err := v.Handler(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(http.StatusBadRequest, w.Result().StatusCode)
+			s.Equal(map[string]interface{}{"message": v.Message}, controllerResult)
+		})
+	}
+}
+
 func (s *suiteItemController) TestGetItems() {
 	testCase := []struct {
 		Name           string
@@ -396,9 +432,15 @@ func (s *suiteItemController) TestGetItems() {
 						"name":          "item",
 						"price":         float64(0),
 						"qty":           float64(0),
+						"image_url":     "",
+						"thumbnail_url": "",
+						"unit":          "",
+						"unit_step":     float64(0),
+						"variants":      nil,
 					},
 				},
-				"message": "get items success",
+				"message":    "get items success",
+				"pagination": listPageResult,
 			},
 			FindItemsErr: nil,
 			FindItemsRes: dto.ItemsResponse{
@@ -417,12 +459,22 @@ func (s *suiteItemController) TestGetItems() {
 			FindItemsErr: errors.New("error"),
 			FindItemsRes: dto.ItemsResponse{},
 		},
+		{
+			Name:           "user not covered by checkpoint",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrCheckpointNotCovered.Error(),
+			},
+			FindItemsErr: customerrors.ErrCheckpointNotCovered,
+			FindItemsRes: dto.ItemsResponse{},
+		},
 		{
 			Name:           "nil items",
 			ExpectedStatus: 200,
 			ExpectedResult: map[string]interface{}{
-				"data":    []interface{}{},
-				"message": "get items success",
+				"data":       []interface{}{},
+				"message":    "get items success",
+				"pagination": listPageResult,
 			},
 			FindItemsErr: nil,
 			FindItemsRes: dto.ItemsResponse{},
@@ -442,7 +494,7 @@ func (s *suiteItemController) TestGetItems() {
 			ctx.SetPath("/items")
 
 			// define mock
-			s.itemServiceMock.On("FindItems").Return(v.FindItemsRes, v.FindItemsErr)
+			s.itemServiceMock.On("FindItems").Return(v.FindItemsRes, listPage, v.FindItemsErr)
 
 			err := s.itemController.GetItems(ctx)
 			s.NoError(err)
@@ -472,12 +524,15 @@ func (s *suiteItemController) TestGetCategories() {
 			ExpectedResult: map[string]interface{}{
 				"data": []interface{}{
 					map[string]interface{}{
-						"id":          float64(1),
-						"name":        "category",
-						"description": "test",
+						"id":            float64(1),
+						"name":          "category",
+						"description":   "test",
+						"image_url":     "",
+						"thumbnail_url": "",
 					},
 				},
-				"message": "get categories success",
+				"message":    "get categories success",
+				"pagination": listPageResult,
 			},
 			FindCategoryErr: nil,
 			FindCategoryRes: dto.CategoriesResponse{
@@ -501,8 +556,9 @@ func (s *suiteItemController) TestGetCategories() {
 			Name:           "nil categories",
 			ExpectedStatus: 200,
 			ExpectedResult: map[string]interface{}{
-				"data":    []interface{}{},
-				"message": "get categories success",
+				"data":       []interface{}{},
+				"message":    "get categories success",
+				"pagination": listPageResult,
 			},
 			FindCategoryErr: nil,
 			FindCategoryRes: dto.CategoriesResponse{},
@@ -522,7 +578,7 @@ func (s *suiteItemController) TestGetCategories() {
 			ctx.SetPath("/items")
 
 			// define mock
-			s.itemServiceMock.On("FindCategories").Return(v.FindCategoryRes, v.FindCategoryErr)
+			s.itemServiceMock.On("FindCategories").Return(v.FindCategoryRes, listPage, v.FindCategoryErr)
 
 			err := s.itemController.GetCategories(ctx)
 			s.NoError(err)
@@ -559,9 +615,15 @@ func (s *suiteItemController) TestGetItemByCategory() {
 						"name":          "item",
 						"price":         float64(0),
 						"qty":           float64(0),
+						"image_url":     "",
+						"thumbnail_url": "",
+						"unit":          "",
+						"unit_step":     float64(0),
+						"variants":      nil,
 					},
 				},
-				"message": "get items success",
+				"message":    "get items success",
+				"pagination": listPageResult,
 			},
 			ParamId:                "1",
 			FindItemsByCategoryErr: nil,
@@ -573,13 +635,13 @@ func (s *suiteItemController) TestGetItemByCategory() {
 			},
 		},
 		{
-			Name:           "bad request",
+			Name:           "invalid id",
 			ExpectedStatus: 400,
 			ExpectedResult: map[string]interface{}{
-				"message": customerrors.ErrBadRequestBody.Error(),
+				"message": customerrors.ErrInvalidId.Error(),
 			},
-			ParamId:                "1",
-			FindItemsByCategoryErr: customerrors.ErrBadRequestBody,
+			ParamId:                "a",
+			FindItemsByCategoryErr: customerrors.ErrInvalidId,
 			FindItemsByCategoryRes: dto.ItemsResponse{},
 		},
 		{
@@ -596,8 +658,9 @@ func (s *suiteItemController) TestGetItemByCategory() {
 			Name:           "nil items",
 			ExpectedStatus: 200,
 			ExpectedResult: map[string]interface{}{
-				"data":    []interface{}{},
-				"message": "get items success",
+				"data":       []interface{}{},
+				"message":    "get items success",
+				"pagination": listPageResult,
 			},
 			FindItemsByCategoryErr: nil,
 			FindItemsByCategoryRes: dto.ItemsResponse{},
@@ -619,7 +682,7 @@ func (s *suiteItemController) TestGetItemByCategory() {
 			ctx.SetParamValues(v.ParamId)
 
 			// define mock
-			s.itemServiceMock.On("FindItemsByCategory").Return(v.FindItemsByCategoryRes, v.FindItemsByCategoryErr)
+			s.itemServiceMock.On("FindItemsByCategory").Return(v.FindItemsByCategoryRes, listPage, v.FindItemsByCategoryErr)
 
 			err := s.itemController.GetItemsByCategory(ctx)
 			s.NoError(err)
@@ -636,6 +699,1032 @@ func (s *suiteItemController) TestGetItemByCategory() {
 	}
 }
 
+func (s *suiteItemController) TestSearchItems() {
+	testCase := []struct {
+		Name           string
+		Query          string
+		ExpectedStatus int
+		ExpectedResult map[string]interface{}
+		ValidatorErr   error
+		SearchItemsErr error
+		SearchItemsRes *dto.ItemSearchResponse
+	}{
+		{
+			Name:           "search items success",
+			Query:          "q=cabe&in_stock=true&sort=-price",
+			ExpectedStatus: 200,
+			ExpectedResult: map[string]interface{}{
+				"data": []interface{}{
+					map[string]interface{}{
+						"category_name": "sayur",
+						"description":   "",
+						"id":            float64(1),
+						"name":          "Cabai Merah",
+						"price":         float64(0),
+						"qty":           float64(0),
+						"image_url":     "",
+						"thumbnail_url": "",
+						"unit":          "",
+						"unit_step":     float64(0),
+						"variants":      nil,
+					},
+				},
+				"facets": []interface{}{
+					map[string]interface{}{
+						"category_id":   float64(1),
+						"category_name": "sayur",
+						"count":         float64(1),
+					},
+				},
+				"message":    "search items success",
+				"pagination": listPageResult,
+			},
+			SearchItemsRes: &dto.ItemSearchResponse{
+				Items:  dto.ItemsResponse{{ID: 1, Name: "Cabai Merah", CategoryName: "sayur"}},
+				Facets: dto.CategoryFacetsResponse{{CategoryID: 1, CategoryName: "sayur", Count: 1}},
+			},
+		},
+		{
+			Name:           "invalid price",
+			Query:          "q=cabe&min_price=murah",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrInvalidFilter.Error(),
+			},
+		},
+		{
+			Name:           "validator error",
+			Query:          "",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": "validator error",
+			},
+			ValidatorErr: errors.New("validator error"),
+		},
+		{
+			Name:           "sort not allowed",
+			Query:          "q=cabe&sort=qty",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrInvalidSort.Error(),
+			},
+		},
+		{
+			Name:           "no word to search",
+			Query:          "q=%21%21",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrInvalidSearch.Error(),
+			},
+			SearchItemsErr: customerrors.ErrInvalidSearch,
+		},
+		{
+			Name:           "error",
+			Query:          "q=cabe",
+			ExpectedStatus: 500,
+			ExpectedResult: map[string]interface{}{
+				"message": errors.New("error").Error(),
+			},
+			SearchItemsErr: errors.New("error"),
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			r := httptest.NewRequest(http.MethodGet, "/?"+v.Query, nil)
+			w := httptest.NewRecorder()
+
+			c := s.echoNew
+			ctx := c.NewContext(r, w)
+			ctx.SetPath("/items/search")
+
+			// define mock
+			s.validatorMock.On("Validate").Return(v.ValidatorErr)
+			s.itemServiceMock.On("SearchItems").Return(v.SearchItemsRes, listPage, v.SearchItemsErr)
+
+			err := s.itemController.SearchItems(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			s.TearDown()
+		})
+	}
+}
+
+func newImageForm(t *testing.T, field string, data []byte) (*bytes.Buffer, string) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile(field, "cabai.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data)
+	writer.Close()
+	return body, writer.FormDataContentType()
+}
+
+func (s *suiteItemController) TestUploadImage() {
+	testCase := []struct {
+		Name           string
+		Field          string
+		Data           []byte
+		Handler        func(u *itemController) func(c echo.Context) error
+		Method         string
+		ExpectedStatus int
+		ExpectedResult map[string]interface{}
+		UploadErr      error
+		UploadRes      *dto.ImageResponse
+	}{
+		{
+			Name:           "upload item image success",
+			Field:          "image",
+			Data:           []byte("image"),
+			Handler:        func(u *itemController) func(c echo.Context) error { return u.UploadItemImage },
+			Method:         "UploadItemImage",
+			ExpectedStatus: 200,
+			ExpectedResult: map[string]interface{}{
+				"message": "success upload item image",
+				"data": map[string]interface{}{
+					"image_url":     "/uploads/items/1/a.png",
+					"thumbnail_url": "/uploads/items/1/a_thumb.png",
+				},
+			},
+			UploadRes: &dto.ImageResponse{ImageURL: "/uploads/items/1/a.png", ThumbnailURL: "/uploads/items/1/a_thumb.png"},
+		},
+		{
+			Name:           "upload category image success",
+			Field:          "image",
+			Data:           []byte("image"),
+			Handler:        func(u *itemController) func(c echo.Context) error { return u.UploadCategoryImage },
+			Method:         "UploadCategoryImage",
+			ExpectedStatus: 200,
+			ExpectedResult: map[string]interface{}{
+				"message": "success upload category image",
+				"data": map[string]interface{}{
+					"image_url":     "/uploads/categories/1/a.png",
+					"thumbnail_url": "/uploads/categories/1/a_thumb.png",
+				},
+			},
+			UploadRes: &dto.ImageResponse{ImageURL: "/uploads/categories/1/a.png", ThumbnailURL: "/uploads/categories/1/a_thumb.png"},
+		},
+		{
+			Name:           "without image field",
+			Field:          "file",
+			Data:           []byte("image"),
+			Handler:        func(u *itemController) func(c echo.Context) error { return u.UploadItemImage },
+			Method:         "UploadItemImage",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrImageRequired.Error(),
+			},
+		},
+		{
+			Name:           "too large",
+			Field:          "image",
+			Data:           make([]byte, constants.Image_max_size+1),
+			Handler:        func(u *itemController) func(c echo.Context) error { return u.UploadItemImage },
+			Method:         "UploadItemImage",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrImageTooLarge.Error(),
+			},
+		},
+		{
+			Name:           "invalid image type",
+			Field:          "image",
+			Data:           []byte("image"),
+			Handler:        func(u *itemController) func(c echo.Context) error { return u.UploadItemImage },
+			Method:         "UploadItemImage",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrImageType.Error(),
+			},
+			UploadErr: customerrors.ErrImageType,
+		},
+		{
+			Name:           "category not found",
+			Field:          "image",
+			Data:           []byte("image"),
+			Handler:        func(u *itemController) func(c echo.Context) error { return u.UploadCategoryImage },
+			Method:         "UploadCategoryImage",
+			ExpectedStatus: 404,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrNotFound.Error(),
+			},
+			UploadErr: customerrors.ErrNotFound,
+		},
+		{
+			Name:           "storage error",
+			Field:          "image",
+			Data:           []byte("image"),
+			Handler:        func(u *itemController) func(c echo.Context) error { return u.UploadItemImage },
+			Method:         "UploadItemImage",
+			ExpectedStatus: 500,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrStorage.Error(),
+			},
+			UploadErr: customerrors.ErrStorage,
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			body, contentType := newImageForm(t, v.Field, v.Data)
+			r := httptest.NewRequest(http.MethodPut, "/", body)
+			r.Header.Set(echo.HeaderContentType, contentType)
+			w := httptest.NewRecorder()
+
+			ctx := s.echoNew.NewContext(r, w)
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1")
+
+			// define mock
+			s.itemServiceMock.On(v.Method).Return(v.UploadRes, v.UploadErr)
+
+			err := v.Handler(s.itemController)(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteItemController) TestCreateVariant() {
+	testCase := []struct {
+		Name             string
+		ExpectedStatus   int
+		ExpectedResult   map[string]interface{}
+		Body             map[string]interface{}
+		ValidatorErr     error
+		CreateVariantErr error
+		CreateVariantRes uint
+	}{
+		{
+			Name:           "success create",
+			ExpectedStatus: 200,
+			ExpectedResult: map[string]interface{}{
+				"id":      float64(1),
+				"message": "new variant success created",
+			},
+			Body:             map[string]interface{}{"name": "organik", "qty": 1.5, "price": 18000},
+			CreateVariantRes: 1,
+		},
+		{
+			Name:           "invalid body type",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrBadRequestBody.Error(),
+			},
+			Body: map[string]interface{}{"name": "organik", "qty": "aa"},
+		},
+		{
+			Name:           "validator error",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": "validator error",
+			},
+			Body:         map[string]interface{}{"qty": 1},
+			ValidatorErr: errors.New("validator error"),
+		},
+		{
+			Name:           "item not found",
+			ExpectedStatus: 404,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrNotFound.Error(),
+			},
+			Body:             map[string]interface{}{"name": "organik", "qty": 1},
+			CreateVariantErr: customerrors.ErrNotFound,
+		},
+		{
+			Name:           "internal server error",
+			ExpectedStatus: 500,
+			ExpectedResult: map[string]interface{}{
+				"message": "internal error",
+			},
+			Body:             map[string]interface{}{"name": "organik", "qty": 1},
+			CreateVariantErr: errors.New("internal error"),
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			body, err := json.Marshal(v.Body)
+			s.NoError(err)
+
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(body))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			ctx := s.echoNew.NewContext(r, w)
+			ctx.SetPath("/items/:id/variants")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1")
+
+			s.itemServiceMock.On("CreateVariant").Return(v.CreateVariantRes, v.CreateVariantErr)
+			s.validatorMock.On("Validate").Return(v.ValidatorErr)
+
+			err = s.itemController.CreateVariant(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteItemController) TestDeleteVariant() {
+	testCase := []struct {
+		Name             string
+		ExpectedStatus   int
+		ExpectedResult   map[string]interface{}
+		DeleteVariantErr error
+	}{
+		{
+			Name:           "success delete",
+			ExpectedStatus: 200,
+			ExpectedResult: map[string]interface{}{
+				"message": "success delete variant",
+			},
+		},
+		{
+			Name:           "invalid id",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrInvalidId.Error(),
+			},
+			DeleteVariantErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:           "variant not found",
+			ExpectedStatus: 404,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrNotFound.Error(),
+			},
+			DeleteVariantErr: customerrors.ErrNotFound,
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			r := httptest.NewRequest(http.MethodDelete, "/", nil)
+			w := httptest.NewRecorder()
+
+			ctx := s.echoNew.NewContext(r, w)
+			ctx.SetPath("/items/:id/variants/:variant_id")
+			ctx.SetParamNames("id", "variant_id")
+			ctx.SetParamValues("1", "2")
+
+			s.itemServiceMock.On("DeleteVariant").Return(v.DeleteVariantErr)
+
+			err := s.itemController.DeleteVariant(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteItemController) TestDeleteCategory() {
+	testCase := []struct {
+		Name              string
+		ExpectedStatus    int
+		ExpectedResult    map[string]interface{}
+		DeleteCategoryErr error
+	}{
+		{
+			Name:           "success archive",
+			ExpectedStatus: 200,
+			ExpectedResult: map[string]interface{}{
+				"message": "success archive category",
+			},
+		},
+		{
+			Name:           "category not found",
+			ExpectedStatus: 404,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrNotFound.Error(),
+			},
+			DeleteCategoryErr: customerrors.ErrNotFound,
+		},
+		{
+			Name:           "category still has item",
+			ExpectedStatus: 409,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrCategoryNotEmpty.Error(),
+			},
+			DeleteCategoryErr: customerrors.ErrCategoryNotEmpty,
+		},
+		{
+			Name:           "internal server error",
+			ExpectedStatus: 500,
+			ExpectedResult: map[string]interface{}{
+				"message": "internal error",
+			},
+			DeleteCategoryErr: errors.New("internal error"),
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			r := httptest.NewRequest(http.MethodDelete, "/", nil)
+			w := httptest.NewRecorder()
+
+			ctx := s.echoNew.NewContext(r, w)
+			ctx.SetPath("/items/categories/:id")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1")
+
+			s.itemServiceMock.On("DeleteCategory").Return(v.DeleteCategoryErr)
+
+			err := s.itemController.DeleteCategory(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteItemController) TestRestoreItem() {
+	testCase := []struct {
+		Name           string
+		ExpectedStatus int
+		ExpectedResult map[string]interface{}
+		RestoreItemErr error
+	}{
+		{
+			Name:           "success restore",
+			ExpectedStatus: 200,
+			ExpectedResult: map[string]interface{}{
+				"message": "success restore item",
+			},
+		},
+		{
+			Name:           "invalid id",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrInvalidId.Error(),
+			},
+			RestoreItemErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:           "category archived",
+			ExpectedStatus: 409,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrCategoryArchived.Error(),
+			},
+			RestoreItemErr: customerrors.ErrCategoryArchived,
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			r := httptest.NewRequest(http.MethodPut, "/", nil)
+			w := httptest.NewRecorder()
+
+			ctx := s.echoNew.NewContext(r, w)
+			ctx.SetPath("/items/:id/restore")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1")
+
+			s.itemServiceMock.On("RestoreItem").Return(v.RestoreItemErr)
+
+			err := s.itemController.RestoreItem(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteItemController) TestGetArchivedItems() {
+	archivedAt := time.Date(2022, 11, 1, 8, 0, 0, 0, time.UTC)
+	r := httptest.NewRequest(http.MethodGet, "/?sort=name", nil)
+	w := httptest.NewRecorder()
+	s.SetupSuit()
+	ctx := s.echoNew.NewContext(r, w)
+	ctx.SetPath("/items/archived")
+
+	s.itemServiceMock.On("FindArchivedItems").Return(dto.ItemsResponse{
+		{ID: 1, Name: "item", Variants: dto.ItemVariantsResponse{}, ArchivedAt: &archivedAt},
+	}, listPage, nil)
+
+	s.NoError(s.itemController.GetArchivedItems(ctx))
+
+	controllerResult := map[string]interface{}{}
+	s.NoError(json.NewDecoder(w.Result().Body).Decode(&controllerResult))
+	s.Equal(http.StatusOK, w.Result().StatusCode)
+	s.Equal("2022-11-01T08:00:00Z", controllerResult["data"].([]interface{})[0].(map[string]interface{})["archived_at"])
+	s.TearDown()
+}
+
+func (s *suiteItemController) TestGetUnits() {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	s.SetupSuit()
+	ctx := s.echoNew.NewContext(r, w)
+	ctx.SetPath("/items/units")
+
+	s.itemServiceMock.On("FindUnits").Return(dto.UnitsResponse{{ID: 1, Name: "kilogram", Symbol: "kg", Step: 0.25}}, nil)
+
+	s.NoError(s.itemController.GetUnits(ctx))
+
+	controllerResult := map[string]interface{}{}
+	s.NoError(json.NewDecoder(w.Result().Body).Decode(&controllerResult))
+	s.Equal(http.StatusOK, w.Result().StatusCode)
+	s.Equal(map[string]interface{}{
+		"message": "success get units",
+		"data": []interface{}{
+			map[string]interface{}{"id": float64(1), "name": "kilogram", "symbol": "kg", "step": 0.25},
+		},
+	}, controllerResult)
+	s.TearDown()
+}
+
+func (s *suiteItemController) TestTransferStock() {
+	checkpointId := uuid.New()
+	testCase := []struct {
+		Name           string
+		ExpectedStatus int
+		ExpectedResult map[string]interface{}
+		Body           map[string]interface{}
+		TransferErr    error
+		TransferRes    dto.StockMovementsResponse
+	}{
+		{
+			Name:           "success transfer",
+			ExpectedStatus: 200,
+			Body:           map[string]interface{}{"to_checkpoint_id": checkpointId.String(), "qty": 2},
+			TransferRes: dto.StockMovementsResponse{
+				{ID: 1, ItemID: 1, Qty: -2, Balance: 3, Reason: "transfer"},
+				{ID: 2, ItemID: 1, CheckpointID: &checkpointId, Qty: 2, Balance: 5, Reason: "transfer"},
+			},
+		},
+		{
+			Name:           "invalid body type",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrBadRequestBody.Error(),
+			},
+			Body: map[string]interface{}{"qty": "aa"},
+		},
+		{
+			Name:           "same checkpoint",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrStockTransfer.Error(),
+			},
+			Body:        map[string]interface{}{"qty": 2},
+			TransferErr: customerrors.ErrStockTransfer,
+		},
+		{
+			Name:           "checkpoint not found",
+			ExpectedStatus: 404,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrCheckpointNotFound.Error(),
+			},
+			Body:        map[string]interface{}{"to_checkpoint_id": checkpointId.String(), "qty": 2},
+			TransferErr: customerrors.ErrCheckpointNotFound,
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			body, err := json.Marshal(v.Body)
+			s.NoError(err)
+
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(body))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			ctx := s.echoNew.NewContext(r, w)
+			ctx.SetPath("/items/:id/stock/transfer")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1")
+
+			s.itemServiceMock.On("TransferStock").Return(v.TransferRes, v.TransferErr)
+			s.validatorMock.On("Validate").Return(nil)
+
+			err = s.itemController.TransferStock(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			if v.ExpectedResult != nil {
+				s.Equal(v.ExpectedResult, controllerResult)
+			} else {
+				s.Len(controllerResult["data"], 2)
+			}
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteItemController) TestCreateStockMovement() {
+	testCase := []struct {
+		Name           string
+		ExpectedStatus int
+		ExpectedResult map[string]interface{}
+		Body           map[string]interface{}
+		ValidatorErr   error
+		CreateMoveErr  error
+		CreateMoveRes  *dto.StockMovementResponse
+	}{
+		{
+			Name:           "success create",
+			ExpectedStatus: 200,
+			Body:           map[string]interface{}{"reason": "spoilage", "qty": 1.5},
+			CreateMoveRes:  &dto.StockMovementResponse{ID: 1, ItemID: 1, Qty: -1.5, Balance: 2, Reason: "spoilage"},
+		},
+		{
+			Name:           "invalid body type",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrBadRequestBody.Error(),
+			},
+			Body: map[string]interface{}{"reason": "spoilage", "qty": "aa"},
+		},
+		{
+			Name:           "validator error",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": "validator error",
+			},
+			Body:         map[string]interface{}{"reason": "sale", "qty": 1},
+			ValidatorErr: errors.New("validator error"),
+		},
+		{
+			Name:           "stock not enough",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrStockNotEnough.Error(),
+			},
+			Body:          map[string]interface{}{"reason": "spoilage", "qty": 10},
+			CreateMoveErr: customerrors.ErrStockNotEnough,
+		},
+		{
+			Name:           "item not found",
+			ExpectedStatus: 404,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrNotFound.Error(),
+			},
+			Body:          map[string]interface{}{"reason": "purchase", "qty": 1},
+			CreateMoveErr: customerrors.ErrNotFound,
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			body, err := json.Marshal(v.Body)
+			s.NoError(err)
+
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(body))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			ctx := s.echoNew.NewContext(r, w)
+			ctx.SetPath("/items/:id/stock")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1")
+
+			s.itemServiceMock.On("CreateStockMovement").Return(v.CreateMoveRes, v.CreateMoveErr)
+			s.validatorMock.On("Validate").Return(v.ValidatorErr)
+
+			err = s.itemController.CreateStockMovement(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			if v.ExpectedResult != nil {
+				s.Equal(v.ExpectedResult, controllerResult)
+			} else {
+				s.Equal(-1.5, controllerResult["data"].(map[string]interface{})["qty"])
+			}
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteItemController) TestGetStockReport() {
+	r := httptest.NewRequest(http.MethodGet, "/?mismatch=true", nil)
+	w := httptest.NewRecorder()
+	s.SetupSuit()
+	ctx := s.echoNew.NewContext(r, w)
+	ctx.SetPath("/items/stock/report")
+
+	s.itemServiceMock.On("StockReport", dto.StockReportRequest{Mismatch: true}).Return(&dto.StockReportResponse{
+		Checked:    2,
+		Mismatched: 1,
+		Rows:       []dto.StockReportRow{{ItemID: 1, ItemName: "cabai", Balance: 3, LedgerTotal: 2.5, Difference: 0.5}},
+	}, nil)
+
+	s.NoError(s.itemController.GetStockReport(ctx))
+
+	controllerResult := map[string]interface{}{}
+	s.NoError(json.NewDecoder(w.Result().Body).Decode(&controllerResult))
+	s.Equal(http.StatusOK, w.Result().StatusCode)
+	s.Equal(map[string]interface{}{
+		"message": "success get stock report",
+		"data": map[string]interface{}{
+			"checked":    float64(2),
+			"mismatched": float64(1),
+			"rows": []interface{}{
+				map[string]interface{}{"item_id": float64(1), "variant_id": nil, "item_name": "cabai", "balance": float64(3), "ledger_total": 2.5, "difference": 0.5},
+			},
+		},
+	}, controllerResult)
+	s.TearDown()
+}
+
+func (s *suiteItemController) TestGetExpiringBatches() {
+	testCase := []struct {
+		Name           string
+		Query          string
+		ExpectedStatus int
+		ExpectedBody   dto.ExpiringBatchRequest
+		FindBatchErr   error
+	}{
+		{
+			Name:           "default days",
+			ExpectedStatus: 200,
+			ExpectedBody:   dto.ExpiringBatchRequest{Days: constants.Batch_expiring_days},
+		},
+		{
+			Name:           "asked days",
+			Query:          "?days=0",
+			ExpectedStatus: 200,
+			ExpectedBody:   dto.ExpiringBatchRequest{Days: 0},
+		},
+		{
+			Name:           "invalid days",
+			Query:          "?days=satu",
+			ExpectedStatus: 400,
+		},
+		{
+			Name:           "negative days",
+			Query:          "?days=-1",
+			ExpectedStatus: 400,
+			ExpectedBody:   dto.ExpiringBatchRequest{Days: -1},
+			FindBatchErr:   customerrors.ErrInvalidFilter,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+			r := httptest.NewRequest(http.MethodGet, "/"+v.Query, nil)
+			w := httptest.NewRecorder()
+			ctx := s.echoNew.NewContext(r, w)
+			ctx.SetPath("/items/stock/expiring")
+
+			s.itemServiceMock.On("FindExpiringBatches", v.ExpectedBody).Return(dto.ExpiringBatchesResponse{{ID: 1, ItemName: "bayam", Qty: 1}}, v.FindBatchErr)
+
+			s.NoError(s.itemController.GetExpiringBatches(ctx))
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			if v.ExpectedStatus == http.StatusOK {
+				s.itemServiceMock.AssertCalled(t, "FindExpiringBatches", v.ExpectedBody)
+			}
+
+			s.TearDown()
+		})
+	}
+}
+
+func newImportForm(t *testing.T, filename string, data []byte) (*bytes.Buffer, string) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data)
+	writer.Close()
+	return body, writer.FormDataContentType()
+}
+
+func (s *suiteItemController) TestImportItems() {
+	testCase := []struct {
+		Name           string
+		Query          string
+		Filename       string
+		Data           []byte
+		ExpectedStatus int
+		ExpectedResult map[string]interface{}
+		ExpectedBody   dto.ImportRequest
+		ImportRes      *dto.ImportResponse
+		ImportErr      error
+	}{
+		{
+			Name:           "success",
+			Filename:       "items.CSV",
+			Data:           []byte("name\nbayam\n"),
+			ExpectedStatus: 200,
+			ExpectedResult: map[string]interface{}{
+				"message": "success import items",
+				"data":    map[string]interface{}{"dry_run": false, "created": float64(1), "updated": float64(0), "errors": []interface{}{}},
+			},
+			ExpectedBody: dto.ImportRequest{Format: "csv"},
+			ImportRes:    &dto.ImportResponse{Created: 1, Errors: []dto.ImportRowError{}},
+		},
+		{
+			Name:           "dry run with format in query",
+			Query:          "?format=xlsx&dry_run=true",
+			Filename:       "items",
+			Data:           []byte("xlsx"),
+			ExpectedStatus: 200,
+			ExpectedResult: map[string]interface{}{
+				"message": "success check import items, nothing is saved",
+				"data":    map[string]interface{}{"dry_run": true, "created": float64(0), "updated": float64(2), "errors": []interface{}{}},
+			},
+			ExpectedBody: dto.ImportRequest{Format: "xlsx", DryRun: true},
+			ImportRes:    &dto.ImportResponse{DryRun: true, Updated: 2, Errors: []dto.ImportRowError{}},
+		},
+		{
+			Name:           "invalid row",
+			Filename:       "items.csv",
+			Data:           []byte("name\n\"\"\n"),
+			ExpectedStatus: 422,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrImportInvalid.Error(),
+				"data": map[string]interface{}{"dry_run": false, "created": float64(0), "updated": float64(0), "errors": []interface{}{
+					map[string]interface{}{"row": float64(2), "message": customerrors.ErrRowName.Error()},
+				}},
+			},
+			ExpectedBody: dto.ImportRequest{Format: "csv"},
+			ImportRes:    &dto.ImportResponse{Errors: []dto.ImportRowError{{Row: 2, Message: customerrors.ErrRowName.Error()}}},
+			ImportErr:    customerrors.ErrImportInvalid,
+		},
+		{
+			Name:           "unknown format",
+			Filename:       "items.pdf",
+			Data:           []byte("pdf"),
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrFileFormat.Error(),
+			},
+			ExpectedBody: dto.ImportRequest{Format: "pdf"},
+			ImportErr:    customerrors.ErrFileFormat,
+		},
+		{
+			Name:           "too large",
+			Filename:       "items.csv",
+			Data:           make([]byte, constants.Import_max_size+1),
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrImportFile.Error(),
+			},
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			body, contentType := newImportForm(t, v.Filename, v.Data)
+			r := httptest.NewRequest(http.MethodPost, "/"+v.Query, body)
+			r.Header.Set(echo.HeaderContentType, contentType)
+			w := httptest.NewRecorder()
+			ctx := s.echoNew.NewContext(r, w)
+
+			// define mock
+			s.itemServiceMock.On("ImportItems", v.ExpectedBody).Return(v.ImportRes, v.ImportErr)
+
+			err := s.itemController.ImportItems(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteItemController) TestExportCategories() {
+	testCase := []struct {
+		Name                string
+		Query               string
+		ExpectedStatus      int
+		ExpectedContentType string
+		ExpectedFilename    string
+		ExpectedBody        dto.ExportRequest
+		ExportErr           error
+	}{
+		{
+			Name:                "default csv",
+			ExpectedStatus:      200,
+			ExpectedContentType: constants.Content_type_csv,
+			ExpectedFilename:    `attachment; filename="categories.csv"`,
+			ExpectedBody:        dto.ExportRequest{Format: "csv"},
+		},
+		{
+			Name:                "xlsx",
+			Query:               "?format=xlsx",
+			ExpectedStatus:      200,
+			ExpectedContentType: constants.Content_type_xlsx,
+			ExpectedFilename:    `attachment; filename="categories.xlsx"`,
+			ExpectedBody:        dto.ExportRequest{Format: "xlsx"},
+		},
+		{
+			Name:                "unknown format",
+			Query:               "?format=pdf",
+			ExpectedStatus:      400,
+			ExpectedContentType: echo.MIMEApplicationJSONCharsetUTF8,
+			ExpectedBody:        dto.ExportRequest{Format: "pdf"},
+			ExportErr:           customerrors.ErrFileFormat,
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			r := httptest.NewRequest(http.MethodGet, "/"+v.Query, nil)
+			w := httptest.NewRecorder()
+			ctx := s.echoNew.NewContext(r, w)
+
+			// define mock
+			s.itemServiceMock.On("ExportCategories", v.ExpectedBody).Return([]byte("name\nsayur\n"), v.ExportErr)
+
+			err := s.itemController.ExportCategories(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedContentType, w.Header().Get(echo.HeaderContentType))
+			s.Equal(v.ExpectedFilename, w.Header().Get(echo.HeaderContentDisposition))
+
+			s.TearDown()
+		})
+	}
+}
+
 func TestItemController(t *testing.T) {
 	suite.Run(t, new(suiteItemController))
 }
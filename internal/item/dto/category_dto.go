@@ -1,9 +1,38 @@
 package dto
 
 import (
+	"time"
+
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
+// CategoryQuery is sort and filter allowed on category list
+var CategoryQuery = query.Schema{
+	Sort: map[string]string{
+		"name":       "name",
+		"created_at": "created_at",
+	},
+	DefaultSort: "name",
+	Key:         "id",
+	Filter: map[string]query.Filter{
+		"name": {Column: "name", Type: query.String, Operator: query.Contain},
+	},
+}
+
+// ArchivedCategoryQuery is sort and filter allowed on archived category list
+var ArchivedCategoryQuery = query.Schema{
+	Sort: map[string]string{
+		"name":        "name",
+		"archived_at": "deleted_at",
+	},
+	DefaultSort: "-archived_at",
+	Key:         "id",
+	Filter: map[string]query.Filter{
+		"name": {Column: "name", Type: query.String, Operator: query.Contain},
+	},
+}
+
 type CategoryRequest struct {
 	Name        string `json:"name" validate:"required"`
 	Description string `json:"description"`
@@ -17,15 +46,23 @@ func (u *CategoryRequest) ToModel() *model.Category {
 }
 
 type CategoryResponse struct {
-	ID          uint   `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	ID           uint       `json:"id"`
+	Name         string     `json:"name"`
+	Description  string     `json:"description"`
+	ImageURL     string     `json:"image_url"`
+	ThumbnailURL string     `json:"thumbnail_url"`
+	ArchivedAt   *time.Time `json:"archived_at,omitempty"`
 }
 
 func (u *CategoryResponse) FromModel(model *model.Category) {
 	u.ID = model.ID
 	u.Name = model.Name
 	u.Description = model.Description
+	u.ImageURL = model.Image.ImageURL
+	u.ThumbnailURL = model.Image.ThumbnailURL
+	if model.DeletedAt.Valid {
+		u.ArchivedAt = &model.DeletedAt.Time
+	}
 }
 
 type CategoriesResponse []CategoryResponse
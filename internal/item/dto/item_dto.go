@@ -1,35 +1,88 @@
 package dto
 
 import (
+	"time"
+
+	"github.com/google/uuid"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/quantity"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
+// ItemQuery is sort and filter allowed on item list
+var ItemQuery = query.Schema{
+	Sort: map[string]string{
+		"name":       "name",
+		"price":      "price",
+		"qty":        "qty",
+		"created_at": "created_at",
+	},
+	DefaultSort: "name",
+	Key:         "id",
+	Filter: map[string]query.Filter{
+		"name":        {Column: "name", Type: query.String, Operator: query.Contain},
+		"category_id": {Column: "category_id", Type: query.Uint, Operator: query.Equal},
+		"min_price":   {Column: "price", Type: query.Uint, Operator: query.From},
+		"max_price":   {Column: "price", Type: query.Uint, Operator: query.Until},
+	},
+}
+
+// ArchivedItemQuery is sort and filter allowed on archived item list
+var ArchivedItemQuery = query.Schema{
+	Sort: map[string]string{
+		"name":        "name",
+		"archived_at": "deleted_at",
+	},
+	DefaultSort: "-archived_at",
+	Key:         "id",
+	Filter: map[string]query.Filter{
+		"name":        {Column: "name", Type: query.String, Operator: query.Contain},
+		"category_id": {Column: "category_id", Type: query.Uint, Operator: query.Equal},
+	},
+}
+
+// ItemRequest qty is counted stock, on update its difference with current stock is saved as
+// stock adjustment and missing qty keep current stock
 type ItemRequest struct {
-	ID          uint   `json:"id"`
-	Name        string `json:"name" validate:"required"`
-	Description string `json:"description"`
-	Qty         int    `json:"qty"`
-	Price       int    `json:"price"`
-	CategoryID  uint   `json:"category_id" validate:"required"`
+	ID          uint     `json:"id"`
+	Name        string   `json:"name" validate:"required"`
+	Description string   `json:"description"`
+	Qty         *float64 `json:"qty" validate:"omitempty,gte=0"`
+	Price       int      `json:"price" validate:"gte=0"`
+	CategoryID  uint     `json:"category_id" validate:"required"`
+	UnitID      uint     `json:"unit_id"`
 }
 
 func (u *ItemRequest) ToModel() *model.Item {
-	return &model.Item{
+	item := &model.Item{
 		Name:        u.Name,
 		Description: u.Description,
-		Qty:         u.Qty,
 		Price:       u.Price,
 		CategoryID:  u.CategoryID,
+		UnitID:      u.UnitID,
 	}
+	if u.Qty != nil {
+		item.Qty = *u.Qty
+	}
+	return item
 }
 
 type ItemResponse struct {
-	ID           uint   `json:"id"`
-	Name         string `json:"name"`
-	Description  string `json:"description"`
-	Qty          int    `json:"qty"`
-	Price        int    `json:"price"`
-	CategoryName string `json:"category_name"`
+	ID           uint                 `json:"id"`
+	Name         string               `json:"name"`
+	Description  string               `json:"description"`
+	Qty          float64              `json:"qty"`
+	Price        int                  `json:"price"`
+	Unit         string               `json:"unit"`
+	UnitStep     float64              `json:"unit_step"`
+	CategoryName string               `json:"category_name"`
+	ImageURL     string               `json:"image_url"`
+	ThumbnailURL string               `json:"thumbnail_url"`
+	Variants     ItemVariantsResponse `json:"variants"`
+	ArchivedAt   *time.Time           `json:"archived_at,omitempty"`
+	// stock of the checkpoint asked in ItemAvailabilityRequest, qty is total of every checkpoint
+	CheckpointID *uuid.UUID `json:"checkpoint_id,omitempty"`
+	Available    *float64   `json:"available,omitempty"`
 }
 
 func (u *ItemResponse) FromModel(model *model.Item) {
@@ -38,7 +91,27 @@ func (u *ItemResponse) FromModel(model *model.Item) {
 	u.Description = model.Description
 	u.Qty = model.Qty
 	u.Price = model.Price
+	u.Unit = model.Unit.Symbol
+	u.UnitStep = model.Unit.Step
 	u.CategoryName = model.Category.Name
+	u.ImageURL = model.Image.ImageURL
+	u.ThumbnailURL = model.Image.ThumbnailURL
+	u.Variants = ItemVariantsResponse{}
+	u.Variants.FromModel(model.Variants)
+	if model.DeletedAt.Valid {
+		u.ArchivedAt = &model.DeletedAt.Time
+	}
+}
+
+// ImageResponse is url of uploaded image and its thumbnail
+type ImageResponse struct {
+	ImageURL     string `json:"image_url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+}
+
+func (u *ImageResponse) FromModel(model *model.Image) {
+	u.ImageURL = model.ImageURL
+	u.ThumbnailURL = model.ThumbnailURL
 }
 
 type ItemsResponse []ItemResponse
@@ -50,3 +123,27 @@ func (u *ItemsResponse) FromModel(model []model.Item) {
 		*u = append(*u, item)
 	}
 }
+
+// ItemAvailabilityRequest ask item list to show stock of a checkpoint, by its id or the
+// nearest checkpoint of the user
+type ItemAvailabilityRequest struct {
+	CheckpointID string `query:"checkpoint_id"`
+	Nearest      bool   `query:"nearest"`
+}
+
+// FromCheckpointStocks fill available stock of item and variants in the checkpoint, item or
+// variant without stock there is not available
+func (u ItemsResponse) FromCheckpointStocks(checkpointId uuid.UUID, stocks []model.CheckpointStock) {
+	available := map[[2]uint]float64{}
+	for _, each := range stocks {
+		available[[2]uint{each.ItemID, each.VariantID}] = quantity.Round(each.Qty)
+	}
+	for i := range u {
+		qty := available[[2]uint{u[i].ID, 0}]
+		u[i].CheckpointID, u[i].Available = &checkpointId, &qty
+		for j := range u[i].Variants {
+			qty := available[[2]uint{u[i].ID, u[i].Variants[j].ID}]
+			u[i].Variants[j].Available = &qty
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package dto
+
+import (
+	"strconv"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+)
+
+type ImportRequest struct {
+	Format string `query:"format"`
+	DryRun bool   `query:"dry_run"`
+}
+
+type ExportRequest struct {
+	Format string `query:"format"`
+}
+
+// ItemImportRow is one row of item import and export file, value is kept as text so
+// invalid value is reported per row. Empty value keep current value of existing item
+type ItemImportRow struct {
+	Name        string `csv:"name"`
+	Description string `csv:"description"`
+	Category    string `csv:"category"`
+	Unit        string `csv:"unit"`
+	Qty         string `csv:"qty"`
+	Price       string `csv:"price"`
+}
+
+func (u *ItemImportRow) FromModel(model *model.Item) {
+	u.Name = model.Name
+	u.Description = model.Description
+	u.Category = model.Category.Name
+	u.Unit = model.Unit.Symbol
+	u.Qty = strconv.FormatFloat(model.Qty, 'f', -1, 64)
+	u.Price = strconv.Itoa(model.Price)
+}
+
+// IsEmpty is true for blank row, like trailing row of spreadsheet
+func (u *ItemImportRow) IsEmpty() bool {
+	return *u == ItemImportRow{}
+}
+
+type ItemImportRows []ItemImportRow
+
+func (u *ItemImportRows) FromModel(model []model.Item) {
+	for _, each := range model {
+		var row ItemImportRow
+		row.FromModel(&each)
+		*u = append(*u, row)
+	}
+}
+
+// CategoryImportRow is one row of category import and export file
+type CategoryImportRow struct {
+	Name        string `csv:"name"`
+	Description string `csv:"description"`
+}
+
+func (u *CategoryImportRow) FromModel(model *model.Category) {
+	u.Name = model.Name
+	u.Description = model.Description
+}
+
+func (u *CategoryImportRow) IsEmpty() bool {
+	return *u == CategoryImportRow{}
+}
+
+type CategoryImportRows []CategoryImportRow
+
+func (u *CategoryImportRows) FromModel(model []model.Category) {
+	for _, each := range model {
+		var row CategoryImportRow
+		row.FromModel(&each)
+		*u = append(*u, row)
+	}
+}
+
+// ImportRowError is validation error of a row, row number follow the file with header as row 1
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+type ImportResponse struct {
+	DryRun  bool             `json:"dry_run"`
+	Created int              `json:"created"`
+	Updated int              `json:"updated"`
+	Errors  []ImportRowError `json:"errors"`
+}
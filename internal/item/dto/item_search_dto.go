@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+)
+
+// ItemSearchQuery is sort allowed on item search, filter is bound to ItemSearchRequest
+var ItemSearchQuery = query.Schema{
+	Sort: map[string]string{
+		"relevance": "s.score",
+		"name":      "items.name",
+		"price":     "items.price",
+	},
+	DefaultSort: "-relevance",
+	Key:         "items.id",
+}
+
+type ItemSearchRequest struct {
+	Q          string `query:"q" validate:"required,max=100"`
+	CategoryID uint   `query:"category_id"`
+	MinPrice   int    `query:"min_price" validate:"min=0"`
+	MaxPrice   int    `query:"max_price" validate:"min=0"`
+	InStock    bool   `query:"in_stock"`
+}
+
+func (u *ItemSearchRequest) ToModel() model.ItemSearch {
+	return model.ItemSearch{
+		Text:       u.Q,
+		CategoryID: u.CategoryID,
+		MinPrice:   u.MinPrice,
+		MaxPrice:   u.MaxPrice,
+		InStock:    u.InStock,
+	}
+}
+
+type CategoryFacetResponse struct {
+	CategoryID   uint   `json:"category_id"`
+	CategoryName string `json:"category_name"`
+	Count        int64  `json:"count"`
+}
+
+type CategoryFacetsResponse []CategoryFacetResponse
+
+func (u *CategoryFacetsResponse) FromModel(facets []model.CategoryFacet) {
+	*u = make(CategoryFacetsResponse, 0, len(facets))
+	for _, each := range facets {
+		*u = append(*u, CategoryFacetResponse{
+			CategoryID:   each.CategoryID,
+			CategoryName: each.Name,
+			Count:        each.Count,
+		})
+	}
+}
+
+type ItemSearchResponse struct {
+	Items  ItemsResponse          `json:"items"`
+	Facets CategoryFacetsResponse `json:"facets"`
+}
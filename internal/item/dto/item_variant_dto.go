@@ -0,0 +1,74 @@
+package dto
+
+import (
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+)
+
+// ItemVariantRequest qty is counted stock like ItemRequest, zero qty on update empty the stock
+// and missing qty keep it
+type ItemVariantRequest struct {
+	Name  string   `json:"name" validate:"required"`
+	Qty   *float64 `json:"qty" validate:"omitempty,gte=0"`
+	Price int      `json:"price" validate:"gte=0"`
+}
+
+func (u *ItemVariantRequest) ToModel() *model.ItemVariant {
+	variant := &model.ItemVariant{
+		Name:  u.Name,
+		Price: u.Price,
+	}
+	if u.Qty != nil {
+		variant.Qty = *u.Qty
+	}
+	return variant
+}
+
+type ItemVariantResponse struct {
+	ID    uint    `json:"id"`
+	Name  string  `json:"name"`
+	Qty   float64 `json:"qty"`
+	Price int     `json:"price"`
+	// stock of the checkpoint asked in ItemAvailabilityRequest
+	Available *float64 `json:"available,omitempty"`
+}
+
+func (u *ItemVariantResponse) FromModel(model *model.ItemVariant) {
+	u.ID = model.ID
+	u.Name = model.Name
+	u.Qty = model.Qty
+	u.Price = model.Price
+}
+
+type ItemVariantsResponse []ItemVariantResponse
+
+func (u *ItemVariantsResponse) FromModel(model []model.ItemVariant) {
+	for _, each := range model {
+		var variant ItemVariantResponse
+		variant.FromModel(&each)
+		*u = append(*u, variant)
+	}
+}
+
+type UnitResponse struct {
+	ID     uint    `json:"id"`
+	Name   string  `json:"name"`
+	Symbol string  `json:"symbol"`
+	Step   float64 `json:"step"`
+}
+
+func (u *UnitResponse) FromModel(model *model.Unit) {
+	u.ID = model.ID
+	u.Name = model.Name
+	u.Symbol = model.Symbol
+	u.Step = model.Step
+}
+
+type UnitsResponse []UnitResponse
+
+func (u *UnitsResponse) FromModel(model []model.Unit) {
+	for _, each := range model {
+		var unit UnitResponse
+		unit.FromModel(&each)
+		*u = append(*u, unit)
+	}
+}
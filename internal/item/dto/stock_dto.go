@@ -0,0 +1,285 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/quantity"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+)
+
+// StockMovementQuery is sort and filter allowed on stock ledger of an item
+var StockMovementQuery = query.Schema{
+	Sort: map[string]string{
+		"created_at": "created_at",
+	},
+	DefaultSort: "-created_at",
+	Key:         "id",
+	Filter: map[string]query.Filter{
+		"reason":     {Column: "reason", Type: query.String, Operator: query.Equal},
+		"variant_id": {Column: "variant_id", Type: query.Uint, Operator: query.Equal},
+		"order_id":   {Column: "order_id", Type: query.UUID, Operator: query.Equal},
+		"from":       {Column: "created_at", Type: query.Time, Operator: query.From},
+		"to":         {Column: "created_at", Type: query.Time, Operator: query.Until},
+	},
+}
+
+// StockMovementRequest is stock move made by admin. Purchase and spoilage qty is positive,
+// spoilage is taken out of stock. Adjustment qty is signed difference. Move is on stock of
+// the checkpoint when CheckpointID is set, else on unallocated stock. Purchase with batch
+// receive the qty as new batch
+type StockMovementRequest struct {
+	VariantID    *uint              `json:"variant_id"`
+	CheckpointID string             `json:"checkpoint_id"`
+	Reason       string             `json:"reason" validate:"required,oneof=purchase spoilage adjustment"`
+	Qty          float64            `json:"qty" validate:"required"`
+	Note         string             `json:"note"`
+	Batch        *StockBatchRequest `json:"batch"`
+}
+
+func (u *StockMovementRequest) ToModel() *model.StockMovement {
+	qty := u.Qty
+	if u.Reason == constants.Stock_reason_spoilage {
+		qty = -qty
+	}
+	move := model.StockMovement{
+		VariantID: u.VariantID,
+		Qty:       qty,
+		Reason:    u.Reason,
+		Note:      u.Note,
+	}
+	if u.Batch != nil {
+		move.Batches = []model.StockBatchMove{{Qty: qty, Batch: u.Batch.ToModel()}}
+	}
+	return &move
+}
+
+// StockBatchRequest is supplier and dates of received batch
+type StockBatchRequest struct {
+	Supplier    string     `json:"supplier"`
+	HarvestedAt *time.Time `json:"harvested_at"`
+	ExpiredAt   time.Time  `json:"expired_at" validate:"required"`
+}
+
+// Valid check batch expire after it is harvested
+func (u *StockBatchRequest) Valid() bool {
+	return u.HarvestedAt == nil || u.ExpiredAt.After(*u.HarvestedAt)
+}
+
+func (u *StockBatchRequest) ToModel() *model.StockBatch {
+	return &model.StockBatch{
+		Supplier:    u.Supplier,
+		HarvestedAt: u.HarvestedAt,
+		ExpiredAt:   u.ExpiredAt,
+	}
+}
+
+// StockTransferRequest move stock between checkpoints, empty checkpoint is unallocated stock
+type StockTransferRequest struct {
+	VariantID        *uint   `json:"variant_id"`
+	FromCheckpointID string  `json:"from_checkpoint_id"`
+	ToCheckpointID   string  `json:"to_checkpoint_id"`
+	Qty              float64 `json:"qty" validate:"required,gt=0"`
+	Note             string  `json:"note"`
+}
+
+// ToModel split transfer into stock out of source and stock in of destination
+func (u *StockTransferRequest) ToModel(from *uuid.UUID, to *uuid.UUID) (*model.StockMovement, *model.StockMovement) {
+	out := model.StockMovement{
+		VariantID:    u.VariantID,
+		CheckpointID: from,
+		Qty:          -u.Qty,
+		Reason:       constants.Stock_reason_transfer,
+		Note:         u.Note,
+	}
+	in := out
+	in.CheckpointID, in.Qty = to, u.Qty
+	return &out, &in
+}
+
+type StockMovementResponse struct {
+	ID           uint       `json:"id"`
+	ItemID       uint       `json:"item_id"`
+	VariantID    *uint      `json:"variant_id"`
+	CheckpointID *uuid.UUID `json:"checkpoint_id"`
+	Qty          float64    `json:"qty"`
+	Balance      float64    `json:"balance"`
+	Reason       string     `json:"reason"`
+	OrderID      *uuid.UUID `json:"order_id"`
+	ActorID      *uuid.UUID `json:"actor_id"`
+	Note         string     `json:"note"`
+	CreatedAt    time.Time  `json:"created_at"`
+	// Batches is qty of the move taken from or put into each batch
+	Batches []StockBatchMoveResponse `json:"batches,omitempty"`
+}
+
+type StockBatchMoveResponse struct {
+	BatchID uint    `json:"batch_id"`
+	Qty     float64 `json:"qty"`
+}
+
+func (u *StockMovementResponse) FromModel(model *model.StockMovement) {
+	u.ID = model.ID
+	u.ItemID = model.ItemID
+	u.VariantID = model.VariantID
+	u.CheckpointID = model.CheckpointID
+	u.Qty = model.Qty
+	u.Balance = model.Balance
+	u.Reason = model.Reason
+	u.OrderID = model.OrderID
+	u.ActorID = model.ActorID
+	u.Note = model.Note
+	u.CreatedAt = model.CreatedAt
+	for _, batch := range model.Batches {
+		u.Batches = append(u.Batches, StockBatchMoveResponse{BatchID: batch.BatchID, Qty: batch.Qty})
+	}
+}
+
+type StockMovementsResponse []StockMovementResponse
+
+func (u *StockMovementsResponse) FromModel(model []model.StockMovement) {
+	for _, each := range model {
+		var move StockMovementResponse
+		move.FromModel(&each)
+		*u = append(*u, move)
+	}
+}
+
+// ItemStockResponse is stock of item or variant in one place, checkpoint is nil for unallocated stock
+type ItemStockResponse struct {
+	VariantID      *uint      `json:"variant_id"`
+	CheckpointID   *uuid.UUID `json:"checkpoint_id"`
+	CheckpointName string     `json:"checkpoint_name,omitempty"`
+	Qty            float64    `json:"qty"`
+}
+
+type ItemStocksResponse []ItemStockResponse
+
+// FromModel list unallocated stock of item and each variant, followed by stock in checkpoints
+func (u *ItemStocksResponse) FromModel(item *model.Item, stocks []model.CheckpointStock) {
+	unallocated := map[uint]float64{0: item.Qty}
+	for _, variant := range item.Variants {
+		unallocated[variant.ID] = variant.Qty
+	}
+	var checkpoints ItemStocksResponse
+	for _, each := range stocks {
+		unallocated[each.VariantID] -= each.Qty
+		checkpointId := each.CheckpointID
+		checkpoints = append(checkpoints, ItemStockResponse{
+			VariantID:      variantOf(each.VariantID),
+			CheckpointID:   &checkpointId,
+			CheckpointName: each.Checkpoint.Name,
+			Qty:            quantity.Round(each.Qty),
+		})
+	}
+	*u = append(*u, ItemStockResponse{Qty: quantity.Round(unallocated[0])})
+	for _, variant := range item.Variants {
+		*u = append(*u, ItemStockResponse{VariantID: variantOf(variant.ID), Qty: quantity.Round(unallocated[variant.ID])})
+	}
+	*u = append(*u, checkpoints...)
+}
+
+// variantOf turn variant id of checkpoint stock back to nullable id, 0 is item without variant
+func variantOf(id uint) *uint {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
+
+// ExpiringBatchRequest ask batches expiring within days from now, expired batch not yet
+// written off is listed too
+type ExpiringBatchRequest struct {
+	Days int `query:"days"`
+}
+
+type ExpiringBatchResponse struct {
+	ID             uint       `json:"id"`
+	ItemID         uint       `json:"item_id"`
+	ItemName       string     `json:"item_name"`
+	VariantID      *uint      `json:"variant_id"`
+	VariantName    string     `json:"variant_name,omitempty"`
+	CheckpointID   *uuid.UUID `json:"checkpoint_id"`
+	CheckpointName string     `json:"checkpoint_name,omitempty"`
+	Supplier       string     `json:"supplier"`
+	HarvestedAt    *time.Time `json:"harvested_at"`
+	ExpiredAt      time.Time  `json:"expired_at"`
+	Expired        bool       `json:"expired"`
+	Qty            float64    `json:"qty"`
+}
+
+// FromModel fill batch, expired when its expiry is not after now
+func (u *ExpiringBatchResponse) FromModel(model *model.ExpiringBatch, now time.Time) {
+	u.ID = model.ID
+	u.ItemID = model.ItemID
+	u.ItemName = model.ItemName
+	u.VariantID = variantOf(model.VariantID)
+	u.VariantName = model.VariantName
+	u.CheckpointID = model.CheckpointID
+	u.CheckpointName = model.CheckpointName
+	u.Supplier = model.Supplier
+	u.HarvestedAt = model.HarvestedAt
+	u.ExpiredAt = model.ExpiredAt
+	u.Expired = !model.ExpiredAt.After(now)
+	u.Qty = quantity.Round(model.Qty)
+}
+
+type ExpiringBatchesResponse []ExpiringBatchResponse
+
+func (u *ExpiringBatchesResponse) FromModel(model []model.ExpiringBatch, now time.Time) {
+	for _, each := range model {
+		var batch ExpiringBatchResponse
+		batch.FromModel(&each, now)
+		*u = append(*u, batch)
+	}
+}
+
+type StockReportRequest struct {
+	Mismatch bool `query:"mismatch"`
+}
+
+// StockReportRow compare stock balance with ledger total, difference is not zero when stock
+// is changed without ledger entry
+type StockReportRow struct {
+	ItemID      uint    `json:"item_id"`
+	VariantID   *uint   `json:"variant_id"`
+	ItemName    string  `json:"item_name"`
+	VariantName string  `json:"variant_name,omitempty"`
+	Balance     float64 `json:"balance"`
+	LedgerTotal float64 `json:"ledger_total"`
+	Difference  float64 `json:"difference"`
+}
+
+func (u *StockReportRow) FromModel(model *model.StockReport) {
+	u.ItemID = model.ItemID
+	u.VariantID = model.VariantID
+	u.ItemName = model.ItemName
+	u.VariantName = model.VariantName
+	u.Balance = quantity.Round(model.Balance)
+	u.LedgerTotal = quantity.Round(model.LedgerTotal)
+	u.Difference = quantity.Round(model.Balance - model.LedgerTotal)
+}
+
+type StockReportResponse struct {
+	Checked    int              `json:"checked"`
+	Mismatched int              `json:"mismatched"`
+	Rows       []StockReportRow `json:"rows"`
+}
+
+// FromModel fill report, only mismatched row is listed when mismatchOnly
+func (u *StockReportResponse) FromModel(model []model.StockReport, mismatchOnly bool) {
+	u.Rows = []StockReportRow{}
+	for _, each := range model {
+		var row StockReportRow
+		row.FromModel(&each)
+		u.Checked++
+		if row.Difference != 0 {
+			u.Mismatched++
+		} else if mismatchOnly {
+			continue
+		}
+		u.Rows = append(u.Rows, row)
+	}
+}
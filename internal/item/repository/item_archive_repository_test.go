@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/database/testdb"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemArchiveRepository(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	repository := NewItemRepository(db)
+	opts := query.Options{Page: 1, Limit: 20, Sort: "name", Key: "id"}
+
+	sayur := model.Category{Name: "sayur"}
+	assert.NoError(t, db.Create(&sayur).Error)
+	bayam := model.Item{Name: "bayam", CategoryID: sayur.ID, Qty: 5, Price: 3000}
+	kangkung := model.Item{Name: "kangkung", CategoryID: sayur.ID, Qty: 5, Price: 2000}
+	assert.NoError(t, db.Create(&bayam).Error)
+	assert.NoError(t, db.Create(&kangkung).Error)
+
+	// category with item cant be archived
+	assert.Equal(t, customerrors.ErrCategoryNotEmpty, repository.DeleteCategory(&model.Category{ID: sayur.ID}, ctx))
+
+	assert.NoError(t, repository.DeleteItem(&model.Item{ID: bayam.ID}, ctx))
+	assert.Equal(t, customerrors.ErrNotFound, repository.DeleteItem(&model.Item{ID: bayam.ID}, ctx))
+	assert.Equal(t, customerrors.ErrNotFound, repository.FindItemById(&model.Item{ID: bayam.ID}, ctx))
+
+	items, total, err := repository.FindItems(opts, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, []string{"kangkung"}, itemNames(items))
+
+	assert.NoError(t, repository.DeleteItem(&model.Item{ID: kangkung.ID}, ctx))
+	assert.NoError(t, repository.DeleteCategory(&model.Category{ID: sayur.ID}, ctx))
+
+	// archived item still list its archived category
+	items, total, err = repository.FindArchivedItems(opts, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, []string{"bayam", "kangkung"}, itemNames(items))
+	assert.Equal(t, "sayur", items[0].Category.Name)
+	assert.True(t, items[0].DeletedAt.Valid)
+
+	categories, total, err := repository.FindArchivedCategories(opts, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, "sayur", categories[0].Name)
+
+	// item cant be restored before its category
+	assert.Equal(t, customerrors.ErrCategoryArchived, repository.RestoreItem(&model.Item{ID: bayam.ID}, ctx))
+	assert.NoError(t, repository.RestoreCategory(&model.Category{ID: sayur.ID}, ctx))
+	assert.Equal(t, customerrors.ErrNotFound, repository.RestoreCategory(&model.Category{ID: sayur.ID}, ctx))
+	assert.NoError(t, repository.RestoreItem(&model.Item{ID: bayam.ID}, ctx))
+	assert.Equal(t, customerrors.ErrNotFound, repository.RestoreItem(&model.Item{ID: bayam.ID}, ctx))
+
+	restored := model.Item{ID: bayam.ID}
+	assert.NoError(t, repository.FindItemById(&restored, ctx))
+	assert.Equal(t, "bayam", restored.Name)
+	assert.Equal(t, float64(5), restored.Qty)
+}
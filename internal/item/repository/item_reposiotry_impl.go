@@ -4,9 +4,14 @@ import (
 	"context"
 	"strings"
 
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/stock"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type itemRepositoryImpl struct {
@@ -25,9 +30,14 @@ func (r *itemRepositoryImpl) CreateCategory(category *model.Category, ctx contex
 	return nil
 }
 
-// CreateItem implements ItemRepository
-func (r *itemRepositoryImpl) CreateItem(item *model.Item, ctx context.Context) error {
-	err := r.db.WithContext(ctx).Create(item).Error
+// CreateItem implements ItemRepository, initial qty is saved as opening stock movement
+func (r *itemRepositoryImpl) CreateItem(item *model.Item, actorId uuid.UUID, ctx context.Context) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(item).Error; err != nil {
+			return err
+		}
+		return openingStock(tx, item.ID, nil, item.Qty, &actorId)
+	})
 	if err != nil {
 		if strings.Contains(err.Error(), "Duplicate entry") {
 			return customerrors.ErrDuplicateData
@@ -41,18 +51,18 @@ func (r *itemRepositoryImpl) CreateItem(item *model.Item, ctx context.Context) e
 }
 
 // FindCategories implements ItemRepository
-func (r *itemRepositoryImpl) FindCategories(ctx context.Context) ([]model.Category, error) {
+func (r *itemRepositoryImpl) FindCategories(opts query.Options, ctx context.Context) ([]model.Category, int64, error) {
 	var categories []model.Category
-	err := r.db.WithContext(ctx).Find(&categories).Error
+	total, err := query.Find(r.db.WithContext(ctx).Model(&model.Category{}), opts, &categories)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return categories, err
+	return categories, total, nil
 }
 
 // FindItemById implements ItemRepository
 func (r *itemRepositoryImpl) FindItemById(item *model.Item, ctx context.Context) error {
-	err := r.db.WithContext(ctx).First(item).Error
+	err := r.db.WithContext(ctx).Preload("Unit").Preload("Variants").First(item).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return customerrors.ErrNotFound
@@ -63,52 +73,363 @@ func (r *itemRepositoryImpl) FindItemById(item *model.Item, ctx context.Context)
 }
 
 // FindItems implements ItemRepository
-func (r *itemRepositoryImpl) FindItems(ctx context.Context) ([]model.Item, error) {
+func (r *itemRepositoryImpl) FindItems(opts query.Options, ctx context.Context) ([]model.Item, int64, error) {
 	var items []model.Item
-	err := r.db.WithContext(ctx).Preload("Category").Find(&items).Error
+	total, err := query.Find(r.db.WithContext(ctx).Model(&model.Item{}).Preload("Category").Preload("Unit").Preload("Variants"), opts, &items)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return items, err
+	return items, total, nil
 }
 
 // FindItemByCategory implements ItemRepository
-func (r *itemRepositoryImpl) FindItemsByCategory(categoryId uint, ctx context.Context) ([]model.Item, error) {
+func (r *itemRepositoryImpl) FindItemsByCategory(categoryId uint, opts query.Options, ctx context.Context) ([]model.Item, int64, error) {
 	var items []model.Item
-	err := r.db.WithContext(ctx).Where("category_id = ?", categoryId).Preload("Category").Find(&items).Error
+	total, err := query.Find(r.db.WithContext(ctx).Model(&model.Item{}).Where("category_id = ?", categoryId).Preload("Category").Preload("Unit").Preload("Variants"), opts, &items)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return items, err
+	return items, total, nil
 }
 
-// UpdateItem implements ItemRepository
-func (r *itemRepositoryImpl) UpdateItem(item *model.Item, ctx context.Context) error {
-	res := r.db.WithContext(ctx).Model(&model.Item{}).Where("id = ?", item.ID).Updates(&model.Item{
-		Name:        item.Name,
-		Description: item.Description,
-		Qty:         item.Qty,
-		Price:       item.Price,
-		CategoryID:  item.CategoryID,
+// UpdateItem implements ItemRepository. Qty is counted stock, difference with current stock
+// is saved as adjustment movement and stock.Uncounted keep it. Other zero field is not updated
+func (r *itemRepositoryImpl) UpdateItem(item *model.Item, actorId uuid.UUID, ctx context.Context) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.Item{}).Where("id = ?", item.ID).Updates(&model.Item{
+			Name:        item.Name,
+			Description: item.Description,
+			Price:       item.Price,
+			CategoryID:  item.CategoryID,
+			UnitID:      item.UnitID,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return customerrors.ErrInvalidId
+		}
+		return stock.Count(tx, &model.StockMovement{
+			ItemID:  item.ID,
+			Reason:  constants.Stock_reason_adjustment,
+			ActorID: &actorId,
+		}, item.Qty)
 	})
-
-	if res.Error != nil {
-		if strings.Contains(res.Error.Error(), "Duplicate entry") {
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
 			return customerrors.ErrDuplicateData
 		}
-		if strings.Contains(res.Error.Error(), "Cannot add or update a child row") {
+		if strings.Contains(err.Error(), "Cannot add or update a child row") {
 			return customerrors.ErrBadRequestBody
 		}
+		return err
+	}
+	return nil
+}
+
+// UpdateItemImage implements ItemRepository
+func (r *itemRepositoryImpl) UpdateItemImage(item *model.Item, ctx context.Context) error {
+	res := r.db.WithContext(ctx).Model(&model.Item{}).Where("id = ?", item.ID).Select(imageColumns).Updates(&model.Item{Image: item.Image})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return customerrors.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteItem implements ItemRepository
+func (r *itemRepositoryImpl) DeleteItem(item *model.Item, ctx context.Context) error {
+	res := r.db.WithContext(ctx).Delete(item)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return customerrors.ErrNotFound
+	}
+	return nil
+}
+
+// RestoreItem implements ItemRepository
+func (r *itemRepositoryImpl) RestoreItem(item *model.Item, ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Where("deleted_at IS NOT NULL").First(item).Error
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return customerrors.ErrNotFound
+			}
+			return err
+		}
+		// item of archived category would be listed without its category
+		err = tx.First(&model.Category{}, item.CategoryID).Error
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return customerrors.ErrCategoryArchived
+			}
+			return err
+		}
+		return tx.Unscoped().Model(&model.Item{}).Where("id = ?", item.ID).Update("deleted_at", nil).Error
+	})
+}
+
+// FindArchivedItems implements ItemRepository
+func (r *itemRepositoryImpl) FindArchivedItems(opts query.Options, ctx context.Context) ([]model.Item, int64, error) {
+	var items []model.Item
+	tx := r.db.WithContext(ctx).Unscoped().Model(&model.Item{}).Where("deleted_at IS NOT NULL").
+		Preload("Category", unscoped).Preload("Unit").Preload("Variants")
+	total, err := query.Find(tx, opts, &items)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// FindCategoryById implements ItemRepository
+func (r *itemRepositoryImpl) FindCategoryById(category *model.Category, ctx context.Context) error {
+	err := r.db.WithContext(ctx).First(category).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return customerrors.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// UpdateCategoryImage implements ItemRepository
+func (r *itemRepositoryImpl) UpdateCategoryImage(category *model.Category, ctx context.Context) error {
+	res := r.db.WithContext(ctx).Model(&model.Category{}).Where("id = ?", category.ID).Select(imageColumns).Updates(&model.Category{Image: category.Image})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return customerrors.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteCategory implements ItemRepository
+func (r *itemRepositoryImpl) DeleteCategory(category *model.Category, ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		err := tx.Model(&model.Item{}).Where("category_id = ?", category.ID).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return customerrors.ErrCategoryNotEmpty
+		}
+		res := tx.Delete(category)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return customerrors.ErrNotFound
+		}
+		return nil
+	})
+}
+
+// RestoreCategory implements ItemRepository
+func (r *itemRepositoryImpl) RestoreCategory(category *model.Category, ctx context.Context) error {
+	res := r.db.WithContext(ctx).Unscoped().Model(&model.Category{}).Where("id = ? AND deleted_at IS NOT NULL", category.ID).Update("deleted_at", nil)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return customerrors.ErrNotFound
+	}
+	return nil
+}
+
+// FindArchivedCategories implements ItemRepository
+func (r *itemRepositoryImpl) FindArchivedCategories(opts query.Options, ctx context.Context) ([]model.Category, int64, error) {
+	var categories []model.Category
+	total, err := query.Find(r.db.WithContext(ctx).Unscoped().Model(&model.Category{}).Where("deleted_at IS NOT NULL"), opts, &categories)
+	if err != nil {
+		return nil, 0, err
+	}
+	return categories, total, nil
+}
+
+// unscoped preload archived row too, like category of archived item
+func unscoped(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// CreateVariant implements ItemRepository, initial qty is saved as opening stock movement
+func (r *itemRepositoryImpl) CreateVariant(variant *model.ItemVariant, actorId uuid.UUID, ctx context.Context) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(variant).Error; err != nil {
+			return err
+		}
+		return openingStock(tx, variant.ItemID, &variant.ID, variant.Qty, &actorId)
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "Cannot add or update a child row") {
+			return customerrors.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// UpdateVariant implements ItemRepository, qty is counted stock like UpdateItem
+func (r *itemRepositoryImpl) UpdateVariant(variant *model.ItemVariant, actorId uuid.UUID, ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.ItemVariant{}).Where("id = ? AND item_id = ?", variant.ID, variant.ItemID).
+			Select("name", "price").Updates(variant)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return customerrors.ErrNotFound
+		}
+		return stock.Count(tx, &model.StockMovement{
+			ItemID:    variant.ItemID,
+			VariantID: &variant.ID,
+			Reason:    constants.Stock_reason_adjustment,
+			ActorID:   &actorId,
+		}, variant.Qty)
+	})
+}
+
+// DeleteVariant implements ItemRepository
+func (r *itemRepositoryImpl) DeleteVariant(variant *model.ItemVariant, ctx context.Context) error {
+	res := r.db.WithContext(ctx).Where("id = ? AND item_id = ?", variant.ID, variant.ItemID).Delete(&model.ItemVariant{})
+	if res.Error != nil {
 		return res.Error
 	}
 	if res.RowsAffected == 0 {
-		return customerrors.ErrInvalidId
+		return customerrors.ErrNotFound
 	}
 	return nil
 }
 
+// FindItemsByName implements ItemRepository, archived item is found too
+func (r *itemRepositoryImpl) FindItemsByName(names []string, ctx context.Context) ([]model.Item, error) {
+	var items []model.Item
+	err := r.db.WithContext(ctx).Unscoped().Where("name IN ?", names).Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// SaveItems implements ItemRepository, item with id is updated and the other created, all in one transaction.
+// Qty of updated item is counted stock like UpdateItem, stock.Uncounted keep its stock
+func (r *itemRepositoryImpl) SaveItems(items []model.Item, actorId uuid.UUID, ctx context.Context) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range items {
+			var err error
+			if items[i].ID == 0 {
+				err = tx.Omit(clause.Associations).Create(&items[i]).Error
+				if err == nil {
+					err = openingStock(tx, items[i].ID, nil, items[i].Qty, &actorId)
+				}
+			} else {
+				err = tx.Model(&model.Item{}).Where("id = ?", items[i].ID).
+					Select("name", "description", "category_id", "unit_id", "price").Updates(&items[i]).Error
+				if err == nil {
+					err = stock.Count(tx, &model.StockMovement{
+						ItemID:  items[i].ID,
+						Reason:  constants.Stock_reason_adjustment,
+						Note:    "import",
+						ActorID: &actorId,
+					}, items[i].Qty)
+				}
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			return customerrors.ErrDuplicateData
+		}
+		return err
+	}
+	return nil
+}
+
+// ExportItems implements ItemRepository
+func (r *itemRepositoryImpl) ExportItems(ctx context.Context) ([]model.Item, error) {
+	var items []model.Item
+	err := r.db.WithContext(ctx).Preload("Category").Preload("Unit").Order("name").Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// FindCategoriesByName implements ItemRepository, archived category is found too
+func (r *itemRepositoryImpl) FindCategoriesByName(names []string, ctx context.Context) ([]model.Category, error) {
+	var categories []model.Category
+	err := r.db.WithContext(ctx).Unscoped().Where("name IN ?", names).Find(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// SaveCategories implements ItemRepository
+func (r *itemRepositoryImpl) SaveCategories(categories []model.Category, ctx context.Context) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range categories {
+			var err error
+			if categories[i].ID == 0 {
+				err = tx.Create(&categories[i]).Error
+			} else {
+				err = tx.Model(&model.Category{}).Where("id = ?", categories[i].ID).
+					Select("name", "description").Updates(&categories[i]).Error
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			return customerrors.ErrDuplicateData
+		}
+		return err
+	}
+	return nil
+}
+
+// ExportCategories implements ItemRepository
+func (r *itemRepositoryImpl) ExportCategories(ctx context.Context) ([]model.Category, error) {
+	var categories []model.Category
+	err := r.db.WithContext(ctx).Order("name").Find(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// FindUnits implements ItemRepository
+func (r *itemRepositoryImpl) FindUnits(ctx context.Context) ([]model.Unit, error) {
+	var units []model.Unit
+	err := r.db.WithContext(ctx).Order("id").Find(&units).Error
+	if err != nil {
+		return nil, err
+	}
+	return units, nil
+}
+
+// imageColumns are updated together, so image and thumbnail always belong to the same upload
+var imageColumns = []string{"image_key", "image_url", "thumbnail_key", "thumbnail_url"}
+
 func NewItemRepository(db *gorm.DB) ItemRepository {
-	return &itemRepositoryImpl{
+	itemRepo := &itemRepositoryImpl{
 		db: db,
 	}
+	if err := itemRepo.InitStockLedger(); err != nil {
+		panic(err)
+	}
+	return itemRepo
 }
@@ -2,16 +2,47 @@ package repository
 
 import (
 	"context"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
 type ItemRepository interface {
-	CreateItem(item *model.Item, ctx context.Context) error
-	UpdateItem(item *model.Item, ctx context.Context) error
-	FindItems(ctx context.Context) ([]model.Item, error)
+	CreateItem(item *model.Item, actorId uuid.UUID, ctx context.Context) error
+	UpdateItem(item *model.Item, actorId uuid.UUID, ctx context.Context) error
+	FindItems(opts query.Options, ctx context.Context) ([]model.Item, int64, error)
 	FindItemById(item *model.Item, ctx context.Context) error
-	FindItemsByCategory(categoryId uint, ctx context.Context) ([]model.Item, error)
+	FindItemsByCategory(categoryId uint, opts query.Options, ctx context.Context) ([]model.Item, int64, error)
+	UpdateItemImage(item *model.Item, ctx context.Context) error
+	DeleteItem(item *model.Item, ctx context.Context) error
+	RestoreItem(item *model.Item, ctx context.Context) error
+	FindArchivedItems(opts query.Options, ctx context.Context) ([]model.Item, int64, error)
+	CreateVariant(variant *model.ItemVariant, actorId uuid.UUID, ctx context.Context) error
+	UpdateVariant(variant *model.ItemVariant, actorId uuid.UUID, ctx context.Context) error
+	DeleteVariant(variant *model.ItemVariant, ctx context.Context) error
 	CreateCategory(category *model.Category, ctx context.Context) error
-	FindCategories(ctx context.Context) ([]model.Category, error)
+	FindCategoryById(category *model.Category, ctx context.Context) error
+	UpdateCategoryImage(category *model.Category, ctx context.Context) error
+	DeleteCategory(category *model.Category, ctx context.Context) error
+	RestoreCategory(category *model.Category, ctx context.Context) error
+	FindArchivedCategories(opts query.Options, ctx context.Context) ([]model.Category, int64, error)
+	FindItemsByName(names []string, ctx context.Context) ([]model.Item, error)
+	SaveItems(items []model.Item, actorId uuid.UUID, ctx context.Context) error
+	ExportItems(ctx context.Context) ([]model.Item, error)
+	FindCategoriesByName(names []string, ctx context.Context) ([]model.Category, error)
+	SaveCategories(categories []model.Category, ctx context.Context) error
+	ExportCategories(ctx context.Context) ([]model.Category, error)
+	CreateStockMovement(move *model.StockMovement, ctx context.Context) error
+	TransferStock(out *model.StockMovement, in *model.StockMovement, ctx context.Context) error
+	FindExpiringBatches(until time.Time, ctx context.Context) ([]model.ExpiringBatch, error)
+	WriteOffBatch(batch *model.StockBatch, ctx context.Context) error
+	FindItemStocks(itemId uint, ctx context.Context) ([]model.CheckpointStock, error)
+	FindCheckpointStocks(checkpointId uuid.UUID, itemIds []uint, ctx context.Context) ([]model.CheckpointStock, error)
+	FindStockMovements(itemId uint, opts query.Options, ctx context.Context) ([]model.StockMovement, int64, error)
+	StockReport(ctx context.Context) ([]model.StockReport, error)
+	InitStockLedger() error
+	FindUnits(ctx context.Context) ([]model.Unit, error)
+	FindCategories(opts query.Options, ctx context.Context) ([]model.Category, int64, error)
 }
@@ -7,8 +7,11 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"github.com/stretchr/testify/suite"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -43,6 +46,7 @@ func (s *suiteItemRepository) TearDown() {
 }
 
 func (s *suiteItemRepository) TestCreateItem() {
+	actorId := uuid.New()
 	testCase := []struct {
 		Name        string
 		Body        model.Item
@@ -99,16 +103,19 @@ func (s *suiteItemRepository) TestCreateItem() {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.SetupSuite()
 			s.mock.ExpectBegin()
-			db := s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `items` (`created_at`,`updated_at`,`deleted_at`,`name`,`category_id`,`description`,`qty`,`price`,`id`) VALUES (?,?,?,?,?,?,?,?,?)"))
+			db := s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `items` (`created_at`,`updated_at`,`deleted_at`,`name`,`category_id`,`description`,`unit_id`,`qty`,`price`,`image_key`,`image_url`,`thumbnail_key`,`thumbnail_url`,`id`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?)"))
 			if v.ExpectedErr != nil {
 				db.WillReturnError(v.MockReturn)
 				s.mock.ExpectRollback()
 			} else {
 				db.WillReturnResult(sqlmock.NewResult(1, 1))
+				s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `stock_movements` (`created_at`,`item_id`,`variant_id`,`qty`,`balance`,`reason`,`order_id`,`checkpoint_id`,`actor_id`,`note`) VALUES (?,?,?,?,?,?,?,?,?,?)")).
+					WithArgs(sqlmock.AnyArg(), v.Body.ID, nil, v.Body.Qty, v.Body.Qty, constants.Stock_reason_opening, nil, nil, actorId, "").
+					WillReturnResult(sqlmock.NewResult(1, 1))
 				s.mock.ExpectCommit()
 			}
 
-			err := s.repository.CreateItem(&v.Body, context.Background())
+			err := s.repository.CreateItem(&v.Body, actorId, context.Background())
 
 			s.Equal(v.ExpectedErr, err)
 
@@ -156,7 +163,7 @@ func (s *suiteItemRepository) TestCreateCategory() {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.SetupSuite()
 			s.mock.ExpectBegin()
-			db := s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `categories` (`created_at`,`updated_at`,`deleted_at`,`name`,`description`,`id`) VALUES (?,?,?,?,?,?)"))
+			db := s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `categories` (`created_at`,`updated_at`,`deleted_at`,`name`,`description`,`image_key`,`image_url`,`thumbnail_key`,`thumbnail_url`,`id`) VALUES (?,?,?,?,?,?,?,?,?,?)"))
 			if v.ExpectedErr != nil {
 				db.WillReturnError(v.MockReturn)
 				s.mock.ExpectRollback()
@@ -205,12 +212,14 @@ func (s *suiteItemRepository) TestFindCategory() {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.SetupSuite()
 
-			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `categories` WHERE `categories`.`deleted_at` IS NULL")).WillReturnRows(v.FindCategoryRes).WillReturnError(v.FindCategoryErr)
+			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `categories` WHERE `categories`.`deleted_at` IS NULL")).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(len(v.ExpectedRes)))
+			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `categories` WHERE `categories`.`deleted_at` IS NULL ORDER BY `name`,`id` LIMIT 20")).WillReturnRows(v.FindCategoryRes).WillReturnError(v.FindCategoryErr)
 
-			res, err := s.repository.FindCategories(context.Background())
+			res, total, err := s.repository.FindCategories(query.Options{Page: 1, Limit: 20, Sort: "name", Key: "id"}, context.Background())
 
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(v.ExpectedRes, res)
+			s.Equal(int64(len(v.ExpectedRes)), total)
 
 			s.TearDown()
 		})
@@ -232,14 +241,19 @@ func (s *suiteItemRepository) TestFindItemById() {
 				ID: 1,
 			},
 			ExpectedRes: model.Item{
-				ID:    1,
-				Name:  "itemA",
-				Qty:   1,
-				Price: 1,
+				ID:     1,
+				Name:   "itemA",
+				UnitID: 1,
+				Unit:   model.Unit{ID: 1, Name: "kilogram", Symbol: "kg", Step: 0.25},
+				Qty:    1.5,
+				Price:  1,
+				Variants: []model.ItemVariant{
+					{ID: 1, ItemID: 1, Name: "organik", Qty: 0.5, Price: 2},
+				},
 			},
 			FindItemErr: nil,
-			FindItemRes: sqlmock.NewRows([]string{"id", "name", "qty", "price"}).
-				AddRow(1, "itemA", 1, 1),
+			FindItemRes: sqlmock.NewRows([]string{"id", "name", "unit_id", "qty", "price"}).
+				AddRow(1, "itemA", 1, 1.5, 1),
 		},
 		{
 			Name:        "error",
@@ -258,6 +272,12 @@ func (s *suiteItemRepository) TestFindItemById() {
 			s.SetupSuite()
 
 			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `items` WHERE `items`.`deleted_at` IS NULL AND `items`.`id` = ? ORDER BY `items`.`id` LIMIT 1")).WillReturnRows(v.FindItemRes).WillReturnError(v.FindItemErr)
+			if v.ExpectedErr == nil {
+				s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `units` WHERE `units`.`id` = ?")).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "symbol", "step"}).AddRow(1, "kilogram", "kg", 0.25))
+				s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `item_variants` WHERE `item_variants`.`item_id` = ? AND `item_variants`.`deleted_at` IS NULL")).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "item_id", "name", "qty", "price"}).AddRow(1, 1, "organik", 0.5, 2))
+			}
 
 			err := s.repository.FindItemById(v.Item, context.Background())
 
@@ -290,6 +310,7 @@ func (s *suiteItemRepository) TestFindItems() {
 						ID:   1,
 						Name: "category",
 					},
+					Variants: []model.ItemVariant{},
 				},
 			},
 			FindItemErr: nil,
@@ -314,14 +335,119 @@ func (s *suiteItemRepository) TestFindItems() {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.SetupSuite()
 
-			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `items` WHERE `items`.`deleted_at` IS NULL")).WillReturnRows(v.FindItemRes).WillReturnError(v.FindItemErr)
+			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `items` WHERE `items`.`deleted_at` IS NULL")).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(len(v.ExpectedRes)))
+			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `items` WHERE `items`.`deleted_at` IS NULL ORDER BY `price` DESC,`id` LIMIT 10 OFFSET 10")).WillReturnRows(v.FindItemRes).WillReturnError(v.FindItemErr)
 
 			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `categories` WHERE `categories`.`id` = ? AND `categories`.`deleted_at` IS NULL")).WillReturnRows(v.PreloadCategoryRes).WillReturnError(v.PreloadCategoryErr)
+			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `item_variants` WHERE `item_variants`.`item_id` = ? AND `item_variants`.`deleted_at` IS NULL")).WillReturnRows(sqlmock.NewRows([]string{"id", "item_id"}))
 
-			res, err := s.repository.FindItems(context.Background())
+			res, total, err := s.repository.FindItems(query.Options{Page: 2, Limit: 10, Sort: "price", Desc: true, Key: "id"}, context.Background())
 
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(v.ExpectedRes, res)
+			s.Equal(int64(len(v.ExpectedRes)), total)
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteItemRepository) TestUpdateItemImage() {
+	testCase := []struct {
+		Name         string
+		ExpectedErr  error
+		RowsAffected int64
+		MockErr      error
+	}{
+		{
+			Name:         "success",
+			ExpectedErr:  nil,
+			RowsAffected: 1,
+		},
+		{
+			Name:         "not found",
+			ExpectedErr:  customerrors.ErrNotFound,
+			RowsAffected: 0,
+		},
+		{
+			Name:        "error",
+			ExpectedErr: errors.New("error"),
+			MockErr:     errors.New("error"),
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuite()
+
+			item := &model.Item{
+				ID: 1,
+				Image: model.Image{
+					ImageKey:     "items/1/a.jpg",
+					ImageURL:     "/uploads/items/1/a.jpg",
+					ThumbnailKey: "items/1/a_thumb.jpg",
+					ThumbnailURL: "/uploads/items/1/a_thumb.jpg",
+				},
+			}
+			s.mock.ExpectBegin()
+			db := s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `items` SET `updated_at`=?,`image_key`=?,`image_url`=?,`thumbnail_key`=?,`thumbnail_url`=? WHERE id = ? AND `items`.`deleted_at` IS NULL")).
+				WithArgs(sqlmock.AnyArg(), item.Image.ImageKey, item.Image.ImageURL, item.Image.ThumbnailKey, item.Image.ThumbnailURL, item.ID)
+			if v.MockErr != nil {
+				db.WillReturnError(v.MockErr)
+				s.mock.ExpectRollback()
+			} else {
+				db.WillReturnResult(sqlmock.NewResult(0, v.RowsAffected))
+				s.mock.ExpectCommit()
+			}
+
+			err := s.repository.UpdateItemImage(item, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteItemRepository) TestFindCategoryById() {
+	testCase := []struct {
+		Name            string
+		ExpectedErr     error
+		ExpectedRes     model.Category
+		FindCategoryErr error
+		FindCategoryRes *sqlmock.Rows
+	}{
+		{
+			Name:        "success",
+			ExpectedErr: nil,
+			ExpectedRes: model.Category{
+				ID:    1,
+				Name:  "sayur",
+				Image: model.Image{ImageURL: "/uploads/categories/1/a.jpg"},
+			},
+			FindCategoryRes: sqlmock.NewRows([]string{"id", "name", "image_url"}).
+				AddRow(1, "sayur", "/uploads/categories/1/a.jpg"),
+		},
+		{
+			Name:            "not found",
+			ExpectedErr:     customerrors.ErrNotFound,
+			ExpectedRes:     model.Category{ID: 1},
+			FindCategoryErr: gorm.ErrRecordNotFound,
+			FindCategoryRes: sqlmock.NewRows([]string{"id", "name", "image_url"}),
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuite()
+
+			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `categories` WHERE `categories`.`deleted_at` IS NULL AND `categories`.`id` = ? ORDER BY `categories`.`id` LIMIT 1")).WillReturnRows(v.FindCategoryRes).WillReturnError(v.FindCategoryErr)
+
+			category := &model.Category{ID: 1}
+			err := s.repository.FindCategoryById(category, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			s.Equal(&v.ExpectedRes, category)
 
 			s.TearDown()
 		})
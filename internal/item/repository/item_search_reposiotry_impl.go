@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/search"
+	"gorm.io/gorm"
+)
+
+// trigram in item name rank the item higher than trigram only in description
+const (
+	nameGramWeight        = 2
+	descriptionGramWeight = 1
+	rebuildIndexBatch     = 100
+	// searchIndexVersion is changed when how item is indexed is changed, so old index is rebuilt
+	searchIndexVersion = 1
+	searchIndexID      = 1
+)
+
+type itemSearchRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// IndexItem implements ItemSearchRepository
+func (r *itemSearchRepositoryImpl) IndexItem(item *model.Item, ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return indexItem(tx, item)
+	})
+}
+
+// RebuildIndex implements ItemSearchRepository
+func (r *itemSearchRepositoryImpl) RebuildIndex(ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("1 = 1").Delete(&model.ItemSearchGram{}).Error
+		if err != nil {
+			return err
+		}
+		var items []model.Item
+		err = tx.Model(&model.Item{}).FindInBatches(&items, rebuildIndexBatch, func(batch *gorm.DB, _ int) error {
+			for i := range items {
+				if err := indexItem(tx, &items[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Save(&model.ItemSearchIndex{ID: searchIndexID, Version: searchIndexVersion}).Error
+	})
+}
+
+// EnsureIndex implements ItemSearchRepository
+func (r *itemSearchRepositoryImpl) EnsureIndex(ctx context.Context) error {
+	var index model.ItemSearchIndex
+	err := r.db.WithContext(ctx).Where("id = ?", searchIndexID).Limit(1).Find(&index).Error
+	if err != nil {
+		return err
+	}
+	if index.Version == searchIndexVersion {
+		var gram model.ItemSearchGram
+		res := r.db.WithContext(ctx).Limit(1).Find(&gram)
+		if res.Error != nil {
+			return res.Error
+		}
+		var item model.Item
+		items := r.db.WithContext(ctx).Limit(1).Find(&item)
+		if items.Error != nil {
+			return items.Error
+		}
+		// index is built and not emptied
+		if res.RowsAffected > 0 || items.RowsAffected == 0 {
+			return nil
+		}
+	}
+	return r.RebuildIndex(ctx)
+}
+
+// SearchItems implements ItemSearchRepository
+func (r *itemSearchRepositoryImpl) SearchItems(filter model.ItemSearch, opts query.Options, ctx context.Context) ([]model.Item, int64, error) {
+	var items []model.Item
+	tx, ok := r.matched(filter, ctx)
+	if !ok {
+		return items, 0, nil
+	}
+	if filter.CategoryID != 0 {
+		tx = tx.Where("items.category_id = ?", filter.CategoryID)
+	}
+	total, err := query.Find(tx.Preload("Category").Preload("Unit").Preload("Variants"), opts, &items)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// FacetCategories implements ItemSearchRepository
+func (r *itemSearchRepositoryImpl) FacetCategories(filter model.ItemSearch, ctx context.Context) ([]model.CategoryFacet, error) {
+	facets := []model.CategoryFacet{}
+	tx, ok := r.matched(filter, ctx)
+	if !ok {
+		return facets, nil
+	}
+	// category filter is not applied, so client still see count of other category
+	err := tx.Select("items.category_id, categories.name, COUNT(*) AS count").
+		Joins("LEFT JOIN categories ON categories.id = items.category_id").
+		Group("items.category_id, categories.name").
+		Order("count DESC, items.category_id").
+		Scan(&facets).Error
+	if err != nil {
+		return nil, err
+	}
+	return facets, nil
+}
+
+// matched join item with its search score, only item sharing enough trigram with the text is joined.
+// Filter other than category is applied. Return false when text has no word to search
+func (r *itemSearchRepositoryImpl) matched(filter model.ItemSearch, ctx context.Context) (*gorm.DB, bool) {
+	grams := search.Grams(filter.Text)
+	if len(grams) == 0 {
+		return nil, false
+	}
+	scores := r.db.Model(&model.ItemSearchGram{}).
+		Select("item_id, SUM(weight) AS score").
+		Where("gram IN ?", grams).
+		Group("item_id").
+		Having("COUNT(*) >= ?", search.MinMatch(grams))
+	tx := r.db.WithContext(ctx).Model(&model.Item{}).Joins("JOIN (?) AS s ON s.item_id = items.id", scores)
+	if filter.MinPrice != 0 {
+		tx = tx.Where("items.price >= ?", filter.MinPrice)
+	}
+	if filter.MaxPrice != 0 {
+		tx = tx.Where("items.price <= ?", filter.MaxPrice)
+	}
+	if filter.InStock {
+		// item with variant is in stock when any of its variant is
+		tx = tx.Where("items.qty > 0 OR EXISTS (SELECT 1 FROM item_variants WHERE item_variants.item_id = items.id AND item_variants.qty > 0 AND item_variants.deleted_at IS NULL)")
+	}
+	return tx, true
+}
+
+// indexItem replace trigram of item with trigram of its current name and description
+func indexItem(tx *gorm.DB, item *model.Item) error {
+	err := tx.Where("item_id = ?", item.ID).Delete(&model.ItemSearchGram{}).Error
+	if err != nil {
+		return err
+	}
+	weights := map[string]int{}
+	var grams []model.ItemSearchGram
+	for _, text := range []struct {
+		value  string
+		weight int
+	}{{item.Name, nameGramWeight}, {item.Description, descriptionGramWeight}} {
+		for _, gram := range search.Grams(text.value) {
+			if _, ok := weights[gram]; ok {
+				continue
+			}
+			weights[gram] = text.weight
+			grams = append(grams, model.ItemSearchGram{
+				Gram:   gram,
+				ItemID: item.ID,
+				Weight: text.weight,
+			})
+		}
+	}
+	if len(grams) == 0 {
+		return nil
+	}
+	return tx.Create(&grams).Error
+}
+
+func NewItemSearchRepository(db *gorm.DB) ItemSearchRepository {
+	return &itemSearchRepositoryImpl{
+		db: db,
+	}
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+)
+
+// ItemSearchRepository is search index of item, text is matched by trigram so typo and synonym still found
+type ItemSearchRepository interface {
+	IndexItem(item *model.Item, ctx context.Context) error
+	RebuildIndex(ctx context.Context) error
+	EnsureIndex(ctx context.Context) error
+	SearchItems(search model.ItemSearch, opts query.Options, ctx context.Context) ([]model.Item, int64, error)
+	FacetCategories(search model.ItemSearch, ctx context.Context) ([]model.CategoryFacet, error)
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/database/testdb"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"github.com/stretchr/testify/assert"
+)
+
+func itemNames(items []model.Item) []string {
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		names = append(names, item.Name)
+	}
+	return names
+}
+
+func TestItemSearchRepository(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	repository := NewItemSearchRepository(db)
+
+	sayur := model.Category{Name: "sayur"}
+	bumbu := model.Category{Name: "bumbu"}
+	assert.NoError(t, db.Create(&sayur).Error)
+	assert.NoError(t, db.Create(&bumbu).Error)
+	items := []model.Item{
+		{Name: "Cabai Merah", Description: "pedas segar", CategoryID: bumbu.ID, Qty: 10, Price: 5000},
+		{Name: "Cabai Rawit", Description: "sangat pedas", CategoryID: bumbu.ID, Qty: 0, Price: 8000},
+		{Name: "Sambal Botol", Description: "dari cabai pilihan", CategoryID: sayur.ID, Qty: 3, Price: 15000},
+		{Name: "Tomat", Description: "merah segar", CategoryID: sayur.ID, Qty: 5, Price: 3000},
+	}
+	assert.NoError(t, db.Create(&items).Error)
+	assert.NoError(t, repository.RebuildIndex(ctx))
+
+	relevance := query.Options{Sort: "s.score", Desc: true, Key: "items.id"}
+
+	found, total, err := repository.SearchItems(model.ItemSearch{Text: "cabe"}, relevance, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	// name match rank above description match
+	assert.Equal(t, []string{"Cabai Merah", "Cabai Rawit", "Sambal Botol"}, itemNames(found))
+	assert.Equal(t, "bumbu", found[0].Category.Name)
+
+	found, _, err = repository.SearchItems(model.ItemSearch{Text: "tomta"}, relevance, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Tomat"}, itemNames(found))
+
+	found, _, err = repository.SearchItems(model.ItemSearch{Text: "cabai", InStock: true, MaxPrice: 10000}, relevance, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Cabai Merah"}, itemNames(found))
+
+	found, _, err = repository.SearchItems(model.ItemSearch{Text: "cabai", CategoryID: sayur.ID}, relevance, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Sambal Botol"}, itemNames(found))
+
+	found, total, err = repository.SearchItems(model.ItemSearch{Text: "kentang"}, relevance, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+	assert.Empty(t, found)
+
+	// category filter not applied to facet
+	facets, err := repository.FacetCategories(model.ItemSearch{Text: "cabai", CategoryID: sayur.ID}, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.CategoryFacet{
+		{CategoryID: bumbu.ID, Name: "bumbu", Count: 2},
+		{CategoryID: sayur.ID, Name: "sayur", Count: 1},
+	}, facets)
+
+	facets, err = repository.FacetCategories(model.ItemSearch{Text: "!!"}, ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, facets)
+
+	// reindexed item only found by its new name
+	items[3].Name = "Terong Ungu"
+	assert.NoError(t, db.Save(&items[3]).Error)
+	assert.NoError(t, repository.IndexItem(&items[3], ctx))
+	found, _, err = repository.SearchItems(model.ItemSearch{Text: "terung"}, relevance, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Terong Ungu"}, itemNames(found))
+	found, _, err = repository.SearchItems(model.ItemSearch{Text: "tomat"}, relevance, ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+// index is rebuilt only when it is not built, emptied, or built by old version
+func TestItemSearchEnsureIndex(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	repository := NewItemSearchRepository(db)
+	relevance := query.Options{Sort: "s.score", Desc: true, Key: "items.id"}
+
+	assert.NoError(t, db.Create(&model.Item{Name: "Tomat", Qty: 5, Price: 3000}).Error)
+	assert.NoError(t, repository.EnsureIndex(ctx))
+	found, _, err := repository.SearchItems(model.ItemSearch{Text: "tomat"}, relevance, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Tomat"}, itemNames(found))
+
+	// current index is kept, item saved without index is not found until rebuild
+	assert.NoError(t, db.Create(&model.Item{Name: "Kentang", Qty: 5, Price: 3000}).Error)
+	assert.NoError(t, repository.EnsureIndex(ctx))
+	found, _, err = repository.SearchItems(model.ItemSearch{Text: "kentang"}, relevance, ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+
+	assert.NoError(t, db.Model(&model.ItemSearchIndex{}).Where("id = ?", searchIndexID).Update("version", searchIndexVersion-1).Error)
+	assert.NoError(t, repository.EnsureIndex(ctx))
+	found, _, err = repository.SearchItems(model.ItemSearch{Text: "kentang"}, relevance, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Kentang"}, itemNames(found))
+
+	assert.NoError(t, db.Where("1 = 1").Delete(&model.ItemSearchGram{}).Error)
+	assert.NoError(t, repository.EnsureIndex(ctx))
+	found, _, err = repository.SearchItems(model.ItemSearch{Text: "tomat"}, relevance, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Tomat"}, itemNames(found))
+}
@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/quantity"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/stock"
+	"gorm.io/gorm"
+)
+
+// openingStock save initial qty of new item or variant, the row is already created with the qty
+func openingStock(tx *gorm.DB, itemId uint, variantId *uint, qty float64, actorId *uuid.UUID) error {
+	if qty == 0 {
+		return nil
+	}
+	return tx.Create(&model.StockMovement{
+		ItemID:    itemId,
+		VariantID: variantId,
+		Qty:       qty,
+		Balance:   qty,
+		Reason:    constants.Stock_reason_opening,
+		ActorID:   actorId,
+	}).Error
+}
+
+// checkStockMovement check item, variant and checkpoint of the move exist
+func checkStockMovement(tx *gorm.DB, move *model.StockMovement) error {
+	var item model.Item
+	if err := tx.Select("id").First(&item, move.ItemID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return customerrors.ErrNotFound
+		}
+		return err
+	}
+	if move.VariantID != nil {
+		var variants int64
+		err := tx.Model(&model.ItemVariant{}).Where("id = ? AND item_id = ?", *move.VariantID, move.ItemID).Count(&variants).Error
+		if err != nil {
+			return err
+		}
+		if variants == 0 {
+			return customerrors.ErrInvalidVariant
+		}
+	}
+	if move.CheckpointID != nil {
+		var checkpoints int64
+		err := tx.Model(&model.Checkpoint{}).Where("id = ?", *move.CheckpointID).Count(&checkpoints).Error
+		if err != nil {
+			return err
+		}
+		if checkpoints == 0 {
+			return customerrors.ErrCheckpointNotFound
+		}
+	}
+	return nil
+}
+
+// CreateStockMovement implements ItemRepository
+func (r *itemRepositoryImpl) CreateStockMovement(move *model.StockMovement, ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkStockMovement(tx, move); err != nil {
+			return err
+		}
+		return stock.Move(tx, move)
+	})
+}
+
+// TransferStock implements ItemRepository, stock out of source and stock in of destination
+// are saved together so total stock is kept. Batches taken out of source are received in
+// destination with the same supplier and dates
+func (r *itemRepositoryImpl) TransferStock(out *model.StockMovement, in *model.StockMovement, ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, move := range []*model.StockMovement{out, in} {
+			if err := checkStockMovement(tx, move); err != nil {
+				return err
+			}
+		}
+		if err := stock.Move(tx, out); err != nil {
+			return err
+		}
+		for _, batch := range out.Batches {
+			in.Batches = append(in.Batches, model.StockBatchMove{
+				Qty: -batch.Qty,
+				Batch: &model.StockBatch{
+					Supplier:    batch.Batch.Supplier,
+					SupplierID:  batch.Batch.SupplierID,
+					HarvestedAt: batch.Batch.HarvestedAt,
+					ExpiredAt:   batch.Batch.ExpiredAt,
+				},
+			})
+		}
+		return stock.Move(tx, in)
+	})
+}
+
+// FindExpiringBatches implements ItemRepository, batch with stock left expiring until the time,
+// the first to expire first
+func (r *itemRepositoryImpl) FindExpiringBatches(until time.Time, ctx context.Context) ([]model.ExpiringBatch, error) {
+	var batches []model.ExpiringBatch
+	err := r.db.WithContext(ctx).Table("stock_batches").
+		Select("stock_batches.*, items.name AS item_name, COALESCE(item_variants.name, '') AS variant_name, COALESCE(checkpoints.name, '') AS checkpoint_name").
+		Joins("JOIN items ON items.id = stock_batches.item_id").
+		Joins("LEFT JOIN item_variants ON item_variants.id = stock_batches.variant_id").
+		Joins("LEFT JOIN checkpoints ON checkpoints.id = stock_batches.checkpoint_id").
+		Where("stock_batches.qty > 0 AND stock_batches.expired_at <= ?", until).
+		Order("stock_batches.expired_at, stock_batches.id").
+		Scan(&batches).Error
+	if err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+// WriteOffBatch implements ItemRepository, stock left in expired batch is taken out as spoilage
+// by the system. Batch taken in the meantime fail with ErrStockNotEnough and is written off on next run
+func (r *itemRepositoryImpl) WriteOffBatch(batch *model.StockBatch, ctx context.Context) error {
+	move := model.StockMovement{
+		ItemID:       batch.ItemID,
+		CheckpointID: batch.CheckpointID,
+		Qty:          -batch.Qty,
+		Reason:       constants.Stock_reason_spoilage,
+		Note:         constants.Stock_note_expired_batch,
+		Batches:      []model.StockBatchMove{{BatchID: batch.ID, Qty: -batch.Qty}},
+	}
+	if batch.VariantID != 0 {
+		variantId := batch.VariantID
+		move.VariantID = &variantId
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return stock.Move(tx, &move)
+	})
+}
+
+// FindItemStocks implements ItemRepository, stock of item and its variants in every checkpoint
+func (r *itemRepositoryImpl) FindItemStocks(itemId uint, ctx context.Context) ([]model.CheckpointStock, error) {
+	var stocks []model.CheckpointStock
+	err := r.db.WithContext(ctx).Preload("Checkpoint").Where("item_id = ?", itemId).Order("checkpoint_id, variant_id").Find(&stocks).Error
+	if err != nil {
+		return nil, err
+	}
+	return stocks, nil
+}
+
+// FindCheckpointStocks implements ItemRepository. Qty is stock can be sold from the checkpoint, its
+// own stock and unallocated stock like stock.Take without expired batch, item or variant without both is not listed
+func (r *itemRepositoryImpl) FindCheckpointStocks(checkpointId uuid.UUID, itemIds []uint, ctx context.Context) ([]model.CheckpointStock, error) {
+	var stocks []model.CheckpointStock
+	if len(itemIds) == 0 {
+		return stocks, nil
+	}
+	err := r.db.WithContext(ctx).Where("checkpoint_id = ? AND item_id IN ?", checkpointId, itemIds).Find(&stocks).Error
+	if err != nil {
+		return nil, err
+	}
+	var unallocated []model.CheckpointStock
+	err = r.db.WithContext(ctx).Model(&model.Item{}).
+		Select("items.id AS item_id, 0 AS variant_id, items.qty - (SELECT COALESCE(SUM(qty), 0) FROM checkpoint_stocks WHERE item_id = items.id AND variant_id = 0) AS qty").
+		Where("items.id IN ?", itemIds).Scan(&unallocated).Error
+	if err != nil {
+		return nil, err
+	}
+	var variants []model.CheckpointStock
+	err = r.db.WithContext(ctx).Model(&model.ItemVariant{}).
+		Select("item_variants.item_id, item_variants.id AS variant_id, item_variants.qty - (SELECT COALESCE(SUM(qty), 0) FROM checkpoint_stocks WHERE item_id = item_variants.item_id AND variant_id = item_variants.id) AS qty").
+		Where("item_variants.item_id IN ?", itemIds).Scan(&variants).Error
+	if err != nil {
+		return nil, err
+	}
+	// expired batch wait to be written off, it is never sold
+	var expired []model.CheckpointStock
+	err = r.db.WithContext(ctx).Model(&model.StockBatch{}).
+		Select("item_id, variant_id, -SUM(qty) AS qty").
+		Where("item_id IN ? AND qty > 0 AND expired_at <= ?", itemIds, time.Now()).
+		Where("checkpoint_id = ? OR checkpoint_id IS NULL", checkpointId).
+		Group("item_id, variant_id").Scan(&expired).Error
+	if err != nil {
+		return nil, err
+	}
+	index := map[[2]uint]int{}
+	for i, each := range stocks {
+		index[[2]uint{each.ItemID, each.VariantID}] = i
+	}
+	for _, each := range append(append(unallocated, variants...), expired...) {
+		i, ok := index[[2]uint{each.ItemID, each.VariantID}]
+		if !ok {
+			if each.Qty <= 0 {
+				continue
+			}
+			stocks = append(stocks, model.CheckpointStock{CheckpointID: checkpointId, ItemID: each.ItemID, VariantID: each.VariantID})
+			i = len(stocks) - 1
+			index[[2]uint{each.ItemID, each.VariantID}] = i
+		}
+		stocks[i].Qty = quantity.Round(math.Max(stocks[i].Qty+each.Qty, 0))
+	}
+	return stocks, nil
+}
+
+// FindStockMovements implements ItemRepository, archived item still has its ledger
+func (r *itemRepositoryImpl) FindStockMovements(itemId uint, opts query.Options, ctx context.Context) ([]model.StockMovement, int64, error) {
+	var moves []model.StockMovement
+	total, err := query.Find(r.db.WithContext(ctx).Model(&model.StockMovement{}).Where("item_id = ?", itemId).Preload("Batches"), opts, &moves)
+	if err != nil {
+		return nil, 0, err
+	}
+	return moves, total, nil
+}
+
+// StockReport implements ItemRepository, compare stock of every active item and variant with its ledger
+func (r *itemRepositoryImpl) StockReport(ctx context.Context) ([]model.StockReport, error) {
+	var items, variants []model.StockReport
+	err := r.db.WithContext(ctx).Table("items").
+		Select("items.id AS item_id, items.name AS item_name, items.qty AS balance, COALESCE(SUM(stock_movements.qty), 0) AS ledger_total").
+		Joins("LEFT JOIN stock_movements ON stock_movements.item_id = items.id AND stock_movements.variant_id IS NULL").
+		Where("items.deleted_at IS NULL").
+		Group("items.id, items.name, items.qty").
+		Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	err = r.db.WithContext(ctx).Table("item_variants").
+		Select("item_variants.item_id, item_variants.id AS variant_id, items.name AS item_name, item_variants.name AS variant_name, " +
+			"item_variants.qty AS balance, COALESCE(SUM(stock_movements.qty), 0) AS ledger_total").
+		Joins("JOIN items ON items.id = item_variants.item_id").
+		Joins("LEFT JOIN stock_movements ON stock_movements.variant_id = item_variants.id").
+		Where("item_variants.deleted_at IS NULL AND items.deleted_at IS NULL").
+		Group("item_variants.item_id, item_variants.id, items.name, item_variants.name, item_variants.qty").
+		Scan(&variants).Error
+	if err != nil {
+		return nil, err
+	}
+	report := append(items, variants...)
+	sort.SliceStable(report, func(i, j int) bool {
+		if report[i].ItemID != report[j].ItemID {
+			return report[i].ItemID < report[j].ItemID
+		}
+		return report[j].VariantID != nil && (report[i].VariantID == nil || *report[i].VariantID < *report[j].VariantID)
+	})
+	return report, nil
+}
+
+// InitStockLedger implements ItemRepository, stock existing before the ledger is saved as opening
+// movement so ledger total match stock. Item or variant already in ledger is skipped
+func (r *itemRepositoryImpl) InitStockLedger() error {
+	now := time.Now()
+	err := r.db.Exec("INSERT INTO stock_movements (created_at, item_id, qty, balance, reason, note) "+
+		"SELECT ?, id, qty, qty, ?, '' FROM items WHERE qty <> 0 AND NOT EXISTS "+
+		"(SELECT 1 FROM stock_movements WHERE stock_movements.item_id = items.id AND stock_movements.variant_id IS NULL)",
+		now, constants.Stock_reason_opening).Error
+	if err != nil {
+		return err
+	}
+	return r.db.Exec("INSERT INTO stock_movements (created_at, item_id, variant_id, qty, balance, reason, note) "+
+		"SELECT ?, item_id, id, qty, qty, ?, '' FROM item_variants WHERE qty <> 0 AND NOT EXISTS "+
+		"(SELECT 1 FROM stock_movements WHERE stock_movements.variant_id = item_variants.id)",
+		now, constants.Stock_reason_opening).Error
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/database/testdb"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/stock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemStockRepository(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	actorId := uuid.New()
+
+	// stock saved before the ledger get opening movement once
+	sayur := model.Category{Name: "sayur"}
+	assert.NoError(t, db.Create(&sayur).Error)
+	bayam := model.Item{Name: "bayam", CategoryID: sayur.ID, Qty: 5, Price: 3000}
+	assert.NoError(t, db.Create(&bayam).Error)
+	repository := NewItemRepository(db)
+
+	cabai := model.Item{Name: "cabai", CategoryID: sayur.ID, UnitID: constants.Unit_kg_id, Qty: 2.5, Price: 40000}
+	assert.NoError(t, repository.CreateItem(&cabai, actorId, ctx))
+	merah := model.ItemVariant{ItemID: cabai.ID, Name: "merah", Qty: 1, Price: 50000}
+	assert.NoError(t, repository.CreateVariant(&merah, actorId, ctx))
+
+	// counted qty save the difference, uncounted qty keep stock
+	assert.NoError(t, repository.UpdateItem(&model.Item{ID: bayam.ID, Qty: 3}, actorId, ctx))
+	assert.NoError(t, repository.UpdateItem(&model.Item{ID: bayam.ID, Price: 3500, Qty: stock.Uncounted}, actorId, ctx))
+	assert.NoError(t, repository.SaveItems([]model.Item{{ID: cabai.ID, Name: "cabai", CategoryID: sayur.ID, UnitID: constants.Unit_kg_id, Qty: stock.Uncounted}}, actorId, ctx))
+
+	spoilage := model.StockMovement{ItemID: bayam.ID, Qty: -1, Reason: constants.Stock_reason_spoilage, ActorID: &actorId}
+	assert.NoError(t, repository.CreateStockMovement(&spoilage, ctx))
+	assert.Equal(t, 2.0, spoilage.Balance)
+	assert.Equal(t, customerrors.ErrStockNotEnough, repository.CreateStockMovement(&model.StockMovement{ItemID: bayam.ID, Qty: -3, Reason: constants.Stock_reason_spoilage}, ctx))
+	assert.Equal(t, customerrors.ErrInvalidVariant, repository.CreateStockMovement(&model.StockMovement{ItemID: bayam.ID, VariantID: &merah.ID, Qty: 1, Reason: constants.Stock_reason_purchase}, ctx))
+	assert.Equal(t, customerrors.ErrNotFound, repository.CreateStockMovement(&model.StockMovement{ItemID: 99, Qty: 1, Reason: constants.Stock_reason_purchase}, ctx))
+	purchase := model.StockMovement{ItemID: cabai.ID, VariantID: &merah.ID, Qty: 0.75, Reason: constants.Stock_reason_purchase, ActorID: &actorId}
+	assert.NoError(t, repository.CreateStockMovement(&purchase, ctx))
+	assert.Equal(t, 1.75, purchase.Balance)
+
+	moves, total, err := repository.FindStockMovements(bayam.ID, query.Options{Page: 1, Limit: 20, Sort: "id", Key: "id"}, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, []string{constants.Stock_reason_opening, constants.Stock_reason_adjustment, constants.Stock_reason_spoilage}, []string{moves[0].Reason, moves[1].Reason, moves[2].Reason})
+	assert.Equal(t, []float64{5, -2, -1}, []float64{moves[0].Qty, moves[1].Qty, moves[2].Qty})
+	assert.Nil(t, moves[0].ActorID)
+	assert.Equal(t, &actorId, moves[1].ActorID)
+
+	// stock changed without ledger show in report
+	assert.NoError(t, db.Model(&model.Item{}).Where("id = ?", cabai.ID).UpdateColumn("qty", 3).Error)
+	report, err := repository.StockReport(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.StockReport{
+		{ItemID: bayam.ID, ItemName: "bayam", Balance: 2, LedgerTotal: 2},
+		{ItemID: cabai.ID, ItemName: "cabai", Balance: 3, LedgerTotal: 2.5},
+		{ItemID: cabai.ID, VariantID: &merah.ID, ItemName: "cabai", VariantName: "merah", Balance: 1.75, LedgerTotal: 1.75},
+	}, report)
+
+	// ledger already has opening, init again add nothing
+	assert.NoError(t, repository.InitStockLedger())
+	_, total, err = repository.FindStockMovements(bayam.ID, query.Options{Page: 1, Limit: 20, Sort: "id", Key: "id"}, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+
+	// uncounted variant keep stock, counted zero empty it
+	var variant model.ItemVariant
+	assert.NoError(t, repository.UpdateVariant(&model.ItemVariant{ID: merah.ID, ItemID: cabai.ID, Name: "merah", Price: 55000, Qty: stock.Uncounted}, actorId, ctx))
+	assert.NoError(t, db.First(&variant, merah.ID).Error)
+	assert.Equal(t, 1.75, variant.Qty)
+	assert.Equal(t, 55000, variant.Price)
+	assert.NoError(t, repository.UpdateVariant(&model.ItemVariant{ID: merah.ID, ItemID: cabai.ID, Name: "merah", Price: 55000}, actorId, ctx))
+	assert.NoError(t, db.First(&variant, merah.ID).Error)
+	assert.Equal(t, 0.0, variant.Qty)
+}
+
+func TestCheckpointStock(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	pasar, gudang := model.Checkpoint{ID: uuid.New(), Name: "pasar"}, model.Checkpoint{ID: uuid.New(), Name: "gudang"}
+	assert.NoError(t, db.Create(&[]model.Checkpoint{pasar, gudang}).Error)
+	repository := NewItemRepository(db)
+
+	bayam := model.Item{Name: "bayam", Qty: 5, Price: 3000}
+	assert.NoError(t, repository.CreateItem(&bayam, uuid.New(), ctx))
+
+	transfer := func(from *uuid.UUID, to *uuid.UUID, qty float64) error {
+		return repository.TransferStock(
+			&model.StockMovement{ItemID: bayam.ID, CheckpointID: from, Qty: -qty, Reason: constants.Stock_reason_transfer},
+			&model.StockMovement{ItemID: bayam.ID, CheckpointID: to, Qty: qty, Reason: constants.Stock_reason_transfer}, ctx)
+	}
+	assert.NoError(t, transfer(nil, &gudang.ID, 4))
+	assert.NoError(t, transfer(&gudang.ID, &pasar.ID, 1.5))
+	assert.NoError(t, transfer(&gudang.ID, &pasar.ID, 0.5))
+	assert.Equal(t, customerrors.ErrStockNotEnough, transfer(&gudang.ID, &pasar.ID, 3))
+	unknown := uuid.New()
+	assert.Equal(t, customerrors.ErrCheckpointNotFound, transfer(&gudang.ID, &unknown, 1))
+
+	// only 1 is left unallocated, stock in checkpoint cant be taken without its checkpoint
+	assert.Equal(t, customerrors.ErrStockNotEnough, repository.CreateStockMovement(&model.StockMovement{ItemID: bayam.ID, Qty: -2, Reason: constants.Stock_reason_spoilage}, ctx))
+	assert.NoError(t, repository.CreateStockMovement(&model.StockMovement{ItemID: bayam.ID, CheckpointID: &pasar.ID, Qty: -0.5, Reason: constants.Stock_reason_spoilage}, ctx))
+	assert.Equal(t, customerrors.ErrStockNotEnough, repository.UpdateItem(&model.Item{ID: bayam.ID, Qty: 3}, uuid.New(), ctx))
+
+	stocks, err := repository.FindItemStocks(bayam.ID, ctx)
+	assert.NoError(t, err)
+	qty := map[string]float64{}
+	for _, each := range stocks {
+		qty[each.Checkpoint.Name] = each.Qty
+	}
+	assert.Equal(t, map[string]float64{"pasar": 1.5, "gudang": 2}, qty)
+
+	// unallocated stock can be taken from any checkpoint
+	stocks, err = repository.FindCheckpointStocks(pasar.ID, []uint{bayam.ID}, ctx)
+	assert.NoError(t, err)
+	assert.Len(t, stocks, 1)
+	assert.Equal(t, 2.5, stocks[0].Qty)
+
+	// transfer keep total, spoilage is taken out of it
+	var item model.Item
+	assert.NoError(t, db.First(&item, bayam.ID).Error)
+	assert.Equal(t, 4.5, item.Qty)
+}
+
+func TestStockBatch(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	now := time.Now()
+	pasar := model.Checkpoint{ID: uuid.New(), Name: "pasar"}
+	assert.NoError(t, db.Create(&pasar).Error)
+	repository := NewItemRepository(db)
+
+	// 1 without batch, then batch expiring tomorrow and next week
+	bayam := model.Item{Name: "bayam", Qty: 1, Price: 3000}
+	assert.NoError(t, repository.CreateItem(&bayam, uuid.New(), ctx))
+	for _, expiredAt := range []time.Time{now.AddDate(0, 0, 7), now.AddDate(0, 0, 1)} {
+		purchase := model.StockMovement{ItemID: bayam.ID, Qty: 2, Reason: constants.Stock_reason_purchase,
+			Batches: []model.StockBatchMove{{Qty: 2, Batch: &model.StockBatch{Supplier: "tani makmur", ExpiredAt: expiredAt}}}}
+		assert.NoError(t, repository.CreateStockMovement(&purchase, ctx))
+	}
+
+	// batch expiring first go out first, stock without batch never expire so it go out last
+	transfer := []*model.StockMovement{
+		{ItemID: bayam.ID, Qty: -2, Reason: constants.Stock_reason_transfer},
+		{ItemID: bayam.ID, CheckpointID: &pasar.ID, Qty: 2, Reason: constants.Stock_reason_transfer},
+	}
+	assert.NoError(t, repository.TransferStock(transfer[0], transfer[1], ctx))
+	var batches []model.StockBatch
+	assert.NoError(t, db.Order("id").Find(&batches).Error)
+	assert.Len(t, batches, 3)
+	assert.Equal(t, []float64{2, 0, 2}, []float64{batches[0].Qty, batches[1].Qty, batches[2].Qty})
+	assert.Equal(t, &pasar.ID, batches[2].CheckpointID)
+	assert.Equal(t, "tani makmur", batches[2].Supplier)
+	assert.True(t, batches[2].ExpiredAt.Equal(batches[1].ExpiredAt))
+
+	moves, _, err := repository.FindStockMovements(bayam.ID, query.Options{Page: 1, Limit: 20, Sort: "id", Key: "id"}, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.StockBatchMove{{ID: 3, MovementID: moves[3].ID, BatchID: batches[1].ID, Qty: -2}}, moves[3].Batches)
+
+	// batch of checkpoint expiring tomorrow is listed, then written off
+	expiring, err := repository.FindExpiringBatches(now.AddDate(0, 0, 2), ctx)
+	assert.NoError(t, err)
+	assert.Len(t, expiring, 1)
+	assert.Equal(t, "pasar", expiring[0].CheckpointName)
+	assert.Equal(t, "bayam", expiring[0].ItemName)
+	assert.NoError(t, repository.WriteOffBatch(&expiring[0].StockBatch, ctx))
+	assert.Equal(t, customerrors.ErrStockNotEnough, repository.WriteOffBatch(&expiring[0].StockBatch, ctx))
+
+	// nothing left in checkpoint and 3 unallocated
+	stocks, err := repository.FindCheckpointStocks(pasar.ID, []uint{bayam.ID}, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 3.0, stocks[0].Qty)
+	var item model.Item
+	assert.NoError(t, db.First(&item, bayam.ID).Error)
+	assert.Equal(t, 3.0, item.Qty)
+}
+
+// expired batch is not available to sell, sale take batch before stock without batch
+func TestStockBatchExpired(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	now := time.Now()
+	pasar := model.Checkpoint{ID: uuid.New(), Name: "pasar"}
+	assert.NoError(t, db.Create(&pasar).Error)
+	repository := NewItemRepository(db)
+
+	// 1 without batch, 2 in expired batch and 1 in batch expiring next week
+	bayam := model.Item{Name: "bayam", Qty: 1, Price: 3000}
+	assert.NoError(t, repository.CreateItem(&bayam, uuid.New(), ctx))
+	for _, batch := range []model.StockBatchMove{
+		{Qty: 2, Batch: &model.StockBatch{ExpiredAt: now.AddDate(0, 0, -1)}},
+		{Qty: 1, Batch: &model.StockBatch{ExpiredAt: now.AddDate(0, 0, 7)}},
+	} {
+		purchase := model.StockMovement{ItemID: bayam.ID, Qty: batch.Qty, Reason: constants.Stock_reason_purchase, Batches: []model.StockBatchMove{batch}}
+		assert.NoError(t, repository.CreateStockMovement(&purchase, ctx))
+	}
+
+	stocks, err := repository.FindCheckpointStocks(pasar.ID, []uint{bayam.ID}, ctx)
+	assert.NoError(t, err)
+	assert.Len(t, stocks, 1)
+	assert.Equal(t, 2.0, stocks[0].Qty)
+
+	assert.Equal(t, customerrors.ErrStockNotEnough, repository.CreateStockMovement(&model.StockMovement{ItemID: bayam.ID, Qty: -3, Reason: constants.Stock_reason_sale}, ctx))
+	sale := model.StockMovement{ItemID: bayam.ID, Qty: -1, Reason: constants.Stock_reason_sale}
+	assert.NoError(t, repository.CreateStockMovement(&sale, ctx))
+	var batches []model.StockBatch
+	assert.NoError(t, db.Order("id").Find(&batches).Error)
+	assert.Equal(t, []float64{2, 0}, []float64{batches[0].Qty, batches[1].Qty})
+	assert.Len(t, sale.Batches, 1)
+	assert.Equal(t, batches[1].ID, sale.Batches[0].BatchID)
+}
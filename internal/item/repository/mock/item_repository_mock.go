@@ -2,8 +2,11 @@ package mock
 
 import (
 	"context"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -11,19 +14,19 @@ type ItemRepositoryMock struct {
 	mock.Mock
 }
 
-func (b *ItemRepositoryMock) CreateItem(item *model.Item, ctx context.Context) error {
+func (b *ItemRepositoryMock) CreateItem(item *model.Item, actorId uuid.UUID, ctx context.Context) error {
 	args := b.Called()
 	return args.Error(0)
 }
 
-func (b *ItemRepositoryMock) UpdateItem(item *model.Item, ctx context.Context) error {
+func (b *ItemRepositoryMock) UpdateItem(item *model.Item, actorId uuid.UUID, ctx context.Context) error {
 	args := b.Called()
 	return args.Error(0)
 }
 
-func (b *ItemRepositoryMock) FindItems(ctx context.Context) ([]model.Item, error) {
+func (b *ItemRepositoryMock) FindItems(opts query.Options, ctx context.Context) ([]model.Item, int64, error) {
 	args := b.Called()
-	return args.Get(0).([]model.Item), args.Error(1)
+	return args.Get(0).([]model.Item), args.Get(1).(int64), args.Error(2)
 }
 
 func (b *ItemRepositoryMock) FindItemById(item *model.Item, ctx context.Context) error {
@@ -31,9 +34,9 @@ func (b *ItemRepositoryMock) FindItemById(item *model.Item, ctx context.Context)
 	return args.Error(0)
 }
 
-func (b *ItemRepositoryMock) FindItemsByCategory(categoryId uint, ctx context.Context) ([]model.Item, error) {
+func (b *ItemRepositoryMock) FindItemsByCategory(categoryId uint, opts query.Options, ctx context.Context) ([]model.Item, int64, error) {
 	args := b.Called()
-	return args.Get(0).([]model.Item), args.Error(1)
+	return args.Get(0).([]model.Item), args.Get(1).(int64), args.Error(2)
 }
 
 func (b *ItemRepositoryMock) CreateCategory(category *model.Category, ctx context.Context) error {
@@ -41,7 +44,147 @@ func (b *ItemRepositoryMock) CreateCategory(category *model.Category, ctx contex
 	return args.Error(0)
 }
 
-func (b *ItemRepositoryMock) FindCategories(ctx context.Context) ([]model.Category, error) {
+func (b *ItemRepositoryMock) FindCategories(opts query.Options, ctx context.Context) ([]model.Category, int64, error) {
+	args := b.Called()
+	return args.Get(0).([]model.Category), args.Get(1).(int64), args.Error(2)
+}
+
+func (b *ItemRepositoryMock) UpdateItemImage(item *model.Item, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *ItemRepositoryMock) FindCategoryById(category *model.Category, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *ItemRepositoryMock) UpdateCategoryImage(category *model.Category, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *ItemRepositoryMock) CreateVariant(variant *model.ItemVariant, actorId uuid.UUID, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *ItemRepositoryMock) UpdateVariant(variant *model.ItemVariant, actorId uuid.UUID, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *ItemRepositoryMock) DeleteVariant(variant *model.ItemVariant, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *ItemRepositoryMock) FindUnits(ctx context.Context) ([]model.Unit, error) {
+	args := b.Called()
+	return args.Get(0).([]model.Unit), args.Error(1)
+}
+
+func (b *ItemRepositoryMock) DeleteItem(item *model.Item, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *ItemRepositoryMock) RestoreItem(item *model.Item, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *ItemRepositoryMock) FindArchivedItems(opts query.Options, ctx context.Context) ([]model.Item, int64, error) {
+	args := b.Called()
+	return args.Get(0).([]model.Item), args.Get(1).(int64), args.Error(2)
+}
+
+func (b *ItemRepositoryMock) DeleteCategory(category *model.Category, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *ItemRepositoryMock) RestoreCategory(category *model.Category, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *ItemRepositoryMock) FindArchivedCategories(opts query.Options, ctx context.Context) ([]model.Category, int64, error) {
+	args := b.Called()
+	return args.Get(0).([]model.Category), args.Get(1).(int64), args.Error(2)
+}
+
+func (b *ItemRepositoryMock) FindItemsByName(names []string, ctx context.Context) ([]model.Item, error) {
+	args := b.Called()
+	return args.Get(0).([]model.Item), args.Error(1)
+}
+
+func (b *ItemRepositoryMock) SaveItems(items []model.Item, actorId uuid.UUID, ctx context.Context) error {
+	args := b.Called(items)
+	return args.Error(0)
+}
+
+func (b *ItemRepositoryMock) ExportItems(ctx context.Context) ([]model.Item, error) {
+	args := b.Called()
+	return args.Get(0).([]model.Item), args.Error(1)
+}
+
+func (b *ItemRepositoryMock) FindCategoriesByName(names []string, ctx context.Context) ([]model.Category, error) {
+	args := b.Called()
+	return args.Get(0).([]model.Category), args.Error(1)
+}
+
+func (b *ItemRepositoryMock) SaveCategories(categories []model.Category, ctx context.Context) error {
+	args := b.Called(categories)
+	return args.Error(0)
+}
+
+func (b *ItemRepositoryMock) ExportCategories(ctx context.Context) ([]model.Category, error) {
 	args := b.Called()
 	return args.Get(0).([]model.Category), args.Error(1)
 }
+
+func (b *ItemRepositoryMock) CreateStockMovement(move *model.StockMovement, ctx context.Context) error {
+	args := b.Called(move)
+	return args.Error(0)
+}
+
+func (b *ItemRepositoryMock) TransferStock(out *model.StockMovement, in *model.StockMovement, ctx context.Context) error {
+	args := b.Called(out, in)
+	return args.Error(0)
+}
+
+func (b *ItemRepositoryMock) FindExpiringBatches(until time.Time, ctx context.Context) ([]model.ExpiringBatch, error) {
+	args := b.Called()
+	return args.Get(0).([]model.ExpiringBatch), args.Error(1)
+}
+
+func (b *ItemRepositoryMock) WriteOffBatch(batch *model.StockBatch, ctx context.Context) error {
+	args := b.Called(batch)
+	return args.Error(0)
+}
+
+func (b *ItemRepositoryMock) FindItemStocks(itemId uint, ctx context.Context) ([]model.CheckpointStock, error) {
+	args := b.Called()
+	return args.Get(0).([]model.CheckpointStock), args.Error(1)
+}
+
+func (b *ItemRepositoryMock) FindCheckpointStocks(checkpointId uuid.UUID, itemIds []uint, ctx context.Context) ([]model.CheckpointStock, error) {
+	args := b.Called()
+	return args.Get(0).([]model.CheckpointStock), args.Error(1)
+}
+
+func (b *ItemRepositoryMock) FindStockMovements(itemId uint, opts query.Options, ctx context.Context) ([]model.StockMovement, int64, error) {
+	args := b.Called()
+	return args.Get(0).([]model.StockMovement), args.Get(1).(int64), args.Error(2)
+}
+
+func (b *ItemRepositoryMock) StockReport(ctx context.Context) ([]model.StockReport, error) {
+	args := b.Called()
+	return args.Get(0).([]model.StockReport), args.Error(1)
+}
+
+func (b *ItemRepositoryMock) InitStockLedger() error {
+	args := b.Called()
+	return args.Error(0)
+}
@@ -0,0 +1,38 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"github.com/stretchr/testify/mock"
+)
+
+type ItemSearchRepositoryMock struct {
+	mock.Mock
+}
+
+func (b *ItemSearchRepositoryMock) IndexItem(item *model.Item, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *ItemSearchRepositoryMock) RebuildIndex(ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *ItemSearchRepositoryMock) EnsureIndex(ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *ItemSearchRepositoryMock) SearchItems(search model.ItemSearch, opts query.Options, ctx context.Context) ([]model.Item, int64, error) {
+	args := b.Called()
+	return args.Get(0).([]model.Item), args.Get(1).(int64), args.Error(2)
+}
+
+func (b *ItemSearchRepositoryMock) FacetCategories(search model.ItemSearch, ctx context.Context) ([]model.CategoryFacet, error) {
+	args := b.Called()
+	return args.Get(0).([]model.CategoryFacet), args.Error(1)
+}
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/item/dto"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/quantity"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/stock"
+)
+
+// first data row, header is row 1
+const importFirstRow = 2
+
+// ImportItems implements ItemService. Row is matched to item by name, matched item is updated
+// and the other created. Nothing is saved when any row is invalid or on dry run
+func (s *itemServiceImpl) ImportItems(data []byte, body dto.ImportRequest, userId string, ctx context.Context) (*dto.ImportResponse, error) {
+	actorId, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	var rows []dto.ItemImportRow
+	if err := s.importCsv.Unmarshal(data, body.Format, &rows); err != nil {
+		return nil, err
+	}
+	names, err := importNames(len(rows), func(i int) (string, bool) { return rows[i].Name, rows[i].IsEmpty() })
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.FindItemsByName(names, ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := map[string]model.Item{}
+	for _, item := range existing {
+		items[importKey(item.Name)] = item
+	}
+	var categoryNames []string
+	for _, row := range rows {
+		if row.Category != "" {
+			categoryNames = append(categoryNames, strings.TrimSpace(row.Category))
+		}
+	}
+	found, err := s.repo.FindCategoriesByName(categoryNames, ctx)
+	if err != nil {
+		return nil, err
+	}
+	categories := map[string]uint{}
+	for _, category := range found {
+		if !category.DeletedAt.Valid {
+			categories[importKey(category.Name)] = category.ID
+		}
+	}
+	units, err := s.repo.FindUnits(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.ImportResponse{DryRun: body.DryRun, Errors: []dto.ImportRowError{}}
+	var saved []model.Item
+	seen := map[string]bool{}
+	for i, row := range rows {
+		if row.IsEmpty() {
+			continue
+		}
+		key := importKey(row.Name)
+		item, err := itemFromRow(row, items, categories, units)
+		if key != "" && seen[key] {
+			err = customerrors.ErrRowDuplicate
+		}
+		seen[key] = true
+		if err != nil {
+			res.Errors = append(res.Errors, dto.ImportRowError{Row: i + importFirstRow, Message: err.Error()})
+			continue
+		}
+		if item.ID == 0 {
+			res.Created++
+		} else {
+			res.Updated++
+		}
+		saved = append(saved, item)
+	}
+	if len(res.Errors) > 0 {
+		return res, customerrors.ErrImportInvalid
+	}
+	if body.DryRun {
+		return res, nil
+	}
+	if err := s.repo.SaveItems(saved, actorId, ctx); err != nil {
+		return nil, err
+	}
+	for i := range saved {
+		s.indexItem(&saved[i], ctx)
+	}
+	return res, nil
+}
+
+// itemFromRow apply row to existing item or new item, empty value keep value of existing item
+func itemFromRow(row dto.ItemImportRow, items map[string]model.Item, categories map[string]uint, units []model.Unit) (model.Item, error) {
+	name := strings.TrimSpace(row.Name)
+	if name == "" {
+		return model.Item{}, customerrors.ErrRowName
+	}
+	item, ok := items[importKey(name)]
+	if ok && item.DeletedAt.Valid {
+		return model.Item{}, customerrors.ErrRowArchived
+	}
+	if !ok {
+		item = model.Item{UnitID: constants.Unit_piece_id}
+	} else {
+		// stock is changed by sale since item is read, only counted qty replace it
+		item.Qty = stock.Uncounted
+	}
+	item.Name = name
+	if row.Description != "" {
+		item.Description = strings.TrimSpace(row.Description)
+	}
+	if row.Category != "" {
+		id, ok := categories[importKey(row.Category)]
+		if !ok {
+			return model.Item{}, customerrors.ErrRowCategory
+		}
+		item.CategoryID = id
+	}
+	if item.CategoryID == 0 {
+		return model.Item{}, customerrors.ErrRowCategory
+	}
+	if row.Unit != "" {
+		unitId, ok := findUnit(row.Unit, units)
+		if !ok {
+			return model.Item{}, customerrors.ErrRowUnit
+		}
+		item.UnitID = unitId
+	}
+	if row.Qty != "" {
+		qty, err := strconv.ParseFloat(strings.TrimSpace(row.Qty), 64)
+		if err != nil || qty < 0 {
+			return model.Item{}, customerrors.ErrRowQty
+		}
+		item.Qty = quantity.Round(qty)
+	}
+	if row.Price != "" {
+		price, err := strconv.Atoi(strings.TrimSpace(row.Price))
+		if err != nil || price < 0 {
+			return model.Item{}, customerrors.ErrRowPrice
+		}
+		item.Price = price
+	}
+	return item, nil
+}
+
+// findUnit match unit by symbol or name
+func findUnit(value string, units []model.Unit) (uint, bool) {
+	for _, unit := range units {
+		if importKey(value) == importKey(unit.Symbol) || importKey(value) == importKey(unit.Name) {
+			return unit.ID, true
+		}
+	}
+	return 0, false
+}
+
+// ImportCategories implements ItemService, category is matched by name like ImportItems
+func (s *itemServiceImpl) ImportCategories(data []byte, body dto.ImportRequest, ctx context.Context) (*dto.ImportResponse, error) {
+	var rows []dto.CategoryImportRow
+	if err := s.importCsv.Unmarshal(data, body.Format, &rows); err != nil {
+		return nil, err
+	}
+	names, err := importNames(len(rows), func(i int) (string, bool) { return rows[i].Name, rows[i].IsEmpty() })
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.FindCategoriesByName(names, ctx)
+	if err != nil {
+		return nil, err
+	}
+	categories := map[string]model.Category{}
+	for _, category := range existing {
+		categories[importKey(category.Name)] = category
+	}
+
+	res := &dto.ImportResponse{DryRun: body.DryRun, Errors: []dto.ImportRowError{}}
+	var saved []model.Category
+	seen := map[string]bool{}
+	for i, row := range rows {
+		if row.IsEmpty() {
+			continue
+		}
+		name := strings.TrimSpace(row.Name)
+		category, ok := categories[importKey(name)]
+		var err error
+		switch {
+		case name == "":
+			err = customerrors.ErrRowName
+		case seen[importKey(name)]:
+			err = customerrors.ErrRowDuplicate
+		case ok && category.DeletedAt.Valid:
+			err = customerrors.ErrRowArchived
+		}
+		seen[importKey(name)] = true
+		if err != nil {
+			res.Errors = append(res.Errors, dto.ImportRowError{Row: i + importFirstRow, Message: err.Error()})
+			continue
+		}
+		category.Name = name
+		if row.Description != "" {
+			category.Description = strings.TrimSpace(row.Description)
+		}
+		if category.ID == 0 {
+			res.Created++
+		} else {
+			res.Updated++
+		}
+		saved = append(saved, category)
+	}
+	if len(res.Errors) > 0 {
+		return res, customerrors.ErrImportInvalid
+	}
+	if body.DryRun {
+		return res, nil
+	}
+	if err := s.repo.SaveCategories(saved, ctx); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ExportItems implements ItemService, file has the same column as import file
+func (s *itemServiceImpl) ExportItems(body dto.ExportRequest, ctx context.Context) ([]byte, error) {
+	items, err := s.repo.ExportItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows := dto.ItemImportRows{}
+	rows.FromModel(items)
+	return s.importCsv.Marshal(&rows, body.Format)
+}
+
+// ExportCategories implements ItemService
+func (s *itemServiceImpl) ExportCategories(body dto.ExportRequest, ctx context.Context) ([]byte, error) {
+	categories, err := s.repo.ExportCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows := dto.CategoryImportRows{}
+	rows.FromModel(categories)
+	return s.importCsv.Marshal(&rows, body.Format)
+}
+
+// importNames collect name of non blank row, fail when there is no row or too many row
+func importNames(count int, row func(i int) (string, bool)) ([]string, error) {
+	var names []string
+	rows := 0
+	for i := 0; i < count; i++ {
+		name, empty := row(i)
+		if empty {
+			continue
+		}
+		if rows++; rows > constants.Import_max_rows {
+			return nil, customerrors.ErrImportRows
+		}
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if rows == 0 {
+		return nil, customerrors.ErrImportRows
+	}
+	return names, nil
+}
+
+// importKey compare name case insensitive, same as unique index of mysql default collation
+func importKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
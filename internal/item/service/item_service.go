@@ -4,13 +4,37 @@ import (
 	"context"
 
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/item/dto"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
 type ItemService interface {
-	CreateItem(body dto.ItemRequest, ctx context.Context) (uint, error)
-	UpdateItem(id string, body dto.ItemRequest, ctx context.Context) error
-	FindItems(ctx context.Context) (dto.ItemsResponse, error)
-	FindItemsByCategory(categoryId string, ctx context.Context) (dto.ItemsResponse, error)
+	CreateItem(body dto.ItemRequest, userId string, ctx context.Context) (uint, error)
+	UpdateItem(id string, body dto.ItemRequest, userId string, ctx context.Context) error
+	FindItems(opts query.Options, body dto.ItemAvailabilityRequest, userId string, ctx context.Context) (dto.ItemsResponse, *query.Page, error)
+	SearchItems(body dto.ItemSearchRequest, opts query.Options, ctx context.Context) (*dto.ItemSearchResponse, *query.Page, error)
+	FindItemsByCategory(categoryId string, opts query.Options, ctx context.Context) (dto.ItemsResponse, *query.Page, error)
+	DeleteItem(id string, ctx context.Context) error
+	RestoreItem(id string, ctx context.Context) error
+	FindArchivedItems(opts query.Options, ctx context.Context) (dto.ItemsResponse, *query.Page, error)
+	UploadItemImage(id string, data []byte, ctx context.Context) (*dto.ImageResponse, error)
+	CreateVariant(itemId string, body dto.ItemVariantRequest, userId string, ctx context.Context) (uint, error)
+	UpdateVariant(itemId string, variantId string, body dto.ItemVariantRequest, userId string, ctx context.Context) error
+	DeleteVariant(itemId string, variantId string, ctx context.Context) error
+	CreateStockMovement(itemId string, body dto.StockMovementRequest, userId string, ctx context.Context) (*dto.StockMovementResponse, error)
+	TransferStock(itemId string, body dto.StockTransferRequest, userId string, ctx context.Context) (dto.StockMovementsResponse, error)
+	FindItemStocks(itemId string, ctx context.Context) (dto.ItemStocksResponse, error)
+	FindStockMovements(itemId string, opts query.Options, ctx context.Context) (dto.StockMovementsResponse, *query.Page, error)
+	FindExpiringBatches(body dto.ExpiringBatchRequest, ctx context.Context) (dto.ExpiringBatchesResponse, error)
+	StockReport(body dto.StockReportRequest, ctx context.Context) (*dto.StockReportResponse, error)
+	FindUnits(ctx context.Context) (dto.UnitsResponse, error)
 	CreateCategory(body dto.CategoryRequest, ctx context.Context) (uint, error)
-	FindCategories(ctx context.Context) (dto.CategoriesResponse, error)
+	UploadCategoryImage(id string, data []byte, ctx context.Context) (*dto.ImageResponse, error)
+	DeleteCategory(id string, ctx context.Context) error
+	RestoreCategory(id string, ctx context.Context) error
+	FindArchivedCategories(opts query.Options, ctx context.Context) (dto.CategoriesResponse, *query.Page, error)
+	ImportItems(data []byte, body dto.ImportRequest, userId string, ctx context.Context) (*dto.ImportResponse, error)
+	ExportItems(body dto.ExportRequest, ctx context.Context) ([]byte, error)
+	ImportCategories(data []byte, body dto.ImportRequest, ctx context.Context) (*dto.ImportResponse, error)
+	ExportCategories(body dto.ExportRequest, ctx context.Context) ([]byte, error)
+	FindCategories(opts query.Options, ctx context.Context) (dto.CategoriesResponse, *query.Page, error)
 }
@@ -2,15 +2,32 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"log"
 	"strconv"
 
+	"github.com/google/uuid"
+	cps "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/checkpoint/service"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/item/dto"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/item/repository"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	importcsv "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/import_csv"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/search"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/stock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/storage"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/thumbnail"
 )
 
 type itemServiceImpl struct {
-	repo repository.ItemRepository
+	repo       repository.ItemRepository
+	searchRepo repository.ItemSearchRepository
+	storage    storage.Storage
+	importCsv  importcsv.ImportCsv
+	// checkpointService find nearest checkpoint of the user for item availability
+	checkpointService cps.CheckpointService
 }
 
 // CreateCategory implements ItemService
@@ -24,70 +41,383 @@ func (s *itemServiceImpl) CreateCategory(body dto.CategoryRequest, ctx context.C
 }
 
 // CreateItem implements ItemService
-func (s *itemServiceImpl) CreateItem(body dto.ItemRequest, ctx context.Context) (uint, error) {
+func (s *itemServiceImpl) CreateItem(body dto.ItemRequest, userId string, ctx context.Context) (uint, error) {
+	actorId, err := uuid.Parse(userId)
+	if err != nil {
+		return 0, customerrors.ErrInvalidId
+	}
 	item := body.ToModel()
-	err := s.repo.CreateItem(item, ctx)
+	err = s.repo.CreateItem(item, actorId, ctx)
 	if err != nil {
 		return 0, err
 	}
+	s.indexItem(item, ctx)
 	return item.ID, err
 }
 
 // FindCategories implements ItemService
-func (s *itemServiceImpl) FindCategories(ctx context.Context) (dto.CategoriesResponse, error) {
-	categories, err := s.repo.FindCategories(ctx)
+func (s *itemServiceImpl) FindCategories(opts query.Options, ctx context.Context) (dto.CategoriesResponse, *query.Page, error) {
+	categories, total, err := s.repo.FindCategories(opts, ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	var categoriesResponse dto.CategoriesResponse
 	categoriesResponse.FromModel(categories)
-	return categoriesResponse, nil
+	return categoriesResponse, query.NewPage(opts, total), nil
 }
 
 // FindItemByCategory implements ItemService
-func (s *itemServiceImpl) FindItemsByCategory(categoryId string, ctx context.Context) (dto.ItemsResponse, error) {
+func (s *itemServiceImpl) FindItemsByCategory(categoryId string, opts query.Options, ctx context.Context) (dto.ItemsResponse, *query.Page, error) {
 	id, err := strconv.Atoi(categoryId)
 	if err != nil {
-		return nil, customerrors.ErrInvalidId
+		return nil, nil, customerrors.ErrInvalidId
 	}
-	items, err := s.repo.FindItemsByCategory(uint(id), ctx)
+	items, total, err := s.repo.FindItemsByCategory(uint(id), opts, ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	var itemsResponse dto.ItemsResponse
 	itemsResponse.FromModel(items)
-	return itemsResponse, nil
+	return itemsResponse, query.NewPage(opts, total), nil
 }
 
 // Findtems implements ItemService
-func (s *itemServiceImpl) FindItems(ctx context.Context) (dto.ItemsResponse, error) {
-	items, err := s.repo.FindItems(ctx)
+func (s *itemServiceImpl) FindItems(opts query.Options, body dto.ItemAvailabilityRequest, userId string, ctx context.Context) (dto.ItemsResponse, *query.Page, error) {
+	checkpointId, err := s.availabilityCheckpoint(body, userId, ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	items, total, err := s.repo.FindItems(opts, ctx)
+	if err != nil {
+		return nil, nil, err
 	}
 	var itemsResponse dto.ItemsResponse
 	itemsResponse.FromModel(items)
-	return itemsResponse, nil
+	if checkpointId != nil {
+		itemIds := make([]uint, len(items))
+		for i := range items {
+			itemIds[i] = items[i].ID
+		}
+		stocks, err := s.repo.FindCheckpointStocks(*checkpointId, itemIds, ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		itemsResponse.FromCheckpointStocks(*checkpointId, stocks)
+	}
+	return itemsResponse, query.NewPage(opts, total), nil
+}
+
+// availabilityCheckpoint find checkpoint whose stock is shown in item list, nearest checkpoint
+// is the first one covering region of the user. Nil when availability is not asked
+func (s *itemServiceImpl) availabilityCheckpoint(body dto.ItemAvailabilityRequest, userId string, ctx context.Context) (*uuid.UUID, error) {
+	if body.CheckpointID != "" {
+		return parseCheckpointId(body.CheckpointID)
+	}
+	if !body.Nearest {
+		return nil, nil
+	}
+	checkpoints, err := s.checkpointService.FindCheckpointsByUser(userId, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(checkpoints) == 0 {
+		return nil, customerrors.ErrCheckpointNotCovered
+	}
+	return &checkpoints[0].ID, nil
 }
 
 // UpdateItem implements ItemService
-func (s *itemServiceImpl) UpdateItem(id string, body dto.ItemRequest, ctx context.Context) error {
+func (s *itemServiceImpl) UpdateItem(id string, body dto.ItemRequest, userId string, ctx context.Context) error {
 	itemId, err := strconv.Atoi(id)
 	if err != nil {
 		return customerrors.ErrInvalidId
 	}
+	actorId, err := uuid.Parse(userId)
+	if err != nil {
+		return customerrors.ErrInvalidId
+	}
 	item := body.ToModel()
 	item.ID = uint(itemId)
+	if body.Qty == nil {
+		item.Qty = stock.Uncounted
+	}
+
+	err = s.repo.UpdateItem(item, actorId, ctx)
+	if err != nil {
+		return err
+	}
+	// zero field is not updated, so index the saved item
+	if err := s.repo.FindItemById(item, ctx); err != nil {
+		log.Printf("reload item %d for search index: %v", item.ID, err)
+		return nil
+	}
+	s.indexItem(item, ctx)
+	return nil
+}
+
+// SearchItems implements ItemService
+func (s *itemServiceImpl) SearchItems(body dto.ItemSearchRequest, opts query.Options, ctx context.Context) (*dto.ItemSearchResponse, *query.Page, error) {
+	if len(search.Words(body.Q)) == 0 {
+		return nil, nil, customerrors.ErrInvalidSearch
+	}
+	if body.MaxPrice != 0 && body.MinPrice > body.MaxPrice {
+		return nil, nil, customerrors.ErrInvalidFilter
+	}
+	filter := body.ToModel()
+	items, total, err := s.searchRepo.SearchItems(filter, opts, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	facets, err := s.searchRepo.FacetCategories(filter, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	res := dto.ItemSearchResponse{Items: dto.ItemsResponse{}}
+	res.Items.FromModel(items)
+	res.Facets.FromModel(facets)
+	return &res, query.NewPage(opts, total), nil
+}
+
+// UploadItemImage implements ItemService
+func (s *itemServiceImpl) UploadItemImage(id string, data []byte, ctx context.Context) (*dto.ImageResponse, error) {
+	itemId, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	item := model.Item{ID: uint(itemId)}
+	if err := s.repo.FindItemById(&item, ctx); err != nil {
+		return nil, err
+	}
+	image, err := s.storeImage(fmt.Sprintf("items/%d", item.ID), data)
+	if err != nil {
+		return nil, err
+	}
+	oldImage := item.Image
+	item.Image = *image
+	if err := s.repo.UpdateItemImage(&item, ctx); err != nil {
+		s.removeImage(*image)
+		return nil, err
+	}
+	s.removeImage(oldImage)
+	var res dto.ImageResponse
+	res.FromModel(image)
+	return &res, nil
+}
+
+// UploadCategoryImage implements ItemService
+func (s *itemServiceImpl) UploadCategoryImage(id string, data []byte, ctx context.Context) (*dto.ImageResponse, error) {
+	categoryId, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	category := model.Category{ID: uint(categoryId)}
+	if err := s.repo.FindCategoryById(&category, ctx); err != nil {
+		return nil, err
+	}
+	image, err := s.storeImage(fmt.Sprintf("categories/%d", category.ID), data)
+	if err != nil {
+		return nil, err
+	}
+	oldImage := category.Image
+	category.Image = *image
+	if err := s.repo.UpdateCategoryImage(&category, ctx); err != nil {
+		s.removeImage(*image)
+		return nil, err
+	}
+	s.removeImage(oldImage)
+	var res dto.ImageResponse
+	res.FromModel(image)
+	return &res, nil
+}
+
+// DeleteItem implements ItemService
+func (s *itemServiceImpl) DeleteItem(id string, ctx context.Context) error {
+	itemId, err := strconv.Atoi(id)
+	if err != nil {
+		return customerrors.ErrInvalidId
+	}
+	return s.repo.DeleteItem(&model.Item{ID: uint(itemId)}, ctx)
+}
+
+// RestoreItem implements ItemService
+func (s *itemServiceImpl) RestoreItem(id string, ctx context.Context) error {
+	itemId, err := strconv.Atoi(id)
+	if err != nil {
+		return customerrors.ErrInvalidId
+	}
+	return s.repo.RestoreItem(&model.Item{ID: uint(itemId)}, ctx)
+}
+
+// FindArchivedItems implements ItemService
+func (s *itemServiceImpl) FindArchivedItems(opts query.Options, ctx context.Context) (dto.ItemsResponse, *query.Page, error) {
+	items, total, err := s.repo.FindArchivedItems(opts, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var itemsResponse dto.ItemsResponse
+	itemsResponse.FromModel(items)
+	return itemsResponse, query.NewPage(opts, total), nil
+}
+
+// DeleteCategory implements ItemService
+func (s *itemServiceImpl) DeleteCategory(id string, ctx context.Context) error {
+	categoryId, err := strconv.Atoi(id)
+	if err != nil {
+		return customerrors.ErrInvalidId
+	}
+	return s.repo.DeleteCategory(&model.Category{ID: uint(categoryId)}, ctx)
+}
 
-	err = s.repo.UpdateItem(item, ctx)
+// RestoreCategory implements ItemService
+func (s *itemServiceImpl) RestoreCategory(id string, ctx context.Context) error {
+	categoryId, err := strconv.Atoi(id)
+	if err != nil {
+		return customerrors.ErrInvalidId
+	}
+	return s.repo.RestoreCategory(&model.Category{ID: uint(categoryId)}, ctx)
+}
+
+// FindArchivedCategories implements ItemService
+func (s *itemServiceImpl) FindArchivedCategories(opts query.Options, ctx context.Context) (dto.CategoriesResponse, *query.Page, error) {
+	categories, total, err := s.repo.FindArchivedCategories(opts, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var categoriesResponse dto.CategoriesResponse
+	categoriesResponse.FromModel(categories)
+	return categoriesResponse, query.NewPage(opts, total), nil
+}
+
+// CreateVariant implements ItemService
+func (s *itemServiceImpl) CreateVariant(itemId string, body dto.ItemVariantRequest, userId string, ctx context.Context) (uint, error) {
+	id, err := strconv.Atoi(itemId)
+	if err != nil {
+		return 0, customerrors.ErrInvalidId
+	}
+	actorId, err := uuid.Parse(userId)
+	if err != nil {
+		return 0, customerrors.ErrInvalidId
+	}
+	variant := body.ToModel()
+	variant.ItemID = uint(id)
+	err = s.repo.CreateVariant(variant, actorId, ctx)
+	if err != nil {
+		return 0, err
+	}
+	return variant.ID, nil
+}
+
+// UpdateVariant implements ItemService
+func (s *itemServiceImpl) UpdateVariant(itemId string, variantId string, body dto.ItemVariantRequest, userId string, ctx context.Context) error {
+	variant, err := parseVariant(itemId, variantId)
 	if err != nil {
 		return err
 	}
-	return err
+	actorId, err := uuid.Parse(userId)
+	if err != nil {
+		return customerrors.ErrInvalidId
+	}
+	update := body.ToModel()
+	update.ID = variant.ID
+	update.ItemID = variant.ItemID
+	if body.Qty == nil {
+		update.Qty = stock.Uncounted
+	}
+	return s.repo.UpdateVariant(update, actorId, ctx)
 }
 
-func NewItemService(repository repository.ItemRepository) ItemService {
-	return &itemServiceImpl{
-		repo: repository,
+// DeleteVariant implements ItemService
+func (s *itemServiceImpl) DeleteVariant(itemId string, variantId string, ctx context.Context) error {
+	variant, err := parseVariant(itemId, variantId)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteVariant(variant, ctx)
+}
+
+// FindUnits implements ItemService
+func (s *itemServiceImpl) FindUnits(ctx context.Context) (dto.UnitsResponse, error) {
+	units, err := s.repo.FindUnits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var res dto.UnitsResponse
+	res.FromModel(units)
+	return res, nil
+}
+
+// parseVariant parse path id of variant, variant only found under its own item
+func parseVariant(itemId string, variantId string) (*model.ItemVariant, error) {
+	item, err := strconv.Atoi(itemId)
+	if err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	id, err := strconv.Atoi(variantId)
+	if err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	return &model.ItemVariant{ID: uint(id), ItemID: uint(item)}, nil
+}
+
+// storeImage check image and put it with its thumbnail under dir, every upload get new key
+// so client cache of replaced image is never served
+func (s *itemServiceImpl) storeImage(dir string, data []byte) (*model.Image, error) {
+	contentType, ext, err := thumbnail.Detect(data)
+	if err != nil {
+		return nil, err
+	}
+	thumb, err := thumbnail.Make(data, contentType, constants.Thumbnail_size)
+	if err != nil {
+		return nil, err
+	}
+	name := dir + "/" + uuid.NewString()
+	image := model.Image{
+		ImageKey:     name + ext,
+		ThumbnailKey: name + "_thumb" + ext,
+	}
+	if err := s.storage.Put(image.ImageKey, data, contentType); err != nil {
+		return nil, err
+	}
+	if err := s.storage.Put(image.ThumbnailKey, thumb, contentType); err != nil {
+		s.removeImage(model.Image{ImageKey: image.ImageKey})
+		return nil, err
+	}
+	image.ImageURL = s.storage.URL(image.ImageKey)
+	image.ThumbnailURL = s.storage.URL(image.ThumbnailKey)
+	return &image, nil
+}
+
+// removeImage delete file no longer used, failed delete only leave unused file so it is logged
+func (s *itemServiceImpl) removeImage(image model.Image) {
+	for _, key := range []string{image.ImageKey, image.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := s.storage.Delete(key); err != nil {
+			log.Printf("delete image %s: %v", key, err)
+		}
+	}
+}
+
+// indexItem update search index of item. Item is already saved,
+// so failed index is only logged and fixed by next rebuild
+func (s *itemServiceImpl) indexItem(item *model.Item, ctx context.Context) {
+	if err := s.searchRepo.IndexItem(item, ctx); err != nil {
+		log.Printf("index item %d for search: %v", item.ID, err)
+	}
+}
+
+func NewItemService(repository repository.ItemRepository, searchRepository repository.ItemSearchRepository, imageStorage storage.Storage, importCsv importcsv.ImportCsv, checkpointService cps.CheckpointService) (ItemService, error) {
+	newItemService := &itemServiceImpl{
+		repo:              repository,
+		searchRepo:        searchRepository,
+		storage:           imageStorage,
+		importCsv:         importCsv,
+		checkpointService: checkpointService,
+	}
+	// index is derived from item table, build it when it is empty or built by old version
+	if err := searchRepository.EnsureIndex(context.Background()); err != nil {
+		return nil, err
 	}
+	return newItemService, nil
 }
@@ -1,37 +1,76 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"image"
+	"image/png"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
+	cdto "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/checkpoint/dto"
+	checkpointServiceMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/checkpoint/service/mock"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/item/dto"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/item/repository"
 	itemRepositoryMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/item/repository/mock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	importcsv "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/import_csv"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/storage"
+	storageMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/storage/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
 )
 
 type suiteItemService struct {
 	suite.Suite
-	itemRepositoryMock *itemRepositoryMock.ItemRepositoryMock
-	itemService        ItemService
+	itemRepositoryMock       *itemRepositoryMock.ItemRepositoryMock
+	itemSearchRepositoryMock *itemRepositoryMock.ItemSearchRepositoryMock
+	storageMock              *storageMock.StorageMock
+	checkpointServiceMock    *checkpointServiceMock.CheckpointServiceMock
+	itemService              ItemService
 }
 
-func newItemService(repository repository.ItemRepository) ItemService {
+var adminId = uuid.NewString()
+
+func newItemService(repository repository.ItemRepository, searchRepository repository.ItemSearchRepository, imageStorage storage.Storage) ItemService {
+	return &itemServiceImpl{
+		repo:       repository,
+		searchRepo: searchRepository,
+		storage:    imageStorage,
+		importCsv:  importcsv.NewImportCsv(),
+	}
+}
+
+func (s *suiteItemService) newItemServiceWithCheckpoint() ItemService {
 	return &itemServiceImpl{
-		repo: repository,
+		repo:              s.itemRepositoryMock,
+		searchRepo:        s.itemSearchRepositoryMock,
+		storage:           s.storageMock,
+		importCsv:         importcsv.NewImportCsv(),
+		checkpointService: s.checkpointServiceMock,
 	}
 }
 
 func (s *suiteItemService) SetupSuit() {
 	s.itemRepositoryMock = new(itemRepositoryMock.ItemRepositoryMock)
-	s.itemService = newItemService(s.itemRepositoryMock)
+	s.itemSearchRepositoryMock = new(itemRepositoryMock.ItemSearchRepositoryMock)
+	s.storageMock = new(storageMock.StorageMock)
+	s.checkpointServiceMock = new(checkpointServiceMock.CheckpointServiceMock)
+	s.itemService = newItemService(s.itemRepositoryMock, s.itemSearchRepositoryMock, s.storageMock)
 }
 
 func (s *suiteItemService) TearDown() {
 	s.itemRepositoryMock = nil
+	s.itemSearchRepositoryMock = nil
+	s.storageMock = nil
+	s.checkpointServiceMock = nil
 	s.itemService = nil
 }
 
@@ -106,8 +145,9 @@ func (s *suiteItemService) TestCreateItem() {
 			s.SetupSuit()
 
 			s.itemRepositoryMock.On("CreateItem").Return(v.CreateItemErr)
+			s.itemSearchRepositoryMock.On("IndexItem").Return(nil)
 
-			_, err := s.itemService.CreateItem(v.Body, context.Background())
+			_, err := s.itemService.CreateItem(v.Body, adminId, context.Background())
 
 			s.Equal(v.ExpectedErr, err)
 
@@ -149,12 +189,16 @@ func (s *suiteItemService) TestFindCategory() {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.SetupSuit()
 
-			s.itemRepositoryMock.On("FindCategories").Return(v.FindCategoryRes, v.FindCategoryErr)
+			s.itemRepositoryMock.On("FindCategories").Return(v.FindCategoryRes, int64(len(v.FindCategoryRes)), v.FindCategoryErr)
 
-			res, err := s.itemService.FindCategories(context.Background())
+			opts := query.Options{Page: 1, Limit: 20}
+			res, page, err := s.itemService.FindCategories(opts, context.Background())
 
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(v.ExpectedRes, res)
+			if v.ExpectedErr == nil {
+				s.Equal(&query.Page{Page: 1, Limit: 20, Total: 1, TotalPages: 1}, page)
+			}
 
 			s.TearDown()
 		})
@@ -173,7 +217,7 @@ func (s *suiteItemService) TestFindItemByCategory() {
 			Name:        "success",
 			ExpectedErr: nil,
 			ExpectedRes: dto.ItemsResponse{
-				{ID: 1, Name: "item"},
+				{ID: 1, Name: "item", Variants: dto.ItemVariantsResponse{}},
 			},
 			CategoryId:        "1",
 			FindByCategoryErr: nil,
@@ -206,12 +250,16 @@ func (s *suiteItemService) TestFindItemByCategory() {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.SetupSuit()
 
-			s.itemRepositoryMock.On("FindItemsByCategory").Return(v.FindByCategoryRes, v.FindByCategoryErr)
+			s.itemRepositoryMock.On("FindItemsByCategory").Return(v.FindByCategoryRes, int64(len(v.FindByCategoryRes)), v.FindByCategoryErr)
 
-			res, err := s.itemService.FindItemsByCategory(v.CategoryId, context.Background())
+			opts := query.Options{Page: 1, Limit: 20}
+			res, page, err := s.itemService.FindItemsByCategory(v.CategoryId, opts, context.Background())
 
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(v.ExpectedRes, res)
+			if v.ExpectedErr == nil {
+				s.Equal(&query.Page{Page: 1, Limit: 20, Total: 1, TotalPages: 1}, page)
+			}
 
 			s.TearDown()
 		})
@@ -229,7 +277,7 @@ func (s *suiteItemService) TestFindItems() {
 			Name:        "success",
 			ExpectedErr: nil,
 			ExpectedRes: dto.ItemsResponse{
-				{ID: 1, Name: "item"},
+				{ID: 1, Name: "item", Variants: dto.ItemVariantsResponse{}},
 			},
 			FindItemsErr: nil,
 			FindItemsRes: []model.Item{
@@ -252,9 +300,360 @@ func (s *suiteItemService) TestFindItems() {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.SetupSuit()
 
-			s.itemRepositoryMock.On("FindItems").Return(v.FindItemsRes, v.FindItemsErr)
+			s.itemRepositoryMock.On("FindItems").Return(v.FindItemsRes, int64(len(v.FindItemsRes)), v.FindItemsErr)
+
+			opts := query.Options{Page: 1, Limit: 20}
+			res, page, err := s.itemService.FindItems(opts, dto.ItemAvailabilityRequest{}, adminId, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			s.Equal(v.ExpectedRes, res)
+			if v.ExpectedErr == nil {
+				s.Equal(&query.Page{Page: 1, Limit: 20, Total: 1, TotalPages: 1}, page)
+			}
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteItemService) TestFindItemsAvailability() {
+	checkpointId := uuid.New()
+	available := func(qty float64) *float64 { return &qty }
+	items := []model.Item{
+		{ID: 1, Name: "bayam"},
+		{ID: 2, Name: "wortel", Variants: []model.ItemVariant{{ID: 7, ItemID: 2, Name: "organik"}}},
+	}
+	stocks := []model.CheckpointStock{{CheckpointID: checkpointId, ItemID: 2, VariantID: 7, Qty: 1.5}}
+	expected := dto.ItemsResponse{
+		{ID: 1, Name: "bayam", Variants: dto.ItemVariantsResponse{}, CheckpointID: &checkpointId, Available: available(0)},
+		{ID: 2, Name: "wortel", Variants: dto.ItemVariantsResponse{{ID: 7, Name: "organik", Available: available(1.5)}}, CheckpointID: &checkpointId, Available: available(0)},
+	}
+
+	testCase := []struct {
+		Name               string
+		Body               dto.ItemAvailabilityRequest
+		FindCheckpointsRes cdto.CheckpointsResponse
+		FindCheckpointsErr error
+		ExpectedErr        error
+		ExpectedRes        dto.ItemsResponse
+	}{
+		{
+			Name:        "checkpoint id",
+			Body:        dto.ItemAvailabilityRequest{CheckpointID: checkpointId.String()},
+			ExpectedRes: expected,
+		},
+		{
+			Name:               "nearest checkpoint",
+			Body:               dto.ItemAvailabilityRequest{Nearest: true},
+			FindCheckpointsRes: cdto.CheckpointsResponse{{ID: checkpointId}, {ID: uuid.New()}},
+			ExpectedRes:        expected,
+		},
+		{
+			Name:               "user not covered",
+			Body:               dto.ItemAvailabilityRequest{Nearest: true},
+			FindCheckpointsErr: customerrors.ErrCheckpointNotCovered,
+			ExpectedErr:        customerrors.ErrCheckpointNotCovered,
+		},
+		{
+			Name:        "invalid checkpoint id",
+			Body:        dto.ItemAvailabilityRequest{CheckpointID: "abc"},
+			ExpectedErr: customerrors.ErrInvalidId,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+			s.itemService = s.newItemServiceWithCheckpoint()
+
+			s.checkpointServiceMock.On("FindCheckpointsByUser").Return(v.FindCheckpointsRes, v.FindCheckpointsErr)
+			s.itemRepositoryMock.On("FindItems").Return(items, int64(len(items)), nil)
+			s.itemRepositoryMock.On("FindCheckpointStocks").Return(stocks, nil)
+
+			res, _, err := s.itemService.FindItems(query.Options{Page: 1, Limit: 20}, v.Body, adminId, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			s.Equal(v.ExpectedRes, res)
+
+			s.TearDown()
+		})
+	}
+}
+func (s *suiteItemService) TestUpdateItem() {
+	testCase := []struct {
+		Name            string
+		Id              string
+		ExpectedErr     error
+		UpdateItemErr   error
+		FindItemByIdErr error
+		IndexItemErr    error
+		IndexCalled     bool
+	}{
+		{
+			Name:        "success",
+			Id:          "1",
+			IndexCalled: true,
+		},
+		{
+			Name:          "update error",
+			Id:            "1",
+			ExpectedErr:   errors.New("error"),
+			UpdateItemErr: errors.New("error"),
+		},
+		{
+			Name:        "invalid id",
+			Id:          "satu",
+			ExpectedErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:         "index error not fail update",
+			Id:           "1",
+			IndexItemErr: errors.New("error"),
+			IndexCalled:  true,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			s.itemRepositoryMock.On("UpdateItem").Return(v.UpdateItemErr)
+			s.itemRepositoryMock.On("FindItemById").Return(v.FindItemByIdErr)
+			s.itemSearchRepositoryMock.On("IndexItem").Return(v.IndexItemErr)
+
+			err := s.itemService.UpdateItem(v.Id, dto.ItemRequest{Name: "item", CategoryID: 1}, adminId, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			if v.IndexCalled {
+				s.itemSearchRepositoryMock.AssertCalled(t, "IndexItem")
+			} else {
+				s.itemSearchRepositoryMock.AssertNotCalled(t, "IndexItem")
+			}
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteItemService) TestSearchItems() {
+	testCase := []struct {
+		Name           string
+		Body           dto.ItemSearchRequest
+		ExpectedErr    error
+		ExpectedRes    *dto.ItemSearchResponse
+		SearchItemsRes []model.Item
+		SearchItemsErr error
+		FacetRes       []model.CategoryFacet
+		FacetErr       error
+	}{
+		{
+			Name: "success",
+			Body: dto.ItemSearchRequest{Q: "cabe"},
+			ExpectedRes: &dto.ItemSearchResponse{
+				Items: dto.ItemsResponse{
+					{ID: 1, Name: "Cabai Merah", CategoryName: "sayur", Variants: dto.ItemVariantsResponse{}},
+				},
+				Facets: dto.CategoryFacetsResponse{
+					{CategoryID: 1, CategoryName: "sayur", Count: 1},
+				},
+			},
+			SearchItemsRes: []model.Item{
+				{ID: 1, Name: "Cabai Merah", CategoryID: 1, Category: model.Category{ID: 1, Name: "sayur"}},
+			},
+			FacetRes: []model.CategoryFacet{
+				{CategoryID: 1, Name: "sayur", Count: 1},
+			},
+		},
+		{
+			Name:        "no word",
+			Body:        dto.ItemSearchRequest{Q: "!!"},
+			ExpectedErr: customerrors.ErrInvalidSearch,
+		},
+		{
+			Name:        "min price above max price",
+			Body:        dto.ItemSearchRequest{Q: "cabe", MinPrice: 2000, MaxPrice: 1000},
+			ExpectedErr: customerrors.ErrInvalidFilter,
+		},
+		{
+			Name:           "search error",
+			Body:           dto.ItemSearchRequest{Q: "cabe"},
+			ExpectedErr:    errors.New("error"),
+			SearchItemsRes: []model.Item{},
+			SearchItemsErr: errors.New("error"),
+		},
+		{
+			Name:           "facet error",
+			Body:           dto.ItemSearchRequest{Q: "cabe"},
+			ExpectedErr:    errors.New("error"),
+			SearchItemsRes: []model.Item{},
+			FacetRes:       []model.CategoryFacet{},
+			FacetErr:       errors.New("error"),
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			s.itemSearchRepositoryMock.On("SearchItems").Return(v.SearchItemsRes, int64(len(v.SearchItemsRes)), v.SearchItemsErr)
+			s.itemSearchRepositoryMock.On("FacetCategories").Return(v.FacetRes, v.FacetErr)
+
+			opts := query.Options{Page: 1, Limit: 20}
+			res, page, err := s.itemService.SearchItems(v.Body, opts, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			s.Equal(v.ExpectedRes, res)
+			if v.ExpectedErr == nil {
+				s.Equal(&query.Page{Page: 1, Limit: 20, Total: 1, TotalPages: 1}, page)
+			}
+
+			s.TearDown()
+		})
+	}
+}
+
+// itemWithImage fill found item with its current image, mock cant change the argument
+type itemWithImage struct {
+	*itemRepositoryMock.ItemRepositoryMock
+	image model.Image
+}
+
+func (r *itemWithImage) FindItemById(item *model.Item, ctx context.Context) error {
+	err := r.ItemRepositoryMock.FindItemById(item, ctx)
+	if err == nil {
+		item.Image = r.image
+	}
+	return err
+}
+
+func newPng(t *testing.T) []byte {
+	var data bytes.Buffer
+	assert.NoError(t, png.Encode(&data, image.NewRGBA(image.Rect(0, 0, 600, 300))))
+	return data.Bytes()
+}
+
+func (s *suiteItemService) TestUploadItemImage() {
+	oldImage := model.Image{
+		ImageKey:     "items/1/old.png",
+		ImageURL:     "https://cdn.kangsayur.com/items/1/old.png",
+		ThumbnailKey: "items/1/old_thumb.png",
+		ThumbnailURL: "https://cdn.kangsayur.com/items/1/old_thumb.png",
+	}
+	testCase := []struct {
+		Name            string
+		Id              string
+		Data            []byte
+		ExpectedErr     error
+		FindItemByIdErr error
+		PutErr          error
+		UpdateImageErr  error
+		ExpectedPut     int
+		ExpectedDelete  int
+	}{
+		{
+			Name:           "success",
+			Id:             "1",
+			Data:           newPng(s.T()),
+			ExpectedPut:    2,
+			ExpectedDelete: 2,
+		},
+		{
+			Name:        "invalid id",
+			Id:          "satu",
+			Data:        newPng(s.T()),
+			ExpectedErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:            "item not found",
+			Id:              "1",
+			Data:            newPng(s.T()),
+			ExpectedErr:     customerrors.ErrNotFound,
+			FindItemByIdErr: customerrors.ErrNotFound,
+		},
+		{
+			Name:        "not an image",
+			Id:          "1",
+			Data:        []byte("<html></html>"),
+			ExpectedErr: customerrors.ErrImageType,
+		},
+		{
+			Name:        "storage error",
+			Id:          "1",
+			Data:        newPng(s.T()),
+			ExpectedErr: customerrors.ErrStorage,
+			PutErr:      customerrors.ErrStorage,
+			ExpectedPut: 1,
+		},
+		{
+			Name:           "update error remove new image",
+			Id:             "1",
+			Data:           newPng(s.T()),
+			ExpectedErr:    errors.New("error"),
+			UpdateImageErr: errors.New("error"),
+			ExpectedPut:    2,
+			ExpectedDelete: 2,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			s.itemRepositoryMock.On("FindItemById").Return(v.FindItemByIdErr)
+			s.itemRepositoryMock.On("UpdateItemImage").Return(v.UpdateImageErr)
+			s.storageMock.On("Put", mock.Anything, "image/png").Return(v.PutErr)
+			s.storageMock.On("Delete", mock.Anything).Return(nil)
+			s.itemService = newItemService(&itemWithImage{ItemRepositoryMock: s.itemRepositoryMock, image: oldImage}, s.itemSearchRepositoryMock, s.storageMock)
 
-			res, err := s.itemService.FindItems(context.Background())
+			res, err := s.itemService.UploadItemImage(v.Id, v.Data, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			s.storageMock.AssertNumberOfCalls(t, "Put", v.ExpectedPut)
+			s.storageMock.AssertNumberOfCalls(t, "Delete", v.ExpectedDelete)
+			if v.ExpectedErr == nil {
+				s.Regexp(`^https://cdn.kangsayur.com/items/1/[0-9a-f-]{36}\.png$`, res.ImageURL)
+				s.Regexp(`^https://cdn.kangsayur.com/items/1/[0-9a-f-]{36}_thumb\.png$`, res.ThumbnailURL)
+				// replaced image is deleted
+				s.storageMock.AssertCalled(t, "Delete", oldImage.ImageKey)
+				s.storageMock.AssertCalled(t, "Delete", oldImage.ThumbnailKey)
+			} else {
+				s.Nil(res)
+				s.storageMock.AssertNotCalled(t, "Delete", oldImage.ImageKey)
+			}
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteItemService) TestCreateVariant() {
+	testCase := []struct {
+		Name             string
+		ItemId           string
+		ExpectedErr      error
+		ExpectedRes      uint
+		CreateVariantErr error
+	}{
+		{
+			Name:   "success",
+			ItemId: "1",
+		},
+		{
+			Name:        "invalid id",
+			ItemId:      "satu",
+			ExpectedErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:             "item not found",
+			ItemId:           "1",
+			ExpectedErr:      customerrors.ErrNotFound,
+			CreateVariantErr: customerrors.ErrNotFound,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			s.itemRepositoryMock.On("CreateVariant").Return(v.CreateVariantErr)
+
+			qty := 1.5
+			res, err := s.itemService.CreateVariant(v.ItemId, dto.ItemVariantRequest{Name: "organik", Qty: &qty, Price: 18000}, adminId, context.Background())
 
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(v.ExpectedRes, res)
@@ -264,6 +663,381 @@ func (s *suiteItemService) TestFindItems() {
 	}
 }
 
+func (s *suiteItemService) TestUpdateVariant() {
+	testCase := []struct {
+		Name             string
+		ItemId           string
+		VariantId        string
+		ExpectedErr      error
+		UpdateVariantErr error
+	}{
+		{
+			Name:      "success",
+			ItemId:    "1",
+			VariantId: "2",
+		},
+		{
+			Name:        "invalid item id",
+			ItemId:      "satu",
+			VariantId:   "2",
+			ExpectedErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:        "invalid variant id",
+			ItemId:      "1",
+			VariantId:   "dua",
+			ExpectedErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:             "variant not found",
+			ItemId:           "1",
+			VariantId:        "2",
+			ExpectedErr:      customerrors.ErrNotFound,
+			UpdateVariantErr: customerrors.ErrNotFound,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			s.itemRepositoryMock.On("UpdateVariant").Return(v.UpdateVariantErr)
+
+			err := s.itemService.UpdateVariant(v.ItemId, v.VariantId, dto.ItemVariantRequest{Name: "organik"}, adminId, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteItemService) TestDeleteItem() {
+	testCase := []struct {
+		Name          string
+		Id            string
+		ExpectedErr   error
+		DeleteItemErr error
+	}{
+		{
+			Name: "success",
+			Id:   "1",
+		},
+		{
+			Name:        "invalid id",
+			Id:          "satu",
+			ExpectedErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:          "item not found",
+			Id:            "1",
+			ExpectedErr:   customerrors.ErrNotFound,
+			DeleteItemErr: customerrors.ErrNotFound,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			s.itemRepositoryMock.On("DeleteItem").Return(v.DeleteItemErr)
+
+			err := s.itemService.DeleteItem(v.Id, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteItemService) TestFindArchivedCategories() {
+	s.SetupSuit()
+	archivedAt := time.Date(2022, 11, 1, 8, 0, 0, 0, time.UTC)
+	s.itemRepositoryMock.On("FindArchivedCategories").Return([]model.Category{
+		{ID: 1, Name: "sayur", DeletedAt: gorm.DeletedAt{Time: archivedAt, Valid: true}},
+	}, int64(1), nil)
+
+	res, page, err := s.itemService.FindArchivedCategories(query.Options{Page: 1, Limit: 20}, context.Background())
+
+	s.NoError(err)
+	s.Equal(dto.CategoriesResponse{{ID: 1, Name: "sayur", ArchivedAt: &archivedAt}}, res)
+	s.Equal(int64(1), page.Total)
+	s.TearDown()
+}
+
+func (s *suiteItemService) TestImportItems() {
+	file := "name,category,unit,qty,price\n" +
+		"Bayam,Sayur,ikat,10,5000\n" +
+		"wortel,sayur,,2.5,\n" +
+		"\n"
+	testCase := []struct {
+		Name        string
+		File        string
+		DryRun      bool
+		ExpectedErr error
+		ExpectedRes *dto.ImportResponse
+	}{
+		{
+			Name:        "success",
+			File:        file,
+			ExpectedRes: &dto.ImportResponse{Created: 1, Updated: 1, Errors: []dto.ImportRowError{}},
+		},
+		{
+			Name:        "dry run",
+			File:        file,
+			DryRun:      true,
+			ExpectedRes: &dto.ImportResponse{DryRun: true, Created: 1, Updated: 1, Errors: []dto.ImportRowError{}},
+		},
+		{
+			Name:        "invalid row",
+			File:        "name,category,unit,qty,price\nBayam,buah,ikat,-1,5000\nKangkung,sayur,kodi,1,seribu\n,sayur,,,\nbayam,sayur,,,\n",
+			ExpectedErr: customerrors.ErrImportInvalid,
+			ExpectedRes: &dto.ImportResponse{Errors: []dto.ImportRowError{
+				{Row: 2, Message: customerrors.ErrRowCategory.Error()},
+				{Row: 3, Message: customerrors.ErrRowUnit.Error()},
+				{Row: 4, Message: customerrors.ErrRowName.Error()},
+				{Row: 5, Message: customerrors.ErrRowDuplicate.Error()},
+			}},
+		},
+		{
+			Name:        "missing name column",
+			File:        "category,qty\nsayur,1\n",
+			ExpectedErr: customerrors.ErrImportHeader,
+		},
+		{
+			Name:        "no row",
+			File:        "name,category\n\n",
+			ExpectedErr: customerrors.ErrImportRows,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			s.itemRepositoryMock.On("FindItemsByName").Return([]model.Item{
+				{ID: 2, Name: "Wortel", CategoryID: 1, UnitID: 1, Qty: 1, Price: 3000},
+			}, nil)
+			s.itemRepositoryMock.On("FindCategoriesByName").Return([]model.Category{{ID: 1, Name: "Sayur"}}, nil)
+			s.itemRepositoryMock.On("FindUnits").Return([]model.Unit{{ID: 1, Name: "piece", Symbol: "pcs"}, {ID: 2, Name: "ikat", Symbol: "ikat"}}, nil)
+			s.itemRepositoryMock.On("SaveItems", []model.Item{
+				{Name: "Bayam", CategoryID: 1, UnitID: 2, Qty: 10, Price: 5000},
+				{ID: 2, Name: "wortel", CategoryID: 1, UnitID: 1, Qty: 2.5, Price: 3000},
+			}).Return(nil)
+			s.itemSearchRepositoryMock.On("IndexItem").Return(nil)
+
+			res, err := s.itemService.ImportItems([]byte(v.File), dto.ImportRequest{Format: "csv", DryRun: v.DryRun}, adminId, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			s.Equal(v.ExpectedRes, res)
+			if v.Name == "success" {
+				s.itemRepositoryMock.AssertCalled(t, "SaveItems", mock.Anything)
+			} else {
+				s.itemRepositoryMock.AssertNotCalled(t, "SaveItems", mock.Anything)
+			}
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteItemService) TestCreateStockMovement() {
+	harvestedAt := time.Date(2022, 11, 1, 6, 0, 0, 0, time.UTC)
+	testCase := []struct {
+		Name          string
+		Id            string
+		Body          dto.StockMovementRequest
+		ExpectedErr   error
+		ExpectedQty   float64
+		CreateMoveErr error
+	}{
+		{
+			Name:        "purchase",
+			Id:          "1",
+			Body:        dto.StockMovementRequest{Reason: "purchase", Qty: 2.5},
+			ExpectedQty: 2.5,
+		},
+		{
+			Name:        "spoilage is taken out",
+			Id:          "1",
+			Body:        dto.StockMovementRequest{Reason: "spoilage", Qty: 1},
+			ExpectedQty: -1,
+		},
+		{
+			Name:        "negative adjustment",
+			Id:          "1",
+			Body:        dto.StockMovementRequest{Reason: "adjustment", Qty: -0.25},
+			ExpectedQty: -0.25,
+		},
+		{
+			Name:        "negative purchase",
+			Id:          "1",
+			Body:        dto.StockMovementRequest{Reason: "purchase", Qty: -1},
+			ExpectedErr: customerrors.ErrStockQty,
+		},
+		{
+			Name:        "qty round to zero",
+			Id:          "1",
+			Body:        dto.StockMovementRequest{Reason: "adjustment", Qty: 0.0001},
+			ExpectedErr: customerrors.ErrStockQty,
+		},
+		{
+			Name:        "invalid id",
+			Id:          "satu",
+			Body:        dto.StockMovementRequest{Reason: "purchase", Qty: 1},
+			ExpectedErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:          "stock not enough",
+			Id:            "1",
+			Body:          dto.StockMovementRequest{Reason: "spoilage", Qty: 10},
+			ExpectedErr:   customerrors.ErrStockNotEnough,
+			ExpectedQty:   -10,
+			CreateMoveErr: customerrors.ErrStockNotEnough,
+		},
+		{
+			Name:        "purchase batch",
+			Id:          "1",
+			Body:        dto.StockMovementRequest{Reason: "purchase", Qty: 2, Batch: &dto.StockBatchRequest{HarvestedAt: &harvestedAt, ExpiredAt: harvestedAt.AddDate(0, 0, 3)}},
+			ExpectedQty: 2,
+		},
+		{
+			Name:        "batch expire before harvest",
+			Id:          "1",
+			Body:        dto.StockMovementRequest{Reason: "purchase", Qty: 2, Batch: &dto.StockBatchRequest{HarvestedAt: &harvestedAt, ExpiredAt: harvestedAt}},
+			ExpectedErr: customerrors.ErrStockBatch,
+		},
+		{
+			Name:        "batch of spoilage",
+			Id:          "1",
+			Body:        dto.StockMovementRequest{Reason: "spoilage", Qty: 2, Batch: &dto.StockBatchRequest{ExpiredAt: harvestedAt}},
+			ExpectedErr: customerrors.ErrStockBatch,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			s.itemRepositoryMock.On("CreateStockMovement", mock.MatchedBy(func(move *model.StockMovement) bool {
+				return move.ItemID == 1 && move.Qty == v.ExpectedQty && move.ActorID.String() == adminId
+			})).Return(v.CreateMoveErr)
+
+			res, err := s.itemService.CreateStockMovement(v.Id, v.Body, adminId, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			if v.ExpectedErr == nil {
+				s.Equal(v.ExpectedQty, res.Qty)
+				s.Equal(v.Body.Reason, res.Reason)
+			}
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteItemService) TestTransferStock() {
+	from, to := uuid.New(), uuid.New()
+	testCase := []struct {
+		Name        string
+		Body        dto.StockTransferRequest
+		ExpectedErr error
+		TransferErr error
+	}{
+		{
+			Name: "between checkpoints",
+			Body: dto.StockTransferRequest{FromCheckpointID: from.String(), ToCheckpointID: to.String(), Qty: 1.5},
+		},
+		{
+			Name: "from unallocated stock",
+			Body: dto.StockTransferRequest{ToCheckpointID: to.String(), Qty: 1.5},
+		},
+		{
+			Name:        "same checkpoint",
+			Body:        dto.StockTransferRequest{FromCheckpointID: to.String(), ToCheckpointID: to.String(), Qty: 1.5},
+			ExpectedErr: customerrors.ErrStockTransfer,
+		},
+		{
+			Name:        "no checkpoint",
+			Body:        dto.StockTransferRequest{Qty: 1.5},
+			ExpectedErr: customerrors.ErrStockTransfer,
+		},
+		{
+			Name:        "invalid checkpoint",
+			Body:        dto.StockTransferRequest{ToCheckpointID: "abc", Qty: 1.5},
+			ExpectedErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:        "stock not enough",
+			Body:        dto.StockTransferRequest{FromCheckpointID: from.String(), ToCheckpointID: to.String(), Qty: 1.5},
+			ExpectedErr: customerrors.ErrStockNotEnough,
+			TransferErr: customerrors.ErrStockNotEnough,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			s.itemRepositoryMock.On("TransferStock", mock.MatchedBy(func(out *model.StockMovement) bool {
+				return out.ItemID == 1 && out.Qty == -1.5 && out.Reason == constants.Stock_reason_transfer
+			}), mock.MatchedBy(func(in *model.StockMovement) bool {
+				return in.ItemID == 1 && in.Qty == 1.5 && *in.CheckpointID == to
+			})).Return(v.TransferErr)
+
+			res, err := s.itemService.TransferStock("1", v.Body, adminId, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			if v.ExpectedErr == nil {
+				s.Len(res, 2)
+				s.Equal(-1.5, res[0].Qty)
+				s.Equal(&to, res[1].CheckpointID)
+			}
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteItemService) TestFindExpiringBatches() {
+	s.SetupSuit()
+	variantId := uint(7)
+	s.itemRepositoryMock.On("FindExpiringBatches").Return([]model.ExpiringBatch{
+		{StockBatch: model.StockBatch{ID: 1, ItemID: 1, ExpiredAt: time.Now().Add(-time.Hour), Qty: 1}, ItemName: "bayam"},
+		{StockBatch: model.StockBatch{ID: 2, ItemID: 2, VariantID: variantId, ExpiredAt: time.Now().Add(time.Hour), Qty: 0.5}, ItemName: "wortel", VariantName: "organik"},
+	}, nil)
+
+	res, err := s.itemService.FindExpiringBatches(dto.ExpiringBatchRequest{Days: 2}, context.Background())
+
+	s.NoError(err)
+	s.Len(res, 2)
+	s.True(res[0].Expired)
+	s.Nil(res[0].VariantID)
+	s.False(res[1].Expired)
+	s.Equal(&variantId, res[1].VariantID)
+
+	_, err = s.itemService.FindExpiringBatches(dto.ExpiringBatchRequest{Days: -1}, context.Background())
+	s.Equal(customerrors.ErrInvalidFilter, err)
+
+	s.TearDown()
+}
+
+func (s *suiteItemService) TestStockReport() {
+	s.SetupSuit()
+	variantId := uint(2)
+	s.itemRepositoryMock.On("StockReport").Return([]model.StockReport{
+		{ItemID: 1, ItemName: "bayam", Balance: 2, LedgerTotal: 2},
+		{ItemID: 1, VariantID: &variantId, ItemName: "bayam", VariantName: "organik", Balance: 0.3, LedgerTotal: 0.1 + 0.2},
+		{ItemID: 3, ItemName: "cabai", Balance: 3, LedgerTotal: 2.5},
+	}, nil)
+
+	res, err := s.itemService.StockReport(dto.StockReportRequest{Mismatch: true}, context.Background())
+
+	s.NoError(err)
+	s.Equal(&dto.StockReportResponse{
+		Checked:    3,
+		Mismatched: 1,
+		Rows:       []dto.StockReportRow{{ItemID: 3, ItemName: "cabai", Balance: 3, LedgerTotal: 2.5, Difference: 0.5}},
+	}, res)
+	s.TearDown()
+}
+
 func TestSuiteItemService(t *testing.T) {
 	suite.Run(t, new(suiteItemService))
 }
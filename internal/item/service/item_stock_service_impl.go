@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/item/dto"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/quantity"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+)
+
+// CreateStockMovement implements ItemService
+func (s *itemServiceImpl) CreateStockMovement(itemId string, body dto.StockMovementRequest, userId string, ctx context.Context) (*dto.StockMovementResponse, error) {
+	id, err := strconv.Atoi(itemId)
+	if err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	actorId, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	qty := quantity.Round(body.Qty)
+	if qty == 0 || (qty < 0 && body.Reason != constants.Stock_reason_adjustment) {
+		return nil, customerrors.ErrStockQty
+	}
+	if body.Batch != nil && (body.Reason != constants.Stock_reason_purchase || !body.Batch.Valid()) {
+		return nil, customerrors.ErrStockBatch
+	}
+	checkpointId, err := parseCheckpointId(body.CheckpointID)
+	if err != nil {
+		return nil, err
+	}
+	move := body.ToModel()
+	move.ItemID = uint(id)
+	move.CheckpointID = checkpointId
+	move.ActorID = &actorId
+	if err := s.repo.CreateStockMovement(move, ctx); err != nil {
+		return nil, err
+	}
+	var res dto.StockMovementResponse
+	res.FromModel(move)
+	return &res, nil
+}
+
+// parseCheckpointId parse optional checkpoint of stock move, empty is unallocated stock
+func parseCheckpointId(id string) (*uuid.UUID, error) {
+	if id == "" {
+		return nil, nil
+	}
+	checkpointId, err := uuid.Parse(id)
+	if err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	return &checkpointId, nil
+}
+
+// TransferStock implements ItemService
+func (s *itemServiceImpl) TransferStock(itemId string, body dto.StockTransferRequest, userId string, ctx context.Context) (dto.StockMovementsResponse, error) {
+	id, err := strconv.Atoi(itemId)
+	if err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	actorId, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	from, err := parseCheckpointId(body.FromCheckpointID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseCheckpointId(body.ToCheckpointID)
+	if err != nil {
+		return nil, err
+	}
+	if (from == nil && to == nil) || (from != nil && to != nil && *from == *to) {
+		return nil, customerrors.ErrStockTransfer
+	}
+	if quantity.Round(body.Qty) <= 0 {
+		return nil, customerrors.ErrStockQty
+	}
+	out, in := body.ToModel(from, to)
+	for _, move := range []*model.StockMovement{out, in} {
+		move.ItemID = uint(id)
+		move.ActorID = &actorId
+	}
+	if err := s.repo.TransferStock(out, in, ctx); err != nil {
+		return nil, err
+	}
+	res := dto.StockMovementsResponse{}
+	res.FromModel([]model.StockMovement{*out, *in})
+	return res, nil
+}
+
+// FindItemStocks implements ItemService
+func (s *itemServiceImpl) FindItemStocks(itemId string, ctx context.Context) (dto.ItemStocksResponse, error) {
+	id, err := strconv.Atoi(itemId)
+	if err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	var item model.Item
+	item.ID = uint(id)
+	if err := s.repo.FindItemById(&item, ctx); err != nil {
+		return nil, err
+	}
+	stocks, err := s.repo.FindItemStocks(item.ID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	var res dto.ItemStocksResponse
+	res.FromModel(&item, stocks)
+	return res, nil
+}
+
+// FindStockMovements implements ItemService
+func (s *itemServiceImpl) FindStockMovements(itemId string, opts query.Options, ctx context.Context) (dto.StockMovementsResponse, *query.Page, error) {
+	id, err := strconv.Atoi(itemId)
+	if err != nil {
+		return nil, nil, customerrors.ErrInvalidId
+	}
+	moves, total, err := s.repo.FindStockMovements(uint(id), opts, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	res := dto.StockMovementsResponse{}
+	res.FromModel(moves)
+	return res, query.NewPage(opts, total), nil
+}
+
+// FindExpiringBatches implements ItemService
+func (s *itemServiceImpl) FindExpiringBatches(body dto.ExpiringBatchRequest, ctx context.Context) (dto.ExpiringBatchesResponse, error) {
+	if body.Days < 0 {
+		return nil, customerrors.ErrInvalidFilter
+	}
+	now := time.Now()
+	batches, err := s.repo.FindExpiringBatches(now.AddDate(0, 0, body.Days), ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := dto.ExpiringBatchesResponse{}
+	res.FromModel(batches, now)
+	return res, nil
+}
+
+// StockReport implements ItemService
+func (s *itemServiceImpl) StockReport(body dto.StockReportRequest, ctx context.Context) (*dto.StockReportResponse, error) {
+	report, err := s.repo.StockReport(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var res dto.StockReportResponse
+	res.FromModel(report, body.Mismatch)
+	return &res, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/item/dto"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -11,24 +12,24 @@ type ItemServiceMock struct {
 	mock.Mock
 }
 
-func (b *ItemServiceMock) CreateItem(body dto.ItemRequest, ctx context.Context) (uint, error) {
+func (b *ItemServiceMock) CreateItem(body dto.ItemRequest, userId string, ctx context.Context) (uint, error) {
 	args := b.Called()
 	return args.Get(0).(uint), args.Error(1)
 }
 
-func (b *ItemServiceMock) UpdateItem(id string, body dto.ItemRequest, ctx context.Context) error {
+func (b *ItemServiceMock) UpdateItem(id string, body dto.ItemRequest, userId string, ctx context.Context) error {
 	args := b.Called()
 	return args.Error(0)
 }
 
-func (b *ItemServiceMock) FindItems(ctx context.Context) (dto.ItemsResponse, error) {
+func (b *ItemServiceMock) FindItems(opts query.Options, body dto.ItemAvailabilityRequest, userId string, ctx context.Context) (dto.ItemsResponse, *query.Page, error) {
 	args := b.Called()
-	return args.Get(0).(dto.ItemsResponse), args.Error(1)
+	return args.Get(0).(dto.ItemsResponse), args.Get(1).(*query.Page), args.Error(2)
 }
 
-func (b *ItemServiceMock) FindItemsByCategory(categoryId string, ctx context.Context) (dto.ItemsResponse, error) {
+func (b *ItemServiceMock) FindItemsByCategory(categoryId string, opts query.Options, ctx context.Context) (dto.ItemsResponse, *query.Page, error) {
 	args := b.Called()
-	return args.Get(0).(dto.ItemsResponse), args.Error(1)
+	return args.Get(0).(dto.ItemsResponse), args.Get(1).(*query.Page), args.Error(2)
 }
 
 func (b *ItemServiceMock) CreateCategory(body dto.CategoryRequest, ctx context.Context) (uint, error) {
@@ -36,7 +37,122 @@ func (b *ItemServiceMock) CreateCategory(body dto.CategoryRequest, ctx context.C
 	return args.Get(0).(uint), args.Error(1)
 }
 
-func (b *ItemServiceMock) FindCategories(ctx context.Context) (dto.CategoriesResponse, error) {
+func (b *ItemServiceMock) FindCategories(opts query.Options, ctx context.Context) (dto.CategoriesResponse, *query.Page, error) {
 	args := b.Called()
-	return args.Get(0).(dto.CategoriesResponse), args.Error(1)
+	return args.Get(0).(dto.CategoriesResponse), args.Get(1).(*query.Page), args.Error(2)
+}
+
+func (b *ItemServiceMock) SearchItems(body dto.ItemSearchRequest, opts query.Options, ctx context.Context) (*dto.ItemSearchResponse, *query.Page, error) {
+	args := b.Called()
+	return args.Get(0).(*dto.ItemSearchResponse), args.Get(1).(*query.Page), args.Error(2)
+}
+
+func (b *ItemServiceMock) UploadItemImage(id string, data []byte, ctx context.Context) (*dto.ImageResponse, error) {
+	args := b.Called()
+	return args.Get(0).(*dto.ImageResponse), args.Error(1)
+}
+
+func (b *ItemServiceMock) UploadCategoryImage(id string, data []byte, ctx context.Context) (*dto.ImageResponse, error) {
+	args := b.Called()
+	return args.Get(0).(*dto.ImageResponse), args.Error(1)
+}
+
+func (b *ItemServiceMock) CreateVariant(itemId string, body dto.ItemVariantRequest, userId string, ctx context.Context) (uint, error) {
+	args := b.Called()
+	return args.Get(0).(uint), args.Error(1)
+}
+
+func (b *ItemServiceMock) UpdateVariant(itemId string, variantId string, body dto.ItemVariantRequest, userId string, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *ItemServiceMock) DeleteVariant(itemId string, variantId string, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *ItemServiceMock) FindUnits(ctx context.Context) (dto.UnitsResponse, error) {
+	args := b.Called()
+	return args.Get(0).(dto.UnitsResponse), args.Error(1)
+}
+
+func (b *ItemServiceMock) DeleteItem(id string, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *ItemServiceMock) RestoreItem(id string, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *ItemServiceMock) FindArchivedItems(opts query.Options, ctx context.Context) (dto.ItemsResponse, *query.Page, error) {
+	args := b.Called()
+	return args.Get(0).(dto.ItemsResponse), args.Get(1).(*query.Page), args.Error(2)
+}
+
+func (b *ItemServiceMock) DeleteCategory(id string, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *ItemServiceMock) RestoreCategory(id string, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *ItemServiceMock) FindArchivedCategories(opts query.Options, ctx context.Context) (dto.CategoriesResponse, *query.Page, error) {
+	args := b.Called()
+	return args.Get(0).(dto.CategoriesResponse), args.Get(1).(*query.Page), args.Error(2)
+}
+
+func (b *ItemServiceMock) ImportItems(data []byte, body dto.ImportRequest, userId string, ctx context.Context) (*dto.ImportResponse, error) {
+	args := b.Called(body)
+	return args.Get(0).(*dto.ImportResponse), args.Error(1)
+}
+
+func (b *ItemServiceMock) ExportItems(body dto.ExportRequest, ctx context.Context) ([]byte, error) {
+	args := b.Called(body)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (b *ItemServiceMock) ImportCategories(data []byte, body dto.ImportRequest, ctx context.Context) (*dto.ImportResponse, error) {
+	args := b.Called(body)
+	return args.Get(0).(*dto.ImportResponse), args.Error(1)
+}
+
+func (b *ItemServiceMock) ExportCategories(body dto.ExportRequest, ctx context.Context) ([]byte, error) {
+	args := b.Called(body)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (b *ItemServiceMock) CreateStockMovement(itemId string, body dto.StockMovementRequest, userId string, ctx context.Context) (*dto.StockMovementResponse, error) {
+	args := b.Called()
+	return args.Get(0).(*dto.StockMovementResponse), args.Error(1)
+}
+
+func (b *ItemServiceMock) TransferStock(itemId string, body dto.StockTransferRequest, userId string, ctx context.Context) (dto.StockMovementsResponse, error) {
+	args := b.Called()
+	return args.Get(0).(dto.StockMovementsResponse), args.Error(1)
+}
+
+func (b *ItemServiceMock) FindItemStocks(itemId string, ctx context.Context) (dto.ItemStocksResponse, error) {
+	args := b.Called()
+	return args.Get(0).(dto.ItemStocksResponse), args.Error(1)
+}
+
+func (b *ItemServiceMock) FindExpiringBatches(body dto.ExpiringBatchRequest, ctx context.Context) (dto.ExpiringBatchesResponse, error) {
+	args := b.Called(body)
+	return args.Get(0).(dto.ExpiringBatchesResponse), args.Error(1)
+}
+
+func (b *ItemServiceMock) FindStockMovements(itemId string, opts query.Options, ctx context.Context) (dto.StockMovementsResponse, *query.Page, error) {
+	args := b.Called()
+	return args.Get(0).(dto.StockMovementsResponse), args.Get(1).(*query.Page), args.Error(2)
+}
+
+func (b *ItemServiceMock) StockReport(body dto.StockReportRequest, ctx context.Context) (*dto.StockReportResponse, error) {
+	args := b.Called(body)
+	return args.Get(0).(*dto.StockReportResponse), args.Error(1)
 }
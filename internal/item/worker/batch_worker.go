@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	ir "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/item/repository"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+)
+
+type Clock interface {
+	Now() time.Time
+}
+
+type batchWorker struct {
+	itemRepo ir.ItemRepository
+	clock    Clock
+	interval time.Duration
+}
+
+func NewBatchWorker(itRepository ir.ItemRepository, clock Clock, interval time.Duration) *batchWorker {
+	return &batchWorker{
+		itemRepo: itRepository,
+		clock:    clock,
+		interval: interval,
+	}
+}
+
+// Start run batch write off every interval until context is done
+func (w *batchWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		if err := w.RunOnce(ctx); err != nil {
+			log.Println("batch worker:", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce write off stock left in expired batches as spoilage
+func (w *batchWorker) RunOnce(ctx context.Context) error {
+	batches, err := w.itemRepo.FindExpiringBatches(w.clock.Now(), ctx)
+	if err != nil {
+		return err
+	}
+	for i := range batches {
+		batch := &batches[i].StockBatch
+		err := w.itemRepo.WriteOffBatch(batch, ctx)
+		// ErrStockNotEnough mean batch is taken in the meantime, it is written off on next run
+		if err != nil && !errors.Is(err, customerrors.ErrStockNotEnough) {
+			log.Println("batch worker: write off batch", batch.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	itemRepositoryMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/item/repository/mock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	clockMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/clock/mock"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBatchWorkerRunOnce(t *testing.T) {
+	now := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	expired := []model.ExpiringBatch{
+		{StockBatch: model.StockBatch{ID: 1, ItemID: 1, Qty: 2, ExpiredAt: now.Add(-time.Hour)}},
+		{StockBatch: model.StockBatch{ID: 2, ItemID: 1, Qty: 1, ExpiredAt: now}},
+	}
+
+	testCase := []struct {
+		Name          string
+		ExpectedErr   error
+		FindBatchRes  []model.ExpiringBatch
+		FindBatchErr  error
+		WriteOffErr   error
+		ExpectedCalls int
+	}{
+		{
+			Name:          "write off expired batches",
+			FindBatchRes:  expired,
+			ExpectedCalls: 2,
+		},
+		{
+			Name:          "batch taken in the meantime",
+			FindBatchRes:  expired,
+			WriteOffErr:   customerrors.ErrStockNotEnough,
+			ExpectedCalls: 2,
+		},
+		{
+			Name:         "error find batches",
+			ExpectedErr:  errors.New("internal error"),
+			FindBatchRes: []model.ExpiringBatch(nil),
+			FindBatchErr: errors.New("internal error"),
+		},
+	}
+	for _, v := range testCase {
+		t.Run(v.Name, func(t *testing.T) {
+			repositoryMock := new(itemRepositoryMock.ItemRepositoryMock)
+			clock := new(clockMock.ClockMock)
+			clock.On("Now").Return(now)
+			repositoryMock.On("FindExpiringBatches").Return(v.FindBatchRes, v.FindBatchErr)
+			repositoryMock.On("WriteOffBatch", mock.Anything).Return(v.WriteOffErr)
+
+			err := NewBatchWorker(repositoryMock, clock, time.Minute).RunOnce(context.Background())
+
+			assert.Equal(t, v.ExpectedErr, err)
+			repositoryMock.AssertNumberOfCalls(t, "WriteOffBatch", v.ExpectedCalls)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/golang-jwt/jwt"
@@ -9,6 +10,8 @@ import (
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/service"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	_middleware "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/middleware"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
 type JWTService interface {
@@ -35,13 +38,21 @@ func NewOrderController(service service.OrderService, jwt JWTService, qr QRCode)
 
 func (u *orderController) InitRoute(auth *echo.Group) {
 	orders := auth.Group("/orders")
-	orders.POST("", u.CreateOrder)
-	orders.GET("", u.GetOrder)
-	orders.GET("/:id", u.GetOrderDetail)
-	orders.GET("/qr/:hash_code", u.GetQRCode)
-	orders.POST("/takeorder", u.TakeOrder)
-	orders.PUT("/cencel/:id", u.CencelOrder)
-	orders.PUT("/ready/:id", u.OrderReady)
+	orders.POST("", u.CreateOrder, _middleware.RequirePermission(constants.Permission_order_create))
+	orders.GET("", u.GetOrder, _middleware.RequirePermission(constants.Permission_order_read))
+	orders.GET("/checkpoint", u.GetCheckpointOrders, _middleware.RequirePermission(constants.Permission_order_process))
+	orders.GET("/:id", u.GetOrderDetail, _middleware.RequirePermission(constants.Permission_order_read))
+	orders.GET("/:id/history", u.GetOrderHistory, _middleware.RequirePermission(constants.Permission_order_read))
+	orders.GET("/qr/:hash_code", u.GetQRCode, _middleware.RequirePermission(constants.Permission_order_read))
+	orders.POST("/takeorder", u.TakeOrder, _middleware.RequirePermission(constants.Permission_order_process))
+	orders.PUT("/cencel/:id", u.CencelOrder, _middleware.RequirePermission(constants.Permission_order_cancel))
+	orders.PUT("/ready/:id", u.OrderReady, _middleware.RequirePermission(constants.Permission_order_process))
+	orders.GET("/cart", u.GetCart, _middleware.RequirePermission(constants.Permission_order_create))
+	orders.PUT("/cart", u.SetCartCheckpoint, _middleware.RequirePermission(constants.Permission_order_create))
+	orders.POST("/cart/lines", u.AddCartLine, _middleware.RequirePermission(constants.Permission_order_create))
+	orders.PUT("/cart/lines/:id", u.UpdateCartLine, _middleware.RequirePermission(constants.Permission_order_create))
+	orders.DELETE("/cart/lines/:id", u.RemoveCartLine, _middleware.RequirePermission(constants.Permission_order_create))
+	orders.POST("/cart/checkout", u.CheckoutCart, _middleware.RequirePermission(constants.Permission_order_create))
 }
 
 func (u *orderController) CreateOrder(c echo.Context) error {
@@ -60,13 +71,7 @@ func (u *orderController) CreateOrder(c echo.Context) error {
 
 	newOrder, err := u.service.CreateOrder(orderBody, userId, c.Request().Context())
 	if err != nil {
-		if err == customerrors.ErrInvalidId || err == customerrors.ErrQtyOrder || err == customerrors.ErrBadRequestBody {
-			return c.JSON(http.StatusBadRequest, echo.Map{
-				"message": err.Error()})
-		}
-		return c.JSON(http.StatusInternalServerError, echo.Map{
-			"message": err.Error(),
-		})
+		return createOrderError(c, err)
 	}
 	return c.JSON(http.StatusOK, echo.Map{
 		"message": "new create order success created",
@@ -74,19 +79,75 @@ func (u *orderController) CreateOrder(c echo.Context) error {
 	})
 }
 
+// createOrderError is response of error creating order, from order body or from cart
+func createOrderError(c echo.Context, err error) error {
+	if err == customerrors.ErrEmailNotVerified {
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"message": err.Error()})
+	}
+	if err == customerrors.ErrInvalidId || err == customerrors.ErrQtyOrder || err == customerrors.ErrBadRequestBody ||
+		err == customerrors.ErrQtyUnit || err == customerrors.ErrVariantRequired || err == customerrors.ErrInvalidVariant ||
+		err == customerrors.ErrItemUnavailable || err == customerrors.ErrCartEmpty || err == customerrors.ErrCartCheckpoint {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	}
+	if err == customerrors.ErrCartPriceChanged || err == customerrors.ErrCartChanged {
+		return c.JSON(http.StatusConflict, echo.Map{
+			"message": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"message": err.Error(),
+	})
+}
+
 func (u *orderController) GetOrder(c echo.Context) error {
 	claims := u.jwtService.GetClaims(&c)
 	userId := claims["user_id"].(string)
 
-	orders, err := u.service.FindOrder(userId, c.Request().Context())
+	opts, err := query.Parse(c.QueryParams(), dto.UserOrderQuery)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	orders, page, err := u.service.FindOrder(userId, opts, c.Request().Context())
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{
 			"message": err.Error(),
 		})
 	}
 	return c.JSON(http.StatusOK, echo.Map{
-		"message": "get orders success",
-		"data":    orders,
+		"message":    "get orders success",
+		"data":       orders,
+		"pagination": page,
+	})
+}
+
+func (u *orderController) GetCheckpointOrders(c echo.Context) error {
+	claims := u.jwtService.GetClaims(&c)
+	userId := claims["user_id"].(string)
+
+	opts, err := query.Parse(c.QueryParams(), dto.OrderQuery)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	orders, page, err := u.service.FindCheckpointOrders(userId, _middleware.HasPermission(claims, constants.Permission_order_manage), opts, c.Request().Context())
+	if err != nil {
+		if err == customerrors.ErrInvalidId {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message":    "get orders success",
+		"data":       orders,
+		"pagination": page,
 	})
 }
 
@@ -107,11 +168,55 @@ func (u *orderController) GetOrderDetail(c echo.Context) error {
 	})
 }
 
+func (u *orderController) GetOrderHistory(c echo.Context) error {
+	claims := u.jwtService.GetClaims(&c)
+	userId := claims["user_id"].(string)
+	orderId := c.Param("id")
+
+	histories, err := u.service.FindOrderHistory(userId, orderId, _middleware.HasPermission(claims, constants.Permission_order_manage), c.Request().Context())
+	if err != nil {
+		if err == customerrors.ErrInvalidId {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		if err == customerrors.ErrNotFound {
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "get order history success",
+		"data":    histories,
+	})
+}
+
 func (u *orderController) CencelOrder(c echo.Context) error {
+	claims := u.jwtService.GetClaims(&c)
+	userId := claims["user_id"].(string)
 	orderId := c.Param("id")
 
-	err := u.service.CencelOder(orderId, c.Request().Context())
+	err := u.service.CencelOder(orderId, userId, _middleware.HasPermission(claims, constants.Permission_order_manage), c.Request().Context())
 	if err != nil {
+		if err == customerrors.ErrInvalidId {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		if err == customerrors.ErrNotFound {
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		if errors.Is(err, customerrors.ErrUpdateStatusOrder) {
+			return c.JSON(http.StatusConflict, echo.Map{
+				"message": err.Error(),
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, echo.Map{
 			"message": err.Error(),
 		})
@@ -123,12 +228,6 @@ func (u *orderController) CencelOrder(c echo.Context) error {
 
 func (u *orderController) TakeOrder(c echo.Context) error {
 	claims := u.jwtService.GetClaims(&c)
-	role := claims["role_id"].(float64)
-	if role != constants.Role_admin {
-		return c.JSON(http.StatusForbidden, echo.Map{
-			"message": customerrors.ErrPermission.Error(),
-		})
-	}
 	var takeOrder dto.TakeOrder
 	if err := c.Bind(&takeOrder); err != nil {
 		return c.JSON(http.StatusBadRequest, echo.Map{
@@ -138,7 +237,8 @@ func (u *orderController) TakeOrder(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, echo.Map{
 			"message": err.Error()})
 	}
-	err := u.service.TakeOrder(takeOrder, c.Request().Context())
+	userId := claims["user_id"].(string)
+	err := u.service.TakeOrder(takeOrder, userId, _middleware.HasPermission(claims, constants.Permission_order_manage), c.Request().Context())
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, echo.Map{
 			"message": err.Error(),
@@ -151,16 +251,26 @@ func (u *orderController) TakeOrder(c echo.Context) error {
 
 func (u *orderController) OrderReady(c echo.Context) error {
 	claims := u.jwtService.GetClaims(&c)
-	role := claims["role_id"].(float64)
-	if role != constants.Role_admin {
-		return c.JSON(http.StatusForbidden, echo.Map{
-			"message": customerrors.ErrPermission.Error(),
-		})
-	}
+	userId := claims["user_id"].(string)
 	orderId := c.Param("id")
 
-	err := u.service.OrderReady(orderId, c.Request().Context())
+	err := u.service.OrderReady(orderId, userId, _middleware.HasPermission(claims, constants.Permission_order_manage), c.Request().Context())
 	if err != nil {
+		if err == customerrors.ErrInvalidId {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		if err == customerrors.ErrNotFound {
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		if errors.Is(err, customerrors.ErrUpdateStatusOrder) {
+			return c.JSON(http.StatusConflict, echo.Map{
+				"message": err.Error(),
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, echo.Map{
 			"message": err.Error(),
 		})
@@ -185,3 +295,131 @@ func (u *orderController) GetQRCode(c echo.Context) error {
 		},
 	})
 }
+
+func (u *orderController) GetCart(c echo.Context) error {
+	claims := u.jwtService.GetClaims(&c)
+	userId := claims["user_id"].(string)
+
+	cart, err := u.service.FindCart(userId, c.Request().Context())
+	if err != nil {
+		if err == customerrors.ErrInvalidId {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"message": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "get cart success",
+		"data":    cart,
+	})
+}
+
+func (u *orderController) SetCartCheckpoint(c echo.Context) error {
+	claims := u.jwtService.GetClaims(&c)
+	userId := claims["user_id"].(string)
+
+	var checkpointBody dto.CartCheckpointRequest
+	if err := c.Bind(&checkpointBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrBadRequestBody.Error()})
+	}
+	err := u.service.SetCartCheckpoint(checkpointBody, userId, c.Request().Context())
+	if err != nil {
+		if err == customerrors.ErrInvalidId || err == customerrors.ErrCheckpointNotFound {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"message": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "success update cart",
+	})
+}
+
+// cartLineError is response of error changing cart line
+func cartLineError(c echo.Context, err error) error {
+	if err == customerrors.ErrInvalidId || err == customerrors.ErrQtyUnit || err == customerrors.ErrVariantRequired ||
+		err == customerrors.ErrInvalidVariant || err == customerrors.ErrItemUnavailable {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	}
+	if err == customerrors.ErrNotFound {
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"message": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"message": err.Error(),
+	})
+}
+
+func (u *orderController) AddCartLine(c echo.Context) error {
+	claims := u.jwtService.GetClaims(&c)
+	userId := claims["user_id"].(string)
+
+	var lineBody dto.CartLineRequest
+	if err := c.Bind(&lineBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrBadRequestBody.Error()})
+	}
+	if err := c.Validate(lineBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	}
+	if err := u.service.AddCartLine(lineBody, userId, c.Request().Context()); err != nil {
+		return cartLineError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "item added to cart",
+	})
+}
+
+func (u *orderController) UpdateCartLine(c echo.Context) error {
+	claims := u.jwtService.GetClaims(&c)
+	userId := claims["user_id"].(string)
+
+	var lineBody dto.CartLineQtyRequest
+	if err := c.Bind(&lineBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrBadRequestBody.Error()})
+	}
+	if err := c.Validate(lineBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	}
+	if err := u.service.UpdateCartLine(c.Param("id"), lineBody, userId, c.Request().Context()); err != nil {
+		return cartLineError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "success update cart",
+	})
+}
+
+func (u *orderController) RemoveCartLine(c echo.Context) error {
+	claims := u.jwtService.GetClaims(&c)
+	userId := claims["user_id"].(string)
+
+	if err := u.service.RemoveCartLine(c.Param("id"), userId, c.Request().Context()); err != nil {
+		return cartLineError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "item removed from cart",
+	})
+}
+
+func (u *orderController) CheckoutCart(c echo.Context) error {
+	claims := u.jwtService.GetClaims(&c)
+	userId := claims["user_id"].(string)
+
+	newOrder, err := u.service.CheckoutCart(userId, c.Request().Context())
+	if err != nil {
+		return createOrderError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "new create order success created",
+		"data":    newOrder,
+	})
+}
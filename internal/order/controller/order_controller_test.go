@@ -14,10 +14,12 @@ import (
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/dto"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/service"
 	osm "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/service/mock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/statemachine"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
 	mm "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/middleware/mock"
 	qrm "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/qrcode/mock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	vm "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/validator/mock"
 	"github.com/stretchr/testify/suite"
 )
@@ -103,6 +105,28 @@ func (s *suiteOrderController) TestCreateOrder() {
 				RedirectURL: "https://test/miodtrans.com",
 			},
 		},
+		{
+			Name:           "error email not verified",
+			ExpectedStatus: 403,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrEmailNotVerified.Error(),
+			},
+			Body: map[string]interface{}{
+				"checkpoint_id": checkpointId.String(),
+				"order": []interface{}{
+					map[string]interface{}{
+						"item_id": 1,
+						"qty":     10,
+					},
+				},
+			},
+			JWTReturn: jwt.MapClaims{
+				"user_id": userId.String(),
+			},
+			ValidatorErr:   nil,
+			CreateOrderErr: customerrors.ErrEmailNotVerified,
+			CreateOrderRes: &dto.NewOrder{},
+		},
 		{
 			Name:           "error invalid body type",
 			ExpectedStatus: 400,
@@ -271,6 +295,16 @@ func (s *suiteOrderController) TestCreateOrder() {
 		})
 	}
 }
+
+var listPage = &query.Page{Page: 1, Limit: 20, Total: 1, TotalPages: 1}
+
+var listPageResult = map[string]interface{}{
+	"page":        float64(1),
+	"limit":       float64(20),
+	"total":       float64(1),
+	"total_pages": float64(1),
+}
+
 func (s *suiteOrderController) TestGetOrder() {
 	userId := uuid.New()
 	orderId := uuid.New()
@@ -300,7 +334,8 @@ func (s *suiteOrderController) TestGetOrder() {
 						"user_name": "",
 					},
 				},
-				"message": "get orders success",
+				"message":    "get orders success",
+				"pagination": listPageResult,
 			},
 			JWTReturn: jwt.MapClaims{
 				"user_id": userId.String(),
@@ -329,8 +364,9 @@ func (s *suiteOrderController) TestGetOrder() {
 			Name:           "nil or empty order",
 			ExpectedStatus: 200,
 			ExpectedResult: map[string]interface{}{
-				"data":    []interface{}{},
-				"message": "get orders success",
+				"data":       []interface{}{},
+				"message":    "get orders success",
+				"pagination": listPageResult,
 			},
 			JWTReturn: jwt.MapClaims{
 				"user_id": userId.String(),
@@ -354,7 +390,7 @@ func (s *suiteOrderController) TestGetOrder() {
 
 			// define mock
 			s.JWTServiceMock.On("GetClaims").Return(v.JWTReturn)
-			s.orderServiceMock.On("FindOrder").Return(v.FindOrderRes, v.FindOrderErr)
+			s.orderServiceMock.On("FindOrder").Return(v.FindOrderRes, listPage, v.FindOrderErr)
 
 			err := s.orderController.GetOrder(ctx)
 			s.NoError(err)
@@ -370,6 +406,85 @@ func (s *suiteOrderController) TestGetOrder() {
 		})
 	}
 }
+func (s *suiteOrderController) TestGetCheckpointOrders() {
+	userId := uuid.New()
+
+	testCase := []struct {
+		Name           string
+		ExpectedStatus int
+		Query          string
+		ExpectedResult map[string]interface{}
+		FindOrderErr   error
+	}{
+		{
+			Name:           "success get checkpoint orders",
+			ExpectedStatus: 200,
+			ExpectedResult: map[string]interface{}{
+				"data":       []interface{}{},
+				"message":    "get orders success",
+				"pagination": listPageResult,
+			},
+			FindOrderErr: nil,
+		},
+		{
+			Name:           "invalid filter",
+			Query:          "checkpoint_id=checkpoint",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrInvalidFilter.Error(),
+			},
+		},
+		{
+			Name:           "invalid user id",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrInvalidId.Error(),
+			},
+			FindOrderErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:           "internal server error",
+			ExpectedStatus: 500,
+			ExpectedResult: map[string]interface{}{
+				"message": errors.New("internal error").Error(),
+			},
+			FindOrderErr: errors.New("internal error"),
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			r := httptest.NewRequest(http.MethodGet, "/?"+v.Query, nil)
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			c := s.echoNew
+			ctx := c.NewContext(r, w)
+			ctx.SetPath("/orders/checkpoint")
+
+			// define mock
+			s.JWTServiceMock.On("GetClaims").Return(jwt.MapClaims{
+				"role_id": float64(constants.Role_checkpoint_operator),
+				"user_id": userId.String(),
+			})
+			s.orderServiceMock.On("FindCheckpointOrders").Return(dto.OrdersResponse{}, listPage, v.FindOrderErr)
+
+			err := s.orderController.GetCheckpointOrders(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			s.TearDown()
+		})
+	}
+}
 func (s *suiteOrderController) TestGetOrderDetail() {
 	userId := uuid.New()
 	orderId := uuid.New()
@@ -484,8 +599,121 @@ func (s *suiteOrderController) TestGetOrderDetail() {
 		})
 	}
 }
+func (s *suiteOrderController) TestGetOrderHistory() {
+	userId := uuid.New()
+	orderId := uuid.New()
+
+	testCase := []struct {
+		Name                string
+		ExpectedStatus      int
+		ExpectedResult      map[string]interface{}
+		JWTReturn           jwt.MapClaims
+		FindOrderHistoryErr error
+		FindOrderHistoryRes dto.OrderStatusHistoriesResponse
+	}{
+		{
+			Name:           "success get history",
+			ExpectedStatus: 200,
+			ExpectedResult: map[string]interface{}{
+				"data": []interface{}{
+					map[string]interface{}{
+						"from_status": "pending",
+						"to_status":   "waiting",
+						"actor_id":    nil,
+						"source":      constants.Order_source_payment_webhook,
+						"created_at":  "0001-01-01T00:00:00Z",
+					},
+				},
+				"message": "get order history success",
+			},
+			JWTReturn: jwt.MapClaims{
+				"user_id": userId.String(),
+				"role_id": float64(constants.Role_user),
+			},
+			FindOrderHistoryErr: nil,
+			FindOrderHistoryRes: dto.OrderStatusHistoriesResponse{
+				{
+					FromStatus: "pending",
+					ToStatus:   "waiting",
+					Source:     constants.Order_source_payment_webhook,
+				},
+			},
+		},
+		{
+			Name:           "order not found or not owner",
+			ExpectedStatus: 404,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrNotFound.Error(),
+			},
+			JWTReturn: jwt.MapClaims{
+				"user_id": userId.String(),
+				"role_id": float64(constants.Role_user),
+			},
+			FindOrderHistoryErr: customerrors.ErrNotFound,
+			FindOrderHistoryRes: dto.OrderStatusHistoriesResponse(nil),
+		},
+		{
+			Name:           "invalid order id",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrInvalidId.Error(),
+			},
+			JWTReturn: jwt.MapClaims{
+				"user_id": userId.String(),
+				"role_id": float64(constants.Role_admin),
+			},
+			FindOrderHistoryErr: customerrors.ErrInvalidId,
+			FindOrderHistoryRes: dto.OrderStatusHistoriesResponse(nil),
+		},
+		{
+			Name:           "internal server error",
+			ExpectedStatus: 500,
+			ExpectedResult: map[string]interface{}{
+				"message": errors.New("internal error").Error(),
+			},
+			JWTReturn: jwt.MapClaims{
+				"user_id": userId.String(),
+				"role_id": float64(constants.Role_admin),
+			},
+			FindOrderHistoryErr: errors.New("internal error"),
+			FindOrderHistoryRes: dto.OrderStatusHistoriesResponse(nil),
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+
+			c := s.echoNew
+			ctx := c.NewContext(r, w)
+			ctx.SetPath("/orders/:id/history")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(orderId.String())
+
+			// define mock
+			s.JWTServiceMock.On("GetClaims").Return(v.JWTReturn)
+			s.orderServiceMock.On("FindOrderHistory").Return(v.FindOrderHistoryRes, v.FindOrderHistoryErr)
+
+			err := s.orderController.GetOrderHistory(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			s.TearDown()
+		})
+	}
+}
 func (s *suiteOrderController) TestCelcelOrder() {
 	orderId := uuid.New()
+	userId := uuid.New()
 
 	testCase := []struct {
 		Name           string
@@ -512,6 +740,24 @@ func (s *suiteOrderController) TestCelcelOrder() {
 			ParamId:        orderId.String(),
 			CencelOrderErr: errors.New("internal error"),
 		},
+		{
+			Name:           "order not found",
+			ExpectedStatus: 404,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrNotFound.Error(),
+			},
+			ParamId:        orderId.String(),
+			CencelOrderErr: customerrors.ErrNotFound,
+		},
+		{
+			Name:           "illegal status transition",
+			ExpectedStatus: 409,
+			ExpectedResult: map[string]interface{}{
+				"message": "cant update status order from waiting to cencel",
+			},
+			ParamId:        orderId.String(),
+			CencelOrderErr: &statemachine.IllegalTransitionError{From: constants.Waiting_status_order_id, To: constants.Cencel_status_order_id},
+		},
 	}
 
 	for _, v := range testCase {
@@ -530,6 +776,10 @@ func (s *suiteOrderController) TestCelcelOrder() {
 
 			// define mock
 			s.orderServiceMock.On("CencelOder").Return(v.CencelOrderErr)
+			s.JWTServiceMock.On("GetClaims").Return(jwt.MapClaims{
+				"user_id": userId.String(),
+				"role_id": float64(constants.Role_user),
+			})
 
 			err := s.orderController.CencelOrder(ctx)
 			s.NoError(err)
@@ -546,7 +796,7 @@ func (s *suiteOrderController) TestCelcelOrder() {
 	}
 }
 func (s *suiteOrderController) TestTakeOrder() {
-	checkpointId := uuid.New()
+	adminId := uuid.New()
 
 	testCase := []struct {
 		Name           string
@@ -564,27 +814,27 @@ func (s *suiteOrderController) TestTakeOrder() {
 				"message": "success take order",
 			},
 			Body: map[string]interface{}{
-				"checkpoint_id": checkpointId.String(),
-				"code":          "qwert",
+				"code": "qwert",
 			},
 			JwtReturn: jwt.MapClaims{
 				"role_id": float64(constants.Role_admin),
+				"user_id": adminId.String(),
 			},
 			ValiodatorErr: nil,
 			TakeOrderErr:  nil,
 		},
 		{
-			Name:           "forbidden",
-			ExpectedStatus: 403,
+			Name:           "operator success take order",
+			ExpectedStatus: 200,
 			ExpectedResult: map[string]interface{}{
-				"message": customerrors.ErrPermission.Error(),
+				"message": "success take order",
 			},
 			Body: map[string]interface{}{
-				"checkpoint_id": checkpointId.String(),
-				"code":          "qwert",
+				"code": "qwert",
 			},
 			JwtReturn: jwt.MapClaims{
-				"role_id": float64(2),
+				"role_id": float64(constants.Role_checkpoint_operator),
+				"user_id": adminId.String(),
 			},
 			ValiodatorErr: nil,
 			TakeOrderErr:  nil,
@@ -596,11 +846,11 @@ func (s *suiteOrderController) TestTakeOrder() {
 				"message": errors.New("validator error").Error(),
 			},
 			Body: map[string]interface{}{
-				"checkpoint_id": checkpointId.String(),
-				"code":          "qwert",
+				"code": "qwert",
 			},
 			JwtReturn: jwt.MapClaims{
 				"role_id": float64(constants.Role_admin),
+				"user_id": adminId.String(),
 			},
 			ValiodatorErr: errors.New("validator error"),
 			TakeOrderErr:  nil,
@@ -612,11 +862,11 @@ func (s *suiteOrderController) TestTakeOrder() {
 				"message": errors.New("take order error").Error(),
 			},
 			Body: map[string]interface{}{
-				"checkpoint_id": checkpointId.String(),
-				"code":          "qwert",
+				"code": "qwert",
 			},
 			JwtReturn: jwt.MapClaims{
 				"role_id": float64(constants.Role_admin),
+				"user_id": adminId.String(),
 			},
 			ValiodatorErr: nil,
 			TakeOrderErr:  errors.New("take order error"),
@@ -656,6 +906,7 @@ func (s *suiteOrderController) TestTakeOrder() {
 }
 func (s *suiteOrderController) TestOrderReady() {
 	orderId := uuid.New()
+	adminId := uuid.New()
 
 	testCase := []struct {
 		Name           string
@@ -674,20 +925,22 @@ func (s *suiteOrderController) TestOrderReady() {
 			OrderId: orderId.String(),
 			JwtReturn: jwt.MapClaims{
 				"role_id": float64(constants.Role_admin),
+				"user_id": adminId.String(),
 			},
 			OrderReadyErr: nil,
 		},
 		{
-			Name:           "forbidden",
-			ExpectedStatus: 403,
+			Name:           "order of other checkpoint",
+			ExpectedStatus: 404,
 			ExpectedResult: map[string]interface{}{
-				"message": customerrors.ErrPermission.Error(),
+				"message": customerrors.ErrNotFound.Error(),
 			},
 			OrderId: orderId.String(),
 			JwtReturn: jwt.MapClaims{
-				"role_id": float64(2),
+				"role_id": float64(constants.Role_checkpoint_operator),
+				"user_id": adminId.String(),
 			},
-			OrderReadyErr: nil,
+			OrderReadyErr: customerrors.ErrNotFound,
 		},
 		{
 			Name:           "error when take order",
@@ -698,6 +951,7 @@ func (s *suiteOrderController) TestOrderReady() {
 			OrderId: orderId.String(),
 			JwtReturn: jwt.MapClaims{
 				"role_id": float64(constants.Role_admin),
+				"user_id": adminId.String(),
 			},
 			OrderReadyErr: errors.New("take order error"),
 		},
@@ -803,6 +1057,204 @@ func (s *suiteOrderController) TestGetQRCode() {
 	}
 }
 
+func (s *suiteOrderController) TestAddCartLine() {
+	userId := uuid.New()
+
+	testCase := []struct {
+		Name           string
+		ExpectedStatus int
+		ExpectedResult map[string]interface{}
+		Body           map[string]interface{}
+		ValidatorErr   error
+		AddCartLineErr error
+	}{
+		{
+			Name:           "success add",
+			ExpectedStatus: 200,
+			ExpectedResult: map[string]interface{}{
+				"message": "item added to cart",
+			},
+			Body: map[string]interface{}{"item_id": 1, "qty": 0.5},
+		},
+		{
+			Name:           "invalid body",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrBadRequestBody.Error(),
+			},
+			Body: map[string]interface{}{"item_id": "bayam"},
+		},
+		{
+			Name:           "validation error",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": "qty is required",
+			},
+			Body:         map[string]interface{}{"item_id": 1},
+			ValidatorErr: errors.New("qty is required"),
+		},
+		{
+			Name:           "qty not multiple of unit step",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrQtyUnit.Error(),
+			},
+			Body:           map[string]interface{}{"item_id": 1, "qty": 0.3},
+			AddCartLineErr: customerrors.ErrQtyUnit,
+		},
+		{
+			Name:           "internal server error",
+			ExpectedStatus: 500,
+			ExpectedResult: map[string]interface{}{
+				"message": "internal error",
+			},
+			Body:           map[string]interface{}{"item_id": 1, "qty": 0.5},
+			AddCartLineErr: errors.New("internal error"),
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			body, _ := json.Marshal(v.Body)
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(body))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			c := s.echoNew
+			ctx := c.NewContext(r, w)
+			ctx.SetPath("/orders/cart/lines")
+
+			// define mock
+			s.validatorMock.On("Validate").Return(v.ValidatorErr)
+			s.orderServiceMock.On("AddCartLine").Return(v.AddCartLineErr)
+			s.JWTServiceMock.On("GetClaims").Return(jwt.MapClaims{
+				"user_id": userId.String(),
+				"role_id": float64(constants.Role_user),
+			})
+
+			err := s.orderController.AddCartLine(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteOrderController) TestCheckoutCart() {
+	userId := uuid.New()
+	orderId := uuid.New()
+
+	testCase := []struct {
+		Name           string
+		ExpectedStatus int
+		ExpectedResult map[string]interface{}
+		CheckoutErr    error
+		CheckoutRes    *dto.NewOrder
+	}{
+		{
+			Name:           "success checkout",
+			ExpectedStatus: 200,
+			ExpectedResult: map[string]interface{}{
+				"message": "new create order success created",
+				"data": map[string]interface{}{
+					"order_id":     orderId.String(),
+					"redirect_url": "http://payment",
+				},
+			},
+			CheckoutRes: &dto.NewOrder{OrderID: orderId, RedirectURL: "http://payment"},
+		},
+		{
+			Name:           "empty cart",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrCartEmpty.Error(),
+			},
+			CheckoutErr: customerrors.ErrCartEmpty,
+		},
+		{
+			Name:           "checkpoint not chosen",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrCartCheckpoint.Error(),
+			},
+			CheckoutErr: customerrors.ErrCartCheckpoint,
+		},
+		{
+			Name:           "qty exceeds stock",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrQtyOrder.Error(),
+			},
+			CheckoutErr: customerrors.ErrQtyOrder,
+		},
+		{
+			Name:           "email not verified",
+			ExpectedStatus: 403,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrEmailNotVerified.Error(),
+			},
+			CheckoutErr: customerrors.ErrEmailNotVerified,
+		},
+		{
+			Name:           "price changed since added",
+			ExpectedStatus: 409,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrCartPriceChanged.Error(),
+			},
+			CheckoutErr: customerrors.ErrCartPriceChanged,
+		},
+		{
+			Name:           "cart changed during checkout",
+			ExpectedStatus: 409,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrCartChanged.Error(),
+			},
+			CheckoutErr: customerrors.ErrCartChanged,
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			w := httptest.NewRecorder()
+
+			c := s.echoNew
+			ctx := c.NewContext(r, w)
+			ctx.SetPath("/orders/cart/checkout")
+
+			// define mock
+			s.orderServiceMock.On("CheckoutCart").Return(v.CheckoutRes, v.CheckoutErr)
+			s.JWTServiceMock.On("GetClaims").Return(jwt.MapClaims{
+				"user_id": userId.String(),
+				"role_id": float64(constants.Role_user),
+			})
+
+			err := s.orderController.CheckoutCart(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			s.TearDown()
+		})
+	}
+}
+
 func TestOrderController(t *testing.T) {
 	suite.Run(t, new(suiteOrderController))
 }
@@ -0,0 +1,76 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+)
+
+// CartCheckpointRequest choose checkpoint cart is ordered to, empty checkpoint clear it
+type CartCheckpointRequest struct {
+	CheckpointID string `json:"checkpoint_id"`
+}
+
+type CartLineRequest struct {
+	ItemID    uint    `json:"item_id" validate:"gte=1,required"`
+	VariantID uint    `json:"variant_id"`
+	Qty       float64 `json:"qty" validate:"gt=0,required"`
+}
+
+func (u *CartLineRequest) ToModel() *model.CartLine {
+	return &model.CartLine{
+		ItemID:    u.ItemID,
+		VariantID: u.VariantID,
+		Qty:       u.Qty,
+	}
+}
+
+type CartLineQtyRequest struct {
+	Qty float64 `json:"qty" validate:"gt=0,required"`
+}
+
+// FromCart fill order request with every line of cart
+func (u *OrderRequest) FromCart(cart *model.Cart) {
+	u.CheckpointID = cart.CheckpointID.String()
+	for _, line := range cart.Lines {
+		u.Order = append(u.Order, OrderDetailRequest{
+			ItemID:    line.ItemID,
+			VariantID: line.VariantID,
+			Qty:       line.Qty,
+		})
+	}
+}
+
+// CartResponse is cart priced when it is read. Line with warning cant be ordered as it is
+// and is not counted in total, Available is stock left in checkpoint of the cart and AddedPrice
+// is price when line is added
+type CartResponse struct {
+	CheckpointID *uuid.UUID        `json:"checkpoint_id"`
+	Lines        CartLinesResponse `json:"lines"`
+	TotalPrice   int               `json:"total_price"`
+	CanCheckout  bool              `json:"can_checkout"`
+}
+
+type CartLineResponse struct {
+	ID          uint     `json:"id"`
+	ItemID      uint     `json:"item_id"`
+	ItemName    string   `json:"item_name"`
+	VariantID   uint     `json:"variant_id"`
+	VariantName string   `json:"variant_name"`
+	Unit        string   `json:"unit"`
+	Qty         float64  `json:"qty"`
+	Price       int      `json:"price"`
+	AddedPrice  int      `json:"added_price"`
+	Total       int      `json:"total"`
+	Available   *float64 `json:"available"`
+	Warning     string   `json:"warning,omitempty"`
+}
+
+func (u *CartLineResponse) FromModel(model *model.CartLine) {
+	u.ID = model.ID
+	u.ItemID = model.ItemID
+	u.VariantID = model.VariantID
+	u.Qty = model.Qty
+	u.AddedPrice = model.Price
+}
+
+type CartLinesResponse []CartLineResponse
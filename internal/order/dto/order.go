@@ -5,8 +5,40 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
+// UserOrderQuery is sort and filter allowed on order list of the user
+var UserOrderQuery = query.Schema{
+	Sort:        orderSort,
+	DefaultSort: "-created_at",
+	Key:         "id",
+	Filter: map[string]query.Filter{
+		"status": {Column: "status_order_id", Type: query.Uint, Operator: query.Equal},
+		"from":   {Column: "created_at", Type: query.Time, Operator: query.From},
+		"to":     {Column: "created_at", Type: query.Time, Operator: query.Until},
+	},
+}
+
+// OrderQuery is sort and filter allowed on order list of admin and checkpoint operator
+var OrderQuery = query.Schema{
+	Sort:        orderSort,
+	DefaultSort: "-created_at",
+	Key:         "id",
+	Filter: map[string]query.Filter{
+		"status":        {Column: "status_order_id", Type: query.Uint, Operator: query.Equal},
+		"checkpoint_id": {Column: "checkpoint_id", Type: query.UUID, Operator: query.Equal},
+		"user_id":       {Column: "user_id", Type: query.UUID, Operator: query.Equal},
+		"from":          {Column: "created_at", Type: query.Time, Operator: query.From},
+		"to":            {Column: "created_at", Type: query.Time, Operator: query.Until},
+	},
+}
+
+var orderSort = map[string]string{
+	"created_at":  "created_at",
+	"grand_total": "grand_total",
+}
+
 type OrderRequest struct {
 	CheckpointID string              `json:"checkpoint_id" validate:"required"`
 	Order        OrderDetailsRequest `json:"order" validate:"required"`
@@ -81,8 +113,7 @@ func (u *OrderWithDetailResponse) FromModel(model *model.Order) {
 }
 
 type TakeOrder struct {
-	CheckpointID string `json:"checkpoint_id"`
-	Code         string `json:"code" validate:"required"`
+	Code string `json:"code" validate:"required"`
 }
 
 type NewOrder struct {
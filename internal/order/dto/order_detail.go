@@ -5,20 +5,31 @@ import (
 )
 
 type OrderDetailRequest struct {
-	ItemID uint `json:"item_id" validate:"gte=1, required"`
-	Qty    int  `json:"qty" validate:"gte=1, required"`
-	Price  int  `json:"price"`
-	Total  int  `json:"total"`
+	ItemID      uint    `json:"item_id" validate:"gte=1, required"`
+	VariantID   uint    `json:"variant_id"`
+	Qty         float64 `json:"qty" validate:"gt=0, required"`
+	Price       int     `json:"price"`
+	Total       int     `json:"total"`
+	VariantName string  `json:"-"`
+	Unit        string  `json:"-"`
 }
 
 type OrderDetailsRequest []OrderDetailRequest
 
 func (u *OrderDetailRequest) ToModel() *model.OrderDetail {
+	var variantId *uint
+	if u.VariantID != 0 {
+		id := u.VariantID
+		variantId = &id
+	}
 	return &model.OrderDetail{
-		ItemID: u.ItemID,
-		Qty:    u.Qty,
-		Price:  u.Price,
-		Total:  u.Total,
+		ItemID:      u.ItemID,
+		VariantID:   variantId,
+		VariantName: u.VariantName,
+		Unit:        u.Unit,
+		Qty:         u.Qty,
+		Price:       u.Price,
+		Total:       u.Total,
 	}
 }
 
@@ -32,16 +43,20 @@ func (u *OrderDetailsRequest) ToModel() *[]model.OrderDetail {
 }
 
 type OrderDetailResponse struct {
-	ID       uint   `json:"order_detail_id"`
-	ItemName string `json:"item_name"`
-	Qty      int    `json:"qty"`
-	Price    int    `json:"price"`
-	Total    int    `json:"total"`
+	ID          uint    `json:"order_detail_id"`
+	ItemName    string  `json:"item_name"`
+	VariantName string  `json:"variant_name"`
+	Unit        string  `json:"unit"`
+	Qty         float64 `json:"qty"`
+	Price       int     `json:"price"`
+	Total       int     `json:"total"`
 }
 
 func (u *OrderDetailResponse) FromModel(model *model.OrderDetail) {
 	u.ID = model.ID
 	u.ItemName = model.Item.Name
+	u.VariantName = model.VariantName
+	u.Unit = model.Unit
 	u.Qty = model.Qty
 	u.Price = model.Price
 	u.Total = model.Total
@@ -0,0 +1,34 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+)
+
+type OrderStatusHistoryResponse struct {
+	FromStatus string     `json:"from_status"`
+	ToStatus   string     `json:"to_status"`
+	ActorID    *uuid.UUID `json:"actor_id"`
+	Source     string     `json:"source"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (u *OrderStatusHistoryResponse) FromModel(model *model.OrderStatusHistory) {
+	u.FromStatus = model.FromStatus.Name
+	u.ToStatus = model.ToStatus.Name
+	u.ActorID = model.ActorID
+	u.Source = model.Source
+	u.CreatedAt = model.CreatedAt
+}
+
+type OrderStatusHistoriesResponse []OrderStatusHistoryResponse
+
+func (u *OrderStatusHistoriesResponse) FromModel(model []model.OrderStatusHistory) {
+	for _, each := range model {
+		var history OrderStatusHistoryResponse
+		history.FromModel(&each)
+		*u = append(*u, history)
+	}
+}
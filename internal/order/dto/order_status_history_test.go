@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderStatusHistoriesResponse_FromModel(t *testing.T) {
+	adminId := uuid.New()
+	createdAt := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	histories := []model.OrderStatusHistory{
+		{
+			FromStatus: model.StatusOrder{Name: "pending"},
+			ToStatus:   model.StatusOrder{Name: "waiting"},
+			Source:     constants.Order_source_payment_webhook,
+			CreatedAt:  createdAt,
+		},
+		{
+			FromStatus: model.StatusOrder{Name: "waiting"},
+			ToStatus:   model.StatusOrder{Name: "ready"},
+			ActorID:    &adminId,
+			Source:     constants.Order_source_admin_api,
+			CreatedAt:  createdAt.Add(time.Hour),
+		},
+	}
+	expected := OrderStatusHistoriesResponse{
+		{
+			FromStatus: "pending",
+			ToStatus:   "waiting",
+			Source:     constants.Order_source_payment_webhook,
+			CreatedAt:  createdAt,
+		},
+		{
+			FromStatus: "waiting",
+			ToStatus:   "ready",
+			ActorID:    &adminId,
+			Source:     constants.Order_source_admin_api,
+			CreatedAt:  createdAt.Add(time.Hour),
+		},
+	}
+
+	var res OrderStatusHistoriesResponse
+	res.FromModel(histories)
+	assert.Equal(t, expected, res)
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/quantity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type cartRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// userCart find cart of user, cart is created on first change
+func userCart(tx *gorm.DB, userId uuid.UUID) (*model.Cart, error) {
+	cart := model.Cart{UserID: userId}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&cart).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("user_id = ?", userId).First(&cart).Error; err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// FindCart implements CartRepository, user without cart has empty cart
+func (r *cartRepositoryImpl) FindCart(cart *model.Cart, ctx context.Context) error {
+	err := r.db.WithContext(ctx).Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Where("user_id = ?", cart.UserID).First(cart).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return err
+	}
+	return nil
+}
+
+// SetCartCheckpoint implements CartRepository, nil checkpoint clear checkpoint of the cart
+func (r *cartRepositoryImpl) SetCartCheckpoint(cart *model.Cart, ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if cart.CheckpointID != nil {
+			var checkpoints int64
+			if err := tx.Model(&model.Checkpoint{}).Where("id = ?", *cart.CheckpointID).Count(&checkpoints).Error; err != nil {
+				return err
+			}
+			if checkpoints == 0 {
+				return customerrors.ErrCheckpointNotFound
+			}
+		}
+		saved, err := userCart(tx, cart.UserID)
+		if err != nil {
+			return err
+		}
+		cart.ID = saved.ID
+		return tx.Model(saved).Update("checkpoint_id", cart.CheckpointID).Error
+	})
+}
+
+// AddCartLine implements CartRepository, qty of item and variant already in cart is added to its line
+// and its price is replaced by the new one
+func (r *cartRepositoryImpl) AddCartLine(userId uuid.UUID, line *model.CartLine, ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		cart, err := userCart(tx, userId)
+		if err != nil {
+			return err
+		}
+		line.CartID = cart.ID
+		err = tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "cart_id"}, {Name: "item_id"}, {Name: "variant_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"qty": gorm.Expr("qty + ?", line.Qty), "price": line.Price, "updated_at": time.Now()}),
+		}).Create(line).Error
+		if err != nil {
+			return err
+		}
+		// id is not returned on conflict, saved line is read back
+		err = tx.Where("cart_id = ? AND item_id = ? AND variant_id = ?", line.CartID, line.ItemID, line.VariantID).First(line).Error
+		if err != nil {
+			return err
+		}
+		line.Qty = quantity.Round(line.Qty)
+		return nil
+	})
+}
+
+// FindCartLine implements CartRepository, line of other user is not found
+func (r *cartRepositoryImpl) FindCartLine(userId uuid.UUID, line *model.CartLine, ctx context.Context) error {
+	err := r.db.WithContext(ctx).Where("id = ? AND cart_id IN (?)", line.ID, r.db.Model(&model.Cart{}).Select("id").Where("user_id = ?", userId)).
+		First(line).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return customerrors.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// UpdateCartLine implements CartRepository, qty and price of the line is changed
+func (r *cartRepositoryImpl) UpdateCartLine(line *model.CartLine, ctx context.Context) error {
+	res := r.db.WithContext(ctx).Model(&model.CartLine{}).Where("id = ? AND cart_id = ?", line.ID, line.CartID).
+		Updates(map[string]interface{}{"qty": line.Qty, "price": line.Price})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return customerrors.ErrNotFound
+	}
+	return nil
+}
+
+// RemoveCartLines implements CartRepository, only line of the user is removed
+func (r *cartRepositoryImpl) RemoveCartLines(userId uuid.UUID, lineIds []uint, ctx context.Context) error {
+	res := r.db.WithContext(ctx).Where("id IN ? AND cart_id IN (?)", lineIds, r.db.Model(&model.Cart{}).Select("id").Where("user_id = ?", userId)).
+		Delete(&model.CartLine{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return customerrors.ErrNotFound
+	}
+	return nil
+}
+
+func NewCartRepository(db *gorm.DB) CartRepository {
+	return &cartRepositoryImpl{
+		db: db,
+	}
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+)
+
+type CartRepository interface {
+	FindCart(cart *model.Cart, ctx context.Context) error
+	SetCartCheckpoint(cart *model.Cart, ctx context.Context) error
+	AddCartLine(userId uuid.UUID, line *model.CartLine, ctx context.Context) error
+	FindCartLine(userId uuid.UUID, line *model.CartLine, ctx context.Context) error
+	UpdateCartLine(line *model.CartLine, ctx context.Context) error
+	RemoveCartLines(userId uuid.UUID, lineIds []uint, ctx context.Context) error
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/database/testdb"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCartRepository(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	repository := NewCartRepository(db)
+	userId, otherId := uuid.New(), uuid.New()
+	pasar := model.Checkpoint{ID: uuid.New(), Name: "pasar"}
+	assert.NoError(t, db.Create(&pasar).Error)
+
+	// user without cart has empty cart
+	cart := model.Cart{UserID: userId}
+	assert.NoError(t, repository.FindCart(&cart, ctx))
+	assert.Empty(t, cart.Lines)
+
+	// same item and variant is merged into one line with the last price
+	bayam := model.CartLine{ItemID: 1, Qty: 1.5, Price: 10000}
+	assert.NoError(t, repository.AddCartLine(userId, &bayam, ctx))
+	assert.NoError(t, repository.AddCartLine(userId, &model.CartLine{ItemID: 1, Qty: 1, Price: 12000}, ctx))
+	assert.NoError(t, repository.AddCartLine(userId, &model.CartLine{ItemID: 1, VariantID: 2, Qty: 1}, ctx))
+	other := model.CartLine{ItemID: 1, Qty: 3}
+	assert.NoError(t, repository.AddCartLine(otherId, &other, ctx))
+
+	unknown := uuid.New()
+	assert.Equal(t, customerrors.ErrCheckpointNotFound, repository.SetCartCheckpoint(&model.Cart{UserID: userId, CheckpointID: &unknown}, ctx))
+	assert.NoError(t, repository.SetCartCheckpoint(&model.Cart{UserID: userId, CheckpointID: &pasar.ID}, ctx))
+
+	cart = model.Cart{UserID: userId}
+	assert.NoError(t, repository.FindCart(&cart, ctx))
+	assert.Equal(t, &pasar.ID, cart.CheckpointID)
+	assert.Len(t, cart.Lines, 2)
+	assert.Equal(t, bayam.ID, cart.Lines[0].ID)
+	assert.Equal(t, 2.5, cart.Lines[0].Qty)
+	assert.Equal(t, 12000, cart.Lines[0].Price)
+
+	// line of other user cant be found, changed or removed
+	assert.Equal(t, customerrors.ErrNotFound, repository.FindCartLine(userId, &model.CartLine{ID: other.ID}, ctx))
+	line := model.CartLine{ID: bayam.ID}
+	assert.NoError(t, repository.FindCartLine(userId, &line, ctx))
+	line.Qty, line.Price = 4, 11000
+	assert.NoError(t, repository.UpdateCartLine(&line, ctx))
+	assert.Equal(t, customerrors.ErrNotFound, repository.UpdateCartLine(&model.CartLine{ID: other.ID, CartID: line.CartID, Qty: 1}, ctx))
+	assert.Equal(t, customerrors.ErrNotFound, repository.RemoveCartLines(userId, []uint{other.ID}, ctx))
+	assert.NoError(t, repository.RemoveCartLines(userId, []uint{cart.Lines[1].ID}, ctx))
+
+	cart = model.Cart{UserID: userId}
+	assert.NoError(t, repository.FindCart(&cart, ctx))
+	assert.Len(t, cart.Lines, 1)
+	assert.Equal(t, 4.0, cart.Lines[0].Qty)
+	assert.Equal(t, 11000, cart.Lines[0].Price)
+}
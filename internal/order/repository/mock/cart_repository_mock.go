@@ -0,0 +1,49 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type CartRepositoryMock struct {
+	mock.Mock
+}
+
+func (b *CartRepositoryMock) FindCart(cart *model.Cart, ctx context.Context) error {
+	args := b.Called(cart)
+
+	return args.Error(0)
+}
+
+func (b *CartRepositoryMock) SetCartCheckpoint(cart *model.Cart, ctx context.Context) error {
+	args := b.Called(cart)
+
+	return args.Error(0)
+}
+
+func (b *CartRepositoryMock) AddCartLine(userId uuid.UUID, line *model.CartLine, ctx context.Context) error {
+	args := b.Called(userId, line)
+
+	return args.Error(0)
+}
+
+func (b *CartRepositoryMock) FindCartLine(userId uuid.UUID, line *model.CartLine, ctx context.Context) error {
+	args := b.Called(userId, line)
+
+	return args.Error(0)
+}
+
+func (b *CartRepositoryMock) UpdateCartLine(line *model.CartLine, ctx context.Context) error {
+	args := b.Called(line)
+
+	return args.Error(0)
+}
+
+func (b *CartRepositoryMock) RemoveCartLines(userId uuid.UUID, lineIds []uint, ctx context.Context) error {
+	args := b.Called(userId, lineIds)
+
+	return args.Error(0)
+}
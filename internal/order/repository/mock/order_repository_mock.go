@@ -2,9 +2,12 @@ package mock
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/repository"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -17,47 +20,52 @@ func (b *OrderRepositoryMock) CreateOrder(order *model.Order, ctx context.Contex
 	return args.Error(0)
 }
 
-func (b *OrderRepositoryMock) FindAllOrders(ctx context.Context) ([]model.Order, error) {
+func (b *OrderRepositoryMock) CreateCartOrder(order *model.Order, lines []model.CartLine, ctx context.Context) error {
 	args := b.Called()
-	return args.Get(0).([]model.Order), args.Error(1)
+	return args.Error(0)
 }
 
-func (b *OrderRepositoryMock) FindOrder(userId uuid.UUID, ctx context.Context) ([]model.Order, error) {
+func (b *OrderRepositoryMock) FindAllOrders(opts query.Options, ctx context.Context) ([]model.Order, int64, error) {
 	args := b.Called()
-	return args.Get(0).([]model.Order), args.Error(1)
+	return args.Get(0).([]model.Order), args.Get(1).(int64), args.Error(2)
 }
 
-func (b *OrderRepositoryMock) FindOrderDetail(order *model.Order, ctx context.Context) error {
+func (b *OrderRepositoryMock) FindOrdersByCheckpoints(checkpointIds []uuid.UUID, opts query.Options, ctx context.Context) ([]model.Order, int64, error) {
+	args := b.Called(checkpointIds)
+	return args.Get(0).([]model.Order), args.Get(1).(int64), args.Error(2)
+}
+
+func (b *OrderRepositoryMock) FindOrder(userId uuid.UUID, opts query.Options, ctx context.Context) ([]model.Order, int64, error) {
 	args := b.Called()
-	return args.Error(0)
+	return args.Get(0).([]model.Order), args.Get(1).(int64), args.Error(2)
 }
 
-func (b *OrderRepositoryMock) CencelOrder(orderId uuid.UUID, ctx context.Context) error {
+func (b *OrderRepositoryMock) FindOrderDetail(order *model.Order, ctx context.Context) error {
 	args := b.Called()
 	return args.Error(0)
 }
 
 func (b *OrderRepositoryMock) FindOrderById(order *model.Order, ctx context.Context) error {
-	args := b.Called()
+	args := b.Called(order)
 	return args.Error(0)
 }
 
-func (b *OrderRepositoryMock) OrderReady(orderId uuid.UUID, ctx context.Context) error {
+func (b *OrderRepositoryMock) InitStatusOrder() error {
 	args := b.Called()
 	return args.Error(0)
 }
 
-func (b *OrderRepositoryMock) OrderDone(orderId uuid.UUID, ctx context.Context) error {
-	args := b.Called()
-	return args.Error(0)
+func (b *OrderRepositoryMock) FindExpiredOrders(statusOrderId uint, now time.Time, ctx context.Context) ([]model.Order, error) {
+	args := b.Called(statusOrderId, now)
+	return args.Get(0).([]model.Order), args.Error(1)
 }
 
-func (b *OrderRepositoryMock) OrderWaiting(orderId uuid.UUID, ctx context.Context) error {
-	args := b.Called()
+func (b *OrderRepositoryMock) UpdateStatusOrder(order *model.Order, change *repository.StatusChange, ctx context.Context) error {
+	args := b.Called(order, change)
 	return args.Error(0)
 }
 
-func (b *OrderRepositoryMock) InitStatusOrder() error {
-	args := b.Called()
-	return args.Error(0)
+func (b *OrderRepositoryMock) FindOrderHistory(orderId uuid.UUID, ctx context.Context) ([]model.OrderStatusHistory, error) {
+	args := b.Called(orderId)
+	return args.Get(0).([]model.OrderStatusHistory), args.Error(1)
 }
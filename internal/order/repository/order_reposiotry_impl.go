@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"sort"
 	"strings"
 	"time"
 
@@ -9,6 +10,8 @@ import (
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/stock"
 	"gorm.io/gorm"
 )
 
@@ -25,10 +28,76 @@ func (r *orderRepositoryImpl) FindOrderById(order *model.Order, ctx context.Cont
 	return nil
 }
 
+func variantID(ord model.OrderDetail) uint {
+	if ord.VariantID == nil {
+		return 0
+	}
+	return *ord.VariantID
+}
+
 // CreateOrder implements OrderRepository
 func (r *orderRepositoryImpl) CreateOrder(order *model.Order, ctx context.Context) error {
-	err := r.db.WithContext(ctx).Create(order).Error
+	return r.createOrder(order, nil, ctx)
+}
+
+// CreateCartOrder implements OrderRepository, ordered cart lines is removed in same transaction.
+// Line changed after it is read, like qty merged by adding same item, fail the checkout
+func (r *orderRepositoryImpl) CreateCartOrder(order *model.Order, lines []model.CartLine, ctx context.Context) error {
+	return r.createOrder(order, lines, ctx)
+}
+
+func (r *orderRepositoryImpl) createOrder(order *model.Order, lines []model.CartLine, ctx context.Context) error {
+	// lock item rows in same order on every checkout to avoid deadlock
+	sort.SliceStable(order.OrderDetail, func(i, j int) bool {
+		a, b := order.OrderDetail[i], order.OrderDetail[j]
+		if a.ItemID != b.ItemID {
+			return a.ItemID < b.ItemID
+		}
+		return variantID(a) < variantID(b)
+	})
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, line := range lines {
+			res := tx.Where("id = ? AND qty = ? AND price = ?", line.ID, line.Qty, line.Price).Delete(&model.CartLine{})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return customerrors.ErrCartChanged
+			}
+		}
+		for _, ord := range order.OrderDetail { // reserve stock, fail when not enough qty
+			err := stock.Take(tx, &model.StockMovement{
+				ItemID:       ord.ItemID,
+				VariantID:    ord.VariantID,
+				Qty:          -ord.Qty,
+				Reason:       constants.Stock_reason_sale,
+				OrderID:      &order.ID,
+				CheckpointID: &order.CheckpointID,
+				ActorID:      &order.UserID,
+			})
+			if err == customerrors.ErrStockNotEnough {
+				return customerrors.ErrQtyOrder
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(order).Error; err != nil {
+			return err
+		}
+		// order is created by customer, timeline of order start here
+		return tx.Create(&model.OrderStatusHistory{
+			CreatedAt:  order.CreatedAt,
+			OrderID:    order.ID,
+			ToStatusID: order.StatusOrderID,
+			ActorID:    &order.UserID,
+			Source:     constants.Order_source_user_api,
+		}).Error
+	})
 	if err != nil {
+		if err == customerrors.ErrQtyOrder || err == customerrors.ErrCartChanged {
+			return err
+		}
 		if strings.Contains(err.Error(), "Duplicate entry") {
 			return customerrors.ErrDuplicateData
 		}
@@ -41,18 +110,20 @@ func (r *orderRepositoryImpl) CreateOrder(order *model.Order, ctx context.Contex
 }
 
 // FindOrder implements OrderRepository
-func (r *orderRepositoryImpl) FindOrder(userId uuid.UUID, ctx context.Context) ([]model.Order, error) {
+func (r *orderRepositoryImpl) FindOrder(userId uuid.UUID, opts query.Options, ctx context.Context) ([]model.Order, int64, error) {
 	var orders []model.Order
-	err := r.db.WithContext(ctx).Where("user_id = ?", userId).Preload("StatusOrder").Preload("User").Preload("Checkpoint").Find(&orders).Error
+	total, err := query.Find(r.db.WithContext(ctx).Model(&model.Order{}).Where("user_id = ?", userId).Preload("StatusOrder").Preload("User").Preload("Checkpoint"), opts, &orders)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return orders, err
+	return orders, total, nil
 }
 
 // FindOrderDetail implements OrderRepository
 func (r *orderRepositoryImpl) FindOrderDetail(order *model.Order, ctx context.Context) error {
-	err := r.db.WithContext(ctx).Where("user_id = ? AND id = ?", order.UserID, order.ID).Preload("OrderDetail.Item").Preload("OrderDetail").Preload("StatusOrder").Preload("Checkpoint").Find(&order).Error
+	err := r.db.WithContext(ctx).Where("user_id = ? AND id = ?", order.UserID, order.ID).Preload("OrderDetail.Item", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped() // archived item still resolve on past order
+	}).Preload("OrderDetail").Preload("StatusOrder").Preload("Checkpoint").Find(&order).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return customerrors.ErrNotFound
@@ -63,78 +134,126 @@ func (r *orderRepositoryImpl) FindOrderDetail(order *model.Order, ctx context.Co
 }
 
 // FindAllOrders implements OrderRepository
-func (r *orderRepositoryImpl) FindAllOrders(ctx context.Context) ([]model.Order, error) {
+func (r *orderRepositoryImpl) FindAllOrders(opts query.Options, ctx context.Context) ([]model.Order, int64, error) {
 	var orders []model.Order
-	err := r.db.WithContext(ctx).Preload("OrderDetail").Preload("StatusOrder").Preload("User").Find(&orders).Error
+	total, err := query.Find(r.db.WithContext(ctx).Model(&model.Order{}).Preload("OrderDetail").Preload("StatusOrder").Preload("User"), opts, &orders)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return orders, err
+	return orders, total, nil
 }
 
-// CencelOrder implements OrderRepository
-func (r *orderRepositoryImpl) CencelOrder(orderId uuid.UUID, ctx context.Context) error {
-	order := model.Order{
-		ID: orderId,
-	}
-	res := r.db.WithContext(ctx).Model(&order).Update("status_order_id", constants.Cencel_status_order_id)
-	if res.Error != nil {
-		return res.Error
+// FindOrdersByCheckpoints implements OrderRepository
+func (r *orderRepositoryImpl) FindOrdersByCheckpoints(checkpointIds []uuid.UUID, opts query.Options, ctx context.Context) ([]model.Order, int64, error) {
+	var orders []model.Order
+	if len(checkpointIds) == 0 {
+		return orders, 0, nil
 	}
-	if res.RowsAffected == 0 {
-		return customerrors.ErrNotFound
+	total, err := query.Find(r.db.WithContext(ctx).Model(&model.Order{}).Where("checkpoint_id IN ?", checkpointIds).Preload("OrderDetail").Preload("StatusOrder").Preload("User"), opts, &orders)
+	if err != nil {
+		return nil, 0, err
 	}
-	return nil
+	return orders, total, nil
 }
 
-// OrderReady implements OrderRepository
-func (r *orderRepositoryImpl) OrderReady(orderId uuid.UUID, ctx context.Context) error {
-	order := model.Order{
-		ID: orderId,
-	}
-	res := r.db.WithContext(ctx).Model(&order).Update("status_order_id", constants.Ready_status_order_id)
-	if res.Error != nil {
-		return res.Error
-	}
-	if res.RowsAffected == 0 {
-		return customerrors.ErrNotFound
-	}
-	return nil
-}
+// UpdateStatusOrder implements OrderRepository
+func (r *orderRepositoryImpl) UpdateStatusOrder(order *model.Order, change *StatusChange, ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// status only change when order still in from status, so concurrent request cant move it twice
+		res := tx.Model(&model.Order{}).Where("id = ? AND status_order_id = ?", order.ID, change.From).Updates(map[string]interface{}{
+			"status_order_id": order.StatusOrderID,
+			"code":            order.Code,
+			"hash":            order.Hash,
+			"expired_order":   order.ExpiredOrder,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return customerrors.ErrUpdateStatusOrder
+		}
+		err := tx.Create(change.History).Error
+		if err != nil {
+			return err
+		}
 
-// OrderDone implements OrderRepository
-func (r *orderRepositoryImpl) OrderDone(orderId uuid.UUID, ctx context.Context) error {
-	order := model.Order{
-		ID: orderId,
-	}
+		if change.RestoreStock {
+			// stock go back to checkpoint and batches it was reserved from
+			var sales []model.StockMovement
+			err := tx.Preload("Batches").Where("order_id = ? AND reason = ?", order.ID, constants.Stock_reason_sale).Find(&sales).Error
+			if err != nil {
+				return err
+			}
+			if len(sales) == 0 { // order older than the ledger go back to unallocated stock
+				var orderDetails []model.OrderDetail
+				err := tx.Where("order_id = ?", order.ID).Find(&orderDetails).Error
+				if err != nil {
+					return err
+				}
+				for _, ord := range orderDetails {
+					sales = append(sales, model.StockMovement{ItemID: ord.ItemID, VariantID: ord.VariantID, Qty: -ord.Qty})
+				}
+			}
+			for _, sale := range sales { // release reserved item qty, archived item too so restore get its stock back
+				restock := model.StockMovement{
+					ItemID:       sale.ItemID,
+					VariantID:    sale.VariantID,
+					Qty:          -sale.Qty,
+					Reason:       constants.Stock_reason_cancel_restock,
+					OrderID:      &order.ID,
+					CheckpointID: sale.CheckpointID,
+					ActorID:      change.History.ActorID,
+				}
+				for _, batch := range sale.Batches {
+					restock.Batches = append(restock.Batches, model.StockBatchMove{BatchID: batch.BatchID, Qty: -batch.Qty})
+				}
+				if err := stock.Move(tx, &restock); err != nil {
+					return err
+				}
+			}
+		}
 
-	res := r.db.WithContext(ctx).Model(&order).Updates(&model.Order{
-		StatusOrderID: constants.Success_status_order_id,
-		Code:          "0",
-		ExpiredOrder:  time.Now(),
+		if change.Refund != nil { // refund paid transaction of the order
+			var transaction model.Transaction
+			err := tx.Where("order_id = ?", order.ID).First(&transaction).Error
+			if err != nil {
+				return err
+			}
+			change.Refund.TransactionID = transaction.ID
+			err = tx.Create(change.Refund).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if change.CompleteRefund {
+			err := tx.Model(&model.Refund{}).Where("order_id = ?", order.ID).UpdateColumn("status", constants.Refund_status_success).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
 	})
-	if res.Error != nil {
-		return res.Error
-	}
-	if res.RowsAffected == 0 {
-		return customerrors.ErrNotFound
-	}
-	return nil
 }
 
-// OrderWaiting implements OrderRepository
-func (r *orderRepositoryImpl) OrderWaiting(orderId uuid.UUID, ctx context.Context) error {
-	order := model.Order{
-		ID: orderId,
-	}
-	res := r.db.WithContext(ctx).Model(&order).Update("status_order_id", constants.Waiting_status_order_id)
-	if res.Error != nil {
-		return res.Error
+// FindOrderHistory implements OrderRepository
+func (r *orderRepositoryImpl) FindOrderHistory(orderId uuid.UUID, ctx context.Context) ([]model.OrderStatusHistory, error) {
+	var histories []model.OrderStatusHistory
+	err := r.db.WithContext(ctx).Where("order_id = ?", orderId).Preload("FromStatus").Preload("ToStatus").Order("created_at, id").Find(&histories).Error
+	if err != nil {
+		return nil, err
 	}
-	if res.RowsAffected == 0 {
-		return customerrors.ErrNotFound
+	return histories, nil
+}
+
+// FindExpiredOrders implements OrderRepository
+func (r *orderRepositoryImpl) FindExpiredOrders(statusOrderId uint, now time.Time, ctx context.Context) ([]model.Order, error) {
+	var orders []model.Order
+	err := r.db.WithContext(ctx).Where("status_order_id = ? AND expired_order <= ?", statusOrderId, now).Find(&orders).Error
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return orders, nil
 }
 
 // InitStatusOrder implements OrderRepository
@@ -2,20 +2,32 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
+// StatusChange is everything saved in one db transaction when order status move
+type StatusChange struct {
+	From           uint
+	RestoreStock   bool
+	History        *model.OrderStatusHistory
+	Refund         *model.Refund
+	CompleteRefund bool
+}
+
 type OrderRepository interface {
 	CreateOrder(order *model.Order, ctx context.Context) error
-	FindAllOrders(ctx context.Context) ([]model.Order, error)
-	FindOrder(userId uuid.UUID, ctx context.Context) ([]model.Order, error)
+	CreateCartOrder(order *model.Order, lines []model.CartLine, ctx context.Context) error
+	FindAllOrders(opts query.Options, ctx context.Context) ([]model.Order, int64, error)
+	FindOrdersByCheckpoints(checkpointIds []uuid.UUID, opts query.Options, ctx context.Context) ([]model.Order, int64, error)
+	FindOrder(userId uuid.UUID, opts query.Options, ctx context.Context) ([]model.Order, int64, error)
 	FindOrderDetail(order *model.Order, ctx context.Context) error
-	CencelOrder(orderId uuid.UUID, ctx context.Context) error
 	FindOrderById(order *model.Order, ctx context.Context) error
-	OrderReady(orderId uuid.UUID, ctx context.Context) error
-	OrderDone(orderId uuid.UUID, ctx context.Context) error
-	OrderWaiting(orderId uuid.UUID, ctx context.Context) error
+	UpdateStatusOrder(order *model.Order, change *StatusChange, ctx context.Context) error
+	FindOrderHistory(orderId uuid.UUID, ctx context.Context) ([]model.OrderStatusHistory, error)
 	InitStatusOrder() error
+	FindExpiredOrders(statusOrderId uint, now time.Time, ctx context.Context) ([]model.Order, error)
 }
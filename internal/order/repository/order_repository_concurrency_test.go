@@ -0,0 +1,330 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/database/testdb"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateOrderConcurrent(t *testing.T) {
+	const (
+		stock  = 10
+		orders = 50
+	)
+	db := testdb.New(t)
+	item := model.Item{
+		Name:  "bayam",
+		Qty:   stock,
+		Price: 5000,
+	}
+	assert.NoError(t, db.Create(&item).Error)
+	checkpointId := uuid.New()
+	assert.NoError(t, db.Create(&model.CheckpointStock{CheckpointID: checkpointId, ItemID: item.ID, Qty: stock}).Error)
+	repository := NewOrderRepository(db)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		success int
+		errs    []error
+	)
+	for i := 0; i < orders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			order := model.Order{
+				ID:           uuid.New(),
+				CheckpointID: checkpointId,
+				OrderDetail: []model.OrderDetail{
+					{ItemID: item.ID, Qty: 1, Price: item.Price, Total: item.Price},
+				},
+			}
+			err := repository.CreateOrder(&order, context.Background())
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				success++
+			} else {
+				errs = append(errs, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, stock, success)
+	for _, err := range errs {
+		assert.Equal(t, customerrors.ErrQtyOrder, err)
+	}
+	var result model.Item
+	assert.NoError(t, db.First(&result, item.ID).Error)
+	assert.Equal(t, float64(0), result.Qty)
+	var checkpointStock model.CheckpointStock
+	assert.NoError(t, db.Where("checkpoint_id = ?", checkpointId).First(&checkpointStock).Error)
+	assert.Equal(t, float64(0), checkpointStock.Qty)
+	var count int64
+	assert.NoError(t, db.Model(&model.Order{}).Count(&count).Error)
+	assert.Equal(t, int64(stock), count)
+}
+
+func TestCreateOrderReserveVariantStock(t *testing.T) {
+	db := testdb.New(t)
+	item := model.Item{
+		Name:  "wortel",
+		Qty:   5,
+		Price: 8000,
+		Variants: []model.ItemVariant{
+			{Name: "organik", Qty: 1.5, Price: 12000},
+		},
+	}
+	assert.NoError(t, db.Create(&item).Error)
+	variantId := item.Variants[0].ID
+	checkpointId := uuid.New()
+	assert.NoError(t, db.Create(&model.CheckpointStock{CheckpointID: checkpointId, ItemID: item.ID, VariantID: variantId, Qty: 1.5}).Error)
+	repository := NewOrderRepository(db)
+
+	order := model.Order{
+		ID:           uuid.New(),
+		CheckpointID: checkpointId,
+		OrderDetail: []model.OrderDetail{
+			{ItemID: item.ID, VariantID: &variantId, Qty: 1.25, Price: 12000, Total: 15000},
+		},
+	}
+	assert.NoError(t, repository.CreateOrder(&order, context.Background()))
+
+	order = model.Order{
+		ID:           uuid.New(),
+		CheckpointID: checkpointId,
+		OrderDetail: []model.OrderDetail{
+			{ItemID: item.ID, VariantID: &variantId, Qty: 0.5, Price: 12000, Total: 6000},
+		},
+	}
+	assert.Equal(t, customerrors.ErrQtyOrder, repository.CreateOrder(&order, context.Background()))
+
+	var variant model.ItemVariant
+	assert.NoError(t, db.First(&variant, variantId).Error)
+	assert.Equal(t, 0.25, variant.Qty)
+	var result model.Item
+	assert.NoError(t, db.First(&result, item.ID).Error)
+	assert.Equal(t, float64(5), result.Qty)
+}
+
+func TestOrderStockLedger(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	item := model.Item{Name: "bayam", Qty: 5, Price: 3000}
+	assert.NoError(t, db.Create(&item).Error)
+	checkpointId, otherCheckpointId := uuid.New(), uuid.New()
+	assert.NoError(t, db.Create(&model.CheckpointStock{CheckpointID: checkpointId, ItemID: item.ID, Qty: 3}).Error)
+	repository := NewOrderRepository(db)
+
+	// stock of other checkpoint cant be ordered, only the 2 unallocated
+	order := model.Order{
+		ID:            uuid.New(),
+		UserID:        uuid.New(),
+		CheckpointID:  otherCheckpointId,
+		StatusOrderID: constants.Pending_status_order_id,
+		OrderDetail: []model.OrderDetail{
+			{ItemID: item.ID, Qty: 4, Price: 3000, Total: 12000},
+		},
+	}
+	assert.Equal(t, customerrors.ErrQtyOrder, repository.CreateOrder(&order, ctx))
+	order.CheckpointID = checkpointId
+	assert.NoError(t, repository.CreateOrder(&order, ctx))
+
+	adminId := uuid.New()
+	order.StatusOrderID = constants.Cencel_status_order_id
+	assert.NoError(t, repository.UpdateStatusOrder(&order, &StatusChange{
+		From:         constants.Pending_status_order_id,
+		RestoreStock: true,
+		History: &model.OrderStatusHistory{
+			OrderID:      order.ID,
+			FromStatusID: &pending,
+			ToStatusID:   constants.Cencel_status_order_id,
+			ActorID:      &adminId,
+		},
+	}, ctx))
+
+	// sale take checkpoint stock first then unallocated stock, made by order owner.
+	// Cancel restock each part where it was taken, by who cancel the order
+	var moves []model.StockMovement
+	assert.NoError(t, db.Where("item_id = ?", item.ID).Order("id").Find(&moves).Error)
+	assert.Len(t, moves, 4)
+	assert.Equal(t, []string{constants.Stock_reason_sale, constants.Stock_reason_sale, constants.Stock_reason_cancel_restock, constants.Stock_reason_cancel_restock},
+		[]string{moves[0].Reason, moves[1].Reason, moves[2].Reason, moves[3].Reason})
+	assert.Equal(t, []float64{-3, -1, 3, 1}, []float64{moves[0].Qty, moves[1].Qty, moves[2].Qty, moves[3].Qty})
+	assert.Equal(t, []float64{2, 1, 4, 5}, []float64{moves[0].Balance, moves[1].Balance, moves[2].Balance, moves[3].Balance})
+	assert.Equal(t, order.UserID, *moves[0].ActorID)
+	assert.Equal(t, adminId, *moves[2].ActorID)
+	assert.Equal(t, order.ID, *moves[2].OrderID)
+	assert.Equal(t, checkpointId, *moves[0].CheckpointID)
+	assert.Nil(t, moves[1].CheckpointID)
+	assert.Equal(t, checkpointId, *moves[2].CheckpointID)
+	assert.Nil(t, moves[3].CheckpointID)
+	var checkpointStock model.CheckpointStock
+	assert.NoError(t, db.Where("checkpoint_id = ?", checkpointId).First(&checkpointStock).Error)
+	assert.Equal(t, float64(3), checkpointStock.Qty)
+
+	// timeline start when order is created
+	histories, err := repository.FindOrderHistory(order.ID, ctx)
+	assert.NoError(t, err)
+	assert.Len(t, histories, 2)
+	assert.Nil(t, histories[0].FromStatusID)
+	assert.Equal(t, uint(constants.Pending_status_order_id), histories[0].ToStatusID)
+	assert.Equal(t, constants.Order_source_user_api, histories[0].Source)
+	assert.Equal(t, order.UserID, *histories[0].ActorID)
+}
+
+func TestFindOrderDetailArchivedItem(t *testing.T) {
+	db := testdb.New(t)
+	item := model.Item{Name: "bayam", Qty: 5, Price: 5000}
+	assert.NoError(t, db.Create(&item).Error)
+	checkpointId := uuid.New()
+	assert.NoError(t, db.Create(&model.CheckpointStock{CheckpointID: checkpointId, ItemID: item.ID, Qty: 5}).Error)
+	repository := NewOrderRepository(db)
+
+	userId := uuid.New()
+	order := model.Order{
+		ID:           uuid.New(),
+		UserID:       userId,
+		CheckpointID: checkpointId,
+		OrderDetail: []model.OrderDetail{
+			{ItemID: item.ID, Qty: 1, Price: item.Price, Total: item.Price},
+		},
+	}
+	assert.NoError(t, repository.CreateOrder(&order, context.Background()))
+	assert.NoError(t, db.Delete(&item).Error)
+
+	found := model.Order{ID: order.ID, UserID: userId}
+	assert.NoError(t, repository.FindOrderDetail(&found, context.Background()))
+	assert.Len(t, found.OrderDetail, 1)
+	assert.Equal(t, "bayam", found.OrderDetail[0].Item.Name)
+}
+
+func TestOrderBatchAllocation(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	now := time.Now()
+	item := model.Item{Name: "bayam", Qty: 6, Price: 3000}
+	assert.NoError(t, db.Create(&item).Error)
+	checkpointId := uuid.New()
+	assert.NoError(t, db.Create(&model.CheckpointStock{CheckpointID: checkpointId, ItemID: item.ID, Qty: 6}).Error)
+	batches := []model.StockBatch{
+		{ItemID: item.ID, CheckpointID: &checkpointId, ExpiredAt: now.Add(72 * time.Hour), Qty: 3},
+		{ItemID: item.ID, CheckpointID: &checkpointId, ExpiredAt: now.Add(24 * time.Hour), Qty: 2},
+		{ItemID: item.ID, CheckpointID: &checkpointId, ExpiredAt: now.Add(-time.Hour), Qty: 1},
+	}
+	assert.NoError(t, db.Create(&batches).Error)
+	repository := NewOrderRepository(db)
+	batchQty := func() []float64 {
+		var qty []float64
+		assert.NoError(t, db.Model(&model.StockBatch{}).Order("id").Pluck("qty", &qty).Error)
+		return qty
+	}
+
+	// expired batch is never sold
+	order := model.Order{
+		ID:            uuid.New(),
+		CheckpointID:  checkpointId,
+		StatusOrderID: constants.Pending_status_order_id,
+		OrderDetail:   []model.OrderDetail{{ItemID: item.ID, Qty: 6, Price: 3000, Total: 18000}},
+	}
+	assert.Equal(t, customerrors.ErrQtyOrder, repository.CreateOrder(&order, ctx))
+
+	// batch expiring first is sold first
+	order.OrderDetail = []model.OrderDetail{{ItemID: item.ID, Qty: 3, Price: 3000, Total: 9000}}
+	assert.NoError(t, repository.CreateOrder(&order, ctx))
+	assert.Equal(t, []float64{2, 0, 1}, batchQty())
+
+	// cancel put stock back to its batches
+	order.StatusOrderID = constants.Cencel_status_order_id
+	assert.NoError(t, repository.UpdateStatusOrder(&order, &StatusChange{
+		From:         constants.Pending_status_order_id,
+		RestoreStock: true,
+		History: &model.OrderStatusHistory{
+			OrderID:      order.ID,
+			FromStatusID: &pending,
+			ToStatusID:   constants.Cencel_status_order_id,
+		},
+	}, ctx))
+	assert.Equal(t, []float64{3, 2, 1}, batchQty())
+}
+
+func TestCreateCartOrder(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	item := model.Item{Name: "bayam", Qty: 5, Price: 3000}
+	assert.NoError(t, db.Create(&item).Error)
+	checkpointId, userId := uuid.New(), uuid.New()
+	carts := NewCartRepository(db)
+	repository := NewOrderRepository(db)
+	assert.NoError(t, carts.AddCartLine(userId, &model.CartLine{ItemID: item.ID, Qty: 1, Price: 3000}, ctx))
+	cart := model.Cart{UserID: userId}
+	assert.NoError(t, carts.FindCart(&cart, ctx))
+
+	// same item added after cart is read is merged into the read line, checkout is refused
+	assert.NoError(t, carts.AddCartLine(userId, &model.CartLine{ItemID: item.ID, Qty: 1, Price: 3000}, ctx))
+	order := model.Order{
+		ID:            uuid.New(),
+		UserID:        userId,
+		CheckpointID:  checkpointId,
+		StatusOrderID: constants.Pending_status_order_id,
+		OrderDetail:   []model.OrderDetail{{ItemID: item.ID, Qty: 1, Price: 3000, Total: 3000}},
+	}
+	assert.Equal(t, customerrors.ErrCartChanged, repository.CreateCartOrder(&order, cart.Lines, ctx))
+	var orders, moves int64
+	assert.NoError(t, db.Model(&model.Order{}).Count(&orders).Error)
+	assert.NoError(t, db.Model(&model.StockMovement{}).Count(&moves).Error)
+	assert.Zero(t, orders)
+	assert.Zero(t, moves)
+
+	// cart read again is ordered and removed with the order
+	cart = model.Cart{UserID: userId}
+	assert.NoError(t, carts.FindCart(&cart, ctx))
+	assert.Equal(t, 2.0, cart.Lines[0].Qty)
+	order.OrderDetail = []model.OrderDetail{{ItemID: item.ID, Qty: 2, Price: 3000, Total: 6000}}
+	assert.NoError(t, repository.CreateCartOrder(&order, cart.Lines, ctx))
+	cart = model.Cart{UserID: userId}
+	assert.NoError(t, carts.FindCart(&cart, ctx))
+	assert.Empty(t, cart.Lines)
+}
+
+func TestOrderExpiredCheckpointBatch(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	item := model.Item{Name: "bayam", Qty: 9, Price: 3000}
+	assert.NoError(t, db.Create(&item).Error)
+	checkpointId := uuid.New()
+	assert.NoError(t, db.Create(&model.CheckpointStock{CheckpointID: checkpointId, ItemID: item.ID, Qty: 4}).Error)
+	assert.NoError(t, db.Create(&model.StockBatch{ItemID: item.ID, CheckpointID: &checkpointId, ExpiredAt: time.Now().Add(-time.Hour), Qty: 3}).Error)
+	repository := NewOrderRepository(db)
+
+	// checkpoint sell only its unexpired 1, the rest is unallocated stock and failed try change nothing
+	order := model.Order{
+		ID:            uuid.New(),
+		CheckpointID:  checkpointId,
+		StatusOrderID: constants.Pending_status_order_id,
+		OrderDetail:   []model.OrderDetail{{ItemID: item.ID, Qty: 2, Price: 3000, Total: 6000}},
+	}
+	assert.NoError(t, repository.CreateOrder(&order, ctx))
+	var saved model.Item
+	assert.NoError(t, db.First(&saved, item.ID).Error)
+	assert.Equal(t, 7.0, saved.Qty)
+	var checkpointStock model.CheckpointStock
+	assert.NoError(t, db.Where("checkpoint_id = ?", checkpointId).First(&checkpointStock).Error)
+	assert.Equal(t, 3.0, checkpointStock.Qty)
+	var moves []model.StockMovement
+	assert.NoError(t, db.Where("item_id = ?", item.ID).Order("id").Find(&moves).Error)
+	assert.Len(t, moves, 2)
+	assert.Equal(t, []float64{-1, -1}, []float64{moves[0].Qty, moves[1].Qty})
+	assert.Equal(t, []float64{8, 7}, []float64{moves[0].Balance, moves[1].Balance})
+	assert.Equal(t, checkpointId, *moves[0].CheckpointID)
+	assert.Nil(t, moves[1].CheckpointID)
+}
@@ -2,19 +2,24 @@ package repository
 
 import (
 	"context"
+	"database/sql/driver"
 	"errors"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/dto"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"github.com/stretchr/testify/suite"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"net/url"
 )
 
 type suiteOrderRepository struct {
@@ -23,6 +28,9 @@ type suiteOrderRepository struct {
 	repository *orderRepositoryImpl
 }
 
+// pending is from status of history, history of created order has no from status
+var pending uint = constants.Pending_status_order_id
+
 func (s *suiteOrderRepository) SetupSuite() {
 	db, mocking, _ := sqlmock.New()
 
@@ -93,6 +101,9 @@ func (s *suiteOrderRepository) TestCreateOrder() {
 				s.mock.ExpectRollback()
 			} else {
 				db.WillReturnResult(sqlmock.NewResult(1, 1))
+				s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `order_status_histories` (`created_at`,`order_id`,`from_status_id`,`to_status_id`,`actor_id`,`source`) VALUES (?,?,?,?,?,?)")).
+					WithArgs(sqlmock.AnyArg(), orderId, nil, v.Order.StatusOrderID, v.Order.UserID, constants.Order_source_user_api).
+					WillReturnResult(sqlmock.NewResult(1, 1))
 				s.mock.ExpectCommit()
 			}
 
@@ -106,6 +117,97 @@ func (s *suiteOrderRepository) TestCreateOrder() {
 	}
 }
 
+func (s *suiteOrderRepository) TestCreateOrderReserveStock() {
+	checkpointId := uuid.New()
+	testCase := []struct {
+		Name         string
+		Order        model.Order
+		RowsAffected []int64
+		ExpectedErr  error
+	}{
+		{
+			Name: "reserve stock success",
+			Order: model.Order{
+				ID:           uuid.New(),
+				CheckpointID: checkpointId,
+				OrderDetail: []model.OrderDetail{
+					{ItemID: 2, Qty: 1},
+					{ItemID: 1, Qty: 3},
+				},
+			},
+			RowsAffected: []int64{1, 1},
+			ExpectedErr:  nil,
+		},
+		{
+			Name: "stock run out",
+			Order: model.Order{
+				ID:           uuid.New(),
+				CheckpointID: checkpointId,
+				OrderDetail: []model.OrderDetail{
+					{ItemID: 1, Qty: 3},
+					{ItemID: 2, Qty: 1},
+				},
+			},
+			RowsAffected: []int64{1, 0},
+			ExpectedErr:  customerrors.ErrQtyOrder,
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuite()
+
+			s.mock.ExpectBegin()
+			for i, rows := range v.RowsAffected {
+				s.mock.ExpectExec("SAVEPOINT sp").WillReturnResult(sqlmock.NewResult(0, 0))
+				s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `checkpoint_stocks` SET `qty`=qty - ? WHERE checkpoint_id = ? AND item_id = ? AND variant_id = ? AND qty >= ?")).
+					WithArgs(float64(3-2*i), checkpointId, i+1, 0, float64(3-2*i)).
+					WillReturnResult(sqlmock.NewResult(0, rows))
+				if rows == 0 { // checkpoint dont keep any, unallocated stock run out too
+					s.mock.ExpectExec("ROLLBACK TO SAVEPOINT sp").WillReturnResult(sqlmock.NewResult(0, 0))
+					s.mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(qty), 0) FROM `checkpoint_stocks` WHERE checkpoint_id = ? AND item_id = ? AND variant_id = ?")).
+						WithArgs(checkpointId, i+1, 0).
+						WillReturnRows(sqlmock.NewRows([]string{"qty"}).AddRow(0))
+					s.mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(qty), 0) FROM `stock_batches` WHERE checkpoint_id = ? AND item_id = ? AND variant_id = ? AND qty > 0 AND expired_at <= ?")).
+						WithArgs(checkpointId, i+1, 0, sqlmock.AnyArg()).
+						WillReturnRows(sqlmock.NewRows([]string{"qty"}).AddRow(0))
+					s.mock.ExpectExec(regexp.QuoteMeta("qty - (SELECT COALESCE(SUM(qty), 0) FROM checkpoint_stocks WHERE item_id = ? AND variant_id = ?) >= ?")).
+						WithArgs(float64(3-2*i), i+1, float64(3-2*i), i+1, 0, float64(3-2*i)).
+						WillReturnResult(sqlmock.NewResult(0, 0))
+					break
+				}
+				s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `items` SET `qty`=qty - ? WHERE (id = ? AND qty >= ?) AND `items`.`deleted_at` IS NULL")).
+					WithArgs(float64(3-2*i), i+1, float64(3-2*i)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				s.mock.ExpectQuery(regexp.QuoteMeta("SELECT `qty` FROM `items` WHERE id = ?")).
+					WithArgs(i + 1).
+					WillReturnRows(sqlmock.NewRows([]string{"qty"}).AddRow(5))
+				s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `stock_batches` WHERE (item_id = ? AND variant_id = ? AND qty > 0) AND checkpoint_id = ? ORDER BY expired_at, id FOR UPDATE")).
+					WithArgs(i+1, 0, checkpointId).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `stock_movements` (`created_at`,`item_id`,`variant_id`,`qty`,`balance`,`reason`,`order_id`,`checkpoint_id`,`actor_id`,`note`) VALUES (?,?,?,?,?,?,?,?,?,?)")).
+					WithArgs(sqlmock.AnyArg(), i+1, nil, float64(2*i-3), float64(5), constants.Stock_reason_sale, v.Order.ID, checkpointId, v.Order.UserID, "").
+					WillReturnResult(sqlmock.NewResult(int64(i+1), 1))
+			}
+			if v.ExpectedErr != nil {
+				s.mock.ExpectRollback()
+			} else {
+				s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `orders`")).WillReturnResult(sqlmock.NewResult(1, 1))
+				s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `order_details`")).WillReturnResult(sqlmock.NewResult(1, 2))
+				s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `order_status_histories`")).WillReturnResult(sqlmock.NewResult(1, 1))
+				s.mock.ExpectCommit()
+			}
+
+			err := s.repository.CreateOrder(&v.Order, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			s.NoError(s.mock.ExpectationsWereMet())
+
+			s.TearDown()
+		})
+	}
+}
+
 func (s *suiteOrderRepository) TestFindOrderById() {
 	orderId := uuid.New()
 
@@ -163,7 +265,8 @@ func (s *suiteOrderRepository) TestFindOrder() {
 	// orderId2 := uuid.New()
 	checkpointId1 := uuid.New()
 	// checkpointId2 := uuid.New()
-	query := regexp.QuoteMeta("SELECT * FROM `orders` WHERE user_id = ? AND `orders`.`deleted_at` IS NULL")
+	countOrder := regexp.QuoteMeta("SELECT count(*) FROM `orders` WHERE user_id = ? AND `status_order_id` = ? AND `orders`.`deleted_at` IS NULL")
+	findOrder := regexp.QuoteMeta("SELECT * FROM `orders` WHERE user_id = ? AND `status_order_id` = ? AND `orders`.`deleted_at` IS NULL ORDER BY `created_at` DESC,`id` LIMIT 20")
 	preloadCheckpoint := regexp.QuoteMeta("SELECT * FROM `checkpoints` WHERE `checkpoints`.`id` = ? AND `checkpoints`.`deleted_at` IS NULL")
 	preloadStatusOrder := regexp.QuoteMeta("SELECT * FROM `status_orders` WHERE `status_orders`.`id` = ? AND `status_orders`.`deleted_at` IS NULL")
 	preloadUser := regexp.QuoteMeta("SELECT * FROM `users` WHERE `users`.`id` = ? AND `users`.`deleted_at` IS NULL")
@@ -219,7 +322,9 @@ func (s *suiteOrderRepository) TestFindOrder() {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.SetupSuite()
 
-			s.mock.ExpectQuery(query).WillReturnError(v.FindOrderErr).WillReturnRows(v.FindOrderRes)
+			s.mock.ExpectQuery(countOrder).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(len(v.ExpectedRes)))
+
+			s.mock.ExpectQuery(findOrder).WillReturnError(v.FindOrderErr).WillReturnRows(v.FindOrderRes)
 
 			s.mock.ExpectQuery(preloadCheckpoint).WillReturnRows(v.PreloadCheckpointRes)
 
@@ -227,10 +332,13 @@ func (s *suiteOrderRepository) TestFindOrder() {
 
 			s.mock.ExpectQuery(preloadUser).WillReturnRows(v.PreloadUserRes)
 
-			res, err := s.repository.FindOrder(v.UserId, context.Background())
+			opts, err := query.Parse(url.Values{"status": {"1"}}, dto.UserOrderQuery)
+			s.NoError(err)
+			res, total, err := s.repository.FindOrder(v.UserId, opts, context.Background())
 
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(v.ExpectedRes, res)
+			s.Equal(int64(len(v.ExpectedRes)), total)
 
 			s.TearDown()
 		})
@@ -313,7 +421,7 @@ func (s *suiteOrderRepository) TestFindOrderDetail() {
 
 			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `order_details` WHERE `order_type` = ? AND `order_details`.`order_id` = ? AND `order_details`.`deleted_at` IS NULL")).WillReturnRows(v.PreloadOrderDetailRes)
 
-			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `items` WHERE `items`.`id` = ? AND `items`.`deleted_at` IS NULL")).WillReturnRows(v.PreloadItemRes)
+			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `items` WHERE `items`.`id` = ?")).WillReturnRows(v.PreloadItemRes)
 
 			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `status_orders` WHERE `status_orders`.`id` = ? AND `status_orders`.`deleted_at` IS NULL")).WillReturnRows(v.PreloadStatusOrderRes)
 
@@ -388,6 +496,8 @@ func (s *suiteOrderRepository) TestFindAllOrders() {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.SetupSuite()
 
+			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `orders` WHERE `orders`.`deleted_at` IS NULL")).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(len(v.ExpectedRes)))
+
 			findOrderMock := s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `orders` WHERE `orders`.`deleted_at` IS NULL"))
 			findOrderMock.WillReturnError(v.FindOrderAllErr)
 			findOrderMock.WillReturnRows(v.FindOrderAllRes)
@@ -399,7 +509,478 @@ func (s *suiteOrderRepository) TestFindAllOrders() {
 			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE `users`.`id` = ? AND `users`.`deleted_at` IS NULL")).WillReturnRows(v.PreloadUserRes)
 
 			var ctx context.Context
-			res, err := s.repository.FindAllOrders(ctx)
+			res, total, err := s.repository.FindAllOrders(query.Options{}, ctx)
+
+			s.Equal(v.ExpectedErr, err)
+			s.Equal(v.ExpectedRes, res)
+			s.Equal(int64(len(v.ExpectedRes)), total)
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteOrderRepository) TestFindOrdersByCheckpoints() {
+	userId := uuid.New()
+	orderId := uuid.New()
+	checkpointId := uuid.New()
+
+	testCase := []struct {
+		Name            string
+		CheckpointIds   []uuid.UUID
+		ExpectedErr     error
+		ExpectedRes     []model.Order
+		ExpectQuery     bool
+		FindOrdersErr   error
+		FindOrdersRes   *sqlmock.Rows
+		PreloadStatusRs *sqlmock.Rows
+		PreloadUserRes  *sqlmock.Rows
+	}{
+		{
+			Name:          "success",
+			CheckpointIds: []uuid.UUID{checkpointId},
+			ExpectedErr:   nil,
+			ExpectedRes: []model.Order{{
+				ID:            orderId,
+				UserID:        userId,
+				User:          model.User{ID: userId, Name: "user"},
+				CheckpointID:  checkpointId,
+				StatusOrderID: constants.Ready_status_order_id,
+				StatusOrder:   model.StatusOrder{ID: constants.Ready_status_order_id, Name: "ready"},
+				OrderDetail:   []model.OrderDetail{},
+			}},
+			ExpectQuery: true,
+			FindOrdersRes: sqlmock.NewRows([]string{"id", "user_id", "checkpoint_id", "status_order_id"}).
+				AddRow(orderId, userId, checkpointId, constants.Ready_status_order_id),
+			PreloadStatusRs: sqlmock.NewRows([]string{"id", "name"}).AddRow(constants.Ready_status_order_id, "ready"),
+			PreloadUserRes:  sqlmock.NewRows([]string{"id", "name"}).AddRow(userId, "user"),
+		},
+		{
+			Name:          "error find orders",
+			CheckpointIds: []uuid.UUID{checkpointId},
+			ExpectedErr:   errors.New("internal error"),
+			ExpectedRes:   nil,
+			ExpectQuery:   true,
+			FindOrdersErr: errors.New("internal error"),
+		},
+		{
+			Name:          "operator without checkpoint",
+			CheckpointIds: []uuid.UUID{},
+			ExpectedErr:   nil,
+			ExpectedRes:   []model.Order(nil),
+			ExpectQuery:   false,
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuite()
+
+			if v.ExpectQuery {
+				s.mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `orders` WHERE checkpoint_id IN (?) AND `created_at` >= ? AND `created_at` <= ? AND `orders`.`deleted_at` IS NULL")).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(len(v.ExpectedRes)))
+				findOrderMock := s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `orders` WHERE checkpoint_id IN (?) AND `created_at` >= ? AND `created_at` <= ? AND `orders`.`deleted_at` IS NULL ORDER BY `grand_total`,`id` LIMIT 10"))
+				if v.FindOrdersErr != nil {
+					findOrderMock.WillReturnError(v.FindOrdersErr)
+				} else {
+					findOrderMock.WillReturnRows(v.FindOrdersRes)
+					s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `order_details`")).WillReturnRows(sqlmock.NewRows([]string{"order_id"}))
+					s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `status_orders`")).WillReturnRows(v.PreloadStatusRs)
+					s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users`")).WillReturnRows(v.PreloadUserRes)
+				}
+			}
+
+			opts, err := query.Parse(url.Values{"from": {"2022-11-01"}, "to": {"2022-11-30"}, "sort": {"grand_total"}, "limit": {"10"}}, dto.OrderQuery)
+			s.NoError(err)
+			res, total, err := s.repository.FindOrdersByCheckpoints(v.CheckpointIds, opts, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			s.Equal(v.ExpectedRes, res)
+			s.Equal(int64(len(v.ExpectedRes)), total)
+			s.NoError(s.mock.ExpectationsWereMet())
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteOrderRepository) TestUpdateStatusOrder() {
+	order := model.Order{
+		ID:            uuid.New(),
+		StatusOrderID: constants.Cencel_status_order_id,
+		ExpiredOrder:  time.Now(),
+	}
+	actorId, checkpointId := uuid.New(), uuid.New()
+	history := model.OrderStatusHistory{
+		CreatedAt:    time.Now(),
+		OrderID:      order.ID,
+		FromStatusID: &pending,
+		ToStatusID:   constants.Cencel_status_order_id,
+		ActorID:      &actorId,
+		Source:       constants.Order_source_user_api,
+	}
+
+	testCase := []struct {
+		Name              string
+		ExpectedErr       error
+		RestoreStock      bool
+		UpdateStatusRes   driver.Result
+		UpdateStatusErr   error
+		ExpectHistory     bool
+		CreateHistoryErr  error
+		FindSaleRes       *sqlmock.Rows
+		UpdateItemErr     error
+		ExpectItemUpdated bool
+	}{
+		{
+			Name:            "success cencel and release qty",
+			ExpectedErr:     nil,
+			RestoreStock:    true,
+			UpdateStatusRes: sqlmock.NewResult(0, 1),
+			ExpectHistory:   true,
+			FindSaleRes: sqlmock.NewRows([]string{"id", "order_id", "item_id", "qty", "checkpoint_id"}).
+				AddRow(1, order.ID, 1, -2, checkpointId),
+			ExpectItemUpdated: true,
+		},
+		{
+			Name:            "success without release qty",
+			ExpectedErr:     nil,
+			RestoreStock:    false,
+			UpdateStatusRes: sqlmock.NewResult(0, 1),
+			ExpectHistory:   true,
+		},
+		{
+			Name:             "error save history",
+			ExpectedErr:      errors.New("internal error"),
+			RestoreStock:     true,
+			UpdateStatusRes:  sqlmock.NewResult(0, 1),
+			ExpectHistory:    true,
+			CreateHistoryErr: errors.New("internal error"),
+		},
+		{
+			Name:            "order status already changed",
+			ExpectedErr:     customerrors.ErrUpdateStatusOrder,
+			RestoreStock:    true,
+			UpdateStatusRes: sqlmock.NewResult(0, 0),
+		},
+		{
+			Name:            "error update status",
+			ExpectedErr:     errors.New("internal error"),
+			RestoreStock:    true,
+			UpdateStatusErr: errors.New("internal error"),
+		},
+		{
+			Name:            "error release qty",
+			ExpectedErr:     errors.New("internal error"),
+			RestoreStock:    true,
+			UpdateStatusRes: sqlmock.NewResult(0, 1),
+			ExpectHistory:   true,
+			FindSaleRes: sqlmock.NewRows([]string{"id", "order_id", "item_id", "qty", "checkpoint_id"}).
+				AddRow(1, order.ID, 1, -2, checkpointId),
+			UpdateItemErr:     errors.New("internal error"),
+			ExpectItemUpdated: true,
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuite()
+
+			s.mock.ExpectBegin()
+			updateStatus := s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `orders` SET `code`=?,`expired_order`=?,`hash`=?,`status_order_id`=?,`updated_at`=? WHERE (id = ? AND status_order_id = ?) AND `orders`.`deleted_at` IS NULL")).
+				WithArgs(order.Code, order.ExpiredOrder, order.Hash, order.StatusOrderID, sqlmock.AnyArg(), order.ID, constants.Pending_status_order_id)
+			if v.UpdateStatusErr != nil {
+				updateStatus.WillReturnError(v.UpdateStatusErr)
+			} else {
+				updateStatus.WillReturnResult(v.UpdateStatusRes)
+			}
+			if v.ExpectHistory {
+				createHistory := s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `order_status_histories` (`created_at`,`order_id`,`from_status_id`,`to_status_id`,`actor_id`,`source`) VALUES (?,?,?,?,?,?)")).
+					WithArgs(history.CreatedAt, history.OrderID, history.FromStatusID, history.ToStatusID, history.ActorID, history.Source)
+				if v.CreateHistoryErr != nil {
+					createHistory.WillReturnError(v.CreateHistoryErr)
+				} else {
+					createHistory.WillReturnResult(sqlmock.NewResult(1, 1))
+				}
+			}
+			if v.FindSaleRes != nil {
+				s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `stock_movements` WHERE order_id = ? AND reason = ?")).
+					WithArgs(order.ID, constants.Stock_reason_sale).
+					WillReturnRows(v.FindSaleRes)
+				s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `stock_batch_moves` WHERE `stock_batch_moves`.`movement_id` = ?")).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "movement_id", "batch_id", "qty"}))
+			}
+			if v.ExpectItemUpdated {
+				s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `checkpoint_stocks` (`checkpoint_id`,`item_id`,`variant_id`,`qty`) VALUES (?,?,?,?) ON DUPLICATE KEY UPDATE `qty`=qty + ?")).
+					WithArgs(checkpointId, 1, 0, float64(2), float64(2)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				updateItem := s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `items` SET `qty`=qty + ? WHERE id = ?")).
+					WithArgs(float64(2), 1)
+				if v.UpdateItemErr != nil {
+					updateItem.WillReturnError(v.UpdateItemErr)
+				} else {
+					updateItem.WillReturnResult(sqlmock.NewResult(0, 1))
+					s.mock.ExpectQuery(regexp.QuoteMeta("SELECT `qty` FROM `items` WHERE id = ?")).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"qty"}).AddRow(7))
+					s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `stock_movements` (`created_at`,`item_id`,`variant_id`,`qty`,`balance`,`reason`,`order_id`,`checkpoint_id`,`actor_id`,`note`) VALUES (?,?,?,?,?,?,?,?,?,?)")).
+						WithArgs(sqlmock.AnyArg(), 1, nil, float64(2), float64(7), constants.Stock_reason_cancel_restock, order.ID, checkpointId, history.ActorID, "").
+						WillReturnResult(sqlmock.NewResult(1, 1))
+				}
+			}
+			if v.ExpectedErr != nil {
+				s.mock.ExpectRollback()
+			} else {
+				s.mock.ExpectCommit()
+			}
+
+			history := history
+			err := s.repository.UpdateStatusOrder(&order, &StatusChange{
+				From:         constants.Pending_status_order_id,
+				RestoreStock: v.RestoreStock,
+				History:      &history,
+			}, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			s.NoError(s.mock.ExpectationsWereMet())
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteOrderRepository) TestUpdateStatusOrderRefund() {
+	order := model.Order{
+		ID:            uuid.New(),
+		StatusOrderID: constants.Refund_status_order_id,
+	}
+	transactionId := uuid.New()
+
+	testCase := []struct {
+		Name               string
+		ExpectedErr        error
+		From               uint
+		Refund             *model.Refund
+		CompleteRefund     bool
+		FindTransactionErr error
+		CreateRefundErr    error
+		CompleteRefundErr  error
+	}{
+		{
+			Name:        "success request refund",
+			ExpectedErr: nil,
+			From:        constants.Ready_status_order_id,
+			Refund: &model.Refund{
+				OrderID:   order.ID,
+				RefundKey: "refund-key",
+				Reason:    constants.Refund_reason_expired,
+				Amount:    5000,
+				Status:    constants.Refund_status_pending,
+			},
+		},
+		{
+			Name:        "transaction of order not found",
+			ExpectedErr: gorm.ErrRecordNotFound,
+			From:        constants.Ready_status_order_id,
+			Refund: &model.Refund{
+				OrderID: order.ID,
+			},
+			FindTransactionErr: gorm.ErrRecordNotFound,
+		},
+		{
+			Name:        "error create refund",
+			ExpectedErr: errors.New("internal error"),
+			From:        constants.Ready_status_order_id,
+			Refund: &model.Refund{
+				OrderID:   order.ID,
+				RefundKey: "refund-key",
+				Reason:    constants.Refund_reason_expired,
+				Amount:    5000,
+				Status:    constants.Refund_status_pending,
+			},
+			CreateRefundErr: errors.New("internal error"),
+		},
+		{
+			Name:           "success complete refund",
+			ExpectedErr:    nil,
+			From:           constants.Refund_status_order_id,
+			CompleteRefund: true,
+		},
+		{
+			Name:              "error complete refund",
+			ExpectedErr:       errors.New("internal error"),
+			From:              constants.Refund_status_order_id,
+			CompleteRefund:    true,
+			CompleteRefundErr: errors.New("internal error"),
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuite()
+
+			s.mock.ExpectBegin()
+			s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `orders` SET `code`=?,`expired_order`=?,`hash`=?,`status_order_id`=?,`updated_at`=? WHERE (id = ? AND status_order_id = ?) AND `orders`.`deleted_at` IS NULL")).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `order_status_histories`")).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			if v.Refund != nil {
+				findTransaction := s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `transactions` WHERE order_id = ? AND `transactions`.`deleted_at` IS NULL ORDER BY `transactions`.`id` LIMIT 1")).
+					WithArgs(order.ID)
+				if v.FindTransactionErr != nil {
+					findTransaction.WillReturnError(v.FindTransactionErr)
+				} else {
+					findTransaction.WillReturnRows(sqlmock.NewRows([]string{"id", "order_id"}).AddRow(transactionId, order.ID))
+					createRefund := s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `refunds` (`created_at`,`updated_at`,`order_id`,`transaction_id`,`refund_key`,`reason`,`amount`,`status`,`attempts`,`last_error`) VALUES (?,?,?,?,?,?,?,?,?,?)")).
+						WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), order.ID, transactionId, v.Refund.RefundKey, v.Refund.Reason, v.Refund.Amount, v.Refund.Status, 0, "")
+					if v.CreateRefundErr != nil {
+						createRefund.WillReturnError(v.CreateRefundErr)
+					} else {
+						createRefund.WillReturnResult(sqlmock.NewResult(1, 1))
+					}
+				}
+			}
+			if v.CompleteRefund {
+				completeRefund := s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `refunds` SET `status`=? WHERE order_id = ?")).
+					WithArgs(constants.Refund_status_success, order.ID)
+				if v.CompleteRefundErr != nil {
+					completeRefund.WillReturnError(v.CompleteRefundErr)
+				} else {
+					completeRefund.WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+			}
+			if v.ExpectedErr != nil {
+				s.mock.ExpectRollback()
+			} else {
+				s.mock.ExpectCommit()
+			}
+
+			err := s.repository.UpdateStatusOrder(&order, &StatusChange{
+				From:           v.From,
+				History:        &model.OrderStatusHistory{OrderID: order.ID, FromStatusID: &v.From, ToStatusID: order.StatusOrderID},
+				Refund:         v.Refund,
+				CompleteRefund: v.CompleteRefund,
+			}, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			if v.Refund != nil && v.ExpectedErr == nil {
+				s.Equal(transactionId, v.Refund.TransactionID)
+			}
+			s.NoError(s.mock.ExpectationsWereMet())
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteOrderRepository) TestFindExpiredOrders() {
+	orderId := uuid.New()
+	now := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	testCase := []struct {
+		Name          string
+		Status        uint
+		ExpectedErr   error
+		ExpectedRes   []model.Order
+		FindOrdersErr error
+		FindOrdersRes *sqlmock.Rows
+	}{
+		{
+			Name:          "expired pending order",
+			Status:        constants.Pending_status_order_id,
+			ExpectedErr:   nil,
+			ExpectedRes:   []model.Order{{ID: orderId, StatusOrderID: constants.Pending_status_order_id}},
+			FindOrdersRes: sqlmock.NewRows([]string{"id", "status_order_id"}).AddRow(orderId, constants.Pending_status_order_id),
+		},
+		{
+			Name:          "no expired ready order",
+			Status:        constants.Ready_status_order_id,
+			ExpectedErr:   nil,
+			ExpectedRes:   []model.Order{},
+			FindOrdersRes: sqlmock.NewRows([]string{"id", "status_order_id"}),
+		},
+		{
+			Name:          "error find orders",
+			Status:        constants.Ready_status_order_id,
+			ExpectedErr:   errors.New("internal error"),
+			ExpectedRes:   nil,
+			FindOrdersErr: errors.New("internal error"),
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuite()
+
+			findOrderMock := s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `orders` WHERE (status_order_id = ? AND expired_order <= ?) AND `orders`.`deleted_at` IS NULL")).
+				WithArgs(v.Status, now)
+			if v.FindOrdersErr != nil {
+				findOrderMock.WillReturnError(v.FindOrdersErr)
+			} else {
+				findOrderMock.WillReturnRows(v.FindOrdersRes)
+			}
+
+			res, err := s.repository.FindExpiredOrders(v.Status, now, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			s.Equal(v.ExpectedRes, res)
+			s.NoError(s.mock.ExpectationsWereMet())
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteOrderRepository) TestFindOrderHistory() {
+	orderId := uuid.New()
+
+	testCase := []struct {
+		Name        string
+		ExpectedErr error
+		ExpectedRes []model.OrderStatusHistory
+		FindErr     error
+	}{
+		{
+			Name:        "find order history success",
+			ExpectedErr: nil,
+			ExpectedRes: []model.OrderStatusHistory{
+				{
+					ID:           1,
+					OrderID:      orderId,
+					FromStatusID: &pending,
+					FromStatus:   model.StatusOrder{ID: constants.Pending_status_order_id, Name: "pending"},
+					ToStatusID:   constants.Waiting_status_order_id,
+					ToStatus:     model.StatusOrder{ID: constants.Waiting_status_order_id, Name: "waiting"},
+					Source:       constants.Order_source_payment_webhook,
+				},
+			},
+		},
+		{
+			Name:        "error find order history",
+			ExpectedErr: errors.New("internal error"),
+			ExpectedRes: nil,
+			FindErr:     errors.New("internal error"),
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuite()
+
+			find := s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `order_status_histories` WHERE order_id = ? ORDER BY created_at, id")).
+				WithArgs(orderId)
+			if v.FindErr != nil {
+				find.WillReturnError(v.FindErr)
+			} else {
+				find.WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "from_status_id", "to_status_id", "source"}).
+					AddRow(1, orderId, constants.Pending_status_order_id, constants.Waiting_status_order_id, constants.Order_source_payment_webhook))
+				s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `status_orders` WHERE `status_orders`.`id` = ? AND `status_orders`.`deleted_at` IS NULL")).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(constants.Pending_status_order_id, "pending"))
+				s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `status_orders` WHERE `status_orders`.`id` = ? AND `status_orders`.`deleted_at` IS NULL")).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(constants.Waiting_status_order_id, "waiting"))
+			}
+
+			res, err := s.repository.FindOrderHistory(orderId, context.Background())
 
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(v.ExpectedRes, res)
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/dto"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+)
+
+// error of cart line shown as warning when cart is read, item or unit changed after it is added
+var cartLineWarnings = map[error]bool{
+	customerrors.ErrItemUnavailable: true,
+	customerrors.ErrVariantRequired: true,
+	customerrors.ErrInvalidVariant:  true,
+	customerrors.ErrQtyUnit:         true,
+}
+
+// FindCart implements OrderService
+func (s *orderServiceImpl) FindCart(userId string, ctx context.Context) (*dto.CartResponse, error) {
+	userIdUUID, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	cart := model.Cart{UserID: userIdUUID}
+	if err := s.cartRepo.FindCart(&cart, ctx); err != nil {
+		return nil, err
+	}
+	res := dto.CartResponse{
+		CheckpointID: cart.CheckpointID,
+		Lines:        dto.CartLinesResponse{},
+		CanCheckout:  cart.CheckpointID != nil && len(cart.Lines) > 0,
+	}
+	for i := range cart.Lines {
+		line, err := s.priceCartLine(&cart.Lines[i], cart.CheckpointID, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if line.Warning != "" {
+			res.CanCheckout = false
+		} else {
+			res.TotalPrice += line.Total
+		}
+		res.Lines = append(res.Lines, *line)
+	}
+	return &res, nil
+}
+
+// cartPriceChanged is true when current price differ from price of the line. Line added before
+// price is kept in cart has no price to compare
+func cartPriceChanged(line *model.CartLine, price int) bool {
+	return line.Price != 0 && line.Price != price
+}
+
+// priceCartLine price line of cart like order detail, stock is checked only when cart has checkpoint.
+// Line that cant be ordered get warning instead of error
+func (s *orderServiceImpl) priceCartLine(line *model.CartLine, checkpointId *uuid.UUID, ctx context.Context) (*dto.CartLineResponse, error) {
+	var res dto.CartLineResponse
+	res.FromModel(line)
+	ord := dto.OrderDetailRequest{ItemID: line.ItemID, VariantID: line.VariantID, Qty: line.Qty}
+	item, err := s.priceItem(&ord, ctx)
+	if err != nil {
+		if cartLineWarnings[err] {
+			res.Warning = err.Error()
+			return &res, nil
+		}
+		return nil, err
+	}
+	res.ItemName = item.Name
+	res.VariantName = ord.VariantName
+	res.Unit = ord.Unit
+	res.Price = ord.Price
+	res.Total = ord.Total
+	if cartPriceChanged(line, ord.Price) {
+		res.Warning = customerrors.ErrCartPriceChanged.Error()
+	}
+	if checkpointId == nil {
+		return &res, nil
+	}
+	stock, err := s.checkpointStock(&ord, *checkpointId, ctx)
+	if err != nil {
+		return nil, err
+	}
+	res.Available = &stock
+	if stock <= 0 {
+		res.Warning = customerrors.ErrCartOutOfStock.Error()
+	} else if stock < ord.Qty {
+		res.Warning = customerrors.ErrCartStockNotEnough.Error()
+	}
+	return &res, nil
+}
+
+// SetCartCheckpoint implements OrderService
+func (s *orderServiceImpl) SetCartCheckpoint(body dto.CartCheckpointRequest, userId string, ctx context.Context) error {
+	userIdUUID, err := uuid.Parse(userId)
+	if err != nil {
+		return customerrors.ErrInvalidId
+	}
+	cart := model.Cart{UserID: userIdUUID}
+	if body.CheckpointID != "" {
+		checkpointId, err := uuid.Parse(body.CheckpointID)
+		if err != nil {
+			return customerrors.ErrInvalidId
+		}
+		cart.CheckpointID = &checkpointId
+	}
+	return s.cartRepo.SetCartCheckpoint(&cart, ctx)
+}
+
+// AddCartLine implements OrderService, stock is not checked until cart is read or checked out
+func (s *orderServiceImpl) AddCartLine(body dto.CartLineRequest, userId string, ctx context.Context) error {
+	userIdUUID, err := uuid.Parse(userId)
+	if err != nil {
+		return customerrors.ErrInvalidId
+	}
+	ord := dto.OrderDetailRequest{ItemID: body.ItemID, VariantID: body.VariantID, Qty: body.Qty}
+	if _, err := s.priceItem(&ord, ctx); err != nil {
+		return err
+	}
+	line := body.ToModel()
+	line.Qty = ord.Qty
+	line.Price = ord.Price
+	return s.cartRepo.AddCartLine(userIdUUID, line, ctx)
+}
+
+// UpdateCartLine implements OrderService
+func (s *orderServiceImpl) UpdateCartLine(lineId string, body dto.CartLineQtyRequest, userId string, ctx context.Context) error {
+	id, err := strconv.Atoi(lineId)
+	if err != nil {
+		return customerrors.ErrInvalidId
+	}
+	userIdUUID, err := uuid.Parse(userId)
+	if err != nil {
+		return customerrors.ErrInvalidId
+	}
+	line := model.CartLine{ID: uint(id)}
+	if err := s.cartRepo.FindCartLine(userIdUUID, &line, ctx); err != nil {
+		return err
+	}
+	ord := dto.OrderDetailRequest{ItemID: line.ItemID, VariantID: line.VariantID, Qty: body.Qty}
+	if _, err := s.priceItem(&ord, ctx); err != nil {
+		return err
+	}
+	line.Qty = ord.Qty
+	line.Price = ord.Price
+	return s.cartRepo.UpdateCartLine(&line, ctx)
+}
+
+// RemoveCartLine implements OrderService
+func (s *orderServiceImpl) RemoveCartLine(lineId string, userId string, ctx context.Context) error {
+	id, err := strconv.Atoi(lineId)
+	if err != nil {
+		return customerrors.ErrInvalidId
+	}
+	userIdUUID, err := uuid.Parse(userId)
+	if err != nil {
+		return customerrors.ErrInvalidId
+	}
+	return s.cartRepo.RemoveCartLines(userIdUUID, []uint{uint(id)}, ctx)
+}
+
+// CheckoutCart implements OrderService, every line of cart is ordered like CreateOrder so it is
+// priced and checked again. Line which price changed since it is added is refused, ordered lines are
+// removed with the order and line added meanwhile stay
+func (s *orderServiceImpl) CheckoutCart(userId string, ctx context.Context) (*dto.NewOrder, error) {
+	userIdUUID, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	cart := model.Cart{UserID: userIdUUID}
+	if err := s.cartRepo.FindCart(&cart, ctx); err != nil {
+		return nil, err
+	}
+	if len(cart.Lines) == 0 {
+		return nil, customerrors.ErrCartEmpty
+	}
+	if cart.CheckpointID == nil {
+		return nil, customerrors.ErrCartCheckpoint
+	}
+	var body dto.OrderRequest
+	body.FromCart(&cart)
+	return s.createOrder(body, userId, cart.Lines, ctx)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/dto"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -17,14 +18,19 @@ func (b *OrderServiceMock) CreateOrder(body dto.OrderRequest, userId string, ctx
 	return args.Get(0).(*dto.NewOrder), args.Error(1)
 }
 
-func (b *OrderServiceMock) FindAllOrders(ctx context.Context) (dto.OrdersResponse, error) {
+func (b *OrderServiceMock) FindAllOrders(opts query.Options, ctx context.Context) (dto.OrdersResponse, *query.Page, error) {
 	args := b.Called()
-	return args.Get(0).(dto.OrdersResponse), args.Error(1)
+	return args.Get(0).(dto.OrdersResponse), args.Get(1).(*query.Page), args.Error(2)
 }
 
-func (b *OrderServiceMock) FindOrder(userId string, ctx context.Context) (dto.OrdersResponse, error) {
+func (b *OrderServiceMock) FindCheckpointOrders(userId string, isAdmin bool, opts query.Options, ctx context.Context) (dto.OrdersResponse, *query.Page, error) {
 	args := b.Called()
-	return args.Get(0).(dto.OrdersResponse), args.Error(1)
+	return args.Get(0).(dto.OrdersResponse), args.Get(1).(*query.Page), args.Error(2)
+}
+
+func (b *OrderServiceMock) FindOrder(userId string, opts query.Options, ctx context.Context) (dto.OrdersResponse, *query.Page, error) {
+	args := b.Called()
+	return args.Get(0).(dto.OrdersResponse), args.Get(1).(*query.Page), args.Error(2)
 }
 
 func (b *OrderServiceMock) FindOrderDetail(userId string, orderId string, ctx context.Context) (*dto.OrderWithDetailResponse, error) {
@@ -32,17 +38,17 @@ func (b *OrderServiceMock) FindOrderDetail(userId string, orderId string, ctx co
 	return args.Get(0).(*dto.OrderWithDetailResponse), args.Error(1)
 }
 
-func (b *OrderServiceMock) CencelOder(orderId string, ctx context.Context) error {
+func (b *OrderServiceMock) CencelOder(orderId string, userId string, isAdmin bool, ctx context.Context) error {
 	args := b.Called()
 	return args.Error(0)
 }
 
-func (b *OrderServiceMock) OrderReady(orderId string, ctx context.Context) error {
+func (b *OrderServiceMock) OrderReady(orderId string, userId string, isAdmin bool, ctx context.Context) error {
 	args := b.Called()
 	return args.Error(0)
 }
 
-func (b *OrderServiceMock) TakeOrder(body dto.TakeOrder, ctx context.Context) error {
+func (b *OrderServiceMock) TakeOrder(body dto.TakeOrder, userId string, isAdmin bool, ctx context.Context) error {
 	args := b.Called()
 	return args.Error(0)
 }
@@ -51,3 +57,38 @@ func (b *OrderServiceMock) SetOrderStatus(orderId uuid.UUID, status string, ctx
 	args := b.Called()
 	return args.Error(0)
 }
+
+func (b *OrderServiceMock) FindOrderHistory(userId string, orderId string, isAdmin bool, ctx context.Context) (dto.OrderStatusHistoriesResponse, error) {
+	args := b.Called()
+	return args.Get(0).(dto.OrderStatusHistoriesResponse), args.Error(1)
+}
+
+func (b *OrderServiceMock) FindCart(userId string, ctx context.Context) (*dto.CartResponse, error) {
+	args := b.Called()
+	return args.Get(0).(*dto.CartResponse), args.Error(1)
+}
+
+func (b *OrderServiceMock) SetCartCheckpoint(body dto.CartCheckpointRequest, userId string, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *OrderServiceMock) AddCartLine(body dto.CartLineRequest, userId string, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *OrderServiceMock) UpdateCartLine(lineId string, body dto.CartLineQtyRequest, userId string, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *OrderServiceMock) RemoveCartLine(lineId string, userId string, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *OrderServiceMock) CheckoutCart(userId string, ctx context.Context) (*dto.NewOrder, error) {
+	args := b.Called()
+	return args.Get(0).(*dto.NewOrder), args.Error(1)
+}
@@ -5,15 +5,24 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/dto"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
 type OrderService interface {
 	CreateOrder(body dto.OrderRequest, userId string, ctx context.Context) (*dto.NewOrder, error)
-	FindAllOrders(ctx context.Context) (dto.OrdersResponse, error)
-	FindOrder(userId string, ctx context.Context) (dto.OrdersResponse, error)
+	FindAllOrders(opts query.Options, ctx context.Context) (dto.OrdersResponse, *query.Page, error)
+	FindCheckpointOrders(userId string, isAdmin bool, opts query.Options, ctx context.Context) (dto.OrdersResponse, *query.Page, error)
+	FindOrder(userId string, opts query.Options, ctx context.Context) (dto.OrdersResponse, *query.Page, error)
 	FindOrderDetail(userId string, orderId string, ctx context.Context) (*dto.OrderWithDetailResponse, error)
-	CencelOder(orderId string, ctx context.Context) error
-	OrderReady(orderId string, ctx context.Context) error
-	TakeOrder(body dto.TakeOrder, ctx context.Context) error
+	CencelOder(orderId string, userId string, isAdmin bool, ctx context.Context) error
+	OrderReady(orderId string, userId string, isAdmin bool, ctx context.Context) error
+	TakeOrder(body dto.TakeOrder, userId string, isAdmin bool, ctx context.Context) error
 	SetOrderStatus(orderId uuid.UUID, status string, ctx context.Context) error
+	FindOrderHistory(userId string, orderId string, isAdmin bool, ctx context.Context) (dto.OrderStatusHistoriesResponse, error)
+	FindCart(userId string, ctx context.Context) (*dto.CartResponse, error)
+	SetCartCheckpoint(body dto.CartCheckpointRequest, userId string, ctx context.Context) error
+	AddCartLine(body dto.CartLineRequest, userId string, ctx context.Context) error
+	UpdateCartLine(lineId string, body dto.CartLineQtyRequest, userId string, ctx context.Context) error
+	RemoveCartLine(lineId string, userId string, ctx context.Context) error
+	CheckoutCart(userId string, ctx context.Context) (*dto.NewOrder, error)
 }
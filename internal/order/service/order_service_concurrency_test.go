@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/dto"
+	or "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/repository"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/database/testdb"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/pickup"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTakeOrderConcurrent(t *testing.T) {
+	const scans = 20
+	db := testdb.New(t)
+	token := pickup.Token{
+		OrderID:      uuid.New(),
+		CheckpointID: uuid.New(),
+		Nonce:        [constants.Pickup_nonce_length]byte{9, 8, 7},
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+	order := model.Order{
+		ID:            token.OrderID,
+		CheckpointID:  token.CheckpointID,
+		StatusOrderID: constants.Ready_status_order_id,
+		Code:          hex.EncodeToString(token.Nonce[:]),
+		Hash:          testPickupSigner.Sign(token),
+		ExpiredOrder:  token.ExpiresAt,
+	}
+	assert.NoError(t, db.Create(&order).Error)
+	service := newOrderService(or.NewOrderRepository(db), nil, nil, nil)
+	body := dto.TakeOrder{
+		Code: order.Hash,
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		success int
+		errs    []error
+	)
+	for i := 0; i < scans; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := service.TakeOrder(body, uuid.New().String(), true, context.Background())
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				success++
+			} else {
+				errs = append(errs, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, success)
+	for _, err := range errs {
+		assert.Equal(t, customerrors.ErrCodeUsed, err)
+	}
+	// scan after order taken
+	assert.Equal(t, customerrors.ErrCodeUsed, service.TakeOrder(body, uuid.New().String(), true, context.Background()))
+
+	var result model.Order
+	assert.NoError(t, db.First(&result, "id = ?", order.ID).Error)
+	assert.Equal(t, uint(constants.Success_status_order_id), result.StatusOrderID)
+	var histories int64
+	assert.NoError(t, db.Model(&model.OrderStatusHistory{}).Where("order_id = ?", order.ID).Count(&histories).Error)
+	assert.Equal(t, int64(1), histories)
+}
@@ -2,43 +2,71 @@ package service
 
 import (
 	"context"
-	"encoding/base64"
-	"fmt"
-	"strings"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
+	cp "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/checkpoint/repository"
 	it "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/item/repository"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/dto"
 	or "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/repository"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/statemachine"
 	urp "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/user/repository"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/payment"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/pickup"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/quantity"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
-type midtrans interface {
-	NewTransaction(order model.Order, user model.User) (string, error)
+// order status for each midtrans transaction status, other transaction status not change order
+var transactionOrderStatus = map[string]uint{
+	"capture":    constants.Waiting_status_order_id,
+	"settlement": constants.Waiting_status_order_id,
+	"deny":       constants.Cencel_status_order_id,
+	"cancel":     constants.Cencel_status_order_id,
+	"expire":     constants.Cencel_status_order_id,
+	"failure":    constants.Cencel_status_order_id,
+	// payment provider confirm refund
+	"refund":         constants.Refund_success_status_order_id,
+	"partial_refund": constants.Refund_success_status_order_id,
 }
 
 type orderServiceImpl struct {
-	orderRepo or.OrderRepository
-	itemRepo  it.ItemRepository
-	payment   midtrans
-	userRepo  urp.UserRepository
+	orderRepo      or.OrderRepository
+	cartRepo       or.CartRepository
+	itemRepo       it.ItemRepository
+	payment        payment.PaymentProvider
+	userRepo       urp.UserRepository
+	checkpointRepo cp.CheckpointRepository
+	machine        *statemachine.StateMachine
+	pickup         *pickup.Signer
 }
 
-func NewOrderService(orRepository or.OrderRepository, itRepository it.ItemRepository, midtrans midtrans, userRepo urp.UserRepository) OrderService {
+func NewOrderService(orRepository or.OrderRepository, cartRepository or.CartRepository, itRepository it.ItemRepository, paymentProvider payment.PaymentProvider, userRepo urp.UserRepository, cpRepository cp.CheckpointRepository, machine *statemachine.StateMachine, pickupSigner *pickup.Signer) OrderService {
 	return &orderServiceImpl{
-		orderRepo: orRepository,
-		itemRepo:  itRepository,
-		payment:   midtrans,
-		userRepo:  userRepo,
+		orderRepo:      orRepository,
+		cartRepo:       cartRepository,
+		itemRepo:       itRepository,
+		payment:        paymentProvider,
+		userRepo:       userRepo,
+		checkpointRepo: cpRepository,
+		machine:        machine,
+		pickup:         pickupSigner,
 	}
 }
 
 // CreateOrder implements OrderService
 func (s *orderServiceImpl) CreateOrder(body dto.OrderRequest, userId string, ctx context.Context) (*dto.NewOrder, error) {
+	return s.createOrder(body, userId, nil, ctx)
+}
+
+// createOrder create order of the body, lines is cart lines the body is made from and is removed with the order
+func (s *orderServiceImpl) createOrder(body dto.OrderRequest, userId string, lines []model.CartLine, ctx context.Context) (*dto.NewOrder, error) {
 	newId := uuid.New()
 	userIdUUID, err := uuid.Parse(userId)
 	if err != nil {
@@ -48,21 +76,24 @@ func (s *orderServiceImpl) CreateOrder(body dto.OrderRequest, userId string, ctx
 	if err != nil {
 		return nil, customerrors.ErrInvalidId
 	}
+	user, err := s.userRepo.FindUserByID(userId, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if user.VerifiedAt == nil {
+		return nil, customerrors.ErrEmailNotVerified
+	}
 	totalPrice := 0
 
 	// validating item and sum price
-	for i, ord := range body.Order {
-		var item model.Item
-		item.ID = ord.ItemID
-		err := s.itemRepo.FindItemById(&item, ctx)
+	for i := range body.Order {
+		err := s.priceOrderDetail(&body.Order[i], checkpointIdUUID, ctx)
 		if err != nil {
-			return nil, customerrors.ErrBadRequestBody
+			return nil, err
 		}
-		if item.Qty < ord.Qty || ord.Qty < 1 {
-			return nil, customerrors.ErrQtyOrder
+		if lines != nil && cartPriceChanged(&lines[i], body.Order[i].Price) {
+			return nil, customerrors.ErrCartPriceChanged
 		}
-		body.Order[i].Price = item.Price
-		body.Order[i].Total = (ord.Qty * item.Price)
 		totalPrice += body.Order[i].Total
 	}
 
@@ -80,13 +111,15 @@ func (s *orderServiceImpl) CreateOrder(body dto.OrderRequest, userId string, ctx
 		ExpiredOrder:  time.Now().Add(constants.ExpOrder),
 	}
 
-	err = s.orderRepo.CreateOrder(&newOrder, ctx)
+	if lines != nil {
+		err = s.orderRepo.CreateCartOrder(&newOrder, lines, ctx)
+	} else {
+		err = s.orderRepo.CreateOrder(&newOrder, ctx)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	user, _ := s.userRepo.FindUserByID(userId, ctx)
-
 	transaction, err := s.payment.NewTransaction(newOrder, *user)
 	if err != nil {
 		return nil, err
@@ -98,19 +131,89 @@ func (s *orderServiceImpl) CreateOrder(body dto.OrderRequest, userId string, ctx
 	return &newOrderResponse, nil
 }
 
+// priceOrderDetail fill price, total and snapshot of ordered item. Item with variant is priced
+// and stocked by the chosen variant, quantity must be multiple of item unit step and is taken
+// from stock of the chosen checkpoint
+func (s *orderServiceImpl) priceOrderDetail(ord *dto.OrderDetailRequest, checkpointId uuid.UUID, ctx context.Context) error {
+	if _, err := s.priceItem(ord, ctx); err != nil {
+		return err
+	}
+	stock, err := s.checkpointStock(ord, checkpointId, ctx)
+	if err != nil {
+		return err
+	}
+	if stock < ord.Qty {
+		return customerrors.ErrQtyOrder
+	}
+	return nil
+}
+
+// priceItem check ordered item, variant and qty, then fill price, total and snapshot of the item
+func (s *orderServiceImpl) priceItem(ord *dto.OrderDetailRequest, ctx context.Context) (*model.Item, error) {
+	var item model.Item
+	item.ID = ord.ItemID
+	err := s.itemRepo.FindItemById(&item, ctx)
+	if err != nil {
+		if err == customerrors.ErrNotFound { // archived item is not found too, even from stale client
+			return nil, customerrors.ErrItemUnavailable
+		}
+		return nil, err
+	}
+	price, variantName := item.Price, ""
+	if len(item.Variants) > 0 || ord.VariantID != 0 {
+		if ord.VariantID == 0 {
+			return nil, customerrors.ErrVariantRequired
+		}
+		var variant *model.ItemVariant
+		for i := range item.Variants {
+			if item.Variants[i].ID == ord.VariantID {
+				variant = &item.Variants[i]
+			}
+		}
+		if variant == nil {
+			return nil, customerrors.ErrInvalidVariant
+		}
+		price, variantName = variant.Price, variant.Name
+	}
+	ord.Qty = quantity.Round(ord.Qty)
+	if ord.Qty <= 0 || !quantity.IsMultiple(ord.Qty, item.Unit.Step) {
+		return nil, customerrors.ErrQtyUnit
+	}
+	ord.Price = price
+	ord.Total = quantity.Total(ord.Qty, price)
+	ord.VariantName = variantName
+	ord.Unit = item.Unit.Symbol
+	return &item, nil
+}
+
+// checkpointStock is stock of ordered item or variant left in the checkpoint
+func (s *orderServiceImpl) checkpointStock(ord *dto.OrderDetailRequest, checkpointId uuid.UUID, ctx context.Context) (float64, error) {
+	stocks, err := s.itemRepo.FindCheckpointStocks(checkpointId, []uint{ord.ItemID}, ctx)
+	if err != nil {
+		return 0, err
+	}
+	stock := 0.0
+	for _, each := range stocks {
+		if each.VariantID == ord.VariantID {
+			stock = each.Qty
+		}
+	}
+	return stock, nil
+}
+
 // FindOrder implements OrderService
-func (s *orderServiceImpl) FindOrder(userId string, ctx context.Context) (dto.OrdersResponse, error) {
+func (s *orderServiceImpl) FindOrder(userId string, opts query.Options, ctx context.Context) (dto.OrdersResponse, *query.Page, error) {
 	userIdUUID, err := uuid.Parse(userId)
 	if err != nil {
-		return nil, customerrors.ErrInvalidId
+		return nil, nil, customerrors.ErrInvalidId
 	}
-	orders, err := s.orderRepo.FindOrder(userIdUUID, ctx)
+	orders, total, err := s.orderRepo.FindOrder(userIdUUID, opts, ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	var ordersResponse dto.OrdersResponse
 	ordersResponse.FromModel(orders)
-	return ordersResponse, nil
+	return ordersResponse, query.NewPage(opts, total), nil
 }
 
 // FindOrderDetail implements OrderService
@@ -137,67 +240,91 @@ func (s *orderServiceImpl) FindOrderDetail(userId string, orderId string, ctx co
 }
 
 // TakeOrder implements OrderService
-func (s *orderServiceImpl) TakeOrder(body dto.TakeOrder, ctx context.Context) error {
-	byt, err := base64.StdEncoding.DecodeString(body.Code)
+func (s *orderServiceImpl) TakeOrder(body dto.TakeOrder, userId string, isAdmin bool, ctx context.Context) error {
+	actor, err := newActor(userId, staffSource(isAdmin))
 	if err != nil {
-		return customerrors.ErrOrderCode
+		return err
 	}
-	// data have 3 index orderId, order code, and checkpoint id
-	data := strings.Split(string(byt), " ")
-
-	id, err := uuid.Parse(data[0])
+	token, err := s.pickup.Verify(body.Code)
 	if err != nil {
-		return customerrors.ErrOrderCode
+		return err
 	}
-
-	orderCode := data[1]
-	checkpointId, err := uuid.Parse(data[2])
-	if err != nil {
-		return customerrors.ErrOrderCode
+	// operator only can hand over order in assigned checkpoint
+	if !isAdmin {
+		assigned, err := s.isOperatorOf(*actor.UserID, token.CheckpointID, ctx)
+		if err != nil {
+			return err
+		}
+		if !assigned {
+			return customerrors.ErrWrongCheckpoint
+		}
 	}
 
 	order := model.Order{
-		ID: id,
+		ID: token.OrderID,
 	}
 	err = s.orderRepo.FindOrderById(&order, ctx)
 	if err != nil {
 		return err
 	}
 
-	if body.CheckpointID != checkpointId.String() {
-		return customerrors.ErrWrongCheckpoint
+	// order code is cleared after order taken, so used token nonce never match again
+	nonce := hex.EncodeToString(token.Nonce[:])
+	if subtle.ConstantTimeCompare([]byte(nonce), []byte(order.Code)) != 1 {
+		return customerrors.ErrCodeUsed
 	}
 
-	fmt.Println(checkpointId, "  ", order.CheckpointID)
-
-	if orderCode != order.Code {
+	err = s.machine.Fire(&order, constants.Success_status_order_id, *actor, ctx)
+	// other scan of same token take the order first
+	if errors.Is(err, customerrors.ErrUpdateStatusOrder) && !statemachine.IsIllegalTransition(err) {
 		return customerrors.ErrCodeUsed
 	}
+	return err
+}
 
-	err = s.orderRepo.OrderDone(id, ctx)
+// FindCheckpointOrders implements OrderService
+func (s *orderServiceImpl) FindCheckpointOrders(userId string, isAdmin bool, opts query.Options, ctx context.Context) (dto.OrdersResponse, *query.Page, error) {
+	if isAdmin {
+		return s.FindAllOrders(opts, ctx)
+	}
+	id, err := uuid.Parse(userId)
 	if err != nil {
-		return err
+		return nil, nil, customerrors.ErrInvalidId
 	}
-	return nil
+	checkpointIds, err := s.checkpointRepo.FindOperatorCheckpointIds(id, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	orders, total, err := s.orderRepo.FindOrdersByCheckpoints(checkpointIds, opts, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var ordersResponse dto.OrdersResponse
+	ordersResponse.FromModel(orders)
+	return ordersResponse, query.NewPage(opts, total), nil
 }
 
 // FindAllOrders implements OrderService
-func (s *orderServiceImpl) FindAllOrders(ctx context.Context) (dto.OrdersResponse, error) {
-	orders, err := s.orderRepo.FindAllOrders(ctx)
+func (s *orderServiceImpl) FindAllOrders(opts query.Options, ctx context.Context) (dto.OrdersResponse, *query.Page, error) {
+	orders, total, err := s.orderRepo.FindAllOrders(opts, ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	var ordersResponse dto.OrdersResponse
 	ordersResponse.FromModel(orders)
-	return ordersResponse, nil
+	return ordersResponse, query.NewPage(opts, total), nil
 }
 
 // OderReady implements OrderService
-func (s *orderServiceImpl) OrderReady(orderId string, ctx context.Context) error {
+func (s *orderServiceImpl) OrderReady(orderId string, userId string, isAdmin bool, ctx context.Context) error {
 	id, err := uuid.Parse(orderId)
 	if err != nil {
 		return customerrors.ErrInvalidId
 	}
+	actor, err := newActor(userId, staffSource(isAdmin))
+	if err != nil {
+		return err
+	}
 	order := model.Order{
 		ID: id,
 	}
@@ -205,19 +332,33 @@ func (s *orderServiceImpl) OrderReady(orderId string, ctx context.Context) error
 	if err != nil {
 		return err
 	}
-	if order.StatusOrderID != constants.Waiting_status_order_id {
-		return customerrors.ErrUpdateStatusOrder
+	// order of other checkpoint is hidden from operator
+	if !isAdmin {
+		assigned, err := s.isOperatorOf(*actor.UserID, order.CheckpointID, ctx)
+		if err != nil {
+			return err
+		}
+		if !assigned {
+			return customerrors.ErrNotFound
+		}
 	}
-	err = s.orderRepo.OrderReady(id, ctx)
-	return err
+	return s.machine.Fire(&order, constants.Ready_status_order_id, *actor, ctx)
 }
 
 // CencelOder implements OrderService
-func (s *orderServiceImpl) CencelOder(orderId string, ctx context.Context) error {
+func (s *orderServiceImpl) CencelOder(orderId string, userId string, isAdmin bool, ctx context.Context) error {
 	id, err := uuid.Parse(orderId)
 	if err != nil {
 		return customerrors.ErrInvalidId
 	}
+	source := constants.Order_source_user_api
+	if isAdmin {
+		source = constants.Order_source_admin_api
+	}
+	actor, err := newActor(userId, source)
+	if err != nil {
+		return err
+	}
 	order := model.Order{
 		ID: id,
 	}
@@ -225,50 +366,97 @@ func (s *orderServiceImpl) CencelOder(orderId string, ctx context.Context) error
 	if err != nil {
 		return err
 	}
-	if order.StatusOrderID == constants.Pending_status_order_id {
-		return customerrors.ErrUpdateStatusOrder
+	// other user order is not found for customer
+	if !isAdmin && order.UserID != *actor.UserID {
+		return customerrors.ErrNotFound
 	}
-	err = s.orderRepo.CencelOrder(id, ctx)
-	return err
+	// only admin can cencel paid order, paid order is refunded
+	if isAdmin && order.StatusOrderID == constants.Waiting_status_order_id {
+		return s.machine.Fire(&order, constants.Refund_status_order_id, *actor, ctx)
+	}
+	return s.machine.Fire(&order, constants.Cencel_status_order_id, *actor, ctx)
 }
 
 // SetOrderStatus implements OrderService
 func (s *orderServiceImpl) SetOrderStatus(orderId uuid.UUID, status string, ctx context.Context) error {
+	to, ok := transactionOrderStatus[status]
+	if !ok {
+		return nil
+	}
 	order := model.Order{
 		ID: orderId,
 	}
-	err := s.orderRepo.FindOrderDetail(&order, ctx)
+	err := s.orderRepo.FindOrderById(&order, ctx)
 	if err != nil {
 		return err
 	}
-	switch status {
-	case "capture":
-		err := s.orderRepo.OrderWaiting(orderId, ctx)
-		if err != nil {
-			return err
-		}
-	case "settlement":
-		err := s.orderRepo.OrderWaiting(orderId, ctx)
-		if err != nil {
-			return err
-		}
-	case "deny":
-		err := s.orderRepo.CencelOrder(orderId, ctx)
-		if err != nil {
-			return err
-		}
-	case "cencel":
-		err := s.orderRepo.CencelOrder(orderId, ctx)
-		if err != nil {
-			return err
-		}
-	case "expired":
-		err := s.orderRepo.CencelOrder(orderId, ctx)
-		if err != nil {
-			return err
-		}
-	default:
+	// capture and settlement both move order to waiting
+	if order.StatusOrderID == to {
 		return nil
 	}
-	return nil
+	// payment arrive after order is cencelled, order cant be reopened so the payment is refunded
+	if order.StatusOrderID == constants.Cencel_status_order_id && to == constants.Waiting_status_order_id {
+		to = constants.Refund_status_order_id
+	}
+	return s.machine.Fire(&order, to, statemachine.Actor{Source: constants.Order_source_payment_webhook}, ctx)
+}
+
+// FindOrderHistory implements OrderService
+func (s *orderServiceImpl) FindOrderHistory(userId string, orderId string, isAdmin bool, ctx context.Context) (dto.OrderStatusHistoriesResponse, error) {
+	orderIdUUID, err := uuid.Parse(orderId)
+	if err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	order := model.Order{
+		ID: orderIdUUID,
+	}
+	err = s.orderRepo.FindOrderById(&order, ctx)
+	if err != nil {
+		return nil, err
+	}
+	// other user order is not found for customer
+	if !isAdmin && order.UserID.String() != userId {
+		return nil, customerrors.ErrNotFound
+	}
+	histories, err := s.orderRepo.FindOrderHistory(orderIdUUID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	var historiesResponse dto.OrderStatusHistoriesResponse
+	historiesResponse.FromModel(histories)
+	return historiesResponse, nil
+}
+
+// isOperatorOf report whether checkpoint is assigned to operator
+func (s *orderServiceImpl) isOperatorOf(userId uuid.UUID, checkpointId uuid.UUID, ctx context.Context) (bool, error) {
+	checkpointIds, err := s.checkpointRepo.FindOperatorCheckpointIds(userId, ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, id := range checkpointIds {
+		if id == checkpointId {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// staffSource return status history source of admin or checkpoint operator request
+func staffSource(isAdmin bool) string {
+	if isAdmin {
+		return constants.Order_source_admin_api
+	}
+	return constants.Order_source_operator_api
+}
+
+// newActor create actor of status change from user id in jwt claims
+func newActor(userId string, source string) (*statemachine.Actor, error) {
+	id, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	return &statemachine.Actor{
+		UserID: &id,
+		Source: source,
+	}, nil
 }
@@ -2,38 +2,53 @@ package service
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	checkpointRepositoryMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/checkpoint/repository/mock"
 	it "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/item/repository"
 	itemRepositoryMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/item/repository/mock"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/dto"
 	or "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/repository"
 	orderRepositoryMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/repository/mock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/statemachine"
 	urp "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/user/repository"
 	userRepositoryMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/user/repository/mock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/clock"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
-	midtransMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/payment/mock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/payment"
+	paymentMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/payment/mock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/pickup"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
 
 type suiteOrderService struct {
 	suite.Suite
-	orderRepositoryMock *orderRepositoryMock.OrderRepositoryMock
-	itemRepositoryMock  *itemRepositoryMock.ItemRepositoryMock
-	userRepositoryMock  *userRepositoryMock.UserRepositoryMock
-	payment             *midtransMock.MidtransMock
-	orderService        OrderService
+	orderRepositoryMock      *orderRepositoryMock.OrderRepositoryMock
+	itemRepositoryMock       *itemRepositoryMock.ItemRepositoryMock
+	userRepositoryMock       *userRepositoryMock.UserRepositoryMock
+	checkpointRepositoryMock *checkpointRepositoryMock.CheckpointRepositoryMock
+	payment                  *paymentMock.PaymentProviderMock
+	orderService             OrderService
 }
 
-func newOrderService(orRepository or.OrderRepository, itRepository it.ItemRepository, midtrans midtrans, userRepo urp.UserRepository) OrderService {
+var testPickupSigner, _ = pickup.NewSigner("secret", clock.Clock{})
+
+func newOrderService(orRepository or.OrderRepository, itRepository it.ItemRepository, paymentProvider payment.PaymentProvider, userRepo urp.UserRepository) OrderService {
 	return &orderServiceImpl{
 		orderRepo: orRepository,
 		itemRepo:  itRepository,
-		payment:   midtrans,
+		payment:   paymentProvider,
 		userRepo:  userRepo,
+		machine:   statemachine.NewStateMachine(orRepository, clock.Clock{}, payment.RefundPolicy{ExpiredPercent: 50, CancelledPercent: 100}, testPickupSigner),
+		pickup:    testPickupSigner,
 	}
 }
 
@@ -41,14 +56,17 @@ func (s *suiteOrderService) SetupSuit() {
 	s.orderRepositoryMock = new(orderRepositoryMock.OrderRepositoryMock)
 	s.itemRepositoryMock = new(itemRepositoryMock.ItemRepositoryMock)
 	s.userRepositoryMock = new(userRepositoryMock.UserRepositoryMock)
-	s.payment = new(midtransMock.MidtransMock)
+	s.checkpointRepositoryMock = new(checkpointRepositoryMock.CheckpointRepositoryMock)
+	s.payment = new(paymentMock.PaymentProviderMock)
 	s.orderService = newOrderService(s.orderRepositoryMock, s.itemRepositoryMock, s.payment, s.userRepositoryMock)
+	s.orderService.(*orderServiceImpl).checkpointRepo = s.checkpointRepositoryMock
 }
 
 func (s *suiteOrderService) TearDown() {
 	s.orderRepositoryMock = nil
 	s.itemRepositoryMock = nil
 	s.userRepositoryMock = nil
+	s.checkpointRepositoryMock = nil
 	s.payment = nil
 	s.orderService = nil
 }
@@ -116,6 +134,57 @@ func (s *suiteOrderService) TearDown() {
 // 	}
 // }
 
+func (s *suiteOrderService) TestCreateOrderUnverifiedUser() {
+	userId := uuid.New()
+	verifiedAt := time.Now()
+
+	testCase := []struct {
+		Name            string
+		FindUserByIdRes *model.User
+		FindUserByIdErr error
+		ExpectedErr     error
+	}{
+		{
+			Name:            "email not verified",
+			FindUserByIdRes: &model.User{ID: userId},
+			ExpectedErr:     customerrors.ErrEmailNotVerified,
+		},
+		{
+			Name:            "user not found",
+			FindUserByIdRes: &model.User{},
+			FindUserByIdErr: customerrors.ErrNotFound,
+			ExpectedErr:     customerrors.ErrNotFound,
+		},
+		{
+			Name:            "verified user continue to validate item",
+			FindUserByIdRes: &model.User{ID: userId, VerifiedAt: &verifiedAt},
+			ExpectedErr:     customerrors.ErrQtyOrder,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+			s.userRepositoryMock.On("FindUserByID").Return(v.FindUserByIdRes, v.FindUserByIdErr)
+			s.itemRepositoryMock.On("FindItemById").Return(nil)
+			s.itemRepositoryMock.On("FindCheckpointStocks").Return([]model.CheckpointStock{}, nil)
+			body := dto.OrderRequest{
+				CheckpointID: uuid.New().String(),
+				Order: dto.OrderDetailsRequest{
+					{ItemID: 1, Qty: 1},
+				},
+			}
+
+			var ctx context.Context
+			_, err := s.orderService.CreateOrder(body, userId.String(), ctx)
+
+			s.Equal(v.ExpectedErr, err)
+			s.orderRepositoryMock.AssertNotCalled(t, "CreateOrder")
+
+			s.TearDown()
+		})
+	}
+}
+
 func (s *suiteOrderService) TestFindOrder() {
 	orderId := uuid.New()
 	userId := uuid.New()
@@ -176,10 +245,10 @@ func (s *suiteOrderService) TestFindOrder() {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.SetupSuit()
 
-			s.orderRepositoryMock.On("FindOrder").Return(v.FindOrderRes, v.FindOrderErr)
+			s.orderRepositoryMock.On("FindOrder").Return(v.FindOrderRes, int64(len(v.FindOrderRes)), v.FindOrderErr)
 
 			var ctx context.Context
-			res, err := s.orderService.FindOrder(v.UserId, ctx)
+			res, _, err := s.orderService.FindOrder(v.UserId, query.Options{Page: 1, Limit: 20}, ctx)
 
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(v.ExpectedRes, res)
@@ -300,10 +369,585 @@ func (s *suiteOrderService) TestFindAllOrders() {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.SetupSuit()
 
-			s.orderRepositoryMock.On("FindAllOrders").Return(v.FindAllOrdersRes, v.FindAllOrdersErr)
+			s.orderRepositoryMock.On("FindAllOrders").Return(v.FindAllOrdersRes, int64(len(v.FindAllOrdersRes)), v.FindAllOrdersErr)
 
 			var ctx context.Context
-			res, err := s.orderService.FindAllOrders(ctx)
+			res, page, err := s.orderService.FindAllOrders(query.Options{Page: 1, Limit: 20}, ctx)
+
+			s.Equal(v.ExpectedErr, err)
+			s.Equal(v.ExpectedRes, res)
+			if v.ExpectedErr == nil {
+				s.Equal(&query.Page{Page: 1, Limit: 20, Total: int64(len(v.FindAllOrdersRes)), TotalPages: 1}, page)
+			}
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteOrderService) TestCencelOder() {
+	orderId := uuid.New()
+	userId := uuid.New()
+
+	testCase := []struct {
+		Name               string
+		OrderId            string
+		IsAdmin            bool
+		ExpectedErr        error
+		FindOrderErr       error
+		Status             uint
+		OrderOwner         uuid.UUID
+		UpdateStatusErr    error
+		ExpectUpdateCalled bool
+		ExpectedRefund     bool
+	}{
+		{
+			Name:               "cencel pending order",
+			OrderId:            orderId.String(),
+			ExpectedErr:        nil,
+			Status:             constants.Pending_status_order_id,
+			ExpectUpdateCalled: true,
+		},
+		{
+			Name:               "admin cencel paid order with refund",
+			OrderId:            orderId.String(),
+			IsAdmin:            true,
+			ExpectedErr:        nil,
+			Status:             constants.Waiting_status_order_id,
+			ExpectUpdateCalled: true,
+			ExpectedRefund:     true,
+		},
+		{
+			Name:               "cant cencel paid order",
+			OrderId:            orderId.String(),
+			ExpectedErr:        &statemachine.IllegalTransitionError{From: constants.Waiting_status_order_id, To: constants.Cencel_status_order_id},
+			Status:             constants.Waiting_status_order_id,
+			ExpectUpdateCalled: false,
+		},
+		{
+			Name:               "cant cencel other user order",
+			OrderId:            orderId.String(),
+			ExpectedErr:        customerrors.ErrNotFound,
+			Status:             constants.Pending_status_order_id,
+			OrderOwner:         uuid.New(),
+			ExpectUpdateCalled: false,
+		},
+		{
+			Name:               "admin cencel other user order",
+			OrderId:            orderId.String(),
+			IsAdmin:            true,
+			ExpectedErr:        nil,
+			Status:             constants.Pending_status_order_id,
+			OrderOwner:         uuid.New(),
+			ExpectUpdateCalled: true,
+		},
+		{
+			Name:               "invalid order id",
+			OrderId:            "abc",
+			ExpectedErr:        customerrors.ErrInvalidId,
+			ExpectUpdateCalled: false,
+		},
+		{
+			Name:               "order not found",
+			OrderId:            orderId.String(),
+			ExpectedErr:        customerrors.ErrNotFound,
+			FindOrderErr:       customerrors.ErrNotFound,
+			ExpectUpdateCalled: false,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			s.orderRepositoryMock.On("FindOrderById", mock.Anything).Return(v.FindOrderErr).Run(func(args mock.Arguments) {
+				order := args.Get(0).(*model.Order)
+				order.StatusOrderID = v.Status
+				order.GrandTotal = 10000
+				order.UserID = userId
+				if v.OrderOwner != uuid.Nil {
+					order.UserID = v.OrderOwner
+				}
+			})
+			s.orderRepositoryMock.On("UpdateStatusOrder", mock.Anything, mock.Anything).Return(v.UpdateStatusErr)
+
+			err := s.orderService.CencelOder(v.OrderId, userId.String(), v.IsAdmin, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			if v.ExpectUpdateCalled {
+				source := constants.Order_source_user_api
+				if v.IsAdmin {
+					source = constants.Order_source_admin_api
+				}
+				s.orderRepositoryMock.AssertCalled(t, "UpdateStatusOrder", mock.Anything, mock.MatchedBy(func(change *or.StatusChange) bool {
+					return change.From == v.Status && change.RestoreStock && (change.Refund != nil) == v.ExpectedRefund &&
+						*change.History.ActorID == userId && change.History.Source == source
+				}))
+			} else {
+				s.orderRepositoryMock.AssertNotCalled(t, "UpdateStatusOrder", mock.Anything, mock.Anything)
+			}
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteOrderService) TestSetOrderStatus() {
+	orderId := uuid.New()
+
+	testCase := []struct {
+		Name                 string
+		TransactionStatus    string
+		Status               uint
+		ExpectedErr          error
+		ExpectedRestoreStock bool
+		ExpectUpdateCalled   bool
+	}{
+		{
+			Name:               "settlement move pending to waiting",
+			TransactionStatus:  "settlement",
+			Status:             constants.Pending_status_order_id,
+			ExpectedErr:        nil,
+			ExpectUpdateCalled: true,
+		},
+		{
+			Name:               "settlement after capture not change order",
+			TransactionStatus:  "settlement",
+			Status:             constants.Waiting_status_order_id,
+			ExpectedErr:        nil,
+			ExpectUpdateCalled: false,
+		},
+		{
+			Name:               "settlement after cencel refund order",
+			TransactionStatus:  "settlement",
+			Status:             constants.Cencel_status_order_id,
+			ExpectedErr:        nil,
+			ExpectUpdateCalled: true,
+		},
+		{
+			Name:                 "expire cencel pending order",
+			TransactionStatus:    "expire",
+			Status:               constants.Pending_status_order_id,
+			ExpectedErr:          nil,
+			ExpectedRestoreStock: true,
+			ExpectUpdateCalled:   true,
+		},
+		{
+			Name:               "cant cencel collected order",
+			TransactionStatus:  "cancel",
+			Status:             constants.Success_status_order_id,
+			ExpectedErr:        &statemachine.IllegalTransitionError{From: constants.Success_status_order_id, To: constants.Cencel_status_order_id},
+			ExpectUpdateCalled: false,
+		},
+		{
+			Name:               "refund transaction complete refund",
+			TransactionStatus:  "refund",
+			Status:             constants.Refund_status_order_id,
+			ExpectedErr:        nil,
+			ExpectUpdateCalled: true,
+		},
+		{
+			Name:               "pending transaction not change order",
+			TransactionStatus:  "pending",
+			Status:             constants.Pending_status_order_id,
+			ExpectedErr:        nil,
+			ExpectUpdateCalled: false,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			s.orderRepositoryMock.On("FindOrderById", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				args.Get(0).(*model.Order).StatusOrderID = v.Status
+			})
+			s.orderRepositoryMock.On("UpdateStatusOrder", mock.Anything, mock.Anything).Return(nil)
+
+			err := s.orderService.SetOrderStatus(orderId, v.TransactionStatus, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			if v.ExpectUpdateCalled {
+				s.orderRepositoryMock.AssertCalled(t, "UpdateStatusOrder", mock.Anything, mock.MatchedBy(func(change *or.StatusChange) bool {
+					return change.From == v.Status && change.RestoreStock == v.ExpectedRestoreStock &&
+						change.History.ActorID == nil && change.History.Source == constants.Order_source_payment_webhook
+				}))
+			} else {
+				s.orderRepositoryMock.AssertNotCalled(t, "UpdateStatusOrder", mock.Anything, mock.Anything)
+			}
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteOrderService) TestTakeOrder() {
+	staffId := uuid.New()
+	checkpointId := uuid.New()
+	token := pickup.Token{
+		OrderID:      uuid.New(),
+		CheckpointID: checkpointId,
+		Nonce:        [constants.Pickup_nonce_length]byte{1, 2, 3},
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+	expiredToken := token
+	expiredToken.ExpiresAt = time.Now().Add(-time.Minute)
+	otherSigner, _ := pickup.NewSigner("other-secret", clock.Clock{})
+	nonce := hex.EncodeToString(token.Nonce[:])
+	code := dto.TakeOrder{Code: testPickupSigner.Sign(token)}
+
+	testCase := []struct {
+		Name               string
+		Body               dto.TakeOrder
+		IsAdmin            bool
+		AssignedIds        []uuid.UUID
+		ExpectedErr        error
+		ExpectedSource     string
+		FindOrderErr       error
+		OrderCode          string
+		OrderStatus        uint
+		UpdateStatusErr    error
+		ExpectUpdateCalled bool
+	}{
+		{
+			Name:               "admin success take order",
+			Body:               code,
+			IsAdmin:            true,
+			ExpectedErr:        nil,
+			ExpectedSource:     constants.Order_source_admin_api,
+			OrderCode:          nonce,
+			OrderStatus:        constants.Ready_status_order_id,
+			ExpectUpdateCalled: true,
+		},
+		{
+			Name:               "operator success take order in assigned checkpoint",
+			Body:               code,
+			AssignedIds:        []uuid.UUID{uuid.New(), checkpointId},
+			ExpectedErr:        nil,
+			ExpectedSource:     constants.Order_source_operator_api,
+			OrderCode:          nonce,
+			OrderStatus:        constants.Ready_status_order_id,
+			ExpectUpdateCalled: true,
+		},
+		{
+			Name:        "operator take order of other checkpoint",
+			Body:        code,
+			AssignedIds: []uuid.UUID{uuid.New()},
+			ExpectedErr: customerrors.ErrWrongCheckpoint,
+		},
+		{
+			Name:        "forged code",
+			Body:        dto.TakeOrder{Code: otherSigner.Sign(token)},
+			IsAdmin:     true,
+			ExpectedErr: customerrors.ErrOrderCode,
+		},
+		{
+			Name:        "expired code",
+			Body:        dto.TakeOrder{Code: testPickupSigner.Sign(expiredToken)},
+			IsAdmin:     true,
+			ExpectedErr: customerrors.ErrCodeExpired,
+		},
+		{
+			Name:         "order not found",
+			Body:         code,
+			IsAdmin:      true,
+			ExpectedErr:  customerrors.ErrNotFound,
+			FindOrderErr: customerrors.ErrNotFound,
+		},
+		{
+			Name:        "code already used",
+			Body:        code,
+			IsAdmin:     true,
+			ExpectedErr: customerrors.ErrCodeUsed,
+			OrderCode:   "0",
+			OrderStatus: constants.Success_status_order_id,
+		},
+		{
+			Name:               "order taken by other scan in the meantime",
+			Body:               code,
+			IsAdmin:            true,
+			ExpectedErr:        customerrors.ErrCodeUsed,
+			ExpectedSource:     constants.Order_source_admin_api,
+			OrderCode:          nonce,
+			OrderStatus:        constants.Ready_status_order_id,
+			UpdateStatusErr:    customerrors.ErrUpdateStatusOrder,
+			ExpectUpdateCalled: true,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			s.checkpointRepositoryMock.On("FindOperatorCheckpointIds", staffId).Return(v.AssignedIds, nil)
+			s.orderRepositoryMock.On("FindOrderById", mock.Anything).Return(v.FindOrderErr).Run(func(args mock.Arguments) {
+				order := args.Get(0).(*model.Order)
+				order.Code = v.OrderCode
+				order.StatusOrderID = v.OrderStatus
+			})
+			s.orderRepositoryMock.On("UpdateStatusOrder", mock.Anything, mock.Anything).Return(v.UpdateStatusErr)
+
+			err := s.orderService.TakeOrder(v.Body, staffId.String(), v.IsAdmin, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			if v.IsAdmin {
+				s.checkpointRepositoryMock.AssertNotCalled(t, "FindOperatorCheckpointIds", mock.Anything)
+			}
+			if v.ExpectUpdateCalled {
+				s.orderRepositoryMock.AssertCalled(t, "UpdateStatusOrder", mock.MatchedBy(func(order *model.Order) bool {
+					return order.ID == token.OrderID
+				}), mock.MatchedBy(func(change *or.StatusChange) bool {
+					return change.From == constants.Ready_status_order_id && *change.History.ActorID == staffId &&
+						change.History.Source == v.ExpectedSource
+				}))
+			} else {
+				s.orderRepositoryMock.AssertNotCalled(t, "UpdateStatusOrder", mock.Anything, mock.Anything)
+			}
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteOrderService) TestOrderReady() {
+	staffId := uuid.New()
+	orderId := uuid.New()
+	checkpointId := uuid.New()
+
+	testCase := []struct {
+		Name               string
+		OrderId            string
+		IsAdmin            bool
+		AssignedIds        []uuid.UUID
+		AssignedErr        error
+		FindOrderErr       error
+		ExpectedErr        error
+		ExpectedSource     string
+		ExpectUpdateCalled bool
+	}{
+		{
+			Name:               "admin set order ready",
+			OrderId:            orderId.String(),
+			IsAdmin:            true,
+			ExpectedSource:     constants.Order_source_admin_api,
+			ExpectUpdateCalled: true,
+		},
+		{
+			Name:               "operator set order ready in assigned checkpoint",
+			OrderId:            orderId.String(),
+			AssignedIds:        []uuid.UUID{checkpointId},
+			ExpectedSource:     constants.Order_source_operator_api,
+			ExpectUpdateCalled: true,
+		},
+		{
+			Name:        "operator cant see order of other checkpoint",
+			OrderId:     orderId.String(),
+			AssignedIds: []uuid.UUID{uuid.New()},
+			ExpectedErr: customerrors.ErrNotFound,
+		},
+		{
+			Name:        "error find operator checkpoints",
+			OrderId:     orderId.String(),
+			AssignedErr: errors.New("internal error"),
+			ExpectedErr: errors.New("internal error"),
+		},
+		{
+			Name:        "invalid order id",
+			OrderId:     "invalid",
+			IsAdmin:     true,
+			ExpectedErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:         "order not found",
+			OrderId:      orderId.String(),
+			IsAdmin:      true,
+			FindOrderErr: customerrors.ErrNotFound,
+			ExpectedErr:  customerrors.ErrNotFound,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			s.checkpointRepositoryMock.On("FindOperatorCheckpointIds", staffId).Return(v.AssignedIds, v.AssignedErr)
+			s.orderRepositoryMock.On("FindOrderById", mock.Anything).Return(v.FindOrderErr).Run(func(args mock.Arguments) {
+				order := args.Get(0).(*model.Order)
+				order.CheckpointID = checkpointId
+				order.StatusOrderID = constants.Waiting_status_order_id
+			})
+			s.orderRepositoryMock.On("UpdateStatusOrder", mock.Anything, mock.Anything).Return(nil)
+
+			err := s.orderService.OrderReady(v.OrderId, staffId.String(), v.IsAdmin, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			if v.ExpectUpdateCalled {
+				s.orderRepositoryMock.AssertCalled(t, "UpdateStatusOrder", mock.Anything, mock.MatchedBy(func(change *or.StatusChange) bool {
+					return change.From == constants.Waiting_status_order_id && change.History.Source == v.ExpectedSource
+				}))
+			} else {
+				s.orderRepositoryMock.AssertNotCalled(t, "UpdateStatusOrder", mock.Anything, mock.Anything)
+			}
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteOrderService) TestFindCheckpointOrders() {
+	staffId := uuid.New()
+	checkpointIds := []uuid.UUID{uuid.New()}
+	orders := []model.Order{
+		{
+			ID: uuid.New(),
+			Checkpoint: model.Checkpoint{
+				Name: "checkpoint",
+			},
+		},
+	}
+	var expected dto.OrdersResponse
+	expected.FromModel(orders)
+
+	testCase := []struct {
+		Name          string
+		IsAdmin       bool
+		ExpectedErr   error
+		ExpectedRes   dto.OrdersResponse
+		FindOrdersErr error
+	}{
+		{
+			Name:        "admin see all orders",
+			IsAdmin:     true,
+			ExpectedRes: expected,
+		},
+		{
+			Name:        "operator see orders of assigned checkpoints",
+			ExpectedRes: expected,
+		},
+		{
+			Name:          "error find orders",
+			ExpectedErr:   errors.New("internal error"),
+			ExpectedRes:   dto.OrdersResponse(nil),
+			FindOrdersErr: errors.New("internal error"),
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			s.orderRepositoryMock.On("FindAllOrders").Return(orders, int64(len(orders)), nil)
+			s.checkpointRepositoryMock.On("FindOperatorCheckpointIds", staffId).Return(checkpointIds, nil)
+			s.orderRepositoryMock.On("FindOrdersByCheckpoints", checkpointIds).Return(orders, int64(len(orders)), v.FindOrdersErr)
+
+			res, page, err := s.orderService.FindCheckpointOrders(staffId.String(), v.IsAdmin, query.Options{Page: 1, Limit: 20}, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			s.Equal(v.ExpectedRes, res)
+			if v.ExpectedErr == nil {
+				s.Equal(&query.Page{Page: 1, Limit: 20, Total: 1, TotalPages: 1}, page)
+			}
+			if v.IsAdmin {
+				s.orderRepositoryMock.AssertNotCalled(t, "FindOrdersByCheckpoints", mock.Anything)
+			} else {
+				s.orderRepositoryMock.AssertNotCalled(t, "FindAllOrders")
+			}
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteOrderService) TestFindOrderHistory() {
+	orderId := uuid.New()
+	ownerId := uuid.New()
+	otherUserId := uuid.New()
+	histories := []model.OrderStatusHistory{
+		{
+			OrderID:    orderId,
+			FromStatus: model.StatusOrder{Name: "pending"},
+			ToStatus:   model.StatusOrder{Name: "waiting"},
+			Source:     constants.Order_source_payment_webhook,
+		},
+	}
+
+	testCase := []struct {
+		Name           string
+		UserId         string
+		OrderId        string
+		IsAdmin        bool
+		ExpectedErr    error
+		ExpectedRes    dto.OrderStatusHistoriesResponse
+		FindOrderErr   error
+		FindHistoryErr error
+	}{
+		{
+			Name:        "owner get history",
+			UserId:      ownerId.String(),
+			OrderId:     orderId.String(),
+			ExpectedErr: nil,
+			ExpectedRes: dto.OrderStatusHistoriesResponse{
+				{
+					FromStatus: "pending",
+					ToStatus:   "waiting",
+					Source:     constants.Order_source_payment_webhook,
+				},
+			},
+		},
+		{
+			Name:        "admin get other user history",
+			UserId:      otherUserId.String(),
+			OrderId:     orderId.String(),
+			IsAdmin:     true,
+			ExpectedErr: nil,
+			ExpectedRes: dto.OrderStatusHistoriesResponse{
+				{
+					FromStatus: "pending",
+					ToStatus:   "waiting",
+					Source:     constants.Order_source_payment_webhook,
+				},
+			},
+		},
+		{
+			Name:        "other user cant get history",
+			UserId:      otherUserId.String(),
+			OrderId:     orderId.String(),
+			ExpectedErr: customerrors.ErrNotFound,
+			ExpectedRes: nil,
+		},
+		{
+			Name:        "invalid order id",
+			UserId:      ownerId.String(),
+			OrderId:     "abc",
+			ExpectedErr: customerrors.ErrInvalidId,
+			ExpectedRes: nil,
+		},
+		{
+			Name:         "order not found",
+			UserId:       ownerId.String(),
+			OrderId:      orderId.String(),
+			ExpectedErr:  customerrors.ErrNotFound,
+			ExpectedRes:  nil,
+			FindOrderErr: customerrors.ErrNotFound,
+		},
+		{
+			Name:           "error find history",
+			UserId:         ownerId.String(),
+			OrderId:        orderId.String(),
+			ExpectedErr:    errors.New("internal error"),
+			ExpectedRes:    nil,
+			FindHistoryErr: errors.New("internal error"),
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			s.orderRepositoryMock.On("FindOrderById", mock.Anything).Return(v.FindOrderErr).Run(func(args mock.Arguments) {
+				args.Get(0).(*model.Order).UserID = ownerId
+			})
+			if v.FindHistoryErr != nil {
+				s.orderRepositoryMock.On("FindOrderHistory", orderId).Return([]model.OrderStatusHistory(nil), v.FindHistoryErr)
+			} else {
+				s.orderRepositoryMock.On("FindOrderHistory", orderId).Return(histories, nil)
+			}
+
+			res, err := s.orderService.FindOrderHistory(v.UserId, v.OrderId, v.IsAdmin, context.Background())
 
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(v.ExpectedRes, res)
@@ -313,6 +957,301 @@ func (s *suiteOrderService) TestFindAllOrders() {
 	}
 }
 
+// foundItem fill found item with unit and variants, mock cant change the argument
+type foundItem struct {
+	*itemRepositoryMock.ItemRepositoryMock
+	item model.Item
+}
+
+func (r *foundItem) FindItemById(item *model.Item, ctx context.Context) error {
+	err := r.ItemRepositoryMock.FindItemById(item, ctx)
+	if err == nil {
+		*item = r.item
+	}
+	return err
+}
+
+func (s *suiteOrderService) TestPriceOrderDetail() {
+	kg := model.Unit{ID: constants.Unit_kg_id, Symbol: "kg", Step: 0.25}
+	item := model.Item{ID: 1, Unit: kg, Qty: 2, Price: 10000}
+	itemWithVariant := model.Item{ID: 1, Unit: kg, Qty: 2, Price: 10000, Variants: []model.ItemVariant{
+		{ID: 7, ItemID: 1, Name: "organik", Qty: 0.5, Price: 18000},
+	}}
+	// total stock is bigger, the rest is in other checkpoint
+	stocks := []model.CheckpointStock{{ItemID: 1, Qty: 1.5}, {ItemID: 1, VariantID: 7, Qty: 0.5}}
+
+	testCase := []struct {
+		Name        string
+		Item        model.Item
+		Body        dto.OrderDetailRequest
+		FindItemErr error
+		Stocks      []model.CheckpointStock
+		ExpectedErr error
+		ExpectedRes dto.OrderDetailRequest
+	}{
+		{
+			Name:        "fractional qty",
+			Item:        item,
+			Body:        dto.OrderDetailRequest{ItemID: 1, Qty: 0.75},
+			ExpectedRes: dto.OrderDetailRequest{ItemID: 1, Qty: 0.75, Price: 10000, Total: 7500, Unit: "kg"},
+		},
+		{
+			Name:        "qty not multiple of unit step",
+			Item:        item,
+			Body:        dto.OrderDetailRequest{ItemID: 1, Qty: 0.3},
+			ExpectedErr: customerrors.ErrQtyUnit,
+		},
+		{
+			Name:        "qty exceeds stock",
+			Item:        item,
+			Body:        dto.OrderDetailRequest{ItemID: 1, Qty: 2.25},
+			ExpectedErr: customerrors.ErrQtyOrder,
+		},
+		{
+			Name:        "qty exceeds stock of checkpoint",
+			Item:        item,
+			Body:        dto.OrderDetailRequest{ItemID: 1, Qty: 1.75},
+			ExpectedErr: customerrors.ErrQtyOrder,
+		},
+		{
+			Name:        "no stock in checkpoint",
+			Item:        item,
+			Body:        dto.OrderDetailRequest{ItemID: 1, Qty: 0.25},
+			Stocks:      []model.CheckpointStock{},
+			ExpectedErr: customerrors.ErrQtyOrder,
+		},
+		{
+			Name:        "variant price",
+			Item:        itemWithVariant,
+			Body:        dto.OrderDetailRequest{ItemID: 1, VariantID: 7, Qty: 0.25},
+			ExpectedRes: dto.OrderDetailRequest{ItemID: 1, VariantID: 7, Qty: 0.25, Price: 18000, Total: 4500, VariantName: "organik", Unit: "kg"},
+		},
+		{
+			Name:        "variant stock",
+			Item:        itemWithVariant,
+			Body:        dto.OrderDetailRequest{ItemID: 1, VariantID: 7, Qty: 1},
+			ExpectedErr: customerrors.ErrQtyOrder,
+		},
+		{
+			Name:        "variant required",
+			Item:        itemWithVariant,
+			Body:        dto.OrderDetailRequest{ItemID: 1, Qty: 0.25},
+			ExpectedErr: customerrors.ErrVariantRequired,
+		},
+		{
+			Name:        "variant of other item",
+			Item:        itemWithVariant,
+			Body:        dto.OrderDetailRequest{ItemID: 1, VariantID: 8, Qty: 0.25},
+			ExpectedErr: customerrors.ErrInvalidVariant,
+		},
+		{
+			Name:        "archived item",
+			Body:        dto.OrderDetailRequest{ItemID: 1, Qty: 1},
+			FindItemErr: customerrors.ErrNotFound,
+			ExpectedErr: customerrors.ErrItemUnavailable,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+			s.itemRepositoryMock.On("FindItemById").Return(v.FindItemErr)
+			if v.Stocks == nil {
+				v.Stocks = stocks
+			}
+			s.itemRepositoryMock.On("FindCheckpointStocks").Return(v.Stocks, nil)
+			service := &orderServiceImpl{itemRepo: &foundItem{ItemRepositoryMock: s.itemRepositoryMock, item: v.Item}}
+
+			body := v.Body
+			err := service.priceOrderDetail(&body, uuid.New(), context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			if v.ExpectedErr == nil {
+				s.Equal(v.ExpectedRes, body)
+			}
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteOrderService) TestFindCart() {
+	kg := model.Unit{ID: constants.Unit_kg_id, Symbol: "kg", Step: 0.25}
+	item := model.Item{ID: 1, Name: "bayam", Unit: kg, Qty: 2, Price: 10000}
+	checkpointId := uuid.New()
+	lines := []model.CartLine{{ID: 1, ItemID: 1, Qty: 0.5}, {ID: 2, ItemID: 1, Qty: 1}}
+
+	testCase := []struct {
+		Name        string
+		Checkpoint  *uuid.UUID
+		Lines       []model.CartLine
+		FindItemErr error
+		Stocks      []model.CheckpointStock
+		ExpectedRes dto.CartResponse
+	}{
+		{
+			Name:  "without checkpoint stock is not checked",
+			Lines: lines[:1],
+			ExpectedRes: dto.CartResponse{TotalPrice: 5000, Lines: dto.CartLinesResponse{
+				{ID: 1, ItemID: 1, ItemName: "bayam", Unit: "kg", Qty: 0.5, Price: 10000, Total: 5000},
+			}},
+		},
+		{
+			Name:       "stock left in checkpoint",
+			Checkpoint: &checkpointId,
+			Lines:      lines,
+			Stocks:     []model.CheckpointStock{{ItemID: 1, Qty: 0.75}},
+			ExpectedRes: dto.CartResponse{CheckpointID: &checkpointId, TotalPrice: 5000, Lines: dto.CartLinesResponse{
+				{ID: 1, ItemID: 1, ItemName: "bayam", Unit: "kg", Qty: 0.5, Price: 10000, Total: 5000, Available: floatPtr(0.75)},
+				{ID: 2, ItemID: 1, ItemName: "bayam", Unit: "kg", Qty: 1, Price: 10000, Total: 10000, Available: floatPtr(0.75),
+					Warning: customerrors.ErrCartStockNotEnough.Error()},
+			}},
+		},
+		{
+			Name:       "out of stock in checkpoint",
+			Checkpoint: &checkpointId,
+			Lines:      lines[:1],
+			Stocks:     []model.CheckpointStock{},
+			ExpectedRes: dto.CartResponse{CheckpointID: &checkpointId, Lines: dto.CartLinesResponse{
+				{ID: 1, ItemID: 1, ItemName: "bayam", Unit: "kg", Qty: 0.5, Price: 10000, Total: 5000, Available: floatPtr(0),
+					Warning: customerrors.ErrCartOutOfStock.Error()},
+			}},
+		},
+		{
+			Name:        "archived item",
+			Checkpoint:  &checkpointId,
+			Lines:       lines[:1],
+			FindItemErr: customerrors.ErrNotFound,
+			ExpectedRes: dto.CartResponse{CheckpointID: &checkpointId, Lines: dto.CartLinesResponse{
+				{ID: 1, ItemID: 1, Qty: 0.5, Warning: customerrors.ErrItemUnavailable.Error()},
+			}},
+		},
+		{
+			Name:       "price changed since added",
+			Checkpoint: &checkpointId,
+			Lines:      []model.CartLine{{ID: 1, ItemID: 1, Qty: 0.5, Price: 8000}},
+			Stocks:     []model.CheckpointStock{{ItemID: 1, Qty: 2}},
+			ExpectedRes: dto.CartResponse{CheckpointID: &checkpointId, Lines: dto.CartLinesResponse{
+				{ID: 1, ItemID: 1, ItemName: "bayam", Unit: "kg", Qty: 0.5, Price: 10000, AddedPrice: 8000, Total: 5000, Available: floatPtr(2),
+					Warning: customerrors.ErrCartPriceChanged.Error()},
+			}},
+		},
+		{
+			Name:       "ready to checkout",
+			Checkpoint: &checkpointId,
+			Lines:      []model.CartLine{{ID: 1, ItemID: 1, Qty: 0.5, Price: 10000}},
+			Stocks:     []model.CheckpointStock{{ItemID: 1, Qty: 2}},
+			ExpectedRes: dto.CartResponse{CheckpointID: &checkpointId, TotalPrice: 5000, CanCheckout: true, Lines: dto.CartLinesResponse{
+				{ID: 1, ItemID: 1, ItemName: "bayam", Unit: "kg", Qty: 0.5, Price: 10000, AddedPrice: 10000, Total: 5000, Available: floatPtr(2)},
+			}},
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+			cartRepo := new(orderRepositoryMock.CartRepositoryMock)
+			cartRepo.On("FindCart", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				cart := args.Get(0).(*model.Cart)
+				cart.CheckpointID = v.Checkpoint
+				cart.Lines = v.Lines
+			})
+			s.itemRepositoryMock.On("FindItemById").Return(v.FindItemErr)
+			s.itemRepositoryMock.On("FindCheckpointStocks").Return(v.Stocks, nil)
+			service := &orderServiceImpl{cartRepo: cartRepo, itemRepo: &foundItem{ItemRepositoryMock: s.itemRepositoryMock, item: item}}
+
+			res, err := service.FindCart(uuid.New().String(), context.Background())
+
+			s.NoError(err)
+			s.Equal(v.ExpectedRes, *res)
+
+			s.TearDown()
+		})
+	}
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func (s *suiteOrderService) TestCheckoutCart() {
+	checkpointId := uuid.New()
+	kg := model.Unit{ID: constants.Unit_kg_id, Symbol: "kg", Step: 0.25}
+	item := model.Item{ID: 1, Name: "bayam", Unit: kg, Qty: 2, Price: 10000}
+	now := time.Now()
+
+	testCase := []struct {
+		Name           string
+		UserId         string
+		Cart           model.Cart
+		CreateOrderErr error
+		ExpectedErr    error
+	}{
+		{
+			Name:        "invalid user id",
+			UserId:      "abc",
+			ExpectedErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:        "empty cart",
+			UserId:      uuid.New().String(),
+			Cart:        model.Cart{CheckpointID: &checkpointId},
+			ExpectedErr: customerrors.ErrCartEmpty,
+		},
+		{
+			Name:        "checkpoint not chosen",
+			UserId:      uuid.New().String(),
+			Cart:        model.Cart{Lines: []model.CartLine{{ID: 1, ItemID: 1, Qty: 1}}},
+			ExpectedErr: customerrors.ErrCartCheckpoint,
+		},
+		{
+			Name:        "price changed since added",
+			UserId:      uuid.New().String(),
+			Cart:        model.Cart{CheckpointID: &checkpointId, Lines: []model.CartLine{{ID: 1, ItemID: 1, Qty: 1, Price: 8000}}},
+			ExpectedErr: customerrors.ErrCartPriceChanged,
+		},
+		{
+			Name:           "cart changed during checkout",
+			UserId:         uuid.New().String(),
+			Cart:           model.Cart{CheckpointID: &checkpointId, Lines: []model.CartLine{{ID: 1, ItemID: 1, Qty: 1, Price: 10000}}},
+			CreateOrderErr: customerrors.ErrCartChanged,
+			ExpectedErr:    customerrors.ErrCartChanged,
+		},
+		{
+			Name:   "success checkout",
+			UserId: uuid.New().String(),
+			Cart:   model.Cart{CheckpointID: &checkpointId, Lines: []model.CartLine{{ID: 1, ItemID: 1, Qty: 1, Price: 10000}}},
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+			cartRepo := new(orderRepositoryMock.CartRepositoryMock)
+			cartRepo.On("FindCart", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				cart := args.Get(0).(*model.Cart)
+				cart.CheckpointID = v.Cart.CheckpointID
+				cart.Lines = v.Cart.Lines
+			})
+			s.userRepositoryMock.On("FindUserByID").Return(&model.User{VerifiedAt: &now}, nil)
+			s.itemRepositoryMock.On("FindItemById").Return(nil)
+			s.itemRepositoryMock.On("FindCheckpointStocks").Return([]model.CheckpointStock{{ItemID: 1, Qty: 2}}, nil)
+			s.orderRepositoryMock.On("CreateCartOrder").Return(v.CreateOrderErr)
+			s.payment.On("NewTransaction").Return("http://payment", nil)
+			s.orderService.(*orderServiceImpl).cartRepo = cartRepo
+			s.orderService.(*orderServiceImpl).itemRepo = &foundItem{ItemRepositoryMock: s.itemRepositoryMock, item: item}
+
+			_, err := s.orderService.CheckoutCart(v.UserId, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			if v.ExpectedErr == customerrors.ErrCartPriceChanged {
+				s.orderRepositoryMock.AssertNotCalled(t, "CreateCartOrder")
+			}
+			// lines are removed with the order, never after it
+			cartRepo.AssertNotCalled(t, "RemoveCartLines", mock.Anything, mock.Anything)
+
+			s.TearDown()
+		})
+	}
+}
+
 func TestSuiteOrderService(t *testing.T) {
 	suite.Run(t, new(suiteOrderService))
 }
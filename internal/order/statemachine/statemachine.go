@@ -0,0 +1,216 @@
+// Package statemachine hold every allowed move between order status and the side effect of each move.
+// Status order only change through StateMachine.Fire, there is no hidden gorm hook anymore.
+package statemachine
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	or "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/repository"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/pickup"
+)
+
+// Action is side effect run when order move to new status
+type Action int
+
+const (
+	// put back reserved item qty to stock
+	RestoreStock Action = iota + 1
+	// generate signed pickup token, pickup must be done before expired
+	GeneratePickupCode
+	// invalidate pickup token after order collected, so token only can be used once
+	ClearPickupCode
+	// create refund of paid transaction, amount follow refund policy of RefundReason.
+	// Nothing is refunded when policy amount is 0, order move straight to refund success
+	// because no refund will ever be confirmed by payment provider
+	RequestRefund
+	// mark refund success after payment provider confirm it
+	CompleteRefund
+)
+
+type Transition struct {
+	From         uint
+	To           uint
+	Actions      []Action
+	RefundReason string
+}
+
+// allowed transition, everything not listed here is illegal
+var transitions = []Transition{
+	{From: constants.Pending_status_order_id, To: constants.Waiting_status_order_id},
+	{From: constants.Pending_status_order_id, To: constants.Cencel_status_order_id, Actions: []Action{RestoreStock}},
+	{From: constants.Waiting_status_order_id, To: constants.Ready_status_order_id, Actions: []Action{GeneratePickupCode}},
+	{From: constants.Waiting_status_order_id, To: constants.Refund_status_order_id, Actions: []Action{RestoreStock, RequestRefund}, RefundReason: constants.Refund_reason_cancelled},
+	{From: constants.Ready_status_order_id, To: constants.Success_status_order_id, Actions: []Action{ClearPickupCode}},
+	{From: constants.Ready_status_order_id, To: constants.Refund_status_order_id, Actions: []Action{RequestRefund}, RefundReason: constants.Refund_reason_expired},
+	{From: constants.Refund_status_order_id, To: constants.Refund_success_status_order_id, Actions: []Action{CompleteRefund}},
+	// payment settled after order is cencelled, stock is already restored when it is cencelled
+	{From: constants.Cencel_status_order_id, To: constants.Refund_status_order_id, Actions: []Action{RequestRefund}, RefundReason: constants.Refund_reason_late_payment},
+}
+
+// IllegalTransitionError returned when transition not listed in state machine.
+// errors.Is(err, customerrors.ErrUpdateStatusOrder) is true for this error
+type IllegalTransitionError struct {
+	From uint
+	To   uint
+}
+
+func (e *IllegalTransitionError) Error() string {
+	return fmt.Sprintf("cant update status order from %s to %s", statusName(e.From), statusName(e.To))
+}
+
+func (e *IllegalTransitionError) Is(target error) bool {
+	return target == customerrors.ErrUpdateStatusOrder
+}
+
+// IsIllegalTransition report whether err is IllegalTransitionError
+func IsIllegalTransition(err error) bool {
+	var illegal *IllegalTransitionError
+	return errors.As(err, &illegal)
+}
+
+func statusName(id uint) string {
+	for _, status := range constants.StatusOrder {
+		if status.ID == id {
+			return status.Name
+		}
+	}
+	return fmt.Sprint(id)
+}
+
+// Find return transition from status to other status
+func Find(from, to uint) (*Transition, error) {
+	for i := range transitions {
+		if transitions[i].From == from && transitions[i].To == to {
+			return &transitions[i], nil
+		}
+	}
+	return nil, &IllegalTransitionError{From: from, To: to}
+}
+
+// Can report whether order can move from status to other status
+func Can(from, to uint) bool {
+	_, err := Find(from, to)
+	return err == nil
+}
+
+// Repository save status change, it must only update order when status still same with from
+// and save every side effect in same db transaction
+type Repository interface {
+	UpdateStatusOrder(order *model.Order, change *or.StatusChange, ctx context.Context) error
+}
+
+type RefundPolicy interface {
+	Amount(grandTotal int, reason string) int
+}
+
+type PickupSigner interface {
+	Sign(token pickup.Token) string
+}
+
+// Actor is who move the order status, UserID nil when moved by system
+type Actor struct {
+	UserID *uuid.UUID
+	Source string
+}
+
+type Clock interface {
+	Now() time.Time
+}
+
+type StateMachine struct {
+	repo   Repository
+	clock  Clock
+	policy RefundPolicy
+	signer PickupSigner
+}
+
+func NewStateMachine(repo Repository, clock Clock, policy RefundPolicy, signer PickupSigner) *StateMachine {
+	return &StateMachine{
+		repo:   repo,
+		clock:  clock,
+		policy: policy,
+		signer: signer,
+	}
+}
+
+// Fire move order to new status, run action of the transition and record it in status history
+func (m *StateMachine) Fire(order *model.Order, to uint, actor Actor, ctx context.Context) error {
+	from := order.StatusOrderID
+	transition, err := Find(from, to)
+	if err != nil {
+		return err
+	}
+	change := or.StatusChange{
+		From: from,
+		History: &model.OrderStatusHistory{
+			CreatedAt:    m.clock.Now(),
+			OrderID:      order.ID,
+			FromStatusID: &from,
+			ToStatusID:   to,
+			ActorID:      actor.UserID,
+			Source:       actor.Source,
+		},
+	}
+	for _, action := range transition.Actions {
+		switch action {
+		case RestoreStock:
+			change.RestoreStock = true
+		case GeneratePickupCode:
+			if err := m.generatePickupCode(order); err != nil {
+				return err
+			}
+		case ClearPickupCode:
+			order.Code = "0"
+			order.Hash = ""
+			order.ExpiredOrder = m.clock.Now()
+		case RequestRefund:
+			amount := m.policy.Amount(order.GrandTotal, transition.RefundReason)
+			if amount == 0 {
+				to = constants.Refund_success_status_order_id
+				change.History.ToStatusID = to
+				continue
+			}
+			change.Refund = &model.Refund{
+				OrderID:   order.ID,
+				RefundKey: uuid.New().String(),
+				Reason:    transition.RefundReason,
+				Amount:    amount,
+				Status:    constants.Refund_status_pending,
+			}
+		case CompleteRefund:
+			change.CompleteRefund = true
+		}
+	}
+	order.StatusOrderID = to
+	err = m.repo.UpdateStatusOrder(order, &change, ctx)
+	if err != nil {
+		order.StatusOrderID = from
+		return err
+	}
+	return nil
+}
+
+// generatePickupCode save token nonce as order code and signed token as order hash
+func (m *StateMachine) generatePickupCode(order *model.Order) error {
+	token := pickup.Token{
+		OrderID:      order.ID,
+		CheckpointID: order.CheckpointID,
+		ExpiresAt:    m.clock.Now().Add(constants.ExpPickupOrder),
+	}
+	if _, err := rand.Read(token.Nonce[:]); err != nil {
+		return err
+	}
+	order.Code = hex.EncodeToString(token.Nonce[:])
+	order.Hash = m.signer.Sign(token)
+	order.ExpiredOrder = token.ExpiresAt
+	return nil
+}
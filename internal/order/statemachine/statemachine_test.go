@@ -0,0 +1,405 @@
+package statemachine
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	or "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/repository"
+	orderRepositoryMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/repository/mock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	clockMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/clock/mock"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/payment"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/pickup"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type suiteStateMachine struct {
+	suite.Suite
+	orderRepositoryMock *orderRepositoryMock.OrderRepositoryMock
+	clockMock           *clockMock.ClockMock
+	signer              *pickup.Signer
+	machine             *StateMachine
+}
+
+func (s *suiteStateMachine) SetupSuit() {
+	s.orderRepositoryMock = new(orderRepositoryMock.OrderRepositoryMock)
+	s.clockMock = new(clockMock.ClockMock)
+	s.signer, _ = pickup.NewSigner("secret", s.clockMock)
+	s.machine = NewStateMachine(s.orderRepositoryMock, s.clockMock, payment.RefundPolicy{ExpiredPercent: 50, CancelledPercent: 100}, s.signer)
+}
+
+func (s *suiteStateMachine) TearDown() {
+	s.orderRepositoryMock = nil
+	s.clockMock = nil
+	s.signer = nil
+	s.machine = nil
+}
+
+func (s *suiteStateMachine) TestCan() {
+	testCase := []struct {
+		Name     string
+		From     uint
+		To       uint
+		Expected bool
+	}{
+		{
+			Name:     "pending to waiting",
+			From:     constants.Pending_status_order_id,
+			To:       constants.Waiting_status_order_id,
+			Expected: true,
+		},
+		{
+			Name:     "pending to cencel",
+			From:     constants.Pending_status_order_id,
+			To:       constants.Cencel_status_order_id,
+			Expected: true,
+		},
+		{
+			Name:     "waiting to ready",
+			From:     constants.Waiting_status_order_id,
+			To:       constants.Ready_status_order_id,
+			Expected: true,
+		},
+		{
+			Name:     "ready to success",
+			From:     constants.Ready_status_order_id,
+			To:       constants.Success_status_order_id,
+			Expected: true,
+		},
+		{
+			Name:     "ready to refund",
+			From:     constants.Ready_status_order_id,
+			To:       constants.Refund_status_order_id,
+			Expected: true,
+		},
+		{
+			Name:     "refund to refund success",
+			From:     constants.Refund_status_order_id,
+			To:       constants.Refund_success_status_order_id,
+			Expected: true,
+		},
+		{
+			Name:     "refund paid order",
+			From:     constants.Waiting_status_order_id,
+			To:       constants.Refund_status_order_id,
+			Expected: true,
+		},
+		{
+			Name:     "cant cencel paid order",
+			From:     constants.Waiting_status_order_id,
+			To:       constants.Cencel_status_order_id,
+			Expected: false,
+		},
+		{
+			Name:     "cant refund unpaid order",
+			From:     constants.Pending_status_order_id,
+			To:       constants.Refund_status_order_id,
+			Expected: false,
+		},
+		{
+			Name:     "cant cencel collected order",
+			From:     constants.Success_status_order_id,
+			To:       constants.Cencel_status_order_id,
+			Expected: false,
+		},
+		{
+			Name:     "cant take not ready order",
+			From:     constants.Waiting_status_order_id,
+			To:       constants.Success_status_order_id,
+			Expected: false,
+		},
+		{
+			Name:     "refund cenceled order paid late",
+			From:     constants.Cencel_status_order_id,
+			To:       constants.Refund_status_order_id,
+			Expected: true,
+		},
+		{
+			Name:     "cant reopen cenceled order",
+			From:     constants.Cencel_status_order_id,
+			To:       constants.Waiting_status_order_id,
+			Expected: false,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.Equal(v.Expected, Can(v.From, v.To))
+		})
+	}
+}
+
+func (s *suiteStateMachine) TestFire() {
+	now := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	userId := uuid.New()
+	actor := Actor{
+		UserID: &userId,
+		Source: constants.Order_source_user_api,
+	}
+
+	testCase := []struct {
+		Name                 string
+		From                 uint
+		To                   uint
+		ExpectedErr          error
+		ExpectedRestoreStock bool
+		ExpectedStatus       uint
+		UpdateStatusErr      error
+		ExpectUpdateCalled   bool
+	}{
+		{
+			Name:                 "cencel restore stock",
+			From:                 constants.Pending_status_order_id,
+			To:                   constants.Cencel_status_order_id,
+			ExpectedErr:          nil,
+			ExpectedRestoreStock: true,
+			ExpectedStatus:       constants.Cencel_status_order_id,
+			ExpectUpdateCalled:   true,
+		},
+		{
+			Name:                 "paid order waiting",
+			From:                 constants.Pending_status_order_id,
+			To:                   constants.Waiting_status_order_id,
+			ExpectedErr:          nil,
+			ExpectedRestoreStock: false,
+			ExpectedStatus:       constants.Waiting_status_order_id,
+			ExpectUpdateCalled:   true,
+		},
+		{
+			Name:               "illegal transition",
+			From:               constants.Success_status_order_id,
+			To:                 constants.Cencel_status_order_id,
+			ExpectedErr:        &IllegalTransitionError{From: constants.Success_status_order_id, To: constants.Cencel_status_order_id},
+			ExpectedStatus:     constants.Success_status_order_id,
+			ExpectUpdateCalled: false,
+		},
+		{
+			Name:                 "status changed by other request",
+			From:                 constants.Pending_status_order_id,
+			To:                   constants.Cencel_status_order_id,
+			ExpectedErr:          customerrors.ErrUpdateStatusOrder,
+			ExpectedRestoreStock: true,
+			ExpectedStatus:       constants.Pending_status_order_id,
+			UpdateStatusErr:      customerrors.ErrUpdateStatusOrder,
+			ExpectUpdateCalled:   true,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			order := model.Order{
+				ID:            uuid.New(),
+				StatusOrderID: v.From,
+			}
+			s.clockMock.On("Now").Return(now)
+			s.orderRepositoryMock.On("UpdateStatusOrder", &order, mock.Anything).Return(v.UpdateStatusErr)
+
+			err := s.machine.Fire(&order, v.To, actor, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			s.Equal(v.ExpectedStatus, order.StatusOrderID)
+			if v.ExpectUpdateCalled {
+				expectedChange := &or.StatusChange{
+					From:         v.From,
+					RestoreStock: v.ExpectedRestoreStock,
+					History: &model.OrderStatusHistory{
+						CreatedAt:    now,
+						OrderID:      order.ID,
+						FromStatusID: &v.From,
+						ToStatusID:   v.To,
+						ActorID:      &userId,
+						Source:       constants.Order_source_user_api,
+					},
+				}
+				s.orderRepositoryMock.AssertCalled(t, "UpdateStatusOrder", &order, expectedChange)
+			} else {
+				s.orderRepositoryMock.AssertNotCalled(t, "UpdateStatusOrder", mock.Anything, mock.Anything)
+			}
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteStateMachine) TestFireReadyGeneratePickupCode() {
+	s.SetupSuit()
+	now := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	order := model.Order{
+		ID:            uuid.New(),
+		CheckpointID:  uuid.New(),
+		StatusOrderID: constants.Waiting_status_order_id,
+	}
+	s.clockMock.On("Now").Return(now)
+	s.orderRepositoryMock.On("UpdateStatusOrder", &order, mock.Anything).Return(nil)
+
+	err := s.machine.Fire(&order, constants.Ready_status_order_id, Actor{Source: constants.Order_source_admin_api}, context.Background())
+
+	s.NoError(err)
+	s.Equal(uint(constants.Ready_status_order_id), order.StatusOrderID)
+	s.Len(order.Code, constants.Pickup_nonce_length*2)
+	s.Equal(now.Add(constants.ExpPickupOrder), order.ExpiredOrder)
+	token, err := s.signer.Verify(order.Hash)
+	s.NoError(err)
+	s.Equal(order.ID, token.OrderID)
+	s.Equal(order.CheckpointID, token.CheckpointID)
+	s.Equal(order.Code, hex.EncodeToString(token.Nonce[:]))
+	s.True(order.ExpiredOrder.Equal(token.ExpiresAt))
+
+	s.TearDown()
+}
+
+func (s *suiteStateMachine) TestFireSuccessClearPickupCode() {
+	s.SetupSuit()
+	now := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	order := model.Order{
+		ID:            uuid.New(),
+		StatusOrderID: constants.Ready_status_order_id,
+		Code:          "123456",
+		Hash:          "token",
+		ExpiredOrder:  now.Add(time.Hour),
+	}
+	s.clockMock.On("Now").Return(now)
+	s.orderRepositoryMock.On("UpdateStatusOrder", &order, mock.Anything).Return(nil)
+
+	err := s.machine.Fire(&order, constants.Success_status_order_id, Actor{Source: constants.Order_source_admin_api}, context.Background())
+
+	s.NoError(err)
+	s.Equal("0", order.Code)
+	s.Empty(order.Hash)
+	s.Equal(now, order.ExpiredOrder)
+
+	s.TearDown()
+}
+
+func (s *suiteStateMachine) TestFireRequestRefund() {
+	now := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	testCase := []struct {
+		Name                 string
+		From                 uint
+		ExpectedReason       string
+		ExpectedAmount       int
+		ExpectedRestoreStock bool
+	}{
+		{
+			Name:                 "cencelled paid order",
+			From:                 constants.Waiting_status_order_id,
+			ExpectedReason:       constants.Refund_reason_cancelled,
+			ExpectedAmount:       10001,
+			ExpectedRestoreStock: true,
+		},
+		{
+			Name:                 "expired ready order",
+			From:                 constants.Ready_status_order_id,
+			ExpectedReason:       constants.Refund_reason_expired,
+			ExpectedAmount:       5000,
+			ExpectedRestoreStock: false,
+		},
+		{
+			Name:                 "cencelled order paid late",
+			From:                 constants.Cencel_status_order_id,
+			ExpectedReason:       constants.Refund_reason_late_payment,
+			ExpectedAmount:       10001,
+			ExpectedRestoreStock: false,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			order := model.Order{
+				ID:            uuid.New(),
+				StatusOrderID: v.From,
+				GrandTotal:    10001,
+			}
+			var change *or.StatusChange
+			s.clockMock.On("Now").Return(now)
+			s.orderRepositoryMock.On("UpdateStatusOrder", &order, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				change = args.Get(1).(*or.StatusChange)
+			})
+
+			err := s.machine.Fire(&order, constants.Refund_status_order_id, Actor{Source: constants.Order_source_admin_api}, context.Background())
+
+			s.NoError(err)
+			s.Equal(uint(constants.Refund_status_order_id), order.StatusOrderID)
+			s.Equal(v.ExpectedRestoreStock, change.RestoreStock)
+			s.False(change.CompleteRefund)
+			s.Equal(order.ID, change.Refund.OrderID)
+			s.Equal(v.ExpectedReason, change.Refund.Reason)
+			s.Equal(v.ExpectedAmount, change.Refund.Amount)
+			s.Equal(constants.Refund_status_pending, change.Refund.Status)
+			s.NotEmpty(change.Refund.RefundKey)
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteStateMachine) TestFireZeroRefund() {
+	s.SetupSuit()
+	s.machine = NewStateMachine(s.orderRepositoryMock, s.clockMock, payment.RefundPolicy{ExpiredPercent: 0, CancelledPercent: 100}, s.signer)
+
+	order := model.Order{
+		ID:            uuid.New(),
+		StatusOrderID: constants.Ready_status_order_id,
+		GrandTotal:    10000,
+	}
+	var change *or.StatusChange
+	s.clockMock.On("Now").Return(time.Now())
+	s.orderRepositoryMock.On("UpdateStatusOrder", &order, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		change = args.Get(1).(*or.StatusChange)
+	})
+
+	err := s.machine.Fire(&order, constants.Refund_status_order_id, Actor{Source: constants.Order_source_expiry_worker}, context.Background())
+
+	// nothing to wait from payment provider, refund is done
+	s.NoError(err)
+	s.Equal(uint(constants.Refund_success_status_order_id), order.StatusOrderID)
+	s.Equal(uint(constants.Refund_success_status_order_id), change.History.ToStatusID)
+	s.Equal(uint(constants.Ready_status_order_id), *change.History.FromStatusID)
+	s.Nil(change.Refund)
+	s.False(change.CompleteRefund)
+
+	s.TearDown()
+}
+
+func (s *suiteStateMachine) TestFireCompleteRefund() {
+	s.SetupSuit()
+	now := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	order := model.Order{
+		ID:            uuid.New(),
+		StatusOrderID: constants.Refund_status_order_id,
+	}
+	var change *or.StatusChange
+	s.clockMock.On("Now").Return(now)
+	s.orderRepositoryMock.On("UpdateStatusOrder", &order, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		change = args.Get(1).(*or.StatusChange)
+	})
+
+	err := s.machine.Fire(&order, constants.Refund_success_status_order_id, Actor{Source: constants.Order_source_payment_webhook}, context.Background())
+
+	s.NoError(err)
+	s.True(change.CompleteRefund)
+	s.Nil(change.Refund)
+
+	s.TearDown()
+}
+
+func (s *suiteStateMachine) TestIllegalTransitionError() {
+	err := error(&IllegalTransitionError{From: constants.Success_status_order_id, To: constants.Cencel_status_order_id})
+	s.Equal("cant update status order from success to cencel", err.Error())
+	s.True(errors.Is(err, customerrors.ErrUpdateStatusOrder))
+	s.True(IsIllegalTransition(err))
+	s.False(IsIllegalTransition(customerrors.ErrUpdateStatusOrder))
+}
+
+func TestSuiteStateMachine(t *testing.T) {
+	suite.Run(t, new(suiteStateMachine))
+}
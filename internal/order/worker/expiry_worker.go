@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	or "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/repository"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/statemachine"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+)
+
+var expiryActor = statemachine.Actor{Source: constants.Order_source_expiry_worker}
+
+type Clock interface {
+	Now() time.Time
+}
+
+type StateMachine interface {
+	Fire(order *model.Order, to uint, actor statemachine.Actor, ctx context.Context) error
+}
+
+type expiryWorker struct {
+	orderRepo or.OrderRepository
+	machine   StateMachine
+	clock     Clock
+	interval  time.Duration
+}
+
+func NewExpiryWorker(orRepository or.OrderRepository, machine StateMachine, clock Clock, interval time.Duration) *expiryWorker {
+	return &expiryWorker{
+		orderRepo: orRepository,
+		machine:   machine,
+		clock:     clock,
+		interval:  interval,
+	}
+}
+
+// Start run expiry check every interval until context is done
+func (w *expiryWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		if err := w.RunOnce(ctx); err != nil {
+			log.Println("expiry worker:", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce cencel expired pending orders and refund expired ready orders
+func (w *expiryWorker) RunOnce(ctx context.Context) error {
+	now := w.clock.Now()
+
+	pendingOrders, err := w.orderRepo.FindExpiredOrders(constants.Pending_status_order_id, now, ctx)
+	if err != nil {
+		return err
+	}
+	for i := range pendingOrders {
+		order := &pendingOrders[i]
+		err := w.machine.Fire(order, constants.Cencel_status_order_id, expiryActor, ctx)
+		// ErrUpdateStatusOrder mean order already handled by other replica or paid in the meantime
+		if err != nil && !errors.Is(err, customerrors.ErrUpdateStatusOrder) {
+			log.Println("expiry worker: cencel order", order.ID, err)
+		}
+	}
+
+	readyOrders, err := w.orderRepo.FindExpiredOrders(constants.Ready_status_order_id, now, ctx)
+	if err != nil {
+		return err
+	}
+	for i := range readyOrders {
+		order := &readyOrders[i]
+		err := w.machine.Fire(order, constants.Refund_status_order_id, expiryActor, ctx)
+		if err != nil && !errors.Is(err, customerrors.ErrUpdateStatusOrder) {
+			log.Println("expiry worker: refund order", order.ID, err)
+		}
+	}
+	return nil
+}
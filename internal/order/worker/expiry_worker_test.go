@@ -0,0 +1,186 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	or "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/repository"
+	orderRepositoryMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/repository/mock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/statemachine"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	clockMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/clock/mock"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/payment"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/pickup"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type suiteExpiryWorker struct {
+	suite.Suite
+	orderRepositoryMock *orderRepositoryMock.OrderRepositoryMock
+	clockMock           *clockMock.ClockMock
+	worker              *expiryWorker
+}
+
+func (s *suiteExpiryWorker) SetupSuit() {
+	s.orderRepositoryMock = new(orderRepositoryMock.OrderRepositoryMock)
+	s.clockMock = new(clockMock.ClockMock)
+	signer, _ := pickup.NewSigner("secret", s.clockMock)
+	machine := statemachine.NewStateMachine(s.orderRepositoryMock, s.clockMock, payment.RefundPolicy{ExpiredPercent: 50, CancelledPercent: 100}, signer)
+	s.worker = NewExpiryWorker(s.orderRepositoryMock, machine, s.clockMock, time.Minute)
+}
+
+func (s *suiteExpiryWorker) TearDown() {
+	s.orderRepositoryMock = nil
+	s.clockMock = nil
+	s.worker = nil
+}
+
+func (s *suiteExpiryWorker) TestRunOnce() {
+	now := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	pendingId := uuid.New()
+	readyId := uuid.New()
+
+	testCase := []struct {
+		Name                 string
+		ExpectedErr          error
+		FindPendingRes       []model.Order
+		FindPendingErr       error
+		CencelExpiredErr     error
+		FindReadyRes         []model.Order
+		FindReadyErr         error
+		RefundExpiredErr     error
+		ExpectedCencelCalled bool
+		ExpectedRefundCalled bool
+	}{
+		{
+			Name:                 "cencel pending and refund ready order",
+			ExpectedErr:          nil,
+			FindPendingRes:       []model.Order{{ID: pendingId, StatusOrderID: constants.Pending_status_order_id}},
+			FindReadyRes:         []model.Order{{ID: readyId, StatusOrderID: constants.Ready_status_order_id, GrandTotal: 10000}},
+			ExpectedCencelCalled: true,
+			ExpectedRefundCalled: true,
+		},
+		{
+			Name:                 "nothing expired",
+			ExpectedErr:          nil,
+			FindPendingRes:       []model.Order{},
+			FindReadyRes:         []model.Order{},
+			ExpectedCencelCalled: false,
+			ExpectedRefundCalled: false,
+		},
+		{
+			Name:                 "order already handled by other replica",
+			ExpectedErr:          nil,
+			FindPendingRes:       []model.Order{{ID: pendingId, StatusOrderID: constants.Pending_status_order_id}},
+			CencelExpiredErr:     customerrors.ErrUpdateStatusOrder,
+			FindReadyRes:         []model.Order{{ID: readyId, StatusOrderID: constants.Ready_status_order_id, GrandTotal: 10000}},
+			RefundExpiredErr:     customerrors.ErrUpdateStatusOrder,
+			ExpectedCencelCalled: true,
+			ExpectedRefundCalled: true,
+		},
+		{
+			Name:                 "error find pending order",
+			ExpectedErr:          errors.New("internal error"),
+			FindPendingRes:       []model.Order(nil),
+			FindPendingErr:       errors.New("internal error"),
+			ExpectedCencelCalled: false,
+			ExpectedRefundCalled: false,
+		},
+		{
+			Name:                 "error find ready order",
+			ExpectedErr:          errors.New("internal error"),
+			FindPendingRes:       []model.Order{},
+			FindReadyRes:         []model.Order(nil),
+			FindReadyErr:         errors.New("internal error"),
+			ExpectedCencelCalled: false,
+			ExpectedRefundCalled: false,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			s.clockMock.On("Now").Return(now)
+			s.orderRepositoryMock.On("FindExpiredOrders", uint(constants.Pending_status_order_id), now).Return(v.FindPendingRes, v.FindPendingErr)
+			s.orderRepositoryMock.On("FindExpiredOrders", uint(constants.Ready_status_order_id), now).Return(v.FindReadyRes, v.FindReadyErr)
+			s.orderRepositoryMock.On("UpdateStatusOrder", orderWithId(pendingId), cencelChange()).Return(v.CencelExpiredErr)
+			s.orderRepositoryMock.On("UpdateStatusOrder", orderWithId(readyId), refundChange()).Return(v.RefundExpiredErr)
+
+			err := s.worker.RunOnce(context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			if v.ExpectedCencelCalled {
+				s.orderRepositoryMock.AssertCalled(t, "UpdateStatusOrder", orderWithId(pendingId), cencelChange())
+			} else {
+				s.orderRepositoryMock.AssertNotCalled(t, "UpdateStatusOrder", orderWithId(pendingId), mock.Anything)
+			}
+			if v.ExpectedRefundCalled {
+				s.orderRepositoryMock.AssertCalled(t, "UpdateStatusOrder", orderWithId(readyId), refundChange())
+			} else {
+				s.orderRepositoryMock.AssertNotCalled(t, "UpdateStatusOrder", orderWithId(readyId), mock.Anything)
+			}
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteExpiryWorker) TestRunOnceUseInjectedClock() {
+	s.SetupSuit()
+	before := time.Date(2022, 11, 1, 11, 0, 0, 0, time.UTC)
+	after := before.Add(2 * time.Hour)
+	orderId := uuid.New()
+
+	s.clockMock.On("Now").Return(before).Once()
+	s.clockMock.On("Now").Return(after).Once()
+	// status history time
+	s.clockMock.On("Now").Return(after)
+	// order expired between first and second run
+	s.orderRepositoryMock.On("FindExpiredOrders", uint(constants.Pending_status_order_id), before).Return([]model.Order{}, nil)
+	s.orderRepositoryMock.On("FindExpiredOrders", uint(constants.Pending_status_order_id), after).Return([]model.Order{{ID: orderId, StatusOrderID: constants.Pending_status_order_id}}, nil)
+	s.orderRepositoryMock.On("FindExpiredOrders", uint(constants.Ready_status_order_id), before).Return([]model.Order{}, nil)
+	s.orderRepositoryMock.On("FindExpiredOrders", uint(constants.Ready_status_order_id), after).Return([]model.Order{}, nil)
+	s.orderRepositoryMock.On("UpdateStatusOrder", orderWithId(orderId), cencelChange()).Return(nil)
+
+	s.NoError(s.worker.RunOnce(context.Background()))
+	s.orderRepositoryMock.AssertNotCalled(s.T(), "UpdateStatusOrder", orderWithId(orderId), mock.Anything)
+
+	s.NoError(s.worker.RunOnce(context.Background()))
+	s.orderRepositoryMock.AssertCalled(s.T(), "UpdateStatusOrder", orderWithId(orderId), mock.MatchedBy(func(change *or.StatusChange) bool {
+		history := change.History
+		return history.Source == constants.Order_source_expiry_worker && history.ActorID == nil && history.CreatedAt.Equal(after)
+	}))
+
+	s.TearDown()
+}
+
+func orderWithId(id uuid.UUID) interface{} {
+	return mock.MatchedBy(func(order *model.Order) bool {
+		return order.ID == id
+	})
+}
+
+// expired pending order is cencelled and reserved stock put back
+func cencelChange() interface{} {
+	return mock.MatchedBy(func(change *or.StatusChange) bool {
+		return change.From == constants.Pending_status_order_id && change.RestoreStock && change.Refund == nil
+	})
+}
+
+// expired ready order is refunded with expired refund policy, stock already taken from checkpoint
+func refundChange() interface{} {
+	return mock.MatchedBy(func(change *or.StatusChange) bool {
+		return change.From == constants.Ready_status_order_id && !change.RestoreStock &&
+			change.Refund != nil && change.Refund.Reason == constants.Refund_reason_expired
+	})
+}
+
+func TestSuiteExpiryWorker(t *testing.T) {
+	suite.Run(t, new(suiteExpiryWorker))
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/refund/dto"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/refund/service"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	_middleware "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/middleware"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+)
+
+type JWTService interface {
+	GetClaims(c *echo.Context) jwt.MapClaims
+}
+
+type refundController struct {
+	service    service.RefundService
+	jwtService JWTService
+}
+
+func NewRefundController(service service.RefundService, jwt JWTService) *refundController {
+	return &refundController{
+		service:    service,
+		jwtService: jwt,
+	}
+}
+
+func (u *refundController) InitRoute(auth *echo.Group) {
+	refunds := auth.Group("/refunds", _middleware.RequirePermission(constants.Permission_refund_manage))
+	refunds.GET("", u.GetRefunds)
+	refunds.POST("/:id/retry", u.RetryRefund)
+}
+
+func (u *refundController) GetRefunds(c echo.Context) error {
+	opts, err := query.Parse(c.QueryParams(), dto.RefundQuery)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	refunds, page, err := u.service.FindRefunds(opts, c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message":    "get refunds success",
+		"data":       refunds,
+		"pagination": page,
+	})
+}
+
+func (u *refundController) RetryRefund(c echo.Context) error {
+	err := u.service.RetryRefund(c.Param("id"), c.Request().Context())
+	if err != nil {
+		if err == customerrors.ErrInvalidId {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		if err == customerrors.ErrNotFound {
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		if err == customerrors.ErrRefundNotRetryable {
+			return c.JSON(http.StatusConflict, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		if err == customerrors.ErrRefundProvider {
+			return c.JSON(http.StatusBadGateway, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "refund request sent",
+	})
+}
@@ -0,0 +1,244 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/refund/dto"
+	rsm "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/refund/service/mock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	mm "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/middleware/mock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"github.com/stretchr/testify/suite"
+)
+
+type suiteRefundController struct {
+	suite.Suite
+	refundServiceMock *rsm.RefundServiceMock
+	JWTServiceMock    *mm.MockJWTService
+	refundController  *refundController
+	echoNew           *echo.Echo
+}
+
+func (s *suiteRefundController) SetupSuit() {
+	s.refundServiceMock = new(rsm.RefundServiceMock)
+	s.JWTServiceMock = new(mm.MockJWTService)
+	s.refundController = NewRefundController(s.refundServiceMock, s.JWTServiceMock)
+	s.echoNew = echo.New()
+}
+
+func (s *suiteRefundController) TearDown() {
+	s.refundServiceMock = nil
+	s.JWTServiceMock = nil
+	s.refundController = nil
+	s.echoNew = nil
+}
+
+func (s *suiteRefundController) TestGetRefunds() {
+	testCase := []struct {
+		Name           string
+		ExpectedStatus int
+		ExpectedResult map[string]interface{}
+		JWTReturn      jwt.MapClaims
+		FindRefundsRes dto.RefundsResponse
+		FindRefundsErr error
+	}{
+		{
+			Name:           "success",
+			ExpectedStatus: 200,
+			ExpectedResult: map[string]interface{}{
+				"message": "get refunds success",
+				"pagination": map[string]interface{}{
+					"page":        float64(1),
+					"limit":       float64(20),
+					"total":       float64(1),
+					"total_pages": float64(1),
+				},
+				"data": []interface{}{
+					map[string]interface{}{
+						"id":             float64(1),
+						"order_id":       "order",
+						"transaction_id": "transaction",
+						"reason":         constants.Refund_reason_expired,
+						"amount":         float64(5000),
+						"status":         constants.Refund_status_failed,
+						"attempts":       float64(1),
+						"last_error":     "provider down",
+						"created_at":     "0001-01-01T00:00:00Z",
+						"updated_at":     "0001-01-01T00:00:00Z",
+					},
+				},
+			},
+			JWTReturn: jwt.MapClaims{
+				"role_id": float64(constants.Role_admin),
+			},
+			FindRefundsRes: dto.RefundsResponse{
+				{
+					ID:            1,
+					OrderID:       "order",
+					TransactionID: "transaction",
+					Reason:        constants.Refund_reason_expired,
+					Amount:        5000,
+					Status:        constants.Refund_status_failed,
+					Attempts:      1,
+					LastError:     "provider down",
+				},
+			},
+		},
+		{
+			Name:           "internal server error",
+			ExpectedStatus: 500,
+			ExpectedResult: map[string]interface{}{
+				"message": "internal error",
+			},
+			JWTReturn: jwt.MapClaims{
+				"role_id": float64(constants.Role_admin),
+			},
+			FindRefundsErr: errors.New("internal error"),
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+
+			ctx := s.echoNew.NewContext(r, w)
+			ctx.SetPath("/refunds")
+
+			// define mock
+			s.JWTServiceMock.On("GetClaims").Return(v.JWTReturn)
+			s.refundServiceMock.On("FindRefunds").Return(v.FindRefundsRes, &query.Page{Page: 1, Limit: 20, Total: 1, TotalPages: 1}, v.FindRefundsErr)
+
+			err := s.refundController.GetRefunds(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteRefundController) TestRetryRefund() {
+	testCase := []struct {
+		Name           string
+		ExpectedStatus int
+		ExpectedResult map[string]interface{}
+		JWTReturn      jwt.MapClaims
+		RetryRefundErr error
+	}{
+		{
+			Name:           "success",
+			ExpectedStatus: 200,
+			ExpectedResult: map[string]interface{}{
+				"message": "refund request sent",
+			},
+			JWTReturn: jwt.MapClaims{
+				"role_id": float64(constants.Role_admin),
+			},
+		},
+		{
+			Name:           "invalid id",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrInvalidId.Error(),
+			},
+			JWTReturn: jwt.MapClaims{
+				"role_id": float64(constants.Role_admin),
+			},
+			RetryRefundErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:           "not found",
+			ExpectedStatus: 404,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrNotFound.Error(),
+			},
+			JWTReturn: jwt.MapClaims{
+				"role_id": float64(constants.Role_admin),
+			},
+			RetryRefundErr: customerrors.ErrNotFound,
+		},
+		{
+			Name:           "refund not failed",
+			ExpectedStatus: 409,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrRefundNotRetryable.Error(),
+			},
+			JWTReturn: jwt.MapClaims{
+				"role_id": float64(constants.Role_admin),
+			},
+			RetryRefundErr: customerrors.ErrRefundNotRetryable,
+		},
+		{
+			Name:           "provider refuse refund",
+			ExpectedStatus: 502,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrRefundProvider.Error(),
+			},
+			JWTReturn: jwt.MapClaims{
+				"role_id": float64(constants.Role_admin),
+			},
+			RetryRefundErr: customerrors.ErrRefundProvider,
+		},
+		{
+			Name:           "internal server error",
+			ExpectedStatus: 500,
+			ExpectedResult: map[string]interface{}{
+				"message": "internal error",
+			},
+			JWTReturn: jwt.MapClaims{
+				"role_id": float64(constants.Role_admin),
+			},
+			RetryRefundErr: errors.New("internal error"),
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			w := httptest.NewRecorder()
+
+			ctx := s.echoNew.NewContext(r, w)
+			ctx.SetPath("/refunds/:id/retry")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1")
+
+			// define mock
+			s.JWTServiceMock.On("GetClaims").Return(v.JWTReturn)
+			s.refundServiceMock.On("RetryRefund").Return(v.RetryRefundErr)
+
+			err := s.refundController.RetryRefund(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			s.TearDown()
+		})
+	}
+}
+
+func TestSuiteRefundController(t *testing.T) {
+	suite.Run(t, new(suiteRefundController))
+}
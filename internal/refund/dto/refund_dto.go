@@ -0,0 +1,61 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+)
+
+// RefundQuery is sort and filter allowed on refund list
+var RefundQuery = query.Schema{
+	Sort: map[string]string{
+		"created_at": "created_at",
+		"amount":     "amount",
+		"attempts":   "attempts",
+	},
+	DefaultSort: "created_at",
+	Key:         "id",
+	Filter: map[string]query.Filter{
+		"status":   {Column: "status", Type: query.String, Operator: query.Equal},
+		"order_id": {Column: "order_id", Type: query.UUID, Operator: query.Equal},
+		"from":     {Column: "created_at", Type: query.Time, Operator: query.From},
+		"to":       {Column: "created_at", Type: query.Time, Operator: query.Until},
+	},
+}
+
+type RefundResponse struct {
+	ID            uint      `json:"id"`
+	OrderID       string    `json:"order_id"`
+	TransactionID string    `json:"transaction_id"`
+	Reason        string    `json:"reason"`
+	Amount        int       `json:"amount"`
+	Status        string    `json:"status"`
+	Attempts      int       `json:"attempts"`
+	LastError     string    `json:"last_error"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (u *RefundResponse) FromModel(model *model.Refund) {
+	u.ID = model.ID
+	u.OrderID = model.OrderID.String()
+	u.TransactionID = model.TransactionID.String()
+	u.Reason = model.Reason
+	u.Amount = model.Amount
+	u.Status = model.Status
+	u.Attempts = model.Attempts
+	u.LastError = model.LastError
+	u.CreatedAt = model.CreatedAt
+	u.UpdatedAt = model.UpdatedAt
+}
+
+type RefundsResponse []RefundResponse
+
+func (u *RefundsResponse) FromModel(model []model.Refund) {
+	for _, each := range model {
+		var refund RefundResponse
+		refund.FromModel(&each)
+		*u = append(*u, refund)
+	}
+}
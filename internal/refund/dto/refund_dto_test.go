@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefundsResponse_FromModel(t *testing.T) {
+	orderId := uuid.New()
+	transactionId := uuid.New()
+	createdAt := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	var response RefundsResponse
+	response.FromModel([]model.Refund{
+		{
+			ID:            1,
+			CreatedAt:     createdAt,
+			UpdatedAt:     createdAt,
+			OrderID:       orderId,
+			TransactionID: transactionId,
+			RefundKey:     "secret-key",
+			Reason:        constants.Refund_reason_expired,
+			Amount:        5000,
+			Status:        constants.Refund_status_failed,
+			Attempts:      2,
+			LastError:     "provider down",
+		},
+	})
+
+	assert.Equal(t, RefundsResponse{
+		{
+			ID:            1,
+			OrderID:       orderId.String(),
+			TransactionID: transactionId.String(),
+			Reason:        constants.Refund_reason_expired,
+			Amount:        5000,
+			Status:        constants.Refund_status_failed,
+			Attempts:      2,
+			LastError:     "provider down",
+			CreatedAt:     createdAt,
+			UpdatedAt:     createdAt,
+		},
+	}, response)
+}
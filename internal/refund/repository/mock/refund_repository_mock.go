@@ -0,0 +1,37 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"github.com/stretchr/testify/mock"
+)
+
+type RefundRepositoryMock struct {
+	mock.Mock
+}
+
+func (b *RefundRepositoryMock) FindRefunds(statuses []string, opts query.Options, ctx context.Context) ([]model.Refund, int64, error) {
+	args := b.Called(statuses)
+
+	return args.Get(0).([]model.Refund), args.Get(1).(int64), args.Error(2)
+}
+
+func (b *RefundRepositoryMock) FindRefundById(refund *model.Refund, ctx context.Context) error {
+	args := b.Called(refund)
+
+	return args.Error(0)
+}
+
+func (b *RefundRepositoryMock) MarkRefundRequested(id uint, ctx context.Context) error {
+	args := b.Called(id)
+
+	return args.Error(0)
+}
+
+func (b *RefundRepositoryMock) MarkRefundFailed(id uint, lastError string, ctx context.Context) error {
+	args := b.Called(id, lastError)
+
+	return args.Error(0)
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"gorm.io/gorm"
+)
+
+// refund can only be sent to payment provider when still pending or failed
+var sendableRefundStatus = []string{constants.Refund_status_pending, constants.Refund_status_failed}
+
+type refundRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// FindRefunds implements RefundRepository
+func (r *refundRepositoryImpl) FindRefunds(statuses []string, opts query.Options, ctx context.Context) ([]model.Refund, int64, error) {
+	var refunds []model.Refund
+	total, err := query.Find(r.db.WithContext(ctx).Model(&model.Refund{}).Where("status IN ?", statuses), opts, &refunds)
+	if err != nil {
+		return nil, 0, err
+	}
+	return refunds, total, nil
+}
+
+// FindRefundById implements RefundRepository
+func (r *refundRepositoryImpl) FindRefundById(refund *model.Refund, ctx context.Context) error {
+	err := r.db.WithContext(ctx).First(refund).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return customerrors.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// MarkRefundRequested implements RefundRepository
+func (r *refundRepositoryImpl) MarkRefundRequested(id uint, ctx context.Context) error {
+	// provider notification can complete the refund before this update,
+	// so only pending or failed refund is moved to requested
+	return r.db.WithContext(ctx).Model(&model.Refund{}).Where("id = ? AND status IN ?", id, sendableRefundStatus).Updates(map[string]interface{}{
+		"status":     constants.Refund_status_requested,
+		"attempts":   gorm.Expr("attempts + ?", 1),
+		"last_error": "",
+	}).Error
+}
+
+// MarkRefundFailed implements RefundRepository
+func (r *refundRepositoryImpl) MarkRefundFailed(id uint, lastError string, ctx context.Context) error {
+	return r.db.WithContext(ctx).Model(&model.Refund{}).Where("id = ? AND status IN ?", id, sendableRefundStatus).Updates(map[string]interface{}{
+		"status":     constants.Refund_status_failed,
+		"attempts":   gorm.Expr("attempts + ?", 1),
+		"last_error": lastError,
+	}).Error
+}
+
+func NewRefundRepository(db *gorm.DB) RefundRepository {
+	return &refundRepositoryImpl{
+		db: db,
+	}
+}
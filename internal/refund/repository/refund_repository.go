@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+)
+
+type RefundRepository interface {
+	FindRefunds(statuses []string, opts query.Options, ctx context.Context) ([]model.Refund, int64, error)
+	FindRefundById(refund *model.Refund, ctx context.Context) error
+	MarkRefundRequested(id uint, ctx context.Context) error
+	MarkRefundFailed(id uint, lastError string, ctx context.Context) error
+}
@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type suiteRefundRepository struct {
+	suite.Suite
+	mock       sqlmock.Sqlmock
+	repository *refundRepositoryImpl
+}
+
+func (s *suiteRefundRepository) SetupSuite() {
+	db, mocking, _ := sqlmock.New()
+
+	dbGorm, _ := gorm.Open(mysql.New(mysql.Config{
+		SkipInitializeWithVersion: true,
+		Conn:                      db,
+	}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+
+	s.mock = mocking
+	s.repository = &refundRepositoryImpl{
+		db: dbGorm,
+	}
+}
+
+func (s *suiteRefundRepository) TestFindRefunds() {
+	orderId := uuid.New()
+	transactionId := uuid.New()
+	createdAt := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	testCase := []struct {
+		Name        string
+		ExpectedErr error
+		ExpectedLen int
+		MockReturn  error
+	}{
+		{
+			Name:        "success",
+			ExpectedErr: nil,
+			ExpectedLen: 1,
+		},
+		{
+			Name:        "error",
+			ExpectedErr: errors.New("err"),
+			MockReturn:  errors.New("err"),
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `refunds` WHERE status IN (?,?)")).
+				WithArgs(constants.Refund_status_pending, constants.Refund_status_failed).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(v.ExpectedLen))
+			db := s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `refunds` WHERE status IN (?,?) ORDER BY `created_at`,`id`")).
+				WithArgs(constants.Refund_status_pending, constants.Refund_status_failed)
+			if v.MockReturn != nil {
+				db.WillReturnError(v.MockReturn)
+			} else {
+				rows := sqlmock.NewRows([]string{"id", "created_at", "order_id", "transaction_id", "refund_key", "reason", "amount", "status", "attempts"}).
+					AddRow(1, createdAt, orderId, transactionId, "key", constants.Refund_reason_expired, 5000, constants.Refund_status_pending, 0)
+				db.WillReturnRows(rows)
+			}
+			var ctx context.Context
+			opts := query.Options{Sort: "created_at", Key: "id"}
+			refunds, total, err := s.repository.FindRefunds([]string{constants.Refund_status_pending, constants.Refund_status_failed}, opts, ctx)
+
+			s.Equal(v.ExpectedErr, err)
+			s.Len(refunds, v.ExpectedLen)
+			s.Equal(int64(v.ExpectedLen), total)
+			if v.ExpectedLen > 0 {
+				s.Equal(orderId, refunds[0].OrderID)
+				s.Equal(5000, refunds[0].Amount)
+			}
+		})
+	}
+}
+
+func (s *suiteRefundRepository) TestFindRefundById() {
+	testCase := []struct {
+		Name        string
+		ExpectedErr error
+		MockReturn  error
+	}{
+		{
+			Name:        "success",
+			ExpectedErr: nil,
+		},
+		{
+			Name:        "not found",
+			ExpectedErr: customerrors.ErrNotFound,
+			MockReturn:  gorm.ErrRecordNotFound,
+		},
+		{
+			Name:        "error",
+			ExpectedErr: errors.New("err"),
+			MockReturn:  errors.New("err"),
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			query := s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `refunds` WHERE `refunds`.`id` = ? ORDER BY `refunds`.`id` LIMIT 1")).WithArgs(1)
+			if v.MockReturn != nil {
+				query.WillReturnError(v.MockReturn)
+			} else {
+				query.WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, constants.Refund_status_failed))
+			}
+			var ctx context.Context
+			refund := model.Refund{ID: 1}
+			err := s.repository.FindRefundById(&refund, ctx)
+
+			s.Equal(v.ExpectedErr, err)
+		})
+	}
+}
+
+func (s *suiteRefundRepository) TestMarkRefundRequested() {
+	testCase := []struct {
+		Name        string
+		ExpectedErr error
+		MockReturn  error
+		MockRows    int64
+	}{
+		{
+			Name:        "success",
+			ExpectedErr: nil,
+			MockRows:    1,
+		},
+		{
+			Name:        "already completed by provider notification",
+			ExpectedErr: nil,
+			MockRows:    0,
+		},
+		{
+			Name:        "error",
+			ExpectedErr: errors.New("err"),
+			MockReturn:  errors.New("err"),
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.mock.ExpectBegin()
+			db := s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `refunds` SET `attempts`=attempts + ?,`last_error`=?,`status`=?,`updated_at`=? WHERE id = ? AND status IN (?,?)"))
+			if v.MockReturn != nil {
+				db.WillReturnError(v.MockReturn)
+				s.mock.ExpectRollback()
+			} else {
+				db.WillReturnResult(sqlmock.NewResult(1, v.MockRows))
+				s.mock.ExpectCommit()
+			}
+			var ctx context.Context
+			err := s.repository.MarkRefundRequested(1, ctx)
+
+			s.Equal(v.ExpectedErr, err)
+		})
+	}
+}
+
+func (s *suiteRefundRepository) TestMarkRefundFailed() {
+	testCase := []struct {
+		Name        string
+		ExpectedErr error
+		MockReturn  error
+	}{
+		{
+			Name:        "success",
+			ExpectedErr: nil,
+		},
+		{
+			Name:        "error",
+			ExpectedErr: errors.New("err"),
+			MockReturn:  errors.New("err"),
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.mock.ExpectBegin()
+			db := s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `refunds` SET `attempts`=attempts + ?,`last_error`=?,`status`=?,`updated_at`=? WHERE id = ? AND status IN (?,?)")).
+				WithArgs(1, "provider down", constants.Refund_status_failed, sqlmock.AnyArg(), 1, constants.Refund_status_pending, constants.Refund_status_failed)
+			if v.MockReturn != nil {
+				db.WillReturnError(v.MockReturn)
+				s.mock.ExpectRollback()
+			} else {
+				db.WillReturnResult(sqlmock.NewResult(1, 1))
+				s.mock.ExpectCommit()
+			}
+			var ctx context.Context
+			err := s.repository.MarkRefundFailed(1, "provider down", ctx)
+
+			s.Equal(v.ExpectedErr, err)
+		})
+	}
+}
+
+func TestSuiteRefundRepository(t *testing.T) {
+	suite.Run(t, new(suiteRefundRepository))
+}
@@ -0,0 +1,28 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/refund/dto"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"github.com/stretchr/testify/mock"
+)
+
+type RefundServiceMock struct {
+	mock.Mock
+}
+
+func (b *RefundServiceMock) FindRefunds(opts query.Options, ctx context.Context) (dto.RefundsResponse, *query.Page, error) {
+	args := b.Called()
+	return args.Get(0).(dto.RefundsResponse), args.Get(1).(*query.Page), args.Error(2)
+}
+
+func (b *RefundServiceMock) ProcessPendingRefunds(ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *RefundServiceMock) RetryRefund(refundId string, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
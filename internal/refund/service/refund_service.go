@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/refund/dto"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+)
+
+type RefundService interface {
+	FindRefunds(opts query.Options, ctx context.Context) (dto.RefundsResponse, *query.Page, error)
+	ProcessPendingRefunds(ctx context.Context) error
+	RetryRefund(refundId string, ctx context.Context) error
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/refund/dto"
+	rr "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/refund/repository"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/payment"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+)
+
+// refund still waiting for provider confirmation
+var openRefundStatus = []string{constants.Refund_status_pending, constants.Refund_status_requested, constants.Refund_status_failed}
+
+// pending refund is sent oldest first
+var pendingRefundOrder = query.Options{Sort: "created_at", Key: "id"}
+
+type refundServiceImpl struct {
+	refundRepo rr.RefundRepository
+	payment    payment.PaymentProvider
+}
+
+// FindRefunds implements RefundService
+func (s *refundServiceImpl) FindRefunds(opts query.Options, ctx context.Context) (dto.RefundsResponse, *query.Page, error) {
+	refunds, total, err := s.refundRepo.FindRefunds(openRefundStatus, opts, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var refundsResponse dto.RefundsResponse
+	refundsResponse.FromModel(refunds)
+	return refundsResponse, query.NewPage(opts, total), nil
+}
+
+// ProcessPendingRefunds implements RefundService
+func (s *refundServiceImpl) ProcessPendingRefunds(ctx context.Context) error {
+	refunds, _, err := s.refundRepo.FindRefunds([]string{constants.Refund_status_pending}, pendingRefundOrder, ctx)
+	if err != nil {
+		return err
+	}
+	for i := range refunds {
+		// failed refund wait for admin retry, other refund still processed
+		if err := s.sendRefund(&refunds[i], ctx); err != nil {
+			log.Println("refund:", refunds[i].OrderID, err)
+		}
+	}
+	return nil
+}
+
+// RetryRefund implements RefundService
+func (s *refundServiceImpl) RetryRefund(refundId string, ctx context.Context) error {
+	id, err := strconv.ParseUint(refundId, 10, 64)
+	if err != nil {
+		return customerrors.ErrInvalidId
+	}
+	refund := model.Refund{
+		ID: uint(id),
+	}
+	err = s.refundRepo.FindRefundById(&refund, ctx)
+	if err != nil {
+		return err
+	}
+	if refund.Status != constants.Refund_status_failed {
+		return customerrors.ErrRefundNotRetryable
+	}
+	return s.sendRefund(&refund, ctx)
+}
+
+// sendRefund call provider refund api, refund key is same on every attempt so provider wont refund twice.
+// order move to refund success when provider notification confirm the refund
+func (s *refundServiceImpl) sendRefund(refund *model.Refund, ctx context.Context) error {
+	err := s.payment.RefundTransaction(refund.OrderID.String(), refund.RefundKey, refund.Amount, refund.Reason)
+	if err != nil {
+		if err := s.refundRepo.MarkRefundFailed(refund.ID, err.Error(), ctx); err != nil {
+			return err
+		}
+		return customerrors.ErrRefundProvider
+	}
+	return s.refundRepo.MarkRefundRequested(refund.ID, ctx)
+}
+
+func NewRefundService(refundRepo rr.RefundRepository, paymentProvider payment.PaymentProvider) RefundService {
+	return &refundServiceImpl{
+		refundRepo: refundRepo,
+		payment:    paymentProvider,
+	}
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	_refundRepositoryMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/refund/repository/mock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	_paymentMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/payment/mock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type suiteRefundService struct {
+	suite.Suite
+	refundRepositoryMock *_refundRepositoryMock.RefundRepositoryMock
+	paymentMock          *_paymentMock.PaymentProviderMock
+	refundService        RefundService
+}
+
+func (s *suiteRefundService) SetupTest() {
+	s.refundRepositoryMock = new(_refundRepositoryMock.RefundRepositoryMock)
+	s.paymentMock = new(_paymentMock.PaymentProviderMock)
+	s.refundService = NewRefundService(s.refundRepositoryMock, s.paymentMock)
+}
+
+func (s *suiteRefundService) TestFindRefunds() {
+	testCase := []struct {
+		Name        string
+		ExpectedErr error
+		ExpectedLen int
+		MockReturn  []model.Refund
+		MockErr     error
+	}{
+		{
+			Name:        "success",
+			ExpectedErr: nil,
+			ExpectedLen: 1,
+			MockReturn:  []model.Refund{{ID: 1, Status: constants.Refund_status_failed}},
+		},
+		{
+			Name:        "error",
+			ExpectedErr: errors.New("err"),
+			MockReturn:  []model.Refund{},
+			MockErr:     errors.New("err"),
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupTest()
+			s.refundRepositoryMock.On("FindRefunds", []string{constants.Refund_status_pending, constants.Refund_status_requested, constants.Refund_status_failed}).Return(v.MockReturn, int64(len(v.MockReturn)), v.MockErr)
+
+			refunds, page, err := s.refundService.FindRefunds(query.Options{Page: 1, Limit: 20}, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			s.Len(refunds, v.ExpectedLen)
+			if v.ExpectedErr == nil {
+				s.Equal(&query.Page{Page: 1, Limit: 20, Total: 1, TotalPages: 1}, page)
+			}
+		})
+	}
+}
+
+func (s *suiteRefundService) TestProcessPendingRefunds() {
+	refunds := []model.Refund{
+		{ID: 1, OrderID: uuid.New(), RefundKey: "key-1", Amount: 5000, Status: constants.Refund_status_pending},
+		{ID: 2, OrderID: uuid.New(), RefundKey: "key-2", Amount: 7000, Status: constants.Refund_status_pending},
+	}
+	s.refundRepositoryMock.On("FindRefunds", []string{constants.Refund_status_pending}).Return(refunds, int64(len(refunds)), nil)
+	s.paymentMock.On("RefundTransaction").Return(errors.New("provider down")).Once()
+	s.paymentMock.On("RefundTransaction").Return(nil).Once()
+	s.refundRepositoryMock.On("MarkRefundFailed", uint(1), "provider down").Return(nil)
+	s.refundRepositoryMock.On("MarkRefundRequested", uint(2)).Return(nil)
+
+	err := s.refundService.ProcessPendingRefunds(context.Background())
+
+	s.NoError(err)
+	s.refundRepositoryMock.AssertCalled(s.T(), "MarkRefundFailed", uint(1), "provider down")
+	s.refundRepositoryMock.AssertCalled(s.T(), "MarkRefundRequested", uint(2))
+}
+
+func (s *suiteRefundService) TestProcessPendingRefundsFindErr() {
+	s.refundRepositoryMock.On("FindRefunds", []string{constants.Refund_status_pending}).Return([]model.Refund{}, int64(0), errors.New("err"))
+
+	err := s.refundService.ProcessPendingRefunds(context.Background())
+
+	s.Equal(errors.New("err"), err)
+	s.paymentMock.AssertNotCalled(s.T(), "RefundTransaction")
+}
+
+func (s *suiteRefundService) TestRetryRefund() {
+	testCase := []struct {
+		Name              string
+		RefundId          string
+		ExpectedErr       error
+		FindErr           error
+		Status            string
+		ProviderErr       error
+		ExpectSendRefund  bool
+		ExpectedRequested bool
+	}{
+		{
+			Name:              "success",
+			RefundId:          "1",
+			ExpectedErr:       nil,
+			Status:            constants.Refund_status_failed,
+			ExpectSendRefund:  true,
+			ExpectedRequested: true,
+		},
+		{
+			Name:        "invalid id",
+			RefundId:    "abc",
+			ExpectedErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:        "not found",
+			RefundId:    "1",
+			ExpectedErr: customerrors.ErrNotFound,
+			FindErr:     customerrors.ErrNotFound,
+		},
+		{
+			Name:        "refund already requested",
+			RefundId:    "1",
+			ExpectedErr: customerrors.ErrRefundNotRetryable,
+			Status:      constants.Refund_status_requested,
+		},
+		{
+			Name:             "provider refuse refund",
+			RefundId:         "1",
+			ExpectedErr:      customerrors.ErrRefundProvider,
+			Status:           constants.Refund_status_failed,
+			ProviderErr:      errors.New("provider down"),
+			ExpectSendRefund: true,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupTest()
+			s.refundRepositoryMock.On("FindRefundById", mock.Anything).Return(v.FindErr).Run(func(args mock.Arguments) {
+				refund := args.Get(0).(*model.Refund)
+				refund.Status = v.Status
+			})
+			s.paymentMock.On("RefundTransaction").Return(v.ProviderErr)
+			s.refundRepositoryMock.On("MarkRefundRequested", uint(1)).Return(nil)
+			s.refundRepositoryMock.On("MarkRefundFailed", uint(1), mock.Anything).Return(nil)
+
+			err := s.refundService.RetryRefund(v.RefundId, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			if v.ExpectSendRefund {
+				s.paymentMock.AssertCalled(t, "RefundTransaction")
+			} else {
+				s.paymentMock.AssertNotCalled(t, "RefundTransaction")
+			}
+			if v.ExpectedRequested {
+				s.refundRepositoryMock.AssertCalled(t, "MarkRefundRequested", uint(1))
+			} else {
+				s.refundRepositoryMock.AssertNotCalled(t, "MarkRefundRequested", mock.Anything)
+			}
+		})
+	}
+}
+
+func TestSuiteRefundService(t *testing.T) {
+	suite.Run(t, new(suiteRefundService))
+}
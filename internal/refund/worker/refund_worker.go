@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+type RefundProcessor interface {
+	ProcessPendingRefunds(ctx context.Context) error
+}
+
+type refundWorker struct {
+	processor RefundProcessor
+	interval  time.Duration
+}
+
+func NewRefundWorker(processor RefundProcessor, interval time.Duration) *refundWorker {
+	return &refundWorker{
+		processor: processor,
+		interval:  interval,
+	}
+}
+
+// Start send pending refund to payment provider every interval until context is done
+func (w *refundWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		if err := w.processor.ProcessPendingRefunds(ctx); err != nil {
+			log.Println("refund worker:", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	rsm "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/refund/service/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefundWorker_Start(t *testing.T) {
+	serviceMock := new(rsm.RefundServiceMock)
+	serviceMock.On("ProcessPendingRefunds").Return(errors.New("err"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	worker := NewRefundWorker(serviceMock, time.Minute)
+
+	done := make(chan struct{})
+	go func() {
+		worker.Start(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("worker not stopped after context done")
+	}
+	// worker process once before waiting for next tick, error is only logged
+	assert.True(t, serviceMock.AssertNumberOfCalls(t, "ProcessPendingRefunds", 1))
+}
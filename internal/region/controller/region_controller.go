@@ -4,8 +4,12 @@ import (
 	"net/http"
 
 	"github.com/labstack/echo/v4"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/region/dto"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/region/service"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	_middleware "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/middleware"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
 type regionController struct {
@@ -19,7 +23,7 @@ func NewRegionController(regionService service.RegionService) *regionController
 }
 
 func (r *regionController) InitRoute(auth *echo.Group) {
-	regions := auth.Group("/regions")
+	regions := auth.Group("/regions", _middleware.RequirePermission(constants.Permission_region_read))
 	regions.GET("/provinces", r.GetProvince)
 	regions.GET("/regencies/:province_id", r.GetRegency)
 	regions.GET("/districts/:regency_id", r.GetDistrict)
@@ -27,21 +31,34 @@ func (r *regionController) InitRoute(auth *echo.Group) {
 }
 
 func (r *regionController) GetProvince(c echo.Context) error {
-	provinces, err := r.service.FindProvince(c.Request().Context())
+	opts, err := query.Parse(c.QueryParams(), dto.RegionQuery)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	provinces, page, err := r.service.FindProvince(opts, c.Request().Context())
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{
 			"message": err.Error(),
 		})
 	}
 	return c.JSON(http.StatusOK, echo.Map{
-		"message": "get provinces success",
-		"data":    provinces,
+		"message":    "get provinces success",
+		"data":       provinces,
+		"pagination": page,
 	})
 }
 
 func (r *regionController) GetRegency(c echo.Context) error {
+	opts, err := query.Parse(c.QueryParams(), dto.RegionQuery)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
 	id := c.Param("province_id")
-	regencies, err := r.service.FindRegency(&id, c.Request().Context())
+	regencies, page, err := r.service.FindRegency(&id, opts, c.Request().Context())
 	if err != nil {
 		if err == customerrors.ErrInvalidId {
 			return c.JSON(http.StatusBadRequest, echo.Map{
@@ -53,14 +70,21 @@ func (r *regionController) GetRegency(c echo.Context) error {
 		})
 	}
 	return c.JSON(http.StatusOK, echo.Map{
-		"message": "get regencies success",
-		"data":    regencies,
+		"message":    "get regencies success",
+		"data":       regencies,
+		"pagination": page,
 	})
 }
 
 func (r *regionController) GetDistrict(c echo.Context) error {
+	opts, err := query.Parse(c.QueryParams(), dto.RegionQuery)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
 	id := c.Param("regency_id")
-	districts, err := r.service.FindDistrict(&id, c.Request().Context())
+	districts, page, err := r.service.FindDistrict(&id, opts, c.Request().Context())
 	if err != nil {
 		if err == customerrors.ErrInvalidId {
 			return c.JSON(http.StatusBadRequest, echo.Map{
@@ -72,14 +96,21 @@ func (r *regionController) GetDistrict(c echo.Context) error {
 		})
 	}
 	return c.JSON(http.StatusOK, echo.Map{
-		"message": "get districts success",
-		"data":    districts,
+		"message":    "get districts success",
+		"data":       districts,
+		"pagination": page,
 	})
 }
 
 func (r *regionController) GetVillage(c echo.Context) error {
+	opts, err := query.Parse(c.QueryParams(), dto.RegionQuery)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
 	id := c.Param("district_id")
-	villages, err := r.service.FindVillage(&id, c.Request().Context())
+	villages, page, err := r.service.FindVillage(&id, opts, c.Request().Context())
 	if err != nil {
 		if err == customerrors.ErrInvalidId {
 			return c.JSON(http.StatusBadRequest, echo.Map{
@@ -91,7 +122,8 @@ func (r *regionController) GetVillage(c echo.Context) error {
 		})
 	}
 	return c.JSON(http.StatusOK, echo.Map{
-		"message": "get villages success",
-		"data":    villages,
+		"message":    "get villages success",
+		"data":       villages,
+		"pagination": page,
 	})
 }
@@ -13,6 +13,7 @@ import (
 	rsm "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/region/service/mock"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -38,6 +39,15 @@ func (s *suiteRegionController) TearDown() {
 	s.regionController = nil
 }
 
+var listPage = &query.Page{Page: 1, Limit: 20, Total: 1, TotalPages: 1}
+
+var listPageResult = map[string]interface{}{
+	"page":        float64(1),
+	"limit":       float64(20),
+	"total":       float64(1),
+	"total_pages": float64(1),
+}
+
 func (s *suiteRegionController) TestGetProvince() {
 	testCase := []struct {
 		Name            string
@@ -61,7 +71,8 @@ func (s *suiteRegionController) TestGetProvince() {
 				},
 			},
 			ExpectedResult: map[string]interface{}{
-				"message": "get provinces success",
+				"message":    "get provinces success",
+				"pagination": listPageResult,
 				"data": []interface{}{
 					map[string]interface{}{
 						"id":   float64(1),
@@ -89,8 +100,9 @@ func (s *suiteRegionController) TestGetProvince() {
 			FindProvinceErr: nil,
 			FindProvinceRes: []model.Province(nil),
 			ExpectedResult: map[string]interface{}{
-				"message": "get provinces success",
-				"data":    nil,
+				"message":    "get provinces success",
+				"pagination": listPageResult,
+				"data":       nil,
 			},
 		},
 	}
@@ -107,7 +119,7 @@ func (s *suiteRegionController) TestGetProvince() {
 			ctx.SetPath("/region/province")
 
 			// define mock
-			s.regionServiceMock.On("FindProvince").Return(v.FindProvinceRes, v.FindProvinceErr)
+			s.regionServiceMock.On("FindProvince").Return(v.FindProvinceRes, listPage, v.FindProvinceErr)
 
 			err := s.regionController.GetProvince(ctx)
 			s.NoError(err)
@@ -152,7 +164,8 @@ func (s *suiteRegionController) TestGetRegency() {
 				},
 			},
 			ExpectedResult: map[string]interface{}{
-				"message": "get regencies success",
+				"message":    "get regencies success",
+				"pagination": listPageResult,
 				"data": []interface{}{
 					map[string]interface{}{
 						"id":            float64(1),
@@ -196,8 +209,9 @@ func (s *suiteRegionController) TestGetRegency() {
 			FindRegencyErr: nil,
 			FindRegencyRes: dto.RegenciesResponse(nil),
 			ExpectedResult: map[string]interface{}{
-				"message": "get regencies success",
-				"data":    nil,
+				"message":    "get regencies success",
+				"pagination": listPageResult,
+				"data":       nil,
 			},
 		},
 	}
@@ -216,7 +230,7 @@ func (s *suiteRegionController) TestGetRegency() {
 			ctx.SetParamValues(v.ProvinceId)
 
 			// define mock
-			s.regionServiceMock.On("FindRegency").Return(v.FindRegencyRes, v.FindRegencyErr)
+			s.regionServiceMock.On("FindRegency").Return(v.FindRegencyRes, listPage, v.FindRegencyErr)
 
 			err := s.regionController.GetRegency(ctx)
 			s.NoError(err)
@@ -261,7 +275,8 @@ func (s *suiteRegionController) TestGetDistrict() {
 				},
 			},
 			ExpectedResult: map[string]interface{}{
-				"message": "get districts success",
+				"message":    "get districts success",
+				"pagination": listPageResult,
 				"data": []interface{}{
 					map[string]interface{}{
 						"id":           float64(1),
@@ -305,8 +320,9 @@ func (s *suiteRegionController) TestGetDistrict() {
 			FindDistrictErr: nil,
 			FindDistrictRes: dto.DistrictsResponse(nil),
 			ExpectedResult: map[string]interface{}{
-				"message": "get districts success",
-				"data":    nil,
+				"message":    "get districts success",
+				"pagination": listPageResult,
+				"data":       nil,
 			},
 		},
 	}
@@ -325,7 +341,7 @@ func (s *suiteRegionController) TestGetDistrict() {
 			ctx.SetParamValues(v.RegencyId)
 
 			// define mock
-			s.regionServiceMock.On("FindDistrict").Return(v.FindDistrictRes, v.FindDistrictErr)
+			s.regionServiceMock.On("FindDistrict").Return(v.FindDistrictRes, listPage, v.FindDistrictErr)
 
 			err := s.regionController.GetDistrict(ctx)
 			s.NoError(err)
@@ -370,7 +386,8 @@ func (s *suiteRegionController) TestGetVillage() {
 				},
 			},
 			ExpectedResult: map[string]interface{}{
-				"message": "get villages success",
+				"message":    "get villages success",
+				"pagination": listPageResult,
 				"data": []interface{}{
 					map[string]interface{}{
 						"id":            float64(1),
@@ -414,8 +431,9 @@ func (s *suiteRegionController) TestGetVillage() {
 			FindVillageErr: nil,
 			FindVillageRes: dto.VillagesResponse(nil),
 			ExpectedResult: map[string]interface{}{
-				"message": "get villages success",
-				"data":    nil,
+				"message":    "get villages success",
+				"pagination": listPageResult,
+				"data":       nil,
 			},
 		},
 	}
@@ -434,7 +452,7 @@ func (s *suiteRegionController) TestGetVillage() {
 			ctx.SetParamValues(v.DistrictId)
 
 			// define mock
-			s.regionServiceMock.On("FindVillage").Return(v.FindVillageRes, v.FindVillageErr)
+			s.regionServiceMock.On("FindVillage").Return(v.FindVillageRes, listPage, v.FindVillageErr)
 
 			err := s.regionController.GetVillage(ctx)
 			s.NoError(err)
@@ -451,6 +469,30 @@ func (s *suiteRegionController) TestGetVillage() {
 	}
 }
 
+func (s *suiteRegionController) TestGetVillageInvalidQuery() {
+	s.SetupSuit()
+	defer s.TearDown()
+
+	r := httptest.NewRequest(http.MethodGet, "/?limit=1000", nil)
+	w := httptest.NewRecorder()
+
+	ctx := echo.New().NewContext(r, w)
+	ctx.SetPath("/region/village/:district_id")
+	ctx.SetParamNames("district_id")
+	ctx.SetParamValues("1")
+
+	err := s.regionController.GetVillage(ctx)
+	s.NoError(err)
+
+	controllerResult := map[string]interface{}{}
+	err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+	s.NoError(err)
+
+	s.Equal(http.StatusBadRequest, w.Result().StatusCode)
+	s.Equal(customerrors.ErrInvalidPage.Error(), controllerResult["message"])
+	s.regionServiceMock.AssertNotCalled(s.T(), "FindVillage")
+}
+
 func (s *suiteRegionController) TestInitRoute() {
 	group := echo.New().Group("/api/v1")
 	s.NotPanics(func() {
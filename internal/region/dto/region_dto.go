@@ -0,0 +1,16 @@
+package dto
+
+import "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+
+// RegionQuery is sort and filter allowed on every region level list
+var RegionQuery = query.Schema{
+	Sort: map[string]string{
+		"id":   "id",
+		"name": "name",
+	},
+	DefaultSort: "id",
+	Key:         "id",
+	Filter: map[string]query.Filter{
+		"name": {Column: "name", Type: query.String, Operator: query.Contain},
+	},
+}
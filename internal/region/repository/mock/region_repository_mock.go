@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -21,22 +22,22 @@ func (b *RegionRepositoryMock) ImportRegion(model interface{}) error {
 	return args.Error(0)
 }
 
-func (b *RegionRepositoryMock) FindProvince(ctx context.Context) ([]model.Province, error) {
+func (b *RegionRepositoryMock) FindProvince(opts query.Options, ctx context.Context) ([]model.Province, int64, error) {
 	args := b.Called()
-	return args.Get(0).([]model.Province), args.Error(1)
+	return args.Get(0).([]model.Province), args.Get(1).(int64), args.Error(2)
 }
 
-func (b *RegionRepositoryMock) FindRegency(regency *model.Regency, ctx context.Context) ([]model.Regency, error) {
+func (b *RegionRepositoryMock) FindRegency(regency *model.Regency, opts query.Options, ctx context.Context) ([]model.Regency, int64, error) {
 	args := b.Called()
-	return args.Get(0).([]model.Regency), args.Error(1)
+	return args.Get(0).([]model.Regency), args.Get(1).(int64), args.Error(2)
 }
 
-func (b *RegionRepositoryMock) FindDistrict(district *model.District, ctx context.Context) ([]model.District, error) {
+func (b *RegionRepositoryMock) FindDistrict(district *model.District, opts query.Options, ctx context.Context) ([]model.District, int64, error) {
 	args := b.Called()
-	return args.Get(0).([]model.District), args.Error(1)
+	return args.Get(0).([]model.District), args.Get(1).(int64), args.Error(2)
 }
 
-func (b *RegionRepositoryMock) FindVillage(village *model.Village, ctx context.Context) ([]model.Village, error) {
+func (b *RegionRepositoryMock) FindVillage(village *model.Village, opts query.Options, ctx context.Context) ([]model.Village, int64, error) {
 	args := b.Called()
-	return args.Get(0).([]model.Village), args.Error(1)
+	return args.Get(0).([]model.Village), args.Get(1).(int64), args.Error(2)
 }
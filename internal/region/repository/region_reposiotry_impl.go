@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"gorm.io/gorm"
 )
 
@@ -12,43 +13,43 @@ type regionRepositoryImpl struct {
 }
 
 // FindProvince implements RegionRepository
-func (r *regionRepositoryImpl) FindProvince(ctx context.Context) ([]model.Province, error) {
+func (r *regionRepositoryImpl) FindProvince(opts query.Options, ctx context.Context) ([]model.Province, int64, error) {
 	var provinces []model.Province
-	err := r.db.WithContext(ctx).Find(&provinces).Error
+	total, err := query.Find(r.db.WithContext(ctx).Model(&model.Province{}), opts, &provinces)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return provinces, err
+	return provinces, total, nil
 }
 
 // FindRegency implements RegionRepository
-func (r *regionRepositoryImpl) FindRegency(regency *model.Regency, ctx context.Context) ([]model.Regency, error) {
+func (r *regionRepositoryImpl) FindRegency(regency *model.Regency, opts query.Options, ctx context.Context) ([]model.Regency, int64, error) {
 	var regencies []model.Regency
-	err := r.db.WithContext(ctx).Preload("Province").Where(regency).Find(&regencies).Error
+	total, err := query.Find(r.db.WithContext(ctx).Model(&model.Regency{}).Preload("Province").Where(regency), opts, &regencies)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return regencies, err
+	return regencies, total, nil
 }
 
 // FindDistrict implements RegionRepository
-func (r *regionRepositoryImpl) FindDistrict(district *model.District, ctx context.Context) ([]model.District, error) {
+func (r *regionRepositoryImpl) FindDistrict(district *model.District, opts query.Options, ctx context.Context) ([]model.District, int64, error) {
 	var districts []model.District
-	err := r.db.WithContext(ctx).Preload("Regency").Where(district).Find(&districts).Error
+	total, err := query.Find(r.db.WithContext(ctx).Model(&model.District{}).Preload("Regency").Where(district), opts, &districts)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return districts, err
+	return districts, total, nil
 }
 
 // FindVillage implements RegionRepository
-func (r *regionRepositoryImpl) FindVillage(village *model.Village, ctx context.Context) ([]model.Village, error) {
+func (r *regionRepositoryImpl) FindVillage(village *model.Village, opts query.Options, ctx context.Context) ([]model.Village, int64, error) {
 	var villages []model.Village
-	err := r.db.WithContext(ctx).Preload("District").Where(village).Find(&villages).Error
+	total, err := query.Find(r.db.WithContext(ctx).Model(&model.Village{}).Preload("District").Where(village), opts, &villages)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return villages, err
+	return villages, total, nil
 }
 
 // CheckIsImported implements RegionRepository
@@ -4,13 +4,14 @@ import (
 	"context"
 
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
 type RegionRepository interface {
 	CheckIsImported(model interface{}) (bool, error)
 	ImportRegion(model interface{}) error
-	FindProvince(ctx context.Context) ([]model.Province, error)
-	FindRegency(regency *model.Regency, ctx context.Context) ([]model.Regency, error)
-	FindDistrict(district *model.District, ctx context.Context) ([]model.District, error)
-	FindVillage(village *model.Village, ctx context.Context) ([]model.Village, error)
+	FindProvince(opts query.Options, ctx context.Context) ([]model.Province, int64, error)
+	FindRegency(regency *model.Regency, opts query.Options, ctx context.Context) ([]model.Regency, int64, error)
+	FindDistrict(district *model.District, opts query.Options, ctx context.Context) ([]model.District, int64, error)
+	FindVillage(village *model.Village, opts query.Options, ctx context.Context) ([]model.Village, int64, error)
 }
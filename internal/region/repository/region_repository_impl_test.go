@@ -7,11 +7,14 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/region/dto"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"github.com/stretchr/testify/suite"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"net/url"
 )
 
 type suiteRegionRepository struct {
@@ -148,14 +151,19 @@ func (s *suiteRegionRepository) TestFindProvince() {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.SetupSuite()
 
-			db := s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `provinces`"))
+			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `provinces`")).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(len(v.ExpectedRes)))
+
+			db := s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `provinces` ORDER BY `id`"))
 			db.WillReturnRows(v.MockRes)
 			db.WillReturnError(v.MockErr)
 			var ctx context.Context
-			res, err := s.repository.FindProvince(ctx)
+			res, total, err := s.repository.FindProvince(query.Options{Sort: "id", Key: "id"}, ctx)
 
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(v.ExpectedRes, res)
+			if v.ExpectedErr == nil {
+				s.Equal(int64(len(v.ExpectedRes)), total)
+			}
 
 			s.TearDown()
 		})
@@ -238,6 +246,8 @@ func (s *suiteRegionRepository) TestFindRegency() {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.SetupSuite()
 
+			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `regencies` WHERE `regencies`.`province_id` = ?")).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(len(v.ExpectedRes)))
+
 			selectRegency := s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `regencies` WHERE `regencies`.`province_id` = ?"))
 			selectRegency.WillReturnRows(v.MockSelectRegencyRes)
 			selectRegency.WillReturnError(v.MockSelectRegencyErr)
@@ -247,10 +257,13 @@ func (s *suiteRegionRepository) TestFindRegency() {
 			selectProvince.WillReturnError(v.MockSelectProvinceErr)
 
 			var ctx context.Context
-			res, err := s.repository.FindRegency(&v.Body, ctx)
+			res, total, err := s.repository.FindRegency(&v.Body, query.Options{}, ctx)
 
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(v.ExpectedRes, res)
+			if v.ExpectedErr == nil {
+				s.Equal(int64(len(v.ExpectedRes)), total)
+			}
 
 			s.TearDown()
 		})
@@ -335,6 +348,8 @@ func (s *suiteRegionRepository) TestFindDistrict() {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.SetupSuite()
 
+			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `districts` WHERE `districts`.`regency_id` = ?")).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(len(v.ExpectedRes)))
+
 			selectDistrict := s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `districts` WHERE `districts`.`regency_id` = ?"))
 			selectDistrict.WillReturnRows(v.MockSelectDistrictRes)
 			selectDistrict.WillReturnError(v.MockSelectDistrictErr)
@@ -344,10 +359,13 @@ func (s *suiteRegionRepository) TestFindDistrict() {
 			selectRegency.WillReturnError(v.MockSelectRegencyErr)
 
 			var ctx context.Context
-			res, err := s.repository.FindDistrict(&v.Body, ctx)
+			res, total, err := s.repository.FindDistrict(&v.Body, query.Options{}, ctx)
 
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(v.ExpectedRes, res)
+			if v.ExpectedErr == nil {
+				s.Equal(int64(len(v.ExpectedRes)), total)
+			}
 
 			s.TearDown()
 		})
@@ -432,7 +450,9 @@ func (s *suiteRegionRepository) TestFindVillage() {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.SetupSuite()
 
-			selectVillage := s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `villages` WHERE `villages`.`district_id` = ?"))
+			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `villages` WHERE `villages`.`district_id` = ?")).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(len(v.ExpectedRes)))
+
+			selectVillage := s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `villages` WHERE `villages`.`district_id` = ? ORDER BY `id` LIMIT 50 OFFSET 100"))
 			selectVillage.WillReturnRows(v.MockSelectVillageRes)
 			selectVillage.WillReturnError(v.MockSelectVillageErr)
 
@@ -441,10 +461,15 @@ func (s *suiteRegionRepository) TestFindVillage() {
 			selectDistrict.WillReturnError(v.MockSelectDistrictErr)
 
 			var ctx context.Context
-			res, err := s.repository.FindVillage(&v.Body, ctx)
+			opts, err := query.Parse(url.Values{"page": {"3"}, "limit": {"50"}}, dto.RegionQuery)
+			s.NoError(err)
+			res, total, err := s.repository.FindVillage(&v.Body, opts, ctx)
 
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(v.ExpectedRes, res)
+			if v.ExpectedErr == nil {
+				s.Equal(int64(len(v.ExpectedRes)), total)
+			}
 
 			s.TearDown()
 		})
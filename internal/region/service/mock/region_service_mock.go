@@ -5,6 +5,7 @@ import (
 
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/region/dto"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -32,22 +33,22 @@ func (b *RegionServiceMock) ImportVillage() error {
 	return args.Error(0)
 }
 
-func (b *RegionServiceMock) FindProvince(ctx context.Context) ([]model.Province, error) {
+func (b *RegionServiceMock) FindProvince(opts query.Options, ctx context.Context) ([]model.Province, *query.Page, error) {
 	args := b.Called()
-	return args.Get(0).([]model.Province), args.Error(1)
+	return args.Get(0).([]model.Province), args.Get(1).(*query.Page), args.Error(2)
 }
 
-func (b *RegionServiceMock) FindRegency(id *string, ctx context.Context) (dto.RegenciesResponse, error) {
+func (b *RegionServiceMock) FindRegency(id *string, opts query.Options, ctx context.Context) (dto.RegenciesResponse, *query.Page, error) {
 	args := b.Called()
-	return args.Get(0).(dto.RegenciesResponse), args.Error(1)
+	return args.Get(0).(dto.RegenciesResponse), args.Get(1).(*query.Page), args.Error(2)
 }
 
-func (b *RegionServiceMock) FindDistrict(id *string, ctx context.Context) (dto.DistrictsResponse, error) {
+func (b *RegionServiceMock) FindDistrict(id *string, opts query.Options, ctx context.Context) (dto.DistrictsResponse, *query.Page, error) {
 	args := b.Called()
-	return args.Get(0).(dto.DistrictsResponse), args.Error(1)
+	return args.Get(0).(dto.DistrictsResponse), args.Get(1).(*query.Page), args.Error(2)
 }
 
-func (b *RegionServiceMock) FindVillage(id *string, ctx context.Context) (dto.VillagesResponse, error) {
+func (b *RegionServiceMock) FindVillage(id *string, opts query.Options, ctx context.Context) (dto.VillagesResponse, *query.Page, error) {
 	args := b.Called()
-	return args.Get(0).(dto.VillagesResponse), args.Error(1)
+	return args.Get(0).(dto.VillagesResponse), args.Get(1).(*query.Page), args.Error(2)
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/region/dto"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
 type RegionService interface {
@@ -12,8 +13,8 @@ type RegionService interface {
 	ImportRegency() error
 	ImportDistrict() error
 	ImportVillage() error
-	FindProvince(ctx context.Context) ([]model.Province, error)
-	FindRegency(id *string, ctx context.Context) (dto.RegenciesResponse, error)
-	FindDistrict(id *string, ctx context.Context) (dto.DistrictsResponse, error)
-	FindVillage(id *string, ctx context.Context) (dto.VillagesResponse, error)
+	FindProvince(opts query.Options, ctx context.Context) ([]model.Province, *query.Page, error)
+	FindRegency(id *string, opts query.Options, ctx context.Context) (dto.RegenciesResponse, *query.Page, error)
+	FindDistrict(id *string, opts query.Options, ctx context.Context) (dto.DistrictsResponse, *query.Page, error)
+	FindVillage(id *string, opts query.Options, ctx context.Context) (dto.VillagesResponse, *query.Page, error)
 }
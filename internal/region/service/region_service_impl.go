@@ -10,6 +10,7 @@ import (
 	importcsv "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/import_csv"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
 type regionServiceImpl struct {
@@ -18,69 +19,69 @@ type regionServiceImpl struct {
 }
 
 // FindProvince implements RegionService
-func (r *regionServiceImpl) FindProvince(ctx context.Context) ([]model.Province, error) {
-	provinces, err := r.repo.FindProvince(ctx)
+func (r *regionServiceImpl) FindProvince(opts query.Options, ctx context.Context) ([]model.Province, *query.Page, error) {
+	provinces, total, err := r.repo.FindProvince(opts, ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return provinces, nil
+	return provinces, query.NewPage(opts, total), nil
 }
 
 // FindRegency implements RegionService
-func (r *regionServiceImpl) FindRegency(id *string, ctx context.Context) (dto.RegenciesResponse, error) {
+func (r *regionServiceImpl) FindRegency(id *string, opts query.Options, ctx context.Context) (dto.RegenciesResponse, *query.Page, error) {
 	var regency model.Regency
 	if id != nil {
 		idInt, err := strconv.Atoi(*id)
 		if err != nil {
-			return nil, customerrors.ErrInvalidId
+			return nil, nil, customerrors.ErrInvalidId
 		}
 		regency.ProvinceID = uint(idInt)
 	}
-	regenciesModel, err := r.repo.FindRegency(&regency, ctx)
+	regenciesModel, total, err := r.repo.FindRegency(&regency, opts, ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	var regencies dto.RegenciesResponse
 	regencies.FromModel(regenciesModel)
-	return regencies, nil
+	return regencies, query.NewPage(opts, total), nil
 }
 
 // FindDistrict implements RegionService
-func (r *regionServiceImpl) FindDistrict(id *string, ctx context.Context) (dto.DistrictsResponse, error) {
+func (r *regionServiceImpl) FindDistrict(id *string, opts query.Options, ctx context.Context) (dto.DistrictsResponse, *query.Page, error) {
 	var district model.District
 	if id != nil {
 		idInt, err := strconv.Atoi(*id)
 		if err != nil {
-			return nil, customerrors.ErrInvalidId
+			return nil, nil, customerrors.ErrInvalidId
 		}
 		district.RegencyID = uint(idInt)
 	}
-	districtsModel, err := r.repo.FindDistrict(&district, ctx)
+	districtsModel, total, err := r.repo.FindDistrict(&district, opts, ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	var districts dto.DistrictsResponse
 	districts.FromModel(districtsModel)
-	return districts, nil
+	return districts, query.NewPage(opts, total), nil
 }
 
 // FindVillage implements RegionService
-func (r *regionServiceImpl) FindVillage(id *string, ctx context.Context) (dto.VillagesResponse, error) {
+func (r *regionServiceImpl) FindVillage(id *string, opts query.Options, ctx context.Context) (dto.VillagesResponse, *query.Page, error) {
 	var village model.Village
 	if id != nil {
 		idInt, err := strconv.Atoi(*id)
 		if err != nil {
-			return nil, customerrors.ErrInvalidId
+			return nil, nil, customerrors.ErrInvalidId
 		}
 		village.DistrictID = uint(idInt)
 	}
-	villagesModel, err := r.repo.FindVillage(&village, ctx)
+	villagesModel, total, err := r.repo.FindVillage(&village, opts, ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	var villages dto.VillagesResponse
 	villages.FromModel(villagesModel)
-	return villages, nil
+	return villages, query.NewPage(opts, total), nil
 }
 
 // ImportProvince implements RegionService
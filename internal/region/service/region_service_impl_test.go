@@ -12,6 +12,7 @@ import (
 	importcsvMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/import_csv/mock"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -358,13 +359,16 @@ func (s *suiteRegionService) TestFindProvince() {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.SetupSuit()
 
-			s.regionRepositoryMock.On("FindProvince").Return(v.FindProvinceRes, v.FindProvinceErr)
+			s.regionRepositoryMock.On("FindProvince").Return(v.FindProvinceRes, int64(len(v.FindProvinceRes)), v.FindProvinceErr)
 
 			var ctx context.Context
-			res, err := s.regionService.FindProvince(ctx)
+			res, page, err := s.regionService.FindProvince(query.Options{Page: 1, Limit: 20}, ctx)
 
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(v.ExpectedRes, res)
+			if v.ExpectedErr == nil {
+				s.Equal(int64(len(v.FindProvinceRes)), page.Total)
+			}
 
 			s.TearDown()
 		})
@@ -448,13 +452,16 @@ func (s *suiteRegionService) TestFindRegency() {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.SetupSuit()
 
-			s.regionRepositoryMock.On("FindRegency").Return(v.FindRegencyRes, v.FindRegencyErr)
+			s.regionRepositoryMock.On("FindRegency").Return(v.FindRegencyRes, int64(len(v.FindRegencyRes)), v.FindRegencyErr)
 
 			var ctx context.Context
-			res, err := s.regionService.FindRegency(&v.ProvinceId, ctx)
+			res, page, err := s.regionService.FindRegency(&v.ProvinceId, query.Options{Page: 1, Limit: 20}, ctx)
 
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(v.ExpectedRes, res)
+			if v.ExpectedErr == nil {
+				s.Equal(int64(len(v.FindRegencyRes)), page.Total)
+			}
 
 			s.TearDown()
 		})
@@ -539,13 +546,16 @@ func (s *suiteRegionService) TestFindDistrict() {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.SetupSuit()
 
-			s.regionRepositoryMock.On("FindDistrict").Return(v.FindDistrictRes, v.FindDistrictErr)
+			s.regionRepositoryMock.On("FindDistrict").Return(v.FindDistrictRes, int64(len(v.FindDistrictRes)), v.FindDistrictErr)
 
 			var ctx context.Context
-			res, err := s.regionService.FindDistrict(&v.RegencyId, ctx)
+			res, page, err := s.regionService.FindDistrict(&v.RegencyId, query.Options{Page: 1, Limit: 20}, ctx)
 
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(v.ExpectedRes, res)
+			if v.ExpectedErr == nil {
+				s.Equal(int64(len(v.FindDistrictRes)), page.Total)
+			}
 
 			s.TearDown()
 		})
@@ -630,13 +640,16 @@ func (s *suiteRegionService) TestFindVillage() {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.SetupSuit()
 
-			s.regionRepositoryMock.On("FindVillage").Return(v.FindVillageRes, v.FindVillageErr)
+			s.regionRepositoryMock.On("FindVillage").Return(v.FindVillageRes, int64(len(v.FindVillageRes)), v.FindVillageErr)
 
 			var ctx context.Context
-			res, err := s.regionService.FindVillage(&v.DistrictId, ctx)
+			res, page, err := s.regionService.FindVillage(&v.DistrictId, query.Options{Page: 1, Limit: 20}, ctx)
 
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(v.ExpectedRes, res)
+			if v.ExpectedErr == nil {
+				s.Equal(int64(len(v.FindVillageRes)), page.Total)
+			}
 
 			s.TearDown()
 		})
@@ -0,0 +1,259 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/supplier/dto"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/supplier/service"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	_middleware "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/middleware"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+)
+
+type JWTService interface {
+	GetClaims(c *echo.Context) jwt.MapClaims
+}
+
+type supplierController struct {
+	service    service.SupplierService
+	jwtService JWTService
+}
+
+func NewSupplierController(service service.SupplierService, jwt JWTService) *supplierController {
+	return &supplierController{
+		service:    service,
+		jwtService: jwt,
+	}
+}
+
+func (u *supplierController) InitRoute(auth *echo.Group) {
+	suppliers := auth.Group("/suppliers", _middleware.RequirePermission(constants.Permission_supplier_manage))
+	suppliers.POST("", u.CreateSupplier)
+	suppliers.GET("", u.GetSuppliers)
+	suppliers.PUT("/:id", u.UpdateSupplier)
+	suppliers.GET("/:id/costs", u.GetSupplierMargins)
+
+	purchaseOrders := auth.Group("/purchase-orders", _middleware.RequirePermission(constants.Permission_supplier_manage))
+	purchaseOrders.POST("", u.CreatePurchaseOrder)
+	purchaseOrders.GET("", u.GetPurchaseOrders)
+	purchaseOrders.GET("/:id", u.GetPurchaseOrder)
+	purchaseOrders.PUT("/:id", u.UpdatePurchaseOrder)
+	purchaseOrders.PUT("/:id/send", u.SendPurchaseOrder)
+	purchaseOrders.PUT("/:id/receive", u.ReceivePurchaseOrder)
+}
+
+func (u *supplierController) CreateSupplier(c echo.Context) error {
+	var supplierBody dto.SupplierRequest
+	if err := c.Bind(&supplierBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrBadRequestBody.Error()})
+	}
+	if err := c.Validate(supplierBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	}
+	id, err := u.service.CreateSupplier(supplierBody, c.Request().Context())
+	if err != nil {
+		if err == customerrors.ErrBadRequestBody {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"message": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "new supplier success created",
+		"id":      id,
+	})
+}
+
+func (u *supplierController) GetSuppliers(c echo.Context) error {
+	opts, err := query.Parse(c.QueryParams(), dto.SupplierQuery)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	suppliers, page, err := u.service.FindSuppliers(opts, c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message":    "get supplier success",
+		"data":       suppliers,
+		"pagination": page,
+	})
+}
+
+func (u *supplierController) UpdateSupplier(c echo.Context) error {
+	var supplierBody dto.SupplierRequest
+	if err := c.Bind(&supplierBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrBadRequestBody.Error()})
+	}
+	if err := c.Validate(supplierBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	}
+	err := u.service.UpdateSupplier(c.Param("id"), supplierBody, c.Request().Context())
+	if err != nil {
+		if err == customerrors.ErrInvalidId || err == customerrors.ErrBadRequestBody {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"message": err.Error()})
+		}
+		if err == customerrors.ErrNotFound {
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"message": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "success update supplier",
+	})
+}
+
+func (u *supplierController) GetSupplierMargins(c echo.Context) error {
+	margins, err := u.service.FindSupplierMargins(c.Param("id"), c.Request().Context())
+	if err != nil {
+		if err == customerrors.ErrInvalidId {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"message": err.Error()})
+		}
+		if err == customerrors.ErrNotFound {
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"message": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "success get supplier costs",
+		"data":    margins,
+	})
+}
+
+// purchaseOrderError is response of purchase order error, referenced supplier, checkpoint
+// or item missing is bad body while missing purchase order is not found
+func purchaseOrderError(c echo.Context, err error) error {
+	if err == customerrors.ErrInvalidId || err == customerrors.ErrPurchaseOrderDetail || err == customerrors.ErrSupplierNotFound ||
+		err == customerrors.ErrCheckpointNotFound || err == customerrors.ErrItemUnavailable || err == customerrors.ErrInvalidVariant ||
+		err == customerrors.ErrVariantRequired {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	}
+	if err == customerrors.ErrNotFound {
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"message": err.Error()})
+	}
+	if err == customerrors.ErrPurchaseOrderStatus {
+		return c.JSON(http.StatusConflict, echo.Map{
+			"message": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"message": err.Error(),
+	})
+}
+
+func (u *supplierController) CreatePurchaseOrder(c echo.Context) error {
+	var orderBody dto.PurchaseOrderRequest
+	if err := c.Bind(&orderBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrBadRequestBody.Error()})
+	}
+	if err := c.Validate(orderBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	}
+	claims := u.jwtService.GetClaims(&c)
+	userId := claims["user_id"].(string)
+	id, err := u.service.CreatePurchaseOrder(orderBody, userId, c.Request().Context())
+	if err != nil {
+		return purchaseOrderError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "new purchase order success created",
+		"id":      id,
+	})
+}
+
+func (u *supplierController) GetPurchaseOrders(c echo.Context) error {
+	opts, err := query.Parse(c.QueryParams(), dto.PurchaseOrderQuery)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	orders, page, err := u.service.FindPurchaseOrders(opts, c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message":    "get purchase order success",
+		"data":       orders,
+		"pagination": page,
+	})
+}
+
+func (u *supplierController) GetPurchaseOrder(c echo.Context) error {
+	order, err := u.service.FindPurchaseOrderById(c.Param("id"), c.Request().Context())
+	if err != nil {
+		return purchaseOrderError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "get purchase order success",
+		"data":    order,
+	})
+}
+
+func (u *supplierController) UpdatePurchaseOrder(c echo.Context) error {
+	var orderBody dto.PurchaseOrderRequest
+	if err := c.Bind(&orderBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrBadRequestBody.Error()})
+	}
+	if err := c.Validate(orderBody); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error()})
+	}
+	err := u.service.UpdatePurchaseOrder(c.Param("id"), orderBody, c.Request().Context())
+	if err != nil {
+		return purchaseOrderError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "success update purchase order",
+	})
+}
+
+func (u *supplierController) SendPurchaseOrder(c echo.Context) error {
+	err := u.service.SendPurchaseOrder(c.Param("id"), c.Request().Context())
+	if err != nil {
+		return purchaseOrderError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "purchase order sent",
+	})
+}
+
+func (u *supplierController) ReceivePurchaseOrder(c echo.Context) error {
+	claims := u.jwtService.GetClaims(&c)
+	userId := claims["user_id"].(string)
+	order, err := u.service.ReceivePurchaseOrder(c.Param("id"), userId, c.Request().Context())
+	if err != nil {
+		return purchaseOrderError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "purchase order received",
+		"data":    order,
+	})
+}
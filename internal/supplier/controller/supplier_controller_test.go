@@ -0,0 +1,224 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/supplier/dto"
+	ssm "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/supplier/service/mock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	mm "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/middleware/mock"
+	vm "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/validator/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type suiteSupplierController struct {
+	suite.Suite
+	supplierServiceMock *ssm.SupplierServiceMock
+	JWTServiceMock      *mm.MockJWTService
+	validatorMock       *vm.CustomValidatorMock
+	supplierController  *supplierController
+	echoNew             *echo.Echo
+}
+
+func (s *suiteSupplierController) SetupSuit() {
+	s.supplierServiceMock = new(ssm.SupplierServiceMock)
+	s.JWTServiceMock = new(mm.MockJWTService)
+	s.validatorMock = new(vm.CustomValidatorMock)
+	s.supplierController = NewSupplierController(s.supplierServiceMock, s.JWTServiceMock)
+	s.echoNew = echo.New()
+	s.echoNew.Validator = s.validatorMock
+}
+
+func (s *suiteSupplierController) TearDown() {
+	s.supplierServiceMock = nil
+	s.JWTServiceMock = nil
+	s.validatorMock = nil
+	s.supplierController = nil
+	s.echoNew = nil
+}
+
+func (s *suiteSupplierController) TestCreatePurchaseOrder() {
+	testCase := []struct {
+		Name           string
+		Body           string
+		ExpectedStatus int
+		ExpectedResult map[string]interface{}
+		ValidatorErr   error
+		CreateErr      error
+	}{
+		{
+			Name:           "success",
+			Body:           `{"supplier_id":1,"details":[{"item_id":1,"qty":10,"cost":3000}]}`,
+			ExpectedStatus: 200,
+			ExpectedResult: map[string]interface{}{
+				"message": "new purchase order success created",
+				"id":      float64(1),
+			},
+		},
+		{
+			Name:           "bad request body",
+			Body:           `{"supplier_id":"satu"}`,
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrBadRequestBody.Error(),
+			},
+		},
+		{
+			Name:           "no detail",
+			Body:           `{"supplier_id":1}`,
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": "details is required",
+			},
+			ValidatorErr: errors.New("details is required"),
+		},
+		{
+			Name:           "invalid detail",
+			Body:           `{"supplier_id":1,"details":[{"item_id":1,"qty":10,"cost":-1}]}`,
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrPurchaseOrderDetail.Error(),
+			},
+			CreateErr: customerrors.ErrPurchaseOrderDetail,
+		},
+		{
+			Name:           "supplier not found",
+			Body:           `{"supplier_id":9,"details":[{"item_id":1,"qty":10}]}`,
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrSupplierNotFound.Error(),
+			},
+			CreateErr: customerrors.ErrSupplierNotFound,
+		},
+		{
+			Name:           "internal server error",
+			Body:           `{"supplier_id":1,"details":[{"item_id":1,"qty":10}]}`,
+			ExpectedStatus: 500,
+			ExpectedResult: map[string]interface{}{
+				"message": "internal error",
+			},
+			CreateErr: errors.New("internal error"),
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(v.Body))
+			r.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			w := httptest.NewRecorder()
+
+			ctx := s.echoNew.NewContext(r, w)
+			ctx.SetPath("/purchase-orders")
+
+			// define mock
+			s.validatorMock.On("Validate").Return(v.ValidatorErr)
+			s.JWTServiceMock.On("GetClaims").Return(jwt.MapClaims{
+				"user_id": uuid.New().String(),
+				"role_id": float64(constants.Role_admin),
+			})
+			s.supplierServiceMock.On("CreatePurchaseOrder").Return(uint(1), v.CreateErr)
+
+			err := s.supplierController.CreatePurchaseOrder(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			s.TearDown()
+		})
+	}
+}
+
+func (s *suiteSupplierController) TestReceivePurchaseOrder() {
+	testCase := []struct {
+		Name           string
+		ExpectedStatus int
+		ExpectedMsg    string
+		ReceiveErr     error
+	}{
+		{
+			Name:           "success",
+			ExpectedStatus: 200,
+			ExpectedMsg:    "purchase order received",
+		},
+		{
+			Name:           "invalid id",
+			ExpectedStatus: 400,
+			ExpectedMsg:    customerrors.ErrInvalidId.Error(),
+			ReceiveErr:     customerrors.ErrInvalidId,
+		},
+		{
+			Name:           "not found",
+			ExpectedStatus: 404,
+			ExpectedMsg:    customerrors.ErrNotFound.Error(),
+			ReceiveErr:     customerrors.ErrNotFound,
+		},
+		{
+			Name:           "not sent",
+			ExpectedStatus: 409,
+			ExpectedMsg:    customerrors.ErrPurchaseOrderStatus.Error(),
+			ReceiveErr:     customerrors.ErrPurchaseOrderStatus,
+		},
+		{
+			Name:           "internal server error",
+			ExpectedStatus: 500,
+			ExpectedMsg:    "internal error",
+			ReceiveErr:     errors.New("internal error"),
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupSuit()
+
+			r := httptest.NewRequest(http.MethodPut, "/", nil)
+			w := httptest.NewRecorder()
+
+			ctx := s.echoNew.NewContext(r, w)
+			ctx.SetPath("/purchase-orders/:id/receive")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1")
+
+			// define mock
+			s.JWTServiceMock.On("GetClaims").Return(jwt.MapClaims{
+				"user_id": uuid.New().String(),
+				"role_id": float64(constants.Role_admin),
+			})
+			s.supplierServiceMock.On("ReceivePurchaseOrder").Return(&dto.PurchaseOrderResponse{ID: 1, Status: constants.Purchase_status_received}, v.ReceiveErr)
+
+			err := s.supplierController.ReceivePurchaseOrder(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedMsg, controllerResult["message"])
+			if v.ReceiveErr == nil {
+				s.Equal(constants.Purchase_status_received, controllerResult["data"].(map[string]interface{})["status"])
+			}
+
+			s.TearDown()
+		})
+	}
+}
+
+func TestSuiteSupplierController(t *testing.T) {
+	suite.Run(t, new(suiteSupplierController))
+}
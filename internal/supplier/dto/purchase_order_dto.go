@@ -0,0 +1,144 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/quantity"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+)
+
+// PurchaseOrderQuery is sort and filter allowed on purchase order list
+var PurchaseOrderQuery = query.Schema{
+	Sort: map[string]string{
+		"created_at": "created_at",
+	},
+	DefaultSort: "-created_at",
+	Key:         "id",
+	Filter: map[string]query.Filter{
+		"status":        {Column: "status", Type: query.String, Operator: query.Equal},
+		"supplier_id":   {Column: "supplier_id", Type: query.Uint, Operator: query.Equal},
+		"checkpoint_id": {Column: "checkpoint_id", Type: query.UUID, Operator: query.Equal},
+		"from":          {Column: "created_at", Type: query.Time, Operator: query.From},
+		"to":            {Column: "created_at", Type: query.Time, Operator: query.Until},
+	},
+}
+
+// PurchaseOrderRequest is purchase order to supplier, received into the checkpoint or
+// unallocated stock when CheckpointID is empty
+type PurchaseOrderRequest struct {
+	SupplierID   uint                         `json:"supplier_id" validate:"required"`
+	CheckpointID string                       `json:"checkpoint_id"`
+	Note         string                       `json:"note"`
+	Details      []PurchaseOrderDetailRequest `json:"details" validate:"required,min=1,dive"`
+}
+
+// PurchaseOrderDetailRequest is qty and unit cost of item or variant, detail with expiry
+// date is received as stock batch
+type PurchaseOrderDetailRequest struct {
+	ItemID      uint       `json:"item_id" validate:"required"`
+	VariantID   *uint      `json:"variant_id"`
+	Qty         float64    `json:"qty" validate:"required"`
+	Cost        int        `json:"cost"`
+	HarvestedAt *time.Time `json:"harvested_at"`
+	ExpiredAt   *time.Time `json:"expired_at"`
+}
+
+// Valid check qty is positive, cost is not negative and batch expire after it is harvested
+func (u *PurchaseOrderDetailRequest) Valid() bool {
+	if quantity.Round(u.Qty) <= 0 || u.Cost < 0 {
+		return false
+	}
+	if u.HarvestedAt != nil {
+		return u.ExpiredAt != nil && u.ExpiredAt.After(*u.HarvestedAt)
+	}
+	return true
+}
+
+func (u *PurchaseOrderRequest) ToModel(checkpointId *uuid.UUID) *model.PurchaseOrder {
+	order := model.PurchaseOrder{
+		SupplierID:   u.SupplierID,
+		CheckpointID: checkpointId,
+		Note:         u.Note,
+	}
+	for _, each := range u.Details {
+		order.Details = append(order.Details, model.PurchaseOrderDetail{
+			ItemID:      each.ItemID,
+			VariantID:   each.VariantID,
+			Qty:         quantity.Round(each.Qty),
+			Cost:        each.Cost,
+			HarvestedAt: each.HarvestedAt,
+			ExpiredAt:   each.ExpiredAt,
+		})
+	}
+	return &order
+}
+
+type PurchaseOrderResponse struct {
+	ID           uint                          `json:"id"`
+	SupplierID   uint                          `json:"supplier_id"`
+	SupplierName string                        `json:"supplier_name"`
+	CheckpointID *uuid.UUID                    `json:"checkpoint_id"`
+	Status       string                        `json:"status"`
+	Note         string                        `json:"note"`
+	ActorID      *uuid.UUID                    `json:"actor_id"`
+	ReceiverID   *uuid.UUID                    `json:"receiver_id"`
+	Total        int                           `json:"total"`
+	CreatedAt    time.Time                     `json:"created_at"`
+	SentAt       *time.Time                    `json:"sent_at"`
+	ReceivedAt   *time.Time                    `json:"received_at"`
+	Details      []PurchaseOrderDetailResponse `json:"details"`
+}
+
+type PurchaseOrderDetailResponse struct {
+	ID          uint       `json:"id"`
+	ItemID      uint       `json:"item_id"`
+	ItemName    string     `json:"item_name"`
+	VariantID   *uint      `json:"variant_id"`
+	Qty         float64    `json:"qty"`
+	Cost        int        `json:"cost"`
+	Subtotal    int        `json:"subtotal"`
+	HarvestedAt *time.Time `json:"harvested_at"`
+	ExpiredAt   *time.Time `json:"expired_at"`
+}
+
+func (u *PurchaseOrderResponse) FromModel(model *model.PurchaseOrder) {
+	u.ID = model.ID
+	u.SupplierID = model.SupplierID
+	u.SupplierName = model.Supplier.Name
+	u.CheckpointID = model.CheckpointID
+	u.Status = model.Status
+	u.Note = model.Note
+	u.ActorID = model.ActorID
+	u.ReceiverID = model.ReceiverID
+	u.CreatedAt = model.CreatedAt
+	u.SentAt = model.SentAt
+	u.ReceivedAt = model.ReceivedAt
+	u.Details = []PurchaseOrderDetailResponse{}
+	for _, each := range model.Details {
+		detail := PurchaseOrderDetailResponse{
+			ID:          each.ID,
+			ItemID:      each.ItemID,
+			ItemName:    each.Item.Name,
+			VariantID:   each.VariantID,
+			Qty:         each.Qty,
+			Cost:        each.Cost,
+			Subtotal:    quantity.Total(each.Qty, each.Cost),
+			HarvestedAt: each.HarvestedAt,
+			ExpiredAt:   each.ExpiredAt,
+		}
+		u.Total += detail.Subtotal
+		u.Details = append(u.Details, detail)
+	}
+}
+
+type PurchaseOrdersResponse []PurchaseOrderResponse
+
+func (u *PurchaseOrdersResponse) FromModel(model []model.PurchaseOrder) {
+	for _, each := range model {
+		var order PurchaseOrderResponse
+		order.FromModel(&each)
+		*u = append(*u, order)
+	}
+}
@@ -0,0 +1,123 @@
+package dto
+
+import (
+	"math"
+	"time"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+)
+
+// SupplierQuery is sort and filter allowed on supplier list
+var SupplierQuery = query.Schema{
+	Sort: map[string]string{
+		"name":       "name",
+		"created_at": "created_at",
+	},
+	DefaultSort: "name",
+	Key:         "id",
+	Filter: map[string]query.Filter{
+		"name":        {Column: "name", Type: query.String, Operator: query.Contain},
+		"province_id": {Column: "province_id", Type: query.Uint, Operator: query.Equal},
+		"regency_id":  {Column: "regency_id", Type: query.Uint, Operator: query.Equal},
+		"district_id": {Column: "district_id", Type: query.Uint, Operator: query.Equal},
+		"village_id":  {Column: "village_id", Type: query.Uint, Operator: query.Equal},
+	},
+}
+
+type SupplierRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Phone       string `json:"phone"`
+	Description string `json:"description"`
+	ProvinceID  uint   `json:"province_id" validate:"required"`
+	RegencyID   uint   `json:"regency_id" validate:"required"`
+	DistrictID  uint   `json:"district_id" validate:"required"`
+	VillageID   uint   `json:"village_id" validate:"required"`
+}
+
+func (u *SupplierRequest) ToModel() *model.Supplier {
+	return &model.Supplier{
+		Name:        u.Name,
+		Phone:       u.Phone,
+		Description: u.Description,
+		ProvinceID:  u.ProvinceID,
+		RegencyID:   u.RegencyID,
+		DistrictID:  u.DistrictID,
+		VillageID:   u.VillageID,
+	}
+}
+
+type SupplierResponse struct {
+	ID           uint      `json:"id"`
+	Name         string    `json:"name"`
+	Phone        string    `json:"phone"`
+	Description  string    `json:"description"`
+	ProvinceName string    `json:"province_name"`
+	RegencyName  string    `json:"regency_name"`
+	DistrictName string    `json:"district_name"`
+	VillageName  string    `json:"village_name"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (u *SupplierResponse) FromModel(model *model.Supplier) {
+	u.ID = model.ID
+	u.Name = model.Name
+	u.Phone = model.Phone
+	u.Description = model.Description
+	u.ProvinceName = model.Province.Name
+	u.RegencyName = model.Regency.Name
+	u.DistrictName = model.District.Name
+	u.VillageName = model.Village.Name
+	u.CreatedAt = model.CreatedAt
+}
+
+type SuppliersResponse []SupplierResponse
+
+func (u *SuppliersResponse) FromModel(model []model.Supplier) {
+	for _, each := range model {
+		var supplier SupplierResponse
+		supplier.FromModel(&each)
+		*u = append(*u, supplier)
+	}
+}
+
+// SupplierMarginResponse is last cost of item or variant from supplier against its selling
+// price. MarginPercent is margin of selling price, 0 when item has no price
+type SupplierMarginResponse struct {
+	ItemID        uint      `json:"item_id"`
+	VariantID     *uint     `json:"variant_id"`
+	ItemName      string    `json:"item_name"`
+	VariantName   string    `json:"variant_name"`
+	Cost          int       `json:"cost"`
+	Price         int       `json:"price"`
+	Margin        int       `json:"margin"`
+	MarginPercent float64   `json:"margin_percent"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (u *SupplierMarginResponse) FromModel(model *model.SupplierMargin) {
+	u.ItemID = model.ItemID
+	if model.VariantID != 0 {
+		variantId := model.VariantID
+		u.VariantID = &variantId
+	}
+	u.ItemName = model.ItemName
+	u.VariantName = model.VariantName
+	u.Cost = model.Cost
+	u.Price = model.Price
+	u.Margin = model.Price - model.Cost
+	if model.Price > 0 {
+		u.MarginPercent = math.Round(float64(u.Margin)*10000/float64(model.Price)) / 100
+	}
+	u.UpdatedAt = model.UpdatedAt
+}
+
+type SupplierMarginsResponse []SupplierMarginResponse
+
+func (u *SupplierMarginsResponse) FromModel(model []model.SupplierMargin) {
+	for _, each := range model {
+		var margin SupplierMarginResponse
+		margin.FromModel(&each)
+		*u = append(*u, margin)
+	}
+}
@@ -0,0 +1,73 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"github.com/stretchr/testify/mock"
+)
+
+type SupplierRepositoryMock struct {
+	mock.Mock
+}
+
+func (b *SupplierRepositoryMock) CreateSupplier(supplier *model.Supplier, ctx context.Context) error {
+	args := b.Called()
+
+	return args.Error(0)
+}
+
+func (b *SupplierRepositoryMock) FindSuppliers(opts query.Options, ctx context.Context) ([]model.Supplier, int64, error) {
+	args := b.Called()
+
+	return args.Get(0).([]model.Supplier), args.Get(1).(int64), args.Error(2)
+}
+
+func (b *SupplierRepositoryMock) UpdateSupplier(supplier *model.Supplier, ctx context.Context) error {
+	args := b.Called(supplier)
+
+	return args.Error(0)
+}
+
+func (b *SupplierRepositoryMock) FindSupplierMargins(supplierId uint, ctx context.Context) ([]model.SupplierMargin, error) {
+	args := b.Called(supplierId)
+
+	return args.Get(0).([]model.SupplierMargin), args.Error(1)
+}
+
+func (b *SupplierRepositoryMock) CreatePurchaseOrder(order *model.PurchaseOrder, ctx context.Context) error {
+	args := b.Called(order)
+
+	return args.Error(0)
+}
+
+func (b *SupplierRepositoryMock) FindPurchaseOrders(opts query.Options, ctx context.Context) ([]model.PurchaseOrder, int64, error) {
+	args := b.Called()
+
+	return args.Get(0).([]model.PurchaseOrder), args.Get(1).(int64), args.Error(2)
+}
+
+func (b *SupplierRepositoryMock) FindPurchaseOrderById(order *model.PurchaseOrder, ctx context.Context) error {
+	args := b.Called(order)
+
+	return args.Error(0)
+}
+
+func (b *SupplierRepositoryMock) UpdatePurchaseOrder(order *model.PurchaseOrder, ctx context.Context) error {
+	args := b.Called(order)
+
+	return args.Error(0)
+}
+
+func (b *SupplierRepositoryMock) SendPurchaseOrder(id uint, ctx context.Context) error {
+	args := b.Called(id)
+
+	return args.Error(0)
+}
+
+func (b *SupplierRepositoryMock) ReceivePurchaseOrder(order *model.PurchaseOrder, ctx context.Context) error {
+	args := b.Called(order)
+
+	return args.Error(0)
+}
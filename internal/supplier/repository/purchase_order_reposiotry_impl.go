@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/stock"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// preloadPurchaseOrder load supplier and item of purchase order, archived ones too
+func preloadPurchaseOrder(tx *gorm.DB) *gorm.DB {
+	unscoped := func(db *gorm.DB) *gorm.DB { return db.Unscoped() }
+	return tx.Preload("Supplier", unscoped).Preload("Details", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).Preload("Details.Item", unscoped)
+}
+
+// checkPurchaseOrder check supplier, checkpoint and every ordered item and variant exist.
+// Archived item is no longer bought
+func checkPurchaseOrder(tx *gorm.DB, order *model.PurchaseOrder) error {
+	var suppliers int64
+	if err := tx.Model(&model.Supplier{}).Where("id = ?", order.SupplierID).Count(&suppliers).Error; err != nil {
+		return err
+	}
+	if suppliers == 0 {
+		return customerrors.ErrSupplierNotFound
+	}
+	if order.CheckpointID != nil {
+		var checkpoints int64
+		if err := tx.Model(&model.Checkpoint{}).Where("id = ?", *order.CheckpointID).Count(&checkpoints).Error; err != nil {
+			return err
+		}
+		if checkpoints == 0 {
+			return customerrors.ErrCheckpointNotFound
+		}
+	}
+	for _, detail := range order.Details {
+		var item model.Item
+		if err := tx.Select("id").First(&item, detail.ItemID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return customerrors.ErrItemUnavailable
+			}
+			return err
+		}
+		query := tx.Model(&model.ItemVariant{}).Where("item_id = ?", detail.ItemID)
+		if detail.VariantID != nil {
+			query = query.Where("id = ?", *detail.VariantID)
+		}
+		var variants int64
+		if err := query.Count(&variants).Error; err != nil {
+			return err
+		}
+		if detail.VariantID != nil && variants == 0 {
+			return customerrors.ErrInvalidVariant
+		}
+		if detail.VariantID == nil && variants != 0 {
+			return customerrors.ErrVariantRequired
+		}
+	}
+	return nil
+}
+
+// purchaseOrderStatusError tell purchase order not updated by status guard is missing or in other status
+func purchaseOrderStatusError(tx *gorm.DB, id uint) error {
+	var orders int64
+	if err := tx.Model(&model.PurchaseOrder{}).Where("id = ?", id).Count(&orders).Error; err != nil {
+		return err
+	}
+	if orders == 0 {
+		return customerrors.ErrNotFound
+	}
+	return customerrors.ErrPurchaseOrderStatus
+}
+
+// CreatePurchaseOrder implements SupplierRepository, new purchase order is draft
+func (r *supplierRepositoryImpl) CreatePurchaseOrder(order *model.PurchaseOrder, ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkPurchaseOrder(tx, order); err != nil {
+			return err
+		}
+		order.Status = constants.Purchase_status_draft
+		return tx.Create(order).Error
+	})
+}
+
+// FindPurchaseOrders implements SupplierRepository
+func (r *supplierRepositoryImpl) FindPurchaseOrders(opts query.Options, ctx context.Context) ([]model.PurchaseOrder, int64, error) {
+	var orders []model.PurchaseOrder
+	total, err := query.Find(preloadPurchaseOrder(r.db.WithContext(ctx).Model(&model.PurchaseOrder{})), opts, &orders)
+	if err != nil {
+		return nil, 0, err
+	}
+	return orders, total, nil
+}
+
+// FindPurchaseOrderById implements SupplierRepository
+func (r *supplierRepositoryImpl) FindPurchaseOrderById(order *model.PurchaseOrder, ctx context.Context) error {
+	err := preloadPurchaseOrder(r.db.WithContext(ctx)).First(order, order.ID).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return customerrors.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// UpdatePurchaseOrder implements SupplierRepository, supplier, checkpoint, note and details of
+// draft are replaced
+func (r *supplierRepositoryImpl) UpdatePurchaseOrder(order *model.PurchaseOrder, ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkPurchaseOrder(tx, order); err != nil {
+			return err
+		}
+		res := tx.Model(&model.PurchaseOrder{}).Where("id = ? AND status = ?", order.ID, constants.Purchase_status_draft).Updates(map[string]interface{}{
+			"supplier_id":   order.SupplierID,
+			"checkpoint_id": order.CheckpointID,
+			"note":          order.Note,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return purchaseOrderStatusError(tx, order.ID)
+		}
+		if err := tx.Where("purchase_order_id = ?", order.ID).Delete(&model.PurchaseOrderDetail{}).Error; err != nil {
+			return err
+		}
+		for i := range order.Details {
+			order.Details[i].PurchaseOrderID = order.ID
+		}
+		return tx.Omit("Item").Create(&order.Details).Error
+	})
+}
+
+// SendPurchaseOrder implements SupplierRepository, only draft can be sent
+func (r *supplierRepositoryImpl) SendPurchaseOrder(id uint, ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.PurchaseOrder{}).Where("id = ? AND status = ?", id, constants.Purchase_status_draft).Updates(map[string]interface{}{
+			"status":  constants.Purchase_status_sent,
+			"sent_at": time.Now(),
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return purchaseOrderStatusError(tx, id)
+		}
+		return nil
+	})
+}
+
+// ReceivePurchaseOrder implements SupplierRepository, sent purchase order is received by
+// ReceiverID. Every detail is added to stock as purchase, detail with expiry date as new
+// batch of the supplier, and its cost become last cost of the supplier. Status is guarded
+// first, so purchase order received twice at the same time only add stock once
+func (r *supplierRepositoryImpl) ReceivePurchaseOrder(order *model.PurchaseOrder, ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.PurchaseOrder{}).Where("id = ? AND status = ?", order.ID, constants.Purchase_status_sent).Updates(map[string]interface{}{
+			"status":      constants.Purchase_status_received,
+			"received_at": time.Now(),
+			"receiver_id": order.ReceiverID,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return purchaseOrderStatusError(tx, order.ID)
+		}
+		if err := preloadPurchaseOrder(tx).First(order, order.ID).Error; err != nil {
+			return err
+		}
+		for _, detail := range order.Details {
+			move := model.StockMovement{
+				ItemID:       detail.ItemID,
+				VariantID:    detail.VariantID,
+				CheckpointID: order.CheckpointID,
+				Qty:          detail.Qty,
+				Reason:       constants.Stock_reason_purchase,
+				ActorID:      order.ReceiverID,
+				Note:         fmt.Sprintf("purchase order %d", order.ID),
+			}
+			if detail.ExpiredAt != nil {
+				supplierId := order.SupplierID
+				move.Batches = []model.StockBatchMove{{Qty: detail.Qty, Batch: &model.StockBatch{
+					Supplier:    order.Supplier.Name,
+					SupplierID:  &supplierId,
+					HarvestedAt: detail.HarvestedAt,
+					ExpiredAt:   *detail.ExpiredAt,
+				}}}
+			}
+			if err := stock.Move(tx, &move); err != nil {
+				return err
+			}
+			cost := model.SupplierCost{SupplierID: order.SupplierID, ItemID: detail.ItemID, Cost: detail.Cost}
+			if detail.VariantID != nil {
+				cost.VariantID = *detail.VariantID
+			}
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "supplier_id"}, {Name: "item_id"}, {Name: "variant_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"cost", "updated_at"}),
+			}).Create(&cost).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"gorm.io/gorm"
+)
+
+type supplierRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// CreateSupplier implements SupplierRepository
+func (r *supplierRepositoryImpl) CreateSupplier(supplier *model.Supplier, ctx context.Context) error {
+	return r.db.WithContext(ctx).Create(supplier).Error
+}
+
+// FindSuppliers implements SupplierRepository
+func (r *supplierRepositoryImpl) FindSuppliers(opts query.Options, ctx context.Context) ([]model.Supplier, int64, error) {
+	var suppliers []model.Supplier
+	total, err := query.Find(r.db.WithContext(ctx).Model(&model.Supplier{}).Preload("Province").Preload("Regency").Preload("District").Preload("Village"), opts, &suppliers)
+	if err != nil {
+		return nil, 0, err
+	}
+	return suppliers, total, nil
+}
+
+// UpdateSupplier implements SupplierRepository, every field is saved so field can be emptied
+func (r *supplierRepositoryImpl) UpdateSupplier(supplier *model.Supplier, ctx context.Context) error {
+	res := r.db.WithContext(ctx).Model(&model.Supplier{ID: supplier.ID}).
+		Select("name", "phone", "description", "province_id", "regency_id", "district_id", "village_id").
+		Updates(supplier)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return customerrors.ErrNotFound
+	}
+	return nil
+}
+
+// FindSupplierMargins implements SupplierRepository, last cost of every item and variant bought
+// from the supplier with current price of the item or variant
+func (r *supplierRepositoryImpl) FindSupplierMargins(supplierId uint, ctx context.Context) ([]model.SupplierMargin, error) {
+	err := r.db.WithContext(ctx).Select("id").First(&model.Supplier{}, supplierId).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, customerrors.ErrNotFound
+		}
+		return nil, err
+	}
+	var margins []model.SupplierMargin
+	err = r.db.WithContext(ctx).Table("supplier_costs").
+		Select("supplier_costs.*, items.name AS item_name, COALESCE(item_variants.name, '') AS variant_name, COALESCE(item_variants.price, items.price) AS price").
+		Joins("JOIN items ON items.id = supplier_costs.item_id").
+		Joins("LEFT JOIN item_variants ON item_variants.id = supplier_costs.variant_id").
+		Where("supplier_costs.supplier_id = ?", supplierId).
+		Order("items.name, supplier_costs.variant_id").
+		Scan(&margins).Error
+	if err != nil {
+		return nil, err
+	}
+	return margins, nil
+}
+
+func NewSupplierRepository(db *gorm.DB) SupplierRepository {
+	return &supplierRepositoryImpl{
+		db: db,
+	}
+}
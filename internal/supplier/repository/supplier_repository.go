@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+)
+
+type SupplierRepository interface {
+	CreateSupplier(supplier *model.Supplier, ctx context.Context) error
+	FindSuppliers(opts query.Options, ctx context.Context) ([]model.Supplier, int64, error)
+	UpdateSupplier(supplier *model.Supplier, ctx context.Context) error
+	FindSupplierMargins(supplierId uint, ctx context.Context) ([]model.SupplierMargin, error)
+	CreatePurchaseOrder(order *model.PurchaseOrder, ctx context.Context) error
+	FindPurchaseOrders(opts query.Options, ctx context.Context) ([]model.PurchaseOrder, int64, error)
+	FindPurchaseOrderById(order *model.PurchaseOrder, ctx context.Context) error
+	UpdatePurchaseOrder(order *model.PurchaseOrder, ctx context.Context) error
+	SendPurchaseOrder(id uint, ctx context.Context) error
+	ReceivePurchaseOrder(order *model.PurchaseOrder, ctx context.Context) error
+}
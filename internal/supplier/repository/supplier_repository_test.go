@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/database/testdb"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupplier(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	repository := NewSupplierRepository(db)
+
+	tani := model.Supplier{Name: "tani makmur", Phone: "0812"}
+	assert.NoError(t, repository.CreateSupplier(&tani, ctx))
+	assert.NoError(t, repository.CreateSupplier(&model.Supplier{Name: "kebun sari"}, ctx))
+
+	// every field is saved, phone can be emptied
+	assert.NoError(t, repository.UpdateSupplier(&model.Supplier{ID: tani.ID, Name: "tani jaya"}, ctx))
+	assert.Equal(t, customerrors.ErrNotFound, repository.UpdateSupplier(&model.Supplier{ID: 99, Name: "tani"}, ctx))
+
+	suppliers, total, err := repository.FindSuppliers(query.Options{Page: 1, Limit: 20, Sort: "name", Key: "id"}, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, []string{"kebun sari", "tani jaya"}, []string{suppliers[0].Name, suppliers[1].Name})
+	assert.Empty(t, suppliers[1].Phone)
+}
+
+func TestPurchaseOrder(t *testing.T) {
+	db := testdb.New(t)
+	ctx := context.Background()
+	actorId := uuid.New()
+	pasar := model.Checkpoint{ID: uuid.New(), Name: "pasar"}
+	assert.NoError(t, db.Create(&pasar).Error)
+	bayam := model.Item{Name: "bayam", Price: 5000}
+	cabai := model.Item{Name: "cabai", Price: 40000, Variants: []model.ItemVariant{{Name: "merah", Price: 50000}}}
+	assert.NoError(t, db.Create(&[]*model.Item{&bayam, &cabai}).Error)
+	merah := cabai.Variants[0]
+	repository := NewSupplierRepository(db)
+	tani := model.Supplier{Name: "tani makmur"}
+	assert.NoError(t, repository.CreateSupplier(&tani, ctx))
+
+	// ordered item and variant must exist
+	expiredAt := time.Now().AddDate(0, 0, 3)
+	order := model.PurchaseOrder{SupplierID: tani.ID, CheckpointID: &pasar.ID, ActorID: &actorId, Details: []model.PurchaseOrderDetail{
+		{ItemID: bayam.ID, Qty: 10, Cost: 3000, ExpiredAt: &expiredAt},
+		{ItemID: cabai.ID, Qty: 2.5, Cost: 30000},
+	}}
+	assert.Equal(t, customerrors.ErrVariantRequired, repository.CreatePurchaseOrder(&order, ctx))
+	order.Details[1].VariantID = &merah.ID
+	assert.Equal(t, customerrors.ErrSupplierNotFound, repository.CreatePurchaseOrder(&model.PurchaseOrder{SupplierID: 99}, ctx))
+	assert.NoError(t, repository.CreatePurchaseOrder(&order, ctx))
+	assert.Equal(t, constants.Purchase_status_draft, order.Status)
+
+	// draft is replaced, cabai cost is changed
+	order.Details[1].Cost = 35000
+	assert.NoError(t, repository.UpdatePurchaseOrder(&model.PurchaseOrder{ID: order.ID, SupplierID: tani.ID, CheckpointID: &pasar.ID, Note: "pagi",
+		Details: []model.PurchaseOrderDetail{order.Details[0], order.Details[1]}}, ctx))
+
+	// only sent purchase order can be received, only draft can be sent
+	assert.Equal(t, customerrors.ErrPurchaseOrderStatus, repository.ReceivePurchaseOrder(&model.PurchaseOrder{ID: order.ID, ReceiverID: &actorId}, ctx))
+	assert.NoError(t, repository.SendPurchaseOrder(order.ID, ctx))
+	assert.Equal(t, customerrors.ErrPurchaseOrderStatus, repository.SendPurchaseOrder(order.ID, ctx))
+	assert.Equal(t, customerrors.ErrPurchaseOrderStatus, repository.UpdatePurchaseOrder(&model.PurchaseOrder{ID: order.ID, SupplierID: tani.ID}, ctx))
+	assert.Equal(t, customerrors.ErrNotFound, repository.SendPurchaseOrder(99, ctx))
+
+	received := model.PurchaseOrder{ID: order.ID, ReceiverID: &actorId}
+	assert.NoError(t, repository.ReceivePurchaseOrder(&received, ctx))
+	assert.Equal(t, constants.Purchase_status_received, received.Status)
+	assert.Equal(t, "pagi", received.Note)
+	assert.Equal(t, "tani makmur", received.Supplier.Name)
+	assert.Len(t, received.Details, 2)
+	assert.Equal(t, customerrors.ErrPurchaseOrderStatus, repository.ReceivePurchaseOrder(&model.PurchaseOrder{ID: order.ID, ReceiverID: &actorId}, ctx))
+
+	// stock is added to the checkpoint, bayam as batch of the supplier
+	var stocks []model.CheckpointStock
+	assert.NoError(t, db.Where("checkpoint_id = ?", pasar.ID).Order("item_id").Find(&stocks).Error)
+	assert.Equal(t, []float64{10, 2.5}, []float64{stocks[0].Qty, stocks[1].Qty})
+	var batch model.StockBatch
+	assert.NoError(t, db.First(&batch).Error)
+	assert.Equal(t, &tani.ID, batch.SupplierID)
+	assert.Equal(t, "tani makmur", batch.Supplier)
+	assert.Equal(t, 10.0, batch.Qty)
+	var moves []model.StockMovement
+	assert.NoError(t, db.Order("id").Find(&moves).Error)
+	assert.Len(t, moves, 2)
+	assert.Equal(t, constants.Stock_reason_purchase, moves[0].Reason)
+	assert.Equal(t, &actorId, moves[0].ActorID)
+
+	margins, err := repository.FindSupplierMargins(tani.ID, ctx)
+	assert.NoError(t, err)
+	assert.Len(t, margins, 2)
+	assert.Equal(t, "bayam", margins[0].ItemName)
+	assert.Equal(t, []int{3000, 5000}, []int{margins[0].Cost, margins[0].Price})
+	assert.Equal(t, "merah", margins[1].VariantName)
+	assert.Equal(t, []int{35000, 50000}, []int{margins[1].Cost, margins[1].Price})
+	_, err = repository.FindSupplierMargins(99, ctx)
+	assert.Equal(t, customerrors.ErrNotFound, err)
+
+	found := model.PurchaseOrder{ID: order.ID}
+	assert.NoError(t, repository.FindPurchaseOrderById(&found, ctx))
+	assert.Equal(t, "bayam", found.Details[0].Item.Name)
+	orders, total, err := repository.FindPurchaseOrders(query.Options{Page: 1, Limit: 20, Sort: "created_at", Key: "id"}, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, received.ID, orders[0].ID)
+}
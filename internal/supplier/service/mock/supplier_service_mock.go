@@ -0,0 +1,63 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/supplier/dto"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"github.com/stretchr/testify/mock"
+)
+
+type SupplierServiceMock struct {
+	mock.Mock
+}
+
+func (b *SupplierServiceMock) CreateSupplier(body dto.SupplierRequest, ctx context.Context) (uint, error) {
+	args := b.Called()
+	return args.Get(0).(uint), args.Error(1)
+}
+
+func (b *SupplierServiceMock) FindSuppliers(opts query.Options, ctx context.Context) (dto.SuppliersResponse, *query.Page, error) {
+	args := b.Called()
+	return args.Get(0).(dto.SuppliersResponse), args.Get(1).(*query.Page), args.Error(2)
+}
+
+func (b *SupplierServiceMock) UpdateSupplier(supplierId string, body dto.SupplierRequest, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *SupplierServiceMock) FindSupplierMargins(supplierId string, ctx context.Context) (dto.SupplierMarginsResponse, error) {
+	args := b.Called()
+	return args.Get(0).(dto.SupplierMarginsResponse), args.Error(1)
+}
+
+func (b *SupplierServiceMock) CreatePurchaseOrder(body dto.PurchaseOrderRequest, userId string, ctx context.Context) (uint, error) {
+	args := b.Called()
+	return args.Get(0).(uint), args.Error(1)
+}
+
+func (b *SupplierServiceMock) FindPurchaseOrders(opts query.Options, ctx context.Context) (dto.PurchaseOrdersResponse, *query.Page, error) {
+	args := b.Called()
+	return args.Get(0).(dto.PurchaseOrdersResponse), args.Get(1).(*query.Page), args.Error(2)
+}
+
+func (b *SupplierServiceMock) FindPurchaseOrderById(purchaseOrderId string, ctx context.Context) (*dto.PurchaseOrderResponse, error) {
+	args := b.Called()
+	return args.Get(0).(*dto.PurchaseOrderResponse), args.Error(1)
+}
+
+func (b *SupplierServiceMock) UpdatePurchaseOrder(purchaseOrderId string, body dto.PurchaseOrderRequest, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *SupplierServiceMock) SendPurchaseOrder(purchaseOrderId string, ctx context.Context) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *SupplierServiceMock) ReceivePurchaseOrder(purchaseOrderId string, userId string, ctx context.Context) (*dto.PurchaseOrderResponse, error) {
+	args := b.Called()
+	return args.Get(0).(*dto.PurchaseOrderResponse), args.Error(1)
+}
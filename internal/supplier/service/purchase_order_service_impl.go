@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/supplier/dto"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+)
+
+// newPurchaseOrder check detail of purchase order request and parse its checkpoint,
+// empty checkpoint is received into unallocated stock
+func newPurchaseOrder(body dto.PurchaseOrderRequest) (*model.PurchaseOrder, error) {
+	for _, detail := range body.Details {
+		if !detail.Valid() {
+			return nil, customerrors.ErrPurchaseOrderDetail
+		}
+	}
+	if body.CheckpointID == "" {
+		return body.ToModel(nil), nil
+	}
+	checkpointId, err := uuid.Parse(body.CheckpointID)
+	if err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	return body.ToModel(&checkpointId), nil
+}
+
+// CreatePurchaseOrder implements SupplierService
+func (s *supplierServiceImpl) CreatePurchaseOrder(body dto.PurchaseOrderRequest, userId string, ctx context.Context) (uint, error) {
+	actorId, err := uuid.Parse(userId)
+	if err != nil {
+		return 0, customerrors.ErrInvalidId
+	}
+	order, err := newPurchaseOrder(body)
+	if err != nil {
+		return 0, err
+	}
+	order.ActorID = &actorId
+	if err := s.repo.CreatePurchaseOrder(order, ctx); err != nil {
+		return 0, err
+	}
+	return order.ID, nil
+}
+
+// FindPurchaseOrders implements SupplierService
+func (s *supplierServiceImpl) FindPurchaseOrders(opts query.Options, ctx context.Context) (dto.PurchaseOrdersResponse, *query.Page, error) {
+	orders, total, err := s.repo.FindPurchaseOrders(opts, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var ordersResponse dto.PurchaseOrdersResponse
+	ordersResponse.FromModel(orders)
+	return ordersResponse, query.NewPage(opts, total), nil
+}
+
+// FindPurchaseOrderById implements SupplierService
+func (s *supplierServiceImpl) FindPurchaseOrderById(purchaseOrderId string, ctx context.Context) (*dto.PurchaseOrderResponse, error) {
+	id, err := strconv.Atoi(purchaseOrderId)
+	if err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	order := model.PurchaseOrder{ID: uint(id)}
+	if err := s.repo.FindPurchaseOrderById(&order, ctx); err != nil {
+		return nil, err
+	}
+	var res dto.PurchaseOrderResponse
+	res.FromModel(&order)
+	return &res, nil
+}
+
+// UpdatePurchaseOrder implements SupplierService
+func (s *supplierServiceImpl) UpdatePurchaseOrder(purchaseOrderId string, body dto.PurchaseOrderRequest, ctx context.Context) error {
+	id, err := strconv.Atoi(purchaseOrderId)
+	if err != nil {
+		return customerrors.ErrInvalidId
+	}
+	order, err := newPurchaseOrder(body)
+	if err != nil {
+		return err
+	}
+	order.ID = uint(id)
+	return s.repo.UpdatePurchaseOrder(order, ctx)
+}
+
+// SendPurchaseOrder implements SupplierService
+func (s *supplierServiceImpl) SendPurchaseOrder(purchaseOrderId string, ctx context.Context) error {
+	id, err := strconv.Atoi(purchaseOrderId)
+	if err != nil {
+		return customerrors.ErrInvalidId
+	}
+	return s.repo.SendPurchaseOrder(uint(id), ctx)
+}
+
+// ReceivePurchaseOrder implements SupplierService
+func (s *supplierServiceImpl) ReceivePurchaseOrder(purchaseOrderId string, userId string, ctx context.Context) (*dto.PurchaseOrderResponse, error) {
+	id, err := strconv.Atoi(purchaseOrderId)
+	if err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	receiverId, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	order := model.PurchaseOrder{ID: uint(id), ReceiverID: &receiverId}
+	if err := s.repo.ReceivePurchaseOrder(&order, ctx); err != nil {
+		return nil, err
+	}
+	var res dto.PurchaseOrderResponse
+	res.FromModel(&order)
+	return &res, nil
+}
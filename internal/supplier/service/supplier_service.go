@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/supplier/dto"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+)
+
+type SupplierService interface {
+	CreateSupplier(body dto.SupplierRequest, ctx context.Context) (uint, error)
+	FindSuppliers(opts query.Options, ctx context.Context) (dto.SuppliersResponse, *query.Page, error)
+	UpdateSupplier(supplierId string, body dto.SupplierRequest, ctx context.Context) error
+	FindSupplierMargins(supplierId string, ctx context.Context) (dto.SupplierMarginsResponse, error)
+	CreatePurchaseOrder(body dto.PurchaseOrderRequest, userId string, ctx context.Context) (uint, error)
+	FindPurchaseOrders(opts query.Options, ctx context.Context) (dto.PurchaseOrdersResponse, *query.Page, error)
+	FindPurchaseOrderById(purchaseOrderId string, ctx context.Context) (*dto.PurchaseOrderResponse, error)
+	UpdatePurchaseOrder(purchaseOrderId string, body dto.PurchaseOrderRequest, ctx context.Context) error
+	SendPurchaseOrder(purchaseOrderId string, ctx context.Context) error
+	ReceivePurchaseOrder(purchaseOrderId string, userId string, ctx context.Context) (*dto.PurchaseOrderResponse, error)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/supplier/dto"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/supplier/repository"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+)
+
+type supplierServiceImpl struct {
+	repo repository.SupplierRepository
+}
+
+// CreateSupplier implements SupplierService
+func (s *supplierServiceImpl) CreateSupplier(body dto.SupplierRequest, ctx context.Context) (uint, error) {
+	supplier := body.ToModel()
+	err := s.repo.CreateSupplier(supplier, ctx)
+	if err != nil {
+		if strings.Contains(err.Error(), "Cannot add or update a child row") {
+			return 0, customerrors.ErrBadRequestBody
+		}
+		return 0, err
+	}
+	return supplier.ID, nil
+}
+
+// FindSuppliers implements SupplierService
+func (s *supplierServiceImpl) FindSuppliers(opts query.Options, ctx context.Context) (dto.SuppliersResponse, *query.Page, error) {
+	suppliers, total, err := s.repo.FindSuppliers(opts, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var suppliersResponse dto.SuppliersResponse
+	suppliersResponse.FromModel(suppliers)
+	return suppliersResponse, query.NewPage(opts, total), nil
+}
+
+// UpdateSupplier implements SupplierService
+func (s *supplierServiceImpl) UpdateSupplier(supplierId string, body dto.SupplierRequest, ctx context.Context) error {
+	id, err := strconv.Atoi(supplierId)
+	if err != nil {
+		return customerrors.ErrInvalidId
+	}
+	supplier := body.ToModel()
+	supplier.ID = uint(id)
+	err = s.repo.UpdateSupplier(supplier, ctx)
+	if err != nil {
+		if strings.Contains(err.Error(), "Cannot add or update a child row") {
+			return customerrors.ErrBadRequestBody
+		}
+		return err
+	}
+	return nil
+}
+
+// FindSupplierMargins implements SupplierService
+func (s *supplierServiceImpl) FindSupplierMargins(supplierId string, ctx context.Context) (dto.SupplierMarginsResponse, error) {
+	id, err := strconv.Atoi(supplierId)
+	if err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	margins, err := s.repo.FindSupplierMargins(uint(id), ctx)
+	if err != nil {
+		return nil, err
+	}
+	marginsResponse := dto.SupplierMarginsResponse{}
+	marginsResponse.FromModel(margins)
+	return marginsResponse, nil
+}
+
+func NewSupplierService(repository repository.SupplierRepository) SupplierService {
+	return &supplierServiceImpl{
+		repo: repository,
+	}
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/supplier/dto"
+	_supplierRepositoryMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/supplier/repository/mock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type suiteSupplierService struct {
+	suite.Suite
+	supplierRepositoryMock *_supplierRepositoryMock.SupplierRepositoryMock
+	supplierService        SupplierService
+}
+
+func (s *suiteSupplierService) SetupTest() {
+	s.supplierRepositoryMock = new(_supplierRepositoryMock.SupplierRepositoryMock)
+	s.supplierService = NewSupplierService(s.supplierRepositoryMock)
+}
+
+func (s *suiteSupplierService) TestUpdateSupplier() {
+	testCase := []struct {
+		Name        string
+		SupplierID  string
+		ExpectedErr error
+		MockErr     error
+	}{
+		{
+			Name:       "success",
+			SupplierID: "1",
+		},
+		{
+			Name:        "invalid id",
+			SupplierID:  "satu",
+			ExpectedErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:        "region not found",
+			SupplierID:  "1",
+			ExpectedErr: customerrors.ErrBadRequestBody,
+			MockErr:     errors.New("Error 1452: Cannot add or update a child row"),
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupTest()
+			s.supplierRepositoryMock.On("UpdateSupplier", mock.Anything).Return(v.MockErr)
+
+			err := s.supplierService.UpdateSupplier(v.SupplierID, dto.SupplierRequest{Name: "tani"}, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			if v.SupplierID == "1" {
+				s.supplierRepositoryMock.AssertCalled(t, "UpdateSupplier", &model.Supplier{ID: 1, Name: "tani"})
+			}
+		})
+	}
+}
+
+func (s *suiteSupplierService) TestFindSupplierMargins() {
+	variantId := uint(2)
+	s.supplierRepositoryMock.On("FindSupplierMargins", uint(1)).Return([]model.SupplierMargin{
+		{SupplierCost: model.SupplierCost{ItemID: 1, Cost: 3000}, ItemName: "bayam", Price: 4000},
+		{SupplierCost: model.SupplierCost{ItemID: 2, VariantID: variantId, Cost: 35000}, ItemName: "cabai", VariantName: "merah", Price: 30000},
+		{SupplierCost: model.SupplierCost{ItemID: 3, Cost: 1000}, ItemName: "daun", Price: 0},
+	}, nil)
+
+	margins, err := s.supplierService.FindSupplierMargins("1", context.Background())
+
+	s.NoError(err)
+	s.Equal(dto.SupplierMarginsResponse{
+		{ItemID: 1, ItemName: "bayam", Cost: 3000, Price: 4000, Margin: 1000, MarginPercent: 25},
+		{ItemID: 2, VariantID: &variantId, ItemName: "cabai", VariantName: "merah", Cost: 35000, Price: 30000, Margin: -5000, MarginPercent: -16.67},
+		{ItemID: 3, ItemName: "daun", Cost: 1000, Margin: -1000},
+	}, margins)
+
+	_, err = s.supplierService.FindSupplierMargins("satu", context.Background())
+	s.Equal(customerrors.ErrInvalidId, err)
+}
+
+func (s *suiteSupplierService) TestCreatePurchaseOrder() {
+	userId := uuid.New()
+	checkpointId := uuid.New()
+	harvestedAt := time.Date(2022, 11, 1, 6, 0, 0, 0, time.UTC)
+	expiredAt := harvestedAt.AddDate(0, 0, 3)
+	testCase := []struct {
+		Name        string
+		UserID      string
+		Body        dto.PurchaseOrderRequest
+		ExpectedErr error
+		MockErr     error
+	}{
+		{
+			Name:   "success",
+			UserID: userId.String(),
+			Body: dto.PurchaseOrderRequest{SupplierID: 1, CheckpointID: checkpointId.String(), Details: []dto.PurchaseOrderDetailRequest{
+				{ItemID: 1, Qty: 10, Cost: 3000, HarvestedAt: &harvestedAt, ExpiredAt: &expiredAt},
+			}},
+		},
+		{
+			Name:        "invalid user id",
+			UserID:      "user",
+			Body:        dto.PurchaseOrderRequest{SupplierID: 1, Details: []dto.PurchaseOrderDetailRequest{{ItemID: 1, Qty: 10}}},
+			ExpectedErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:        "invalid checkpoint id",
+			UserID:      userId.String(),
+			Body:        dto.PurchaseOrderRequest{SupplierID: 1, CheckpointID: "pasar", Details: []dto.PurchaseOrderDetailRequest{{ItemID: 1, Qty: 10}}},
+			ExpectedErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:        "negative cost",
+			UserID:      userId.String(),
+			Body:        dto.PurchaseOrderRequest{SupplierID: 1, Details: []dto.PurchaseOrderDetailRequest{{ItemID: 1, Qty: 10, Cost: -1}}},
+			ExpectedErr: customerrors.ErrPurchaseOrderDetail,
+		},
+		{
+			Name:        "qty round to zero",
+			UserID:      userId.String(),
+			Body:        dto.PurchaseOrderRequest{SupplierID: 1, Details: []dto.PurchaseOrderDetailRequest{{ItemID: 1, Qty: 0.0001}}},
+			ExpectedErr: customerrors.ErrPurchaseOrderDetail,
+		},
+		{
+			Name:        "harvest without expiry",
+			UserID:      userId.String(),
+			Body:        dto.PurchaseOrderRequest{SupplierID: 1, Details: []dto.PurchaseOrderDetailRequest{{ItemID: 1, Qty: 10, HarvestedAt: &harvestedAt}}},
+			ExpectedErr: customerrors.ErrPurchaseOrderDetail,
+		},
+		{
+			Name:        "supplier not found",
+			UserID:      userId.String(),
+			Body:        dto.PurchaseOrderRequest{SupplierID: 1, Details: []dto.PurchaseOrderDetailRequest{{ItemID: 1, Qty: 10}}},
+			ExpectedErr: customerrors.ErrSupplierNotFound,
+			MockErr:     customerrors.ErrSupplierNotFound,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupTest()
+			s.supplierRepositoryMock.On("CreatePurchaseOrder", mock.Anything).Return(v.MockErr)
+
+			_, err := s.supplierService.CreatePurchaseOrder(v.Body, v.UserID, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			if v.Name == "success" {
+				s.supplierRepositoryMock.AssertCalled(t, "CreatePurchaseOrder", &model.PurchaseOrder{
+					SupplierID:   1,
+					CheckpointID: &checkpointId,
+					ActorID:      &userId,
+					Details:      []model.PurchaseOrderDetail{{ItemID: 1, Qty: 10, Cost: 3000, HarvestedAt: &harvestedAt, ExpiredAt: &expiredAt}},
+				})
+			}
+		})
+	}
+}
+
+func (s *suiteSupplierService) TestReceivePurchaseOrder() {
+	userId := uuid.New()
+	testCase := []struct {
+		Name            string
+		PurchaseOrderID string
+		UserID          string
+		ExpectedErr     error
+		MockErr         error
+	}{
+		{
+			Name:            "success",
+			PurchaseOrderID: "1",
+			UserID:          userId.String(),
+		},
+		{
+			Name:            "invalid id",
+			PurchaseOrderID: "satu",
+			UserID:          userId.String(),
+			ExpectedErr:     customerrors.ErrInvalidId,
+		},
+		{
+			Name:            "invalid user id",
+			PurchaseOrderID: "1",
+			UserID:          "user",
+			ExpectedErr:     customerrors.ErrInvalidId,
+		},
+		{
+			Name:            "not sent",
+			PurchaseOrderID: "1",
+			UserID:          userId.String(),
+			ExpectedErr:     customerrors.ErrPurchaseOrderStatus,
+			MockErr:         customerrors.ErrPurchaseOrderStatus,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupTest()
+			s.supplierRepositoryMock.On("ReceivePurchaseOrder", mock.Anything).Run(func(args mock.Arguments) {
+				order := args.Get(0).(*model.PurchaseOrder)
+				order.Status = constants.Purchase_status_received
+				order.Details = []model.PurchaseOrderDetail{{ItemID: 1, Qty: 2.5, Cost: 3000}, {ItemID: 2, Qty: 1, Cost: 500}}
+			}).Return(v.MockErr)
+
+			order, err := s.supplierService.ReceivePurchaseOrder(v.PurchaseOrderID, v.UserID, context.Background())
+
+			s.Equal(v.ExpectedErr, err)
+			if v.ExpectedErr == nil {
+				s.Equal(constants.Purchase_status_received, order.Status)
+				s.Equal(&userId, order.ReceiverID)
+				s.Equal(8000, order.Total)
+			}
+		})
+	}
+}
+
+func TestSuiteSupplierService(t *testing.T) {
+	suite.Run(t, new(suiteSupplierService))
+}
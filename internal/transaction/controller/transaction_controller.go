@@ -1,13 +1,17 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/golang-jwt/jwt"
 	"github.com/labstack/echo/v4"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/transaction/dto"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/transaction/service"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	_middleware "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/middleware"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
 type JWTService interface {
@@ -31,7 +35,7 @@ func (u *transactionController) InitRoute(api, auth *echo.Group) {
 	transactions.POST("/notification", u.TransactionNotification)
 
 	transactionsWithAuth := auth.Group("/transactions")
-	transactionsWithAuth.GET("", u.GetTransactions)
+	transactionsWithAuth.GET("", u.GetTransactions, _middleware.RequirePermission(constants.Permission_transaction_read))
 }
 
 func (u *transactionController) TransactionNotification(c echo.Context) error {
@@ -42,8 +46,25 @@ func (u *transactionController) TransactionNotification(c echo.Context) error {
 			"message": customerrors.ErrBadRequestBody.Error()})
 	}
 
+	transactionBody.SourceIP = c.RealIP()
 	err := u.service.CreateTransaction(transactionBody, c.Request().Context())
 	if err != nil {
+		if err == customerrors.ErrInvalidSignature {
+			return c.JSON(http.StatusForbidden, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		if err == customerrors.ErrBadRequestBody || err == customerrors.ErrNotFound || err == customerrors.ErrGrossAmount ||
+			err == customerrors.ErrUnknownTransactionStatus {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		if err == customerrors.ErrNotificationReplay || err == customerrors.ErrNotificationOutOfOrder || errors.Is(err, customerrors.ErrUpdateStatusOrder) {
+			return c.JSON(http.StatusConflict, echo.Map{
+				"message": err.Error(),
+			})
+		}
 		return c.JSON(http.StatusInternalServerError, echo.Map{
 			"message": err.Error(),
 		})
@@ -57,14 +78,21 @@ func (u *transactionController) GetTransactions(c echo.Context) error {
 	claims := u.jwtService.GetClaims(&c)
 	userId := claims["user_id"].(string)
 
-	transactions, err := u.service.FindTransaction(userId, c.Request().Context())
+	opts, err := query.Parse(c.QueryParams(), dto.TransactionQuery)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	transactions, page, err := u.service.FindTransaction(userId, opts, c.Request().Context())
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{
 			"message": err.Error(),
 		})
 	}
 	return c.JSON(http.StatusOK, echo.Map{
-		"message": "get transactions success",
-		"data":    transactions,
+		"message":    "get transactions success",
+		"data":       transactions,
+		"pagination": page,
 	})
 }
@@ -16,6 +16,7 @@ import (
 	tsm "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/transaction/service/mock"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
 	mm "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/middleware/mock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -95,6 +96,51 @@ func (s *suiteTransactionController) TestTransactionNotification() {
 				"message": "internal server error",
 			},
 		},
+		{
+			Name: "invalid signature",
+			Body: map[string]interface{}{
+				"transaction_id":     transactionId.String(),
+				"order_id":           orderId.String(),
+				"transaction_status": "settlement",
+				"signature_key":      "forged",
+				"gross_amount":       "10000",
+			},
+			ExpectedStatus:       403,
+			CreateTransactionErr: customerrors.ErrInvalidSignature,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrInvalidSignature.Error(),
+			},
+		},
+		{
+			Name: "gross amount not match",
+			Body: map[string]interface{}{
+				"transaction_id":     transactionId.String(),
+				"order_id":           orderId.String(),
+				"transaction_status": "settlement",
+				"signature_key":      "003592974b7cb5956dbfe36c000b",
+				"gross_amount":       "1",
+			},
+			ExpectedStatus:       400,
+			CreateTransactionErr: customerrors.ErrGrossAmount,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrGrossAmount.Error(),
+			},
+		},
+		{
+			Name: "replayed notification",
+			Body: map[string]interface{}{
+				"transaction_id":     transactionId.String(),
+				"order_id":           orderId.String(),
+				"transaction_status": "settlement",
+				"signature_key":      "003592974b7cb5956dbfe36c000b",
+				"gross_amount":       "10000",
+			},
+			ExpectedStatus:       409,
+			CreateTransactionErr: customerrors.ErrNotificationReplay,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrNotificationReplay.Error(),
+			},
+		},
 		{
 			Name: "internal server error",
 			Body: map[string]interface{}{
@@ -179,6 +225,12 @@ func (s *suiteTransactionController) TestGetTransaction() {
 			},
 			ExpectedResult: map[string]interface{}{
 				"message": "get transactions success",
+				"pagination": map[string]interface{}{
+					"page":        float64(1),
+					"limit":       float64(20),
+					"total":       float64(1),
+					"total_pages": float64(1),
+				},
 				"data": []interface{}{
 					map[string]interface{}{
 						"transaction_id":     transactionId.String(),
@@ -215,7 +267,13 @@ func (s *suiteTransactionController) TestGetTransaction() {
 			},
 			ExpectedResult: map[string]interface{}{
 				"message": "get transactions success",
-				"data":    nil,
+				"pagination": map[string]interface{}{
+					"page":        float64(1),
+					"limit":       float64(20),
+					"total":       float64(1),
+					"total_pages": float64(1),
+				},
+				"data": nil,
 			},
 		},
 	}
@@ -230,7 +288,7 @@ func (s *suiteTransactionController) TestGetTransaction() {
 			ctx.SetPath("/transaction/notification")
 
 			// define mock
-			mock1 := s.transactionServiceMock.On("FindTransaction").Return(v.FindTransactionRes, v.FindTransactionErr)
+			mock1 := s.transactionServiceMock.On("FindTransaction").Return(v.FindTransactionRes, &query.Page{Page: 1, Limit: 20, Total: 1, TotalPages: 1}, v.FindTransactionErr)
 			mock2 := s.JWTServiceMock.On("GetClaims").Return(v.JwtRes)
 
 			err := s.transactionController.GetTransactions(ctx)
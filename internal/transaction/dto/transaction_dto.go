@@ -3,8 +3,25 @@ package dto
 import (
 	"github.com/google/uuid"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
+// TransactionQuery is sort and filter allowed on transaction list.
+// Column is qualified because the list join orders table
+var TransactionQuery = query.Schema{
+	Sort: map[string]string{
+		"created_at": "transactions.created_at",
+	},
+	DefaultSort: "-created_at",
+	Key:         "transactions.id",
+	Filter: map[string]query.Filter{
+		"status":       {Column: "transactions.transaction_status", Type: query.String, Operator: query.Equal},
+		"payment_type": {Column: "transactions.payment_type", Type: query.String, Operator: query.Equal},
+		"from":         {Column: "transactions.created_at", Type: query.Time, Operator: query.From},
+		"to":           {Column: "transactions.created_at", Type: query.Time, Operator: query.Until},
+	},
+}
+
 type TransactionRequest struct {
 	TransactionID     string `json:"transaction_id"`
 	OrderID           string `json:"order_id"`
@@ -14,6 +31,8 @@ type TransactionRequest struct {
 	PaymentType       string `json:"payment_type"`
 	GrossAmount       string `json:"gross_amount"`
 	SettlementTime    string `json:"settlement_time"`
+	StatusCode        string `json:"status_code"`
+	SourceIP          string `json:"-"`
 }
 
 func (t *TransactionRequest) ToModel() *model.Transaction {
@@ -31,6 +50,19 @@ func (t *TransactionRequest) ToModel() *model.Transaction {
 	}
 }
 
+func (t *TransactionRequest) ToAuditModel(reason string) *model.NotificationAudit {
+	return &model.NotificationAudit{
+		TransactionID:     t.TransactionID,
+		OrderID:           t.OrderID,
+		TransactionStatus: t.TransactionStatus,
+		StatusCode:        t.StatusCode,
+		GrossAmount:       t.GrossAmount,
+		SignatureKey:      t.SignatureKey,
+		SourceIP:          t.SourceIP,
+		Reason:            reason,
+	}
+}
+
 type TransactionResponse struct {
 	TransactionID     string `json:"transaction_id"`
 	OrderID           string `json:"order_id"`
@@ -187,3 +187,50 @@ func TestTransactionsResponse_FromModel(t *testing.T) {
 		})
 	}
 }
+
+func TestTransactionRequest_ToAuditModel(t *testing.T) {
+	testCase := []struct {
+		Name     string
+		Dto      TransactionRequest
+		Reason   string
+		Expected *model.NotificationAudit
+	}{
+		{
+			Name: "all filled",
+			Dto: TransactionRequest{
+				TransactionID:     "transaction",
+				OrderID:           "order",
+				TransactionStatus: "settlement",
+				SignatureKey:      "signature",
+				GrossAmount:       "10000.00",
+				StatusCode:        "200",
+				SourceIP:          "127.0.0.1",
+			},
+			Reason: "invalid signature key",
+			Expected: &model.NotificationAudit{
+				TransactionID:     "transaction",
+				OrderID:           "order",
+				TransactionStatus: "settlement",
+				SignatureKey:      "signature",
+				GrossAmount:       "10000.00",
+				StatusCode:        "200",
+				SourceIP:          "127.0.0.1",
+				Reason:            "invalid signature key",
+			},
+		},
+		{
+			Name:   "dto empty",
+			Dto:    TransactionRequest{},
+			Reason: "invalid signature key",
+			Expected: &model.NotificationAudit{
+				Reason: "invalid signature key",
+			},
+		},
+	}
+	for _, v := range testCase {
+		t.Run(v.Name, func(t *testing.T) {
+			result := v.Dto.ToAuditModel(v.Reason)
+			assert.Equal(t, v.Expected, result)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -17,20 +18,32 @@ func (b *TransactionRepositoryMock) CreateTransaction(transaction *model.Transac
 	return args.Error(0)
 }
 
-func (b *TransactionRepositoryMock) UpdateTransaction(transaction *model.Transaction, ctx context.Context) error {
+func (b *TransactionRepositoryMock) UpdateTransaction(transaction *model.Transaction, prevStatus string, ctx context.Context) error {
 	args := b.Called()
 
 	return args.Error(0)
 }
 
-func (b *TransactionRepositoryMock) FindAllTransaction(userId string, ctx context.Context) ([]model.Transaction, error) {
+func (b *TransactionRepositoryMock) SetOrderApplied(transaction *model.Transaction, ctx context.Context) error {
 	args := b.Called()
 
-	return args.Get(0).([]model.Transaction), args.Error(1)
+	return args.Error(0)
 }
 
-func (b *TransactionRepositoryMock) FindTransaction(transaction *model.Transaction, ctx context.Context) error {
+func (b *TransactionRepositoryMock) FindAllTransaction(userId string, opts query.Options, ctx context.Context) ([]model.Transaction, int64, error) {
 	args := b.Called()
 
+	return args.Get(0).([]model.Transaction), args.Get(1).(int64), args.Error(2)
+}
+
+func (b *TransactionRepositoryMock) FindTransaction(transaction *model.Transaction, ctx context.Context) error {
+	args := b.Called(transaction)
+
+	return args.Error(0)
+}
+
+func (b *TransactionRepositoryMock) CreateNotificationAudit(audit *model.NotificationAudit, ctx context.Context) error {
+	args := b.Called(audit)
+
 	return args.Error(0)
 }
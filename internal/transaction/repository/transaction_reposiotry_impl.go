@@ -6,6 +6,7 @@ import (
 
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"gorm.io/gorm"
 )
 
@@ -25,28 +26,39 @@ func (r *transactionRepositoryImpl) CreateTransaction(transaction *model.Transac
 	return nil
 }
 
-// CreateTransaction implements TransactionRepository
-func (r *transactionRepositoryImpl) UpdateTransaction(transaction *model.Transaction, ctx context.Context) error {
-	err := r.db.WithContext(ctx).Model(&model.Transaction{}).Where("id = ?", transaction.ID).Updates(&model.Transaction{
+// UpdateTransaction implements TransactionRepository
+func (r *transactionRepositoryImpl) UpdateTransaction(transaction *model.Transaction, prevStatus string, ctx context.Context) error {
+	// only update when status not changed by other notification in the meantime, new status is not applied to order yet
+	res := r.db.WithContext(ctx).Model(&model.Transaction{}).Where("id = ? AND transaction_status = ?", transaction.ID, prevStatus).
+		Select("transaction_status", "order_applied").Updates(&model.Transaction{
 		TransactionStatus: transaction.TransactionStatus,
-	}).Error
-	if err != nil {
-		if strings.Contains(err.Error(), "Cannot add or update a child row") {
+	})
+	if res.Error != nil {
+		if strings.Contains(res.Error.Error(), "Cannot add or update a child row") {
 			return customerrors.ErrBadRequestBody
 		}
-		return err
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return customerrors.ErrNotificationOutOfOrder
 	}
 	return nil
 }
 
+// SetOrderApplied implements TransactionRepository
+func (r *transactionRepositoryImpl) SetOrderApplied(transaction *model.Transaction, ctx context.Context) error {
+	return r.db.WithContext(ctx).Model(&model.Transaction{}).Where("id = ? AND transaction_status = ?", transaction.ID, transaction.TransactionStatus).
+		Update("order_applied", true).Error
+}
+
 // FindAllTransaction implements TransactionRepository
-func (r *transactionRepositoryImpl) FindAllTransaction(userid string, ctx context.Context) ([]model.Transaction, error) {
+func (r *transactionRepositoryImpl) FindAllTransaction(userid string, opts query.Options, ctx context.Context) ([]model.Transaction, int64, error) {
 	var transactions []model.Transaction
-	err := r.db.WithContext(ctx).Model(&model.Transaction{}).Joins("left join orders on orders.id = transactions.order_id").Where("orders.user_id = ?", userid).Find(&transactions).Error
+	total, err := query.Find(r.db.WithContext(ctx).Model(&model.Transaction{}).Joins("left join orders on orders.id = transactions.order_id").Where("orders.user_id = ?", userid), opts, &transactions)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return transactions, nil
+	return transactions, total, nil
 }
 
 // FindTransaction implements TransactionRepository
@@ -61,6 +73,11 @@ func (r *transactionRepositoryImpl) FindTransaction(transaction *model.Transacti
 	return nil
 }
 
+// CreateNotificationAudit implements TransactionRepository
+func (r *transactionRepositoryImpl) CreateNotificationAudit(audit *model.NotificationAudit, ctx context.Context) error {
+	return r.db.WithContext(ctx).Create(audit).Error
+}
+
 func NewTransactionRepository(db *gorm.DB) TransactionRepository {
 	return &transactionRepositoryImpl{
 		db: db,
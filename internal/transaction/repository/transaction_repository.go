@@ -4,11 +4,14 @@ import (
 	"context"
 
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
 type TransactionRepository interface {
 	CreateTransaction(transaction *model.Transaction, ctx context.Context) error
-	UpdateTransaction(transaction *model.Transaction, ctx context.Context) error
-	FindAllTransaction(userId string, ctx context.Context) ([]model.Transaction, error)
+	UpdateTransaction(transaction *model.Transaction, prevStatus string, ctx context.Context) error
+	SetOrderApplied(transaction *model.Transaction, ctx context.Context) error
+	FindAllTransaction(userId string, opts query.Options, ctx context.Context) ([]model.Transaction, int64, error)
 	FindTransaction(transaction *model.Transaction, ctx context.Context) error
+	CreateNotificationAudit(audit *model.NotificationAudit, ctx context.Context) error
 }
@@ -8,12 +8,15 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/transaction/dto"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"github.com/stretchr/testify/suite"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"net/url"
 )
 
 type suiteTransactionRepository struct {
@@ -98,7 +101,7 @@ func (s *suiteTransactionRepository) TestCreateTransaction() {
 	for _, v := range testCase {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.mock.ExpectBegin()
-			db := s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `transactions` (`id`,`created_at`,`updated_at`,`deleted_at`,`order_id`,`transaction_status`,`transaction_time`,`signature_key`,`payment_type`,`gross_amount`,`settlement_time`) VALUES (?,?,?,?,?,?,?,?,?,?,?)"))
+			db := s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `transactions` (`id`,`created_at`,`updated_at`,`deleted_at`,`order_id`,`transaction_status`,`transaction_time`,`signature_key`,`payment_type`,`gross_amount`,`settlement_time`,`order_applied`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?)"))
 			if v.ExpectedErr != nil {
 				db.WillReturnError(v.MockReturn)
 				s.mock.ExpectRollback()
@@ -123,6 +126,7 @@ func (s *suiteTransactionRepository) TestUpdateTransaction() {
 		Body        model.Transaction
 		ExpectedErr error
 		MockReturn  error
+		MockRows    int64
 	}{
 		{
 			Name: "success",
@@ -138,6 +142,18 @@ func (s *suiteTransactionRepository) TestUpdateTransaction() {
 			},
 			ExpectedErr: nil,
 			MockReturn:  nil,
+			MockRows:    1,
+		},
+		{
+			Name: "status changed by other notification",
+			Body: model.Transaction{
+				ID:                transactionId,
+				OrderID:           orderId,
+				TransactionStatus: "test",
+			},
+			ExpectedErr: customerrors.ErrNotificationOutOfOrder,
+			MockReturn:  nil,
+			MockRows:    0,
 		},
 		{
 			Name: "invalid foreign key (order id)",
@@ -174,8 +190,48 @@ func (s *suiteTransactionRepository) TestUpdateTransaction() {
 	for _, v := range testCase {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.mock.ExpectBegin()
-			db := s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `transactions` SET `updated_at`=?,`transaction_status`=? WHERE id = ? AND `transactions`.`deleted_at` IS NULL"))
-			if v.ExpectedErr != nil {
+			db := s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `transactions` SET `updated_at`=?,`transaction_status`=?,`order_applied`=? WHERE (id = ? AND transaction_status = ?) AND `transactions`.`deleted_at` IS NULL")).
+				WithArgs(sqlmock.AnyArg(), v.Body.TransactionStatus, false, transactionId, "pending")
+			if v.MockReturn != nil {
+				db.WillReturnError(v.MockReturn)
+				s.mock.ExpectRollback()
+			} else {
+				db.WillReturnResult(sqlmock.NewResult(1, v.MockRows))
+				s.mock.ExpectCommit()
+			}
+			var ctx context.Context
+			err := s.repository.UpdateTransaction(&v.Body, "pending", ctx)
+
+			s.Equal(v.ExpectedErr, err)
+		})
+	}
+}
+func (s *suiteTransactionRepository) TestSetOrderApplied() {
+	transactionId := uuid.New()
+
+	testCase := []struct {
+		Name        string
+		ExpectedErr error
+		MockReturn  error
+	}{
+		{
+			Name:        "success",
+			ExpectedErr: nil,
+			MockReturn:  nil,
+		},
+		{
+			Name:        "error",
+			ExpectedErr: errors.New("err"),
+			MockReturn:  errors.New("err"),
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.mock.ExpectBegin()
+			db := s.mock.ExpectExec(regexp.QuoteMeta("UPDATE `transactions` SET `order_applied`=?,`updated_at`=? WHERE (id = ? AND transaction_status = ?) AND `transactions`.`deleted_at` IS NULL")).
+				WithArgs(true, sqlmock.AnyArg(), transactionId, "settlement")
+			if v.MockReturn != nil {
 				db.WillReturnError(v.MockReturn)
 				s.mock.ExpectRollback()
 			} else {
@@ -183,12 +239,14 @@ func (s *suiteTransactionRepository) TestUpdateTransaction() {
 				s.mock.ExpectCommit()
 			}
 			var ctx context.Context
-			err := s.repository.UpdateTransaction(&v.Body, ctx)
+			err := s.repository.SetOrderApplied(&model.Transaction{ID: transactionId, TransactionStatus: "settlement"}, ctx)
 
 			s.Equal(v.ExpectedErr, err)
+			s.NoError(s.mock.ExpectationsWereMet())
 		})
 	}
 }
+
 func (s *suiteTransactionRepository) TestFindAllTransaction() {
 	userId := uuid.New().String()
 	transactionId := uuid.New()
@@ -225,16 +283,20 @@ func (s *suiteTransactionRepository) TestFindAllTransaction() {
 
 	for _, v := range testCase {
 		s.T().Run(v.Name, func(t *testing.T) {
-			db := s.mock.ExpectQuery(regexp.QuoteMeta("SELECT `transactions`.`id`,`transactions`.`created_at`,`transactions`.`updated_at`,`transactions`.`deleted_at`,`transactions`.`order_id`,`transactions`.`transaction_status`,`transactions`.`transaction_time`,`transactions`.`signature_key`,`transactions`.`payment_type`,`transactions`.`gross_amount`,`transactions`.`settlement_time` FROM `transactions` left join orders on orders.id = transactions.order_id WHERE orders.user_id = ? AND `transactions`.`deleted_at` IS NULL"))
+			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `transactions` left join orders on orders.id = transactions.order_id WHERE orders.user_id = ? AND `transactions`.`transaction_status` = ? AND `transactions`.`deleted_at` IS NULL")).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(len(v.ExpectedRes)))
+			db := s.mock.ExpectQuery(regexp.QuoteMeta("SELECT `transactions`.`id`,`transactions`.`created_at`,`transactions`.`updated_at`,`transactions`.`deleted_at`,`transactions`.`order_id`,`transactions`.`transaction_status`,`transactions`.`transaction_time`,`transactions`.`signature_key`,`transactions`.`payment_type`,`transactions`.`gross_amount`,`transactions`.`settlement_time`,`transactions`.`order_applied` FROM `transactions` left join orders on orders.id = transactions.order_id WHERE orders.user_id = ? AND `transactions`.`transaction_status` = ? AND `transactions`.`deleted_at` IS NULL ORDER BY `transactions`.`created_at` DESC,`transactions`.`id` LIMIT 20"))
 			if v.MockRes != nil {
 				db.WillReturnRows(v.MockRes)
 			}
 			db.WillReturnError(v.MockErr)
 			var ctx context.Context
-			res, err := s.repository.FindAllTransaction(v.UserId, ctx)
+			opts, err := query.Parse(url.Values{"status": {"settlement"}}, dto.TransactionQuery)
+			s.NoError(err)
+			res, total, err := s.repository.FindAllTransaction(v.UserId, opts, ctx)
 
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(v.ExpectedRes, res)
+			s.Equal(int64(len(v.ExpectedRes)), total)
 		})
 	}
 }
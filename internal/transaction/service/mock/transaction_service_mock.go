@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/transaction/dto"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -21,7 +22,7 @@ func (b *TransactionServiceMock) SetOrderStatus(body dto.TransactionRequest, ctx
 	return args.Error(0)
 }
 
-func (b *TransactionServiceMock) FindTransaction(id string, ctx context.Context) (dto.TransactionsResponse, error) {
+func (b *TransactionServiceMock) FindTransaction(id string, opts query.Options, ctx context.Context) (dto.TransactionsResponse, *query.Page, error) {
 	args := b.Called()
-	return args.Get(0).(dto.TransactionsResponse), args.Error(1)
+	return args.Get(0).(dto.TransactionsResponse), args.Get(1).(*query.Page), args.Error(2)
 }
@@ -4,9 +4,10 @@ import (
 	"context"
 
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/transaction/dto"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
 type TransactionService interface {
 	CreateTransaction(body dto.TransactionRequest, ctx context.Context) error
-	FindTransaction(id string, ctx context.Context) (dto.TransactionsResponse, error)
+	FindTransaction(id string, opts query.Options, ctx context.Context) (dto.TransactionsResponse, *query.Page, error)
 }
@@ -2,55 +2,135 @@ package service
 
 import (
 	"context"
+	"crypto/subtle"
+	"log"
+	"strconv"
 
+	"github.com/google/uuid"
 	or "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/repository"
 	os "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/service"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/transaction/dto"
 	tr "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/transaction/repository"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/payment"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
+// order of midtrans transaction status, notification cant move status to same or lower rank.
+// Status not listed here is rejected before it is saved
+var transactionStatusRank = map[string]int{
+	"pending":        1,
+	"authorize":      2,
+	"capture":        3,
+	"settlement":     4,
+	"deny":           4,
+	"cancel":         4,
+	"expire":         4,
+	"failure":        4,
+	"refund":         5,
+	"partial_refund": 5,
+	"chargeback":     5,
+}
+
 type transactionServiceImpl struct {
 	transactionRepo tr.TransactionRepository
 	orderRepo       or.OrderRepository
 	orderService    os.OrderService
+	serverKey       string
 }
 
 // FindTransaction implements TransactionService
-func (s *transactionServiceImpl) FindTransaction(id string, ctx context.Context) (dto.TransactionsResponse, error) {
-	transactions, err := s.transactionRepo.FindAllTransaction(id, ctx)
+func (s *transactionServiceImpl) FindTransaction(id string, opts query.Options, ctx context.Context) (dto.TransactionsResponse, *query.Page, error) {
+	transactions, total, err := s.transactionRepo.FindAllTransaction(id, opts, ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	var transactionsResponse dto.TransactionsResponse
 	transactionsResponse.FromModel(transactions)
-	return transactionsResponse, nil
+	return transactionsResponse, query.NewPage(opts, total), nil
 }
 
 // CreateTransaction implements TransactionService
 func (s *transactionServiceImpl) CreateTransaction(body dto.TransactionRequest, ctx context.Context) error {
-	model := body.ToModel()
-	err := s.transactionRepo.FindTransaction(model, ctx)
+	signature := payment.SignatureKey(body.OrderID, body.StatusCode, body.GrossAmount, s.serverKey)
+	if subtle.ConstantTimeCompare([]byte(signature), []byte(body.SignatureKey)) != 1 {
+		return s.rejectNotification(body, customerrors.ErrInvalidSignature, ctx)
+	}
+	if _, ok := transactionStatusRank[body.TransactionStatus]; !ok {
+		log.Println("transaction: unknown status", body.TransactionStatus, "of order", body.OrderID)
+		return s.rejectNotification(body, customerrors.ErrUnknownTransactionStatus, ctx)
+	}
+
+	_, err := uuid.Parse(body.TransactionID)
+	if err != nil {
+		return s.rejectNotification(body, customerrors.ErrBadRequestBody, ctx)
+	}
+	orderId, err := uuid.Parse(body.OrderID)
 	if err != nil {
-		if err == customerrors.ErrNotFound {
-			err = s.transactionRepo.CreateTransaction(model, ctx)
+		return s.rejectNotification(body, customerrors.ErrBadRequestBody, ctx)
+	}
+	order := model.Order{
+		ID: orderId,
+	}
+	err = s.orderRepo.FindOrderById(&order, ctx)
+	if err != nil {
+		return s.rejectNotification(body, err, ctx)
+	}
+	grossAmount, err := strconv.ParseFloat(body.GrossAmount, 64)
+	if err != nil || grossAmount != float64(order.GrandTotal) {
+		return s.rejectNotification(body, customerrors.ErrGrossAmount, ctx)
+	}
+
+	newTransaction := body.ToModel()
+	oldTransaction := body.ToModel()
+	err = s.transactionRepo.FindTransaction(oldTransaction, ctx)
+	if err != nil {
+		if err != customerrors.ErrNotFound {
+			return err
+		}
+		err = s.transactionRepo.CreateTransaction(newTransaction, ctx)
+		if err != nil {
+			return err
+		}
+	} else if oldTransaction.TransactionStatus == newTransaction.TransactionStatus {
+		// notification is retried when order status failed to be applied, only applied one is replay
+		if oldTransaction.OrderApplied {
+			return s.rejectNotification(body, customerrors.ErrNotificationReplay, ctx)
+		}
+	} else {
+		if transactionStatusRank[newTransaction.TransactionStatus] <= transactionStatusRank[oldTransaction.TransactionStatus] {
+			return s.rejectNotification(body, customerrors.ErrNotificationOutOfOrder, ctx)
+		}
+		err = s.transactionRepo.UpdateTransaction(newTransaction, oldTransaction.TransactionStatus, ctx)
+		if err == customerrors.ErrNotificationOutOfOrder {
+			return s.rejectNotification(body, err, ctx)
+		}
+		if err != nil {
 			return err
 		}
+	}
+	err = s.orderService.SetOrderStatus(newTransaction.OrderID, newTransaction.TransactionStatus, ctx)
+	if err != nil {
 		return err
 	}
-	makeTransaction := body.ToModel()
-	err = s.transactionRepo.UpdateTransaction(makeTransaction, ctx)
+	return s.transactionRepo.SetOrderApplied(newTransaction, ctx)
+}
+
+// rejectNotification save audit of rejected notification and return the reason
+func (s *transactionServiceImpl) rejectNotification(body dto.TransactionRequest, reason error, ctx context.Context) error {
+	err := s.transactionRepo.CreateNotificationAudit(body.ToAuditModel(reason.Error()), ctx)
 	if err != nil {
 		return err
 	}
-	err = s.orderService.SetOrderStatus(makeTransaction.OrderID, makeTransaction.TransactionStatus, ctx)
-	return err
+	return reason
 }
 
-func NewTransactionService(transaction tr.TransactionRepository, orderRepository or.OrderRepository, orderService os.OrderService) TransactionService {
+func NewTransactionService(transaction tr.TransactionRepository, orderRepository or.OrderRepository, orderService os.OrderService, serverKey string) TransactionService {
 	return &transactionServiceImpl{
 		transactionRepo: transaction,
 		orderRepo:       orderRepository,
 		orderService:    orderService,
+		serverKey:       serverKey,
 	}
 }
@@ -15,6 +15,9 @@ import (
 	_transactionRepositoryMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/transaction/repository/mock"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/payment"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -26,11 +29,14 @@ type suiteTransactionService struct {
 	transactionService        TransactionService
 }
 
+const serverKey = "SB-Mid-server-test"
+
 func newTransactionServiceMock(transaction tr.TransactionRepository, orderRepository or.OrderRepository, orderService os.OrderService) TransactionService {
 	return &transactionServiceImpl{
 		transactionRepo: transaction,
 		orderRepo:       orderRepository,
 		orderService:    orderService,
+		serverKey:       serverKey,
 	}
 }
 
@@ -44,141 +50,181 @@ func (s *suiteTransactionService) SetupTest() {
 func (s *suiteTransactionService) TestCreateTransaction() {
 	transactionId := uuid.New().String()
 	orderId := uuid.New().String()
+	newBody := func(status, grossAmount, signature string) dto.TransactionRequest {
+		if signature == "" {
+			signature = payment.SignatureKey(orderId, "200", grossAmount, serverKey)
+		}
+		return dto.TransactionRequest{
+			TransactionID:     transactionId,
+			OrderID:           orderId,
+			TransactionStatus: status,
+			TransactionTime:   "test",
+			SignatureKey:      signature,
+			PaymentType:       "test",
+			GrossAmount:       grossAmount,
+			SettlementTime:    "test",
+			StatusCode:        "200",
+		}
+	}
 
 	testCase := []struct {
 		Name                 string
 		Body                 dto.TransactionRequest
 		ExpectedErr          error
+		ExpectedAudit        bool
+		FindOrderErr         error
+		OrderGrandTotal      int
 		FindTransactionErr   error
+		OldStatus            string
+		OldApplied           bool
 		CreateTransactionErr error
 		UpdateTransactionErr error
 		SerOrderStatusErr    error
 	}{
 		{
-			Name: "success",
-			Body: dto.TransactionRequest{
-				TransactionID:     transactionId,
-				OrderID:           orderId,
-				TransactionStatus: "pending",
-				TransactionTime:   "test",
-				SignatureKey:      "test",
-				PaymentType:       "test",
-				GrossAmount:       "test",
-				SettlementTime:    "test",
-			},
-			ExpectedErr:          nil,
-			FindTransactionErr:   customerrors.ErrNotFound,
-			CreateTransactionErr: nil,
-			UpdateTransactionErr: nil,
-			SerOrderStatusErr:    nil,
-		},
-		{
-			Name: "transaction alredy exist, update transaction, and set new order status",
-			Body: dto.TransactionRequest{
-				TransactionID:     transactionId,
-				OrderID:           orderId,
-				TransactionStatus: "settlement",
-				TransactionTime:   "test",
-				SignatureKey:      "test",
-				PaymentType:       "test",
-				GrossAmount:       "test",
-				SettlementTime:    "test",
-			},
-			ExpectedErr:          nil,
-			FindTransactionErr:   nil,
-			CreateTransactionErr: nil,
-			UpdateTransactionErr: nil,
-			SerOrderStatusErr:    nil,
-		},
-		{
-			Name: "error when find transaction",
-			Body: dto.TransactionRequest{
-				TransactionID:     transactionId,
-				OrderID:           orderId,
-				TransactionStatus: "settlement",
-				TransactionTime:   "test",
-				SignatureKey:      "test",
-				PaymentType:       "test",
-				GrossAmount:       "test",
-				SettlementTime:    "test",
-			},
-			ExpectedErr:          errors.New("error find transaction"),
-			FindTransactionErr:   errors.New("error find transaction"),
-			CreateTransactionErr: nil,
-			UpdateTransactionErr: nil,
-			SerOrderStatusErr:    nil,
-		},
-		{
-			Name: "error when create transaction",
-			Body: dto.TransactionRequest{
-				TransactionID:     transactionId,
-				OrderID:           orderId,
-				TransactionStatus: "settlement",
-				TransactionTime:   "test",
-				SignatureKey:      "test",
-				PaymentType:       "test",
-				GrossAmount:       "test",
-				SettlementTime:    "test",
-			},
+			Name:               "success",
+			Body:               newBody("pending", "25000.00", ""),
+			ExpectedErr:        nil,
+			OrderGrandTotal:    25000,
+			FindTransactionErr: customerrors.ErrNotFound,
+		},
+		{
+			Name:            "transaction alredy exist, update transaction, and set new order status",
+			Body:            newBody("settlement", "25000.00", ""),
+			ExpectedErr:     nil,
+			OrderGrandTotal: 25000,
+			OldStatus:       "pending",
+		},
+		{
+			Name:          "invalid signature",
+			Body:          newBody("settlement", "25000.00", "forged"),
+			ExpectedErr:   customerrors.ErrInvalidSignature,
+			ExpectedAudit: true,
+		},
+		{
+			Name:          "signature from other gross amount",
+			Body:          newBody("settlement", "25000.00", payment.SignatureKey(orderId, "200", "1.00", serverKey)),
+			ExpectedErr:   customerrors.ErrInvalidSignature,
+			ExpectedAudit: true,
+		},
+		{
+			Name:          "unknown status",
+			Body:          newBody("settled", "25000.00", ""),
+			ExpectedErr:   customerrors.ErrUnknownTransactionStatus,
+			ExpectedAudit: true,
+		},
+		{
+			Name:          "order not found",
+			Body:          newBody("settlement", "25000.00", ""),
+			ExpectedErr:   customerrors.ErrNotFound,
+			ExpectedAudit: true,
+			FindOrderErr:  customerrors.ErrNotFound,
+		},
+		{
+			Name:            "gross amount not match with order",
+			Body:            newBody("settlement", "1.00", ""),
+			ExpectedErr:     customerrors.ErrGrossAmount,
+			ExpectedAudit:   true,
+			OrderGrandTotal: 25000,
+		},
+		{
+			Name:            "replayed notification",
+			Body:            newBody("settlement", "25000.00", ""),
+			ExpectedErr:     customerrors.ErrNotificationReplay,
+			ExpectedAudit:   true,
+			OrderGrandTotal: 25000,
+			OldStatus:       "settlement",
+			OldApplied:      true,
+		},
+		{
+			Name:            "retried notification not applied to order yet",
+			Body:            newBody("settlement", "25000.00", ""),
+			ExpectedErr:     nil,
+			OrderGrandTotal: 25000,
+			OldStatus:       "settlement",
+		},
+		{
+			Name:            "out of order notification",
+			Body:            newBody("pending", "25000.00", ""),
+			ExpectedErr:     customerrors.ErrNotificationOutOfOrder,
+			ExpectedAudit:   true,
+			OrderGrandTotal: 25000,
+			OldStatus:       "settlement",
+		},
+		{
+			Name:                 "status changed by concurrent notification",
+			Body:                 newBody("settlement", "25000.00", ""),
+			ExpectedErr:          customerrors.ErrNotificationOutOfOrder,
+			ExpectedAudit:        true,
+			OrderGrandTotal:      25000,
+			OldStatus:            "pending",
+			UpdateTransactionErr: customerrors.ErrNotificationOutOfOrder,
+		},
+		{
+			Name:               "error when find transaction",
+			Body:               newBody("settlement", "25000.00", ""),
+			ExpectedErr:        errors.New("error find transaction"),
+			OrderGrandTotal:    25000,
+			FindTransactionErr: errors.New("error find transaction"),
+		},
+		{
+			Name:                 "error when create transaction",
+			Body:                 newBody("settlement", "25000.00", ""),
 			ExpectedErr:          errors.New("error create transaction"),
+			OrderGrandTotal:      25000,
 			FindTransactionErr:   customerrors.ErrNotFound,
 			CreateTransactionErr: errors.New("error create transaction"),
-			UpdateTransactionErr: nil,
-			SerOrderStatusErr:    nil,
-		},
-		{
-			Name: "error when update transaction",
-			Body: dto.TransactionRequest{
-				TransactionID:     transactionId,
-				OrderID:           orderId,
-				TransactionStatus: "settlement",
-				TransactionTime:   "test",
-				SignatureKey:      "test",
-				PaymentType:       "test",
-				GrossAmount:       "test",
-				SettlementTime:    "test",
-			},
+		},
+		{
+			Name:                 "error when update transaction",
+			Body:                 newBody("settlement", "25000.00", ""),
 			ExpectedErr:          errors.New("error update transaction"),
-			FindTransactionErr:   nil,
-			CreateTransactionErr: nil,
+			OrderGrandTotal:      25000,
+			OldStatus:            "pending",
 			UpdateTransactionErr: errors.New("error update transaction"),
-			SerOrderStatusErr:    nil,
-		},
-		{
-			Name: "error when set new order stratus",
-			Body: dto.TransactionRequest{
-				TransactionID:     transactionId,
-				OrderID:           orderId,
-				TransactionStatus: "settlement",
-				TransactionTime:   "test",
-				SignatureKey:      "test",
-				PaymentType:       "test",
-				GrossAmount:       "test",
-				SettlementTime:    "test",
-			},
-			ExpectedErr:          errors.New("error set order status"),
-			FindTransactionErr:   nil,
-			CreateTransactionErr: nil,
-			UpdateTransactionErr: nil,
-			SerOrderStatusErr:    errors.New("error set order status"),
+		},
+		{
+			Name:              "error when set new order stratus",
+			Body:              newBody("settlement", "25000.00", ""),
+			ExpectedErr:       errors.New("error set order status"),
+			OrderGrandTotal:   25000,
+			OldStatus:         "pending",
+			SerOrderStatusErr: errors.New("error set order status"),
 		},
 	}
 	for _, v := range testCase {
 		s.T().Run(v.Name, func(t *testing.T) {
-			mock1 := s.transactionRepositoryMock.On("FindTransaction").Return(v.FindTransactionErr)
-			mock2 := s.transactionRepositoryMock.On("CreateTransaction").Return(v.CreateTransactionErr)
-			mock3 := s.transactionRepositoryMock.On("UpdateTransaction").Return(v.UpdateTransactionErr)
-			mock4 := s.orderServiceMock.On("SetOrderStatus").Return(v.SerOrderStatusErr)
+			s.SetupTest()
+
+			s.orderRepositoryMock.On("FindOrderById", mock.Anything).Return(v.FindOrderErr).Run(func(args mock.Arguments) {
+				args.Get(0).(*model.Order).GrandTotal = v.OrderGrandTotal
+			})
+			s.transactionRepositoryMock.On("FindTransaction", mock.Anything).Return(v.FindTransactionErr).Run(func(args mock.Arguments) {
+				args.Get(0).(*model.Transaction).TransactionStatus = v.OldStatus
+				args.Get(0).(*model.Transaction).OrderApplied = v.OldApplied
+			})
+			s.transactionRepositoryMock.On("CreateTransaction").Return(v.CreateTransactionErr)
+			s.transactionRepositoryMock.On("UpdateTransaction").Return(v.UpdateTransactionErr)
+			s.transactionRepositoryMock.On("CreateNotificationAudit", mock.Anything).Return(nil)
+			s.orderServiceMock.On("SetOrderStatus").Return(v.SerOrderStatusErr)
+			s.transactionRepositoryMock.On("SetOrderApplied").Return(nil)
 
 			var ctx context.Context
 			err := s.transactionService.CreateTransaction(v.Body, ctx)
 
 			s.Equal(v.ExpectedErr, err)
-
-			mock1.Unset()
-			mock2.Unset()
-			mock3.Unset()
-			mock4.Unset()
+			if v.ExpectedAudit {
+				s.transactionRepositoryMock.AssertCalled(t, "CreateNotificationAudit", v.Body.ToAuditModel(v.ExpectedErr.Error()))
+				s.orderServiceMock.AssertNotCalled(t, "SetOrderStatus")
+			} else {
+				s.transactionRepositoryMock.AssertNotCalled(t, "CreateNotificationAudit", mock.Anything)
+			}
+			// status is only marked applied after order status is set
+			if v.ExpectedErr == nil {
+				s.transactionRepositoryMock.AssertCalled(t, "SetOrderApplied")
+			} else {
+				s.transactionRepositoryMock.AssertNotCalled(t, "SetOrderApplied")
+			}
 		})
 	}
 }
@@ -236,13 +282,16 @@ func (s *suiteTransactionService) TestFindTransaction() {
 	}
 	for _, v := range testCase {
 		s.T().Run(v.Name, func(t *testing.T) {
-			mock1 := s.transactionRepositoryMock.On("FindAllTransaction").Return(v.FindAllTransactionRes, v.FindAllTransactionErr)
+			mock1 := s.transactionRepositoryMock.On("FindAllTransaction").Return(v.FindAllTransactionRes, int64(len(v.FindAllTransactionRes)), v.FindAllTransactionErr)
 
 			var ctx context.Context
-			res, err := s.transactionService.FindTransaction(v.UserId, ctx)
+			res, page, err := s.transactionService.FindTransaction(v.UserId, query.Options{Page: 1, Limit: 20}, ctx)
 
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(v.ExpectedRes, res)
+			if v.ExpectedErr == nil {
+				s.Equal(int64(len(v.FindAllTransactionRes)), page.Total)
+			}
 
 			mock1.Unset()
 		})
@@ -9,6 +9,8 @@ import (
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/user/service"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	_middleware "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/middleware"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
 type JWTService interface {
@@ -30,12 +32,25 @@ func NewUserController(service service.UserService, jwt JWTService) *userControl
 func (u *userController) InitRoute(api *echo.Group, auth *echo.Group) {
 	api.POST("/signup", u.SignUp)
 	api.POST("/login", u.Login)
+	api.POST("/token/refresh", u.RefreshToken)
+	api.POST("/logout", u.Logout)
+	api.POST("/email/verify", u.VerifyEmail)
+	api.POST("/email/resend", u.ResendVerification)
+	api.POST("/password/forgot", u.ForgotPassword)
+	api.POST("/password/reset", u.ResetPassword)
 
 	users := auth.Group("/users")
-	users.GET("", u.GetUsers)
-	users.PUT("", u.UpdateUser)
-	users.DELETE("/:id", u.DeleteUser)
-	users.GET("/profile", u.GetUser)
+	users.GET("", u.GetUsers, _middleware.RequirePermission(constants.Permission_user_manage))
+	users.PUT("", u.UpdateUser, _middleware.RequirePermission(constants.Permission_user_profile))
+	users.DELETE("/:id", u.DeleteUser, _middleware.RequirePermission(constants.Permission_user_manage))
+	users.GET("/profile", u.GetUser, _middleware.RequirePermission(constants.Permission_user_profile))
+	users.PUT("/password", u.ChangePassword, _middleware.RequirePermission(constants.Permission_user_profile))
+	users.GET("/:id", u.GetUserDetail, _middleware.RequirePermission(constants.Permission_user_manage))
+	users.PUT("/:id/role", u.ChangeRole, _middleware.RequirePermission(constants.Permission_user_manage))
+	users.PUT("/:id/disable", u.DisableUser, _middleware.RequirePermission(constants.Permission_user_manage))
+	users.PUT("/:id/enable", u.EnableUser, _middleware.RequirePermission(constants.Permission_user_manage))
+	users.POST("/:id/password/reset", u.ForcePasswordReset, _middleware.RequirePermission(constants.Permission_user_manage))
+	users.PUT("/:id/unlock", u.UnlockUser, _middleware.RequirePermission(constants.Permission_user_manage))
 }
 
 func (u *userController) SignUp(c echo.Context) error {
@@ -78,9 +93,107 @@ func (u *userController) Login(c echo.Context) error {
 			"message": err.Error(),
 		})
 	}
-	token, err := u.service.Login(user, c.Request().Context())
+	token, err := u.service.Login(user, c.RealIP(), c.Request().Context())
 	if err != nil {
-		if err == customerrors.ErrNotFound || err == customerrors.ErrInvalidPassword {
+		if err == customerrors.ErrInvalidCredentials {
+			return c.JSON(http.StatusUnauthorized, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		if err == customerrors.ErrLoginLocked {
+			return c.JSON(http.StatusTooManyRequests, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		if err == customerrors.ErrUserDisabled {
+			return c.JSON(http.StatusForbidden, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message":       "login success",
+		"token":         token.Token,
+		"refresh_token": token.RefreshToken,
+	})
+}
+
+func (u *userController) RefreshToken(c echo.Context) error {
+	var body dto.RefreshTokenRequest
+	if err := c.Bind(&body); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrBadRequestBody.Error(),
+		})
+	}
+	if err := c.Validate(body); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	token, err := u.service.RefreshToken(body, c.Request().Context())
+	if err != nil {
+		if err == customerrors.ErrInvalidToken {
+			return c.JSON(http.StatusUnauthorized, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message":       "refresh token success",
+		"token":         token.Token,
+		"refresh_token": token.RefreshToken,
+	})
+}
+
+func (u *userController) Logout(c echo.Context) error {
+	var body dto.RefreshTokenRequest
+	if err := c.Bind(&body); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrBadRequestBody.Error(),
+		})
+	}
+	if err := c.Validate(body); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	err := u.service.Logout(body, c.Request().Context())
+	if err != nil {
+		if err == customerrors.ErrInvalidToken {
+			return c.JSON(http.StatusUnauthorized, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "logout success",
+	})
+}
+
+func (u *userController) VerifyEmail(c echo.Context) error {
+	var body dto.VerifyEmailRequest
+	if err := c.Bind(&body); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrBadRequestBody.Error(),
+		})
+	}
+	if err := c.Validate(body); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	err := u.service.VerifyEmail(body, c.Request().Context())
+	if err != nil {
+		if err == customerrors.ErrInvalidToken {
 			return c.JSON(http.StatusBadRequest, echo.Map{
 				"message": err.Error(),
 			})
@@ -90,8 +203,81 @@ func (u *userController) Login(c echo.Context) error {
 		})
 	}
 	return c.JSON(http.StatusOK, echo.Map{
-		"message": "login success",
-		"token":   token,
+		"message": "email verified",
+	})
+}
+
+func (u *userController) ResendVerification(c echo.Context) error {
+	var body dto.EmailRequest
+	if err := c.Bind(&body); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrBadRequestBody.Error(),
+		})
+	}
+	if err := c.Validate(body); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	err := u.service.ResendVerification(body, c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "verification email sent if email is registered and not verified",
+	})
+}
+
+func (u *userController) ForgotPassword(c echo.Context) error {
+	var body dto.EmailRequest
+	if err := c.Bind(&body); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrBadRequestBody.Error(),
+		})
+	}
+	if err := c.Validate(body); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	err := u.service.ForgotPassword(body, c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "reset password email sent if email is registered",
+	})
+}
+
+func (u *userController) ResetPassword(c echo.Context) error {
+	var body dto.ResetPasswordRequest
+	if err := c.Bind(&body); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrBadRequestBody.Error(),
+		})
+	}
+	if err := c.Validate(body); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	err := u.service.ResetPassword(body, c.Request().Context())
+	if err != nil {
+		if err == customerrors.ErrInvalidToken {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "password reset success",
 	})
 }
 
@@ -111,22 +297,22 @@ func (u *userController) GetUser(c echo.Context) error {
 }
 
 func (u *userController) GetUsers(c echo.Context) error {
-	claims := u.jwtService.GetClaims(&c)
-	role := claims["role_id"].(float64)
-	if role != constants.Role_admin {
-		return c.JSON(http.StatusForbidden, echo.Map{
-			"message": customerrors.ErrPermission.Error(),
+	opts, err := query.Parse(c.QueryParams(), dto.UserQuery)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
 		})
 	}
-	users, err := u.service.FindAllUsers(c.Request().Context())
+	users, page, err := u.service.FindAllUsers(opts, c.Request().Context())
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, echo.Map{
 			"message": err.Error(),
 		})
 	}
 	return c.JSON(http.StatusOK, echo.Map{
-		"message": "success get users",
-		"data":    users,
+		"message":    "success get users",
+		"data":       users,
+		"pagination": page,
 	})
 }
 
@@ -150,21 +336,156 @@ func (u *userController) UpdateUser(c echo.Context) error {
 }
 
 func (u *userController) DeleteUser(c echo.Context) error {
+	id := c.Param("id")
+	err := u.service.DeleteUser(id, c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "success delete user",
+	})
+}
+
+func (u *userController) ChangePassword(c echo.Context) error {
 	claims := u.jwtService.GetClaims(&c)
-	role := claims["role_id"].(float64)
-	if role != constants.Role_admin {
-		return c.JSON(http.StatusForbidden, echo.Map{
-			"message": customerrors.ErrPermission.Error(),
+	userId := claims["user_id"].(string)
+	sessionId, _ := claims["sid"].(string)
+	var body dto.ChangePasswordRequest
+	if err := c.Bind(&body); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrBadRequestBody.Error(),
+		})
+	}
+	if err := c.Validate(body); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	token, err := u.service.ChangePassword(userId, sessionId, body, c.Request().Context())
+	if err != nil {
+		if err == customerrors.ErrInvalidId || err == customerrors.ErrInvalidPassword || err == customerrors.ErrSamePassword {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		if err == customerrors.ErrNotFound {
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
 		})
 	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "password changed",
+		"token":   token,
+	})
+}
+
+func (u *userController) GetUserDetail(c echo.Context) error {
 	id := c.Param("id")
-	err := u.service.DeleteUser(id, c.Request().Context())
+	user, err := u.service.FindUserDetail(id, c.Request().Context())
 	if err != nil {
+		return manageUserError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "get user success",
+		"data":    user,
+	})
+}
+
+func (u *userController) ChangeRole(c echo.Context) error {
+	claims := u.jwtService.GetClaims(&c)
+	actorId := claims["user_id"].(string)
+	id := c.Param("id")
+	var body dto.ChangeRoleRequest
+	if err := c.Bind(&body); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrBadRequestBody.Error(),
+		})
+	}
+	if err := c.Validate(body); err != nil {
 		return c.JSON(http.StatusBadRequest, echo.Map{
 			"message": err.Error(),
 		})
 	}
+	err := u.service.ChangeRole(actorId, id, body, c.Request().Context())
+	if err != nil {
+		return manageUserError(c, err)
+	}
 	return c.JSON(http.StatusOK, echo.Map{
-		"message": "success delete user",
+		"message": "success change role",
+	})
+}
+
+func (u *userController) DisableUser(c echo.Context) error {
+	claims := u.jwtService.GetClaims(&c)
+	actorId := claims["user_id"].(string)
+	id := c.Param("id")
+	err := u.service.DisableUser(actorId, id, c.Request().Context())
+	if err != nil {
+		return manageUserError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "user disabled",
+	})
+}
+
+func (u *userController) EnableUser(c echo.Context) error {
+	claims := u.jwtService.GetClaims(&c)
+	actorId := claims["user_id"].(string)
+	id := c.Param("id")
+	err := u.service.EnableUser(actorId, id, c.Request().Context())
+	if err != nil {
+		return manageUserError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "user enabled",
+	})
+}
+
+func (u *userController) ForcePasswordReset(c echo.Context) error {
+	id := c.Param("id")
+	err := u.service.ForcePasswordReset(id, c.Request().Context())
+	if err != nil {
+		return manageUserError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "user must reset password",
+	})
+}
+
+func (u *userController) UnlockUser(c echo.Context) error {
+	id := c.Param("id")
+	err := u.service.UnlockUser(id, c.Request().Context())
+	if err != nil {
+		return manageUserError(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "user unlocked",
+	})
+}
+
+// manageUserError map error of admin user management to response
+func manageUserError(c echo.Context, err error) error {
+	switch err {
+	case customerrors.ErrInvalidId, customerrors.ErrInvalidRole:
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": err.Error(),
+		})
+	case customerrors.ErrNotFound:
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"message": err.Error(),
+		})
+	case customerrors.ErrDefaultAdmin, customerrors.ErrChangeOwnAccount:
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"message": err.Error(),
 	})
 }
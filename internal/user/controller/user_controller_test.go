@@ -17,6 +17,7 @@ import (
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
 	mm "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/middleware/mock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	vm "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/validator/mock"
 	"github.com/stretchr/testify/suite"
 )
@@ -169,7 +170,7 @@ func (s *suiteUserController) TestLogin() {
 		Body           map[string]interface{}
 		ValidatorErr   error
 		ExpectedStatus int
-		LoginRes       string
+		LoginRes       *dto.TokenResponse
 		LoginErr       error
 		ExpectedResult map[string]interface{}
 	}{
@@ -181,11 +182,12 @@ func (s *suiteUserController) TestLogin() {
 			},
 			ValidatorErr:   nil,
 			ExpectedStatus: 200,
-			LoginRes:       "123",
+			LoginRes:       &dto.TokenResponse{Token: "123", RefreshToken: "456"},
 			LoginErr:       nil,
 			ExpectedResult: map[string]interface{}{
-				"message": "login success",
-				"token":   "123",
+				"message":       "login success",
+				"token":         "123",
+				"refresh_token": "456",
 			},
 		},
 		{
@@ -196,7 +198,7 @@ func (s *suiteUserController) TestLogin() {
 			},
 			ValidatorErr:   nil,
 			ExpectedStatus: 400,
-			LoginRes:       "",
+			LoginRes:       nil,
 			LoginErr:       nil,
 			ExpectedResult: map[string]interface{}{
 				"message": customerrors.ErrBadRequestBody.Error(),
@@ -209,38 +211,52 @@ func (s *suiteUserController) TestLogin() {
 			},
 			ValidatorErr:   errors.New("invalid"),
 			ExpectedStatus: 400,
-			LoginRes:       "",
+			LoginRes:       nil,
 			LoginErr:       nil,
 			ExpectedResult: map[string]interface{}{
 				"message": "invalid",
 			},
 		},
 		{
-			Name: "user not found",
+			Name: "invalid credentials",
 			Body: map[string]interface{}{
 				"email":    "test@gmail.com",
 				"password": "123",
 			},
 			ValidatorErr:   nil,
-			ExpectedStatus: 400,
-			LoginRes:       "",
-			LoginErr:       customerrors.ErrNotFound,
+			ExpectedStatus: 401,
+			LoginRes:       nil,
+			LoginErr:       customerrors.ErrInvalidCredentials,
 			ExpectedResult: map[string]interface{}{
-				"message": customerrors.ErrNotFound.Error(),
+				"message": customerrors.ErrInvalidCredentials.Error(),
 			},
 		},
 		{
-			Name: "invalid password",
+			Name: "login locked",
 			Body: map[string]interface{}{
 				"email":    "test@gmail.com",
 				"password": "123",
 			},
 			ValidatorErr:   nil,
-			ExpectedStatus: 400,
-			LoginRes:       "",
-			LoginErr:       customerrors.ErrInvalidPassword,
+			ExpectedStatus: 429,
+			LoginRes:       nil,
+			LoginErr:       customerrors.ErrLoginLocked,
 			ExpectedResult: map[string]interface{}{
-				"message": customerrors.ErrInvalidPassword.Error(),
+				"message": customerrors.ErrLoginLocked.Error(),
+			},
+		},
+		{
+			Name: "user disabled",
+			Body: map[string]interface{}{
+				"email":    "test@gmail.com",
+				"password": "123",
+			},
+			ValidatorErr:   nil,
+			ExpectedStatus: 403,
+			LoginRes:       nil,
+			LoginErr:       customerrors.ErrUserDisabled,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrUserDisabled.Error(),
 			},
 		},
 		{
@@ -251,7 +267,7 @@ func (s *suiteUserController) TestLogin() {
 			},
 			ValidatorErr:   nil,
 			ExpectedStatus: 500,
-			LoginRes:       "",
+			LoginRes:       nil,
 			LoginErr:       errors.New("err"),
 			ExpectedResult: map[string]interface{}{
 				"message": errors.New("err").Error(),
@@ -290,50 +306,77 @@ func (s *suiteUserController) TestLogin() {
 	}
 }
 
-func (s *suiteUserController) TestGetUser() {
-	// uuid statis in test
-	varUUID := uuid.New()
-
+func (s *suiteUserController) TestRefreshToken() {
 	testCase := []struct {
 		Name           string
-		JwtRes         jwt.MapClaims
+		Body           map[string]interface{}
+		ValidatorErr   error
 		ExpectedStatus int
-		FindUserRes    *dto.UserResponse
-		FindUserErr    error
+		RefreshRes     *dto.TokenResponse
+		RefreshErr     error
 		ExpectedResult map[string]interface{}
 	}{
 		{
 			Name: "success",
-			JwtRes: jwt.MapClaims{
-				"user_id": varUUID.String(),
+			Body: map[string]interface{}{
+				"refresh_token": "abc",
 			},
+			ValidatorErr:   nil,
 			ExpectedStatus: 200,
-			FindUserRes: &dto.UserResponse{
-				ID: varUUID,
+			RefreshRes:     &dto.TokenResponse{Token: "123", RefreshToken: "456"},
+			RefreshErr:     nil,
+			ExpectedResult: map[string]interface{}{
+				"message":       "refresh token success",
+				"token":         "123",
+				"refresh_token": "456",
 			},
-			FindUserErr: nil,
+		},
+		{
+			Name: "body type invalid",
+			Body: map[string]interface{}{
+				"refresh_token": 123,
+			},
+			ValidatorErr:   nil,
+			ExpectedStatus: 400,
+			RefreshRes:     nil,
+			RefreshErr:     nil,
 			ExpectedResult: map[string]interface{}{
-				"data": map[string]interface{}{
-					"district_name": "",
-					"email":         "",
-					"id":            varUUID.String(),
-					"name":          "",
-					"phone":         "",
-					"province_name": "",
-					"regency_name":  "",
-					"village_name":  "",
-				},
-				"message": "get user success",
+				"message": customerrors.ErrBadRequestBody.Error(),
+			},
+		},
+		{
+			Name:           "body invalid",
+			Body:           map[string]interface{}{},
+			ValidatorErr:   errors.New("invalid"),
+			ExpectedStatus: 400,
+			RefreshRes:     nil,
+			RefreshErr:     nil,
+			ExpectedResult: map[string]interface{}{
+				"message": "invalid",
+			},
+		},
+		{
+			Name: "invalid token",
+			Body: map[string]interface{}{
+				"refresh_token": "abc",
+			},
+			ValidatorErr:   nil,
+			ExpectedStatus: 401,
+			RefreshRes:     nil,
+			RefreshErr:     customerrors.ErrInvalidToken,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrInvalidToken.Error(),
 			},
 		},
 		{
 			Name: "internal server error",
-			JwtRes: jwt.MapClaims{
-				"user_id": varUUID.String(),
+			Body: map[string]interface{}{
+				"refresh_token": "abc",
 			},
+			ValidatorErr:   nil,
 			ExpectedStatus: 500,
-			FindUserRes:    nil,
-			FindUserErr:    errors.New("err"),
+			RefreshRes:     nil,
+			RefreshErr:     errors.New("err"),
 			ExpectedResult: map[string]interface{}{
 				"message": errors.New("err").Error(),
 			},
@@ -342,18 +385,20 @@ func (s *suiteUserController) TestGetUser() {
 
 	for _, v := range testCase {
 		s.T().Run(v.Name, func(t *testing.T) {
-			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			body, err := json.Marshal(v.Body)
+			s.NoError(err)
+
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(body))
 			r.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 
 			ctx := s.echoNew.NewContext(r, w)
-			ctx.SetPath("/user")
+			ctx.SetPath("/token/refresh")
 
 			//define mock
-			mock1 := s.userServiceMock.On("FindUser").Return(v.FindUserRes, v.FindUserErr)
-			mock2 := s.JWTServiceMock.On("GetClaims").Return(v.JwtRes)
-
-			err := s.userController.GetUser(ctx)
+			mock1 := s.userServiceMock.On("RefreshToken").Return(v.RefreshRes, v.RefreshErr)
+			mock2 := s.validaorMock.On("Validate").Return(v.ValidatorErr)
+			err = s.userController.RefreshToken(ctx)
 			s.NoError(err)
 
 			controllerResult := map[string]interface{}{}
@@ -369,85 +414,91 @@ func (s *suiteUserController) TestGetUser() {
 	}
 }
 
-func (s *suiteUserController) TestGetAllUsers() {
-	// uuid statis in test
-	varUUID := uuid.New()
-
+func (s *suiteUserController) TestLogout() {
 	testCase := []struct {
-		Name            string
-		JwtRes          jwt.MapClaims
-		ExpectedStatus  int
-		FindAllUsersRes dto.UsersResponse
-		FindAllUsersErr error
-		ExpectedResult  map[string]interface{}
+		Name           string
+		Body           map[string]interface{}
+		ValidatorErr   error
+		ExpectedStatus int
+		LogoutErr      error
+		ExpectedResult map[string]interface{}
 	}{
 		{
 			Name: "success",
-			JwtRes: jwt.MapClaims{
-				"role_id": float64(constants.Role_admin),
+			Body: map[string]interface{}{
+				"refresh_token": "abc",
 			},
+			ValidatorErr:   nil,
 			ExpectedStatus: 200,
-			FindAllUsersRes: dto.UsersResponse{
-				{
-					ID: varUUID,
-				},
+			LogoutErr:      nil,
+			ExpectedResult: map[string]interface{}{
+				"message": "logout success",
 			},
-			FindAllUsersErr: nil,
+		},
+		{
+			Name: "body type invalid",
+			Body: map[string]interface{}{
+				"refresh_token": 123,
+			},
+			ValidatorErr:   nil,
+			ExpectedStatus: 400,
+			LogoutErr:      nil,
 			ExpectedResult: map[string]interface{}{
-				"data": []interface{}{
-					map[string]interface{}{
-						"district_name": "",
-						"email":         "",
-						"id":            varUUID.String(),
-						"name":          "",
-						"phone":         "",
-						"province_name": "",
-						"regency_name":  "",
-						"village_name":  "",
-					},
-				},
-				"message": "success get users",
+				"message": customerrors.ErrBadRequestBody.Error(),
 			},
 		},
 		{
-			Name: "forbidden",
-			JwtRes: jwt.MapClaims{
-				"role_id": float64(constants.Role_user),
+			Name:           "body invalid",
+			Body:           map[string]interface{}{},
+			ValidatorErr:   errors.New("invalid"),
+			ExpectedStatus: 400,
+			LogoutErr:      nil,
+			ExpectedResult: map[string]interface{}{
+				"message": "invalid",
 			},
-			ExpectedStatus:  403,
-			FindAllUsersRes: nil,
-			FindAllUsersErr: nil,
+		},
+		{
+			Name: "invalid token",
+			Body: map[string]interface{}{
+				"refresh_token": "abc",
+			},
+			ValidatorErr:   nil,
+			ExpectedStatus: 401,
+			LogoutErr:      customerrors.ErrInvalidToken,
 			ExpectedResult: map[string]interface{}{
-				"message": customerrors.ErrPermission.Error(),
+				"message": customerrors.ErrInvalidToken.Error(),
 			},
 		},
 		{
 			Name: "internal server error",
-			JwtRes: jwt.MapClaims{
-				"role_id": float64(constants.Role_admin),
+			Body: map[string]interface{}{
+				"refresh_token": "abc",
 			},
-			ExpectedStatus:  500,
-			FindAllUsersRes: nil,
-			FindAllUsersErr: errors.New("err"),
+			ValidatorErr:   nil,
+			ExpectedStatus: 500,
+			LogoutErr:      errors.New("err"),
 			ExpectedResult: map[string]interface{}{
 				"message": errors.New("err").Error(),
 			},
 		},
 	}
+
 	for _, v := range testCase {
 		s.T().Run(v.Name, func(t *testing.T) {
-			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			body, err := json.Marshal(v.Body)
+			s.NoError(err)
+
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(body))
 			r.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 
 			ctx := s.echoNew.NewContext(r, w)
-			ctx.SetPath("/user/all")
+			ctx.SetPath("/logout")
 
 			//define mock
-			mock1 := s.userServiceMock.On("FindAllUsers").Return(v.FindAllUsersRes, v.FindAllUsersErr)
-			mock2 := s.JWTServiceMock.On("GetClaims").Return(v.JwtRes)
-
-			err := s.userController.GetUsers(ctx)
+			mock1 := s.userServiceMock.On("Logout").Return(v.LogoutErr)
+			mock2 := s.validaorMock.On("Validate").Return(v.ValidatorErr)
+			err = s.userController.Logout(ctx)
 			s.NoError(err)
 
 			controllerResult := map[string]interface{}{}
@@ -463,56 +514,69 @@ func (s *suiteUserController) TestGetAllUsers() {
 	}
 }
 
-func (s *suiteUserController) TestUpdateUser() {
-	// uuid statis in test
-	varUUID := uuid.New()
-
+func (s *suiteUserController) TestVerifyEmail() {
 	testCase := []struct {
 		Name           string
 		Body           map[string]interface{}
-		JwtRes         jwt.MapClaims
+		ValidatorErr   error
 		ExpectedStatus int
-		UpdateUserErr  error
+		VerifyEmailErr error
 		ExpectedResult map[string]interface{}
 	}{
 		{
 			Name: "success",
-			JwtRes: jwt.MapClaims{
-				"user_id": varUUID.String(),
-			},
 			Body: map[string]interface{}{
-				"name": "test",
+				"token": "abc",
 			},
+			ValidatorErr:   nil,
 			ExpectedStatus: 200,
-			UpdateUserErr:  nil,
+			VerifyEmailErr: nil,
 			ExpectedResult: map[string]interface{}{
-				"message": "success update user",
+				"message": "email verified",
 			},
 		},
 		{
 			Name: "body type invalid",
-			JwtRes: jwt.MapClaims{
-				"user_id": varUUID.String(),
-			},
 			Body: map[string]interface{}{
-				"name": 123,
+				"token": 123,
 			},
-			UpdateUserErr:  nil,
+			ValidatorErr:   nil,
 			ExpectedStatus: 400,
+			VerifyEmailErr: nil,
 			ExpectedResult: map[string]interface{}{
 				"message": customerrors.ErrBadRequestBody.Error(),
 			},
 		},
 		{
-			Name: "error update",
-			JwtRes: jwt.MapClaims{
-				"user_id": varUUID.String(),
+			Name:           "body invalid",
+			Body:           map[string]interface{}{},
+			ValidatorErr:   errors.New("invalid"),
+			ExpectedStatus: 400,
+			VerifyEmailErr: nil,
+			ExpectedResult: map[string]interface{}{
+				"message": "invalid",
 			},
+		},
+		{
+			Name: "invalid token",
 			Body: map[string]interface{}{
-				"name": "test",
+				"token": "abc",
 			},
+			ValidatorErr:   nil,
 			ExpectedStatus: 400,
-			UpdateUserErr:  errors.New("err"),
+			VerifyEmailErr: customerrors.ErrInvalidToken,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrInvalidToken.Error(),
+			},
+		},
+		{
+			Name: "internal server error",
+			Body: map[string]interface{}{
+				"token": "abc",
+			},
+			ValidatorErr:   nil,
+			ExpectedStatus: 500,
+			VerifyEmailErr: errors.New("err"),
 			ExpectedResult: map[string]interface{}{
 				"message": errors.New("err").Error(),
 			},
@@ -524,20 +588,17 @@ func (s *suiteUserController) TestUpdateUser() {
 			body, err := json.Marshal(v.Body)
 			s.NoError(err)
 
-			r := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(body))
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(body))
 			r.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 
 			ctx := s.echoNew.NewContext(r, w)
-			ctx.SetPath("/user/:id")
-			ctx.SetParamNames("id")
-			ctx.SetParamValues(varUUID.String())
-
-			// define mock
-			mock1 := s.userServiceMock.On("UpdateUser").Return(v.UpdateUserErr)
-			mock2 := s.JWTServiceMock.On("GetClaims").Return(v.JwtRes)
+			ctx.SetPath("/email/verify")
 
-			err = s.userController.UpdateUser(ctx)
+			//define mock
+			mock1 := s.userServiceMock.On("VerifyEmail").Return(v.VerifyEmailErr)
+			mock2 := s.validaorMock.On("Validate").Return(v.ValidatorErr)
+			err = s.userController.VerifyEmail(ctx)
 			s.NoError(err)
 
 			controllerResult := map[string]interface{}{}
@@ -547,49 +608,63 @@ func (s *suiteUserController) TestUpdateUser() {
 			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
 			s.Equal(v.ExpectedResult, controllerResult)
 
-			// mock1.Unset()
 			mock1.Unset()
 			mock2.Unset()
 		})
 	}
 }
-func (s *suiteUserController) TestDeleteUser() {
+
+func (s *suiteUserController) TestResendVerification() {
 	testCase := []struct {
-		Name           string
-		JwtRes         jwt.MapClaims
-		ExpectedStatus int
-		DeleteUserErr  error
-		ExpectedResult map[string]interface{}
+		Name                  string
+		Body                  map[string]interface{}
+		ValidatorErr          error
+		ExpectedStatus        int
+		ResendVerificationErr error
+		ExpectedResult        map[string]interface{}
 	}{
 		{
 			Name: "success",
-			JwtRes: jwt.MapClaims{
-				"role_id": float64(constants.Role_admin),
+			Body: map[string]interface{}{
+				"email": "test@gmail.com",
 			},
-			ExpectedStatus: 200,
-			DeleteUserErr:  nil,
+			ValidatorErr:          nil,
+			ExpectedStatus:        200,
+			ResendVerificationErr: nil,
 			ExpectedResult: map[string]interface{}{
-				"message": "success delete user",
+				"message": "verification email sent if email is registered and not verified",
 			},
 		},
 		{
-			Name: "forbidden",
-			JwtRes: jwt.MapClaims{
-				"role_id": float64(constants.Role_user),
+			Name: "body type invalid",
+			Body: map[string]interface{}{
+				"email": 123,
 			},
-			ExpectedStatus: 403,
-			DeleteUserErr:  nil,
+			ValidatorErr:          nil,
+			ExpectedStatus:        400,
+			ResendVerificationErr: nil,
 			ExpectedResult: map[string]interface{}{
-				"message": customerrors.ErrPermission.Error(),
+				"message": customerrors.ErrBadRequestBody.Error(),
 			},
 		},
 		{
-			Name: "delete error",
-			JwtRes: jwt.MapClaims{
-				"role_id": float64(constants.Role_admin),
+			Name:                  "body invalid",
+			Body:                  map[string]interface{}{},
+			ValidatorErr:          errors.New("invalid"),
+			ExpectedStatus:        400,
+			ResendVerificationErr: nil,
+			ExpectedResult: map[string]interface{}{
+				"message": "invalid",
 			},
-			ExpectedStatus: 400,
-			DeleteUserErr:  errors.New("err"),
+		},
+		{
+			Name: "internal server error",
+			Body: map[string]interface{}{
+				"email": "test@gmail.com",
+			},
+			ValidatorErr:          nil,
+			ExpectedStatus:        500,
+			ResendVerificationErr: errors.New("err"),
 			ExpectedResult: map[string]interface{}{
 				"message": errors.New("err").Error(),
 			},
@@ -598,20 +673,1002 @@ func (s *suiteUserController) TestDeleteUser() {
 
 	for _, v := range testCase {
 		s.T().Run(v.Name, func(t *testing.T) {
-			r := httptest.NewRequest(http.MethodDelete, "/", nil)
+			body, err := json.Marshal(v.Body)
+			s.NoError(err)
+
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(body))
 			r.Header.Set("Content-Type", "application/json")
 			w := httptest.NewRecorder()
 
 			ctx := s.echoNew.NewContext(r, w)
-			ctx.SetPath("/user/:id")
-			ctx.SetParamNames("id")
-			ctx.SetParamValues(uuid.New().String())
+			ctx.SetPath("/email/resend")
 
-			// define mock
-			mock1 := s.userServiceMock.On("DeleteUser").Return(v.DeleteUserErr)
+			//define mock
+			mock1 := s.userServiceMock.On("ResendVerification").Return(v.ResendVerificationErr)
+			mock2 := s.validaorMock.On("Validate").Return(v.ValidatorErr)
+			err = s.userController.ResendVerification(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			mock1.Unset()
+			mock2.Unset()
+		})
+	}
+}
+
+func (s *suiteUserController) TestForgotPassword() {
+	testCase := []struct {
+		Name              string
+		Body              map[string]interface{}
+		ValidatorErr      error
+		ExpectedStatus    int
+		ForgotPasswordErr error
+		ExpectedResult    map[string]interface{}
+	}{
+		{
+			Name: "success",
+			Body: map[string]interface{}{
+				"email": "test@gmail.com",
+			},
+			ValidatorErr:      nil,
+			ExpectedStatus:    200,
+			ForgotPasswordErr: nil,
+			ExpectedResult: map[string]interface{}{
+				"message": "reset password email sent if email is registered",
+			},
+		},
+		{
+			Name: "body type invalid",
+			Body: map[string]interface{}{
+				"email": 123,
+			},
+			ValidatorErr:      nil,
+			ExpectedStatus:    400,
+			ForgotPasswordErr: nil,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrBadRequestBody.Error(),
+			},
+		},
+		{
+			Name:              "body invalid",
+			Body:              map[string]interface{}{},
+			ValidatorErr:      errors.New("invalid"),
+			ExpectedStatus:    400,
+			ForgotPasswordErr: nil,
+			ExpectedResult: map[string]interface{}{
+				"message": "invalid",
+			},
+		},
+		{
+			Name: "internal server error",
+			Body: map[string]interface{}{
+				"email": "test@gmail.com",
+			},
+			ValidatorErr:      nil,
+			ExpectedStatus:    500,
+			ForgotPasswordErr: errors.New("err"),
+			ExpectedResult: map[string]interface{}{
+				"message": errors.New("err").Error(),
+			},
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			body, err := json.Marshal(v.Body)
+			s.NoError(err)
+
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(body))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			ctx := s.echoNew.NewContext(r, w)
+			ctx.SetPath("/password/forgot")
+
+			//define mock
+			mock1 := s.userServiceMock.On("ForgotPassword").Return(v.ForgotPasswordErr)
+			mock2 := s.validaorMock.On("Validate").Return(v.ValidatorErr)
+			err = s.userController.ForgotPassword(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			mock1.Unset()
+			mock2.Unset()
+		})
+	}
+}
+
+func (s *suiteUserController) TestResetPassword() {
+	testCase := []struct {
+		Name             string
+		Body             map[string]interface{}
+		ValidatorErr     error
+		ExpectedStatus   int
+		ResetPasswordErr error
+		ExpectedResult   map[string]interface{}
+	}{
+		{
+			Name: "success",
+			Body: map[string]interface{}{
+				"token":    "abc",
+				"password": "123",
+			},
+			ValidatorErr:     nil,
+			ExpectedStatus:   200,
+			ResetPasswordErr: nil,
+			ExpectedResult: map[string]interface{}{
+				"message": "password reset success",
+			},
+		},
+		{
+			Name: "body type invalid",
+			Body: map[string]interface{}{
+				"token": 123,
+			},
+			ValidatorErr:     nil,
+			ExpectedStatus:   400,
+			ResetPasswordErr: nil,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrBadRequestBody.Error(),
+			},
+		},
+		{
+			Name:             "body invalid",
+			Body:             map[string]interface{}{},
+			ValidatorErr:     errors.New("invalid"),
+			ExpectedStatus:   400,
+			ResetPasswordErr: nil,
+			ExpectedResult: map[string]interface{}{
+				"message": "invalid",
+			},
+		},
+		{
+			Name: "invalid token",
+			Body: map[string]interface{}{
+				"token":    "abc",
+				"password": "123",
+			},
+			ValidatorErr:     nil,
+			ExpectedStatus:   400,
+			ResetPasswordErr: customerrors.ErrInvalidToken,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrInvalidToken.Error(),
+			},
+		},
+		{
+			Name: "internal server error",
+			Body: map[string]interface{}{
+				"token":    "abc",
+				"password": "123",
+			},
+			ValidatorErr:     nil,
+			ExpectedStatus:   500,
+			ResetPasswordErr: errors.New("err"),
+			ExpectedResult: map[string]interface{}{
+				"message": errors.New("err").Error(),
+			},
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			body, err := json.Marshal(v.Body)
+			s.NoError(err)
+
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(body))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			ctx := s.echoNew.NewContext(r, w)
+			ctx.SetPath("/password/reset")
+
+			//define mock
+			mock1 := s.userServiceMock.On("ResetPassword").Return(v.ResetPasswordErr)
+			mock2 := s.validaorMock.On("Validate").Return(v.ValidatorErr)
+			err = s.userController.ResetPassword(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			mock1.Unset()
+			mock2.Unset()
+		})
+	}
+}
+
+func (s *suiteUserController) TestGetUser() {
+	// uuid statis in test
+	varUUID := uuid.New()
+
+	testCase := []struct {
+		Name           string
+		JwtRes         jwt.MapClaims
+		ExpectedStatus int
+		FindUserRes    *dto.UserResponse
+		FindUserErr    error
+		ExpectedResult map[string]interface{}
+	}{
+		{
+			Name: "success",
+			JwtRes: jwt.MapClaims{
+				"user_id": varUUID.String(),
+			},
+			ExpectedStatus: 200,
+			FindUserRes: &dto.UserResponse{
+				ID: varUUID,
+			},
+			FindUserErr: nil,
+			ExpectedResult: map[string]interface{}{
+				"data": map[string]interface{}{
+					"district_name": "",
+					"email":         "",
+					"id":            varUUID.String(),
+					"name":          "",
+					"phone":         "",
+					"province_name": "",
+					"regency_name":  "",
+					"village_name":  "",
+				},
+				"message": "get user success",
+			},
+		},
+		{
+			Name: "internal server error",
+			JwtRes: jwt.MapClaims{
+				"user_id": varUUID.String(),
+			},
+			ExpectedStatus: 500,
+			FindUserRes:    nil,
+			FindUserErr:    errors.New("err"),
+			ExpectedResult: map[string]interface{}{
+				"message": errors.New("err").Error(),
+			},
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			ctx := s.echoNew.NewContext(r, w)
+			ctx.SetPath("/user")
+
+			//define mock
+			mock1 := s.userServiceMock.On("FindUser").Return(v.FindUserRes, v.FindUserErr)
 			mock2 := s.JWTServiceMock.On("GetClaims").Return(v.JwtRes)
 
-			err := s.userController.DeleteUser(ctx)
+			err := s.userController.GetUser(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			mock1.Unset()
+			mock2.Unset()
+		})
+	}
+}
+
+func (s *suiteUserController) TestGetAllUsers() {
+	// uuid statis in test
+	varUUID := uuid.New()
+
+	testCase := []struct {
+		Name            string
+		JwtRes          jwt.MapClaims
+		ExpectedStatus  int
+		Query           string
+		FindAllUsersRes dto.UsersResponse
+		FindAllUsersErr error
+		ExpectedResult  map[string]interface{}
+	}{
+		{
+			Name: "success",
+			JwtRes: jwt.MapClaims{
+				"role_id": float64(constants.Role_admin),
+			},
+			ExpectedStatus: 200,
+			FindAllUsersRes: dto.UsersResponse{
+				{
+					ID: varUUID,
+				},
+			},
+			FindAllUsersErr: nil,
+			ExpectedResult: map[string]interface{}{
+				"data": []interface{}{
+					map[string]interface{}{
+						"district_name": "",
+						"email":         "",
+						"id":            varUUID.String(),
+						"name":          "",
+						"phone":         "",
+						"province_name": "",
+						"regency_name":  "",
+						"village_name":  "",
+					},
+				},
+				"message": "success get users",
+				"pagination": map[string]interface{}{
+					"page":        float64(1),
+					"limit":       float64(20),
+					"total":       float64(1),
+					"total_pages": float64(1),
+				},
+			},
+		},
+		{
+			Name: "sort not allowed",
+			JwtRes: jwt.MapClaims{
+				"role_id": float64(constants.Role_admin),
+			},
+			Query:          "sort=password",
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrInvalidSort.Error(),
+			},
+		},
+		{
+			Name: "internal server error",
+			JwtRes: jwt.MapClaims{
+				"role_id": float64(constants.Role_admin),
+			},
+			ExpectedStatus:  500,
+			FindAllUsersRes: nil,
+			FindAllUsersErr: errors.New("err"),
+			ExpectedResult: map[string]interface{}{
+				"message": errors.New("err").Error(),
+			},
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/?"+v.Query, nil)
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			ctx := s.echoNew.NewContext(r, w)
+			ctx.SetPath("/user/all")
+
+			//define mock
+			mock1 := s.userServiceMock.On("FindAllUsers").Return(v.FindAllUsersRes, &query.Page{Page: 1, Limit: 20, Total: 1, TotalPages: 1}, v.FindAllUsersErr)
+			mock2 := s.JWTServiceMock.On("GetClaims").Return(v.JwtRes)
+
+			err := s.userController.GetUsers(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			mock1.Unset()
+			mock2.Unset()
+		})
+	}
+}
+
+func (s *suiteUserController) TestUpdateUser() {
+	// uuid statis in test
+	varUUID := uuid.New()
+
+	testCase := []struct {
+		Name           string
+		Body           map[string]interface{}
+		JwtRes         jwt.MapClaims
+		ExpectedStatus int
+		UpdateUserErr  error
+		ExpectedResult map[string]interface{}
+	}{
+		{
+			Name: "success",
+			JwtRes: jwt.MapClaims{
+				"user_id": varUUID.String(),
+			},
+			Body: map[string]interface{}{
+				"name": "test",
+			},
+			ExpectedStatus: 200,
+			UpdateUserErr:  nil,
+			ExpectedResult: map[string]interface{}{
+				"message": "success update user",
+			},
+		},
+		{
+			Name: "body type invalid",
+			JwtRes: jwt.MapClaims{
+				"user_id": varUUID.String(),
+			},
+			Body: map[string]interface{}{
+				"name": 123,
+			},
+			UpdateUserErr:  nil,
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrBadRequestBody.Error(),
+			},
+		},
+		{
+			Name: "error update",
+			JwtRes: jwt.MapClaims{
+				"user_id": varUUID.String(),
+			},
+			Body: map[string]interface{}{
+				"name": "test",
+			},
+			ExpectedStatus: 400,
+			UpdateUserErr:  errors.New("err"),
+			ExpectedResult: map[string]interface{}{
+				"message": errors.New("err").Error(),
+			},
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			body, err := json.Marshal(v.Body)
+			s.NoError(err)
+
+			r := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(body))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			ctx := s.echoNew.NewContext(r, w)
+			ctx.SetPath("/user/:id")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(varUUID.String())
+
+			// define mock
+			mock1 := s.userServiceMock.On("UpdateUser").Return(v.UpdateUserErr)
+			mock2 := s.JWTServiceMock.On("GetClaims").Return(v.JwtRes)
+
+			err = s.userController.UpdateUser(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			// mock1.Unset()
+			mock1.Unset()
+			mock2.Unset()
+		})
+	}
+}
+func (s *suiteUserController) TestDeleteUser() {
+	testCase := []struct {
+		Name           string
+		JwtRes         jwt.MapClaims
+		ExpectedStatus int
+		DeleteUserErr  error
+		ExpectedResult map[string]interface{}
+	}{
+		{
+			Name: "success",
+			JwtRes: jwt.MapClaims{
+				"role_id": float64(constants.Role_admin),
+			},
+			ExpectedStatus: 200,
+			DeleteUserErr:  nil,
+			ExpectedResult: map[string]interface{}{
+				"message": "success delete user",
+			},
+		},
+		{
+			Name: "delete error",
+			JwtRes: jwt.MapClaims{
+				"role_id": float64(constants.Role_admin),
+			},
+			ExpectedStatus: 400,
+			DeleteUserErr:  errors.New("err"),
+			ExpectedResult: map[string]interface{}{
+				"message": errors.New("err").Error(),
+			},
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodDelete, "/", nil)
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			ctx := s.echoNew.NewContext(r, w)
+			ctx.SetPath("/user/:id")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(uuid.New().String())
+
+			// define mock
+			mock1 := s.userServiceMock.On("DeleteUser").Return(v.DeleteUserErr)
+			mock2 := s.JWTServiceMock.On("GetClaims").Return(v.JwtRes)
+
+			err := s.userController.DeleteUser(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			// mock1.Unset()
+			mock1.Unset()
+			mock2.Unset()
+		})
+	}
+}
+
+func (s *suiteUserController) TestChangePassword() {
+	testCase := []struct {
+		Name              string
+		Body              map[string]interface{}
+		ValidatorErr      error
+		ChangePasswordRes string
+		ChangePasswordErr error
+		ExpectedStatus    int
+		ExpectedResult    map[string]interface{}
+	}{
+		{
+			Name: "success",
+			Body: map[string]interface{}{
+				"current_password": "old",
+				"new_password":     "new",
+			},
+			ChangePasswordRes: "token",
+			ExpectedStatus:    200,
+			ExpectedResult: map[string]interface{}{
+				"message": "password changed",
+				"token":   "token",
+			},
+		},
+		{
+			Name: "body type invalid",
+			Body: map[string]interface{}{
+				"current_password": 123,
+			},
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrBadRequestBody.Error(),
+			},
+		},
+		{
+			Name:           "body invalid",
+			Body:           map[string]interface{}{},
+			ValidatorErr:   errors.New("invalid"),
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": "invalid",
+			},
+		},
+		{
+			Name: "wrong current password",
+			Body: map[string]interface{}{
+				"current_password": "wrong",
+				"new_password":     "new",
+			},
+			ChangePasswordErr: customerrors.ErrInvalidPassword,
+			ExpectedStatus:    400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrInvalidPassword.Error(),
+			},
+		},
+		{
+			Name: "same password",
+			Body: map[string]interface{}{
+				"current_password": "old",
+				"new_password":     "old",
+			},
+			ChangePasswordErr: customerrors.ErrSamePassword,
+			ExpectedStatus:    400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrSamePassword.Error(),
+			},
+		},
+		{
+			Name: "user not found",
+			Body: map[string]interface{}{
+				"current_password": "old",
+				"new_password":     "new",
+			},
+			ChangePasswordErr: customerrors.ErrNotFound,
+			ExpectedStatus:    404,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrNotFound.Error(),
+			},
+		},
+		{
+			Name: "internal server error",
+			Body: map[string]interface{}{
+				"current_password": "old",
+				"new_password":     "new",
+			},
+			ChangePasswordErr: errors.New("err"),
+			ExpectedStatus:    500,
+			ExpectedResult: map[string]interface{}{
+				"message": errors.New("err").Error(),
+			},
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			body, err := json.Marshal(v.Body)
+			s.NoError(err)
+
+			r := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(body))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			ctx := s.echoNew.NewContext(r, w)
+			ctx.SetPath("/users/password")
+
+			// define mock
+			mock1 := s.userServiceMock.On("ChangePassword").Return(v.ChangePasswordRes, v.ChangePasswordErr)
+			mock2 := s.validaorMock.On("Validate").Return(v.ValidatorErr)
+			mock3 := s.JWTServiceMock.On("GetClaims").Return(jwt.MapClaims{
+				"user_id": uuid.New().String(),
+				"sid":     uuid.New().String(),
+			})
+			err = s.userController.ChangePassword(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			mock1.Unset()
+			mock2.Unset()
+			mock3.Unset()
+		})
+	}
+}
+
+func (s *suiteUserController) TestGetUserDetail() {
+	userId := uuid.New()
+	testCase := []struct {
+		Name           string
+		FindUserRes    *dto.UserDetailResponse
+		FindUserErr    error
+		ExpectedStatus int
+		ExpectedResult map[string]interface{}
+	}{
+		{
+			Name: "success",
+			FindUserRes: &dto.UserDetailResponse{
+				UserResponse: dto.UserResponse{ID: userId},
+				RoleID:       constants.Role_user,
+			},
+			ExpectedStatus: 200,
+		},
+		{
+			Name:           "invalid id",
+			FindUserErr:    customerrors.ErrInvalidId,
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrInvalidId.Error(),
+			},
+		},
+		{
+			Name:           "not found",
+			FindUserErr:    customerrors.ErrNotFound,
+			ExpectedStatus: 404,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrNotFound.Error(),
+			},
+		},
+		{
+			Name:           "internal server error",
+			FindUserErr:    errors.New("err"),
+			ExpectedStatus: 500,
+			ExpectedResult: map[string]interface{}{
+				"message": errors.New("err").Error(),
+			},
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+
+			ctx := s.echoNew.NewContext(r, w)
+			ctx.SetPath("/users/:id")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(userId.String())
+
+			// define mock
+			mock1 := s.userServiceMock.On("FindUserDetail").Return(v.FindUserRes, v.FindUserErr)
+
+			err := s.userController.GetUserDetail(ctx)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			if v.FindUserErr == nil {
+				controllerResult := struct {
+					Message string                 `json:"message"`
+					Data    dto.UserDetailResponse `json:"data"`
+				}{}
+				err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+				s.NoError(err)
+				s.Equal("get user success", controllerResult.Message)
+				s.Equal(*v.FindUserRes, controllerResult.Data)
+			} else {
+				controllerResult := map[string]interface{}{}
+				err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+				s.NoError(err)
+				s.Equal(v.ExpectedResult, controllerResult)
+			}
+
+			mock1.Unset()
+		})
+	}
+}
+
+func (s *suiteUserController) TestChangeRole() {
+	testCase := []struct {
+		Name           string
+		Body           map[string]interface{}
+		ValidatorErr   error
+		ChangeRoleErr  error
+		ExpectedStatus int
+		ExpectedResult map[string]interface{}
+	}{
+		{
+			Name: "success",
+			Body: map[string]interface{}{
+				"role_id": constants.Role_checkpoint_operator,
+			},
+			ExpectedStatus: 200,
+			ExpectedResult: map[string]interface{}{
+				"message": "success change role",
+			},
+		},
+		{
+			Name: "body type invalid",
+			Body: map[string]interface{}{
+				"role_id": "admin",
+			},
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrBadRequestBody.Error(),
+			},
+		},
+		{
+			Name:           "body invalid",
+			Body:           map[string]interface{}{},
+			ValidatorErr:   errors.New("invalid"),
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": "invalid",
+			},
+		},
+		{
+			Name: "unknown role",
+			Body: map[string]interface{}{
+				"role_id": 100,
+			},
+			ChangeRoleErr:  customerrors.ErrInvalidRole,
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrInvalidRole.Error(),
+			},
+		},
+		{
+			Name: "default admin",
+			Body: map[string]interface{}{
+				"role_id": constants.Role_user,
+			},
+			ChangeRoleErr:  customerrors.ErrDefaultAdmin,
+			ExpectedStatus: 403,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrDefaultAdmin.Error(),
+			},
+		},
+		{
+			Name: "own account",
+			Body: map[string]interface{}{
+				"role_id": constants.Role_user,
+			},
+			ChangeRoleErr:  customerrors.ErrChangeOwnAccount,
+			ExpectedStatus: 403,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrChangeOwnAccount.Error(),
+			},
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			body, err := json.Marshal(v.Body)
+			s.NoError(err)
+
+			r := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(body))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			ctx := s.echoNew.NewContext(r, w)
+			ctx.SetPath("/users/:id/role")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(uuid.New().String())
+
+			// define mock
+			mock1 := s.userServiceMock.On("ChangeRole").Return(v.ChangeRoleErr)
+			mock2 := s.validaorMock.On("Validate").Return(v.ValidatorErr)
+			mock3 := s.JWTServiceMock.On("GetClaims").Return(jwt.MapClaims{
+				"user_id": uuid.New().String(),
+			})
+			err = s.userController.ChangeRole(ctx)
+			s.NoError(err)
+
+			controllerResult := map[string]interface{}{}
+			err = json.NewDecoder(w.Result().Body).Decode(&controllerResult)
+			s.NoError(err)
+
+			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
+			s.Equal(v.ExpectedResult, controllerResult)
+
+			mock1.Unset()
+			mock2.Unset()
+			mock3.Unset()
+		})
+	}
+}
+
+func (s *suiteUserController) TestManageUserStatus() {
+	testCase := []struct {
+		Name           string
+		Method         string
+		Handler        func(c echo.Context) error
+		ServiceErr     error
+		ExpectedStatus int
+		ExpectedResult map[string]interface{}
+	}{
+		{
+			Name:           "disable success",
+			Method:         "DisableUser",
+			Handler:        s.userController.DisableUser,
+			ExpectedStatus: 200,
+			ExpectedResult: map[string]interface{}{
+				"message": "user disabled",
+			},
+		},
+		{
+			Name:           "disable own account",
+			Method:         "DisableUser",
+			Handler:        s.userController.DisableUser,
+			ServiceErr:     customerrors.ErrChangeOwnAccount,
+			ExpectedStatus: 403,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrChangeOwnAccount.Error(),
+			},
+		},
+		{
+			Name:           "disable user not found",
+			Method:         "DisableUser",
+			Handler:        s.userController.DisableUser,
+			ServiceErr:     customerrors.ErrNotFound,
+			ExpectedStatus: 404,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrNotFound.Error(),
+			},
+		},
+		{
+			Name:           "enable success",
+			Method:         "EnableUser",
+			Handler:        s.userController.EnableUser,
+			ExpectedStatus: 200,
+			ExpectedResult: map[string]interface{}{
+				"message": "user enabled",
+			},
+		},
+		{
+			Name:           "enable default admin",
+			Method:         "EnableUser",
+			Handler:        s.userController.EnableUser,
+			ServiceErr:     customerrors.ErrDefaultAdmin,
+			ExpectedStatus: 403,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrDefaultAdmin.Error(),
+			},
+		},
+		{
+			Name:           "force password reset success",
+			Method:         "ForcePasswordReset",
+			Handler:        s.userController.ForcePasswordReset,
+			ExpectedStatus: 200,
+			ExpectedResult: map[string]interface{}{
+				"message": "user must reset password",
+			},
+		},
+		{
+			Name:           "force password reset invalid id",
+			Method:         "ForcePasswordReset",
+			Handler:        s.userController.ForcePasswordReset,
+			ServiceErr:     customerrors.ErrInvalidId,
+			ExpectedStatus: 400,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrInvalidId.Error(),
+			},
+		},
+		{
+			Name:           "unlock success",
+			Method:         "UnlockUser",
+			Handler:        s.userController.UnlockUser,
+			ExpectedStatus: 200,
+			ExpectedResult: map[string]interface{}{
+				"message": "user unlocked",
+			},
+		},
+		{
+			Name:           "unlock user not found",
+			Method:         "UnlockUser",
+			Handler:        s.userController.UnlockUser,
+			ServiceErr:     customerrors.ErrNotFound,
+			ExpectedStatus: 404,
+			ExpectedResult: map[string]interface{}{
+				"message": customerrors.ErrNotFound.Error(),
+			},
+		},
+		{
+			Name:           "force password reset internal error",
+			Method:         "ForcePasswordReset",
+			Handler:        s.userController.ForcePasswordReset,
+			ServiceErr:     errors.New("err"),
+			ExpectedStatus: 500,
+			ExpectedResult: map[string]interface{}{
+				"message": errors.New("err").Error(),
+			},
+		},
+	}
+
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/", nil)
+			w := httptest.NewRecorder()
+
+			ctx := s.echoNew.NewContext(r, w)
+			ctx.SetPath("/users/:id")
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(uuid.New().String())
+
+			// define mock
+			mock1 := s.userServiceMock.On(v.Method).Return(v.ServiceErr)
+			mock2 := s.JWTServiceMock.On("GetClaims").Return(jwt.MapClaims{
+				"user_id": uuid.New().String(),
+			})
+
+			err := v.Handler(ctx)
 			s.NoError(err)
 
 			controllerResult := map[string]interface{}{}
@@ -621,7 +1678,6 @@ func (s *suiteUserController) TestDeleteUser() {
 			s.Equal(v.ExpectedStatus, w.Result().StatusCode)
 			s.Equal(v.ExpectedResult, controllerResult)
 
-			// mock1.Unset()
 			mock1.Unset()
 			mock2.Unset()
 		})
@@ -1,13 +1,32 @@
 package dto
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
+// UserQuery is sort and filter allowed on user list
+var UserQuery = query.Schema{
+	Sort: map[string]string{
+		"name":       "name",
+		"email":      "email",
+		"created_at": "created_at",
+	},
+	DefaultSort: "created_at",
+	Key:         "id",
+	Filter: map[string]query.Filter{
+		"name":    {Column: "name", Type: query.String, Operator: query.Contain},
+		"email":   {Column: "email", Type: query.String, Operator: query.Contain},
+		"role_id": {Column: "role_id", Type: query.Uint, Operator: query.Equal},
+	},
+}
+
 type UserSignup struct {
 	Name     string `json:"name"  validate:"required"`
-	Email    string `json:"email"  validate:"required"`
+	Email    string `json:"email"  validate:"required,email"`
 	Password string `json:"password"  validate:"required"`
 }
 
@@ -69,3 +88,24 @@ func (u *UsersResponse) FromModel(model []model.User) {
 		*u = append(*u, user)
 	}
 }
+
+type ChangeRoleRequest struct {
+	RoleID uint `json:"role_id" validate:"required"`
+}
+
+// UserDetailResponse is user data for admin, with role and account status
+type UserDetailResponse struct {
+	UserResponse
+	RoleID             uint       `json:"role_id"`
+	VerifiedAt         *time.Time `json:"verified_at"`
+	DisabledAt         *time.Time `json:"disabled_at"`
+	MustChangePassword bool       `json:"must_change_password"`
+}
+
+func (u *UserDetailResponse) FromModel(model *model.User) {
+	u.UserResponse.FromModel(model)
+	u.RoleID = model.RoleID
+	u.VerifiedAt = model.VerifiedAt
+	u.DisabledAt = model.DisabledAt
+	u.MustChangePassword = model.MustChangePassword
+}
@@ -2,6 +2,7 @@ package dto
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
@@ -269,3 +270,50 @@ func TestUsersResponse_FromModel(t *testing.T) {
 		})
 	}
 }
+
+func TestUserDetailResponse_FromModel(t *testing.T) {
+	userId := uuid.New()
+	now := time.Now()
+
+	testCase := []struct {
+		Name     string
+		Model    *model.User
+		Expected UserDetailResponse
+	}{
+		{
+			Name: "all filled",
+			Model: &model.User{
+				ID:                 userId,
+				Name:               "test",
+				Email:              "test@gmail.com",
+				RoleID:             2,
+				VerifiedAt:         &now,
+				DisabledAt:         &now,
+				MustChangePassword: true,
+			},
+			Expected: UserDetailResponse{
+				UserResponse: UserResponse{
+					ID:    userId,
+					Name:  "test",
+					Email: "test@gmail.com",
+				},
+				RoleID:             2,
+				VerifiedAt:         &now,
+				DisabledAt:         &now,
+				MustChangePassword: true,
+			},
+		},
+		{
+			Name:     "empty",
+			Model:    &model.User{},
+			Expected: UserDetailResponse{},
+		},
+	}
+	for _, v := range testCase {
+		t.Run(v.Name, func(t *testing.T) {
+			var result UserDetailResponse
+			result.FromModel(v.Model)
+			assert.Equal(t, v.Expected, result)
+		})
+	}
+}
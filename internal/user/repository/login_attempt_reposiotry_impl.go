@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type loginAttemptRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// FindLoginAttempt implements LoginAttemptRepository
+func (r *loginAttemptRepositoryImpl) FindLoginAttempt(identifier string, ctx context.Context) (*model.LoginAttempt, error) {
+	attempt := model.LoginAttempt{}
+	err := r.db.WithContext(ctx).Where("identifier = ?", identifier).First(&attempt).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, customerrors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// RecordLoginFailure implements LoginAttemptRepository.
+// Counter is incremented in one upsert so concurrent failure is not lost
+func (r *loginAttemptRepositoryImpl) RecordLoginFailure(identifier string, failedAt time.Time, windowStart time.Time, ctx context.Context) (*model.LoginAttempt, error) {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "identifier"}},
+		// failures must be set before last_failed_at, mysql use the new value of column assigned before
+		DoUpdates: clause.Set{
+			{
+				Column: clause.Column{Name: "failures"},
+				Value:  gorm.Expr("CASE WHEN last_failed_at < ? AND (locked_until IS NULL OR locked_until < ?) THEN 1 ELSE failures + 1 END", windowStart, windowStart),
+			},
+			{
+				Column: clause.Column{Name: "last_failed_at"},
+				Value:  failedAt,
+			},
+		},
+	}).Create(&model.LoginAttempt{
+		Identifier:   identifier,
+		Failures:     1,
+		LastFailedAt: failedAt,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.FindLoginAttempt(identifier, ctx)
+}
+
+// LockLoginAttempt implements LoginAttemptRepository
+func (r *loginAttemptRepositoryImpl) LockLoginAttempt(identifier string, lockedUntil time.Time, ctx context.Context) error {
+	return r.db.WithContext(ctx).Model(&model.LoginAttempt{}).Where("identifier = ?", identifier).Update("locked_until", lockedUntil).Error
+}
+
+// DeleteLoginAttempt implements LoginAttemptRepository
+func (r *loginAttemptRepositoryImpl) DeleteLoginAttempt(identifier string, ctx context.Context) error {
+	return r.db.WithContext(ctx).Where("identifier = ?", identifier).Delete(&model.LoginAttempt{}).Error
+}
+
+func NewLoginAttemptDatabaseRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepositoryImpl{
+		db: db,
+	}
+}
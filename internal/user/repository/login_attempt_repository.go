@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"gorm.io/gorm"
+)
+
+type LoginAttemptRepository interface {
+	FindLoginAttempt(identifier string, ctx context.Context) (*model.LoginAttempt, error)
+	// RecordLoginFailure add one failure and return the updated attempt,
+	// failure count start again from 1 when there is no failure and no lock since windowStart
+	RecordLoginFailure(identifier string, failedAt time.Time, windowStart time.Time, ctx context.Context) (*model.LoginAttempt, error)
+	LockLoginAttempt(identifier string, lockedUntil time.Time, ctx context.Context) error
+	DeleteLoginAttempt(identifier string, ctx context.Context) error
+}
+
+// NewLoginAttemptRepository return repository of configured store, database is used when store is empty
+func NewLoginAttemptRepository(store string, db *gorm.DB) (LoginAttemptRepository, error) {
+	switch store {
+	case "", constants.Login_attempt_store_database:
+		return NewLoginAttemptDatabaseRepository(db), nil
+	case constants.Login_attempt_store_memory:
+		return NewLoginAttemptMemoryRepository(), nil
+	default:
+		return nil, customerrors.ErrLoginAttemptStore
+	}
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+)
+
+type loginAttemptMemoryRepository struct {
+	mu        sync.Mutex
+	attempts  map[string]model.LoginAttempt
+	lastSweep time.Time
+}
+
+// FindLoginAttempt implements LoginAttemptRepository
+func (r *loginAttemptMemoryRepository) FindLoginAttempt(identifier string, ctx context.Context) (*model.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	attempt, ok := r.attempts[identifier]
+	if !ok {
+		return nil, customerrors.ErrNotFound
+	}
+	return &attempt, nil
+}
+
+// RecordLoginFailure implements LoginAttemptRepository
+func (r *loginAttemptMemoryRepository) RecordLoginFailure(identifier string, failedAt time.Time, windowStart time.Time, ctx context.Context) (*model.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sweep(failedAt, windowStart)
+	attempt, ok := r.attempts[identifier]
+	if !ok || loginAttemptForgotten(attempt, windowStart) {
+		attempt = model.LoginAttempt{Identifier: identifier}
+	}
+	attempt.Failures++
+	attempt.LastFailedAt = failedAt
+	r.attempts[identifier] = attempt
+	return &attempt, nil
+}
+
+// LockLoginAttempt implements LoginAttemptRepository
+func (r *loginAttemptMemoryRepository) LockLoginAttempt(identifier string, lockedUntil time.Time, ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if attempt, ok := r.attempts[identifier]; ok {
+		attempt.LockedUntil = &lockedUntil
+		r.attempts[identifier] = attempt
+	}
+	return nil
+}
+
+// DeleteLoginAttempt implements LoginAttemptRepository
+func (r *loginAttemptMemoryRepository) DeleteLoginAttempt(identifier string, ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.attempts, identifier)
+	return nil
+}
+
+// sweep remove forgotten attempt at most once a window, so map dont grow with every ip ever seen
+func (r *loginAttemptMemoryRepository) sweep(now time.Time, windowStart time.Time) {
+	if now.Sub(r.lastSweep) < now.Sub(windowStart) {
+		return
+	}
+	for identifier, attempt := range r.attempts {
+		if loginAttemptForgotten(attempt, windowStart) {
+			delete(r.attempts, identifier)
+		}
+	}
+	r.lastSweep = now
+}
+
+// same rule as database repository, no failure and no lock since windowStart
+func loginAttemptForgotten(attempt model.LoginAttempt, windowStart time.Time) bool {
+	return attempt.LastFailedAt.Before(windowStart) && (attempt.LockedUntil == nil || attempt.LockedUntil.Before(windowStart))
+}
+
+func NewLoginAttemptMemoryRepository() LoginAttemptRepository {
+	return &loginAttemptMemoryRepository{
+		attempts: map[string]model.LoginAttempt{},
+	}
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/database/testdb"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// both store must behave the same
+func loginAttemptRepositories(t *testing.T) map[string]LoginAttemptRepository {
+	return map[string]LoginAttemptRepository{
+		constants.Login_attempt_store_database: NewLoginAttemptDatabaseRepository(testdb.New(t)),
+		constants.Login_attempt_store_memory:   NewLoginAttemptMemoryRepository(),
+	}
+}
+
+func TestLoginAttemptRepository(t *testing.T) {
+	for store, repository := range loginAttemptRepositories(t) {
+		t.Run(store, func(t *testing.T) {
+			ctx := context.Background()
+			now := time.Date(2022, 11, 1, 10, 0, 0, 0, time.UTC)
+			window := constants.Login_attempt_window
+
+			_, err := repository.FindLoginAttempt("email:test@gmail.com", ctx)
+			assert.Equal(t, customerrors.ErrNotFound, err)
+
+			for i := 1; i <= 3; i++ {
+				attempt, err := repository.RecordLoginFailure("email:test@gmail.com", now, now.Add(-window), ctx)
+				assert.NoError(t, err)
+				assert.Equal(t, i, attempt.Failures)
+			}
+			attempt, err := repository.RecordLoginFailure("ip:127.0.0.1", now, now.Add(-window), ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, attempt.Failures)
+
+			lockedUntil := now.Add(time.Hour)
+			assert.NoError(t, repository.LockLoginAttempt("email:test@gmail.com", lockedUntil, ctx))
+			attempt, err = repository.FindLoginAttempt("email:test@gmail.com", ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 3, attempt.Failures)
+			assert.True(t, lockedUntil.Equal(*attempt.LockedUntil))
+
+			// lock is in window, failure keep counting so next lock is longer
+			later := now.Add(window + time.Minute)
+			attempt, err = repository.RecordLoginFailure("email:test@gmail.com", later, later.Add(-window), ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 4, attempt.Failures)
+
+			// no failure and lock in window, counter start again
+			attempt, err = repository.RecordLoginFailure("ip:127.0.0.1", later, later.Add(-window), ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, attempt.Failures)
+
+			assert.NoError(t, repository.DeleteLoginAttempt("email:test@gmail.com", ctx))
+			_, err = repository.FindLoginAttempt("email:test@gmail.com", ctx)
+			assert.Equal(t, customerrors.ErrNotFound, err)
+		})
+	}
+}
+
+func TestRecordLoginFailureConcurrent(t *testing.T) {
+	for store, repository := range loginAttemptRepositories(t) {
+		t.Run(store, func(t *testing.T) {
+			ctx := context.Background()
+			now := time.Now()
+
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, err := repository.RecordLoginFailure("ip:127.0.0.1", now, now.Add(-constants.Login_attempt_window), ctx)
+					assert.NoError(t, err)
+				}()
+			}
+			wg.Wait()
+
+			attempt, err := repository.FindLoginAttempt("ip:127.0.0.1", ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 10, attempt.Failures)
+		})
+	}
+}
+
+func TestNewLoginAttemptRepository(t *testing.T) {
+	_, err := NewLoginAttemptRepository("redis", nil)
+	assert.Equal(t, customerrors.ErrLoginAttemptStore, err)
+}
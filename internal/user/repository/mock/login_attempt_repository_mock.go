@@ -0,0 +1,33 @@
+package mock
+
+import (
+	"context"
+	"time"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type LoginAttemptRepositoryMock struct {
+	mock.Mock
+}
+
+func (b *LoginAttemptRepositoryMock) FindLoginAttempt(identifier string, ctx context.Context) (*model.LoginAttempt, error) {
+	args := b.Called(identifier)
+	return args.Get(0).(*model.LoginAttempt), args.Error(1)
+}
+
+func (b *LoginAttemptRepositoryMock) RecordLoginFailure(identifier string, failedAt time.Time, windowStart time.Time, ctx context.Context) (*model.LoginAttempt, error) {
+	args := b.Called(identifier)
+	return args.Get(0).(*model.LoginAttempt), args.Error(1)
+}
+
+func (b *LoginAttemptRepositoryMock) LockLoginAttempt(identifier string, lockedUntil time.Time, ctx context.Context) error {
+	args := b.Called(identifier, lockedUntil)
+	return args.Error(0)
+}
+
+func (b *LoginAttemptRepositoryMock) DeleteLoginAttempt(identifier string, ctx context.Context) error {
+	args := b.Called(identifier)
+	return args.Error(0)
+}
@@ -0,0 +1,49 @@
+package mock
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type SessionRepositoryMock struct {
+	mock.Mock
+}
+
+func (b *SessionRepositoryMock) CreateSession(session *model.Session, token *model.RefreshToken, ctx context.Context) error {
+	args := b.Called(session, token)
+	return args.Error(0)
+}
+
+func (b *SessionRepositoryMock) FindSessionById(session *model.Session, ctx context.Context) error {
+	args := b.Called(session)
+	return args.Error(0)
+}
+
+func (b *SessionRepositoryMock) FindRefreshToken(hash string, ctx context.Context) (*model.RefreshToken, error) {
+	args := b.Called(hash)
+	return args.Get(0).(*model.RefreshToken), args.Error(1)
+}
+
+func (b *SessionRepositoryMock) RotateRefreshToken(used *model.RefreshToken, next *model.RefreshToken, usedAt time.Time, ctx context.Context) error {
+	args := b.Called(used, next)
+	return args.Error(0)
+}
+
+func (b *SessionRepositoryMock) RevokeSession(id uuid.UUID, revokedAt time.Time, ctx context.Context) error {
+	args := b.Called(id)
+	return args.Error(0)
+}
+
+func (b *SessionRepositoryMock) RevokeUserSessions(userId uuid.UUID, revokedAt time.Time, ctx context.Context) error {
+	args := b.Called(userId)
+	return args.Error(0)
+}
+
+func (b *SessionRepositoryMock) RevokeOtherSessions(userId uuid.UUID, sessionId uuid.UUID, revokedAt time.Time, ctx context.Context) error {
+	args := b.Called(userId, sessionId)
+	return args.Error(0)
+}
@@ -2,8 +2,11 @@ package mock
 
 import (
 	"context"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -17,10 +20,10 @@ func (b *UserRepositoryMock) CreateUser(user *model.User, ctx context.Context) e
 	return args.Error(0)
 }
 
-func (b *UserRepositoryMock) FindAllUsers(ctx context.Context) ([]model.User, error) {
+func (b *UserRepositoryMock) FindAllUsers(opts query.Options, ctx context.Context) ([]model.User, int64, error) {
 	args := b.Called()
 
-	return args.Get(0).([]model.User), args.Error(1)
+	return args.Get(0).([]model.User), args.Get(1).(int64), args.Error(2)
 }
 
 func (b *UserRepositoryMock) FindUserByEmail(email string, ctx context.Context) (*model.User, error) {
@@ -52,3 +55,27 @@ func (b *UserRepositoryMock) InitRole() error {
 
 	return args.Error(0)
 }
+
+func (b *UserRepositoryMock) UpdatePassword(id uuid.UUID, password string, ctx context.Context) error {
+	args := b.Called(id, password)
+
+	return args.Error(0)
+}
+
+func (b *UserRepositoryMock) UpdateRole(id uuid.UUID, roleId uint, ctx context.Context) error {
+	args := b.Called(id, roleId)
+
+	return args.Error(0)
+}
+
+func (b *UserRepositoryMock) UpdateDisabled(id uuid.UUID, disabledAt *time.Time, ctx context.Context) error {
+	args := b.Called(id, disabledAt)
+
+	return args.Error(0)
+}
+
+func (b *UserRepositoryMock) ForcePasswordChange(id uuid.UUID, ctx context.Context) error {
+	args := b.Called(id)
+
+	return args.Error(0)
+}
@@ -0,0 +1,33 @@
+package mock
+
+import (
+	"context"
+	"time"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type UserTokenRepositoryMock struct {
+	mock.Mock
+}
+
+func (b *UserTokenRepositoryMock) CreateUserToken(token *model.UserToken, ctx context.Context) error {
+	args := b.Called(token)
+	return args.Error(0)
+}
+
+func (b *UserTokenRepositoryMock) FindUserToken(hash string, purpose string, ctx context.Context) (*model.UserToken, error) {
+	args := b.Called(hash, purpose)
+	return args.Get(0).(*model.UserToken), args.Error(1)
+}
+
+func (b *UserTokenRepositoryMock) VerifyEmail(token *model.UserToken, usedAt time.Time, ctx context.Context) error {
+	args := b.Called(token)
+	return args.Error(0)
+}
+
+func (b *UserTokenRepositoryMock) ResetPassword(token *model.UserToken, password string, usedAt time.Time, ctx context.Context) error {
+	args := b.Called(token, password)
+	return args.Error(0)
+}
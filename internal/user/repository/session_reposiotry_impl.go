@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"gorm.io/gorm"
+)
+
+type sessionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// CreateSession implements SessionRepository
+func (r *sessionRepositoryImpl) CreateSession(session *model.Session, token *model.RefreshToken, ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+		token.SessionID = session.ID
+		return tx.Omit("Session").Create(token).Error
+	})
+}
+
+// FindSessionById implements SessionRepository
+func (r *sessionRepositoryImpl) FindSessionById(session *model.Session, ctx context.Context) error {
+	err := r.db.WithContext(ctx).First(session).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return customerrors.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// FindRefreshToken implements SessionRepository
+func (r *sessionRepositoryImpl) FindRefreshToken(hash string, ctx context.Context) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := r.db.WithContext(ctx).Preload("Session").Where("hash = ?", hash).First(&token).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, customerrors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RotateRefreshToken implements SessionRepository
+func (r *sessionRepositoryImpl) RotateRefreshToken(used *model.RefreshToken, next *model.RefreshToken, usedAt time.Time, ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// compare and set, so two request with same refresh token cant both get new token
+		res := tx.Model(&model.RefreshToken{}).Where("id = ? AND used_at IS NULL", used.ID).Update("used_at", usedAt)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return customerrors.ErrTokenUsed
+		}
+		next.SessionID = used.SessionID
+		return tx.Omit("Session").Create(next).Error
+	})
+}
+
+// RevokeSession implements SessionRepository
+func (r *sessionRepositoryImpl) RevokeSession(id uuid.UUID, revokedAt time.Time, ctx context.Context) error {
+	return r.db.WithContext(ctx).Model(&model.Session{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", revokedAt).Error
+}
+
+// RevokeUserSessions implements SessionRepository
+func (r *sessionRepositoryImpl) RevokeUserSessions(userId uuid.UUID, revokedAt time.Time, ctx context.Context) error {
+	return r.db.WithContext(ctx).Model(&model.Session{}).Where("user_id = ? AND revoked_at IS NULL", userId).Update("revoked_at", revokedAt).Error
+}
+
+// RevokeOtherSessions implements SessionRepository
+func (r *sessionRepositoryImpl) RevokeOtherSessions(userId uuid.UUID, sessionId uuid.UUID, revokedAt time.Time, ctx context.Context) error {
+	return r.db.WithContext(ctx).Model(&model.Session{}).Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userId, sessionId).Update("revoked_at", revokedAt).Error
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepositoryImpl{
+		db: db,
+	}
+}
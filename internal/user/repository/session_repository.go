@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+)
+
+type SessionRepository interface {
+	CreateSession(session *model.Session, token *model.RefreshToken, ctx context.Context) error
+	FindSessionById(session *model.Session, ctx context.Context) error
+	FindRefreshToken(hash string, ctx context.Context) (*model.RefreshToken, error)
+	RotateRefreshToken(used *model.RefreshToken, next *model.RefreshToken, usedAt time.Time, ctx context.Context) error
+	RevokeSession(id uuid.UUID, revokedAt time.Time, ctx context.Context) error
+	RevokeUserSessions(userId uuid.UUID, revokedAt time.Time, ctx context.Context) error
+	RevokeOtherSessions(userId uuid.UUID, sessionId uuid.UUID, revokedAt time.Time, ctx context.Context) error
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/database/testdb"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestUser(t *testing.T, repository *userRepositoryImpl) model.User {
+	assert.NoError(t, repository.InitRole())
+	user := model.User{
+		ID:       uuid.New(),
+		Name:     "test",
+		Email:    uuid.NewString() + "@gmail.com",
+		Password: "123",
+		RoleID:   constants.Role_user,
+	}
+	assert.NoError(t, repository.db.Create(&user).Error)
+	return user
+}
+
+func TestSessionRepository(t *testing.T) {
+	db := testdb.New(t)
+	user := newTestUser(t, &userRepositoryImpl{db: db})
+	repository := NewSessionRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	session := model.Session{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		ExpiredAt: now.Add(time.Hour),
+	}
+	assert.NoError(t, repository.CreateSession(&session, &model.RefreshToken{Hash: "first"}, ctx))
+
+	token, err := repository.FindRefreshToken("first", ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, session.ID, token.SessionID)
+	assert.Equal(t, user.ID, token.Session.UserID)
+	assert.Nil(t, token.UsedAt)
+
+	_, err = repository.FindRefreshToken("unknown", ctx)
+	assert.Equal(t, customerrors.ErrNotFound, err)
+
+	assert.NoError(t, repository.RotateRefreshToken(token, &model.RefreshToken{Hash: "second"}, now, ctx))
+	used, err := repository.FindRefreshToken("first", ctx)
+	assert.NoError(t, err)
+	assert.NotNil(t, used.UsedAt)
+	next, err := repository.FindRefreshToken("second", ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, session.ID, next.SessionID)
+
+	// used token cant be rotated again
+	assert.Equal(t, customerrors.ErrTokenUsed, repository.RotateRefreshToken(token, &model.RefreshToken{Hash: "third"}, now, ctx))
+	_, err = repository.FindRefreshToken("third", ctx)
+	assert.Equal(t, customerrors.ErrNotFound, err)
+
+	assert.NoError(t, repository.RevokeSession(session.ID, now, ctx))
+	found := model.Session{ID: session.ID}
+	assert.NoError(t, repository.FindSessionById(&found, ctx))
+	assert.NotNil(t, found.RevokedAt)
+
+	assert.Equal(t, customerrors.ErrNotFound, repository.FindSessionById(&model.Session{ID: uuid.New()}, ctx))
+}
+
+func TestRevokeUserSessions(t *testing.T) {
+	db := testdb.New(t)
+	userRepository := &userRepositoryImpl{db: db}
+	user := newTestUser(t, userRepository)
+	other := newTestUser(t, userRepository)
+	repository := NewSessionRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	sessions := []model.Session{
+		{ID: uuid.New(), UserID: user.ID, ExpiredAt: now.Add(time.Hour)},
+		{ID: uuid.New(), UserID: user.ID, ExpiredAt: now.Add(time.Hour)},
+		{ID: uuid.New(), UserID: other.ID, ExpiredAt: now.Add(time.Hour)},
+	}
+	for i := range sessions {
+		assert.NoError(t, repository.CreateSession(&sessions[i], &model.RefreshToken{Hash: uuid.NewString()}, ctx))
+	}
+
+	assert.NoError(t, repository.RevokeUserSessions(user.ID, now, ctx))
+
+	for _, session := range sessions {
+		found := model.Session{ID: session.ID}
+		assert.NoError(t, repository.FindSessionById(&found, ctx))
+		assert.Equal(t, session.UserID == user.ID, found.RevokedAt != nil)
+	}
+}
+
+func TestRotateRefreshTokenConcurrent(t *testing.T) {
+	const requests = 20
+	db := testdb.New(t)
+	user := newTestUser(t, &userRepositoryImpl{db: db})
+	repository := NewSessionRepository(db)
+	ctx := context.Background()
+
+	session := model.Session{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+	assert.NoError(t, repository.CreateSession(&session, &model.RefreshToken{Hash: "first"}, ctx))
+	token, err := repository.FindRefreshToken("first", ctx)
+	assert.NoError(t, err)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		success int
+		errs    []error
+	)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			used := *token
+			err := repository.RotateRefreshToken(&used, &model.RefreshToken{Hash: uuid.NewString()}, time.Now(), ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				success++
+			} else {
+				errs = append(errs, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, success)
+	for _, err := range errs {
+		assert.Equal(t, customerrors.ErrTokenUsed, err)
+	}
+	var count int64
+	assert.NoError(t, db.Model(&model.RefreshToken{}).Where("session_id = ?", session.ID).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/database/testdb"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateAccount(t *testing.T) {
+	db := testdb.New(t)
+	repository := &userRepositoryImpl{db: db}
+	user := newTestUser(t, repository)
+	ctx := context.Background()
+	now := time.Now()
+
+	find := func() model.User {
+		found := model.User{ID: user.ID}
+		assert.NoError(t, db.First(&found).Error)
+		return found
+	}
+
+	assert.NoError(t, repository.ForcePasswordChange(user.ID, ctx))
+	assert.True(t, find().MustChangePassword)
+
+	// changing password clear the forced change flag
+	assert.NoError(t, repository.UpdatePassword(user.ID, "456", ctx))
+	found := find()
+	assert.Equal(t, "456", found.Password)
+	assert.False(t, found.MustChangePassword)
+	assert.Equal(t, customerrors.ErrNotFound, repository.UpdatePassword(uuid.New(), "456", ctx))
+
+	assert.NoError(t, repository.UpdateRole(user.ID, constants.Role_admin, ctx))
+	assert.Equal(t, uint(constants.Role_admin), find().RoleID)
+	// updated_at always change, same role is still affected row
+	assert.NoError(t, repository.UpdateRole(user.ID, constants.Role_admin, ctx))
+	assert.Equal(t, customerrors.ErrNotFound, repository.UpdateRole(uuid.New(), constants.Role_admin, ctx))
+
+	assert.NoError(t, repository.UpdateDisabled(user.ID, &now, ctx))
+	assert.NotNil(t, find().DisabledAt)
+	assert.NoError(t, repository.UpdateDisabled(user.ID, nil, ctx))
+	assert.Nil(t, find().DisabledAt)
+	assert.Equal(t, customerrors.ErrNotFound, repository.UpdateDisabled(uuid.New(), nil, ctx))
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	db := testdb.New(t)
+	userRepository := &userRepositoryImpl{db: db}
+	user := newTestUser(t, userRepository)
+	other := newTestUser(t, userRepository)
+	repository := NewSessionRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	sessions := []model.Session{
+		{ID: uuid.New(), UserID: user.ID, ExpiredAt: now.Add(time.Hour)},
+		{ID: uuid.New(), UserID: user.ID, ExpiredAt: now.Add(time.Hour)},
+		{ID: uuid.New(), UserID: other.ID, ExpiredAt: now.Add(time.Hour)},
+	}
+	for i := range sessions {
+		assert.NoError(t, repository.CreateSession(&sessions[i], &model.RefreshToken{Hash: uuid.NewString()}, ctx))
+	}
+
+	assert.NoError(t, repository.RevokeOtherSessions(user.ID, sessions[0].ID, now, ctx))
+
+	for i, session := range sessions {
+		found := model.Session{ID: session.ID}
+		assert.NoError(t, repository.FindSessionById(&found, ctx))
+		assert.Equal(t, i == 1, found.RevokedAt != nil)
+	}
+}
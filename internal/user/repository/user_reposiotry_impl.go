@@ -3,11 +3,15 @@ package repository
 import (
 	"context"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type userRepositoryImpl struct {
@@ -29,7 +33,7 @@ func (u *userRepositoryImpl) CreateUser(user *model.User, ctx context.Context) e
 // FindUserByEmail implements UserRepository
 func (u *userRepositoryImpl) FindUserByEmail(email string, ctx context.Context) (*model.User, error) {
 	var user model.User
-	err := u.db.WithContext(ctx).Select([]string{"id", "email", "password", "role_id"}).Where("email = ?", email).First(&user).Error
+	err := u.db.WithContext(ctx).Select([]string{"id", "name", "email", "password", "role_id", "verified_at", "disabled_at", "must_change_password"}).Where("email = ?", email).First(&user).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, customerrors.ErrNotFound
@@ -53,10 +57,13 @@ func (u *userRepositoryImpl) FindUserByID(id string, ctx context.Context) (*mode
 }
 
 // FindAllUsers implements UserRepository
-func (u *userRepositoryImpl) FindAllUsers(ctx context.Context) ([]model.User, error) {
+func (u *userRepositoryImpl) FindAllUsers(opts query.Options, ctx context.Context) ([]model.User, int64, error) {
 	var users []model.User
-	err := u.db.WithContext(ctx).Preload("Province").Preload("Regency").Preload("District").Preload("Village").Find(&users).Error
-	return users, err
+	total, err := query.Find(u.db.WithContext(ctx).Model(&model.User{}).Preload("Province").Preload("Regency").Preload("District").Preload("Village"), opts, &users)
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
 }
 
 // UpdateUser implements UserRepository
@@ -79,6 +86,50 @@ func (u *userRepositoryImpl) UpdateUser(user *model.User, ctx context.Context) e
 	return nil
 }
 
+// UpdatePassword implements UserRepository
+func (u *userRepositoryImpl) UpdatePassword(id uuid.UUID, password string, ctx context.Context) error {
+	res := u.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"password":             password,
+		"must_change_password": false,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return customerrors.ErrNotFound
+	}
+	return nil
+}
+
+// UpdateRole implements UserRepository
+func (u *userRepositoryImpl) UpdateRole(id uuid.UUID, roleId uint, ctx context.Context) error {
+	res := u.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("role_id", roleId)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return customerrors.ErrNotFound
+	}
+	return nil
+}
+
+// UpdateDisabled implements UserRepository, nil disabledAt enable the user
+func (u *userRepositoryImpl) UpdateDisabled(id uuid.UUID, disabledAt *time.Time, ctx context.Context) error {
+	res := u.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("disabled_at", disabledAt)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return customerrors.ErrNotFound
+	}
+	return nil
+}
+
+// ForcePasswordChange implements UserRepository
+func (u *userRepositoryImpl) ForcePasswordChange(id uuid.UUID, ctx context.Context) error {
+	return u.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("must_change_password", true).Error
+}
+
 // DeleteUser implements UserRepository
 func (u *userRepositoryImpl) DeleteUser(user *model.User, ctx context.Context) error {
 	res := u.db.WithContext(ctx).Delete(user)
@@ -93,17 +144,32 @@ func (u *userRepositoryImpl) DeleteUser(user *model.User, ctx context.Context) e
 
 // InitRole implements UserRepository
 func (u *userRepositoryImpl) InitRole() error {
-	var count int64
-	err := u.db.Model(&model.Role{}).Count(&count).Error
-	if err != nil {
-		return err
-	}
-	if count >= 1 {
+	return u.db.Transaction(func(tx *gorm.DB) error {
+		// only create missing role and permission, so role added later also exist in old database
+		roles := append([]model.Role(nil), constants.Role...)
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&roles).Error; err != nil {
+			return err
+		}
+		permissions := append([]model.Permission(nil), constants.Permission...)
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&permissions).Error; err != nil {
+			return err
+		}
+		permissionByName := map[string]model.Permission{}
+		for _, permission := range permissions {
+			permissionByName[permission.Name] = permission
+		}
+		// replace role permission, so permission revoked in constants also revoked in database
+		for i := range roles {
+			var rolePermissions []model.Permission
+			for _, name := range constants.RolePermission[roles[i].ID] {
+				rolePermissions = append(rolePermissions, permissionByName[name])
+			}
+			if err := tx.Model(&roles[i]).Association("Permissions").Replace(rolePermissions); err != nil {
+				return err
+			}
+		}
 		return nil
-	}
-	role := constants.Role
-	err = u.db.Create(&role).Error
-	return err
+	})
 }
 
 func NewUserRepository(db *gorm.DB) UserRepository {
@@ -2,16 +2,23 @@ package repository
 
 import (
 	"context"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
 type UserRepository interface {
 	CreateUser(user *model.User, ctx context.Context) error
 	UpdateUser(user *model.User, ctx context.Context) error
 	DeleteUser(user *model.User, ctx context.Context) error
-	FindAllUsers(ctx context.Context) ([]model.User, error)
+	FindAllUsers(opts query.Options, ctx context.Context) ([]model.User, int64, error)
 	FindUserByEmail(email string, ctx context.Context) (*model.User, error)
 	FindUserByID(id string, ctx context.Context) (*model.User, error)
+	UpdatePassword(id uuid.UUID, password string, ctx context.Context) error
+	UpdateRole(id uuid.UUID, roleId uint, ctx context.Context) error
+	UpdateDisabled(id uuid.UUID, disabledAt *time.Time, ctx context.Context) error
+	ForcePasswordChange(id uuid.UUID, ctx context.Context) error
 	InitRole() error
 }
@@ -8,12 +8,15 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/user/dto"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"github.com/stretchr/testify/suite"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"net/url"
 )
 
 type suiteUserRepository struct {
@@ -77,7 +80,7 @@ func (s *suiteUserRepository) TestCreateUser() {
 	for _, v := range testCase {
 		s.T().Run(v.Name, func(t *testing.T) {
 			s.mock.ExpectBegin()
-			db := s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `users` (`id`,`created_at`,`updated_at`,`deleted_at`,`name`,`email`,`phone`,`password`,`role_id`,`province_id`,`regency_id`,`district_id`,`village_id`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?)"))
+			db := s.mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `users` (`id`,`created_at`,`updated_at`,`deleted_at`,`name`,`email`,`phone`,`password`,`verified_at`,`disabled_at`,`must_change_password`,`role_id`,`province_id`,`regency_id`,`district_id`,`village_id`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)"))
 			if v.ExpectedErr != nil {
 				db.WillReturnError(v.MockReturn)
 				s.mock.ExpectRollback()
@@ -128,7 +131,7 @@ func (s *suiteUserRepository) TestFindUserByEmail() {
 	row := sqlmock.NewRows([]string{"email"}).AddRow("test@gmail.com")
 	for _, v := range testCase {
 		s.T().Run(v.Name, func(t *testing.T) {
-			db := s.mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`,`email`,`password`,`role_id` FROM `users` WHERE email = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1"))
+			db := s.mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`,`name`,`email`,`password`,`role_id`,`verified_at`,`disabled_at`,`must_change_password` FROM `users` WHERE email = ? AND `users`.`deleted_at` IS NULL ORDER BY `users`.`id` LIMIT 1"))
 			if v.ExpectedErr != nil {
 				db.WillReturnError(v.MockReturn)
 			} else {
@@ -213,17 +216,21 @@ func (s *suiteUserRepository) TestFindAllUser() {
 	row := sqlmock.NewRows([]string{"email"}).AddRow("test@gmail.com")
 	for _, v := range testCase {
 		s.T().Run(v.Name, func(t *testing.T) {
-			db := s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE `users`.`deleted_at` IS NULL"))
+			s.mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `users` WHERE `role_id` = ? AND `users`.`deleted_at` IS NULL")).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(len(v.ExpeckedResult)))
+			db := s.mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `users` WHERE `role_id` = ? AND `users`.`deleted_at` IS NULL ORDER BY `created_at`,`id` LIMIT 20"))
 			if v.ExpectedErr != nil {
 				db.WillReturnError(v.MockReturn)
 			} else {
 				db.WillReturnRows(row)
 			}
 			var ctx context.Context
-			res, err := s.repository.FindAllUsers(ctx)
+			opts, err := query.Parse(url.Values{"role_id": {"2"}}, dto.UserQuery)
+			s.NoError(err)
+			res, total, err := s.repository.FindAllUsers(opts, ctx)
 
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(v.ExpeckedResult, res)
+			s.Equal(int64(len(v.ExpeckedResult)), total)
 		})
 	}
 }
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/database/testdb"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitRole(t *testing.T) {
+	db := testdb.New(t)
+	repository := &userRepositoryImpl{db: db}
+
+	// init twice like restarting app with old database
+	assert.NoError(t, repository.InitRole())
+	assert.NoError(t, repository.InitRole())
+
+	var roles []model.Role
+	assert.NoError(t, db.Preload("Permissions").Order("id").Find(&roles).Error)
+	assert.Len(t, roles, len(constants.Role))
+	for _, role := range roles {
+		var names []string
+		for _, permission := range role.Permissions {
+			names = append(names, permission.Name)
+		}
+		assert.ElementsMatch(t, constants.RolePermission[role.ID], names, role.Name)
+	}
+
+	var permissions int64
+	assert.NoError(t, db.Model(&model.Permission{}).Count(&permissions).Error)
+	assert.Equal(t, int64(len(constants.Permission)), permissions)
+}
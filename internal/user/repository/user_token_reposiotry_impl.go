@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"gorm.io/gorm"
+)
+
+type userTokenRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// CreateUserToken implements UserTokenRepository
+func (r *userTokenRepositoryImpl) CreateUserToken(token *model.UserToken, ctx context.Context) error {
+	return r.db.WithContext(ctx).Omit("User").Create(token).Error
+}
+
+// FindUserToken implements UserTokenRepository
+func (r *userTokenRepositoryImpl) FindUserToken(hash string, purpose string, ctx context.Context) (*model.UserToken, error) {
+	var token model.UserToken
+	err := r.db.WithContext(ctx).Where("hash = ? AND purpose = ?", hash, purpose).First(&token).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, customerrors.ErrNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// VerifyEmail implements UserTokenRepository
+func (r *userTokenRepositoryImpl) VerifyEmail(token *model.UserToken, usedAt time.Time, ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := useUserToken(tx, token, usedAt); err != nil {
+			return err
+		}
+		// keep first verified time when user verify again with another token
+		res := tx.Model(&model.User{}).Where("id = ? AND verified_at IS NULL", token.UserID).Update("verified_at", usedAt)
+		return res.Error
+	})
+}
+
+// ResetPassword implements UserTokenRepository
+func (r *userTokenRepositoryImpl) ResetPassword(token *model.UserToken, password string, usedAt time.Time, ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := useUserToken(tx, token, usedAt); err != nil {
+			return err
+		}
+		res := tx.Model(&model.User{}).Where("id = ?", token.UserID).Updates(map[string]interface{}{
+			"password":             password,
+			"must_change_password": false,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return customerrors.ErrNotFound
+		}
+		return nil
+	})
+}
+
+// useUserToken mark token used with compare and set, so same token cant be used twice concurrently
+func useUserToken(tx *gorm.DB, token *model.UserToken, usedAt time.Time) error {
+	res := tx.Model(&model.UserToken{}).Where("id = ? AND used_at IS NULL", token.ID).Update("used_at", usedAt)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return customerrors.ErrTokenUsed
+	}
+	return nil
+}
+
+func NewUserTokenRepository(db *gorm.DB) UserTokenRepository {
+	return &userTokenRepositoryImpl{
+		db: db,
+	}
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+)
+
+type UserTokenRepository interface {
+	CreateUserToken(token *model.UserToken, ctx context.Context) error
+	FindUserToken(hash string, purpose string, ctx context.Context) (*model.UserToken, error)
+	VerifyEmail(token *model.UserToken, usedAt time.Time, ctx context.Context) error
+	ResetPassword(token *model.UserToken, password string, usedAt time.Time, ctx context.Context) error
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/database/testdb"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyEmail(t *testing.T) {
+	db := testdb.New(t)
+	user := newTestUser(t, &userRepositoryImpl{db: db})
+	repository := NewUserTokenRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	token := model.UserToken{
+		UserID:    user.ID,
+		Purpose:   constants.Token_purpose_verify_email,
+		Hash:      "verify",
+		ExpiredAt: now.Add(time.Hour),
+	}
+	assert.NoError(t, repository.CreateUserToken(&token, ctx))
+
+	// token of other purpose cant be found
+	_, err := repository.FindUserToken("verify", constants.Token_purpose_reset_password, ctx)
+	assert.Equal(t, customerrors.ErrNotFound, err)
+
+	found, err := repository.FindUserToken("verify", constants.Token_purpose_verify_email, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, found.UserID)
+	assert.Nil(t, found.UsedAt)
+
+	assert.NoError(t, repository.VerifyEmail(found, now, ctx))
+	var result model.User
+	assert.NoError(t, db.First(&result, "id = ?", user.ID).Error)
+	assert.NotNil(t, result.VerifiedAt)
+
+	assert.Equal(t, customerrors.ErrTokenUsed, repository.VerifyEmail(found, now, ctx))
+	found, err = repository.FindUserToken("verify", constants.Token_purpose_verify_email, ctx)
+	assert.NoError(t, err)
+	assert.NotNil(t, found.UsedAt)
+}
+
+func TestResetPasswordConcurrent(t *testing.T) {
+	const requests = 20
+	db := testdb.New(t)
+	user := newTestUser(t, &userRepositoryImpl{db: db})
+	repository := NewUserTokenRepository(db)
+	ctx := context.Background()
+
+	token := model.UserToken{
+		UserID:    user.ID,
+		Purpose:   constants.Token_purpose_reset_password,
+		Hash:      "reset",
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+	assert.NoError(t, repository.CreateUserToken(&token, ctx))
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		success int
+		errs    []error
+	)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			used := token
+			err := repository.ResetPassword(&used, "new-password", time.Now(), ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				success++
+			} else {
+				errs = append(errs, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, success)
+	for _, err := range errs {
+		assert.Equal(t, customerrors.ErrTokenUsed, err)
+	}
+	var result model.User
+	assert.NoError(t, db.First(&result, "id = ?", user.ID).Error)
+	assert.Equal(t, "new-password", result.Password)
+}
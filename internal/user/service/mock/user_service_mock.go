@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/user/dto"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -29,16 +30,34 @@ func (b *UserServiceMock) UpdateUser(id string, user dto.UserUpdate, ctx context
 
 	return args.Error(0)
 }
-func (b *UserServiceMock) FindAllUsers(ctx context.Context) (dto.UsersResponse, error) {
+func (b *UserServiceMock) FindAllUsers(opts query.Options, ctx context.Context) (dto.UsersResponse, *query.Page, error) {
 	args := b.Called()
 
-	return args.Get(0).(dto.UsersResponse), args.Error(1)
+	return args.Get(0).(dto.UsersResponse), args.Get(1).(*query.Page), args.Error(2)
 }
 
-func (b *UserServiceMock) Login(user dto.LoginRequest, ctx context.Context) (string, error) {
+func (b *UserServiceMock) Login(user dto.LoginRequest, ip string, ctx context.Context) (*dto.TokenResponse, error) {
 	args := b.Called()
 
-	return args.Get(0).(string), args.Error(1)
+	return args.Get(0).(*dto.TokenResponse), args.Error(1)
+}
+
+func (b *UserServiceMock) RefreshToken(body dto.RefreshTokenRequest, ctx context.Context) (*dto.TokenResponse, error) {
+	args := b.Called()
+
+	return args.Get(0).(*dto.TokenResponse), args.Error(1)
+}
+
+func (b *UserServiceMock) Logout(body dto.RefreshTokenRequest, ctx context.Context) error {
+	args := b.Called()
+
+	return args.Error(0)
+}
+
+func (b *UserServiceMock) CheckSession(sessionId string, ctx context.Context) error {
+	args := b.Called(sessionId)
+
+	return args.Error(0)
 }
 
 func (b *UserServiceMock) FindUser(id string, ctx context.Context) (*dto.UserResponse, error) {
@@ -52,3 +71,69 @@ func (b *UserServiceMock) DeleteUser(id string, ctx context.Context) error {
 
 	return args.Error(0)
 }
+
+func (b *UserServiceMock) VerifyEmail(body dto.VerifyEmailRequest, ctx context.Context) error {
+	args := b.Called()
+
+	return args.Error(0)
+}
+
+func (b *UserServiceMock) ResendVerification(body dto.EmailRequest, ctx context.Context) error {
+	args := b.Called()
+
+	return args.Error(0)
+}
+
+func (b *UserServiceMock) ForgotPassword(body dto.EmailRequest, ctx context.Context) error {
+	args := b.Called()
+
+	return args.Error(0)
+}
+
+func (b *UserServiceMock) ResetPassword(body dto.ResetPasswordRequest, ctx context.Context) error {
+	args := b.Called()
+
+	return args.Error(0)
+}
+
+func (b *UserServiceMock) ChangePassword(id string, sessionId string, body dto.ChangePasswordRequest, ctx context.Context) (string, error) {
+	args := b.Called()
+
+	return args.String(0), args.Error(1)
+}
+
+func (b *UserServiceMock) FindUserDetail(id string, ctx context.Context) (*dto.UserDetailResponse, error) {
+	args := b.Called()
+
+	return args.Get(0).(*dto.UserDetailResponse), args.Error(1)
+}
+
+func (b *UserServiceMock) ChangeRole(actorId string, id string, body dto.ChangeRoleRequest, ctx context.Context) error {
+	args := b.Called()
+
+	return args.Error(0)
+}
+
+func (b *UserServiceMock) DisableUser(actorId string, id string, ctx context.Context) error {
+	args := b.Called()
+
+	return args.Error(0)
+}
+
+func (b *UserServiceMock) EnableUser(actorId string, id string, ctx context.Context) error {
+	args := b.Called()
+
+	return args.Error(0)
+}
+
+func (b *UserServiceMock) ForcePasswordReset(id string, ctx context.Context) error {
+	args := b.Called()
+
+	return args.Error(0)
+}
+
+func (b *UserServiceMock) UnlockUser(id string, ctx context.Context) error {
+	args := b.Called()
+
+	return args.Error(0)
+}
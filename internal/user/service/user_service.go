@@ -5,14 +5,29 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/user/dto"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
 type UserService interface {
 	CreateDefaultAdmin() error
 	CreateUser(user dto.UserSignup, ctx context.Context) (uuid.UUID, error)
+	VerifyEmail(body dto.VerifyEmailRequest, ctx context.Context) error
+	ResendVerification(body dto.EmailRequest, ctx context.Context) error
+	ForgotPassword(body dto.EmailRequest, ctx context.Context) error
+	ResetPassword(body dto.ResetPasswordRequest, ctx context.Context) error
 	UpdateUser(id string, user dto.UserUpdate, ctx context.Context) error
-	FindAllUsers(ctx context.Context) (dto.UsersResponse, error)
-	Login(user dto.LoginRequest, ctx context.Context) (string, error)
+	FindAllUsers(opts query.Options, ctx context.Context) (dto.UsersResponse, *query.Page, error)
+	Login(user dto.LoginRequest, ip string, ctx context.Context) (*dto.TokenResponse, error)
+	RefreshToken(body dto.RefreshTokenRequest, ctx context.Context) (*dto.TokenResponse, error)
+	Logout(body dto.RefreshTokenRequest, ctx context.Context) error
+	CheckSession(sessionId string, ctx context.Context) error
 	FindUser(id string, ctx context.Context) (*dto.UserResponse, error)
 	DeleteUser(id string, ctx context.Context) error
+	ChangePassword(id string, sessionId string, body dto.ChangePasswordRequest, ctx context.Context) (string, error)
+	FindUserDetail(id string, ctx context.Context) (*dto.UserDetailResponse, error)
+	ChangeRole(actorId string, id string, body dto.ChangeRoleRequest, ctx context.Context) error
+	DisableUser(actorId string, id string, ctx context.Context) error
+	EnableUser(actorId string, id string, ctx context.Context) error
+	ForcePasswordReset(id string, ctx context.Context) error
+	UnlockUser(id string, ctx context.Context) error
 }
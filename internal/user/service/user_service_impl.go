@@ -2,6 +2,14 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/user/dto"
@@ -9,6 +17,8 @@ import (
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/mailer"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
 )
 
 type PasswordHashFunction interface {
@@ -17,50 +27,254 @@ type PasswordHashFunction interface {
 }
 
 type JWTService interface {
-	GenerateToken(user *model.User) (string, error)
+	GenerateToken(user *model.User, sessionId uuid.UUID) (string, error)
 }
+
+type Clock interface {
+	Now() time.Time
+}
+
+type Mailer interface {
+	Send(message mailer.Message) error
+}
+
+// bcrypt hash of random password with same cost as real one, compared when email not registered
+const dummyPasswordHash = "$2a$14$BQP4aysHX/NCNWbEEgYng.c4L0pW/INAnxkNltAE37Ykso8WdnqTG"
+
+type userTokenMail struct {
+	exp     time.Duration
+	subject string
+	body    string
+}
+
+// mail sent for each user token purpose, body is formatted with user name and token
+var userTokenMails = map[string]userTokenMail{
+	constants.Token_purpose_verify_email: {
+		exp:     constants.ExpVerifyEmailToken,
+		subject: "Verify your email",
+		body:    "Hi %s,\n\nUse this token to verify your email:\n\n%s\n\nThe token expires in 24 hours.",
+	},
+	constants.Token_purpose_reset_password: {
+		exp:     constants.ExpResetPasswordToken,
+		subject: "Reset your password",
+		body:    "Hi %s,\n\nUse this token to reset your password:\n\n%s\n\nThe token expires in 30 minutes. Ignore this email if you did not ask to reset your password.",
+	},
+}
+
 type userServiceImpl struct {
-	repo       repository.UserRepository
-	password   PasswordHashFunction
-	jwtService JWTService
+	repo             repository.UserRepository
+	sessionRepo      repository.SessionRepository
+	tokenRepo        repository.UserTokenRepository
+	loginAttemptRepo repository.LoginAttemptRepository
+	password         PasswordHashFunction
+	jwtService       JWTService
+	mailer           Mailer
+	clock            Clock
 }
 
-// Login implements UserService
-func (u *userServiceImpl) Login(user dto.LoginRequest, ctx context.Context) (string, error) {
+// Login implements UserService.
+// Unknown email and wrong password give the same error, failure is counted per account and per ip
+func (u *userServiceImpl) Login(user dto.LoginRequest, ip string, ctx context.Context) (*dto.TokenResponse, error) {
+	now := u.clock.Now()
+	limits := loginLimits(user.Email, ip)
+	for _, limit := range limits {
+		attempt, err := u.loginAttemptRepo.FindLoginAttempt(limit.identifier, ctx)
+		if err == customerrors.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if attempt.LockedUntil != nil && attempt.LockedUntil.After(now) {
+			return nil, customerrors.ErrLoginLocked
+		}
+	}
 	userModel, err := u.repo.FindUserByEmail(user.Email, ctx)
 	if err != nil {
-		return "", err
+		if err != customerrors.ErrNotFound {
+			return nil, err
+		}
+		// hash anyway so response time dont tell the email is not registered
+		u.password.CheckPasswordHash(user.Password, dummyPasswordHash)
+		return nil, u.loginFailed(limits, now, ctx)
 	}
 	if !u.password.CheckPasswordHash(user.Password, userModel.Password) {
-		return "", customerrors.ErrInvalidPassword
+		return nil, u.loginFailed(limits, now, ctx)
 	}
-	token, err := u.jwtService.GenerateToken(userModel)
+	// ip failure is kept, one valid account must not clear failures of other account from same ip
+	err = u.loginAttemptRepo.DeleteLoginAttempt(limits[0].identifier, ctx)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if userModel.DisabledAt != nil {
+		return nil, customerrors.ErrUserDisabled
+	}
+	refreshToken, refreshTokenModel, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	session := model.Session{
+		ID:        uuid.New(),
+		UserID:    userModel.ID,
+		ExpiredAt: u.clock.Now().Add(constants.ExpRefreshToken),
+	}
+	err = u.sessionRepo.CreateSession(&session, refreshTokenModel, ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := u.jwtService.GenerateToken(userModel, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.TokenResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// RefreshToken implements UserService
+func (u *userServiceImpl) RefreshToken(body dto.RefreshTokenRequest, ctx context.Context) (*dto.TokenResponse, error) {
+	used, err := u.findRefreshToken(body.RefreshToken, ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := u.clock.Now()
+	// used refresh token is sent again, token may be stolen so whole session is revoked
+	if used.UsedAt != nil {
+		return nil, u.revokeStolenSession(used.SessionID, ctx)
+	}
+	// token carry current role, deleted user cant refresh
+	user, err := u.repo.FindUserByID(used.Session.UserID.String(), ctx)
+	if err != nil {
+		if err == customerrors.ErrNotFound {
+			return nil, u.revokeStolenSession(used.SessionID, ctx)
+		}
+		return nil, err
+	}
+	if user.DisabledAt != nil {
+		return nil, u.revokeStolenSession(used.SessionID, ctx)
+	}
+	refreshToken, next, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	err = u.sessionRepo.RotateRefreshToken(used, next, now, ctx)
+	if err != nil {
+		if err == customerrors.ErrTokenUsed {
+			return nil, u.revokeStolenSession(used.SessionID, ctx)
+		}
+		return nil, err
+	}
+	token, err := u.jwtService.GenerateToken(user, used.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.TokenResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// Logout implements UserService
+func (u *userServiceImpl) Logout(body dto.RefreshTokenRequest, ctx context.Context) error {
+	token, err := u.findRefreshToken(body.RefreshToken, ctx)
+	if err != nil {
+		return err
+	}
+	return u.sessionRepo.RevokeSession(token.SessionID, u.clock.Now(), ctx)
+}
+
+// CheckSession implements UserService
+func (u *userServiceImpl) CheckSession(sessionId string, ctx context.Context) error {
+	id, err := uuid.Parse(sessionId)
+	if err != nil {
+		return customerrors.ErrInvalidToken
+	}
+	session := model.Session{
+		ID: id,
+	}
+	err = u.sessionRepo.FindSessionById(&session, ctx)
+	if err != nil {
+		if err == customerrors.ErrNotFound {
+			return customerrors.ErrTokenRevoked
+		}
+		return err
+	}
+	if session.RevokedAt != nil || !u.clock.Now().Before(session.ExpiredAt) {
+		return customerrors.ErrTokenRevoked
+	}
+	return nil
+}
+
+// findRefreshToken return refresh token of active session
+func (u *userServiceImpl) findRefreshToken(refreshToken string, ctx context.Context) (*model.RefreshToken, error) {
+	token, err := u.sessionRepo.FindRefreshToken(hashToken(refreshToken), ctx)
+	if err != nil {
+		if err == customerrors.ErrNotFound {
+			return nil, customerrors.ErrInvalidToken
+		}
+		return nil, err
+	}
+	if token.Session.RevokedAt != nil || !u.clock.Now().Before(token.Session.ExpiredAt) {
+		return nil, customerrors.ErrInvalidToken
 	}
 	return token, nil
 }
 
+func (u *userServiceImpl) revokeStolenSession(sessionId uuid.UUID, ctx context.Context) error {
+	if err := u.sessionRepo.RevokeSession(sessionId, u.clock.Now(), ctx); err != nil {
+		return err
+	}
+	return customerrors.ErrInvalidToken
+}
+
+// newRefreshToken return random refresh token for client and its hash to be saved
+func newRefreshToken() (string, *model.RefreshToken, error) {
+	token, err := newRandomToken(constants.Refresh_token_length)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, &model.RefreshToken{Hash: hashToken(token)}, nil
+}
+
+func newRandomToken(length int) (string, error) {
+	b := make([]byte, length)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken return sha256 hash of token, token is random so it dont need slow hash like password
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
 // CreateAdmin implements UserService
 func (u *userServiceImpl) CreateDefaultAdmin() error {
 	var ctx context.Context
-	users, err := u.repo.FindAllUsers(ctx)
+	_, total, err := u.repo.FindAllUsers(query.Options{Limit: 1}, ctx)
 	if err != nil {
 		return err
 	}
-	if len(users) > 0 {
+	if total > 0 {
 		return nil
 	}
 	hashPassword, err := u.password.HashPassword(constants.Default_password_admin)
 	if err != nil {
 		return err
 	}
+	now := u.clock.Now()
 	admin := model.User{
-		ID:       uuid.New(),
-		Name:     "admin",
-		Email:    constants.Default_email_admin,
-		Password: hashPassword,
-		RoleID:   constants.Role_admin,
+		ID:         uuid.New(),
+		Name:       "admin",
+		Email:      constants.Default_email_admin,
+		Password:   hashPassword,
+		VerifiedAt: &now,
+		RoleID:     constants.Role_admin,
+		// default password is public, admin must change it on first login
+		MustChangePassword: true,
 	}
 	err = u.repo.CreateUser(&admin, ctx)
 	return err
@@ -81,18 +295,134 @@ func (u *userServiceImpl) CreateUser(user dto.UserSignup, ctx context.Context) (
 	if err != nil {
 		return uuid.Nil, err
 	}
+	// account is already created, user can ask verification email again
+	if err := u.sendUserToken(userModel, constants.Token_purpose_verify_email, ctx); err != nil {
+		log.Printf("send verification email to user %s: %v", newID, err)
+	}
 	return newID, nil
 }
 
-// FindAllUsers implements UserService
-func (u *userServiceImpl) FindAllUsers(ctx context.Context) (dto.UsersResponse, error) {
-	users, err := u.repo.FindAllUsers(ctx)
+// VerifyEmail implements UserService
+func (u *userServiceImpl) VerifyEmail(body dto.VerifyEmailRequest, ctx context.Context) error {
+	token, err := u.findUserToken(body.Token, constants.Token_purpose_verify_email, ctx)
+	if err != nil {
+		return err
+	}
+	err = u.tokenRepo.VerifyEmail(token, u.clock.Now(), ctx)
+	if err != nil {
+		if err == customerrors.ErrTokenUsed {
+			return customerrors.ErrInvalidToken
+		}
+		return err
+	}
+	return nil
+}
+
+// ResendVerification implements UserService
+func (u *userServiceImpl) ResendVerification(body dto.EmailRequest, ctx context.Context) error {
+	user, err := u.repo.FindUserByEmail(body.Email, ctx)
+	if err != nil {
+		// same response for unknown email, so email of user cant be guessed
+		if err == customerrors.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+	if user.VerifiedAt != nil {
+		return nil
+	}
+	if err := u.sendUserToken(user, constants.Token_purpose_verify_email, ctx); err != nil {
+		log.Printf("send verification email to user %s: %v", user.ID, err)
+	}
+	return nil
+}
+
+// ForgotPassword implements UserService
+func (u *userServiceImpl) ForgotPassword(body dto.EmailRequest, ctx context.Context) error {
+	user, err := u.repo.FindUserByEmail(body.Email, ctx)
+	if err != nil {
+		// same response for unknown email, so email of user cant be guessed
+		if err == customerrors.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+	if err := u.sendUserToken(user, constants.Token_purpose_reset_password, ctx); err != nil {
+		log.Printf("send reset password email to user %s: %v", user.ID, err)
+	}
+	return nil
+}
+
+// ResetPassword implements UserService
+func (u *userServiceImpl) ResetPassword(body dto.ResetPasswordRequest, ctx context.Context) error {
+	token, err := u.findUserToken(body.Token, constants.Token_purpose_reset_password, ctx)
+	if err != nil {
+		return err
+	}
+	hashPassword, err := u.password.HashPassword(body.Password)
+	if err != nil {
+		return err
+	}
+	now := u.clock.Now()
+	err = u.tokenRepo.ResetPassword(token, hashPassword, now, ctx)
+	if err != nil {
+		if err == customerrors.ErrTokenUsed || err == customerrors.ErrNotFound {
+			return customerrors.ErrInvalidToken
+		}
+		return err
+	}
+	// whoever knew old password must login again
+	return u.sessionRepo.RevokeUserSessions(token.UserID, now, ctx)
+}
+
+// sendUserToken save new user token of purpose and send it to user email
+func (u *userServiceImpl) sendUserToken(user *model.User, purpose string, ctx context.Context) error {
+	mail := userTokenMails[purpose]
+	token, err := newRandomToken(constants.User_token_length)
+	if err != nil {
+		return err
+	}
+	userToken := model.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		Hash:      hashToken(token),
+		ExpiredAt: u.clock.Now().Add(mail.exp),
+	}
+	err = u.tokenRepo.CreateUserToken(&userToken, ctx)
+	if err != nil {
+		return err
+	}
+	return u.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: mail.subject,
+		Body:    fmt.Sprintf(mail.body, user.Name, token),
+	})
+}
+
+// findUserToken return unused and unexpired user token of purpose
+func (u *userServiceImpl) findUserToken(token string, purpose string, ctx context.Context) (*model.UserToken, error) {
+	userToken, err := u.tokenRepo.FindUserToken(hashToken(token), purpose, ctx)
 	if err != nil {
+		if err == customerrors.ErrNotFound {
+			return nil, customerrors.ErrInvalidToken
+		}
 		return nil, err
 	}
+	if userToken.UsedAt != nil || !u.clock.Now().Before(userToken.ExpiredAt) {
+		return nil, customerrors.ErrInvalidToken
+	}
+	return userToken, nil
+}
+
+// FindAllUsers implements UserService
+func (u *userServiceImpl) FindAllUsers(opts query.Options, ctx context.Context) (dto.UsersResponse, *query.Page, error) {
+	users, total, err := u.repo.FindAllUsers(opts, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
 	var usersDto dto.UsersResponse
 	usersDto.FromModel(users)
-	return usersDto, nil
+	return usersDto, query.NewPage(opts, total), nil
 }
 
 // FindUser implements UserService
@@ -122,6 +452,157 @@ func (u *userServiceImpl) UpdateUser(id string, user dto.UserUpdate, ctx context
 	return err
 }
 
+// ChangePassword implements UserService, it return new access token without must change password claim
+func (u *userServiceImpl) ChangePassword(id string, sessionId string, body dto.ChangePasswordRequest, ctx context.Context) (string, error) {
+	idUUID, err := uuid.Parse(id)
+	if err != nil {
+		return "", customerrors.ErrInvalidId
+	}
+	sessionIdUUID, err := uuid.Parse(sessionId)
+	if err != nil {
+		return "", customerrors.ErrInvalidId
+	}
+	user, err := u.repo.FindUserByID(id, ctx)
+	if err != nil {
+		return "", err
+	}
+	if !u.password.CheckPasswordHash(body.CurrentPassword, user.Password) {
+		return "", customerrors.ErrInvalidPassword
+	}
+	if body.NewPassword == body.CurrentPassword {
+		return "", customerrors.ErrSamePassword
+	}
+	hashPassword, err := u.password.HashPassword(body.NewPassword)
+	if err != nil {
+		return "", err
+	}
+	err = u.repo.UpdatePassword(idUUID, hashPassword, ctx)
+	if err != nil {
+		return "", err
+	}
+	// other device that knew old password must login again, current session keep going
+	err = u.sessionRepo.RevokeOtherSessions(idUUID, sessionIdUUID, u.clock.Now(), ctx)
+	if err != nil {
+		return "", err
+	}
+	user.MustChangePassword = false
+	return u.jwtService.GenerateToken(user, sessionIdUUID)
+}
+
+// FindUserDetail implements UserService
+func (u *userServiceImpl) FindUserDetail(id string, ctx context.Context) (*dto.UserDetailResponse, error) {
+	_, err := uuid.Parse(id)
+	if err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	user, err := u.repo.FindUserByID(id, ctx)
+	if err != nil {
+		return nil, err
+	}
+	var userDto dto.UserDetailResponse
+	userDto.FromModel(user)
+	return &userDto, nil
+}
+
+// ChangeRole implements UserService
+func (u *userServiceImpl) ChangeRole(actorId string, id string, body dto.ChangeRoleRequest, ctx context.Context) error {
+	user, err := u.findManagedUser(actorId, id, ctx)
+	if err != nil {
+		return err
+	}
+	if !roleExist(body.RoleID) {
+		return customerrors.ErrInvalidRole
+	}
+	err = u.repo.UpdateRole(user.ID, body.RoleID, ctx)
+	if err != nil {
+		return err
+	}
+	// access token carry role, so old role is revoked with the sessions
+	return u.sessionRepo.RevokeUserSessions(user.ID, u.clock.Now(), ctx)
+}
+
+// DisableUser implements UserService
+func (u *userServiceImpl) DisableUser(actorId string, id string, ctx context.Context) error {
+	user, err := u.findManagedUser(actorId, id, ctx)
+	if err != nil {
+		return err
+	}
+	if user.DisabledAt != nil {
+		return nil
+	}
+	now := u.clock.Now()
+	err = u.repo.UpdateDisabled(user.ID, &now, ctx)
+	if err != nil {
+		return err
+	}
+	return u.sessionRepo.RevokeUserSessions(user.ID, now, ctx)
+}
+
+// EnableUser implements UserService
+func (u *userServiceImpl) EnableUser(actorId string, id string, ctx context.Context) error {
+	user, err := u.findManagedUser(actorId, id, ctx)
+	if err != nil {
+		return err
+	}
+	if user.DisabledAt == nil {
+		return nil
+	}
+	return u.repo.UpdateDisabled(user.ID, nil, ctx)
+}
+
+// ForcePasswordReset implements UserService
+func (u *userServiceImpl) ForcePasswordReset(id string, ctx context.Context) error {
+	idUUID, err := uuid.Parse(id)
+	if err != nil {
+		return customerrors.ErrInvalidId
+	}
+	user, err := u.repo.FindUserByID(id, ctx)
+	if err != nil {
+		return err
+	}
+	err = u.repo.ForcePasswordChange(idUUID, ctx)
+	if err != nil {
+		return err
+	}
+	err = u.sessionRepo.RevokeUserSessions(idUUID, u.clock.Now(), ctx)
+	if err != nil {
+		return err
+	}
+	// user can reset with email token, or login with old password and change it
+	if err := u.sendUserToken(user, constants.Token_purpose_reset_password, ctx); err != nil {
+		log.Printf("send reset password email to user %s: %v", user.ID, err)
+	}
+	return nil
+}
+
+// findManagedUser return user whose role or status can be changed by actor
+func (u *userServiceImpl) findManagedUser(actorId string, id string, ctx context.Context) (*model.User, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, customerrors.ErrInvalidId
+	}
+	// admin cant lock himself out
+	if actorId == id {
+		return nil, customerrors.ErrChangeOwnAccount
+	}
+	user, err := u.repo.FindUserByID(id, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if user.Email == constants.Default_email_admin {
+		return nil, customerrors.ErrDefaultAdmin
+	}
+	return user, nil
+}
+
+func roleExist(roleId uint) bool {
+	for _, role := range constants.Role {
+		if role.ID == roleId {
+			return true
+		}
+	}
+	return false
+}
+
 // DeleteUser implements UserService
 func (u *userServiceImpl) DeleteUser(id string, ctx context.Context) error {
 	idUUID, err := uuid.Parse(id)
@@ -132,14 +613,80 @@ func (u *userServiceImpl) DeleteUser(id string, ctx context.Context) error {
 		ID: idUUID,
 	}
 	err = u.repo.DeleteUser(&user, ctx)
-	return err
+	if err != nil {
+		return err
+	}
+	// deleted user lose access now, not when access token expired
+	return u.sessionRepo.RevokeUserSessions(idUUID, u.clock.Now(), ctx)
+}
+
+// UnlockUser implements UserService, clear failed login of the account
+func (u *userServiceImpl) UnlockUser(id string, ctx context.Context) error {
+	user, err := u.repo.FindUserByID(id, ctx)
+	if err != nil {
+		return err
+	}
+	return u.loginAttemptRepo.DeleteLoginAttempt(loginAccountIdentifier(user.Email), ctx)
+}
+
+// loginFailed count failure of every limit and lock the one that reach its max
+func (u *userServiceImpl) loginFailed(limits []loginLimit, now time.Time, ctx context.Context) error {
+	for _, limit := range limits {
+		attempt, err := u.loginAttemptRepo.RecordLoginFailure(limit.identifier, now, now.Add(-constants.Login_attempt_window), ctx)
+		if err != nil {
+			return err
+		}
+		if attempt.Failures < limit.maxFailure {
+			continue
+		}
+		err = u.loginAttemptRepo.LockLoginAttempt(limit.identifier, now.Add(loginLockDuration(attempt.Failures-limit.maxFailure)), ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return customerrors.ErrInvalidCredentials
+}
+
+type loginLimit struct {
+	identifier string
+	maxFailure int
+}
+
+func loginLimits(email string, ip string) []loginLimit {
+	limits := []loginLimit{{identifier: loginAccountIdentifier(email), maxFailure: constants.Login_max_account_failure}}
+	if ip != "" {
+		limits = append(limits, loginLimit{identifier: "ip:" + ip, maxFailure: constants.Login_max_ip_failure})
+	}
+	return limits
+}
+
+// email is lowered, mysql compare email case insensitive so other case is same account
+func loginAccountIdentifier(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// loginLockDuration double the lock on each failure after max failure reached
+func loginLockDuration(overLimit int) time.Duration {
+	duration := constants.Login_lock_base
+	for i := 0; i < overLimit && duration < constants.Login_lock_max; i++ {
+		duration *= 2
+	}
+	if duration > constants.Login_lock_max {
+		return constants.Login_lock_max
+	}
+	return duration
 }
 
-func NewUserService(repository repository.UserRepository, password PasswordHashFunction, jwt JWTService) UserService {
+func NewUserService(repository repository.UserRepository, sessionRepository repository.SessionRepository, tokenRepository repository.UserTokenRepository, loginAttemptRepository repository.LoginAttemptRepository, password PasswordHashFunction, jwt JWTService, mailer Mailer, clock Clock) UserService {
 	userService := &userServiceImpl{
-		repo:       repository,
-		password:   password,
-		jwtService: jwt,
+		repo:             repository,
+		sessionRepo:      sessionRepository,
+		tokenRepo:        tokenRepository,
+		loginAttemptRepo: loginAttemptRepository,
+		password:         password,
+		jwtService:       jwt,
+		mailer:           mailer,
+		clock:            clock,
 	}
 	err := userService.CreateDefaultAdmin()
 	if err != nil {
@@ -4,39 +4,64 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/user/dto"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/user/repository"
 	um "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/user/repository/mock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	clockMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/clock/mock"
 	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/mailer"
+	mailerMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/mailer/mock"
 	mm "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/middleware/mock"
 	pm "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/password/mock"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/query"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
 
 type suiteUserService struct {
 	suite.Suite
-	userRepositoryMock *um.UserRepositoryMock
-	passwordMock       *pm.PasswordMock
-	JWTServiceMock     *mm.MockJWTService
-	userService        UserService
+	userRepositoryMock    *um.UserRepositoryMock
+	sessionRepositoryMock *um.SessionRepositoryMock
+	tokenRepositoryMock   *um.UserTokenRepositoryMock
+	loginAttemptMock      *um.LoginAttemptRepositoryMock
+	passwordMock          *pm.PasswordMock
+	JWTServiceMock        *mm.MockJWTService
+	mailerMock            *mailerMock.MailerMock
+	clockMock             *clockMock.ClockMock
+	userService           UserService
 }
 
-func newUserServiceMock(repository repository.UserRepository, password PasswordHashFunction, jwt JWTService) UserService {
+func newUserServiceMock(repository repository.UserRepository, sessionRepository repository.SessionRepository, tokenRepository repository.UserTokenRepository, loginAttemptRepository repository.LoginAttemptRepository, password PasswordHashFunction, jwt JWTService, mailer Mailer, clock Clock) UserService {
 	return &userServiceImpl{
-		repo:       repository,
-		password:   password,
-		jwtService: jwt,
+		repo:             repository,
+		sessionRepo:      sessionRepository,
+		tokenRepo:        tokenRepository,
+		loginAttemptRepo: loginAttemptRepository,
+		password:         password,
+		jwtService:       jwt,
+		mailer:           mailer,
+		clock:            clock,
 	}
 }
 
+var now = time.Date(2022, 11, 1, 10, 0, 0, 0, time.UTC)
+
 func (s *suiteUserService) SetupTest() {
 	s.userRepositoryMock = new(um.UserRepositoryMock)
+	s.sessionRepositoryMock = new(um.SessionRepositoryMock)
+	s.tokenRepositoryMock = new(um.UserTokenRepositoryMock)
+	s.loginAttemptMock = new(um.LoginAttemptRepositoryMock)
 	s.passwordMock = new(pm.PasswordMock)
 	s.JWTServiceMock = new(mm.MockJWTService)
-	s.userService = newUserServiceMock(s.userRepositoryMock, s.passwordMock, s.JWTServiceMock)
+	s.mailerMock = new(mailerMock.MailerMock)
+	s.clockMock = new(clockMock.ClockMock)
+	s.clockMock.On("Now").Return(now)
+	s.userService = newUserServiceMock(s.userRepositoryMock, s.sessionRepositoryMock, s.tokenRepositoryMock, s.loginAttemptMock, s.passwordMock, s.JWTServiceMock, s.mailerMock, s.clockMock)
 }
 
 func (s *suiteUserService) TestCreateUser() {
@@ -89,34 +114,65 @@ func (s *suiteUserService) TestCreateUser() {
 				"createUser": errors.New("err"),
 			},
 		},
+		{
+			Name: "error send verification email",
+			Body: dto.UserSignup{
+				Email:    "test@gmail.com",
+				Password: "1234",
+			},
+			ExpectedErr:    nil,
+			ExpectedResult: uuid.New(),
+			mockReturn: map[string]interface{}{
+				"hashStr":    uuid.New().String(),
+				"hashErr":    nil,
+				"createUser": nil,
+				"sendMail":   errors.New("err"),
+			},
+		},
 	}
 	for _, v := range testCase {
 		s.T().Run(v.Name, func(t *testing.T) {
-			hash := s.passwordMock.On("HashPassword").Return(v.mockReturn["hashStr"], v.mockReturn["hashErr"])
-			create := s.userRepositoryMock.On("CreateUser").Return(v.mockReturn["createUser"])
+			s.SetupTest()
+			s.passwordMock.On("HashPassword").Return(v.mockReturn["hashStr"], v.mockReturn["hashErr"])
+			s.userRepositoryMock.On("CreateUser").Return(v.mockReturn["createUser"])
+			s.tokenRepositoryMock.On("CreateUserToken", mock.Anything).Return(nil)
+			s.mailerMock.On("Send", mock.Anything).Return(v.mockReturn["sendMail"])
 			var ctx context.Context
 			res, err := s.userService.CreateUser(v.Body, ctx)
 
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(len(v.ExpectedResult.String()), len(res.String()))
-
-			hash.Unset()
-			create.Unset()
+			if v.mockReturn["createUser"] == nil && v.mockReturn["hashErr"] == nil {
+				token := s.tokenRepositoryMock.Calls[0].Arguments.Get(0).(*model.UserToken)
+				message := s.mailerMock.Calls[0].Arguments.Get(0).(mailer.Message)
+				s.Equal(res, token.UserID)
+				s.Equal(constants.Token_purpose_verify_email, token.Purpose)
+				s.Equal(now.Add(constants.ExpVerifyEmailToken), token.ExpiredAt)
+				s.Equal(v.Body.Email, message.To)
+			} else {
+				s.tokenRepositoryMock.AssertNotCalled(t, "CreateUserToken", mock.Anything)
+			}
 		})
 	}
 }
 
 func (s *suiteUserService) TestLogin() {
+	lockedUntil := now.Add(time.Minute)
 	testCase := []struct {
 		Name             string
 		Body             dto.LoginRequest
 		ExpectedErr      error
-		ExpectedResult   string
+		ExpectedToken    string
 		FindByEmailRes   *model.User
 		FindByEmailErr   error
 		CheckPassRes     bool
+		CreateSessionErr error
 		GenerateTokenRes string
 		GenerateTokenErr error
+		FindAttemptRes   *model.LoginAttempt
+		FindAttemptErr   error
+		RecordFailureRes *model.LoginAttempt
+		RecordFailureErr error
 	}{
 		{
 			Name: "success",
@@ -125,7 +181,7 @@ func (s *suiteUserService) TestLogin() {
 				Password: "1234",
 			},
 			ExpectedErr:      nil,
-			ExpectedResult:   "test",
+			ExpectedToken:    "test",
 			FindByEmailRes:   &model.User{},
 			FindByEmailErr:   nil,
 			CheckPassRes:     true,
@@ -139,21 +195,65 @@ func (s *suiteUserService) TestLogin() {
 				Password: "1234",
 			},
 			ExpectedErr:      errors.New("err"),
-			ExpectedResult:   "",
 			FindByEmailRes:   &model.User{},
 			FindByEmailErr:   errors.New("err"),
 			CheckPassRes:     false,
 			GenerateTokenRes: "",
 			GenerateTokenErr: nil,
 		},
+		{
+			Name: "email not registered",
+			Body: dto.LoginRequest{
+				Email:    "test@gmail.com",
+				Password: "1234",
+			},
+			ExpectedErr:      customerrors.ErrInvalidCredentials,
+			FindByEmailRes:   &model.User{},
+			FindByEmailErr:   customerrors.ErrNotFound,
+			CheckPassRes:     false,
+			RecordFailureRes: &model.LoginAttempt{Failures: 1},
+		},
+		{
+			Name: "account locked",
+			Body: dto.LoginRequest{
+				Email:    "test@gmail.com",
+				Password: "1234",
+			},
+			ExpectedErr:    customerrors.ErrLoginLocked,
+			FindAttemptRes: &model.LoginAttempt{Failures: constants.Login_max_account_failure, LockedUntil: &lockedUntil},
+			FindByEmailRes: &model.User{},
+			CheckPassRes:   true,
+		},
+		{
+			Name: "error find login attempt",
+			Body: dto.LoginRequest{
+				Email:    "test@gmail.com",
+				Password: "1234",
+			},
+			ExpectedErr:    errors.New("err"),
+			FindAttemptErr: errors.New("err"),
+			FindByEmailRes: &model.User{},
+			CheckPassRes:   true,
+		},
+		{
+			Name: "error record failure",
+			Body: dto.LoginRequest{
+				Email:    "test@gmail.com",
+				Password: "1234",
+			},
+			ExpectedErr:      errors.New("err"),
+			FindByEmailRes:   &model.User{},
+			CheckPassRes:     false,
+			RecordFailureErr: errors.New("err"),
+		},
 		{
 			Name: "Check password hash",
 			Body: dto.LoginRequest{
 				Email:    "test@gmail.com",
 				Password: "1234",
 			},
-			ExpectedErr:      customerrors.ErrInvalidPassword,
-			ExpectedResult:   "",
+			ExpectedErr:      customerrors.ErrInvalidCredentials,
+			RecordFailureRes: &model.LoginAttempt{Failures: 1},
 			FindByEmailRes:   &model.User{},
 			FindByEmailErr:   nil,
 			CheckPassRes:     false,
@@ -167,30 +267,148 @@ func (s *suiteUserService) TestLogin() {
 				Password: "1234",
 			},
 			ExpectedErr:      errors.New("err"),
-			ExpectedResult:   "",
 			FindByEmailRes:   &model.User{},
 			FindByEmailErr:   nil,
 			CheckPassRes:     true,
 			GenerateTokenRes: "",
 			GenerateTokenErr: errors.New("err"),
 		},
+		{
+			Name: "user disabled",
+			Body: dto.LoginRequest{
+				Email:    "test@gmail.com",
+				Password: "1234",
+			},
+			ExpectedErr:      customerrors.ErrUserDisabled,
+			FindByEmailRes:   &model.User{DisabledAt: &now},
+			FindByEmailErr:   nil,
+			CheckPassRes:     true,
+			GenerateTokenRes: "test",
+		},
+		{
+			Name: "error create session",
+			Body: dto.LoginRequest{
+				Email:    "test@gmail.com",
+				Password: "1234",
+			},
+			ExpectedErr:      errors.New("err"),
+			FindByEmailRes:   &model.User{},
+			FindByEmailErr:   nil,
+			CheckPassRes:     true,
+			CreateSessionErr: errors.New("err"),
+		},
 	}
 	for _, v := range testCase {
 		s.T().Run(v.Name, func(t *testing.T) {
 			fbe := s.userRepositoryMock.On("FindUserByEmail").Return(v.FindByEmailRes, v.FindByEmailErr)
 			cpw := s.passwordMock.On("CheckPasswordHash").Return(v.CheckPassRes)
+			cs := s.sessionRepositoryMock.On("CreateSession", mock.Anything, mock.Anything).Return(v.CreateSessionErr)
 			gtk := s.JWTServiceMock.On("GenerateToken").Return(v.GenerateTokenRes, v.GenerateTokenErr)
+			findAttemptErr := v.FindAttemptErr
+			if v.FindAttemptRes == nil && findAttemptErr == nil {
+				findAttemptErr = customerrors.ErrNotFound
+			}
+			fla := s.loginAttemptMock.On("FindLoginAttempt", mock.Anything).Return(v.FindAttemptRes, findAttemptErr)
+			rlf := s.loginAttemptMock.On("RecordLoginFailure", mock.Anything).Return(v.RecordFailureRes, v.RecordFailureErr)
+			dla := s.loginAttemptMock.On("DeleteLoginAttempt", "email:test@gmail.com").Return(nil)
 			var ctx context.Context
-			res, err := s.userService.Login(v.Body, ctx)
+			res, err := s.userService.Login(v.Body, "127.0.0.1", ctx)
 			s.Equal(v.ExpectedErr, err)
-			s.Equal(v.ExpectedResult, res)
+			if v.ExpectedErr == nil {
+				s.Equal(v.ExpectedToken, res.Token)
+				s.NotEmpty(res.RefreshToken)
+				session := cs.Parent.Calls[len(cs.Parent.Calls)-1].Arguments.Get(0).(*model.Session)
+				token := cs.Parent.Calls[len(cs.Parent.Calls)-1].Arguments.Get(1).(*model.RefreshToken)
+				s.Equal(now.Add(constants.ExpRefreshToken), session.ExpiredAt)
+				s.Equal(hashToken(res.RefreshToken), token.Hash)
+			} else {
+				s.Nil(res)
+			}
 
 			fbe.Unset()
 			cpw.Unset()
+			cs.Unset()
 			gtk.Unset()
+			fla.Unset()
+			rlf.Unset()
+			dla.Unset()
 		})
 	}
 }
+
+// movingClock is moved by test to pass lock duration
+type movingClock struct {
+	now time.Time
+}
+
+func (c *movingClock) Now() time.Time {
+	return c.now
+}
+
+// login use real memory store, so failure counting and backoff is tested through service
+func (s *suiteUserService) TestLoginLockout() {
+	clock := &movingClock{now: now}
+	userService := newUserServiceMock(s.userRepositoryMock, s.sessionRepositoryMock, s.tokenRepositoryMock, repository.NewLoginAttemptMemoryRepository(), s.passwordMock, s.JWTServiceMock, s.mailerMock, clock)
+	user := &model.User{ID: uuid.New(), Email: "test@gmail.com", Password: "hash"}
+	s.userRepositoryMock.On("FindUserByEmail").Return(user, nil)
+	s.sessionRepositoryMock.On("CreateSession", mock.Anything, mock.Anything).Return(nil)
+	s.JWTServiceMock.On("GenerateToken").Return("token", nil)
+	wrong := s.passwordMock.On("CheckPasswordHash").Return(false)
+	login := func(email string, ip string) error {
+		_, err := userService.Login(dto.LoginRequest{Email: email, Password: "123"}, ip, context.Background())
+		return err
+	}
+
+	for i := 0; i < constants.Login_max_account_failure; i++ {
+		s.Equal(customerrors.ErrInvalidCredentials, login("test@gmail.com", "10.0.0.1"))
+	}
+	// email in other case is same account
+	s.Equal(customerrors.ErrLoginLocked, login("TEST@gmail.com", "10.0.0.2"))
+
+	// lock is doubled on next failure after lock expired
+	clock.now = clock.now.Add(constants.Login_lock_base)
+	s.Equal(customerrors.ErrInvalidCredentials, login("test@gmail.com", "10.0.0.1"))
+	clock.now = clock.now.Add(constants.Login_lock_base)
+	s.Equal(customerrors.ErrLoginLocked, login("test@gmail.com", "10.0.0.1"))
+	clock.now = clock.now.Add(constants.Login_lock_base)
+
+	// correct password clear account failure
+	wrong.Unset()
+	correct := s.passwordMock.On("CheckPasswordHash").Return(true)
+	s.NoError(login("test@gmail.com", "10.0.0.1"))
+	correct.Unset()
+	s.passwordMock.On("CheckPasswordHash").Return(false)
+	s.Equal(customerrors.ErrInvalidCredentials, login("test@gmail.com", "10.0.0.3"))
+	s.Equal(customerrors.ErrInvalidCredentials, login("test@gmail.com", "10.0.0.3"))
+
+	// one ip guessing many account is locked by ip limit
+	s.userRepositoryMock.ExpectedCalls = nil
+	s.userRepositoryMock.On("FindUserByEmail").Return((*model.User)(nil), customerrors.ErrNotFound)
+	for i := 0; i < constants.Login_max_ip_failure; i++ {
+		s.Equal(customerrors.ErrInvalidCredentials, login(uuid.NewString()+"@gmail.com", "10.0.0.4"))
+	}
+	s.Equal(customerrors.ErrLoginLocked, login(uuid.NewString()+"@gmail.com", "10.0.0.4"))
+	s.Equal(customerrors.ErrInvalidCredentials, login(uuid.NewString()+"@gmail.com", "10.0.0.5"))
+}
+
+func (s *suiteUserService) TestLoginLockDuration() {
+	s.Equal(constants.Login_lock_base, loginLockDuration(0))
+	s.Equal(4*constants.Login_lock_base, loginLockDuration(2))
+	s.Equal(constants.Login_lock_max, loginLockDuration(100))
+}
+
+func (s *suiteUserService) TestUnlockUser() {
+	user := &model.User{ID: uuid.New(), Email: "Test@gmail.com"}
+
+	s.userRepositoryMock.On("FindUserByID").Return(user, nil).Once()
+	s.loginAttemptMock.On("DeleteLoginAttempt", "email:test@gmail.com").Return(nil).Once()
+	s.NoError(s.userService.UnlockUser(user.ID.String(), context.Background()))
+
+	s.userRepositoryMock.On("FindUserByID").Return((*model.User)(nil), customerrors.ErrNotFound).Once()
+	s.Equal(customerrors.ErrNotFound, s.userService.UnlockUser(user.ID.String(), context.Background()))
+	s.loginAttemptMock.AssertExpectations(s.T())
+}
+
 func (s *suiteUserService) TestFindUser() {
 	// uuid statis
 	varUUID := uuid.New()
@@ -266,11 +484,14 @@ func (s *suiteUserService) TestFindAllUsers() {
 	}
 	for _, v := range testCase {
 		s.T().Run(v.Name, func(t *testing.T) {
-			fau := s.userRepositoryMock.On("FindAllUsers").Return(v.FindAllUsersRes, v.FindAllUsersErr)
+			fau := s.userRepositoryMock.On("FindAllUsers").Return(v.FindAllUsersRes, int64(len(v.FindAllUsersRes)), v.FindAllUsersErr)
 			var ctx context.Context
-			res, err := s.userService.FindAllUsers(ctx)
+			res, page, err := s.userService.FindAllUsers(query.Options{Page: 1, Limit: 20}, ctx)
 			s.Equal(v.ExpectedErr, err)
 			s.Equal(len(v.ExpectedResult), len(res))
+			if v.ExpectedErr == nil {
+				s.Equal(&query.Page{Page: 1, Limit: 20, Total: 1, TotalPages: 1}, page)
+			}
 
 			fau.Unset()
 		})
@@ -330,6 +551,7 @@ func (s *suiteUserService) TestDeleteUser() {
 		Id            string
 		ExpectedErr   error
 		DeleteUserErr error
+		RevokeErr     error
 	}{
 		{
 			Name:          "success",
@@ -349,15 +571,899 @@ func (s *suiteUserService) TestDeleteUser() {
 			ExpectedErr:   errors.New("err"),
 			DeleteUserErr: errors.New("err"),
 		},
+		{
+			Name:        "error revoke sessions",
+			Id:          uuid.New().String(),
+			ExpectedErr: errors.New("err"),
+			RevokeErr:   errors.New("err"),
+		},
 	}
 	for _, v := range testCase {
 		s.T().Run(v.Name, func(t *testing.T) {
 			upd := s.userRepositoryMock.On("DeleteUser").Return(v.DeleteUserErr)
+			rus := s.sessionRepositoryMock.On("RevokeUserSessions", mock.Anything).Return(v.RevokeErr)
 			var ctx context.Context
 			err := s.userService.DeleteUser(v.Id, ctx)
 			s.Equal(v.ExpectedErr, err)
+			if v.DeleteUserErr == nil && v.ExpectedErr != customerrors.ErrInvalidId {
+				s.sessionRepositoryMock.AssertCalled(t, "RevokeUserSessions", uuid.MustParse(v.Id))
+			}
 
 			upd.Unset()
+			rus.Unset()
+		})
+	}
+}
+
+func (s *suiteUserService) TestRefreshToken() {
+	sessionId := uuid.New()
+	userId := uuid.New()
+	activeSession := model.Session{ID: sessionId, UserID: userId, ExpiredAt: now.Add(time.Hour)}
+	revokedAt := now.Add(-time.Minute)
+	usedAt := now.Add(-time.Minute)
+	testCase := []struct {
+		Name             string
+		FindTokenRes     *model.RefreshToken
+		FindTokenErr     error
+		FindUserErr      error
+		UserDisabledAt   *time.Time
+		RotateErr        error
+		GenerateTokenErr error
+		ExpectedErr      error
+		ExpectedRevoke   bool
+	}{
+		{
+			Name:         "success",
+			FindTokenRes: &model.RefreshToken{SessionID: sessionId, Session: activeSession},
+		},
+		{
+			Name:         "token not found",
+			FindTokenErr: customerrors.ErrNotFound,
+			ExpectedErr:  customerrors.ErrInvalidToken,
+		},
+		{
+			Name:         "error find token",
+			FindTokenErr: errors.New("err"),
+			ExpectedErr:  errors.New("err"),
+		},
+		{
+			Name:         "session revoked",
+			FindTokenRes: &model.RefreshToken{SessionID: sessionId, Session: model.Session{ID: sessionId, ExpiredAt: now.Add(time.Hour), RevokedAt: &revokedAt}},
+			ExpectedErr:  customerrors.ErrInvalidToken,
+		},
+		{
+			Name:         "session expired",
+			FindTokenRes: &model.RefreshToken{SessionID: sessionId, Session: model.Session{ID: sessionId, ExpiredAt: now}},
+			ExpectedErr:  customerrors.ErrInvalidToken,
+		},
+		{
+			Name:           "token reused",
+			FindTokenRes:   &model.RefreshToken{SessionID: sessionId, Session: activeSession, UsedAt: &usedAt},
+			ExpectedErr:    customerrors.ErrInvalidToken,
+			ExpectedRevoke: true,
+		},
+		{
+			Name:           "user deleted",
+			FindTokenRes:   &model.RefreshToken{SessionID: sessionId, Session: activeSession},
+			FindUserErr:    customerrors.ErrNotFound,
+			ExpectedErr:    customerrors.ErrInvalidToken,
+			ExpectedRevoke: true,
+		},
+		{
+			Name:           "user disabled",
+			FindTokenRes:   &model.RefreshToken{SessionID: sessionId, Session: activeSession},
+			UserDisabledAt: &usedAt,
+			ExpectedErr:    customerrors.ErrInvalidToken,
+			ExpectedRevoke: true,
+		},
+		{
+			Name:           "token rotated concurrently",
+			FindTokenRes:   &model.RefreshToken{SessionID: sessionId, Session: activeSession},
+			RotateErr:      customerrors.ErrTokenUsed,
+			ExpectedErr:    customerrors.ErrInvalidToken,
+			ExpectedRevoke: true,
+		},
+		{
+			Name:         "error rotate",
+			FindTokenRes: &model.RefreshToken{SessionID: sessionId, Session: activeSession},
+			RotateErr:    errors.New("err"),
+			ExpectedErr:  errors.New("err"),
+		},
+		{
+			Name:             "error generate token",
+			FindTokenRes:     &model.RefreshToken{SessionID: sessionId, Session: activeSession},
+			GenerateTokenErr: errors.New("err"),
+			ExpectedErr:      errors.New("err"),
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupTest()
+			s.sessionRepositoryMock.On("FindRefreshToken", hashToken("refresh")).Return(v.FindTokenRes, v.FindTokenErr)
+			s.sessionRepositoryMock.On("RevokeSession", sessionId).Return(nil)
+			s.sessionRepositoryMock.On("RotateRefreshToken", mock.Anything, mock.Anything).Return(v.RotateErr)
+			s.userRepositoryMock.On("FindUserByID").Return(&model.User{ID: userId, DisabledAt: v.UserDisabledAt}, v.FindUserErr)
+			s.JWTServiceMock.On("GenerateToken").Return("token", v.GenerateTokenErr)
+			var ctx context.Context
+			res, err := s.userService.RefreshToken(dto.RefreshTokenRequest{RefreshToken: "refresh"}, ctx)
+			s.Equal(v.ExpectedErr, err)
+			if v.ExpectedErr == nil {
+				s.Equal("token", res.Token)
+				next := s.sessionRepositoryMock.Calls[len(s.sessionRepositoryMock.Calls)-1].Arguments.Get(1).(*model.RefreshToken)
+				s.Equal(hashToken(res.RefreshToken), next.Hash)
+				s.NotEqual("refresh", res.RefreshToken)
+			} else {
+				s.Nil(res)
+			}
+			if v.ExpectedRevoke {
+				s.sessionRepositoryMock.AssertCalled(t, "RevokeSession", sessionId)
+			} else {
+				s.sessionRepositoryMock.AssertNotCalled(t, "RevokeSession", sessionId)
+			}
+		})
+	}
+}
+
+func (s *suiteUserService) TestLogout() {
+	sessionId := uuid.New()
+	revokedAt := now.Add(-time.Minute)
+	testCase := []struct {
+		Name         string
+		FindTokenRes *model.RefreshToken
+		FindTokenErr error
+		RevokeErr    error
+		ExpectedErr  error
+	}{
+		{
+			Name:         "success",
+			FindTokenRes: &model.RefreshToken{SessionID: sessionId, Session: model.Session{ID: sessionId, ExpiredAt: now.Add(time.Hour)}},
+		},
+		{
+			Name:         "token not found",
+			FindTokenErr: customerrors.ErrNotFound,
+			ExpectedErr:  customerrors.ErrInvalidToken,
+		},
+		{
+			Name:         "session already revoked",
+			FindTokenRes: &model.RefreshToken{SessionID: sessionId, Session: model.Session{ID: sessionId, ExpiredAt: now.Add(time.Hour), RevokedAt: &revokedAt}},
+			ExpectedErr:  customerrors.ErrInvalidToken,
+		},
+		{
+			Name:         "error revoke",
+			FindTokenRes: &model.RefreshToken{SessionID: sessionId, Session: model.Session{ID: sessionId, ExpiredAt: now.Add(time.Hour)}},
+			RevokeErr:    errors.New("err"),
+			ExpectedErr:  errors.New("err"),
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupTest()
+			s.sessionRepositoryMock.On("FindRefreshToken", hashToken("refresh")).Return(v.FindTokenRes, v.FindTokenErr)
+			s.sessionRepositoryMock.On("RevokeSession", sessionId).Return(v.RevokeErr)
+			var ctx context.Context
+			err := s.userService.Logout(dto.RefreshTokenRequest{RefreshToken: "refresh"}, ctx)
+			s.Equal(v.ExpectedErr, err)
+		})
+	}
+}
+
+func (s *suiteUserService) TestCheckSession() {
+	sessionId := uuid.New()
+	revokedAt := now.Add(-time.Minute)
+	testCase := []struct {
+		Name        string
+		SessionId   string
+		Session     model.Session
+		FindErr     error
+		ExpectedErr error
+	}{
+		{
+			Name:      "active session",
+			SessionId: sessionId.String(),
+			Session:   model.Session{ExpiredAt: now.Add(time.Hour)},
+		},
+		{
+			Name:        "invalid session id",
+			SessionId:   "123",
+			ExpectedErr: customerrors.ErrInvalidToken,
+		},
+		{
+			Name:        "session not found",
+			SessionId:   sessionId.String(),
+			FindErr:     customerrors.ErrNotFound,
+			ExpectedErr: customerrors.ErrTokenRevoked,
+		},
+		{
+			Name:        "error find session",
+			SessionId:   sessionId.String(),
+			FindErr:     errors.New("err"),
+			ExpectedErr: errors.New("err"),
+		},
+		{
+			Name:        "session revoked",
+			SessionId:   sessionId.String(),
+			Session:     model.Session{ExpiredAt: now.Add(time.Hour), RevokedAt: &revokedAt},
+			ExpectedErr: customerrors.ErrTokenRevoked,
+		},
+		{
+			Name:        "session expired",
+			SessionId:   sessionId.String(),
+			Session:     model.Session{ExpiredAt: now},
+			ExpectedErr: customerrors.ErrTokenRevoked,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupTest()
+			session := v.Session
+			s.sessionRepositoryMock.On("FindSessionById", mock.Anything).Return(v.FindErr).Run(func(args mock.Arguments) {
+				arg := args.Get(0).(*model.Session)
+				arg.ExpiredAt = session.ExpiredAt
+				arg.RevokedAt = session.RevokedAt
+			})
+			var ctx context.Context
+			err := s.userService.CheckSession(v.SessionId, ctx)
+			s.Equal(v.ExpectedErr, err)
+		})
+	}
+}
+
+func (s *suiteUserService) TestVerifyEmail() {
+	usedAt := now.Add(-time.Minute)
+	testCase := []struct {
+		Name         string
+		FindTokenRes *model.UserToken
+		FindTokenErr error
+		VerifyErr    error
+		ExpectedErr  error
+	}{
+		{
+			Name:         "success",
+			FindTokenRes: &model.UserToken{ID: 1, ExpiredAt: now.Add(time.Hour)},
+		},
+		{
+			Name:         "token not found",
+			FindTokenErr: customerrors.ErrNotFound,
+			ExpectedErr:  customerrors.ErrInvalidToken,
+		},
+		{
+			Name:         "error find token",
+			FindTokenErr: errors.New("err"),
+			ExpectedErr:  errors.New("err"),
+		},
+		{
+			Name:         "token expired",
+			FindTokenRes: &model.UserToken{ID: 1, ExpiredAt: now},
+			ExpectedErr:  customerrors.ErrInvalidToken,
+		},
+		{
+			Name:         "token used",
+			FindTokenRes: &model.UserToken{ID: 1, ExpiredAt: now.Add(time.Hour), UsedAt: &usedAt},
+			ExpectedErr:  customerrors.ErrInvalidToken,
+		},
+		{
+			Name:         "token used concurrently",
+			FindTokenRes: &model.UserToken{ID: 1, ExpiredAt: now.Add(time.Hour)},
+			VerifyErr:    customerrors.ErrTokenUsed,
+			ExpectedErr:  customerrors.ErrInvalidToken,
+		},
+		{
+			Name:         "error verify",
+			FindTokenRes: &model.UserToken{ID: 1, ExpiredAt: now.Add(time.Hour)},
+			VerifyErr:    errors.New("err"),
+			ExpectedErr:  errors.New("err"),
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupTest()
+			s.tokenRepositoryMock.On("FindUserToken", hashToken("token"), constants.Token_purpose_verify_email).Return(v.FindTokenRes, v.FindTokenErr)
+			s.tokenRepositoryMock.On("VerifyEmail", v.FindTokenRes).Return(v.VerifyErr)
+			var ctx context.Context
+			err := s.userService.VerifyEmail(dto.VerifyEmailRequest{Token: "token"}, ctx)
+			s.Equal(v.ExpectedErr, err)
+		})
+	}
+}
+
+func (s *suiteUserService) TestResendVerification() {
+	verifiedAt := now.Add(-time.Hour)
+	testCase := []struct {
+		Name           string
+		FindUserRes    *model.User
+		FindUserErr    error
+		CreateTokenErr error
+		SendErr        error
+		ExpectedErr    error
+		ExpectedSend   bool
+	}{
+		{
+			Name:         "success",
+			FindUserRes:  &model.User{ID: uuid.New(), Email: "test@gmail.com"},
+			ExpectedSend: true,
+		},
+		{
+			Name:        "email not registered",
+			FindUserErr: customerrors.ErrNotFound,
+		},
+		{
+			Name:        "error find user",
+			FindUserErr: errors.New("err"),
+			ExpectedErr: errors.New("err"),
+		},
+		{
+			Name:        "already verified",
+			FindUserRes: &model.User{ID: uuid.New(), Email: "test@gmail.com", VerifiedAt: &verifiedAt},
+		},
+		{
+			Name:           "error create token",
+			FindUserRes:    &model.User{ID: uuid.New(), Email: "test@gmail.com"},
+			CreateTokenErr: errors.New("err"),
+		},
+		{
+			Name:         "error send email",
+			FindUserRes:  &model.User{ID: uuid.New(), Email: "test@gmail.com"},
+			SendErr:      errors.New("err"),
+			ExpectedSend: true,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupTest()
+			s.userRepositoryMock.On("FindUserByEmail").Return(v.FindUserRes, v.FindUserErr)
+			s.tokenRepositoryMock.On("CreateUserToken", mock.Anything).Return(v.CreateTokenErr)
+			s.mailerMock.On("Send", mock.Anything).Return(v.SendErr)
+			var ctx context.Context
+			err := s.userService.ResendVerification(dto.EmailRequest{Email: "test@gmail.com"}, ctx)
+			s.Equal(v.ExpectedErr, err)
+			if v.ExpectedSend {
+				token := s.tokenRepositoryMock.Calls[0].Arguments.Get(0).(*model.UserToken)
+				s.Equal(v.FindUserRes.ID, token.UserID)
+				s.Equal(constants.Token_purpose_verify_email, token.Purpose)
+				s.mailerMock.AssertNumberOfCalls(t, "Send", 1)
+			} else {
+				s.mailerMock.AssertNotCalled(t, "Send", mock.Anything)
+			}
+		})
+	}
+}
+
+func (s *suiteUserService) TestForgotPassword() {
+	testCase := []struct {
+		Name         string
+		FindUserRes  *model.User
+		FindUserErr  error
+		SendErr      error
+		ExpectedErr  error
+		ExpectedSend bool
+	}{
+		{
+			Name:         "success",
+			FindUserRes:  &model.User{ID: uuid.New(), Email: "test@gmail.com"},
+			ExpectedSend: true,
+		},
+		{
+			Name:        "email not registered",
+			FindUserErr: customerrors.ErrNotFound,
+		},
+		{
+			Name:        "error find user",
+			FindUserErr: errors.New("err"),
+			ExpectedErr: errors.New("err"),
+		},
+		{
+			Name:         "error send email",
+			FindUserRes:  &model.User{ID: uuid.New(), Email: "test@gmail.com"},
+			SendErr:      errors.New("err"),
+			ExpectedSend: true,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupTest()
+			s.userRepositoryMock.On("FindUserByEmail").Return(v.FindUserRes, v.FindUserErr)
+			s.tokenRepositoryMock.On("CreateUserToken", mock.Anything).Return(nil)
+			s.mailerMock.On("Send", mock.Anything).Return(v.SendErr)
+			var ctx context.Context
+			err := s.userService.ForgotPassword(dto.EmailRequest{Email: "test@gmail.com"}, ctx)
+			s.Equal(v.ExpectedErr, err)
+			if v.ExpectedSend {
+				token := s.tokenRepositoryMock.Calls[0].Arguments.Get(0).(*model.UserToken)
+				message := s.mailerMock.Calls[0].Arguments.Get(0).(mailer.Message)
+				s.Equal(v.FindUserRes.ID, token.UserID)
+				s.Equal(constants.Token_purpose_reset_password, token.Purpose)
+				s.Equal(now.Add(constants.ExpResetPasswordToken), token.ExpiredAt)
+				s.Equal("test@gmail.com", message.To)
+			} else {
+				s.mailerMock.AssertNotCalled(t, "Send", mock.Anything)
+			}
+		})
+	}
+}
+
+func (s *suiteUserService) TestResetPassword() {
+	userId := uuid.New()
+	testCase := []struct {
+		Name           string
+		FindTokenRes   *model.UserToken
+		FindTokenErr   error
+		HashErr        error
+		ResetErr       error
+		RevokeErr      error
+		ExpectedErr    error
+		ExpectedRevoke bool
+	}{
+		{
+			Name:           "success",
+			FindTokenRes:   &model.UserToken{ID: 1, UserID: userId, ExpiredAt: now.Add(time.Minute)},
+			ExpectedRevoke: true,
+		},
+		{
+			Name:         "token not found",
+			FindTokenErr: customerrors.ErrNotFound,
+			ExpectedErr:  customerrors.ErrInvalidToken,
+		},
+		{
+			Name:         "token expired",
+			FindTokenRes: &model.UserToken{ID: 1, UserID: userId, ExpiredAt: now.Add(-time.Minute)},
+			ExpectedErr:  customerrors.ErrInvalidToken,
+		},
+		{
+			Name:         "error hash password",
+			FindTokenRes: &model.UserToken{ID: 1, UserID: userId, ExpiredAt: now.Add(time.Minute)},
+			HashErr:      errors.New("err"),
+			ExpectedErr:  errors.New("err"),
+		},
+		{
+			Name:         "token used concurrently",
+			FindTokenRes: &model.UserToken{ID: 1, UserID: userId, ExpiredAt: now.Add(time.Minute)},
+			ResetErr:     customerrors.ErrTokenUsed,
+			ExpectedErr:  customerrors.ErrInvalidToken,
+		},
+		{
+			Name:         "user deleted",
+			FindTokenRes: &model.UserToken{ID: 1, UserID: userId, ExpiredAt: now.Add(time.Minute)},
+			ResetErr:     customerrors.ErrNotFound,
+			ExpectedErr:  customerrors.ErrInvalidToken,
+		},
+		{
+			Name:           "error revoke sessions",
+			FindTokenRes:   &model.UserToken{ID: 1, UserID: userId, ExpiredAt: now.Add(time.Minute)},
+			RevokeErr:      errors.New("err"),
+			ExpectedErr:    errors.New("err"),
+			ExpectedRevoke: true,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupTest()
+			s.tokenRepositoryMock.On("FindUserToken", hashToken("token"), constants.Token_purpose_reset_password).Return(v.FindTokenRes, v.FindTokenErr)
+			s.passwordMock.On("HashPassword").Return("hash", v.HashErr)
+			s.tokenRepositoryMock.On("ResetPassword", v.FindTokenRes, "hash").Return(v.ResetErr)
+			s.sessionRepositoryMock.On("RevokeUserSessions", userId).Return(v.RevokeErr)
+			var ctx context.Context
+			err := s.userService.ResetPassword(dto.ResetPasswordRequest{Token: "token", Password: "new"}, ctx)
+			s.Equal(v.ExpectedErr, err)
+			if v.ExpectedRevoke {
+				s.sessionRepositoryMock.AssertCalled(t, "RevokeUserSessions", userId)
+			} else {
+				s.sessionRepositoryMock.AssertNotCalled(t, "RevokeUserSessions", userId)
+			}
+		})
+	}
+}
+
+func (s *suiteUserService) TestChangePassword() {
+	userId := uuid.New()
+	sessionId := uuid.New()
+	testCase := []struct {
+		Name              string
+		UserId            string
+		SessionId         string
+		Body              dto.ChangePasswordRequest
+		FindUserErr       error
+		CheckPassRes      bool
+		HashErr           error
+		UpdatePasswordErr error
+		RevokeErr         error
+		ExpectedErr       error
+		ExpectedToken     string
+	}{
+		{
+			Name:          "success",
+			UserId:        userId.String(),
+			SessionId:     sessionId.String(),
+			Body:          dto.ChangePasswordRequest{CurrentPassword: "old", NewPassword: "new"},
+			CheckPassRes:  true,
+			ExpectedToken: "token",
+		},
+		{
+			Name:        "invalid user id",
+			UserId:      "123",
+			SessionId:   sessionId.String(),
+			Body:        dto.ChangePasswordRequest{CurrentPassword: "old", NewPassword: "new"},
+			ExpectedErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:        "invalid session id",
+			UserId:      userId.String(),
+			SessionId:   "",
+			Body:        dto.ChangePasswordRequest{CurrentPassword: "old", NewPassword: "new"},
+			ExpectedErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:        "user not found",
+			UserId:      userId.String(),
+			SessionId:   sessionId.String(),
+			Body:        dto.ChangePasswordRequest{CurrentPassword: "old", NewPassword: "new"},
+			FindUserErr: customerrors.ErrNotFound,
+			ExpectedErr: customerrors.ErrNotFound,
+		},
+		{
+			Name:         "wrong current password",
+			UserId:       userId.String(),
+			SessionId:    sessionId.String(),
+			Body:         dto.ChangePasswordRequest{CurrentPassword: "wrong", NewPassword: "new"},
+			CheckPassRes: false,
+			ExpectedErr:  customerrors.ErrInvalidPassword,
+		},
+		{
+			Name:         "same password",
+			UserId:       userId.String(),
+			SessionId:    sessionId.String(),
+			Body:         dto.ChangePasswordRequest{CurrentPassword: "old", NewPassword: "old"},
+			CheckPassRes: true,
+			ExpectedErr:  customerrors.ErrSamePassword,
+		},
+		{
+			Name:         "error hash",
+			UserId:       userId.String(),
+			SessionId:    sessionId.String(),
+			Body:         dto.ChangePasswordRequest{CurrentPassword: "old", NewPassword: "new"},
+			CheckPassRes: true,
+			HashErr:      errors.New("err"),
+			ExpectedErr:  errors.New("err"),
+		},
+		{
+			Name:              "error update password",
+			UserId:            userId.String(),
+			SessionId:         sessionId.String(),
+			Body:              dto.ChangePasswordRequest{CurrentPassword: "old", NewPassword: "new"},
+			CheckPassRes:      true,
+			UpdatePasswordErr: errors.New("err"),
+			ExpectedErr:       errors.New("err"),
+		},
+		{
+			Name:         "error revoke other sessions",
+			UserId:       userId.String(),
+			SessionId:    sessionId.String(),
+			Body:         dto.ChangePasswordRequest{CurrentPassword: "old", NewPassword: "new"},
+			CheckPassRes: true,
+			RevokeErr:    errors.New("err"),
+			ExpectedErr:  errors.New("err"),
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupTest()
+			s.userRepositoryMock.On("FindUserByID").Return(&model.User{ID: userId, Password: "hash-old", MustChangePassword: true}, v.FindUserErr)
+			s.passwordMock.On("CheckPasswordHash").Return(v.CheckPassRes)
+			s.passwordMock.On("HashPassword").Return("hash-new", v.HashErr)
+			s.userRepositoryMock.On("UpdatePassword", userId, "hash-new").Return(v.UpdatePasswordErr)
+			s.sessionRepositoryMock.On("RevokeOtherSessions", userId, sessionId).Return(v.RevokeErr)
+			s.JWTServiceMock.On("GenerateToken").Return("token", nil)
+			var ctx context.Context
+			token, err := s.userService.ChangePassword(v.UserId, v.SessionId, v.Body, ctx)
+			s.Equal(v.ExpectedErr, err)
+			s.Equal(v.ExpectedToken, token)
+			if v.ExpectedErr == nil {
+				s.sessionRepositoryMock.AssertCalled(t, "RevokeOtherSessions", userId, sessionId)
+			}
+		})
+	}
+}
+
+func (s *suiteUserService) TestFindUserDetail() {
+	userId := uuid.New()
+	testCase := []struct {
+		Name            string
+		Id              string
+		FindUserByIdRes *model.User
+		FindUserByIdErr error
+		ExpectedErr     error
+		ExpectedResult  *dto.UserDetailResponse
+	}{
+		{
+			Name:            "success",
+			Id:              userId.String(),
+			FindUserByIdRes: &model.User{ID: userId, RoleID: constants.Role_user, DisabledAt: &now},
+			ExpectedResult: &dto.UserDetailResponse{
+				UserResponse: dto.UserResponse{ID: userId},
+				RoleID:       constants.Role_user,
+				DisabledAt:   &now,
+			},
+		},
+		{
+			Name:        "invalid id",
+			Id:          "123",
+			ExpectedErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:            "not found",
+			Id:              userId.String(),
+			FindUserByIdErr: customerrors.ErrNotFound,
+			ExpectedErr:     customerrors.ErrNotFound,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupTest()
+			s.userRepositoryMock.On("FindUserByID").Return(v.FindUserByIdRes, v.FindUserByIdErr)
+			var ctx context.Context
+			res, err := s.userService.FindUserDetail(v.Id, ctx)
+			s.Equal(v.ExpectedErr, err)
+			s.Equal(v.ExpectedResult, res)
+		})
+	}
+}
+
+func (s *suiteUserService) TestChangeRole() {
+	actorId := uuid.New()
+	userId := uuid.New()
+	testCase := []struct {
+		Name           string
+		Id             string
+		RoleId         uint
+		FindUserRes    *model.User
+		FindUserErr    error
+		UpdateRoleErr  error
+		ExpectedErr    error
+		ExpectedRevoke bool
+	}{
+		{
+			Name:           "success",
+			Id:             userId.String(),
+			RoleId:         constants.Role_checkpoint_operator,
+			FindUserRes:    &model.User{ID: userId, Email: "test@gmail.com"},
+			ExpectedRevoke: true,
+		},
+		{
+			Name:        "invalid id",
+			Id:          "123",
+			RoleId:      constants.Role_admin,
+			ExpectedErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:        "own account",
+			Id:          actorId.String(),
+			RoleId:      constants.Role_user,
+			ExpectedErr: customerrors.ErrChangeOwnAccount,
+		},
+		{
+			Name:        "user not found",
+			Id:          userId.String(),
+			RoleId:      constants.Role_admin,
+			FindUserRes: &model.User{},
+			FindUserErr: customerrors.ErrNotFound,
+			ExpectedErr: customerrors.ErrNotFound,
+		},
+		{
+			Name:        "default admin",
+			Id:          userId.String(),
+			RoleId:      constants.Role_user,
+			FindUserRes: &model.User{ID: userId, Email: constants.Default_email_admin},
+			ExpectedErr: customerrors.ErrDefaultAdmin,
+		},
+		{
+			Name:        "unknown role",
+			Id:          userId.String(),
+			RoleId:      100,
+			FindUserRes: &model.User{ID: userId, Email: "test@gmail.com"},
+			ExpectedErr: customerrors.ErrInvalidRole,
+		},
+		{
+			Name:          "error update role",
+			Id:            userId.String(),
+			RoleId:        constants.Role_admin,
+			FindUserRes:   &model.User{ID: userId, Email: "test@gmail.com"},
+			UpdateRoleErr: errors.New("err"),
+			ExpectedErr:   errors.New("err"),
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupTest()
+			s.userRepositoryMock.On("FindUserByID").Return(v.FindUserRes, v.FindUserErr)
+			s.userRepositoryMock.On("UpdateRole", userId, v.RoleId).Return(v.UpdateRoleErr)
+			s.sessionRepositoryMock.On("RevokeUserSessions", userId).Return(nil)
+			var ctx context.Context
+			err := s.userService.ChangeRole(actorId.String(), v.Id, dto.ChangeRoleRequest{RoleID: v.RoleId}, ctx)
+			s.Equal(v.ExpectedErr, err)
+			if v.ExpectedRevoke {
+				s.sessionRepositoryMock.AssertCalled(t, "RevokeUserSessions", userId)
+			} else {
+				s.sessionRepositoryMock.AssertNotCalled(t, "RevokeUserSessions", userId)
+			}
+		})
+	}
+}
+
+func (s *suiteUserService) TestDisableUser() {
+	actorId := uuid.New()
+	userId := uuid.New()
+	testCase := []struct {
+		Name           string
+		Id             string
+		FindUserRes    *model.User
+		FindUserErr    error
+		UpdateErr      error
+		ExpectedErr    error
+		ExpectedUpdate bool
+	}{
+		{
+			Name:           "success",
+			Id:             userId.String(),
+			FindUserRes:    &model.User{ID: userId, Email: "test@gmail.com"},
+			ExpectedUpdate: true,
+		},
+		{
+			Name:        "already disabled",
+			Id:          userId.String(),
+			FindUserRes: &model.User{ID: userId, Email: "test@gmail.com", DisabledAt: &now},
+		},
+		{
+			Name:        "own account",
+			Id:          actorId.String(),
+			ExpectedErr: customerrors.ErrChangeOwnAccount,
+		},
+		{
+			Name:        "default admin",
+			Id:          userId.String(),
+			FindUserRes: &model.User{ID: userId, Email: constants.Default_email_admin},
+			ExpectedErr: customerrors.ErrDefaultAdmin,
+		},
+		{
+			Name:           "error update",
+			Id:             userId.String(),
+			FindUserRes:    &model.User{ID: userId, Email: "test@gmail.com"},
+			UpdateErr:      errors.New("err"),
+			ExpectedErr:    errors.New("err"),
+			ExpectedUpdate: true,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupTest()
+			s.userRepositoryMock.On("FindUserByID").Return(v.FindUserRes, v.FindUserErr)
+			s.userRepositoryMock.On("UpdateDisabled", userId, &now).Return(v.UpdateErr)
+			s.sessionRepositoryMock.On("RevokeUserSessions", userId).Return(nil)
+			var ctx context.Context
+			err := s.userService.DisableUser(actorId.String(), v.Id, ctx)
+			s.Equal(v.ExpectedErr, err)
+			if v.ExpectedUpdate {
+				s.userRepositoryMock.AssertCalled(t, "UpdateDisabled", userId, &now)
+			} else {
+				s.userRepositoryMock.AssertNotCalled(t, "UpdateDisabled", userId, &now)
+			}
+			if v.ExpectedUpdate && v.UpdateErr == nil {
+				s.sessionRepositoryMock.AssertCalled(t, "RevokeUserSessions", userId)
+			} else {
+				s.sessionRepositoryMock.AssertNotCalled(t, "RevokeUserSessions", userId)
+			}
+		})
+	}
+}
+
+func (s *suiteUserService) TestEnableUser() {
+	actorId := uuid.New()
+	userId := uuid.New()
+	var enabled *time.Time
+	testCase := []struct {
+		Name           string
+		FindUserRes    *model.User
+		UpdateErr      error
+		ExpectedErr    error
+		ExpectedUpdate bool
+	}{
+		{
+			Name:           "success",
+			FindUserRes:    &model.User{ID: userId, Email: "test@gmail.com", DisabledAt: &now},
+			ExpectedUpdate: true,
+		},
+		{
+			Name:        "already enabled",
+			FindUserRes: &model.User{ID: userId, Email: "test@gmail.com"},
+		},
+		{
+			Name:           "error update",
+			FindUserRes:    &model.User{ID: userId, Email: "test@gmail.com", DisabledAt: &now},
+			UpdateErr:      errors.New("err"),
+			ExpectedErr:    errors.New("err"),
+			ExpectedUpdate: true,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupTest()
+			s.userRepositoryMock.On("FindUserByID").Return(v.FindUserRes, nil)
+			s.userRepositoryMock.On("UpdateDisabled", userId, enabled).Return(v.UpdateErr)
+			var ctx context.Context
+			err := s.userService.EnableUser(actorId.String(), userId.String(), ctx)
+			s.Equal(v.ExpectedErr, err)
+			if v.ExpectedUpdate {
+				s.userRepositoryMock.AssertCalled(t, "UpdateDisabled", userId, enabled)
+			} else {
+				s.userRepositoryMock.AssertNotCalled(t, "UpdateDisabled", userId, enabled)
+			}
+		})
+	}
+}
+
+func (s *suiteUserService) TestForcePasswordReset() {
+	userId := uuid.New()
+	testCase := []struct {
+		Name         string
+		Id           string
+		FindUserErr  error
+		ForceErr     error
+		RevokeErr    error
+		SendErr      error
+		ExpectedErr  error
+		ExpectedSend bool
+	}{
+		{
+			Name:         "success",
+			Id:           userId.String(),
+			ExpectedSend: true,
+		},
+		{
+			Name:        "invalid id",
+			Id:          "123",
+			ExpectedErr: customerrors.ErrInvalidId,
+		},
+		{
+			Name:        "user not found",
+			Id:          userId.String(),
+			FindUserErr: customerrors.ErrNotFound,
+			ExpectedErr: customerrors.ErrNotFound,
+		},
+		{
+			Name:        "error force password change",
+			Id:          userId.String(),
+			ForceErr:    errors.New("err"),
+			ExpectedErr: errors.New("err"),
+		},
+		{
+			Name:        "error revoke sessions",
+			Id:          userId.String(),
+			RevokeErr:   errors.New("err"),
+			ExpectedErr: errors.New("err"),
+		},
+		{
+			Name:         "error send email",
+			Id:           userId.String(),
+			SendErr:      errors.New("err"),
+			ExpectedSend: true,
+		},
+	}
+	for _, v := range testCase {
+		s.T().Run(v.Name, func(t *testing.T) {
+			s.SetupTest()
+			s.userRepositoryMock.On("FindUserByID").Return(&model.User{ID: userId, Email: "test@gmail.com"}, v.FindUserErr)
+			s.userRepositoryMock.On("ForcePasswordChange", userId).Return(v.ForceErr)
+			s.sessionRepositoryMock.On("RevokeUserSessions", userId).Return(v.RevokeErr)
+			s.tokenRepositoryMock.On("CreateUserToken", mock.Anything).Return(nil)
+			s.mailerMock.On("Send", mock.Anything).Return(v.SendErr)
+			var ctx context.Context
+			err := s.userService.ForcePasswordReset(v.Id, ctx)
+			s.Equal(v.ExpectedErr, err)
+			if v.ExpectedSend {
+				token := s.tokenRepositoryMock.Calls[0].Arguments.Get(0).(*model.UserToken)
+				s.Equal(constants.Token_purpose_reset_password, token.Purpose)
+				s.mailerMock.AssertNumberOfCalls(t, "Send", 1)
+			} else {
+				s.mailerMock.AssertNotCalled(t, "Send", mock.Anything)
+			}
 		})
 	}
 }
@@ -3,20 +3,43 @@ package config
 import (
 	"fmt"
 
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	API_PORT               string
-	DB_ADDRESS             string
-	DB_USERNAME            string
-	DB_PASSWORD            string
-	DB_NAME                string
-	DEFAULT_ADMIN_EMAIL    string
-	DEFAULT_ADMIN_PASSWORD string
-	JWT_SECRET             string
-	ORDER_SECRET           string
-	MIDTRANS_SERVER_KEY    string
+	API_PORT                 string
+	DB_ADDRESS               string
+	DB_USERNAME              string
+	DB_PASSWORD              string
+	DB_NAME                  string
+	DEFAULT_ADMIN_EMAIL      string
+	DEFAULT_ADMIN_PASSWORD   string
+	JWT_SECRET               string
+	ORDER_SECRET             string
+	MIDTRANS_SERVER_KEY      string
+	PAYMENT_PROVIDER         string
+	PAYMENT_ENV              string
+	PAYMENT_FAKE_URL         string
+	REFUND_EXPIRED_PERCENT   int
+	REFUND_CANCELLED_PERCENT int
+	MAIL_DRIVER              string
+	MAIL_FROM                string
+	MAIL_FILE_PATH           string
+	SMTP_HOST                string
+	SMTP_PORT                string
+	SMTP_USERNAME            string
+	SMTP_PASSWORD            string
+	LOGIN_ATTEMPT_STORE      string
+	TRUSTED_PROXIES          string
+	STORAGE_DRIVER           string
+	STORAGE_LOCAL_PATH       string
+	STORAGE_PUBLIC_URL       string
+	S3_ENDPOINT              string
+	S3_REGION                string
+	S3_BUCKET                string
+	S3_ACCESS_KEY            string
+	S3_SECRET_KEY            string
 }
 
 var Cfg *Config
@@ -27,6 +50,12 @@ func InitConfig() {
 	viper.SetConfigName(".env")
 	viper.SetConfigType("env")
 	viper.AddConfigPath(".")
+	viper.SetDefault("REFUND_EXPIRED_PERCENT", constants.Refund_expired_percent)
+	viper.SetDefault("REFUND_CANCELLED_PERCENT", constants.Refund_cancelled_percent)
+	viper.SetDefault("MAIL_DRIVER", constants.Mail_driver_log)
+	viper.SetDefault("LOGIN_ATTEMPT_STORE", constants.Login_attempt_store_database)
+	viper.SetDefault("STORAGE_DRIVER", constants.Storage_driver_local)
+	viper.SetDefault("STORAGE_LOCAL_PATH", constants.Storage_local_path)
 
 	if err := viper.ReadInConfig(); err != nil {
 		fmt.Println(err)
@@ -0,0 +1,13 @@
+package constants
+
+// bulk import and export file format
+const File_format_csv = "csv"
+const File_format_xlsx = "xlsx"
+
+const Content_type_csv = "text/csv"
+const Content_type_xlsx = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// uploaded import file
+const Import_max_size = 5 << 20
+const Import_body_limit = "6M"
+const Import_max_rows = 5000
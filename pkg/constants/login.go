@@ -0,0 +1,20 @@
+package constants
+
+import "time"
+
+// store of failed login attempt, memory only work when api run in single instance
+const Login_attempt_store_memory = "memory"
+const Login_attempt_store_database = "database"
+
+// failed login before account is locked
+const Login_max_account_failure = 5
+
+// failed login before ip is locked, higher than account because many user can share one ip
+const Login_max_ip_failure = 20
+
+// failed login is forgotten when there is no failure and no lock in this duration
+const Login_attempt_window = 15 * time.Minute
+
+// first lock duration, doubled on each failure after limit reached until max
+const Login_lock_base = time.Minute
+const Login_lock_max = time.Hour
@@ -0,0 +1,6 @@
+package constants
+
+// mail driver
+const Mail_driver_smtp = "smtp"
+const Mail_driver_file = "file"
+const Mail_driver_log = "log"
@@ -6,8 +6,8 @@ import (
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 )
 
-// code konfirmation order to pick up order
-const Item_code_length = 6
+// random nonce length in byte of pickup token, nonce is saved as order code so token only valid once
+const Pickup_nonce_length = 16
 
 // set shipping cost
 const Shipping_cost = 5000
@@ -15,6 +15,12 @@ const Shipping_cost = 5000
 // order expired duration
 const ExpOrder = 24 * time.Hour
 
+// time to pick up ready order in checkpoint before refunded
+const ExpPickupOrder = 12 * time.Hour
+
+// interval expiry worker check expired order
+const ExpiryWorkerInterval = 1 * time.Minute
+
 // status id
 const Pending_status_order_id = 1
 const Waiting_status_order_id = 2
@@ -24,6 +30,13 @@ const Refund_status_order_id = 5
 const Refund_success_status_order_id = 6
 const Cencel_status_order_id = 7
 
+// source of order status change saved in status history
+const Order_source_user_api = "user_api"
+const Order_source_admin_api = "admin_api"
+const Order_source_operator_api = "operator_api"
+const Order_source_payment_webhook = "payment_webhook"
+const Order_source_expiry_worker = "expiry_worker"
+
 // default value status order model
 var (
 	StatusOrder = []model.StatusOrder{
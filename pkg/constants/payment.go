@@ -0,0 +1,9 @@
+package constants
+
+// payment provider
+const Payment_provider_midtrans = "midtrans"
+const Payment_provider_fake = "fake"
+
+// payment environment
+const Payment_env_sandbox = "sandbox"
+const Payment_env_production = "production"
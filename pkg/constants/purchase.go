@@ -0,0 +1,6 @@
+package constants
+
+// purchase order status
+const Purchase_status_draft = "draft"       // created, details still can be changed
+const Purchase_status_sent = "sent"         // sent to supplier, waiting delivery
+const Purchase_status_received = "received" // delivered, stock is added
@@ -0,0 +1,7 @@
+package constants
+
+// page size of list endpoint when client dont send limit
+const Default_page_limit = 20
+
+// biggest page client can ask, keep response small even for big table like villages
+const Max_page_limit = 100
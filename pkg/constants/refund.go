@@ -0,0 +1,21 @@
+package constants
+
+import "time"
+
+// reason of refund, used to choose refund percent
+const Refund_reason_expired = "expired"
+const Refund_reason_cancelled = "cancelled"
+const Refund_reason_late_payment = "late_payment" // paid after order is cencelled, always refunded in full
+
+// refund status
+const Refund_status_pending = "pending"     // created, not sent to payment provider yet
+const Refund_status_requested = "requested" // accepted by payment provider, waiting notification
+const Refund_status_failed = "failed"       // payment provider reject, retry by admin
+const Refund_status_success = "success"     // confirmed by payment provider notification
+
+// default percent of order grand total refunded
+const Refund_expired_percent = 50
+const Refund_cancelled_percent = 100
+
+// interval refund worker send pending refund to payment provider
+const RefundWorkerInterval = 1 * time.Minute
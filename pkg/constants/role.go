@@ -5,6 +5,24 @@ import "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 // role
 const Role_admin = 1
 const Role_user = 2
+const Role_checkpoint_operator = 3
+
+// permission
+const Permission_order_create = "order:create"
+const Permission_order_read = "order:read"
+const Permission_order_cancel = "order:cancel"
+const Permission_order_process = "order:process"
+const Permission_order_manage = "order:manage"
+const Permission_item_read = "item:read"
+const Permission_item_manage = "item:manage"
+const Permission_checkpoint_read = "checkpoint:read"
+const Permission_checkpoint_manage = "checkpoint:manage"
+const Permission_user_profile = "user:profile"
+const Permission_user_manage = "user:manage"
+const Permission_region_read = "region:read"
+const Permission_transaction_read = "transaction:read"
+const Permission_refund_manage = "refund:manage"
+const Permission_supplier_manage = "supplier:manage"
 
 var (
 	Role = []model.Role{
@@ -18,5 +36,70 @@ var (
 			Name:        "user",
 			Description: "role for common users",
 		},
+		{
+			ID:          Role_checkpoint_operator,
+			Name:        "checkpoint_operator",
+			Description: "role for staff of assigned checkpoints",
+		},
+	}
+
+	Permission = []model.Permission{
+		{ID: 1, Name: Permission_order_create, Description: "create own order"},
+		{ID: 2, Name: Permission_order_read, Description: "see own order, order history and pickup qrcode"},
+		{ID: 3, Name: Permission_order_cancel, Description: "cencel own unpaid order"},
+		{ID: 4, Name: Permission_order_process, Description: "see checkpoint order, set order ready and hand over order"},
+		{ID: 5, Name: Permission_order_manage, Description: "access order of every user and checkpoint"},
+		{ID: 6, Name: Permission_item_read, Description: "see item and category"},
+		{ID: 7, Name: Permission_item_manage, Description: "create and update item and category"},
+		{ID: 8, Name: Permission_checkpoint_read, Description: "see checkpoint"},
+		{ID: 9, Name: Permission_checkpoint_manage, Description: "create checkpoint and assign operator"},
+		{ID: 10, Name: Permission_user_profile, Description: "see and update own profile"},
+		{ID: 11, Name: Permission_user_manage, Description: "see and delete every user"},
+		{ID: 12, Name: Permission_region_read, Description: "see region"},
+		{ID: 13, Name: Permission_transaction_read, Description: "see own transaction"},
+		{ID: 14, Name: Permission_refund_manage, Description: "see and retry refund"},
+		{ID: 15, Name: Permission_supplier_manage, Description: "manage supplier and purchase order"},
+	}
+
+	// permission set of each role, role not listed here has no permission
+	RolePermission = map[uint][]string{
+		Role_admin: {
+			Permission_order_create,
+			Permission_order_read,
+			Permission_order_cancel,
+			Permission_order_process,
+			Permission_order_manage,
+			Permission_item_read,
+			Permission_item_manage,
+			Permission_checkpoint_read,
+			Permission_checkpoint_manage,
+			Permission_user_profile,
+			Permission_user_manage,
+			Permission_region_read,
+			Permission_transaction_read,
+			Permission_refund_manage,
+			Permission_supplier_manage,
+		},
+		Role_user: {
+			Permission_order_create,
+			Permission_order_read,
+			Permission_order_cancel,
+			Permission_item_read,
+			Permission_checkpoint_read,
+			Permission_user_profile,
+			Permission_region_read,
+			Permission_transaction_read,
+		},
+		Role_checkpoint_operator: {
+			Permission_order_create,
+			Permission_order_read,
+			Permission_order_cancel,
+			Permission_order_process,
+			Permission_item_read,
+			Permission_checkpoint_read,
+			Permission_user_profile,
+			Permission_region_read,
+			Permission_transaction_read,
+		},
 	}
 )
@@ -0,0 +1,23 @@
+package constants
+
+import (
+	"time"
+)
+
+// reason of stock movement
+const Stock_reason_opening = "opening"
+const Stock_reason_purchase = "purchase"
+const Stock_reason_sale = "sale"
+const Stock_reason_cancel_restock = "cancel_restock"
+const Stock_reason_spoilage = "spoilage"
+const Stock_reason_adjustment = "adjustment"
+const Stock_reason_transfer = "transfer"
+
+// note of spoilage move of expired batch written off by batch worker
+const Stock_note_expired_batch = "expired batch"
+
+// interval batch worker write off expired batch
+const BatchWorkerInterval = 10 * time.Minute
+
+// days ahead batch is listed in expiring report when not asked
+const Batch_expiring_days = 2
@@ -0,0 +1,15 @@
+package constants
+
+// storage driver
+const Storage_driver_local = "local"
+const Storage_driver_s3 = "s3"
+
+// local storage
+const Storage_local_path = "uploads"
+const Storage_local_route = "/uploads"
+
+// uploaded image
+const Image_max_size = 2 << 20
+const Image_body_limit = "3M"
+const Image_max_pixels = 40_000_000
+const Thumbnail_size = 256
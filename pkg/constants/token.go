@@ -2,5 +2,24 @@ package constants
 
 import "time"
 
-// token expired duration
-const ExpToken = 12 * time.Hour
+// access token expired duration, keep it short so role change and deleted user take effect soon
+const ExpToken = 15 * time.Minute
+
+// refresh token expired duration, login again after session expired
+const ExpRefreshToken = 7 * 24 * time.Hour
+
+// random refresh token length in byte
+const Refresh_token_length = 32
+
+// purpose of token sent to user email
+const Token_purpose_verify_email = "verify_email"
+const Token_purpose_reset_password = "reset_password"
+
+// email verification token expired duration
+const ExpVerifyEmailToken = 24 * time.Hour
+
+// reset password token expired duration, keep it short because it can take over account
+const ExpResetPasswordToken = 30 * time.Minute
+
+// random email token length in byte
+const User_token_length = 32
@@ -0,0 +1,32 @@
+package constants
+
+import "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+
+// unit id
+const Unit_kg_id = 1
+const Unit_bunch_id = 2
+const Unit_piece_id = 3
+
+// default value unit model
+var (
+	Units = []model.Unit{
+		{
+			ID:     Unit_kg_id,
+			Name:   "kilogram",
+			Symbol: "kg",
+			Step:   0.25,
+		},
+		{
+			ID:     Unit_bunch_id,
+			Name:   "ikat",
+			Symbol: "ikat",
+			Step:   1,
+		},
+		{
+			ID:     Unit_piece_id,
+			Name:   "piece",
+			Symbol: "pcs",
+			Step:   1,
+		},
+	}
+)
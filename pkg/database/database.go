@@ -4,9 +4,11 @@ import (
 	"fmt"
 
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/config"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func ConnectDB() (*gorm.DB, error) {
@@ -20,13 +22,59 @@ func ConnectDB() (*gorm.DB, error) {
 	return gorm.Open(mysql.Open(dsn), &gorm.Config{})
 }
 
+// seedUnits create unit item is sold in, existing unit is kept
+func seedUnits(db *gorm.DB) error {
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(constants.Units).Error
+}
+
 func MigrateDB(db *gorm.DB) error {
-	return db.AutoMigrate(
+	// item saved before unit existed get default unit when unit_id is added,
+	// so unit must exist before foreign key of item is added
+	if db.Migrator().HasTable(&model.Item{}) && !db.Migrator().HasColumn(&model.Item{}, "UnitID") {
+		if err := db.AutoMigrate(model.Unit{}); err != nil {
+			return err
+		}
+		if err := seedUnits(db); err != nil {
+			return err
+		}
+	}
+	err := db.AutoMigrate(
+		model.Permission{},
+		model.Role{},
 		model.User{},
+		model.Session{},
+		model.RefreshToken{},
+		model.UserToken{},
+		model.LoginAttempt{},
 		model.Checkpoint{},
+		model.CheckpointOperator{},
+		model.Unit{},
 		model.Item{},
+		model.ItemVariant{},
+		model.ItemSearchGram{},
+		model.ItemSearchIndex{},
+		model.StockMovement{},
+		model.CheckpointStock{},
+		model.StockBatch{},
+		model.StockBatchMove{},
+		model.Supplier{},
+		model.SupplierCost{},
+		model.PurchaseOrder{},
+		model.PurchaseOrderDetail{},
 		model.Order{},
 		model.OrderDetail{},
+		model.OrderStatusHistory{},
+		model.Cart{},
+		model.CartLine{},
 		model.Transaction{},
+		model.NotificationAudit{},
+		model.Refund{},
 	)
+	if err != nil {
+		return err
+	}
+	if err := seedUnits(db); err != nil {
+		return err
+	}
+	return runMigrations(db, migrations)
 }
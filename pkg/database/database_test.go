@@ -0,0 +1,124 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	password "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/password"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openOldDB open empty sqlite database, test create table of old version before MigrateDB
+func openOldDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "old.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sqlDB, err := db.DB()
+		if err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func TestMigratePermissionTable(t *testing.T) {
+	db := openOldDB(t)
+
+	assert.NoError(t, MigrateDB(db))
+
+	assert.True(t, db.Migrator().HasTable("permissions"))
+	assert.True(t, db.Migrator().HasTable("role_permissions"))
+}
+
+func TestMigrateVerifyExistingUsers(t *testing.T) {
+	db := openOldDB(t)
+	assert.NoError(t, MigrateDB(db))
+
+	// users of old database, only user registered after verification existed has verify token
+	createdAt := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	oldUser := model.User{ID: uuid.New(), CreatedAt: createdAt, Name: "old", Email: "old@mail.com", Password: "-"}
+	newUser := model.User{ID: uuid.New(), Name: "new", Email: "new@mail.com", Password: "-"}
+	assert.NoError(t, db.Create(&[]model.User{oldUser, newUser}).Error)
+	assert.NoError(t, db.Create(&model.UserToken{UserID: newUser.ID, Purpose: constants.Token_purpose_verify_email, Hash: "hash"}).Error)
+	assert.NoError(t, db.Delete(&migrationRecord{ID: "verify_existing_users"}).Error)
+
+	assert.NoError(t, runMigrations(db, migrations))
+
+	var users []model.User
+	assert.NoError(t, db.Order("name").Find(&users).Error)
+	assert.Equal(t, "new", users[0].Name)
+	assert.Nil(t, users[0].VerifiedAt)
+	assert.True(t, createdAt.Equal(*users[1].VerifiedAt))
+
+	// migration already recorded is not run again
+	assert.NoError(t, db.Model(&model.User{}).Where("id = ?", oldUser.ID).Update("verified_at", nil).Error)
+	assert.NoError(t, runMigrations(db, migrations))
+	assert.NoError(t, db.First(&users[1], "id = ?", oldUser.ID).Error)
+	assert.Nil(t, users[1].VerifiedAt)
+}
+
+func TestMigrateForceDefaultAdminPasswordChange(t *testing.T) {
+	db := openOldDB(t)
+	assert.NoError(t, MigrateDB(db))
+
+	// admin seeded before forced change existed, still with default password
+	hash, err := password.Password{}.HashPassword(constants.Default_password_admin)
+	assert.NoError(t, err)
+	admin := model.User{ID: uuid.New(), Name: "admin", Email: constants.Default_email_admin, Password: hash, RoleID: constants.Role_admin}
+	assert.NoError(t, db.Create(&admin).Error)
+	assert.NoError(t, db.Delete(&migrationRecord{ID: "force_default_admin_password_change"}).Error)
+
+	assert.NoError(t, runMigrations(db, migrations))
+
+	assert.NoError(t, db.First(&admin, "id = ?", admin.ID).Error)
+	assert.True(t, admin.MustChangePassword)
+}
+
+func TestMigrateApplyExistingTransactions(t *testing.T) {
+	db := openOldDB(t)
+	assert.NoError(t, MigrateDB(db))
+
+	// transaction of old database, its status was applied with the notification
+	transaction := model.Transaction{ID: uuid.New(), OrderID: uuid.New(), TransactionStatus: "settlement"}
+	assert.NoError(t, db.Create(&transaction).Error)
+	assert.NoError(t, db.Delete(&migrationRecord{ID: "apply_existing_transactions"}).Error)
+
+	assert.NoError(t, runMigrations(db, migrations))
+
+	assert.NoError(t, db.First(&transaction, "id = ?", transaction.ID).Error)
+	assert.True(t, transaction.OrderApplied)
+}
+
+// unit is seeded by MigrateDB itself, item saved right after migration get default unit
+func TestMigrateSeedUnit(t *testing.T) {
+	db := openOldDB(t)
+	assert.NoError(t, db.Exec("PRAGMA foreign_keys = ON").Error)
+
+	assert.NoError(t, MigrateDB(db))
+
+	category := model.Category{Name: "sayur"}
+	assert.NoError(t, db.Create(&category).Error)
+	item := model.Item{Name: "Tomat", CategoryID: category.ID, Qty: 5, Price: 3000}
+	assert.NoError(t, db.Create(&item).Error)
+	assert.NoError(t, db.Preload("Unit").First(&item, item.ID).Error)
+	assert.Equal(t, uint(constants.Unit_piece_id), item.Unit.ID)
+	var units int64
+	assert.NoError(t, db.Model(&model.Unit{}).Count(&units).Error)
+	assert.Equal(t, int64(len(constants.Units)), units)
+
+	// seed is kept when unit already exist
+	assert.NoError(t, seedUnits(db))
+	assert.NoError(t, db.Model(&model.Unit{}).Count(&units).Error)
+	assert.Equal(t, int64(len(constants.Units)), units)
+}
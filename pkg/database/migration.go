@@ -0,0 +1,86 @@
+package database
+
+import (
+	"time"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	password "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/password"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// migration change data of old database after AutoMigrate, like backfill of new column.
+// Each migration run once, its id is saved in migrations table in the same transaction
+type migration struct {
+	ID      string
+	Migrate func(tx *gorm.DB) error
+}
+
+type migrationRecord struct {
+	ID        string `gorm:"primaryKey;type:varchar(100)"`
+	CreatedAt time.Time
+}
+
+func (migrationRecord) TableName() string {
+	return "migrations"
+}
+
+// migrations run in listed order, new migration is appended and id is never changed
+var migrations = []migration{
+	{
+		// user registered before email verification existed can still order
+		ID: "verify_existing_users",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.Exec("UPDATE users SET verified_at = created_at WHERE verified_at IS NULL AND NOT EXISTS "+
+				"(SELECT 1 FROM user_tokens WHERE user_tokens.user_id = users.id AND user_tokens.purpose = ?)",
+				constants.Token_purpose_verify_email).Error
+		},
+	},
+	{
+		// default admin seeded before forced change existed must change default password
+		ID: "force_default_admin_password_change",
+		Migrate: func(tx *gorm.DB) error {
+			var admin model.User
+			err := tx.Where("email = ?", constants.Default_email_admin).Limit(1).Find(&admin).Error
+			if err != nil || admin.Email == "" {
+				return err
+			}
+			if !(password.Password{}).CheckPasswordHash(constants.Default_password_admin, admin.Password) {
+				return nil
+			}
+			return tx.Model(&admin).Update("must_change_password", true).Error
+		},
+	},
+	{
+		// transaction saved before applied status is kept was applied to its order already
+		ID: "apply_existing_transactions",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.Model(&model.Transaction{}).Where("order_applied = ?", false).Update("order_applied", true).Error
+		},
+	},
+}
+
+// runMigrations run migration not recorded yet, replica starting at the same time wait for
+// the record lock so each migration still run once
+func runMigrations(db *gorm.DB, migrations []migration) error {
+	if err := db.AutoMigrate(&migrationRecord{}); err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&migrationRecord{ID: m.ID})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return nil
+			}
+			return m.Migrate(tx)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+// Package testdb open real sqlite database for test that need behaviour sqlmock cant give,
+// like concurrent transaction. Only import it from _test.go file.
+package testdb
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// New create migrated sqlite database in test temp dir, removed when test finish
+func New(t testing.TB) *gorm.DB {
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.MigrateDB(db); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sqlDB, err := db.DB()
+		if err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
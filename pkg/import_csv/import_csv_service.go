@@ -1,13 +1,24 @@
 package importcsv
 
 import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"math"
 	"os"
+	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/gocarina/gocsv"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
 )
 
 type ImportCsv interface {
 	UnmarshalCsv(filepath string, model interface{}) error
+	Unmarshal(data []byte, format string, model interface{}) error
+	Marshal(model interface{}, format string) ([]byte, error)
 }
 
 type importCsvImpl struct{}
@@ -25,6 +36,158 @@ func (*importCsvImpl) UnmarshalCsv(filepath string, model interface{}) error {
 	return nil
 }
 
+// Unmarshal implements ImportCsv. Header is matched case insensitive and must have key
+// column of model, first data row is row 2 of the file
+func (*importCsvImpl) Unmarshal(data []byte, format string, model interface{}) error {
+	var records [][]string
+	var err error
+	switch format {
+	case constants.File_format_csv:
+		records, err = readCsv(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	case constants.File_format_xlsx:
+		records, err = readXlsx(data)
+	default:
+		return customerrors.ErrFileFormat
+	}
+	if err != nil {
+		return customerrors.ErrFileFormat
+	}
+	if len(records) == 0 {
+		return customerrors.ErrImportRows
+	}
+	for _, record := range records {
+		for i, value := range record {
+			record[i] = unescapeFormula(value)
+		}
+	}
+	for i, column := range records[0] {
+		records[0][i] = strings.ToLower(strings.TrimSpace(column))
+	}
+	if !hasColumns(records[0], model) {
+		return customerrors.ErrImportHeader
+	}
+	return gocsv.UnmarshalCSV(&recordReader{records: records}, model)
+}
+
+// Marshal implements ImportCsv. Text value look like formula is escaped, so opening
+// exported file in spreadsheet never run value entered by user
+func (*importCsvImpl) Marshal(model interface{}, format string) ([]byte, error) {
+	data, err := gocsv.MarshalBytes(model)
+	if err != nil {
+		return nil, err
+	}
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		for i, value := range record {
+			record[i] = escapeFormula(value)
+		}
+	}
+	switch format {
+	case constants.File_format_csv:
+		var out bytes.Buffer
+		writer := csv.NewWriter(&out)
+		if err := writer.WriteAll(records); err != nil {
+			return nil, err
+		}
+		return out.Bytes(), nil
+	case constants.File_format_xlsx:
+		return writeXlsx(records)
+	default:
+		return nil, customerrors.ErrFileFormat
+	}
+}
+
+// formulaPrefix is first character spreadsheet read as start of formula, tab and carriage
+// return too because spreadsheet may drop them before reading the rest
+const formulaPrefix = "=+-@\t\r"
+
+// escapeFormula prefix text starting like formula with quote, number like -5 is kept
+func escapeFormula(value string) string {
+	if value == "" || !strings.ContainsRune(formulaPrefix, rune(value[0])) || isNumber(value) {
+		return value
+	}
+	return "'" + value
+}
+
+// unescapeFormula remove quote added by escapeFormula, so exported file can be imported back
+func unescapeFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(formulaPrefix, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+// isNumber check value is finite number, text like "-Inf" is not number in spreadsheet
+func isNumber(value string) bool {
+	n, err := strconv.ParseFloat(value, 64)
+	return err == nil && !math.IsInf(n, 0) && !math.IsNaN(n)
+}
+
+// hasColumns check header has key column, the first tagged field of model slice element.
+// Other column is optional, missing column is read as empty value
+func hasColumns(header []string, model interface{}) bool {
+	columns := map[string]bool{}
+	for _, column := range header {
+		columns[column] = true
+	}
+	elem := reflect.TypeOf(model)
+	for elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Slice {
+		elem = elem.Elem()
+	}
+	for i := 0; i < elem.NumField(); i++ {
+		tag := strings.Split(elem.Field(i).Tag.Get("csv"), ",")[0]
+		if tag != "" && tag != "-" {
+			return columns[tag]
+		}
+	}
+	return false
+}
+
+// readCsv read every record. Blank line is kept as empty record like readXlsx so record
+// index follow line number
+func readCsv(data []byte) ([][]string, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	var records [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		for line > len(records)+1 {
+			records = append(records, nil)
+		}
+		records = append(records, record)
+	}
+}
+
+// recordReader give already read records to gocsv
+type recordReader struct {
+	records [][]string
+}
+
+func (r *recordReader) Read() ([]string, error) {
+	if len(r.records) == 0 {
+		return nil, io.EOF
+	}
+	record := r.records[0]
+	r.records = r.records[1:]
+	return record, nil
+}
+
+func (r *recordReader) ReadAll() ([][]string, error) {
+	records := r.records
+	r.records = nil
+	return records, nil
+}
+
 func NewImportCsv() ImportCsv {
 	return &importCsvImpl{}
 }
@@ -0,0 +1,130 @@
+package importcsv
+
+import (
+	"testing"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+type row struct {
+	Name string `csv:"name"`
+	Qty  string `csv:"qty"`
+}
+
+func TestUnmarshalCsv(t *testing.T) {
+	testCase := []struct {
+		Name        string
+		Data        string
+		Format      string
+		ExpectedErr error
+		Expected    []row
+	}{
+		{
+			Name:     "success with bom and blank line",
+			Data:     "\xef\xbb\xbf Name ,QTY\nbayam,1\n\nwortel,2.5\n",
+			Format:   constants.File_format_csv,
+			Expected: []row{{Name: "bayam", Qty: "1"}, {}, {Name: "wortel", Qty: "2.5"}},
+		},
+		{
+			Name:     "optional column",
+			Data:     "name\nbayam\n",
+			Format:   constants.File_format_csv,
+			Expected: []row{{Name: "bayam"}},
+		},
+		{
+			Name:        "missing key column",
+			Data:        "qty\n1\n",
+			Format:      constants.File_format_csv,
+			ExpectedErr: customerrors.ErrImportHeader,
+		},
+		{
+			Name:        "empty file",
+			Format:      constants.File_format_csv,
+			ExpectedErr: customerrors.ErrImportRows,
+		},
+		{
+			Name:        "invalid xlsx",
+			Data:        "name,qty\n",
+			Format:      constants.File_format_xlsx,
+			ExpectedErr: customerrors.ErrFileFormat,
+		},
+		{
+			Name:        "unknown format",
+			Data:        "name,qty\n",
+			Format:      "pdf",
+			ExpectedErr: customerrors.ErrFileFormat,
+		},
+	}
+	for _, v := range testCase {
+		t.Run(v.Name, func(t *testing.T) {
+			var rows []row
+			err := NewImportCsv().Unmarshal([]byte(v.Data), v.Format, &rows)
+
+			assert.Equal(t, v.ExpectedErr, err)
+			if v.ExpectedErr == nil {
+				assert.Equal(t, v.Expected, rows)
+			}
+		})
+	}
+}
+
+func TestXlsxRoundTrip(t *testing.T) {
+	rows := []row{{Name: "bayam <ikat> & co", Qty: "1.5"}, {Name: "wortel"}}
+	data, err := NewImportCsv().Marshal(&rows, constants.File_format_xlsx)
+	assert.NoError(t, err)
+
+	var res []row
+	err = NewImportCsv().Unmarshal(data, constants.File_format_xlsx, &res)
+
+	assert.NoError(t, err)
+	assert.Equal(t, rows, res)
+}
+
+// spreadsheet app save text as shared string or rich text, and skip empty row and cell
+func TestReadXlsx(t *testing.T) {
+	file := excelize.NewFile()
+	assert.NoError(t, file.SetCellStr("Sheet1", "A1", "name"))
+	assert.NoError(t, file.SetCellStr("Sheet1", "C1", "qty"))
+	assert.NoError(t, file.SetCellRichText("Sheet1", "A3", []excelize.RichTextRun{{Text: "bay"}, {Text: "am"}}))
+	assert.NoError(t, file.SetCellInt("Sheet1", "C3", 2))
+	data, err := file.WriteToBuffer()
+	assert.NoError(t, err)
+
+	records, err := readXlsx(data.Bytes())
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"name", "", "qty"}, nil, {"bayam", "", "2"}}, records)
+}
+
+func TestMarshalEscapeFormula(t *testing.T) {
+	rows := []row{{Name: "=HYPERLINK(\"http://evil\")", Qty: "-5"}, {Name: "@SUM(A1)", Qty: "1.5"}, {Name: "-Inf", Qty: "2"}, {Name: "\t=1+1", Qty: "3"}, {Name: "\r=1+1", Qty: "4"}}
+	for _, format := range []string{constants.File_format_csv, constants.File_format_xlsx} {
+		t.Run(format, func(t *testing.T) {
+			data, err := NewImportCsv().Marshal(&rows, format)
+			assert.NoError(t, err)
+
+			records := [][]string{}
+			if format == constants.File_format_csv {
+				records, err = readCsv(data)
+			} else {
+				records, err = readXlsx(data)
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, [][]string{
+				{"name", "qty"},
+				{"'=HYPERLINK(\"http://evil\")", "-5"},
+				{"'@SUM(A1)", "1.5"},
+				{"'-Inf", "2"},
+				{"'\t=1+1", "3"},
+				{"'\r=1+1", "4"},
+			}, records)
+
+			var res []row
+			assert.NoError(t, NewImportCsv().Unmarshal(data, format, &res))
+			assert.Equal(t, rows, res)
+		})
+	}
+}
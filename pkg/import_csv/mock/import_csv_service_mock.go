@@ -12,3 +12,13 @@ func (b *ImportCsvMock) UnmarshalCsv(filepath string, model interface{}) error {
 	args := b.Called()
 	return args.Error(0)
 }
+
+func (b *ImportCsvMock) Unmarshal(data []byte, format string, model interface{}) error {
+	args := b.Called()
+	return args.Error(0)
+}
+
+func (b *ImportCsvMock) Marshal(model interface{}, format string) ([]byte, error) {
+	args := b.Called()
+	return args.Get(0).([]byte), args.Error(1)
+}
@@ -0,0 +1,70 @@
+package importcsv
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// only the first sheet is read, file expanding bigger than this is rejected so small zip cant expand to huge xml
+const xlsxMaxPartSize = 64 << 20
+
+const xlsxSheetName = "Sheet1"
+
+var errXlsx = errors.New("invalid xlsx file")
+
+// readXlsx read first sheet as records. Empty row between rows is kept so record index
+// follow row number seen in spreadsheet
+func readXlsx(data []byte) ([][]string, error) {
+	file, err := excelize.OpenReader(bytes.NewReader(data), excelize.Options{
+		UnzipSizeLimit:    xlsxMaxPartSize,
+		UnzipXMLSizeLimit: xlsxMaxPartSize,
+	})
+	if err != nil {
+		return nil, errXlsx
+	}
+	defer file.Close()
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, errXlsx
+	}
+	records, err := file.GetRows(sheets[0])
+	if err != nil {
+		return nil, errXlsx
+	}
+	for i, record := range records {
+		if len(record) == 0 {
+			records[i] = nil
+		}
+	}
+	return records, nil
+}
+
+// writeXlsx write records as single sheet workbook. Number is written as number cell
+// except in header row, other value as string
+func writeXlsx(records [][]string) ([]byte, error) {
+	file := excelize.NewFile()
+	defer file.Close()
+	for i, record := range records {
+		for j, value := range record {
+			cell, err := excelize.CoordinatesToCellName(j+1, i+1)
+			if err != nil {
+				return nil, err
+			}
+			if i > 0 && isNumber(value) {
+				err = file.SetCellDefault(xlsxSheetName, cell, value)
+			} else {
+				err = file.SetCellStr(xlsxSheetName, cell, value)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	buf, err := file.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
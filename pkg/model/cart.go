@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cart is basket of user kept until checkout, one for each user. Stock is not kept in cart,
+// lines are priced and checked again every time cart is read
+type Cart struct {
+	ID           uint `gorm:"primaryKey"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	UserID       uuid.UUID  `gorm:"type:varchar(50);not null;uniqueIndex"`
+	CheckpointID *uuid.UUID `gorm:"type:varchar(50)"`
+	Lines        []CartLine
+}
+
+// CartLine is qty of item or variant in cart, VariantID is 0 for item without variant.
+// Same item and variant added again is added to its line. Price is price of item or variant when
+// line is last added or changed, checkout is refused while it differ from current price
+type CartLine struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	CartID    uint    `gorm:"not null;uniqueIndex:idx_cart_line"`
+	ItemID    uint    `gorm:"not null;uniqueIndex:idx_cart_line"`
+	VariantID uint    `gorm:"not null;default:0;uniqueIndex:idx_cart_line"`
+	Qty       float64 `gorm:"type:decimal(12,3)"`
+	Price     int
+}
@@ -24,3 +24,13 @@ type Checkpoint struct {
 	Village     Village
 	LatLong     string
 }
+
+// CheckpointOperator assign user with checkpoint operator role to checkpoint
+type CheckpointOperator struct {
+	ID           uint `gorm:"primaryKey"`
+	CreatedAt    time.Time
+	UserID       uuid.UUID `gorm:"uniqueIndex:idx_checkpoint_operator; type:varchar(50)"`
+	User         User
+	CheckpointID uuid.UUID `gorm:"uniqueIndex:idx_checkpoint_operator; type:varchar(50)"`
+	Checkpoint   Checkpoint
+}
@@ -15,8 +15,35 @@ type Item struct {
 	CategoryID  uint
 	Category    Category
 	Description string
-	Qty         int
-	Price       int
+	// unit id 3 is piece, the unit of item before unit exist
+	UnitID   uint `gorm:"not null;default:3"`
+	Unit     Unit
+	Qty      float64 `gorm:"type:decimal(12,3)"`
+	Price    int
+	Image    Image `gorm:"embedded"`
+	Variants []ItemVariant
+}
+
+// ItemVariant is size, grade or kind of item with its own price and stock.
+// Item with variant is only ordered through its variant
+type ItemVariant struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	ItemID    uint           `gorm:"not null;index"`
+	Name      string         `gorm:"not null"`
+	Qty       float64        `gorm:"type:decimal(12,3)"`
+	Price     int
+}
+
+// Unit is measure item sold in, Step is the smallest quantity can be ordered and
+// every ordered quantity must be multiple of it
+type Unit struct {
+	ID     uint   `gorm:"primaryKey"`
+	Name   string `gorm:"not null;unique"`
+	Symbol string
+	Step   float64 `gorm:"type:decimal(12,3)"`
 }
 
 type Category struct {
@@ -26,4 +53,13 @@ type Category struct {
 	DeletedAt   gorm.DeletedAt `gorm:"index"`
 	Name        string         `gorm:"not null;unique"`
 	Description string
+	Image       Image `gorm:"embedded"`
+}
+
+// Image is uploaded picture and its thumbnail, key is kept to delete the file when replaced
+type Image struct {
+	ImageKey     string
+	ImageURL     string
+	ThumbnailKey string
+	ThumbnailURL string
 }
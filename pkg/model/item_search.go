@@ -0,0 +1,31 @@
+package model
+
+// ItemSearchGram is one trigram of item name or description, the search index of item.
+// Weight is higher when trigram is in item name
+type ItemSearchGram struct {
+	Gram   string `gorm:"primaryKey;type:varchar(16)"`
+	ItemID uint   `gorm:"primaryKey;index"`
+	Weight int
+}
+
+// ItemSearchIndex is version of built search index, index is rebuilt when version is changed
+type ItemSearchIndex struct {
+	ID      uint `gorm:"primaryKey;autoIncrement:false"`
+	Version int  `gorm:"not null"`
+}
+
+// ItemSearch is searched text and filter of item search, zero value filter is not applied
+type ItemSearch struct {
+	Text       string
+	CategoryID uint
+	MinPrice   int
+	MaxPrice   int
+	InStock    bool
+}
+
+// CategoryFacet is number of searched item in one category
+type CategoryFacet struct {
+	CategoryID uint
+	Name       string
+	Count      int64
+}
@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// LoginAttempt count failed login of one account or ip, identifier is prefixed with its kind like "email:" or "ip:"
+type LoginAttempt struct {
+	Identifier   string `gorm:"primaryKey;type:varchar(191)"`
+	Failures     int    `gorm:"not null"`
+	LastFailedAt time.Time
+	LockedUntil  *time.Time
+}
@@ -1,8 +1,6 @@
 package model
 
 import (
-	"encoding/base64"
-	"math/rand"
 	"time"
 
 	"github.com/google/uuid"
@@ -48,56 +46,24 @@ type OrderDetail struct {
 	Order     Order
 	ItemID    uint
 	Item      Item `gorm:"constraint:OnUpdate:NO ACTION,OnDelete:NO ACTION;"`
-	Qty       int
-	Price     int
-	Total     int
+	// variant and unit are copied when order created, later change of item not change the order
+	VariantID   *uint
+	VariantName string
+	Unit        string
+	Qty         float64 `gorm:"type:decimal(12,3)"`
+	Price       int
+	Total       int
 }
 
-// gorm hooks or trigger
-func (u *Order) AfterCreate(tx *gorm.DB) (err error) {
-	for _, ord := range u.OrderDetail { // create order item qty--
-		var item Item
-		tx.Model(&Item{}).Where("id = ?", ord.ItemID).First(&item)
-		newQty := item.Qty - ord.Qty
-		tx.Model(&Item{}).Where("id = ?", ord.ItemID).Update("qty", newQty)
-	}
-	return
-}
-
-func (u *Order) AfterUpdate(tx *gorm.DB) (err error) {
-	if u.StatusOrderID == 7 { // order cencel item qty++
-		var or Order
-		tx.Model(&Order{}).Where("id = ?", u.ID).Preload("OrderDetail").First(&or)
-		for _, ord := range or.OrderDetail {
-			var item Item
-			tx.Model(&Item{}).Where("id = ?", ord.ItemID).First(&item)
-			newQty := item.Qty + ord.Qty
-			tx.Model(&Item{}).Where("id = ?", ord.ItemID).Update("qty", newQty)
-		}
-		if err != nil {
-			panic(err)
-		}
-		tx.Model(&Order{}).Where("id = ?", u.ID).Update("expired_time", time.Now())
-	} else if u.StatusOrderID == 3 { // order ready generate code
-		var order Order
-		tx.Model(&Order{}).Where("id = ?", u.ID).First(&order)
-		// Generating Random string
-		ran_str := make([]byte, 5)
-		for i := 0; i < 5; i++ {
-			ran_str[i] = byte(48 + rand.Intn(10))
-		}
-		rand := string(ran_str)
-		text := order.ID.String() + " " + rand + " " + order.CheckpointID.String()
-		str := base64.StdEncoding.EncodeToString([]byte(text))
-		if err != nil {
-			panic(err)
-		}
-		tx.Model(&Order{}).Where("id = ?", u.ID).Update("hash", str)
-		tx.Model(&Order{}).Where("id = ?", u.ID).Update("code", rand)
-		if err != nil {
-			panic(err)
-		}
-		tx.Model(&Order{}).Where("id = ?", u.ID).Update("expired_order", time.Now().Add(12*time.Hour))
-	}
-	return
+// OrderStatusHistory is audit trail of every order status change
+type OrderStatusHistory struct {
+	ID           uint `gorm:"primaryKey"`
+	CreatedAt    time.Time
+	OrderID      uuid.UUID   `gorm:"index; type:varchar(50)"`
+	FromStatusID *uint       // nil when order is created
+	FromStatus   StatusOrder `gorm:"foreignKey:FromStatusID"`
+	ToStatusID   uint
+	ToStatus     StatusOrder `gorm:"foreignKey:ToStatusID"`
+	ActorID      *uuid.UUID  `gorm:"type:varchar(50)"`
+	Source       string
 }
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// refund of paid transaction when order expired or cencelled after paid
+type Refund struct {
+	ID            uint `gorm:"primaryKey"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	OrderID       uuid.UUID `gorm:"uniqueIndex; type:varchar(50)"`
+	Order         Order
+	TransactionID uuid.UUID `gorm:"type:varchar(50)"`
+	Transaction   Transaction
+	RefundKey     string `gorm:"uniqueIndex; type:varchar(50)"`
+	Reason        string
+	Amount        int
+	Status        string `gorm:"index; type:varchar(20)"`
+	Attempts      int
+	LastError     string
+}
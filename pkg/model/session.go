@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is one login of user, access token carry session id so revoked session also reject its access token
+type Session struct {
+	ID        uuid.UUID `gorm:"primaryKey; type:varchar(50)"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID `gorm:"type:varchar(50);index"`
+	User      User
+	ExpiredAt time.Time
+	RevokedAt *time.Time
+}
+
+// RefreshToken can be used once to get new token pair, only sha256 hash of token is saved
+type RefreshToken struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	SessionID uuid.UUID `gorm:"type:varchar(50);index"`
+	Session   Session
+	Hash      string `gorm:"type:varchar(64);not null;uniqueIndex"`
+	UsedAt    *time.Time
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StockMovement is one append only entry of stock ledger. Qty is signed, stock in is positive
+// and stock out negative, Balance is stock of the item or variant right after the move.
+// VariantID is set when the move is on variant stock, CheckpointID when it is on stock kept
+// in a checkpoint instead of unallocated stock
+type StockMovement struct {
+	ID           uint       `gorm:"primaryKey"`
+	CreatedAt    time.Time  `gorm:"index"`
+	ItemID       uint       `gorm:"not null;index"`
+	VariantID    *uint      `gorm:"index"`
+	Qty          float64    `gorm:"type:decimal(12,3)"`
+	Balance      float64    `gorm:"type:decimal(12,3)"`
+	Reason       string     `gorm:"type:varchar(20);not null"`
+	OrderID      *uuid.UUID `gorm:"type:varchar(50);index"`
+	CheckpointID *uuid.UUID `gorm:"type:varchar(50);index"`
+	// actor is nil when move is made by system, like expired order worker
+	ActorID *uuid.UUID `gorm:"type:varchar(50)"`
+	Note    string
+	// part of the move taken from or put into stock batches, stock without batch is not listed
+	Batches []StockBatchMove `gorm:"foreignKey:MovementID"`
+}
+
+// StockBatch is stock of item or variant received together, sharing supplier, harvest and
+// expiry date. Qty is what is left of the batch in its checkpoint, or in unallocated stock
+// when CheckpointID is nil. VariantID is 0 for item without variant
+type StockBatch struct {
+	ID           uint `gorm:"primaryKey"`
+	CreatedAt    time.Time
+	ItemID       uint       `gorm:"not null;index:idx_stock_batch_place"`
+	VariantID    uint       `gorm:"not null;default:0;index:idx_stock_batch_place"`
+	CheckpointID *uuid.UUID `gorm:"type:varchar(50);index:idx_stock_batch_place"`
+	Supplier     string
+	// supplier the batch is received from by purchase order, nil for batch of stock move
+	SupplierID  *uint `gorm:"index"`
+	HarvestedAt *time.Time
+	ExpiredAt   time.Time `gorm:"not null;index"`
+	Qty         float64   `gorm:"type:decimal(12,3)"`
+}
+
+// StockBatchMove is signed qty of a stock movement taken from or put into a batch
+type StockBatchMove struct {
+	ID         uint    `gorm:"primaryKey"`
+	MovementID uint    `gorm:"not null;index"`
+	BatchID    uint    `gorm:"not null;index"`
+	Qty        float64 `gorm:"type:decimal(12,3)"`
+	// Batch is the batch to create on stock in, or the batch stock is taken from on stock out
+	Batch *StockBatch `gorm:"-"`
+}
+
+// ExpiringBatch is batch with stock left, with name of its item, variant and checkpoint
+type ExpiringBatch struct {
+	StockBatch
+	ItemName       string
+	VariantName    string
+	CheckpointName string
+}
+
+// CheckpointStock is stock of item or variant kept in a checkpoint, VariantID is 0 for item
+// without variant. Qty of item or variant is the total, stock of every checkpoint plus
+// unallocated stock which is not sent to any checkpoint yet
+type CheckpointStock struct {
+	ID           uint       `gorm:"primaryKey"`
+	CheckpointID uuid.UUID  `gorm:"type:varchar(50);not null;uniqueIndex:idx_checkpoint_stock"`
+	ItemID       uint       `gorm:"not null;uniqueIndex:idx_checkpoint_stock"`
+	VariantID    uint       `gorm:"not null;default:0;uniqueIndex:idx_checkpoint_stock"`
+	Qty          float64    `gorm:"type:decimal(12,3)"`
+	Checkpoint   Checkpoint `gorm:"foreignKey:CheckpointID"`
+}
+
+// StockReport compare stock balance of item or variant with total of its ledger
+type StockReport struct {
+	ItemID      uint
+	VariantID   *uint
+	ItemName    string
+	VariantName string
+	Balance     float64
+	LedgerTotal float64
+}
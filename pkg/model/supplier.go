@@ -0,0 +1,80 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Supplier is farmer or vendor produce is bought from, located in region like checkpoint
+type Supplier struct {
+	ID          uint `gorm:"primaryKey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	Name        string         `gorm:"not null"`
+	Phone       string
+	Description string
+	ProvinceID  uint
+	Province    Province
+	RegencyID   uint
+	Regency     Regency
+	DistrictID  uint
+	District    District
+	VillageID   uint
+	Village     Village
+}
+
+// SupplierCost is unit cost of item or variant in the last purchase order received from
+// the supplier, VariantID is 0 for item without variant
+type SupplierCost struct {
+	ID         uint `gorm:"primaryKey"`
+	UpdatedAt  time.Time
+	SupplierID uint `gorm:"not null;uniqueIndex:idx_supplier_cost"`
+	ItemID     uint `gorm:"not null;uniqueIndex:idx_supplier_cost"`
+	VariantID  uint `gorm:"not null;default:0;uniqueIndex:idx_supplier_cost"`
+	Cost       int
+}
+
+// SupplierMargin is cost of item or variant from supplier with its current selling price
+type SupplierMargin struct {
+	SupplierCost
+	ItemName    string
+	VariantName string
+	Price       int
+}
+
+// PurchaseOrder is produce ordered from supplier. Details only can be changed while draft,
+// and stock is added when it is received, into the checkpoint or unallocated stock when
+// CheckpointID is nil
+type PurchaseOrder struct {
+	ID           uint `gorm:"primaryKey"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	SupplierID   uint `gorm:"not null;index"`
+	Supplier     Supplier
+	CheckpointID *uuid.UUID `gorm:"type:varchar(50)"`
+	Status       string     `gorm:"index; type:varchar(20)"`
+	Note         string
+	// actor who created the order and who received it
+	ActorID    *uuid.UUID `gorm:"type:varchar(50)"`
+	ReceiverID *uuid.UUID `gorm:"type:varchar(50)"`
+	SentAt     *time.Time
+	ReceivedAt *time.Time
+	Details    []PurchaseOrderDetail
+}
+
+// PurchaseOrderDetail is qty of item or variant ordered with its unit cost. Detail with
+// expiry date is received as stock batch
+type PurchaseOrderDetail struct {
+	ID              uint `gorm:"primaryKey"`
+	PurchaseOrderID uint `gorm:"not null;index"`
+	ItemID          uint `gorm:"not null"`
+	Item            Item
+	VariantID       *uint
+	Qty             float64 `gorm:"type:decimal(12,3)"`
+	Cost            int
+	HarvestedAt     *time.Time
+	ExpiredAt       *time.Time
+}
@@ -20,4 +20,20 @@ type Transaction struct {
 	PaymentType       string
 	GrossAmount       string
 	SettlementTime    string
+	// status is applied to order, notification retried before it is applied is applied again
+	OrderApplied bool
+}
+
+// rejected payment notification
+type NotificationAudit struct {
+	ID                uint `gorm:"primaryKey"`
+	CreatedAt         time.Time
+	TransactionID     string
+	OrderID           string
+	TransactionStatus string
+	StatusCode        string
+	GrossAmount       string
+	SignatureKey      string
+	SourceIP          string
+	Reason            string
 }
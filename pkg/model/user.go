@@ -16,16 +16,20 @@ type User struct {
 	Email      string         `gorm:"not null;unique"`
 	Phone      string
 	Password   string `gorm:"not null"`
-	RoleID     uint
-	Role       Role
-	ProvinceID *uint
-	Province   Province
-	RegencyID  *uint
-	Regency    Regency
-	DistrictID *uint
-	District   District
-	VillageID  *uint
-	Village    Village
+	VerifiedAt *time.Time
+	DisabledAt *time.Time
+	// user must change password before access anything else, set for seeded admin and forced reset
+	MustChangePassword bool `gorm:"not null;default:false"`
+	RoleID             uint
+	Role               Role
+	ProvinceID         *uint
+	Province           Province
+	RegencyID          *uint
+	Regency            Regency
+	DistrictID         *uint
+	District           District
+	VillageID          *uint
+	Village            Village
 }
 
 type Role struct {
@@ -35,4 +39,13 @@ type Role struct {
 	DeletedAt   gorm.DeletedAt `gorm:"index"`
 	Name        string
 	Description string
+	Permissions []Permission `gorm:"many2many:role_permissions"`
+}
+
+type Permission struct {
+	ID          uint `gorm:"primaryKey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Name        string `gorm:"type:varchar(50);not null;unique"`
+	Description string
 }
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserToken is single use token sent to user email, for verify email or reset password.
+// Only sha256 hash of token is saved
+type UserToken struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UserID    uuid.UUID `gorm:"type:varchar(50);index"`
+	User      User
+	Purpose   string `gorm:"type:varchar(20);not null"`
+	Hash      string `gorm:"type:varchar(64);not null;uniqueIndex"`
+	ExpiredAt time.Time
+	UsedAt    *time.Time
+}
@@ -1,6 +1,11 @@
 package route
 
 import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
 	"github.com/go-playground/validator"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -10,12 +15,22 @@ import (
 	pkgItemController "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/item/controller"
 	pkgItemRepository "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/item/repository"
 	pkgItemService "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/item/service"
+	pkgItemWorker "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/item/worker"
 	pkgOrderController "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/controller"
 	pkgOrderRepository "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/repository"
 	pkgOrderService "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/service"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/statemachine"
+	pkgOrderWorker "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/order/worker"
+	pkgRefundController "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/refund/controller"
+	pkgRefundRepository "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/refund/repository"
+	pkgRefundService "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/refund/service"
+	pkgRefundWorker "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/refund/worker"
 	pkgRegionController "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/region/controller"
 	pkgRegionRepository "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/region/repository"
 	pkgRegionService "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/region/service"
+	pkgSupplierController "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/supplier/controller"
+	pkgSupplierRepository "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/supplier/repository"
+	pkgSupplierService "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/supplier/service"
 	pkgTransactionController "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/transaction/controller"
 	pkgTransactionRepository "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/transaction/repository"
 	pkgTransactionService "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/internal/transaction/service"
@@ -25,20 +40,75 @@ import (
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/config"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	importcsv "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/import_csv"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/clock"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/mailer"
 	_middleware "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/middleware"
 	password "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/password"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/payment"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/pickup"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/qrcode"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/storage"
 	_validator "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/validator"
 	"gorm.io/gorm"
 )
 
-func InitGlobalRoute(e *echo.Echo, db *gorm.DB) {
+// NewIPExtractor trust X-Forwarded-For only when request come from listed proxy cidr.
+// Without proxy, ip of the connection is the client ip
+func NewIPExtractor(trustedProxies string) (echo.IPExtractor, error) {
+	if strings.TrimSpace(trustedProxies) == "" {
+		return echo.ExtractIPDirect(), nil
+	}
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, cidr := range strings.Split(trustedProxies, ",") {
+		_, ipRange, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, customerrors.ErrTrustedProxy
+		}
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
+
+// Worker run in background until context is done
+type Worker interface {
+	Start(ctx context.Context)
+}
+
+// InitGlobalRoute register every route and return background workers, workers share
+// payment provider and repositories with the routes
+func InitGlobalRoute(e *echo.Echo, db *gorm.DB) []Worker {
+	ipExtractor, err := NewIPExtractor(config.Cfg.TRUSTED_PROXIES)
+	if err != nil {
+		panic(err)
+	}
+	// client ip decide login lockout, header set by client must not be trusted
+	e.IPExtractor = ipExtractor
 	e.Use(middleware.Recover())
 	e.Validator = &_validator.CustomValidator{
 		Validator: validator.New(),
 	}
 	jwtService := _middleware.NewJWTService(config.Cfg.JWT_SECRET, constants.ExpToken)
+	paymentProvider, err := payment.NewPaymentProvider(config.Cfg)
+	if err != nil {
+		panic(err)
+	}
+	refundPolicy, err := payment.NewRefundPolicy(config.Cfg)
+	if err != nil {
+		panic(err)
+	}
+	pickupSigner, err := pickup.NewSigner(config.Cfg.ORDER_SECRET, clock.Clock{})
+	if err != nil {
+		panic(err)
+	}
+	mailerService, err := mailer.NewMailer(config.Cfg)
+	if err != nil {
+		panic(err)
+	}
+	storageService, err := storage.NewStorage(config.Cfg)
+	if err != nil {
+		panic(err)
+	}
 
 	api := e.Group("/api")
 
@@ -49,7 +119,17 @@ func InitGlobalRoute(e *echo.Echo, db *gorm.DB) {
 
 	//init user controller
 	userRepository := pkgUserRepository.NewUserRepository(db)
-	userService := pkgUserService.NewUserService(userRepository, password.Password{}, jwtService)
+	sessionRepository := pkgUserRepository.NewSessionRepository(db)
+	userTokenRepository := pkgUserRepository.NewUserTokenRepository(db)
+	loginAttemptRepository, err := pkgUserRepository.NewLoginAttemptRepository(config.Cfg.LOGIN_ATTEMPT_STORE, db)
+	if err != nil {
+		panic(err)
+	}
+	userService := pkgUserService.NewUserService(userRepository, sessionRepository, userTokenRepository, loginAttemptRepository, password.Password{}, jwtService, mailerService, clock.Clock{})
+	// must be registered before any auth route, group middleware is captured on route creation
+	auth.Use(_middleware.RequireSession(userService))
+	// user with must change password, like seeded admin, can only change the password
+	auth.Use(_middleware.RequirePasswordChanged(http.MethodPut + " /api/v1/users/password"))
 	userController := pkgUserController.NewUserController(userService, jwtService)
 	userController.InitRoute(v1, auth)
 
@@ -68,19 +148,56 @@ func InitGlobalRoute(e *echo.Echo, db *gorm.DB) {
 
 	// init item controller
 	itemRepository := pkgItemRepository.NewItemRepository(db)
-	itemService := pkgItemService.NewItemService(itemRepository)
+	itemSearchRepository := pkgItemRepository.NewItemSearchRepository(db)
+	itemService, err := pkgItemService.NewItemService(itemRepository, itemSearchRepository, storageService, importCsvService, checkpointService)
+	if err != nil {
+		panic(err)
+	}
 	itemController := pkgItemController.NewItemController(itemService, jwtService)
 	itemController.InitRoute(auth)
 
+	// init supplier controller
+	supplierRepository := pkgSupplierRepository.NewSupplierRepository(db)
+	supplierService := pkgSupplierService.NewSupplierService(supplierRepository)
+	supplierController := pkgSupplierController.NewSupplierController(supplierService, jwtService)
+	supplierController.InitRoute(auth)
+
 	// init order controller
 	orderRepository := pkgOrderRepository.NewOrderRepository(db)
-	orderService := pkgOrderService.NewOrderService(orderRepository, itemRepository, &payment.Midtrans{}, userRepository)
+	cartRepository := pkgOrderRepository.NewCartRepository(db)
+	orderStateMachine := statemachine.NewStateMachine(orderRepository, clock.Clock{}, refundPolicy, pickupSigner)
+	orderService := pkgOrderService.NewOrderService(orderRepository, cartRepository, itemRepository, paymentProvider, userRepository, checkpointRepository, orderStateMachine, pickupSigner)
 	orderController := pkgOrderController.NewOrderController(orderService, jwtService, &qrcode.QRCode{})
 	orderController.InitRoute(auth)
 
 	// init transaction controller
 	transactionRepository := pkgTransactionRepository.NewTransactionRepository(db)
-	transactionService := pkgTransactionService.NewTransactionService(transactionRepository, orderRepository, orderService)
+	transactionService := pkgTransactionService.NewTransactionService(transactionRepository, orderRepository, orderService, config.Cfg.MIDTRANS_SERVER_KEY)
 	transactionController := pkgTransactionController.NewTransactionController(transactionService, jwtService)
 	transactionController.InitRoute(v1, auth)
+
+	// init refund controller
+	refundRepository := pkgRefundRepository.NewRefundRepository(db)
+	refundService := pkgRefundService.NewRefundService(refundRepository, paymentProvider)
+	refundController := pkgRefundController.NewRefundController(refundService, jwtService)
+	refundController.InitRoute(auth)
+
+	// uploaded file of local storage
+	if localStorage, ok := storageService.(*storage.Local); ok {
+		localStorage.InitRoute(e)
+	}
+
+	// fake payment page for QA environment
+	if fakePayment, ok := paymentProvider.(*payment.Fake); ok {
+		fakePayment.InitRoute(v1)
+	}
+
+	return []Worker{
+		// cencel expired pending order and refund expired ready order
+		pkgOrderWorker.NewExpiryWorker(orderRepository, orderStateMachine, clock.Clock{}, constants.ExpiryWorkerInterval),
+		// write off expired stock batch as spoilage
+		pkgItemWorker.NewBatchWorker(itemRepository, clock.Clock{}, constants.BatchWorkerInterval),
+		// send pending refund to payment provider
+		pkgRefundWorker.NewRefundWorker(refundService, constants.RefundWorkerInterval),
+	}
 }
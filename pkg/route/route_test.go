@@ -0,0 +1,278 @@
+package route
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/config"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/database/testdb"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// route anyone can call without jwt
+var publicRoutes = map[string]bool{
+	http.MethodPost + " /api/v1/signup":                    true,
+	http.MethodPost + " /api/v1/login":                     true,
+	http.MethodPost + " /api/v1/token/refresh":             true,
+	http.MethodPost + " /api/v1/logout":                    true,
+	http.MethodPost + " /api/v1/email/verify":              true,
+	http.MethodPost + " /api/v1/email/resend":              true,
+	http.MethodPost + " /api/v1/password/forgot":           true,
+	http.MethodPost + " /api/v1/password/reset":            true,
+	http.MethodPost + " /api/v1/transactions/notification": true,
+	// uploaded image of local storage
+	http.MethodGet + " /uploads*": true,
+}
+
+// route only admin can call
+var adminRoutes = []string{
+	http.MethodPost + " /api/v1/items",
+	http.MethodPut + " /api/v1/items/:id",
+	http.MethodPut + " /api/v1/items/:id/image",
+	http.MethodGet + " /api/v1/items/archived",
+	http.MethodDelete + " /api/v1/items/:id",
+	http.MethodPut + " /api/v1/items/:id/restore",
+	http.MethodPost + " /api/v1/items/import",
+	http.MethodGet + " /api/v1/items/export",
+	http.MethodGet + " /api/v1/items/stock/report",
+	http.MethodGet + " /api/v1/items/stock/expiring",
+	http.MethodPost + " /api/v1/items/:id/stock",
+	http.MethodGet + " /api/v1/items/:id/stock",
+	http.MethodPost + " /api/v1/items/:id/stock/transfer",
+	http.MethodGet + " /api/v1/items/:id/stock/checkpoints",
+	http.MethodPost + " /api/v1/items/:id/variants",
+	http.MethodPut + " /api/v1/items/:id/variants/:variant_id",
+	http.MethodDelete + " /api/v1/items/:id/variants/:variant_id",
+	http.MethodPost + " /api/v1/items/categories",
+	http.MethodPut + " /api/v1/items/categories/:id/image",
+	http.MethodGet + " /api/v1/items/categories/archived",
+	http.MethodDelete + " /api/v1/items/categories/:id",
+	http.MethodPut + " /api/v1/items/categories/:id/restore",
+	http.MethodPost + " /api/v1/items/categories/import",
+	http.MethodGet + " /api/v1/items/categories/export",
+	http.MethodPost + " /api/v1/checkpoints",
+	http.MethodPost + " /api/v1/checkpoints/:id/operators",
+	http.MethodDelete + " /api/v1/checkpoints/:id/operators/:user_id",
+	http.MethodGet + " /api/v1/users",
+	http.MethodGet + " /api/v1/users/:id",
+	http.MethodDelete + " /api/v1/users/:id",
+	http.MethodPut + " /api/v1/users/:id/role",
+	http.MethodPut + " /api/v1/users/:id/disable",
+	http.MethodPut + " /api/v1/users/:id/enable",
+	http.MethodPost + " /api/v1/users/:id/password/reset",
+	http.MethodPut + " /api/v1/users/:id/unlock",
+	http.MethodGet + " /api/v1/refunds",
+	http.MethodPost + " /api/v1/refunds/:id/retry",
+	http.MethodPost + " /api/v1/suppliers",
+	http.MethodGet + " /api/v1/suppliers",
+	http.MethodPut + " /api/v1/suppliers/:id",
+	http.MethodGet + " /api/v1/suppliers/:id/costs",
+	http.MethodPost + " /api/v1/purchase-orders",
+	http.MethodGet + " /api/v1/purchase-orders",
+	http.MethodGet + " /api/v1/purchase-orders/:id",
+	http.MethodPut + " /api/v1/purchase-orders/:id",
+	http.MethodPut + " /api/v1/purchase-orders/:id/send",
+	http.MethodPut + " /api/v1/purchase-orders/:id/receive",
+}
+
+func newTestEcho(t *testing.T) (*echo.Echo, *gorm.DB) {
+	config.Cfg = &config.Config{
+		JWT_SECRET:               "jwt-secret",
+		ORDER_SECRET:             "order-secret",
+		REFUND_EXPIRED_PERCENT:   constants.Refund_expired_percent,
+		REFUND_CANCELLED_PERCENT: constants.Refund_cancelled_percent,
+	}
+	constants.PathProvinceCsv = "testdata/provinces.csv"
+	constants.PathRegencyCsv = "testdata/regencies.csv"
+	constants.PathDistrictCsv = "testdata/districts.csv"
+	constants.PathVillageCsv = "testdata/villages.csv"
+	e := echo.New()
+	db := testdb.New(t)
+	InitGlobalRoute(e, db)
+	return e, db
+}
+
+// newToken create access token of new active session
+func newToken(t *testing.T, db *gorm.DB, role float64) (string, model.Session) {
+	session := model.Session{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+	assert.NoError(t, db.Create(&session).Error)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": session.UserID.String(),
+		"role_id": role,
+		"sid":     session.ID.String(),
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(config.Cfg.JWT_SECRET))
+	assert.NoError(t, err)
+	return token, session
+}
+
+func request(e *echo.Echo, method string, path string, token string) int {
+	// fill path param with valid id, so only permission decide the response
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, ":") {
+			segment = uuid.New().String()
+		}
+		segments = append(segments, segment)
+	}
+	r := httptest.NewRequest(method, strings.Join(segments, "/"), nil)
+	r.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	return w.Result().StatusCode
+}
+
+// every route must declare permission, new route without permission is rejected for role without permission
+func TestRouteFailClosed(t *testing.T) {
+	e, db := newTestEcho(t)
+	notFoundHandler := runtime.FuncForPC(reflect.ValueOf(echo.NotFoundHandler).Pointer()).Name()
+	token, _ := newToken(t, db, 0)
+
+	checked := 0
+	for _, route := range e.Routes() {
+		key := route.Method + " " + route.Path
+		// group catch all route registered by echo for group middleware
+		if route.Name == notFoundHandler || publicRoutes[key] {
+			continue
+		}
+		checked++
+		assert.Equal(t, http.StatusForbidden, request(e, route.Method, route.Path, token), key)
+	}
+	assert.NotZero(t, checked)
+}
+
+func TestAdminRoute(t *testing.T) {
+	e, db := newTestEcho(t)
+	routes := map[string]bool{}
+	for _, route := range e.Routes() {
+		routes[route.Method+" "+route.Path] = true
+	}
+
+	for _, role := range []float64{constants.Role_user, constants.Role_checkpoint_operator} {
+		token, _ := newToken(t, db, role)
+		for _, key := range adminRoutes {
+			assert.True(t, routes[key], key)
+			method, path, _ := strings.Cut(key, " ")
+			assert.Equal(t, http.StatusForbidden, request(e, method, path, token), key)
+		}
+	}
+}
+
+// access token of revoked session is rejected on every auth route before it expired
+func TestRevokedSession(t *testing.T) {
+	e, db := newTestEcho(t)
+	notFoundHandler := runtime.FuncForPC(reflect.ValueOf(echo.NotFoundHandler).Pointer()).Name()
+	token, session := newToken(t, db, constants.Role_admin)
+	assert.NoError(t, db.Model(&session).Update("revoked_at", time.Now()).Error)
+	noSession, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": uuid.New().String(),
+		"role_id": constants.Role_admin,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(config.Cfg.JWT_SECRET))
+	assert.NoError(t, err)
+
+	for _, route := range e.Routes() {
+		key := route.Method + " " + route.Path
+		if route.Name == notFoundHandler || publicRoutes[key] {
+			continue
+		}
+		assert.Equal(t, http.StatusUnauthorized, request(e, route.Method, route.Path, token), key)
+		assert.Equal(t, http.StatusUnauthorized, request(e, route.Method, route.Path, noSession), key)
+	}
+}
+
+func requestJSON(e *echo.Echo, method string, path string, token string, body string) (int, map[string]interface{}) {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if token != "" {
+		r.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	result := map[string]interface{}{}
+	json.NewDecoder(w.Result().Body).Decode(&result)
+	return w.Result().StatusCode, result
+}
+
+// default admin must change password before using any other route
+func TestDefaultAdminMustChangePassword(t *testing.T) {
+	e, _ := newTestEcho(t)
+
+	status, login := requestJSON(e, http.MethodPost, "/api/v1/login", "", `{"email":"admin@gmail.com","password":"admin"}`)
+	assert.Equal(t, http.StatusOK, status)
+	token, _ := login["token"].(string)
+	assert.NotEmpty(t, token)
+
+	status, _ = requestJSON(e, http.MethodGet, "/api/v1/users/profile", token, "")
+	assert.Equal(t, http.StatusForbidden, status)
+
+	status, changed := requestJSON(e, http.MethodPut, "/api/v1/users/password", token, `{"current_password":"admin","new_password":"new-password"}`)
+	assert.Equal(t, http.StatusOK, status)
+	changedToken, _ := changed["token"].(string)
+	assert.NotEmpty(t, changedToken)
+
+	status, _ = requestJSON(e, http.MethodGet, "/api/v1/users/profile", changedToken, "")
+	assert.Equal(t, http.StatusOK, status)
+
+	status, _ = requestJSON(e, http.MethodPost, "/api/v1/login", "", `{"email":"admin@gmail.com","password":"new-password"}`)
+	assert.Equal(t, http.StatusOK, status)
+}
+
+// X-Forwarded-For set by client must not give new ip to bypass ip login lockout
+func TestLoginSpoofedForwardedFor(t *testing.T) {
+	e, db := newTestEcho(t)
+
+	for _, ip := range []string{"203.0.113.1", "203.0.113.2"} {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/login", strings.NewReader(`{"email":"admin@gmail.com","password":"wrong"}`))
+		r.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		r.Header.Set(echo.HeaderXForwardedFor, ip)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusUnauthorized, w.Result().StatusCode)
+	}
+
+	var attempt model.LoginAttempt
+	assert.NoError(t, db.Where("identifier = ?", "ip:192.0.2.1").First(&attempt).Error)
+	assert.Equal(t, 2, attempt.Failures)
+	var spoofed int64
+	assert.NoError(t, db.Model(&model.LoginAttempt{}).Where("identifier LIKE ?", "ip:203.0.113.%").Count(&spoofed).Error)
+	assert.Zero(t, spoofed)
+}
+
+func TestNewIPExtractor(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.5:1234"
+	r.Header.Set(echo.HeaderXForwardedFor, "203.0.113.1")
+
+	extractor, err := NewIPExtractor("")
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.5", extractor(r))
+
+	extractor, err = NewIPExtractor("10.0.0.0/8, 172.16.0.0/12")
+	assert.NoError(t, err)
+	assert.Equal(t, "203.0.113.1", extractor(r))
+
+	// proxy not listed is not trusted even in private network
+	r.RemoteAddr = "192.168.1.5:1234"
+	assert.Equal(t, "192.168.1.5", extractor(r))
+
+	_, err = NewIPExtractor("10.0.0.0")
+	assert.Equal(t, customerrors.ErrTrustedProxy, err)
+}
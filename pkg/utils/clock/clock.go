@@ -0,0 +1,10 @@
+package clock
+
+import "time"
+
+type Clock struct {
+}
+
+func (Clock) Now() time.Time {
+	return time.Now()
+}
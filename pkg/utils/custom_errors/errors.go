@@ -20,5 +20,76 @@ var (
 	ErrUpdateStatusOrder            = errors.New("cant update status order")
 	ErrGenerateQR                   = errors.New("error when generate qrcode")
 	ErrCodeUsed                     = errors.New("code is used")
+	ErrCodeExpired                  = errors.New("code is expired")
+	ErrOrderSecret                  = errors.New("order secret is not set")
+	ErrInvalidOperator              = errors.New("user cant be checkpoint operator")
 	ErrWrongCheckpoint              = errors.New("cant pick up this order at this checkpoint")
+	ErrInvalidSignature             = errors.New("invalid signature key")
+	ErrGrossAmount                  = errors.New("gross amount not match with order")
+	ErrNotificationReplay           = errors.New("notification already processed")
+	ErrNotificationOutOfOrder       = errors.New("notification out of order")
+	ErrUnknownTransactionStatus     = errors.New("unknown transaction status")
+	ErrPaymentConfig                = errors.New("invalid payment provider or environment")
+	ErrPaymentNotification          = errors.New("payment notification rejected")
+	ErrRefundPolicy                 = errors.New("refund percent must be between 0 and 100")
+	ErrRefundNotRetryable           = errors.New("only failed refund can be retried")
+	ErrRefundProvider               = errors.New("payment provider refused refund")
+	ErrInvalidToken                 = errors.New("invalid or expired token")
+	ErrTokenRevoked                 = errors.New("token is revoked")
+	ErrTokenUsed                    = errors.New("refresh token is used")
+	ErrMailConfig                   = errors.New("invalid mail driver or smtp config")
+	ErrTrustedProxy                 = errors.New("invalid cidr of trusted proxy")
+	ErrMailHeader                   = errors.New("invalid mail header")
+	ErrSamePassword                 = errors.New("new password must be different from current password")
+	ErrPasswordChangeRequired       = errors.New("password must be changed before continue")
+	ErrUserDisabled                 = errors.New("user is disabled")
+	ErrInvalidRole                  = errors.New("role not found")
+	ErrDefaultAdmin                 = errors.New("default admin role and status cant be changed")
+	ErrChangeOwnAccount             = errors.New("cant change role or status of own account")
+	ErrEmailNotVerified             = errors.New("email is not verified")
+	ErrInvalidCredentials           = errors.New("invalid email or password")
+	ErrLoginLocked                  = errors.New("too many failed login, try again later")
+	ErrLoginAttemptStore            = errors.New("invalid login attempt store")
+	ErrInvalidPage                  = errors.New("page and limit must be positive number, limit at most 100")
+	ErrInvalidSort                  = errors.New("sort field not allowed")
+	ErrInvalidFilter                = errors.New("invalid filter value")
+	ErrInvalidSearch                = errors.New("search text must contain letter or number")
+	ErrStorageConfig                = errors.New("invalid storage driver or s3 config")
+	ErrStorageKey                   = errors.New("invalid storage key")
+	ErrStorage                      = errors.New("storage request failed")
+	ErrImageRequired                = errors.New("image file is required")
+	ErrImageType                    = errors.New("image must be jpeg or png")
+	ErrImageTooLarge                = errors.New("image must be at most 2MB and 40 megapixel")
+	ErrQtyUnit                      = errors.New("qty must be positive multiple of item unit step")
+	ErrVariantRequired              = errors.New("item has variant, variant_id is required")
+	ErrInvalidVariant               = errors.New("variant not found in item")
+	ErrItemUnavailable              = errors.New("item not found or no longer sold")
+	ErrCategoryNotEmpty             = errors.New("category still has item, archive its item first")
+	ErrCategoryArchived             = errors.New("category of item is archived, restore it first")
+	ErrFileFormat                   = errors.New("file format must be csv or xlsx")
+	ErrImportFile                   = errors.New("import file is required, at most 5MB")
+	ErrImportHeader                 = errors.New("import file header is missing column")
+	ErrImportRows                   = errors.New("import file has no row or more than 5000 row")
+	ErrImportInvalid                = errors.New("import has invalid row, nothing is saved")
+	ErrRowName                      = errors.New("name is required")
+	ErrRowDuplicate                 = errors.New("name appear more than once in file")
+	ErrRowCategory                  = errors.New("category not found")
+	ErrRowUnit                      = errors.New("unit not found")
+	ErrRowQty                       = errors.New("qty must be number at least 0")
+	ErrRowPrice                     = errors.New("price must be whole number at least 0")
+	ErrRowArchived                  = errors.New("row is archived, restore it first")
+	ErrStockNotEnough               = errors.New("stock is not enough")
+	ErrStockQty                     = errors.New("qty must be positive for purchase and spoilage, and not zero for adjustment")
+	ErrStockTransfer                = errors.New("transfer source and destination must be different")
+	ErrCheckpointNotFound           = errors.New("checkpoint not found")
+	ErrStockBatch                   = errors.New("batch is only received by purchase and must expire after harvest")
+	ErrSupplierNotFound             = errors.New("supplier not found")
+	ErrPurchaseOrderStatus          = errors.New("purchase order is not in status allowed for this action")
+	ErrPurchaseOrderDetail          = errors.New("purchase order detail must have positive qty, cost at least 0 and expire after harvest")
+	ErrCartEmpty                    = errors.New("cart is empty")
+	ErrCartCheckpoint               = errors.New("choose checkpoint of cart before checkout")
+	ErrCartOutOfStock               = errors.New("item is out of stock in checkpoint")
+	ErrCartStockNotEnough           = errors.New("qty exceeds stock left in checkpoint")
+	ErrCartPriceChanged             = errors.New("price changed since item is added to cart, update qty of the line to accept new price")
+	ErrCartChanged                  = errors.New("cart changed during checkout, check cart again")
 )
@@ -0,0 +1,31 @@
+package mailer
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Log write mail to writer instead of sending it, for local and test environment.
+// Token in mail body is readable by anyone who can read the writer, never use it in production
+type Log struct {
+	mu     sync.Mutex
+	writer io.Writer
+	from   string
+}
+
+func NewLog(writer io.Writer, from string) *Log {
+	return &Log{
+		writer: writer,
+		from:   from,
+	}
+}
+
+// Send implements Mailer
+func (l *Log) Send(message Message) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err := fmt.Fprintf(l.writer, "Date: %s\nFrom: %s\nTo: %s\nSubject: %s\n\n%s\n\n", time.Now().Format(time.RFC1123Z), l.from, message.To, message.Subject, message.Body)
+	return err
+}
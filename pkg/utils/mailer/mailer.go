@@ -0,0 +1,43 @@
+package mailer
+
+import (
+	"os"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/config"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+)
+
+type Mailer interface {
+	Send(message Message) error
+}
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// NewMailer choose mail driver from config, log driver only print mail so it is safe for local
+func NewMailer(cfg *config.Config) (Mailer, error) {
+	switch cfg.MAIL_DRIVER {
+	case "", constants.Mail_driver_log:
+		return NewLog(os.Stdout, cfg.MAIL_FROM), nil
+	case constants.Mail_driver_file:
+		if cfg.MAIL_FILE_PATH == "" {
+			return nil, customerrors.ErrMailConfig
+		}
+		file, err := os.OpenFile(cfg.MAIL_FILE_PATH, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, err
+		}
+		return NewLog(file, cfg.MAIL_FROM), nil
+	case constants.Mail_driver_smtp:
+		if cfg.SMTP_HOST == "" || cfg.SMTP_PORT == "" || cfg.MAIL_FROM == "" {
+			return nil, customerrors.ErrMailConfig
+		}
+		return NewSMTP(cfg.SMTP_HOST, cfg.SMTP_PORT, cfg.SMTP_USERNAME, cfg.SMTP_PASSWORD, cfg.MAIL_FROM), nil
+	default:
+		return nil, customerrors.ErrMailConfig
+	}
+}
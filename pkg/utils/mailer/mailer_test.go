@@ -0,0 +1,80 @@
+package mailer
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/config"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMailer(t *testing.T) {
+	testCase := []struct {
+		Name         string
+		Cfg          config.Config
+		ExpectedType interface{}
+		ExpectedErr  error
+	}{
+		{
+			Name:         "default log",
+			Cfg:          config.Config{},
+			ExpectedType: &Log{},
+		},
+		{
+			Name:         "file",
+			Cfg:          config.Config{MAIL_DRIVER: constants.Mail_driver_file, MAIL_FILE_PATH: filepath.Join(t.TempDir(), "mail.log")},
+			ExpectedType: &Log{},
+		},
+		{
+			Name:        "file without path",
+			Cfg:         config.Config{MAIL_DRIVER: constants.Mail_driver_file},
+			ExpectedErr: customerrors.ErrMailConfig,
+		},
+		{
+			Name:         "smtp",
+			Cfg:          config.Config{MAIL_DRIVER: constants.Mail_driver_smtp, SMTP_HOST: "localhost", SMTP_PORT: "25", MAIL_FROM: "noreply@kangsayur.com"},
+			ExpectedType: &SMTP{},
+		},
+		{
+			Name:        "smtp without host",
+			Cfg:         config.Config{MAIL_DRIVER: constants.Mail_driver_smtp, SMTP_PORT: "25", MAIL_FROM: "noreply@kangsayur.com"},
+			ExpectedErr: customerrors.ErrMailConfig,
+		},
+		{
+			Name:        "unknown driver",
+			Cfg:         config.Config{MAIL_DRIVER: "pigeon"},
+			ExpectedErr: customerrors.ErrMailConfig,
+		},
+	}
+	for _, v := range testCase {
+		t.Run(v.Name, func(t *testing.T) {
+			mailer, err := NewMailer(&v.Cfg)
+			assert.Equal(t, v.ExpectedErr, err)
+			if v.ExpectedErr == nil {
+				assert.IsType(t, v.ExpectedType, mailer)
+			}
+		})
+	}
+}
+
+func TestLog_Send(t *testing.T) {
+	var buf bytes.Buffer
+	mailer := NewLog(&buf, "noreply@kangsayur.com")
+
+	err := mailer.Send(Message{To: "user@gmail.com", Subject: "Verify email", Body: "token: abc"})
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "From: noreply@kangsayur.com\nTo: user@gmail.com\nSubject: Verify email\n\ntoken: abc\n")
+}
+
+func TestBuildMessage(t *testing.T) {
+	msg, err := buildMessage("noreply@kangsayur.com", Message{To: "user@gmail.com", Subject: "Verify email", Body: "line 1\nline 2"})
+	assert.NoError(t, err)
+	assert.Equal(t, "From: noreply@kangsayur.com\r\nTo: user@gmail.com\r\nSubject: Verify email\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=\"utf-8\"\r\n\r\nline 1\r\nline 2\r\n", string(msg))
+
+	_, err = buildMessage("noreply@kangsayur.com", Message{To: "user@gmail.com\r\nBcc: other@gmail.com", Subject: "Verify email"})
+	assert.Equal(t, customerrors.ErrMailHeader, err)
+}
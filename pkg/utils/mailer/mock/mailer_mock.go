@@ -0,0 +1,16 @@
+package mock
+
+import (
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/mailer"
+	"github.com/stretchr/testify/mock"
+)
+
+type MailerMock struct {
+	mock.Mock
+}
+
+func (m *MailerMock) Send(message mailer.Message) error {
+	args := m.Called(message)
+
+	return args.Error(0)
+}
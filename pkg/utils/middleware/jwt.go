@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 )
@@ -20,11 +21,15 @@ func NewJWTService(secretKey string, exp time.Duration) *JWTService {
 	}
 }
 
-func (j *JWTService) GenerateToken(user *model.User) (string, error) {
+// GenerateToken create access token of user session, revoked session reject the token before it expired
+func (j *JWTService) GenerateToken(user *model.User, sessionId uuid.UUID) (string, error) {
 	claims := &jwt.MapClaims{
 		"user_id": user.ID,
 		"role_id": user.RoleID,
+		"sid":     sessionId,
 		"exp":     time.Now().Add(j.exp).Unix(),
+		// checked by RequirePasswordChanged
+		"must_change_password": user.MustChangePassword,
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(j.secretKey))
@@ -2,6 +2,7 @@ package mock
 
 import (
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 	"github.com/stretchr/testify/mock"
@@ -11,7 +12,7 @@ type MockJWTService struct {
 	mock.Mock
 }
 
-func (m *MockJWTService) GenerateToken(user *model.User) (string, error) {
+func (m *MockJWTService) GenerateToken(user *model.User, sessionId uuid.UUID) (string, error) {
 	args := m.Called()
 	return args.String(0), args.Error(1)
 }
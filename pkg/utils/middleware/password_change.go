@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+)
+
+// RequirePasswordChanged reject token with must_change_password claim, except on allowed route.
+// Allowed route is written as method and route path, like "PUT /api/v1/users/password"
+func RequirePasswordChanged(allowed ...string) echo.MiddlewareFunc {
+	allowedRoutes := map[string]bool{}
+	for _, route := range allowed {
+		allowedRoutes[route] = true
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token, ok := c.Get("user").(*jwt.Token)
+			if !ok {
+				return forbidden(c)
+			}
+			claims, ok := token.Claims.(jwt.MapClaims)
+			if !ok {
+				return forbidden(c)
+			}
+			mustChange, _ := claims["must_change_password"].(bool)
+			if mustChange && !allowedRoutes[c.Request().Method+" "+c.Path()] {
+				return c.JSON(http.StatusForbidden, echo.Map{
+					"message": customerrors.ErrPasswordChangeRequired.Error(),
+				})
+			}
+			return next(c)
+		}
+	}
+}
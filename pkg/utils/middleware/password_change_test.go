@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequirePasswordChanged(t *testing.T) {
+	testCase := []struct {
+		Name           string
+		User           interface{}
+		Method         string
+		Path           string
+		ExpectedStatus int
+		ExpectedMsg    string
+	}{
+		{
+			Name:           "password changed",
+			User:           &jwt.Token{Claims: jwt.MapClaims{"must_change_password": false}},
+			Method:         http.MethodGet,
+			Path:           "/api/v1/orders",
+			ExpectedStatus: http.StatusOK,
+		},
+		{
+			Name:           "token without claim",
+			User:           &jwt.Token{Claims: jwt.MapClaims{"user_id": "user"}},
+			Method:         http.MethodGet,
+			Path:           "/api/v1/orders",
+			ExpectedStatus: http.StatusOK,
+		},
+		{
+			Name:           "must change password",
+			User:           &jwt.Token{Claims: jwt.MapClaims{"must_change_password": true}},
+			Method:         http.MethodGet,
+			Path:           "/api/v1/orders",
+			ExpectedStatus: http.StatusForbidden,
+			ExpectedMsg:    customerrors.ErrPasswordChangeRequired.Error(),
+		},
+		{
+			Name:           "must change password on allowed route",
+			User:           &jwt.Token{Claims: jwt.MapClaims{"must_change_password": true}},
+			Method:         http.MethodPut,
+			Path:           "/api/v1/users/password",
+			ExpectedStatus: http.StatusOK,
+		},
+		{
+			Name:           "must change password on allowed path with other method",
+			User:           &jwt.Token{Claims: jwt.MapClaims{"must_change_password": true}},
+			Method:         http.MethodGet,
+			Path:           "/api/v1/users/password",
+			ExpectedStatus: http.StatusForbidden,
+			ExpectedMsg:    customerrors.ErrPasswordChangeRequired.Error(),
+		},
+		{
+			Name:           "no jwt token",
+			User:           nil,
+			Method:         http.MethodGet,
+			Path:           "/api/v1/orders",
+			ExpectedStatus: http.StatusForbidden,
+			ExpectedMsg:    customerrors.ErrPermission.Error(),
+		},
+	}
+
+	for _, v := range testCase {
+		t.Run(v.Name, func(t *testing.T) {
+			r := httptest.NewRequest(v.Method, v.Path, nil)
+			w := httptest.NewRecorder()
+			ctx := echo.New().NewContext(r, w)
+			ctx.SetPath(v.Path)
+			if v.User != nil {
+				ctx.Set("user", v.User)
+			}
+			called := false
+			handler := RequirePasswordChanged(http.MethodPut + " /api/v1/users/password")(func(c echo.Context) error {
+				called = true
+				return c.NoContent(http.StatusOK)
+			})
+
+			err := handler(ctx)
+
+			assert.NoError(t, err)
+			assert.Equal(t, v.ExpectedStatus, w.Result().StatusCode)
+			assert.Equal(t, v.ExpectedStatus == http.StatusOK, called)
+			if v.ExpectedStatus != http.StatusOK {
+				result := map[string]interface{}{}
+				assert.NoError(t, json.NewDecoder(w.Result().Body).Decode(&result))
+				assert.Equal(t, v.ExpectedMsg, result["message"])
+			}
+		})
+	}
+}
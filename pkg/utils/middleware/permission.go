@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+)
+
+// RequirePermission only let request through when role of role_id claim has every permissions.
+// It must be used after jwt middleware, request without valid claims is forbidden
+func RequirePermission(permissions ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token, ok := c.Get("user").(*jwt.Token)
+			if !ok {
+				return forbidden(c)
+			}
+			claims, ok := token.Claims.(jwt.MapClaims)
+			if !ok || len(permissions) == 0 {
+				return forbidden(c)
+			}
+			for _, permission := range permissions {
+				if !HasPermission(claims, permission) {
+					return forbidden(c)
+				}
+			}
+			return next(c)
+		}
+	}
+}
+
+// HasPermission report whether role of role_id claim has permission
+func HasPermission(claims jwt.MapClaims, permission string) bool {
+	role, ok := claims["role_id"].(float64)
+	if !ok || role < 0 || role != float64(uint(role)) {
+		return false
+	}
+	for _, p := range constants.RolePermission[uint(role)] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func forbidden(c echo.Context) error {
+	return c.JSON(http.StatusForbidden, echo.Map{
+		"message": customerrors.ErrPermission.Error(),
+	})
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequirePermission(t *testing.T) {
+	testCase := []struct {
+		Name           string
+		User           interface{}
+		Permissions    []string
+		ExpectedStatus int
+	}{
+		{
+			Name:           "role has permission",
+			User:           &jwt.Token{Claims: jwt.MapClaims{"role_id": float64(constants.Role_admin)}},
+			Permissions:    []string{constants.Permission_item_manage},
+			ExpectedStatus: http.StatusOK,
+		},
+		{
+			Name:           "role has every permissions",
+			User:           &jwt.Token{Claims: jwt.MapClaims{"role_id": float64(constants.Role_checkpoint_operator)}},
+			Permissions:    []string{constants.Permission_order_read, constants.Permission_order_process},
+			ExpectedStatus: http.StatusOK,
+		},
+		{
+			Name:           "role miss one of permissions",
+			User:           &jwt.Token{Claims: jwt.MapClaims{"role_id": float64(constants.Role_checkpoint_operator)}},
+			Permissions:    []string{constants.Permission_order_process, constants.Permission_order_manage},
+			ExpectedStatus: http.StatusForbidden,
+		},
+		{
+			Name:           "role without permission",
+			User:           &jwt.Token{Claims: jwt.MapClaims{"role_id": float64(constants.Role_user)}},
+			Permissions:    []string{constants.Permission_item_manage},
+			ExpectedStatus: http.StatusForbidden,
+		},
+		{
+			Name:           "unknown role",
+			User:           &jwt.Token{Claims: jwt.MapClaims{"role_id": float64(100)}},
+			Permissions:    []string{constants.Permission_item_read},
+			ExpectedStatus: http.StatusForbidden,
+		},
+		{
+			Name:           "role claim not integer",
+			User:           &jwt.Token{Claims: jwt.MapClaims{"role_id": 1.5}},
+			Permissions:    []string{constants.Permission_item_read},
+			ExpectedStatus: http.StatusForbidden,
+		},
+		{
+			Name:           "role claim missing",
+			User:           &jwt.Token{Claims: jwt.MapClaims{"user_id": "user"}},
+			Permissions:    []string{constants.Permission_item_read},
+			ExpectedStatus: http.StatusForbidden,
+		},
+		{
+			Name:           "role claim not number",
+			User:           &jwt.Token{Claims: jwt.MapClaims{"role_id": "1"}},
+			Permissions:    []string{constants.Permission_item_read},
+			ExpectedStatus: http.StatusForbidden,
+		},
+		{
+			Name:           "no permission required",
+			User:           &jwt.Token{Claims: jwt.MapClaims{"role_id": float64(constants.Role_admin)}},
+			Permissions:    nil,
+			ExpectedStatus: http.StatusForbidden,
+		},
+		{
+			Name:           "no jwt token",
+			User:           nil,
+			Permissions:    []string{constants.Permission_item_read},
+			ExpectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, v := range testCase {
+		t.Run(v.Name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			ctx := echo.New().NewContext(r, w)
+			if v.User != nil {
+				ctx.Set("user", v.User)
+			}
+			called := false
+			handler := RequirePermission(v.Permissions...)(func(c echo.Context) error {
+				called = true
+				return c.NoContent(http.StatusOK)
+			})
+
+			err := handler(ctx)
+
+			assert.NoError(t, err)
+			assert.Equal(t, v.ExpectedStatus, w.Result().StatusCode)
+			assert.Equal(t, v.ExpectedStatus == http.StatusOK, called)
+			if v.ExpectedStatus == http.StatusForbidden {
+				result := map[string]interface{}{}
+				assert.NoError(t, json.NewDecoder(w.Result().Body).Decode(&result))
+				assert.Equal(t, customerrors.ErrPermission.Error(), result["message"])
+			}
+		})
+	}
+}
+
+func TestRolePermissionSeeded(t *testing.T) {
+	seeded := map[string]bool{}
+	for _, p := range constants.Permission {
+		seeded[p.Name] = true
+	}
+	for _, role := range constants.Role {
+		assert.NotEmpty(t, constants.RolePermission[role.ID], role.Name)
+		for _, p := range constants.RolePermission[role.ID] {
+			assert.True(t, seeded[p], p)
+		}
+	}
+}
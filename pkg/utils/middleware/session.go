@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+)
+
+type SessionChecker interface {
+	CheckSession(sessionId string, ctx context.Context) error
+}
+
+// RequireSession reject access token whose session is revoked or expired.
+// It must be used after jwt middleware, token without sid claim is unauthorized
+func RequireSession(checker SessionChecker) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token, ok := c.Get("user").(*jwt.Token)
+			if !ok {
+				return unauthorized(c)
+			}
+			claims, ok := token.Claims.(jwt.MapClaims)
+			if !ok {
+				return unauthorized(c)
+			}
+			sessionId, ok := claims["sid"].(string)
+			if !ok {
+				return unauthorized(c)
+			}
+			if err := checker.CheckSession(sessionId, c.Request().Context()); err != nil {
+				if err == customerrors.ErrTokenRevoked || err == customerrors.ErrInvalidToken {
+					return unauthorized(c)
+				}
+				return c.JSON(http.StatusInternalServerError, echo.Map{
+					"message": err.Error(),
+				})
+			}
+			return next(c)
+		}
+	}
+}
+
+func unauthorized(c echo.Context) error {
+	return c.JSON(http.StatusUnauthorized, echo.Map{
+		"message": customerrors.ErrTokenRevoked.Error(),
+	})
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type sessionCheckerStub struct {
+	err       error
+	sessionId string
+}
+
+func (s *sessionCheckerStub) CheckSession(sessionId string, ctx context.Context) error {
+	s.sessionId = sessionId
+	return s.err
+}
+
+func TestRequireSession(t *testing.T) {
+	testCase := []struct {
+		Name              string
+		User              interface{}
+		CheckErr          error
+		ExpectedSessionId string
+		ExpectedStatus    int
+	}{
+		{
+			Name:              "active session",
+			User:              &jwt.Token{Claims: jwt.MapClaims{"sid": "session"}},
+			ExpectedSessionId: "session",
+			ExpectedStatus:    http.StatusOK,
+		},
+		{
+			Name:              "revoked session",
+			User:              &jwt.Token{Claims: jwt.MapClaims{"sid": "session"}},
+			CheckErr:          customerrors.ErrTokenRevoked,
+			ExpectedSessionId: "session",
+			ExpectedStatus:    http.StatusUnauthorized,
+		},
+		{
+			Name:              "invalid session id",
+			User:              &jwt.Token{Claims: jwt.MapClaims{"sid": "session"}},
+			CheckErr:          customerrors.ErrInvalidToken,
+			ExpectedSessionId: "session",
+			ExpectedStatus:    http.StatusUnauthorized,
+		},
+		{
+			Name:              "check session error",
+			User:              &jwt.Token{Claims: jwt.MapClaims{"sid": "session"}},
+			CheckErr:          errors.New("error"),
+			ExpectedSessionId: "session",
+			ExpectedStatus:    http.StatusInternalServerError,
+		},
+		{
+			Name:           "sid claim missing",
+			User:           &jwt.Token{Claims: jwt.MapClaims{"user_id": "user"}},
+			ExpectedStatus: http.StatusUnauthorized,
+		},
+		{
+			Name:           "no jwt token",
+			User:           nil,
+			ExpectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, v := range testCase {
+		t.Run(v.Name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			ctx := echo.New().NewContext(r, w)
+			if v.User != nil {
+				ctx.Set("user", v.User)
+			}
+			checker := &sessionCheckerStub{err: v.CheckErr}
+			called := false
+			handler := RequireSession(checker)(func(c echo.Context) error {
+				called = true
+				return c.NoContent(http.StatusOK)
+			})
+
+			err := handler(ctx)
+
+			assert.NoError(t, err)
+			assert.Equal(t, v.ExpectedStatus, w.Result().StatusCode)
+			assert.Equal(t, v.ExpectedStatus == http.StatusOK, called)
+			assert.Equal(t, v.ExpectedSessionId, checker.sessionId)
+			if v.ExpectedStatus == http.StatusUnauthorized {
+				result := map[string]interface{}{}
+				assert.NoError(t, json.NewDecoder(w.Result().Body).Decode(&result))
+				assert.Equal(t, customerrors.ErrTokenRevoked.Error(), result["message"])
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package payment
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+)
+
+// midtrans status code for each transaction status
+var fakeStatusCode = map[string]string{
+	"pending":        "201",
+	"capture":        "200",
+	"settlement":     "200",
+	"refund":         "200",
+	"partial_refund": "200",
+	"deny":           "202",
+	"cancel":         "202",
+	"expire":         "202",
+}
+
+type fakeTransaction struct {
+	TransactionID     string `json:"transaction_id"`
+	OrderID           string `json:"order_id"`
+	TransactionStatus string `json:"transaction_status"`
+	TransactionTime   string `json:"transaction_time"`
+	SignatureKey      string `json:"signature_key"`
+	PaymentType       string `json:"payment_type"`
+	GrossAmount       string `json:"gross_amount"`
+	SettlementTime    string `json:"settlement_time"`
+	StatusCode        string `json:"status_code"`
+}
+
+// Fake is in-process payment provider for QA and integration test, it never call midtrans.
+// Redirect url point to this api and paying it send signed notification to /transactions/notification
+type Fake struct {
+	serverKey    string
+	baseURL      string
+	client       *http.Client
+	mu           sync.Mutex
+	transactions map[string]*fakeTransaction
+}
+
+func NewFake(serverKey string, baseURL string) *Fake {
+	return &Fake{
+		serverKey:    serverKey,
+		baseURL:      baseURL,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		transactions: map[string]*fakeTransaction{},
+	}
+}
+
+func (f *Fake) InitRoute(api *echo.Group) {
+	payments := api.Group("/payments/fake")
+	payments.GET("/:order_id", f.GetPayment)
+	payments.POST("/:order_id", f.Pay)
+}
+
+// NewTransaction implements PaymentProvider
+func (f *Fake) NewTransaction(order model.Order, user model.User) (string, error) {
+	orderId := order.ID.String()
+	f.mu.Lock()
+	f.transactions[orderId] = &fakeTransaction{
+		TransactionID:     uuid.New().String(),
+		OrderID:           orderId,
+		TransactionStatus: "pending",
+		TransactionTime:   time.Now().Format("2006-01-02 15:04:05"),
+		PaymentType:       "fake",
+		GrossAmount:       strconv.Itoa(order.GrandTotal) + ".00",
+		StatusCode:        fakeStatusCode["pending"],
+	}
+	f.mu.Unlock()
+	return f.baseURL + "/payments/fake/" + orderId, nil
+}
+
+// CheckTransaction implements PaymentProvider
+func (f *Fake) CheckTransaction(orderId string) (*TransactionStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	transaction, ok := f.transactions[orderId]
+	if !ok {
+		return nil, customerrors.ErrNotFound
+	}
+	return &TransactionStatus{
+		TransactionID:     transaction.TransactionID,
+		OrderID:           transaction.OrderID,
+		TransactionStatus: transaction.TransactionStatus,
+		StatusCode:        transaction.StatusCode,
+		GrossAmount:       transaction.GrossAmount,
+		PaymentType:       transaction.PaymentType,
+	}, nil
+}
+
+// CancelTransaction implements PaymentProvider
+func (f *Fake) CancelTransaction(orderId string) error {
+	return f.Notify(orderId, "cancel")
+}
+
+// RefundTransaction implements PaymentProvider
+func (f *Fake) RefundTransaction(orderId string, refundKey string, amount int, reason string) error {
+	status, err := f.CheckTransaction(orderId)
+	if err != nil {
+		return err
+	}
+	if strconv.Itoa(amount)+".00" == status.GrossAmount {
+		return f.Notify(orderId, "refund")
+	}
+	return f.Notify(orderId, "partial_refund")
+}
+
+// Notify change transaction status and send signed notification like midtrans do
+func (f *Fake) Notify(orderId string, status string) error {
+	statusCode, ok := fakeStatusCode[status]
+	if !ok {
+		return customerrors.ErrBadRequestBody
+	}
+	f.mu.Lock()
+	transaction, ok := f.transactions[orderId]
+	if !ok {
+		f.mu.Unlock()
+		return customerrors.ErrNotFound
+	}
+	transaction.TransactionStatus = status
+	transaction.StatusCode = statusCode
+	if status == "settlement" {
+		transaction.SettlementTime = time.Now().Format("2006-01-02 15:04:05")
+	}
+	transaction.SignatureKey = SignatureKey(transaction.OrderID, transaction.StatusCode, transaction.GrossAmount, f.serverKey)
+	body, err := json.Marshal(transaction)
+	f.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	resp, err := f.client.Post(f.baseURL+"/transactions/notification", echo.MIMEApplicationJSON, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return customerrors.ErrPaymentNotification
+	}
+	return nil
+}
+
+func (f *Fake) GetPayment(c echo.Context) error {
+	status, err := f.CheckTransaction(c.Param("order_id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "get fake payment success",
+		"data":    status,
+	})
+}
+
+func (f *Fake) Pay(c echo.Context) error {
+	var body struct {
+		Status string `json:"status"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"message": customerrors.ErrBadRequestBody.Error(),
+		})
+	}
+	if body.Status == "" {
+		body.Status = "settlement"
+	}
+	err := f.Notify(c.Param("order_id"), body.Status)
+	if err != nil {
+		if err == customerrors.ErrNotFound {
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		if err == customerrors.ErrBadRequestBody {
+			return c.JSON(http.StatusBadRequest, echo.Map{
+				"message": err.Error(),
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"message": err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "fake payment " + body.Status,
+	})
+}
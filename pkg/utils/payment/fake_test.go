@@ -0,0 +1,257 @@
+package payment
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/config"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const serverKey = "SB-Mid-server-test"
+
+func newNotificationServer(t *testing.T, status int, received *[]fakeTransaction) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/transactions/notification", r.URL.Path)
+		var notification fakeTransaction
+		err := json.NewDecoder(r.Body).Decode(&notification)
+		assert.NoError(t, err)
+		*received = append(*received, notification)
+		w.WriteHeader(status)
+	}))
+}
+
+func TestFake_NewTransaction(t *testing.T) {
+	fake := NewFake(serverKey, "http://localhost/api/v1")
+	order := model.Order{
+		ID:         uuid.New(),
+		GrandTotal: 25000,
+	}
+
+	url, err := fake.NewTransaction(order, model.User{})
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost/api/v1/payments/fake/"+order.ID.String(), url)
+
+	status, err := fake.CheckTransaction(order.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", status.TransactionStatus)
+	assert.Equal(t, "25000.00", status.GrossAmount)
+}
+
+func TestFake_Notify(t *testing.T) {
+	order := model.Order{
+		ID:         uuid.New(),
+		GrandTotal: 25000,
+	}
+
+	testCase := []struct {
+		Name           string
+		OrderId        string
+		Status         string
+		ServerStatus   int
+		ExpectedErr    error
+		ExpectedStatus string
+		ExpectedCode   string
+	}{
+		{
+			Name:           "settlement",
+			OrderId:        order.ID.String(),
+			Status:         "settlement",
+			ServerStatus:   http.StatusOK,
+			ExpectedErr:    nil,
+			ExpectedStatus: "settlement",
+			ExpectedCode:   "200",
+		},
+		{
+			Name:           "expire",
+			OrderId:        order.ID.String(),
+			Status:         "expire",
+			ServerStatus:   http.StatusOK,
+			ExpectedErr:    nil,
+			ExpectedStatus: "expire",
+			ExpectedCode:   "202",
+		},
+		{
+			Name:         "notification rejected",
+			OrderId:      order.ID.String(),
+			Status:       "settlement",
+			ServerStatus: http.StatusForbidden,
+			ExpectedErr:  customerrors.ErrPaymentNotification,
+		},
+		{
+			Name:         "unknown status",
+			OrderId:      order.ID.String(),
+			Status:       "paid",
+			ServerStatus: http.StatusOK,
+			ExpectedErr:  customerrors.ErrBadRequestBody,
+		},
+		{
+			Name:         "unknown order",
+			OrderId:      uuid.New().String(),
+			Status:       "settlement",
+			ServerStatus: http.StatusOK,
+			ExpectedErr:  customerrors.ErrNotFound,
+		},
+	}
+	for _, v := range testCase {
+		t.Run(v.Name, func(t *testing.T) {
+			var received []fakeTransaction
+			server := newNotificationServer(t, v.ServerStatus, &received)
+			defer server.Close()
+
+			fake := NewFake(serverKey, server.URL)
+			_, err := fake.NewTransaction(order, model.User{})
+			assert.NoError(t, err)
+
+			err = fake.Notify(v.OrderId, v.Status)
+			assert.Equal(t, v.ExpectedErr, err)
+			if v.ExpectedStatus == "" {
+				return
+			}
+
+			assert.Len(t, received, 1)
+			notification := received[0]
+			assert.Equal(t, v.ExpectedStatus, notification.TransactionStatus)
+			assert.Equal(t, v.ExpectedCode, notification.StatusCode)
+			assert.Equal(t, "25000.00", notification.GrossAmount)
+			assert.Equal(t, SignatureKey(order.ID.String(), v.ExpectedCode, "25000.00", serverKey), notification.SignatureKey)
+		})
+	}
+}
+
+func TestFake_RefundTransaction(t *testing.T) {
+	order := model.Order{
+		ID:         uuid.New(),
+		GrandTotal: 25000,
+	}
+	testCase := []struct {
+		Name           string
+		Amount         int
+		ExpectedStatus string
+	}{
+		{
+			Name:           "full refund",
+			Amount:         25000,
+			ExpectedStatus: "refund",
+		},
+		{
+			Name:           "partial refund",
+			Amount:         12500,
+			ExpectedStatus: "partial_refund",
+		},
+	}
+	for _, v := range testCase {
+		t.Run(v.Name, func(t *testing.T) {
+			var received []fakeTransaction
+			server := newNotificationServer(t, http.StatusOK, &received)
+			defer server.Close()
+
+			fake := NewFake(serverKey, server.URL)
+			_, err := fake.NewTransaction(order, model.User{})
+			assert.NoError(t, err)
+
+			err = fake.RefundTransaction(order.ID.String(), "refund-key", v.Amount, "order expired")
+			assert.NoError(t, err)
+			assert.Len(t, received, 1)
+			assert.Equal(t, v.ExpectedStatus, received[0].TransactionStatus)
+		})
+	}
+}
+
+func TestFake_Pay(t *testing.T) {
+	var received []fakeTransaction
+	server := newNotificationServer(t, http.StatusOK, &received)
+	defer server.Close()
+
+	order := model.Order{
+		ID:         uuid.New(),
+		GrandTotal: 25000,
+	}
+	fake := NewFake(serverKey, server.URL)
+	_, err := fake.NewTransaction(order, model.User{})
+	assert.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	w := httptest.NewRecorder()
+	ctx := echo.New().NewContext(r, w)
+	ctx.SetPath("/payments/fake/:order_id")
+	ctx.SetParamNames("order_id")
+	ctx.SetParamValues(order.ID.String())
+
+	err = fake.Pay(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	assert.Len(t, received, 1)
+	assert.Equal(t, "settlement", received[0].TransactionStatus)
+}
+
+func TestNewPaymentProvider(t *testing.T) {
+	testCase := []struct {
+		Name         string
+		Config       config.Config
+		ExpectedErr  error
+		ExpectedType PaymentProvider
+	}{
+		{
+			Name:         "default midtrans sandbox",
+			Config:       config.Config{},
+			ExpectedErr:  nil,
+			ExpectedType: &Midtrans{},
+		},
+		{
+			Name: "midtrans production",
+			Config: config.Config{
+				PAYMENT_PROVIDER: "midtrans",
+				PAYMENT_ENV:      "production",
+			},
+			ExpectedErr:  nil,
+			ExpectedType: &Midtrans{},
+		},
+		{
+			Name: "fake sandbox",
+			Config: config.Config{
+				PAYMENT_PROVIDER: "fake",
+				PAYMENT_ENV:      "sandbox",
+			},
+			ExpectedErr:  nil,
+			ExpectedType: &Fake{},
+		},
+		{
+			Name: "fake not allowed in production",
+			Config: config.Config{
+				PAYMENT_PROVIDER: "fake",
+				PAYMENT_ENV:      "production",
+			},
+			ExpectedErr: customerrors.ErrPaymentConfig,
+		},
+		{
+			Name: "unknown provider",
+			Config: config.Config{
+				PAYMENT_PROVIDER: "paypal",
+			},
+			ExpectedErr: customerrors.ErrPaymentConfig,
+		},
+		{
+			Name: "unknown environment",
+			Config: config.Config{
+				PAYMENT_ENV: "staging",
+			},
+			ExpectedErr: customerrors.ErrPaymentConfig,
+		},
+	}
+	for _, v := range testCase {
+		t.Run(v.Name, func(t *testing.T) {
+			provider, err := NewPaymentProvider(&v.Config)
+			assert.Equal(t, v.ExpectedErr, err)
+			if v.ExpectedType != nil {
+				assert.IsType(t, v.ExpectedType, provider)
+			}
+		})
+	}
+}
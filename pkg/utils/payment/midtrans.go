@@ -5,18 +5,28 @@ import (
 	"strconv"
 
 	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/coreapi"
 	"github.com/midtrans/midtrans-go/snap"
-	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/config"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
 	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
 )
 
 type Midtrans struct {
+	serverKey string
+	env       midtrans.EnvironmentType
 }
 
-func (*Midtrans) NewTransaction(order model.Order, user model.User) (string, error) {
+func NewMidtrans(serverKey string, env midtrans.EnvironmentType) *Midtrans {
+	return &Midtrans{
+		serverKey: serverKey,
+		env:       env,
+	}
+}
+
+// NewTransaction implements PaymentProvider
+func (m *Midtrans) NewTransaction(order model.Order, user model.User) (string, error) {
 	var s snap.Client
-	s.New(config.Cfg.MIDTRANS_SERVER_KEY, midtrans.Sandbox)
+	s.New(m.serverKey, m.env)
 	shipping := strconv.Itoa(constants.Shipping_cost)
 
 	req := &snap.Request{
@@ -42,3 +52,50 @@ func (*Midtrans) NewTransaction(order model.Order, user model.User) (string, err
 
 	return resp, nil
 }
+
+// CheckTransaction implements PaymentProvider
+func (m *Midtrans) CheckTransaction(orderId string) (*TransactionStatus, error) {
+	var c coreapi.Client
+	c.New(m.serverKey, m.env)
+
+	resp, err := c.CheckTransaction(orderId)
+	if err != nil {
+		return nil, errors.New(err.Message)
+	}
+	return &TransactionStatus{
+		TransactionID:     resp.TransactionID,
+		OrderID:           resp.OrderID,
+		TransactionStatus: resp.TransactionStatus,
+		StatusCode:        resp.StatusCode,
+		GrossAmount:       resp.GrossAmount,
+		PaymentType:       resp.PaymentType,
+	}, nil
+}
+
+// CancelTransaction implements PaymentProvider
+func (m *Midtrans) CancelTransaction(orderId string) error {
+	var c coreapi.Client
+	c.New(m.serverKey, m.env)
+
+	_, err := c.CancelTransaction(orderId)
+	if err != nil {
+		return errors.New(err.Message)
+	}
+	return nil
+}
+
+// RefundTransaction implements PaymentProvider
+func (m *Midtrans) RefundTransaction(orderId string, refundKey string, amount int, reason string) error {
+	var c coreapi.Client
+	c.New(m.serverKey, m.env)
+
+	_, err := c.RefundTransaction(orderId, &coreapi.RefundReq{
+		RefundKey: refundKey,
+		Amount:    int64(amount),
+		Reason:    reason,
+	})
+	if err != nil {
+		return errors.New(err.Message)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package mock
+
+import (
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/payment"
+	"github.com/stretchr/testify/mock"
+)
+
+type PaymentProviderMock struct {
+	mock.Mock
+}
+
+func (m *PaymentProviderMock) NewTransaction(order model.Order, user model.User) (string, error) {
+	args := m.Called()
+
+	return args.String(0), args.Error(1)
+}
+
+func (m *PaymentProviderMock) CheckTransaction(orderId string) (*payment.TransactionStatus, error) {
+	args := m.Called()
+
+	return args.Get(0).(*payment.TransactionStatus), args.Error(1)
+}
+
+func (m *PaymentProviderMock) CancelTransaction(orderId string) error {
+	args := m.Called()
+
+	return args.Error(0)
+}
+
+func (m *PaymentProviderMock) RefundTransaction(orderId string, refundKey string, amount int, reason string) error {
+	args := m.Called()
+
+	return args.Error(0)
+}
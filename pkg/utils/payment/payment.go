@@ -0,0 +1,50 @@
+package payment
+
+import (
+	"github.com/midtrans/midtrans-go"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/config"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+)
+
+type PaymentProvider interface {
+	NewTransaction(order model.Order, user model.User) (string, error)
+	CheckTransaction(orderId string) (*TransactionStatus, error)
+	CancelTransaction(orderId string) error
+	RefundTransaction(orderId string, refundKey string, amount int, reason string) error
+}
+
+type TransactionStatus struct {
+	TransactionID     string
+	OrderID           string
+	TransactionStatus string
+	StatusCode        string
+	GrossAmount       string
+	PaymentType       string
+}
+
+// NewPaymentProvider choose payment provider and environment from config
+func NewPaymentProvider(cfg *config.Config) (PaymentProvider, error) {
+	env := midtrans.Sandbox
+	switch cfg.PAYMENT_ENV {
+	case "", constants.Payment_env_sandbox:
+	case constants.Payment_env_production:
+		env = midtrans.Production
+	default:
+		return nil, customerrors.ErrPaymentConfig
+	}
+
+	switch cfg.PAYMENT_PROVIDER {
+	case "", constants.Payment_provider_midtrans:
+		return NewMidtrans(cfg.MIDTRANS_SERVER_KEY, env), nil
+	case constants.Payment_provider_fake:
+		// fake provider never move real money
+		if env == midtrans.Production {
+			return nil, customerrors.ErrPaymentConfig
+		}
+		return NewFake(cfg.MIDTRANS_SERVER_KEY, cfg.PAYMENT_FAKE_URL), nil
+	default:
+		return nil, customerrors.ErrPaymentConfig
+	}
+}
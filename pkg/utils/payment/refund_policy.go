@@ -0,0 +1,42 @@
+package payment
+
+import (
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/config"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+)
+
+// RefundPolicy is percent of order grand total refunded for each refund reason
+type RefundPolicy struct {
+	ExpiredPercent   int
+	CancelledPercent int
+}
+
+func NewRefundPolicy(cfg *config.Config) (RefundPolicy, error) {
+	policy := RefundPolicy{
+		ExpiredPercent:   cfg.REFUND_EXPIRED_PERCENT,
+		CancelledPercent: cfg.REFUND_CANCELLED_PERCENT,
+	}
+	if !validPercent(policy.ExpiredPercent) || !validPercent(policy.CancelledPercent) {
+		return RefundPolicy{}, customerrors.ErrRefundPolicy
+	}
+	return policy, nil
+}
+
+func validPercent(percent int) bool {
+	return percent >= 0 && percent <= 100
+}
+
+// Amount return refunded amount of grand total, rounded down
+func (p RefundPolicy) Amount(grandTotal int, reason string) int {
+	switch reason {
+	case constants.Refund_reason_expired:
+		return grandTotal * p.ExpiredPercent / 100
+	case constants.Refund_reason_cancelled:
+		return grandTotal * p.CancelledPercent / 100
+	case constants.Refund_reason_late_payment:
+		return grandTotal
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,82 @@
+package payment
+
+import (
+	"testing"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/config"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefundPolicy(t *testing.T) {
+	testCase := []struct {
+		Name           string
+		Config         config.Config
+		ExpectedErr    error
+		Reason         string
+		ExpectedAmount int
+	}{
+		{
+			Name: "expired order refund half",
+			Config: config.Config{
+				REFUND_EXPIRED_PERCENT:   50,
+				REFUND_CANCELLED_PERCENT: 100,
+			},
+			Reason:         constants.Refund_reason_expired,
+			ExpectedAmount: 12502,
+		},
+		{
+			Name: "cancelled order refund full",
+			Config: config.Config{
+				REFUND_EXPIRED_PERCENT:   50,
+				REFUND_CANCELLED_PERCENT: 100,
+			},
+			Reason:         constants.Refund_reason_cancelled,
+			ExpectedAmount: 25005,
+		},
+		{
+			Name: "late payment refund full whatever the policy",
+			Config: config.Config{
+				REFUND_EXPIRED_PERCENT:   0,
+				REFUND_CANCELLED_PERCENT: 0,
+			},
+			Reason:         constants.Refund_reason_late_payment,
+			ExpectedAmount: 25005,
+		},
+		{
+			Name: "unknown reason not refunded",
+			Config: config.Config{
+				REFUND_EXPIRED_PERCENT:   50,
+				REFUND_CANCELLED_PERCENT: 100,
+			},
+			Reason:         "other",
+			ExpectedAmount: 0,
+		},
+		{
+			Name: "percent more than 100",
+			Config: config.Config{
+				REFUND_EXPIRED_PERCENT:   150,
+				REFUND_CANCELLED_PERCENT: 100,
+			},
+			ExpectedErr: customerrors.ErrRefundPolicy,
+		},
+		{
+			Name: "negative percent",
+			Config: config.Config{
+				REFUND_EXPIRED_PERCENT:   50,
+				REFUND_CANCELLED_PERCENT: -1,
+			},
+			ExpectedErr: customerrors.ErrRefundPolicy,
+		},
+	}
+	for _, v := range testCase {
+		t.Run(v.Name, func(t *testing.T) {
+			policy, err := NewRefundPolicy(&v.Config)
+			assert.Equal(t, v.ExpectedErr, err)
+			if err == nil {
+				assert.Equal(t, v.ExpectedAmount, policy.Amount(25005, v.Reason))
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package payment
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+)
+
+// SignatureKey generate midtrans notification signature, SHA512(order_id+status_code+gross_amount+server_key)
+func SignatureKey(orderId, statusCode, grossAmount, serverKey string) string {
+	hash := sha512.Sum512([]byte(orderId + statusCode + grossAmount + serverKey))
+	return hex.EncodeToString(hash[:])
+}
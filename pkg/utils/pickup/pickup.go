@@ -0,0 +1,87 @@
+// Package pickup sign and verify pickup token shown as qr code to customer.
+// Token is HMAC-SHA256 signed with ORDER_SECRET, so only server can create valid token.
+package pickup
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+)
+
+const (
+	nonceLength   = constants.Pickup_nonce_length
+	payloadLength = 16 + 16 + nonceLength + 8
+	tokenLength   = payloadLength + sha256.Size
+)
+
+// Token is data inside pickup code, Nonce is random and saved in order code
+type Token struct {
+	OrderID      uuid.UUID
+	CheckpointID uuid.UUID
+	Nonce        [nonceLength]byte
+	ExpiresAt    time.Time
+}
+
+type Clock interface {
+	Now() time.Time
+}
+
+type Signer struct {
+	secret []byte
+	clock  Clock
+}
+
+func NewSigner(secret string, clock Clock) (*Signer, error) {
+	if secret == "" {
+		return nil, customerrors.ErrOrderSecret
+	}
+	return &Signer{
+		secret: []byte(secret),
+		clock:  clock,
+	}, nil
+}
+
+// Sign return url safe token, token layout is orderId | checkpointId | nonce | expires unix | hmac
+func (s *Signer) Sign(token Token) string {
+	payload := make([]byte, 0, tokenLength)
+	payload = append(payload, token.OrderID[:]...)
+	payload = append(payload, token.CheckpointID[:]...)
+	payload = append(payload, token.Nonce[:]...)
+	payload = binary.BigEndian.AppendUint64(payload, uint64(token.ExpiresAt.Unix()))
+	return base64.RawURLEncoding.EncodeToString(append(payload, s.mac(payload)...))
+}
+
+// Verify check token signature and expiry. It return ErrOrderCode when token is not signed by this server
+func (s *Signer) Verify(code string) (*Token, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(code)
+	if err != nil || len(raw) != tokenLength {
+		return nil, customerrors.ErrOrderCode
+	}
+	payload, signature := raw[:payloadLength], raw[payloadLength:]
+	// hmac.Equal compare in constant time
+	if !hmac.Equal(signature, s.mac(payload)) {
+		return nil, customerrors.ErrOrderCode
+	}
+
+	var token Token
+	copy(token.OrderID[:], payload[0:16])
+	copy(token.CheckpointID[:], payload[16:32])
+	copy(token.Nonce[:], payload[32:32+nonceLength])
+	token.ExpiresAt = time.Unix(int64(binary.BigEndian.Uint64(payload[32+nonceLength:])), 0)
+	if !s.clock.Now().Before(token.ExpiresAt) {
+		return nil, customerrors.ErrCodeExpired
+	}
+	return &token, nil
+}
+
+func (s *Signer) mac(payload []byte) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write(payload)
+	return h.Sum(nil)
+}
@@ -0,0 +1,99 @@
+package pickup
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	clockMock "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/clock/mock"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSigner(t *testing.T) {
+	_, err := NewSigner("", new(clockMock.ClockMock))
+	assert.Equal(t, customerrors.ErrOrderSecret, err)
+
+	signer, err := NewSigner("secret", new(clockMock.ClockMock))
+	assert.NoError(t, err)
+	assert.NotNil(t, signer)
+}
+
+func TestSigner_Verify(t *testing.T) {
+	now := time.Date(2022, 11, 1, 12, 0, 0, 0, time.UTC)
+	token := Token{
+		OrderID:      uuid.New(),
+		CheckpointID: uuid.New(),
+		Nonce:        [nonceLength]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		ExpiresAt:    now.Add(time.Hour),
+	}
+	clock := new(clockMock.ClockMock)
+	signer, _ := NewSigner("secret", clock)
+	otherSigner, _ := NewSigner("other-secret", clock)
+	code := signer.Sign(token)
+
+	tampered, _ := base64.RawURLEncoding.DecodeString(code)
+	// move pickup to other checkpoint
+	tampered[16] ^= 1
+
+	testCase := []struct {
+		Name        string
+		Code        string
+		Now         time.Time
+		ExpectedErr error
+	}{
+		{
+			Name:        "valid token",
+			Code:        code,
+			Now:         now,
+			ExpectedErr: nil,
+		},
+		{
+			Name:        "expired token",
+			Code:        code,
+			Now:         now.Add(time.Hour),
+			ExpectedErr: customerrors.ErrCodeExpired,
+		},
+		{
+			Name:        "tampered token",
+			Code:        base64.RawURLEncoding.EncodeToString(tampered),
+			Now:         now,
+			ExpectedErr: customerrors.ErrOrderCode,
+		},
+		{
+			Name:        "signed with other secret",
+			Code:        otherSigner.Sign(token),
+			Now:         now,
+			ExpectedErr: customerrors.ErrOrderCode,
+		},
+		{
+			Name:        "old base64 code",
+			Code:        base64.StdEncoding.EncodeToString([]byte(token.OrderID.String() + " 123456 " + token.CheckpointID.String())),
+			Now:         now,
+			ExpectedErr: customerrors.ErrOrderCode,
+		},
+		{
+			Name:        "not base64",
+			Code:        "!!!",
+			Now:         now,
+			ExpectedErr: customerrors.ErrOrderCode,
+		},
+	}
+	for _, v := range testCase {
+		t.Run(v.Name, func(t *testing.T) {
+			clock.ExpectedCalls = nil
+			clock.On("Now").Return(v.Now)
+
+			result, err := signer.Verify(v.Code)
+
+			assert.Equal(t, v.ExpectedErr, err)
+			if v.ExpectedErr == nil {
+				assert.Equal(t, token.OrderID, result.OrderID)
+				assert.Equal(t, token.CheckpointID, result.CheckpointID)
+				assert.Equal(t, token.Nonce, result.Nonce)
+				assert.True(t, token.ExpiresAt.Equal(result.ExpiresAt))
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+// Package quantity handle fractional quantity of item. Quantity is kept as decimal with
+// 3 digit precision in database, so float error is removed by rounding to that precision.
+package quantity
+
+import "math"
+
+// precision of quantity, same as decimal(12,3) column
+const precision = 1000
+
+// Round quantity to 3 decimal digit
+func Round(qty float64) float64 {
+	return math.Round(qty*precision) / precision
+}
+
+// IsMultiple check qty is multiple of unit step, zero step accept every quantity
+func IsMultiple(qty float64, step float64) bool {
+	if step <= 0 {
+		return true
+	}
+	n := math.Round(qty * precision)
+	s := math.Round(step * precision)
+	return math.Mod(n, s) == 0
+}
+
+// Total is price of qty, rounded to nearest rupiah
+func Total(qty float64, price int) int {
+	return int(math.Round(Round(qty) * float64(price)))
+}
@@ -0,0 +1,41 @@
+package quantity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRound(t *testing.T) {
+	assert.Equal(t, 0.3, Round(0.1+0.2))
+	assert.Equal(t, 1.235, Round(1.2349))
+	assert.Equal(t, 2.0, Round(2))
+}
+
+func TestIsMultiple(t *testing.T) {
+	testCase := []struct {
+		Name     string
+		Qty      float64
+		Step     float64
+		Expected bool
+	}{
+		{Name: "quarter kg", Qty: 0.25, Step: 0.25, Expected: true},
+		{Name: "one and half kg", Qty: 1.5, Step: 0.25, Expected: true},
+		{Name: "float error", Qty: 0.1 + 0.2, Step: 0.1, Expected: true},
+		{Name: "not multiple", Qty: 0.3, Step: 0.25, Expected: false},
+		{Name: "half piece", Qty: 0.5, Step: 1, Expected: false},
+		{Name: "whole piece", Qty: 3, Step: 1, Expected: true},
+		{Name: "no step", Qty: 0.123, Step: 0, Expected: true},
+	}
+	for _, v := range testCase {
+		t.Run(v.Name, func(t *testing.T) {
+			assert.Equal(t, v.Expected, IsMultiple(v.Qty, v.Step))
+		})
+	}
+}
+
+func TestTotal(t *testing.T) {
+	assert.Equal(t, 3000, Total(0.25, 12000))
+	assert.Equal(t, 4167, Total(0.333, 12513))
+	assert.Equal(t, 10000, Total(2, 5000))
+}
@@ -0,0 +1,238 @@
+// Package query parse page, sort and filter of list endpoint from query param.
+// Each list declare a Schema, only sort and filter listed in schema reach the database.
+package query
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Type of filter value, query param is parsed to this type before used in query
+type Type int
+
+const (
+	String Type = iota
+	Uint
+	UUID
+	Time
+)
+
+// Operator compare column with filter value
+type Operator int
+
+const (
+	Equal Operator = iota
+	Contain
+	From
+	Until
+)
+
+const dateLayout = "2006-01-02"
+
+// Filter is one query param the list can be filtered with
+type Filter struct {
+	Column   string
+	Type     Type
+	Operator Operator
+}
+
+// Schema list sort and filter allowed on one list.
+// Sort map sort param to column, DefaultSort is sort param used when client dont send one,
+// Key is unique column added as last order so row dont move between page
+type Schema struct {
+	Sort        map[string]string
+	DefaultSort string
+	Key         string
+	Filter      map[string]Filter
+}
+
+type condition struct {
+	column   string
+	operator Operator
+	value    interface{}
+}
+
+// Options is parsed page, sort and filter. Zero Options return every row without order
+type Options struct {
+	Page       int
+	Limit      int
+	Sort       string
+	Desc       bool
+	Key        string
+	conditions []condition
+}
+
+// Page is pagination metadata sent with the list
+type Page struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int64 `json:"total_pages"`
+}
+
+// Parse read page, limit, sort and filter param. Sort is "field" or "-field" for descending
+func Parse(params url.Values, schema Schema) (Options, error) {
+	opts := Options{
+		Page:  1,
+		Limit: constants.Default_page_limit,
+		Key:   schema.Key,
+	}
+	var err error
+	if page := params.Get("page"); page != "" {
+		opts.Page, err = strconv.Atoi(page)
+		if err != nil || opts.Page < 1 {
+			return Options{}, customerrors.ErrInvalidPage
+		}
+	}
+	if limit := params.Get("limit"); limit != "" {
+		opts.Limit, err = strconv.Atoi(limit)
+		if err != nil || opts.Limit < 1 || opts.Limit > constants.Max_page_limit {
+			return Options{}, customerrors.ErrInvalidPage
+		}
+	}
+
+	sortBy := params.Get("sort")
+	if sortBy == "" {
+		sortBy = schema.DefaultSort
+	}
+	if sortBy != "" {
+		opts.Desc = strings.HasPrefix(sortBy, "-")
+		column, ok := schema.Sort[strings.TrimPrefix(sortBy, "-")]
+		if !ok {
+			return Options{}, customerrors.ErrInvalidSort
+		}
+		opts.Sort = column
+	}
+
+	// filter is applied in name order so the same request always build the same sql
+	names := make([]string, 0, len(schema.Filter))
+	for name := range schema.Filter {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		filter := schema.Filter[name]
+		param := params.Get(name)
+		if param == "" {
+			continue
+		}
+		value, err := parseValue(param, filter)
+		if err != nil {
+			return Options{}, customerrors.ErrInvalidFilter
+		}
+		opts.conditions = append(opts.conditions, condition{
+			column:   filter.Column,
+			operator: filter.Operator,
+			value:    value,
+		})
+	}
+	return opts, nil
+}
+
+func parseValue(param string, filter Filter) (interface{}, error) {
+	switch filter.Type {
+	case Uint:
+		return strconv.ParseUint(param, 10, 64)
+	case UUID:
+		return uuid.Parse(param)
+	case Time:
+		if value, err := time.Parse(time.RFC3339, param); err == nil {
+			return value, nil
+		}
+		value, err := time.Parse(dateLayout, param)
+		if err != nil {
+			return nil, err
+		}
+		// date only until include the whole day
+		if filter.Operator == Until {
+			value = value.Add(24*time.Hour - time.Nanosecond)
+		}
+		return value, nil
+	default:
+		return param, nil
+	}
+}
+
+// Where apply only the filter, used to count every row of the list
+func (o Options) Where(db *gorm.DB) *gorm.DB {
+	for _, c := range o.conditions {
+		column := clause.Column{Name: c.column}
+		switch c.operator {
+		case Contain:
+			db = db.Where(clause.Expr{SQL: "? LIKE ? ESCAPE '!'", Vars: []interface{}{column, "%" + escapeLike(c.value.(string)) + "%"}})
+		case From:
+			db = db.Where(clause.Gte{Column: column, Value: c.value})
+		case Until:
+			db = db.Where(clause.Lte{Column: column, Value: c.value})
+		default:
+			db = db.Where(clause.Eq{Column: column, Value: c.value})
+		}
+	}
+	return db
+}
+
+// Scope apply filter, sort and page
+func (o Options) Scope(db *gorm.DB) *gorm.DB {
+	db = o.Where(db)
+	return o.order(db)
+}
+
+func (o Options) order(db *gorm.DB) *gorm.DB {
+	if o.Sort != "" {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: o.Sort}, Desc: o.Desc})
+	}
+	if o.Key != "" && o.Key != o.Sort {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: o.Key}})
+	}
+	if o.Limit > 0 {
+		db = db.Limit(o.Limit)
+	}
+	if o.Limit > 0 && o.Page > 1 {
+		db = db.Offset((o.Page - 1) * o.Limit)
+	}
+	return db
+}
+
+// Find count every row of tx matching the filter, then fill dest with one page of it.
+// tx must already has the model and condition of the list, like owner of the row
+func Find(tx *gorm.DB, opts Options, dest interface{}) (int64, error) {
+	tx = tx.Scopes(opts.Where).Session(&gorm.Session{})
+	var total int64
+	err := tx.Count(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	err = tx.Scopes(opts.order).Find(dest).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// NewPage create pagination metadata from options and total row
+func NewPage(opts Options, total int64) *Page {
+	page := &Page{
+		Page:  opts.Page,
+		Limit: opts.Limit,
+		Total: total,
+	}
+	if opts.Limit > 0 {
+		page.TotalPages = (total + int64(opts.Limit) - 1) / int64(opts.Limit)
+	}
+	return page
+}
+
+// escapeLike make % and _ in filter match literally. Escape char is ! because
+// backslash is escaped differently in mysql and sqlite string literal
+func escapeLike(value string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(value)
+}
@@ -0,0 +1,172 @@
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/database/testdb"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var itemSchema = Schema{
+	Sort: map[string]string{
+		"name":  "items.name",
+		"price": "items.price",
+	},
+	DefaultSort: "name",
+	Key:         "items.id",
+	Filter: map[string]Filter{
+		"name":        {Column: "items.name", Type: String, Operator: Contain},
+		"category_id": {Column: "items.category_id", Type: Uint, Operator: Equal},
+		"from":        {Column: "items.created_at", Type: Time, Operator: From},
+		"to":          {Column: "items.created_at", Type: Time, Operator: Until},
+		"user_id":     {Column: "items.user_id", Type: UUID, Operator: Equal},
+	},
+}
+
+func TestParse(t *testing.T) {
+	testCase := []struct {
+		Name        string
+		Query       string
+		ExpectedErr error
+		Expected    Options
+	}{
+		{
+			Name:  "default",
+			Query: "",
+			Expected: Options{
+				Page:  1,
+				Limit: constants.Default_page_limit,
+				Sort:  "items.name",
+				Key:   "items.id",
+			},
+		},
+		{
+			Name:  "page and descending sort",
+			Query: "page=3&limit=5&sort=-price",
+			Expected: Options{
+				Page:  3,
+				Limit: 5,
+				Sort:  "items.price",
+				Desc:  true,
+				Key:   "items.id",
+			},
+		},
+		{
+			Name:        "page not number",
+			Query:       "page=a",
+			ExpectedErr: customerrors.ErrInvalidPage,
+		},
+		{
+			Name:        "page zero",
+			Query:       "page=0",
+			ExpectedErr: customerrors.ErrInvalidPage,
+		},
+		{
+			Name:        "limit too big",
+			Query:       fmt.Sprintf("limit=%d", constants.Max_page_limit+1),
+			ExpectedErr: customerrors.ErrInvalidPage,
+		},
+		{
+			Name:        "sort not allowed",
+			Query:       "sort=password",
+			ExpectedErr: customerrors.ErrInvalidSort,
+		},
+		{
+			Name:        "invalid uint filter",
+			Query:       "category_id=-1",
+			ExpectedErr: customerrors.ErrInvalidFilter,
+		},
+		{
+			Name:        "invalid uuid filter",
+			Query:       "user_id=1",
+			ExpectedErr: customerrors.ErrInvalidFilter,
+		},
+		{
+			Name:        "invalid time filter",
+			Query:       "from=yesterday",
+			ExpectedErr: customerrors.ErrInvalidFilter,
+		},
+	}
+
+	for _, v := range testCase {
+		t.Run(v.Name, func(t *testing.T) {
+			params, err := url.ParseQuery(v.Query)
+			assert.NoError(t, err)
+			opts, err := Parse(params, itemSchema)
+			assert.Equal(t, v.ExpectedErr, err)
+			if v.ExpectedErr == nil {
+				assert.Equal(t, v.Expected, opts)
+			}
+		})
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	userId := uuid.New()
+	params := url.Values{
+		"category_id": {"2"},
+		"from":        {"2022-11-01T10:00:00Z"},
+		"to":          {"2022-11-02"},
+		"user_id":     {userId.String()},
+		"unknown":     {"ignored"},
+	}
+	opts, err := Parse(params, itemSchema)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []condition{
+		{column: "items.category_id", operator: Equal, value: uint64(2)},
+		{column: "items.created_at", operator: From, value: time.Date(2022, 11, 1, 10, 0, 0, 0, time.UTC)},
+		// date only until include the whole day
+		{column: "items.created_at", operator: Until, value: time.Date(2022, 11, 2, 23, 59, 59, 999999999, time.UTC)},
+		{column: "items.user_id", operator: Equal, value: userId},
+	}, opts.conditions)
+}
+
+func TestFind(t *testing.T) {
+	db := testdb.New(t)
+	categories := []model.Category{{Name: "fruit"}, {Name: "vegetable"}}
+	assert.NoError(t, db.Create(&categories).Error)
+	for i := 1; i <= 7; i++ {
+		assert.NoError(t, db.Create(&model.Item{
+			Name:       fmt.Sprintf("item_%d", i),
+			CategoryID: categories[i%2].ID,
+			Price:      i * 100,
+		}).Error)
+	}
+	assert.NoError(t, db.Create(&model.Item{Name: "100% fresh", CategoryID: categories[0].ID, Price: 50}).Error)
+
+	find := func(query string) ([]model.Item, *Page) {
+		params, err := url.ParseQuery(query)
+		assert.NoError(t, err)
+		opts, err := Parse(params, itemSchema)
+		assert.NoError(t, err)
+		var items []model.Item
+		total, err := Find(db.Model(&model.Item{}).Preload("Category"), opts, &items)
+		assert.NoError(t, err)
+		return items, NewPage(opts, total)
+	}
+
+	items, page := find("limit=3&page=2&sort=-price")
+	assert.Equal(t, &Page{Page: 2, Limit: 3, Total: 8, TotalPages: 3}, page)
+	assert.Equal(t, []string{"item_4", "item_3", "item_2"}, []string{items[0].Name, items[1].Name, items[2].Name})
+	assert.Equal(t, categories[0].Name, items[0].Category.Name)
+
+	items, page = find(fmt.Sprintf("category_id=%d", categories[1].ID))
+	assert.Equal(t, int64(4), page.Total)
+	assert.Len(t, items, 4)
+
+	// % is matched literally
+	items, page = find("name=%25")
+	assert.Equal(t, int64(1), page.Total)
+	assert.Equal(t, "100% fresh", items[0].Name)
+
+	items, page = find("name=item_1")
+	assert.Equal(t, int64(1), page.Total)
+	assert.Equal(t, "item_1", items[0].Name)
+}
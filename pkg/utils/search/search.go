@@ -0,0 +1,76 @@
+// Package search turn product text into trigram used by item search index.
+// Trigram make search tolerate typo, and word is replaced by its canonical synonym
+// before split, so "cabe" and "cabai" produce the same trigram.
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// synonyms map local or informal word to one canonical word
+var synonyms = map[string]string{
+	"cabe":    "cabai",
+	"lombok":  "cabai",
+	"tomato":  "tomat",
+	"terong":  "terung",
+	"timun":   "mentimun",
+	"ketimun": "mentimun",
+	"kol":     "kubis",
+	"kobis":   "kubis",
+	"caisim":  "sawi",
+	"bayem":   "bayam",
+	"ketela":  "singkong",
+	"kaspe":   "singkong",
+	"kates":   "pepaya",
+	"sereh":   "serai",
+	"laos":    "lengkuas",
+	"kunir":   "kunyit",
+	"telor":   "telur",
+	"pete":    "petai",
+}
+
+// Words split text into lower case canonical word, each word only once
+func Words(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	seen := make(map[string]bool, len(fields))
+	words := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if canonical, ok := synonyms[field]; ok {
+			field = canonical
+		}
+		if seen[field] {
+			continue
+		}
+		seen[field] = true
+		words = append(words, field)
+	}
+	return words
+}
+
+// Grams split every word of text into trigram. Word is padded with $ so
+// start and end of word weigh more than the middle, each trigram only once
+func Grams(text string) []string {
+	seen := map[string]bool{}
+	var grams []string
+	for _, word := range Words(text) {
+		runes := []rune("$" + word + "$")
+		for i := 0; i+3 <= len(runes); i++ {
+			gram := string(runes[i : i+3])
+			if seen[gram] {
+				continue
+			}
+			seen[gram] = true
+			grams = append(grams, gram)
+		}
+	}
+	return grams
+}
+
+// MinMatch is least trigram an item must share with searched text, one third
+// of them keep one typo per word found while dropping unrelated item
+func MinMatch(grams []string) int {
+	return (len(grams) + 2) / 3
+}
@@ -0,0 +1,73 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWords(t *testing.T) {
+	testCase := []struct {
+		Name     string
+		Text     string
+		Expected []string
+	}{
+		{
+			Name:     "lower case and split on symbol",
+			Text:     "Bawang Merah, 1kg!",
+			Expected: []string{"bawang", "merah", "1kg"},
+		},
+		{
+			Name:     "synonym replaced by canonical word",
+			Text:     "cabe lombok cabai",
+			Expected: []string{"cabai"},
+		},
+		{
+			Name:     "no word",
+			Text:     " !? ",
+			Expected: []string{},
+		},
+	}
+	for _, v := range testCase {
+		t.Run(v.Name, func(t *testing.T) {
+			assert.Equal(t, v.Expected, Words(v.Text))
+		})
+	}
+}
+
+func TestGrams(t *testing.T) {
+	assert.Equal(t, []string{"$ca", "cab", "aba", "bai", "ai$"}, Grams("cabai"))
+	assert.Equal(t, Grams("cabai"), Grams("Cabe"))
+	assert.Equal(t, []string{"$a$"}, Grams("a"))
+	assert.Nil(t, Grams(""))
+}
+
+func TestTypoShareMinMatch(t *testing.T) {
+	testCase := []struct {
+		Name   string
+		Search string
+		Text   string
+		Match  bool
+	}{
+		{Name: "missing letter", Search: "cabi", Text: "cabai merah", Match: true},
+		{Name: "swapped letter", Search: "tomta", Text: "tomat", Match: true},
+		{Name: "synonym", Search: "terong ungu", Text: "terung", Match: true},
+		{Name: "unrelated", Search: "kentang", Text: "tomat", Match: false},
+	}
+	for _, v := range testCase {
+		t.Run(v.Name, func(t *testing.T) {
+			searched := Grams(v.Search)
+			text := map[string]bool{}
+			for _, gram := range Grams(v.Text) {
+				text[gram] = true
+			}
+			shared := 0
+			for _, gram := range searched {
+				if text[gram] {
+					shared++
+				}
+			}
+			assert.Equal(t, v.Match, shared >= MinMatch(searched))
+		})
+	}
+}
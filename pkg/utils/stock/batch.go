@@ -0,0 +1,123 @@
+package stock
+
+import (
+	"time"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/quantity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// moveBatches apply batches of the move, stock in create new batch or put back to its batch.
+// Stock out without batches is allocated first expired first out
+func moveBatches(tx *gorm.DB, move *model.StockMovement, variantId uint) error {
+	if move.Qty < 0 && len(move.Batches) == 0 {
+		return allocate(tx, move, variantId)
+	}
+	for i := range move.Batches {
+		batch := &move.Batches[i]
+		batch.Qty = quantity.Round(batch.Qty)
+		if batch.BatchID == 0 { // new batch is received in place of the move
+			batch.Batch.ID = 0
+			batch.Batch.ItemID, batch.Batch.VariantID, batch.Batch.CheckpointID = move.ItemID, variantId, move.CheckpointID
+			batch.Batch.Qty = batch.Qty
+			if err := tx.Create(batch.Batch).Error; err != nil {
+				return err
+			}
+			batch.BatchID = batch.Batch.ID
+			continue
+		}
+		res := tx.Model(&model.StockBatch{}).Where("id = ?", batch.BatchID)
+		if batch.Qty < 0 {
+			res = res.Where("qty >= ?", -batch.Qty)
+		}
+		res = res.UpdateColumn("qty", gorm.Expr("qty + ?", batch.Qty))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			if batch.Qty < 0 {
+				return customerrors.ErrStockNotEnough
+			}
+			return customerrors.ErrNotFound
+		}
+	}
+	return nil
+}
+
+// allocate take stock out of batches in place of the move, batch that expire first is taken
+// first. Stock without batch never expire, so it is taken only after every batch. Expired
+// batch is never sold, it wait to be written off
+func allocate(tx *gorm.DB, move *model.StockMovement, variantId uint) error {
+	var batches []model.StockBatch
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("item_id = ? AND variant_id = ? AND qty > 0", move.ItemID, variantId)
+	query = wherePlace(query, move)
+	if err := query.Order("expired_at, id").Find(&batches).Error; err != nil {
+		return err
+	}
+	if len(batches) == 0 {
+		return nil
+	}
+	left, err := placeQty(tx, move, variantId)
+	if err != nil {
+		return err
+	}
+	// stock of the place before the move, minus what is kept in batches
+	unbatched := left - move.Qty
+	for _, batch := range batches {
+		unbatched -= batch.Qty
+	}
+	if unbatched < 0 {
+		unbatched = 0
+	}
+	need := quantity.Round(-move.Qty)
+	now := time.Now()
+	for i := range batches {
+		if need <= 0 {
+			break
+		}
+		if move.Reason == constants.Stock_reason_sale && !batches[i].ExpiredAt.After(now) {
+			continue
+		}
+		take := batches[i].Qty
+		if take > need {
+			take = need
+		}
+		err := tx.Model(&model.StockBatch{}).Where("id = ?", batches[i].ID).UpdateColumn("qty", gorm.Expr("qty - ?", take)).Error
+		if err != nil {
+			return err
+		}
+		move.Batches = append(move.Batches, model.StockBatchMove{BatchID: batches[i].ID, Qty: -take, Batch: &batches[i]})
+		need = quantity.Round(need - take)
+	}
+	if need > quantity.Round(unbatched) {
+		return customerrors.ErrStockNotEnough
+	}
+	return nil
+}
+
+// placeQty read stock left in place of the move, its checkpoint or unallocated stock
+func placeQty(tx *gorm.DB, move *model.StockMovement, variantId uint) (float64, error) {
+	var qty float64
+	if move.CheckpointID != nil {
+		err := tx.Model(&model.CheckpointStock{}).Select("qty").
+			Where("checkpoint_id = ? AND item_id = ? AND variant_id = ?", *move.CheckpointID, move.ItemID, variantId).
+			Scan(&qty).Error
+		return qty, err
+	}
+	err := tx.Model(&model.CheckpointStock{}).Select("COALESCE(SUM(qty), 0)").
+		Where("item_id = ? AND variant_id = ?", move.ItemID, variantId).
+		Scan(&qty).Error
+	return move.Balance - qty, err
+}
+
+// wherePlace filter batches kept in place of the move
+func wherePlace(query *gorm.DB, move *model.StockMovement) *gorm.DB {
+	if move.CheckpointID == nil {
+		return query.Where("checkpoint_id IS NULL")
+	}
+	return query.Where("checkpoint_id = ?", *move.CheckpointID)
+}
@@ -0,0 +1,166 @@
+// Package stock keep item and variant stock in line with stock ledger. Stock column is only
+// changed through Move, inside the transaction of the caller, so every change has its ledger entry.
+package stock
+
+import (
+	"math"
+	"time"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/model"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/quantity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Move apply signed qty of move to stock of item or variant and append move to ledger.
+// Move with checkpoint change stock kept in the checkpoint too, move without it change
+// unallocated stock. Stock out fail with ErrStockNotEnough when stock of the checkpoint or
+// unallocated stock is less than qty, and only take from active row. Stock in reach
+// archived row too, like stock of cancelled order. Batches of the move are applied as
+// they are, stock out without batches is allocated by Allocate
+func Move(tx *gorm.DB, move *model.StockMovement) error {
+	move.Qty = quantity.Round(move.Qty)
+	var stock interface{} = &model.Item{}
+	id, variantId := move.ItemID, uint(0)
+	if move.VariantID != nil {
+		stock, id, variantId = &model.ItemVariant{}, *move.VariantID, *move.VariantID
+	}
+	if move.CheckpointID != nil {
+		if err := moveCheckpoint(tx, move, variantId); err != nil {
+			return err
+		}
+	}
+	var res *gorm.DB
+	if move.Qty < 0 {
+		res = tx.Model(stock).Where("id = ? AND qty >= ?", id, -move.Qty)
+		if move.CheckpointID == nil { // stock kept in checkpoint is not unallocated
+			res = res.Where("qty - (SELECT COALESCE(SUM(qty), 0) FROM checkpoint_stocks WHERE item_id = ? AND variant_id = ?) >= ?", move.ItemID, variantId, -move.Qty)
+		}
+		res = res.UpdateColumn("qty", gorm.Expr("qty - ?", -move.Qty))
+	} else {
+		res = tx.Unscoped().Model(stock).Where("id = ?", id).UpdateColumn("qty", gorm.Expr("qty + ?", move.Qty))
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		if move.Qty < 0 {
+			return customerrors.ErrStockNotEnough
+		}
+		return customerrors.ErrNotFound
+	}
+	var balance float64
+	if err := tx.Unscoped().Model(stock).Select("qty").Where("id = ?", id).Scan(&balance).Error; err != nil {
+		return err
+	}
+	move.Balance = quantity.Round(balance)
+	if err := moveBatches(tx, move, variantId); err != nil {
+		return err
+	}
+	return tx.Create(move).Error
+}
+
+// moveCheckpoint apply signed qty of move to stock of its checkpoint, row of the checkpoint
+// is created on first stock in
+func moveCheckpoint(tx *gorm.DB, move *model.StockMovement, variantId uint) error {
+	if move.Qty < 0 {
+		res := tx.Model(&model.CheckpointStock{}).
+			Where("checkpoint_id = ? AND item_id = ? AND variant_id = ? AND qty >= ?", *move.CheckpointID, move.ItemID, variantId, -move.Qty).
+			UpdateColumn("qty", gorm.Expr("qty - ?", -move.Qty))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return customerrors.ErrStockNotEnough
+		}
+		return nil
+	}
+	return tx.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "checkpoint_id"}, {Name: "item_id"}, {Name: "variant_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"qty": gorm.Expr("qty + ?", move.Qty)}),
+	}).Create(&model.CheckpointStock{
+		CheckpointID: *move.CheckpointID,
+		ItemID:       move.ItemID,
+		VariantID:    variantId,
+		Qty:          move.Qty,
+	}).Error
+}
+
+// Take move stock out of the checkpoint of move, qty the checkpoint dont keep is taken from
+// unallocated stock, so stock saved before it was kept in checkpoint can still be sold from any
+// checkpoint. Each part is saved as its own move and fail with ErrStockNotEnough like Move
+func Take(tx *gorm.DB, move *model.StockMovement) error {
+	// try the checkpoint first, failed update still lock the checkpoint row before it is read.
+	// Stock is already changed when batch of the checkpoint is not enough, so it is tried in
+	// savepoint that is rolled back on failure
+	whole := *move
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		return Move(tx, &whole)
+	})
+	if err != customerrors.ErrStockNotEnough {
+		*move = whole
+		return err
+	}
+	variantId := uint(0)
+	if move.VariantID != nil {
+		variantId = *move.VariantID
+	}
+	var kept, expired float64
+	err = tx.Model(&model.CheckpointStock{}).Select("COALESCE(SUM(qty), 0)").
+		Where("checkpoint_id = ? AND item_id = ? AND variant_id = ?", *move.CheckpointID, move.ItemID, variantId).
+		Scan(&kept).Error
+	if err != nil {
+		return err
+	}
+	// expired batch of the checkpoint is never sold
+	err = tx.Model(&model.StockBatch{}).Select("COALESCE(SUM(qty), 0)").
+		Where("checkpoint_id = ? AND item_id = ? AND variant_id = ? AND qty > 0 AND expired_at <= ?", *move.CheckpointID, move.ItemID, variantId, time.Now()).
+		Scan(&expired).Error
+	if err != nil {
+		return err
+	}
+	kept -= expired
+	need := quantity.Round(-move.Qty)
+	fromCheckpoint := quantity.Round(math.Min(math.Max(kept, 0), need))
+	if fromCheckpoint > 0 {
+		part := *move
+		part.Qty = -fromCheckpoint
+		if err := Move(tx, &part); err != nil {
+			return err
+		}
+	}
+	if rest := quantity.Round(need - fromCheckpoint); rest > 0 {
+		part := *move
+		part.Qty, part.CheckpointID = -rest, nil
+		if err := Move(tx, &part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Uncounted is count of item or variant whose stock is kept as it is
+const Uncounted = -1
+
+// Count set stock of item or variant to counted qty, difference with current stock is saved
+// as the move of unallocated stock. Nothing is saved when stock already equal count or count is Uncounted
+func Count(tx *gorm.DB, move *model.StockMovement, count float64) error {
+	if count == Uncounted {
+		return nil
+	}
+	var stock interface{} = &model.Item{}
+	id := move.ItemID
+	if move.VariantID != nil {
+		stock, id = &model.ItemVariant{}, *move.VariantID
+	}
+	var balance float64
+	if err := tx.Unscoped().Model(stock).Select("qty").Where("id = ?", id).Scan(&balance).Error; err != nil {
+		return err
+	}
+	move.Qty = quantity.Round(count - balance)
+	if move.Qty == 0 {
+		return nil
+	}
+	return Move(tx, move)
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+)
+
+// Local keep file in disk directory, for development and single server deployment
+type Local struct {
+	dir       string
+	publicURL string
+}
+
+func NewLocal(dir string, publicURL string) *Local {
+	return &Local{
+		dir:       dir,
+		publicURL: publicURL,
+	}
+}
+
+// InitRoute serve stored file, file is public so it is not behind jwt
+func (l *Local) InitRoute(e *echo.Echo) {
+	e.Static(constants.Storage_local_route, l.dir)
+}
+
+// Put implements Storage
+func (l *Local) Put(key string, body []byte, contentType string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, body, 0644)
+}
+
+// Delete implements Storage
+func (l *Local) Delete(key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// URL implements Storage
+func (l *Local) URL(key string) string {
+	return joinURL(l.publicURL, key)
+}
+
+// path keep file inside storage dir, key with .. cant write other file
+func (l *Local) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", customerrors.ErrStorageKey
+	}
+	return filepath.Join(l.dir, clean), nil
+}
@@ -0,0 +1,23 @@
+package mock
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+type StorageMock struct {
+	mock.Mock
+}
+
+func (m *StorageMock) Put(key string, body []byte, contentType string) error {
+	args := m.Called(key, contentType)
+	return args.Error(0)
+}
+
+func (m *StorageMock) Delete(key string) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *StorageMock) URL(key string) string {
+	return "https://cdn.kangsayur.com/" + key
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+)
+
+const s3Timeout = 30 * time.Second
+
+// S3 keep file in S3 compatible object storage, like AWS S3 or MinIO.
+// Request use path style url, bucket must allow public read so client can open the URL
+type S3 struct {
+	client    *minio.Client
+	bucket    string
+	publicURL string
+}
+
+func NewS3(endpoint string, region string, bucket string, accessKey string, secretKey string, publicURL string) (*S3, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, customerrors.ErrStorageConfig
+	}
+	client, err := minio.New(u.Host, &minio.Options{
+		Creds:        credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure:       u.Scheme == "https",
+		Region:       region,
+		BucketLookup: minio.BucketLookupPath,
+	})
+	if err != nil {
+		return nil, customerrors.ErrStorageConfig
+	}
+	return &S3{
+		client:    client,
+		bucket:    bucket,
+		publicURL: publicURL,
+	}, nil
+}
+
+// Put implements Storage
+func (s *S3) Put(key string, body []byte, contentType string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s3Timeout)
+	defer cancel()
+	_, err := s.client.PutObject(ctx, s.bucket, key, bytes.NewReader(body), int64(len(body)), minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", customerrors.ErrStorage, err)
+	}
+	return nil
+}
+
+// Delete implements Storage
+func (s *S3) Delete(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s3Timeout)
+	defer cancel()
+	if err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("%w: %v", customerrors.ErrStorage, err)
+	}
+	return nil
+}
+
+// URL implements Storage
+func (s *S3) URL(key string) string {
+	return joinURL(s.publicURL, key)
+}
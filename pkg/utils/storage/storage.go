@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"strings"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/config"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+)
+
+// Storage keep uploaded file by key, file is public and read by client from URL
+type Storage interface {
+	Put(key string, body []byte, contentType string) error
+	Delete(key string) error
+	URL(key string) string
+}
+
+// NewStorage choose storage driver from config, local driver keep file in disk and serve it from this app
+func NewStorage(cfg *config.Config) (Storage, error) {
+	switch cfg.STORAGE_DRIVER {
+	case "", constants.Storage_driver_local:
+		dir := cfg.STORAGE_LOCAL_PATH
+		if dir == "" {
+			dir = constants.Storage_local_path
+		}
+		publicURL := cfg.STORAGE_PUBLIC_URL
+		if publicURL == "" {
+			publicURL = constants.Storage_local_route
+		}
+		return NewLocal(dir, publicURL), nil
+	case constants.Storage_driver_s3:
+		if cfg.S3_ENDPOINT == "" || cfg.S3_BUCKET == "" || cfg.S3_ACCESS_KEY == "" || cfg.S3_SECRET_KEY == "" {
+			return nil, customerrors.ErrStorageConfig
+		}
+		region := cfg.S3_REGION
+		if region == "" {
+			region = "us-east-1"
+		}
+		publicURL := cfg.STORAGE_PUBLIC_URL
+		if publicURL == "" {
+			publicURL = strings.TrimRight(cfg.S3_ENDPOINT, "/") + "/" + cfg.S3_BUCKET
+		}
+		return NewS3(cfg.S3_ENDPOINT, region, cfg.S3_BUCKET, cfg.S3_ACCESS_KEY, cfg.S3_SECRET_KEY, publicURL)
+	default:
+		return nil, customerrors.ErrStorageConfig
+	}
+}
+
+// joinURL put key under base url, key is already safe path generated by app
+func joinURL(base string, key string) string {
+	return strings.TrimRight(base, "/") + "/" + key
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/config"
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewStorage(t *testing.T) {
+	s3Cfg := config.Config{
+		STORAGE_DRIVER: constants.Storage_driver_s3,
+		S3_ENDPOINT:    "http://localhost:9000",
+		S3_BUCKET:      "kangsayur",
+		S3_ACCESS_KEY:  "minioadmin",
+		S3_SECRET_KEY:  "minioadmin",
+	}
+	testCase := []struct {
+		Name         string
+		Cfg          config.Config
+		ExpectedType interface{}
+		ExpectedURL  string
+		ExpectedErr  error
+	}{
+		{
+			Name:         "default local",
+			Cfg:          config.Config{},
+			ExpectedType: &Local{},
+			ExpectedURL:  "/uploads/items/1.jpg",
+		},
+		{
+			Name:         "local with public url",
+			Cfg:          config.Config{STORAGE_DRIVER: constants.Storage_driver_local, STORAGE_PUBLIC_URL: "https://kangsayur.com/uploads/"},
+			ExpectedType: &Local{},
+			ExpectedURL:  "https://kangsayur.com/uploads/items/1.jpg",
+		},
+		{
+			Name:         "s3",
+			Cfg:          s3Cfg,
+			ExpectedType: &S3{},
+			ExpectedURL:  "http://localhost:9000/kangsayur/items/1.jpg",
+		},
+		{
+			Name:        "s3 without bucket",
+			Cfg:         config.Config{STORAGE_DRIVER: constants.Storage_driver_s3, S3_ENDPOINT: "http://localhost:9000"},
+			ExpectedErr: customerrors.ErrStorageConfig,
+		},
+		{
+			Name:        "s3 endpoint without scheme",
+			Cfg:         config.Config{STORAGE_DRIVER: constants.Storage_driver_s3, S3_ENDPOINT: "localhost:9000", S3_BUCKET: "kangsayur", S3_ACCESS_KEY: "minioadmin", S3_SECRET_KEY: "minioadmin"},
+			ExpectedErr: customerrors.ErrStorageConfig,
+		},
+		{
+			Name:        "unknown driver",
+			Cfg:         config.Config{STORAGE_DRIVER: "floppy"},
+			ExpectedErr: customerrors.ErrStorageConfig,
+		},
+	}
+	for _, v := range testCase {
+		t.Run(v.Name, func(t *testing.T) {
+			storage, err := NewStorage(&v.Cfg)
+			assert.Equal(t, v.ExpectedErr, err)
+			if v.ExpectedErr == nil {
+				assert.IsType(t, v.ExpectedType, storage)
+				assert.Equal(t, v.ExpectedURL, storage.URL("items/1.jpg"))
+			}
+		})
+	}
+}
+
+func TestLocal(t *testing.T) {
+	dir := t.TempDir()
+	local := NewLocal(dir, "/uploads")
+
+	assert.NoError(t, local.Put("items/1/a.jpg", []byte("image"), "image/jpeg"))
+	data, err := os.ReadFile(filepath.Join(dir, "items", "1", "a.jpg"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("image"), data)
+
+	assert.NoError(t, local.Delete("items/1/a.jpg"))
+	_, err = os.Stat(filepath.Join(dir, "items", "1", "a.jpg"))
+	assert.True(t, os.IsNotExist(err))
+	// deleted file is already gone
+	assert.NoError(t, local.Delete("items/1/a.jpg"))
+
+	assert.Equal(t, customerrors.ErrStorageKey, local.Put("../escape.jpg", []byte("image"), "image/jpeg"))
+	assert.Equal(t, customerrors.ErrStorageKey, local.Put("", []byte("image"), "image/jpeg"))
+}
+
+func TestS3(t *testing.T) {
+	type request struct {
+		Method      string
+		Path        string
+		ContentType string
+		Body        string
+		Auth        string
+	}
+	var requests []request
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, request{
+			Method:      r.Method,
+			Path:        r.URL.EscapedPath(),
+			ContentType: r.Header.Get("Content-Type"),
+			Body:        string(body),
+			Auth:        r.Header.Get("Authorization"),
+		})
+		w.Header().Set("ETag", `"etag"`)
+		if r.Method == http.MethodDelete && status == http.StatusOK {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	s3, err := NewS3(server.URL, "us-east-1", "kangsayur", "minioadmin", "minioadmin", "https://cdn.kangsayur.com")
+	assert.NoError(t, err)
+
+	assert.NoError(t, s3.Put("items/1/cabai merah.jpg", []byte("image"), "image/jpeg"))
+	assert.NoError(t, s3.Delete("items/1/cabai merah.jpg"))
+	status = http.StatusForbidden
+	err = s3.Put("items/1/a.jpg", []byte("image"), "image/jpeg")
+	assert.ErrorIs(t, err, customerrors.ErrStorage)
+
+	assert.Len(t, requests, 3)
+	assert.Equal(t, http.MethodPut, requests[0].Method)
+	assert.Equal(t, "/kangsayur/items/1/cabai%20merah.jpg", requests[0].Path)
+	assert.Equal(t, "image/jpeg", requests[0].ContentType)
+	// body of plain http request is sent in signed chunk
+	assert.Contains(t, requests[0].Body, "image")
+	assert.True(t, strings.HasPrefix(requests[0].Auth, "AWS4-HMAC-SHA256 Credential=minioadmin/"))
+	assert.Contains(t, requests[0].Auth, "/us-east-1/s3/aws4_request")
+	assert.Equal(t, http.MethodDelete, requests[1].Method)
+	assert.Equal(t, "/kangsayur/items/1/cabai%20merah.jpg", requests[1].Path)
+
+	assert.Equal(t, "https://cdn.kangsayur.com/items/1/a.jpg", s3.URL("items/1/a.jpg"))
+}
@@ -0,0 +1,91 @@
+package thumbnail
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/constants"
+	customerrors "github.com/rnwxyz/rian-wijaya_mini-project_kang-sayur/pkg/utils/custom_errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newImage(w int, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 30, B: 30, A: 255})
+		}
+	}
+	return img
+}
+
+func TestDetect(t *testing.T) {
+	var pngData, jpegData bytes.Buffer
+	assert.NoError(t, png.Encode(&pngData, newImage(10, 10)))
+	assert.NoError(t, jpeg.Encode(&jpegData, newImage(10, 10), nil))
+
+	testCase := []struct {
+		Name         string
+		Data         []byte
+		ExpectedType string
+		ExpectedExt  string
+		ExpectedErr  error
+	}{
+		{Name: "png", Data: pngData.Bytes(), ExpectedType: "image/png", ExpectedExt: ".png"},
+		{Name: "jpeg", Data: jpegData.Bytes(), ExpectedType: "image/jpeg", ExpectedExt: ".jpg"},
+		{Name: "text", Data: []byte("not an image"), ExpectedErr: customerrors.ErrImageType},
+		{Name: "gif", Data: []byte("GIF89a"), ExpectedErr: customerrors.ErrImageType},
+		{Name: "broken png", Data: pngData.Bytes()[:20], ExpectedErr: customerrors.ErrImageType},
+		{Name: "too large", Data: make([]byte, constants.Image_max_size+1), ExpectedErr: customerrors.ErrImageTooLarge},
+	}
+	for _, v := range testCase {
+		t.Run(v.Name, func(t *testing.T) {
+			contentType, ext, err := Detect(v.Data)
+			assert.Equal(t, v.ExpectedErr, err)
+			assert.Equal(t, v.ExpectedType, contentType)
+			assert.Equal(t, v.ExpectedExt, ext)
+		})
+	}
+}
+
+func TestMake(t *testing.T) {
+	testCase := []struct {
+		Name        string
+		Width       int
+		Height      int
+		ContentType string
+		ExpectedW   int
+		ExpectedH   int
+	}{
+		{Name: "wide png", Width: 600, Height: 300, ContentType: "image/png", ExpectedW: 256, ExpectedH: 128},
+		{Name: "tall jpeg", Width: 300, Height: 600, ContentType: "image/jpeg", ExpectedW: 128, ExpectedH: 256},
+		{Name: "small keep size", Width: 100, Height: 50, ContentType: "image/png", ExpectedW: 100, ExpectedH: 50},
+	}
+	for _, v := range testCase {
+		t.Run(v.Name, func(t *testing.T) {
+			var data bytes.Buffer
+			if v.ContentType == "image/png" {
+				assert.NoError(t, png.Encode(&data, newImage(v.Width, v.Height)))
+			} else {
+				assert.NoError(t, jpeg.Encode(&data, newImage(v.Width, v.Height), nil))
+			}
+
+			thumb, err := Make(data.Bytes(), v.ContentType, constants.Thumbnail_size)
+			assert.NoError(t, err)
+
+			contentType, _, err := Detect(thumb)
+			assert.NoError(t, err)
+			assert.Equal(t, v.ContentType, contentType)
+			img, _, err := image.Decode(bytes.NewReader(thumb))
+			assert.NoError(t, err)
+			assert.Equal(t, v.ExpectedW, img.Bounds().Dx())
+			assert.Equal(t, v.ExpectedH, img.Bounds().Dy())
+			r, _, _, _ := img.At(v.ExpectedW/2, v.ExpectedH/2).RGBA()
+			assert.InDelta(t, 200, r>>8, 5)
+		})
+	}
+}